@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/go-kit/log"
+
+	"github.com/cortexproject/cortex/pkg/cortex"
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	"github.com/cortexproject/cortex/pkg/storage/bucket/encryption"
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketfsck"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+)
+
+const bucketFsckCommandName = "bucket-fsck"
+
+const bucketFsckCommandUsage = `Usage: %s bucket-fsck [flags]
+
+Check the consistency of the blocks storage bucket and optionally repair it. The blocks
+of each tenant are cross-checked against the bucket index, the block markers, the Parquet
+converter marks and the user index. The bucket is configured through the regular Cortex
+configuration file and flags.
+
+The inconsistencies are only reported unless -bucket-fsck.dry-run=false is set. The exit
+code is 0 if no inconsistency is left, 1 on error and 3 if some inconsistencies are left.
+
+Flags:
+`
+
+// runBucketFsckCommand runs the bucket consistency checker with the given arguments and
+// returns the process exit code.
+func runBucketFsckCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet(bucketFsckCommandName, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), bucketFsckCommandUsage, os.Args[0])
+		fs.PrintDefaults()
+	}
+
+	var (
+		cfg    cortex.Config
+		cmdCfg bucketfsck.Config
+		format string
+	)
+
+	configFile, expandENV := parseConfigFileParameter(args)
+	cfg.RegisterFlags(fs)
+	cmdCfg.RegisterFlags(fs)
+	fs.StringVar(&format, "bucket-fsck.output-format", "table", "Output format. Supported values are: table, json.")
+	flagext.IgnoredFlag(fs, configFileOption, "Configuration file to load.")
+	_ = fs.Bool(configExpandENV, false, "Expands ${var} or $var in config according to the values of the environment variables.")
+
+	if configFile != "" {
+		if err := LoadConfig(configFile, expandENV, &cfg); err != nil {
+			fmt.Fprintf(stderr, "error loading config from %s: %v\n", configFile, err)
+			return 1
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if err := cmdCfg.Validate(); err != nil {
+		fmt.Fprintf(stderr, "invalid config: %v\n", err)
+		return 2
+	}
+	if format != "table" && format != "json" {
+		fmt.Fprintf(stderr, "unsupported output format %q\n", format)
+		return 2
+	}
+
+	ctx := context.Background()
+	logger := log.NewLogfmtLogger(log.NewSyncWriter(stderr))
+
+	// The encrypted objects are decrypted, like the Cortex services do.
+	if cfg.BlocksStorage.Encryption.Enabled {
+		middleware, err := encryption.NewMiddleware(cfg.BlocksStorage.Encryption, logger)
+		if err != nil {
+			fmt.Fprintf(stderr, "error initializing blocks storage encryption: %v\n", err)
+			return 1
+		}
+		cfg.BlocksStorage.Bucket.Middlewares = append(cfg.BlocksStorage.Bucket.Middlewares, middleware)
+	}
+
+	bkt, err := bucket.NewClient(ctx, cfg.BlocksStorage.Bucket, nil, bucketFsckCommandName, logger, nil)
+	if err != nil {
+		fmt.Fprintf(stderr, "error creating bucket client: %v\n", err)
+		return 1
+	}
+
+	report, err := bucketfsck.NewChecker(cmdCfg, bkt, logger).Run(ctx)
+	if err != nil {
+		fmt.Fprintf(stderr, "error checking bucket: %v\n", err)
+		return 1
+	}
+
+	if err := writeBucketFsckReport(stdout, report, format); err != nil {
+		fmt.Fprintf(stderr, "error writing report: %v\n", err)
+		return 1
+	}
+
+	if report.Unrepaired() > 0 {
+		return 3
+	}
+	return 0
+}
+
+func writeBucketFsckReport(out io.Writer, report *bucketfsck.Report, format string) error {
+	if format == "json" {
+		return writeJSON(out, report)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TENANT\tBLOCK\tTYPE\tDETAILS\tREPAIR")
+	for _, i := range report.Issues {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", i.UserID, i.BlockID, i.Type, i.Details, bucketFsckRepairStatus(i))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Checked %d tenant(s) and %d block(s): %d issue(s) found, %d repaired\n",
+		report.Tenants, report.Blocks, len(report.Issues), len(report.Issues)-report.Unrepaired())
+	return w.Flush()
+}
+
+func bucketFsckRepairStatus(issue bucketfsck.Issue) string {
+	switch {
+	case issue.Repair == "":
+		return "manual fix required"
+	case issue.Repaired:
+		return "repaired: " + issue.Repair
+	case issue.RepairError != "":
+		return fmt.Sprintf("failed to %s: %s", issue.Repair, issue.RepairError)
+	default:
+		return "would " + issue.Repair
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore/providers/filesystem"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketfsck"
+)
+
+func TestBucketFsckCommand(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	bkt, err := filesystem.NewBucket(dir)
+	require.NoError(t, err)
+
+	partial := ulid.MustNew(1, nil)
+	require.NoError(t, bkt.Upload(ctx, path.Join("user-1", partial.String(), "index"), strings.NewReader("index")))
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(fmt.Sprintf("blocks_storage:\n  backend: filesystem\n  filesystem:\n    dir: %s\n", dir)), 0644))
+
+	run := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := runBucketFsckCommand(append(args, "-config.file="+configFile, "-bucket-fsck.min-age=0"), &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	t.Run("invalid output format", func(t *testing.T) {
+		code, _, stderr := run("-bucket-fsck.output-format=xml")
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr, `unsupported output format "xml"`)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		code, stdout, stderr := run()
+		require.Equal(t, 3, code, stderr)
+		assert.Contains(t, stdout, "would mark the block for deletion")
+		assert.Contains(t, stdout, "1 issue(s) found, 0 repaired")
+
+		exists, err := bkt.Exists(ctx, path.Join("user-1", partial.String(), metadata.DeletionMarkFilename))
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("repair", func(t *testing.T) {
+		code, stdout, stderr := run("-bucket-fsck.dry-run=false", "-bucket-fsck.output-format=json")
+		require.Equal(t, 0, code, stderr)
+
+		var report bucketfsck.Report
+		require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+		require.NotEmpty(t, report.Issues)
+		assert.Zero(t, report.Unrepaired())
+
+		exists, err := bkt.Exists(ctx, path.Join("user-1", partial.String(), metadata.DeletionMarkFilename))
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+}
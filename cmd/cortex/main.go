@@ -72,6 +72,13 @@ func main() {
 	)
 
 	args := os.Args[1:]
+	if len(args) > 0 && args[0] == ringCommandName {
+		os.Exit(runRingCommand(args[1:], os.Stdout, os.Stderr))
+	}
+	if len(args) > 0 && args[0] == bucketFsckCommandName {
+		os.Exit(runBucketFsckCommand(args[1:], os.Stdout, os.Stderr))
+	}
+
 	configFile, expandENV := parseConfigFileParameter(args)
 
 	// This sets default values from flags to the config.
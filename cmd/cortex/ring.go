@@ -0,0 +1,438 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+	"github.com/thanos-io/thanos/pkg/discovery/dns"
+
+	"github.com/cortexproject/cortex/pkg/alertmanager"
+	"github.com/cortexproject/cortex/pkg/cortex"
+	"github.com/cortexproject/cortex/pkg/ingester"
+	"github.com/cortexproject/cortex/pkg/ring"
+	"github.com/cortexproject/cortex/pkg/ring/kv"
+	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
+	"github.com/cortexproject/cortex/pkg/ring/kv/memberlist"
+	"github.com/cortexproject/cortex/pkg/storegateway"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/services"
+)
+
+const ringCommandName = "ring"
+
+const ringCommandUsage = `Usage: %s ring <command> [flags]
+
+Inspect and repair the state of a hash ring stored in any of the supported KV stores.
+The KV store is configured through the regular Cortex configuration file and flags.
+
+Commands:
+  dump               Print all the instances registered in the ring.
+  ownership          Print the token ownership of each instance and zone.
+  forget-unhealthy   Remove from the ring all the instances whose heartbeat timed out.
+  simulate           Preview ownership changes when adding or removing instances.
+
+Flags:
+`
+
+// ringTarget contains the information required to access a single ring.
+type ringTarget struct {
+	kvConfig         kv.Config
+	key              string
+	heartbeatTimeout time.Duration
+	zoneAware        bool
+	numTokens        int
+	tokensStrategy   string
+}
+
+// ringTargets returns the rings which can be managed by the ring command, keyed by name.
+func ringTargets(cfg *cortex.Config) map[string]ringTarget {
+	ingesterRing := cfg.Ingester.LifecyclerConfig.RingConfig
+
+	return map[string]ringTarget{
+		"ingester": {
+			kvConfig:         ingesterRing.KVStore,
+			key:              ingester.RingKey,
+			heartbeatTimeout: ingesterRing.HeartbeatTimeout,
+			zoneAware:        ingesterRing.ZoneAwarenessEnabled,
+			numTokens:        cfg.Ingester.LifecyclerConfig.NumTokens,
+			tokensStrategy:   cfg.Ingester.LifecyclerConfig.TokensGeneratorStrategy,
+		},
+		"distributor": {
+			kvConfig:         cfg.Distributor.DistributorRing.KVStore,
+			key:              "distributor",
+			heartbeatTimeout: cfg.Distributor.DistributorRing.HeartbeatTimeout,
+			numTokens:        1,
+		},
+		"store-gateway": {
+			kvConfig:         cfg.StoreGateway.ShardingRing.KVStore,
+			key:              storegateway.RingKey,
+			heartbeatTimeout: cfg.StoreGateway.ShardingRing.HeartbeatTimeout,
+			zoneAware:        cfg.StoreGateway.ShardingRing.ZoneAwarenessEnabled,
+			numTokens:        storegateway.RingNumTokens,
+		},
+		"compactor": {
+			kvConfig:         cfg.Compactor.ShardingRing.KVStore,
+			key:              "compactor",
+			heartbeatTimeout: cfg.Compactor.ShardingRing.HeartbeatTimeout,
+			numTokens:        512,
+		},
+		"ruler": {
+			kvConfig:         cfg.Ruler.Ring.KVStore,
+			key:              "ring",
+			heartbeatTimeout: cfg.Ruler.Ring.HeartbeatTimeout,
+			zoneAware:        cfg.Ruler.Ring.ZoneAwarenessEnabled,
+			numTokens:        cfg.Ruler.Ring.NumTokens,
+		},
+		"alertmanager": {
+			kvConfig:         cfg.Alertmanager.ShardingRing.KVStore,
+			key:              alertmanager.RingKey,
+			heartbeatTimeout: cfg.Alertmanager.ShardingRing.HeartbeatTimeout,
+			zoneAware:        cfg.Alertmanager.ShardingRing.ZoneAwarenessEnabled,
+			numTokens:        alertmanager.RingNumTokens,
+		},
+		"parquet-converter": {
+			kvConfig:         cfg.ParquetConverter.Ring.KVStore,
+			key:              "parquet-converter",
+			heartbeatTimeout: cfg.ParquetConverter.Ring.HeartbeatTimeout,
+			numTokens:        512,
+		},
+	}
+}
+
+type ringCommandFunc func(ctx context.Context, client kv.Client, target ringTarget, cmdCfg ringCommandConfig, out io.Writer) error
+
+var ringCommands = map[string]ringCommandFunc{
+	"dump":             ringDump,
+	"ownership":        ringOwnership,
+	"forget-unhealthy": ringForgetUnhealthy,
+	"simulate":         ringSimulate,
+}
+
+type ringCommandConfig struct {
+	name     string
+	format   string
+	dryRun   bool
+	add      flagext.StringSliceCSV
+	remove   flagext.StringSliceCSV
+	strategy string
+	timeout  time.Duration
+}
+
+func (c *ringCommandConfig) RegisterFlags(f *flag.FlagSet) {
+	f.StringVar(&c.name, "ring.name", "ingester", "Name of the ring to operate on. Supported values are: alertmanager, compactor, distributor, ingester, parquet-converter, ruler, store-gateway.")
+	f.StringVar(&c.format, "ring.output-format", "table", "Output format. Supported values are: table, json.")
+	f.BoolVar(&c.dryRun, "ring.dry-run", false, "Only print the instances which would be forgotten, without modifying the ring.")
+	f.Var(&c.add, "ring.simulate.add", "Comma-separated list of instances to add in the simulation, in the form <id> or <id>:<zone>.")
+	f.Var(&c.remove, "ring.simulate.remove", "Comma-separated list of instance IDs to remove in the simulation.")
+	f.StringVar(&c.strategy, "ring.simulate.tokens-generator-strategy", "", "Algorithm used to generate the tokens of the added instances. Defaults to the strategy configured for the ring.")
+	f.DurationVar(&c.timeout, "ring.timeout", time.Minute, "Timeout for the operations against the KV store.")
+}
+
+// runRingCommand runs the ring administration command with the given arguments and
+// returns the process exit code.
+func runRingCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet(ringCommandName, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), ringCommandUsage, os.Args[0])
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fs.Usage()
+		return 2
+	}
+	command, args := args[0], args[1:]
+
+	run, ok := ringCommands[command]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown ring command %q\n", command)
+		fs.Usage()
+		return 2
+	}
+
+	var (
+		cfg    cortex.Config
+		cmdCfg ringCommandConfig
+	)
+
+	configFile, expandENV := parseConfigFileParameter(args)
+	cfg.RegisterFlags(fs)
+	cmdCfg.RegisterFlags(fs)
+	flagext.IgnoredFlag(fs, configFileOption, "Configuration file to load.")
+	_ = fs.Bool(configExpandENV, false, "Expands ${var} or $var in config according to the values of the environment variables.")
+
+	if configFile != "" {
+		if err := LoadConfig(configFile, expandENV, &cfg); err != nil {
+			fmt.Fprintf(stderr, "error loading config from %s: %v\n", configFile, err)
+			return 1
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	target, ok := ringTargets(&cfg)[cmdCfg.name]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown ring %q\n", cmdCfg.name)
+		return 2
+	}
+	if cmdCfg.format != "table" && cmdCfg.format != "json" {
+		fmt.Fprintf(stderr, "unsupported output format %q\n", cmdCfg.format)
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cmdCfg.timeout)
+	defer cancel()
+
+	logger := log.NewLogfmtLogger(log.NewSyncWriter(stderr))
+	client, closeClient, err := newRingKVClient(ctx, &cfg, target.kvConfig, logger)
+	if err != nil {
+		fmt.Fprintf(stderr, "error creating KV client: %v\n", err)
+		return 1
+	}
+	defer closeClient()
+
+	if err := run(ctx, client, target, cmdCfg, stdout); err != nil {
+		fmt.Fprintf(stderr, "error running ring %s: %v\n", command, err)
+		return 1
+	}
+	return 0
+}
+
+// newRingKVClient builds the KV client for the given config. When the store is memberlist,
+// the process joins the memberlist cluster in order to receive the ring state.
+func newRingKVClient(ctx context.Context, cfg *cortex.Config, kvCfg kv.Config, logger log.Logger) (kv.Client, func(), error) {
+	closeFn := func() {}
+
+	if usesMemberlist(kvCfg) {
+		cfg.MemberlistKV.Codecs = []codec.Codec{ring.GetCodec()}
+		dnsProvider := dns.NewProvider(logger, nil, dns.GolangResolverType)
+		kvs := memberlist.NewKVInitService(&cfg.MemberlistKV, logger, dnsProvider, nil)
+		if err := services.StartAndAwaitRunning(ctx, kvs); err != nil {
+			return nil, nil, err
+		}
+
+		kvCfg.MemberlistKV = kvs.GetMemberlistKV
+		closeFn = func() {
+			_ = services.StopAndAwaitTerminated(context.Background(), kvs)
+		}
+	}
+
+	client, err := kv.NewClient(kvCfg, ring.GetCodec(), nil, logger)
+	if err != nil {
+		closeFn()
+		return nil, nil, err
+	}
+	return client, closeFn, nil
+}
+
+func usesMemberlist(cfg kv.Config) bool {
+	if cfg.Store == "memberlist" {
+		return true
+	}
+	return cfg.Store == "multi" && (cfg.Multi.Primary == "memberlist" || cfg.Multi.Secondary == "memberlist")
+}
+
+func getRingDesc(ctx context.Context, client kv.Client, key string) (*ring.Desc, error) {
+	val, err := client.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return ring.GetOrCreateRingDesc(val), nil
+}
+
+type ringInstance struct {
+	ID                  string    `json:"id"`
+	Zone                string    `json:"zone"`
+	State               string    `json:"state"`
+	Address             string    `json:"address"`
+	Healthy             bool      `json:"healthy"`
+	RegisteredTimestamp time.Time `json:"registered_timestamp"`
+	HeartbeatTimestamp  time.Time `json:"heartbeat_timestamp"`
+	Tokens              []uint32  `json:"tokens"`
+}
+
+func ringDump(ctx context.Context, client kv.Client, target ringTarget, cmdCfg ringCommandConfig, out io.Writer) error {
+	desc, err := getRingDesc(ctx, client, target.key)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	instances := make([]ringInstance, 0, len(desc.Ingesters))
+	for id, instance := range desc.Ingesters {
+		instances = append(instances, ringInstance{
+			ID:                  id,
+			Zone:                instance.Zone,
+			State:               instance.State.String(),
+			Address:             instance.Addr,
+			Healthy:             instance.IsHeartbeatHealthy(target.heartbeatTimeout, now),
+			RegisteredTimestamp: instance.GetRegisteredAt(),
+			HeartbeatTimestamp:  time.Unix(instance.Timestamp, 0),
+			Tokens:              instance.Tokens,
+		})
+	}
+	sort.Slice(instances, func(i, j int) bool { return instances[i].ID < instances[j].ID })
+
+	if cmdCfg.format == "json" {
+		return writeJSON(out, instances)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tZONE\tSTATE\tADDRESS\tHEALTHY\tREGISTERED AT\tLAST HEARTBEAT\tTOKENS")
+	for _, i := range instances {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%s\t%s\t%d\n", i.ID, i.Zone, i.State, i.Address, i.Healthy,
+			formatTimestamp(i.RegisteredTimestamp), formatTimestamp(i.HeartbeatTimestamp), len(i.Tokens))
+	}
+	return w.Flush()
+}
+
+func ringOwnership(ctx context.Context, client kv.Client, target ringTarget, cmdCfg ringCommandConfig, out io.Writer) error {
+	desc, err := getRingDesc(ctx, client, target.key)
+	if err != nil {
+		return err
+	}
+
+	report := desc.GetOwnership(target.zoneAware)
+	if cmdCfg.format == "json" {
+		return writeJSON(out, report)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tZONE\tSTATE\tTOKENS\tOWNERSHIP\tDIFF FROM EXPECTED")
+	for _, i := range report.Instances {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%.2f%%\t%.2f%%\n", i.ID, i.Zone, i.State, i.NumTokens, i.Ownership, i.DiffOwnership)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "ZONE\tINSTANCES\tTOKENS\tOWNERSHIP")
+	for _, z := range report.Zones {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.2f%%\n", z.Zone, z.NumInstances, z.NumTokens, z.Ownership)
+	}
+	return w.Flush()
+}
+
+func ringForgetUnhealthy(ctx context.Context, client kv.Client, target ringTarget, cmdCfg ringCommandConfig, out io.Writer) error {
+	if target.heartbeatTimeout <= 0 {
+		return errors.New("the heartbeat timeout is disabled, so no instance can be considered unhealthy")
+	}
+
+	var forgotten []string
+	err := client.CAS(ctx, target.key, func(in any) (any, bool, error) {
+		forgotten = forgotten[:0]
+		if in == nil {
+			return nil, false, nil
+		}
+
+		desc := in.(*ring.Desc)
+		now := time.Now()
+		for id, instance := range desc.Ingesters {
+			if !instance.IsHeartbeatHealthy(target.heartbeatTimeout, now) {
+				forgotten = append(forgotten, id)
+			}
+		}
+
+		if len(forgotten) == 0 || cmdCfg.dryRun {
+			return nil, false, nil
+		}
+
+		for _, id := range forgotten {
+			desc.RemoveIngester(id)
+		}
+		return desc, true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(forgotten)
+	if cmdCfg.format == "json" {
+		return writeJSON(out, struct {
+			Forgotten []string `json:"forgotten"`
+			DryRun    bool     `json:"dry_run"`
+		}{Forgotten: forgotten, DryRun: cmdCfg.dryRun})
+	}
+
+	verb := "Forgot"
+	if cmdCfg.dryRun {
+		verb = "Would forget"
+	}
+	fmt.Fprintf(out, "%s %d unhealthy instance(s)\n", verb, len(forgotten))
+	for _, id := range forgotten {
+		fmt.Fprintln(out, id)
+	}
+	return nil
+}
+
+func ringSimulate(ctx context.Context, client kv.Client, target ringTarget, cmdCfg ringCommandConfig, out io.Writer) error {
+	desc, err := getRingDesc(ctx, client, target.key)
+	if err != nil {
+		return err
+	}
+
+	strategy := cmdCfg.strategy
+	if strategy == "" {
+		strategy = target.tokensStrategy
+	}
+	generator, err := ring.NewTokenGenerator(strategy)
+	if err != nil {
+		return err
+	}
+
+	simCfg := ring.SimulationConfig{
+		Remove:    cmdCfg.remove,
+		NumTokens: target.numTokens,
+		ZoneAware: target.zoneAware,
+		Generator: generator,
+	}
+	for _, instance := range cmdCfg.add {
+		id, zone, _ := strings.Cut(instance, ":")
+		simCfg.Add = append(simCfg.Add, ring.SimulatedInstance{ID: id, Zone: zone})
+	}
+
+	changes, err := ring.SimulateOwnership(desc, simCfg)
+	if err != nil {
+		return err
+	}
+
+	if cmdCfg.format == "json" {
+		return writeJSON(out, changes)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tZONE\tCHANGE\tOWNERSHIP BEFORE\tOWNERSHIP AFTER\tDELTA")
+	for _, c := range changes {
+		change := ""
+		if c.Added {
+			change = "added"
+		} else if c.Removed {
+			change = "removed"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.2f%%\t%.2f%%\t%+.2f%%\n", c.ID, c.Zone, change, c.Before, c.After, c.After-c.Before)
+	}
+	return w.Flush()
+}
+
+func writeJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() || t.Unix() == 0 {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
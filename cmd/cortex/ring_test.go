@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ingester"
+	"github.com/cortexproject/cortex/pkg/ring"
+	"github.com/cortexproject/cortex/pkg/ring/kv"
+)
+
+func TestRingCommand(t *testing.T) {
+	ctx := context.Background()
+
+	client, err := kv.NewClient(kv.Config{Store: "inmemory", Prefix: "collectors/"}, ring.GetCodec(), nil, log.NewNopLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, client.CAS(ctx, ingester.RingKey, func(any) (any, bool, error) {
+		desc := ring.NewDesc()
+		desc.AddIngester("ingester-1", "1.1.1.1", "zone-a", []uint32{0}, ring.ACTIVE, time.Now())
+		desc.AddIngester("ingester-2", "2.2.2.2", "zone-a", []uint32{1 << 31}, ring.ACTIVE, time.Now())
+		unhealthy := desc.AddIngester("ingester-3", "3.3.3.3", "zone-a", []uint32{3 << 30}, ring.ACTIVE, time.Now())
+		unhealthy.Timestamp = time.Now().Add(-time.Hour).Unix()
+		desc.Ingesters["ingester-3"] = unhealthy
+		return desc, true, nil
+	}))
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("ingester:\n  lifecycler:\n    ring:\n      kvstore:\n        store: inmemory\n"), 0644))
+
+	run := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := runRingCommand(append(args, "-config.file="+configFile), &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	t.Run("unknown command", func(t *testing.T) {
+		code, _, stderr := run("unknown")
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr, `unknown ring command "unknown"`)
+	})
+
+	t.Run("unknown ring", func(t *testing.T) {
+		code, _, stderr := run("dump", "-ring.name=unknown")
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr, `unknown ring "unknown"`)
+	})
+
+	t.Run("dump", func(t *testing.T) {
+		code, stdout, stderr := run("dump", "-ring.output-format=json")
+		require.Equal(t, 0, code, stderr)
+
+		var instances []ringInstance
+		require.NoError(t, json.Unmarshal([]byte(stdout), &instances))
+		require.Len(t, instances, 3)
+		assert.Equal(t, "ingester-1", instances[0].ID)
+		assert.True(t, instances[0].Healthy)
+		assert.False(t, instances[2].Healthy)
+	})
+
+	t.Run("ownership", func(t *testing.T) {
+		code, stdout, stderr := run("ownership", "-ring.output-format=json")
+		require.Equal(t, 0, code, stderr)
+
+		var report ring.OwnershipReport
+		require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+		require.Len(t, report.Instances, 3)
+		assert.InDelta(t, 25, report.Instances[0].Ownership, 0.0001)
+		assert.InDelta(t, 50, report.Instances[1].Ownership, 0.0001)
+		assert.InDelta(t, 25, report.Instances[2].Ownership, 0.0001)
+		require.Len(t, report.Zones, 1)
+		assert.InDelta(t, 100, report.Zones[0].Ownership, 0.0001)
+	})
+
+	t.Run("simulate", func(t *testing.T) {
+		code, stdout, stderr := run("simulate", "-ring.simulate.remove=ingester-2", "-ring.simulate.add=ingester-4:zone-a")
+		require.Equal(t, 0, code, stderr)
+		assert.Contains(t, stdout, "ingester-2")
+		assert.Contains(t, stdout, "removed")
+		assert.Contains(t, stdout, "added")
+	})
+
+	t.Run("forget unhealthy in dry-run", func(t *testing.T) {
+		code, stdout, stderr := run("forget-unhealthy", "-ring.dry-run")
+		require.Equal(t, 0, code, stderr)
+		assert.Equal(t, "Would forget 1 unhealthy instance(s)\ningester-3\n", stdout)
+
+		desc, err := getRingDesc(ctx, client, ingester.RingKey)
+		require.NoError(t, err)
+		assert.Len(t, desc.Ingesters, 3)
+	})
+
+	t.Run("forget unhealthy", func(t *testing.T) {
+		code, stdout, stderr := run("forget-unhealthy")
+		require.Equal(t, 0, code, stderr)
+		assert.Equal(t, "Forgot 1 unhealthy instance(s)\ningester-3\n", stdout)
+
+		desc, err := getRingDesc(ctx, client, ingester.RingKey)
+		require.NoError(t, err)
+		assert.Len(t, desc.Ingesters, 2)
+		assert.NotContains(t, desc.Ingesters, "ingester-3")
+	})
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/weaveworks/common/logging"
+	"gopkg.in/yaml.v2"
+
+	"github.com/cortexproject/cortex/pkg/util/log"
+	"github.com/cortexproject/cortex/tools/tenantmigrate"
+)
+
+func main() {
+	var (
+		configFilename string
+		cfg            tenantmigrate.Config
+	)
+
+	logfmt, loglvl := logging.Format{}, logging.Level{}
+	logfmt.RegisterFlags(flag.CommandLine)
+	loglvl.RegisterFlags(flag.CommandLine)
+	cfg.RegisterFlags(flag.CommandLine)
+	flag.StringVar(&configFilename, "config", "", "Path to the migration config YAML, with the source and destination storage configs")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "%s is a tool to copy the blocks, rule groups and Alertmanager config of a tenant to another tenant or bucket.\nPlease see %s for instructions on how to run it.\n\n", os.Args[0], "https://cortexmetrics.io/docs/guides/tenant-migration/")
+		fmt.Fprintf(flag.CommandLine.Output(), "Flags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	logger, err := log.NewPrometheusLogger(loglvl, logfmt)
+	if err != nil {
+		fatal("failed to create logger: %v", err)
+	}
+
+	if configFilename != "" {
+		buf, err := os.ReadFile(configFilename)
+		if err != nil {
+			fatal("failed to load config file from %s: %v", configFilename, err)
+		}
+		err = yaml.UnmarshalStrict(buf, &cfg)
+		if err != nil {
+			fatal("failed to parse config file: %v", err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fatal("config is invalid: %v", err)
+	}
+
+	ctx := context.Background()
+
+	migrator, err := tenantmigrate.NewMigrator(ctx, cfg, logger)
+	if err != nil {
+		fatal("couldn't initialize migrator: %v", err)
+	}
+
+	results, err := migrator.Run(ctx)
+
+	fmt.Println("Results:")
+	if cfg.DryRun {
+		fmt.Println("  (dry-run, nothing has been copied)")
+	}
+	fmt.Printf("  Copied blocks %d:\n  %s\n", len(results.CopiedBlocks), strings.Join(results.CopiedBlocks, ","))
+	fmt.Printf("  Rewritten blocks %d:\n  %s\n", len(results.RewrittenBlocks), strings.Join(results.RewrittenBlocks, ","))
+	fmt.Printf("  Blocks with no matching series %d:\n  %s\n", len(results.EmptyBlocks), strings.Join(results.EmptyBlocks, ","))
+	fmt.Printf("  Blocks already migrated %d:\n  %s\n", len(results.MigratedBlocks), strings.Join(results.MigratedBlocks, ","))
+	fmt.Printf("  Failed blocks %d:\n  %s\n", len(results.FailedBlocks), strings.Join(results.FailedBlocks, ","))
+	fmt.Printf("  Blocks size: %d bytes\n", results.EstimatedBytes)
+	fmt.Printf("  Rule groups: %d\n", results.RuleGroups)
+	fmt.Printf("  Alertmanager config: %t\n", results.AlertmanagerConfig)
+	fmt.Printf("  Alertmanager state: %t\n", results.AlertmanagerState)
+
+	if err != nil {
+		fatal("migration failed: %v", err)
+	}
+	if len(results.FailedBlocks) > 0 {
+		fatal("migration failed for %d blocks, run it again to retry them", len(results.FailedBlocks))
+	}
+}
+
+func fatal(msg string, args ...any) {
+	fmt.Fprintf(os.Stderr, msg+"\n", args...)
+	os.Exit(1)
+}
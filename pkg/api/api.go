@@ -25,6 +25,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/cortexpb"
 	"github.com/cortexproject/cortex/pkg/distributor"
 	"github.com/cortexproject/cortex/pkg/distributor/distributorpb"
+	"github.com/cortexproject/cortex/pkg/export"
 	frontendv1 "github.com/cortexproject/cortex/pkg/frontend/v1"
 	"github.com/cortexproject/cortex/pkg/frontend/v1/frontendv1pb"
 	frontendv2 "github.com/cortexproject/cortex/pkg/frontend/v2"
@@ -53,6 +54,8 @@ type Config struct {
 	AlertmanagerHTTPPrefix string `yaml:"alertmanager_http_prefix"`
 	PrometheusHTTPPrefix   string `yaml:"prometheus_http_prefix"`
 
+	JWTAuth JWTAuthConfig `yaml:"jwt_auth"`
+
 	// The following configs are injected by the upstream caller.
 	ServerPrefix       string               `yaml:"-"`
 	LegacyHTTPPrefix   string               `yaml:"-"`
@@ -94,6 +97,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.StringVar(&cfg.RequestIdHeader, "api.request-id-header", "", "HTTP header that can be used as request id")
 	f.BoolVar(&cfg.buildInfoEnabled, "api.build-info-enabled", false, "If enabled, build Info API will be served by query frontend or querier.")
 	f.StringVar(&cfg.QuerierDefaultCodec, "api.querier-default-codec", "json", "Choose default codec for querier response serialization. Supports 'json' and 'protobuf'.")
+	cfg.JWTAuth.RegisterFlagsWithPrefix("api.jwt-auth.", f)
 	cfg.RegisterFlagsWithPrefix("", f)
 }
 
@@ -238,17 +242,17 @@ func (a *API) RegisterAlertmanager(am *alertmanager.MultitenantAlertmanager, tar
 	a.RegisterRoute("/multitenant_alertmanager/status", am.GetStatusHandler(), false, "GET")
 	a.RegisterRoute("/multitenant_alertmanager/configs", http.HandlerFunc(am.ListAllConfigs), false, "GET")
 	a.RegisterRoute("/multitenant_alertmanager/ring", http.HandlerFunc(am.RingHandler), false, "GET", "POST")
-	a.RegisterRoute("/multitenant_alertmanager/delete_tenant_config", http.HandlerFunc(am.DeleteUserConfig), true, "POST")
+	a.RegisterRoute("/multitenant_alertmanager/delete_tenant_config", requireAPIGroup(APIGroupAlertmanager, http.HandlerFunc(am.DeleteUserConfig)), true, "POST")
 
 	// UI components lead to a large number of routes to support, utilize a path prefix instead
-	a.RegisterRoutesWithPrefix(a.cfg.AlertmanagerHTTPPrefix, am, true)
+	a.RegisterRoutesWithPrefix(a.cfg.AlertmanagerHTTPPrefix, requireAPIGroup(APIGroupAlertmanager, am), true)
 	level.Debug(a.logger).Log("msg", "api: registering alertmanager", "path_prefix", a.cfg.AlertmanagerHTTPPrefix)
 
 	// MultiTenant Alertmanager Experimental API routes
 	if apiEnabled {
-		a.RegisterRoute("/api/v1/alerts", http.HandlerFunc(am.GetUserConfig), true, "GET")
-		a.RegisterRoute("/api/v1/alerts", http.HandlerFunc(am.SetUserConfig), true, "POST")
-		a.RegisterRoute("/api/v1/alerts", http.HandlerFunc(am.DeleteUserConfig), true, "DELETE")
+		a.RegisterRoute("/api/v1/alerts", requireAPIGroup(APIGroupAlertmanager, http.HandlerFunc(am.GetUserConfig)), true, "GET")
+		a.RegisterRoute("/api/v1/alerts", requireAPIGroup(APIGroupAlertmanager, http.HandlerFunc(am.SetUserConfig)), true, "POST")
+		a.RegisterRoute("/api/v1/alerts", requireAPIGroup(APIGroupAlertmanager, http.HandlerFunc(am.DeleteUserConfig)), true, "DELETE")
 	}
 
 	// If the target is Alertmanager, enable the legacy behaviour. Otherwise only enable
@@ -257,7 +261,7 @@ func (a *API) RegisterAlertmanager(am *alertmanager.MultitenantAlertmanager, tar
 		a.RegisterRoute("/status", am.GetStatusHandler(), false, "GET")
 		// WARNING: If LegacyHTTPPrefix is an empty string, any other paths added after this point will be
 		// silently ignored by the HTTP service. Therefore, this must be the last route to be configured.
-		a.RegisterRoutesWithPrefix(a.cfg.LegacyHTTPPrefix, am, true)
+		a.RegisterRoutesWithPrefix(a.cfg.LegacyHTTPPrefix, requireAPIGroup(APIGroupAlertmanager, am), true)
 	}
 }
 
@@ -279,12 +283,22 @@ func (a *API) RegisterRuntimeConfig(runtimeConfigHandler http.HandlerFunc) {
 	a.RegisterRoute("/runtime_config", runtimeConfigHandler, false, "GET")
 }
 
+// RegisterTenantOverrides registers the endpoints to manage the per-tenant overrides stored in the
+// runtime config bucket. The tenant handler is optional.
+func (a *API) RegisterTenantOverrides(adminHandler, tenantHandler http.HandlerFunc) {
+	a.RegisterRoute("/runtime_config/overrides/{tenant}", adminHandler, false, "GET", "PATCH", "DELETE")
+
+	if tenantHandler != nil {
+		a.RegisterRoute("/api/v1/overrides", requireAPIGroup(APIGroupOverrides, tenantHandler), true, "GET", "PATCH")
+	}
+}
+
 // RegisterDistributor registers the endpoints associated with the distributor.
 func (a *API) RegisterDistributor(d *distributor.Distributor, pushConfig distributor.Config, overrides *validation.Overrides) {
 	distributorpb.RegisterDistributorServer(a.server.GRPC, d)
 
-	a.RegisterRoute("/api/v1/push", push.Handler(pushConfig.RemoteWriteV2Enabled, pushConfig.MaxRecvMsgSize, overrides, a.sourceIPs, a.cfg.wrapDistributorPush(d)), true, "POST")
-	a.RegisterRoute("/api/v1/otlp/v1/metrics", push.OTLPHandler(pushConfig.OTLPMaxRecvMsgSize, overrides, pushConfig.OTLPConfig, a.sourceIPs, a.cfg.wrapDistributorPush(d)), true, "POST")
+	a.RegisterRoute("/api/v1/push", requireAPIGroup(APIGroupWrite, push.Handler(pushConfig.RemoteWriteV2Enabled, pushConfig.MaxRecvMsgSize, overrides, a.sourceIPs, a.cfg.wrapDistributorPush(d))), true, "POST")
+	a.RegisterRoute("/api/v1/otlp/v1/metrics", requireAPIGroup(APIGroupWrite, push.OTLPHandler(pushConfig.OTLPMaxRecvMsgSize, overrides, pushConfig.OTLPConfig, a.sourceIPs, a.cfg.wrapDistributorPush(d))), true, "POST")
 
 	a.indexPage.AddLink(SectionAdminEndpoints, "/distributor/ring", "Distributor Ring Status")
 	a.indexPage.AddLink(SectionAdminEndpoints, "/distributor/all_user_stats", "Usage Statistics")
@@ -295,7 +309,7 @@ func (a *API) RegisterDistributor(d *distributor.Distributor, pushConfig distrib
 	a.RegisterRoute("/distributor/ha_tracker", d.HATracker, false, "GET")
 
 	// Legacy Routes
-	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/push"), push.Handler(pushConfig.RemoteWriteV2Enabled, pushConfig.MaxRecvMsgSize, overrides, a.sourceIPs, a.cfg.wrapDistributorPush(d)), true, "POST")
+	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/push"), requireAPIGroup(APIGroupWrite, push.Handler(pushConfig.RemoteWriteV2Enabled, pushConfig.MaxRecvMsgSize, overrides, a.sourceIPs, a.cfg.wrapDistributorPush(d))), true, "POST")
 	a.RegisterRoute("/all_user_stats", http.HandlerFunc(d.AllUserStatsHandler), false, "GET")
 	a.RegisterRoute("/ha-tracker", d.HATracker, false, "GET")
 }
@@ -328,17 +342,17 @@ func (a *API) RegisterIngester(i Ingester, pushConfig distributor.Config, overri
 	a.RegisterRoute("/ingester/renewTokens", http.HandlerFunc(i.RenewTokenHandler), false, "GET", "POST")
 	a.RegisterRoute("/ingester/all_user_stats", http.HandlerFunc(i.AllUserStatsHandler), false, "GET")
 	a.RegisterRoute("/ingester/mode", http.HandlerFunc(i.ModeHandler), false, "GET", "POST")
-	a.RegisterRoute("/ingester/push", push.Handler(pushConfig.RemoteWriteV2Enabled, pushConfig.MaxRecvMsgSize, overrides, a.sourceIPs, i.Push), true, "POST") // For testing and debugging.
+	a.RegisterRoute("/ingester/push", requireAPIGroup(APIGroupWrite, push.Handler(pushConfig.RemoteWriteV2Enabled, pushConfig.MaxRecvMsgSize, overrides, a.sourceIPs, i.Push)), true, "POST") // For testing and debugging.
 
 	// Legacy Routes
 	a.RegisterRoute("/flush", http.HandlerFunc(i.FlushHandler), false, "GET", "POST")
 	a.RegisterRoute("/shutdown", http.HandlerFunc(i.ShutdownHandler), false, "GET", "POST")
-	a.RegisterRoute("/push", push.Handler(pushConfig.RemoteWriteV2Enabled, pushConfig.MaxRecvMsgSize, overrides, a.sourceIPs, i.Push), true, "POST") // For testing and debugging.
+	a.RegisterRoute("/push", requireAPIGroup(APIGroupWrite, push.Handler(pushConfig.RemoteWriteV2Enabled, pushConfig.MaxRecvMsgSize, overrides, a.sourceIPs, i.Push)), true, "POST") // For testing and debugging.
 }
 
 func (a *API) RegisterTenantDeletion(api *purger.TenantDeletionAPI) {
-	a.RegisterRoute("/purger/delete_tenant", http.HandlerFunc(api.DeleteTenant), true, "POST")
-	a.RegisterRoute("/purger/delete_tenant_status", http.HandlerFunc(api.DeleteTenantStatus), true, "GET")
+	a.RegisterRoute("/purger/delete_tenant", requireAPIGroup(APIGroupAdmin, http.HandlerFunc(api.DeleteTenant)), true, "POST")
+	a.RegisterRoute("/purger/delete_tenant_status", requireAPIGroup(APIGroupAdmin, http.HandlerFunc(api.DeleteTenantStatus)), true, "GET")
 }
 
 // RegisterRuler registers routes associated with the Ruler service.
@@ -347,7 +361,7 @@ func (a *API) RegisterRuler(r *ruler.Ruler) {
 	a.RegisterRoute("/ruler/ring", r, false, "GET", "POST")
 
 	// Administrative API, uses authentication to inform which user's configuration to delete.
-	a.RegisterRoute("/ruler/delete_tenant_config", http.HandlerFunc(r.DeleteTenantConfiguration), true, "POST")
+	a.RegisterRoute("/ruler/delete_tenant_config", requireAPIGroup(APIGroupRules, http.HandlerFunc(r.DeleteTenantConfiguration)), true, "POST")
 
 	// Legacy Ring Route
 	a.RegisterRoute("/ruler_ring", r, false, "GET", "POST")
@@ -361,28 +375,35 @@ func (a *API) RegisterRuler(r *ruler.Ruler) {
 // RegisterRulerAPI registers routes associated with the Ruler API
 func (a *API) RegisterRulerAPI(r *ruler.API) {
 	// Prometheus Rule API Routes
-	a.RegisterRoute(path.Join(a.cfg.PrometheusHTTPPrefix, "/api/v1/rules"), http.HandlerFunc(r.PrometheusRules), true, "GET")
-	a.RegisterRoute(path.Join(a.cfg.PrometheusHTTPPrefix, "/api/v1/alerts"), http.HandlerFunc(r.PrometheusAlerts), true, "GET")
+	a.RegisterRoute(path.Join(a.cfg.PrometheusHTTPPrefix, "/api/v1/rules"), requireAPIGroup(APIGroupRules, http.HandlerFunc(r.PrometheusRules)), true, "GET")
+	a.RegisterRoute(path.Join(a.cfg.PrometheusHTTPPrefix, "/api/v1/alerts"), requireAPIGroup(APIGroupRules, http.HandlerFunc(r.PrometheusAlerts)), true, "GET")
 
 	// Ruler API Routes
-	a.RegisterRoute("/api/v1/rules", http.HandlerFunc(r.ListRules), true, "GET")
-	a.RegisterRoute("/api/v1/rules/{namespace}", http.HandlerFunc(r.ListRules), true, "GET")
-	a.RegisterRoute("/api/v1/rules/{namespace}/{groupName}", http.HandlerFunc(r.GetRuleGroup), true, "GET")
-	a.RegisterRoute("/api/v1/rules/{namespace}", http.HandlerFunc(r.CreateRuleGroup), true, "POST")
-	a.RegisterRoute("/api/v1/rules/{namespace}/{groupName}", http.HandlerFunc(r.DeleteRuleGroup), true, "DELETE")
-	a.RegisterRoute("/api/v1/rules/{namespace}", http.HandlerFunc(r.DeleteNamespace), true, "DELETE")
+	a.RegisterRoute("/api/v1/rules", requireAPIGroup(APIGroupRules, http.HandlerFunc(r.ListRules)), true, "GET")
+	a.RegisterRoute("/api/v1/rules/{namespace}", requireAPIGroup(APIGroupRules, http.HandlerFunc(r.ListRules)), true, "GET")
+	a.RegisterRoute("/api/v1/rules/{namespace}/{groupName}", requireAPIGroup(APIGroupRules, http.HandlerFunc(r.GetRuleGroup)), true, "GET")
+	a.RegisterRoute("/api/v1/rules/{namespace}", requireAPIGroup(APIGroupRules, http.HandlerFunc(r.CreateRuleGroup)), true, "POST")
+	a.RegisterRoute("/api/v1/rules/{namespace}/{groupName}", requireAPIGroup(APIGroupRules, http.HandlerFunc(r.DeleteRuleGroup)), true, "DELETE")
+	a.RegisterRoute("/api/v1/rules/{namespace}", requireAPIGroup(APIGroupRules, http.HandlerFunc(r.DeleteNamespace)), true, "DELETE")
+
+	// SLO API Routes
+	a.RegisterRoute("/api/v1/slos", requireAPIGroup(APIGroupRules, http.HandlerFunc(r.ListSLOs)), true, "GET")
+	a.RegisterRoute("/api/v1/slos", requireAPIGroup(APIGroupRules, http.HandlerFunc(r.CreateSLO)), true, "POST")
+	a.RegisterRoute("/api/v1/slos/{name}", requireAPIGroup(APIGroupRules, http.HandlerFunc(r.GetSLO)), true, "GET")
+	a.RegisterRoute("/api/v1/slos/{name}", requireAPIGroup(APIGroupRules, http.HandlerFunc(r.DeleteSLO)), true, "DELETE")
+	a.RegisterRoute("/api/v1/slos/{name}/error_budget", requireAPIGroup(APIGroupRules, http.HandlerFunc(r.SLOErrorBudget)), true, "GET")
 
 	// Legacy Prometheus Rule API Routes
-	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/api/v1/rules"), http.HandlerFunc(r.PrometheusRules), true, "GET")
-	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/api/v1/alerts"), http.HandlerFunc(r.PrometheusAlerts), true, "GET")
+	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/api/v1/rules"), requireAPIGroup(APIGroupRules, http.HandlerFunc(r.PrometheusRules)), true, "GET")
+	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/api/v1/alerts"), requireAPIGroup(APIGroupRules, http.HandlerFunc(r.PrometheusAlerts)), true, "GET")
 
 	// Legacy Ruler API Routes
-	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/rules"), http.HandlerFunc(r.ListRules), true, "GET")
-	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/rules/{namespace}"), http.HandlerFunc(r.ListRules), true, "GET")
-	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/rules/{namespace}/{groupName}"), http.HandlerFunc(r.GetRuleGroup), true, "GET")
-	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/rules/{namespace}"), http.HandlerFunc(r.CreateRuleGroup), true, "POST")
-	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/rules/{namespace}/{groupName}"), http.HandlerFunc(r.DeleteRuleGroup), true, "DELETE")
-	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/rules/{namespace}"), http.HandlerFunc(r.DeleteNamespace), true, "DELETE")
+	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/rules"), requireAPIGroup(APIGroupRules, http.HandlerFunc(r.ListRules)), true, "GET")
+	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/rules/{namespace}"), requireAPIGroup(APIGroupRules, http.HandlerFunc(r.ListRules)), true, "GET")
+	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/rules/{namespace}/{groupName}"), requireAPIGroup(APIGroupRules, http.HandlerFunc(r.GetRuleGroup)), true, "GET")
+	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/rules/{namespace}"), requireAPIGroup(APIGroupRules, http.HandlerFunc(r.CreateRuleGroup)), true, "POST")
+	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/rules/{namespace}/{groupName}"), requireAPIGroup(APIGroupRules, http.HandlerFunc(r.DeleteRuleGroup)), true, "DELETE")
+	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/rules/{namespace}"), requireAPIGroup(APIGroupRules, http.HandlerFunc(r.DeleteNamespace)), true, "DELETE")
 }
 
 // RegisterRing registers the ring UI page associated with the distributor for writes.
@@ -394,12 +415,14 @@ func (a *API) RegisterRing(r *ring.Ring) {
 	a.RegisterRoute("/ring", r, false, "GET", "POST")
 }
 
-// RegisterStoreGateway registers the ring UI page associated with the store-gateway.
+// RegisterStoreGateway registers the ring and blocks UI pages associated with the store-gateway.
 func (a *API) RegisterStoreGateway(s *storegateway.StoreGateway) {
 	storegatewaypb.RegisterStoreGatewayServer(a.server.GRPC, s)
 
 	a.indexPage.AddLink(SectionAdminEndpoints, "/store-gateway/ring", "Store Gateway Ring")
 	a.RegisterRoute("/store-gateway/ring", http.HandlerFunc(s.RingHandler), false, "GET", "POST")
+	a.indexPage.AddLink(SectionAdminEndpoints, "/store-gateway/blocks", "Store Gateway Blocks")
+	a.RegisterRoute("/store-gateway/blocks", http.HandlerFunc(s.BlocksHandler), false, "GET")
 }
 
 // RegisterCompactor registers the ring UI page associated with the compactor.
@@ -420,17 +443,46 @@ func (a *API) RegisterQueryable(
 	distributor Distributor,
 ) {
 	// these routes are always registered to the default server
-	a.RegisterRoute("/api/v1/user_stats", http.HandlerFunc(distributor.UserStatsHandler), true, "GET")
+	a.RegisterRoute("/api/v1/user_stats", requireAPIGroup(APIGroupRead, http.HandlerFunc(distributor.UserStatsHandler)), true, "GET")
+
+	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/user_stats"), requireAPIGroup(APIGroupRead, http.HandlerFunc(distributor.UserStatsHandler)), true, "GET")
+}
+
+// RegisterOutOfOrderSeries registers the report of the series producing the most out-of-order samples.
+func (a *API) RegisterOutOfOrderSeries(handler http.Handler) {
+	a.RegisterRoute("/api/v1/out_of_order_series", requireAPIGroup(APIGroupRead, handler), true, "GET")
+}
+
+// RegisterUnusedMetrics registers the report of the metrics ingested but not queried.
+func (a *API) RegisterUnusedMetrics(handler http.Handler) {
+	a.RegisterRoute("/api/v1/unused_metrics", requireAPIGroup(APIGroupRead, handler), true, "GET")
+}
+
+// RegisterUsage registers the report of the resources used by a tenant.
+func (a *API) RegisterUsage(handler http.Handler) {
+	a.RegisterRoute("/api/v1/usage", requireAPIGroup(APIGroupRead, handler), true, "GET")
+}
+
+// RegisterParquetSQL registers the SQL queries over the parquet files.
+func (a *API) RegisterParquetSQL(handler http.Handler) {
+	a.RegisterRoute("/api/v1/sql", requireAPIGroup(APIGroupRead, handler), true, "GET", "POST")
+}
 
-	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/user_stats"), http.HandlerFunc(distributor.UserStatsHandler), true, "GET")
+// RegisterExport registers the export jobs API.
+func (a *API) RegisterExport(e *export.Exporter) {
+	a.RegisterRoute("/api/v1/exports", requireAPIGroup(APIGroupRead, http.HandlerFunc(e.SubmitHandler)), true, "POST")
+	a.RegisterRoute("/api/v1/exports", requireAPIGroup(APIGroupRead, http.HandlerFunc(e.ListHandler)), true, "GET")
+	a.RegisterRoute("/api/v1/exports/{id}", requireAPIGroup(APIGroupRead, http.HandlerFunc(e.StatusHandler)), true, "GET")
+	a.RegisterRoute("/api/v1/exports/{id}", requireAPIGroup(APIGroupRead, http.HandlerFunc(e.DeleteHandler)), true, "DELETE")
+	a.RegisterRoute("/api/v1/exports/{id}/files/{file:.+}", requireAPIGroup(APIGroupRead, http.HandlerFunc(e.FileHandler)), true, "GET")
 }
 
 // RegisterQueryAPI registers the Prometheus API routes with the provided handler.
 func (a *API) RegisterQueryAPI(handler http.Handler) {
-	hf := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	hf := requireAPIGroup(APIGroupRead, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		httputil.SetCORS(w, a.corsOrigin, r)
 		handler.ServeHTTP(w, r)
-	})
+	}))
 
 	a.RegisterRoute(path.Join(a.cfg.PrometheusHTTPPrefix, "/api/v1/read"), hf, true, "POST")
 	a.RegisterRoute(path.Join(a.cfg.PrometheusHTTPPrefix, "/api/v1/query"), hf, true, "GET", "POST")
@@ -457,8 +509,8 @@ func (a *API) RegisterQueryAPI(handler http.Handler) {
 
 	if a.cfg.buildInfoEnabled {
 		infoHandler := &buildInfoHandler{logger: a.logger}
-		a.RegisterRoute(path.Join(a.cfg.PrometheusHTTPPrefix, "/api/v1/status/buildinfo"), infoHandler, true, "GET")
-		a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/api/v1/status/buildinfo"), infoHandler, true, "GET")
+		a.RegisterRoute(path.Join(a.cfg.PrometheusHTTPPrefix, "/api/v1/status/buildinfo"), requireAPIGroup(APIGroupRead, infoHandler), true, "GET")
+		a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/api/v1/status/buildinfo"), requireAPIGroup(APIGroupRead, infoHandler), true, "GET")
 	}
 }
 
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+)
+
+const (
+	// jwksMinReloadPeriod is the minimum period between two reloads of the JWKS triggered
+	// by a token signed with an unknown key, so that such tokens can't flood the JWKS URL.
+	jwksMinReloadPeriod = time.Minute
+
+	jwksFetchTimeout = 10 * time.Second
+	jwksMaxSize      = 1 << 20
+)
+
+// jsonWebKey is a public key of a JSON Web Key Set, as defined by RFC 7517.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+
+	// RSA keys.
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC and OKP keys.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS parses a JSON Web Key Set and returns its signature verification keys by ID.
+// Keys of unsupported types are skipped.
+func parseJWKS(data []byte) (map[string]any, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, errors.Wrap(err, "invalid JWKS")
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key %d (kid: %q)", i, k.Kid)
+		}
+		if key == nil {
+			continue
+		}
+		if _, ok := keys[k.Kid]; ok {
+			return nil, fmt.Errorf("duplicate key ID %q", k.Kid)
+		}
+		keys[k.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("the JWKS contains no signature verification key")
+	}
+	return keys, nil
+}
+
+// publicKey returns the public key, or nil if the key type is not supported.
+func (k jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, errors.Wrap(err, "modulus")
+		}
+		e, err := decodeJWKInt(k.E)
+		if err != nil {
+			return nil, errors.Wrap(err, "exponent")
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeJWKInt(k.X)
+		if err != nil {
+			return nil, errors.Wrap(err, "x coordinate")
+		}
+		y, err := decodeJWKInt(k.Y)
+		if err != nil {
+			return nil, errors.Wrap(err, "y coordinate")
+		}
+		if !curve.IsOnCurve(x, y) { //nolint:staticcheck
+			return nil, errors.New("point not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, errors.Wrap(err, "public key")
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid public key size")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, nil
+}
+
+func decodeJWKInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, errors.New("missing value")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// jwksKeySet holds the keys of a JWKS loaded from a file or URL. The keys are reloaded
+// once older than the refresh period, or when a token is signed with an unknown key.
+type jwksKeySet struct {
+	file          string
+	url           string
+	refreshPeriod time.Duration
+	client        *http.Client
+	logger        log.Logger
+	now           func() time.Time
+
+	mtx        sync.Mutex
+	keys       map[string]any
+	lastReload time.Time
+}
+
+func newJWKSKeySet(file, url string, refreshPeriod time.Duration, logger log.Logger) (*jwksKeySet, error) {
+	s := &jwksKeySet{
+		file:          file,
+		url:           url,
+		refreshPeriod: refreshPeriod,
+		client:        &http.Client{Timeout: jwksFetchTimeout},
+		logger:        logger,
+		now:           time.Now,
+	}
+
+	keys, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	s.keys = keys
+	s.lastReload = s.now()
+	return s, nil
+}
+
+func (s *jwksKeySet) load() (map[string]any, error) {
+	if s.file != "" {
+		data, err := os.ReadFile(s.file)
+		if err != nil {
+			return nil, errors.Wrap(err, "read JWKS file")
+		}
+		return parseJWKS(data)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetch JWKS")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch JWKS: unexpected status code %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, jwksMaxSize))
+	if err != nil {
+		return nil, errors.Wrap(err, "fetch JWKS")
+	}
+	return parseJWKS(data)
+}
+
+// keyfunc returns the key the token has been signed with, or all the keys if the token
+// doesn't have a key ID.
+func (s *jwksKeySet) keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := s.now()
+	_, known := s.keys[kid]
+	if (s.refreshPeriod > 0 && now.Sub(s.lastReload) >= s.refreshPeriod) || (kid != "" && !known && now.Sub(s.lastReload) >= jwksMinReloadPeriod) {
+		s.lastReload = now
+		if keys, err := s.load(); err != nil {
+			level.Warn(s.logger).Log("msg", "failed to reload the JWKS, keeping the previous keys", "err", err)
+		} else {
+			s.keys = keys
+		}
+	}
+
+	if kid != "" {
+		key, ok := s.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key ID %q", kid)
+		}
+		return key, nil
+	}
+
+	set := jwt.VerificationKeySet{}
+	for _, key := range s.keys {
+		set.Keys = append(set.Keys, key)
+	}
+	return set, nil
+}
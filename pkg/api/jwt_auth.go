@@ -0,0 +1,380 @@
+package api
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	"github.com/weaveworks/common/middleware"
+	"github.com/weaveworks/common/server"
+	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/requestmeta"
+	"github.com/cortexproject/cortex/pkg/util/users"
+)
+
+// API groups a JWT can be restricted to.
+const (
+	APIGroupWrite        = "write"
+	APIGroupRead         = "read"
+	APIGroupRules        = "rules"
+	APIGroupAlertmanager = "alertmanager"
+	APIGroupOverrides    = "overrides"
+	APIGroupAdmin        = "admin"
+)
+
+var (
+	apiGroups = []string{APIGroupWrite, APIGroupRead, APIGroupRules, APIGroupAlertmanager, APIGroupOverrides, APIGroupAdmin}
+
+	errJWTAuthMissingJWKS       = errors.New("either the JWKS file or URL must be set when JWT authentication is enabled")
+	errJWTAuthBothJWKS          = errors.New("the JWKS file and URL can't be both set")
+	errJWTAuthMissingClaim      = errors.New("the tenant claim must be set when JWT authentication is enabled")
+	errMissingBearerToken       = errors.New("missing bearer token")
+	errMissingTenantClaim       = errors.New("the token has no valid tenant claim")
+	errInvalidAccessPolicyClaim = errors.New("the token access policy claim must be a single policy name")
+	errTenantNotAllowed         = errors.New("the token doesn't grant access to the requested tenant")
+	errAPIGroupNotAllowed       = errors.New("the token doesn't grant access to this API")
+	errJWTAuthRequiresAuth      = errors.New("JWT authentication requires authentication to be enabled")
+)
+
+// JWTAuthConfig configures the authentication of the requests with JSON Web Tokens.
+type JWTAuthConfig struct {
+	Enabled           bool                   `yaml:"enabled"`
+	JWKSFile          string                 `yaml:"jwks_file"`
+	JWKSURL           string                 `yaml:"jwks_url"`
+	JWKSRefreshPeriod time.Duration          `yaml:"jwks_refresh_period"`
+	Issuer            string                 `yaml:"issuer"`
+	Audience          string                 `yaml:"audience"`
+	TenantClaim       string                 `yaml:"tenant_claim"`
+	APIGroupsClaim    string                 `yaml:"api_groups_claim"`
+	AccessPolicyClaim string                 `yaml:"access_policy_claim"`
+	GRPCMethods       flagext.StringSliceCSV `yaml:"grpc_methods"`
+}
+
+// RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet.
+func (cfg *JWTAuthConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	cfg.GRPCMethods = []string{"/distributor.Distributor/Push"}
+
+	f.BoolVar(&cfg.Enabled, prefix+"enabled", false, "[Experimental] If enabled, the requests are authenticated with a JSON Web Token passed in the Authorization header as a bearer token, and the tenant is taken from the token claims instead of the X-Scope-OrgID header.")
+	f.StringVar(&cfg.JWKSFile, prefix+"jwks-file", "", "Path to the JSON Web Key Set used to verify the tokens signature.")
+	f.StringVar(&cfg.JWKSURL, prefix+"jwks-url", "", "URL of the JSON Web Key Set used to verify the tokens signature, such as the jwks_uri of an OpenID Connect provider.")
+	f.DurationVar(&cfg.JWKSRefreshPeriod, prefix+"jwks-refresh-period", time.Hour, "How frequently the JSON Web Key Set is reloaded. It is also reloaded, at most once a minute, when a token is signed with an unknown key. 0 to only reload on unknown keys.")
+	f.StringVar(&cfg.Issuer, prefix+"issuer", "", "If set, the issuer (iss claim) the tokens must have.")
+	f.StringVar(&cfg.Audience, prefix+"audience", "", "If set, the audience (aud claim) the tokens must have.")
+	f.StringVar(&cfg.TenantClaim, prefix+"tenant-claim", "tenant", "Claim holding the tenants the token grants access to, either as a string or an array of strings. Nested claims are separated by dots. When the token grants access to multiple tenants, the request can select some of them with the X-Scope-OrgID header, otherwise all of them are queried.")
+	f.StringVar(&cfg.APIGroupsClaim, prefix+"api-groups-claim", "", "If set, claim holding the API groups the token grants access to, as an array of strings. Supported groups are: "+strings.Join(apiGroups, ", ")+". Tokens without this claim are denied access to all the API groups.")
+	f.StringVar(&cfg.AccessPolicyClaim, prefix+"access-policy-claim", "", "If set, claim holding the name of the query access policy of the HTTP requests, as a string. It replaces the X-Cortex-Access-Policy header sent by the client, which is ignored. Tokens without this claim are only restricted by the mandatory query access policy of the tenant, if any.")
+	f.Var(&cfg.GRPCMethods, prefix+"grpc-methods", "Comma-separated list of the gRPC methods authenticated with a JSON Web Token. The other methods keep being authenticated with the X-Scope-OrgID metadata set by the Cortex components.")
+}
+
+// Validate the config.
+func (cfg *JWTAuthConfig) Validate(authEnabled bool) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if !authEnabled {
+		return errJWTAuthRequiresAuth
+	}
+	if cfg.JWKSFile == "" && cfg.JWKSURL == "" {
+		return errJWTAuthMissingJWKS
+	}
+	if cfg.JWKSFile != "" && cfg.JWKSURL != "" {
+		return errJWTAuthBothJWKS
+	}
+	if cfg.TenantClaim == "" {
+		return errJWTAuthMissingClaim
+	}
+	return nil
+}
+
+// JWTAuthenticator authenticates the requests with JSON Web Tokens.
+type JWTAuthenticator struct {
+	cfg    JWTAuthConfig
+	keys   *jwksKeySet
+	parser *jwt.Parser
+}
+
+// NewJWTAuthenticator loads the JSON Web Key Set and returns an authenticator.
+func NewJWTAuthenticator(cfg JWTAuthConfig, logger log.Logger) (*JWTAuthenticator, error) {
+	keys, err := newJWKSKeySet(cfg.JWKSFile, cfg.JWKSURL, cfg.JWKSRefreshPeriod, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &JWTAuthenticator{
+		cfg:    cfg,
+		keys:   keys,
+		parser: jwt.NewParser(opts...),
+	}, nil
+}
+
+// jwtIdentity is what a verified token grants access to.
+type jwtIdentity struct {
+	tenants []string
+	// groups is nil if the token is not restricted to some API groups.
+	groups []string
+	// accessPolicy is the query access policy of the token, if any.
+	accessPolicy string
+}
+
+// authenticate verifies the token and returns the tenant ID to use for the request, built
+// from the tenants granted by the token and the ones requested, if any.
+func (a *JWTAuthenticator) authenticate(token, requestedTenants string) (string, jwtIdentity, error) {
+	claims := jwt.MapClaims{}
+	if _, err := a.parser.ParseWithClaims(token, claims, a.keys.keyfunc); err != nil {
+		return "", jwtIdentity{}, errors.Wrap(err, "invalid token")
+	}
+
+	id := jwtIdentity{}
+	tenants, ok := stringsClaim(claims, a.cfg.TenantClaim)
+	if !ok || len(tenants) == 0 {
+		return "", id, errMissingTenantClaim
+	}
+	for _, tenant := range tenants {
+		if err := users.ValidTenantID(tenant); err != nil {
+			return "", id, errMissingTenantClaim
+		}
+	}
+	id.tenants = tenants
+
+	if a.cfg.APIGroupsClaim != "" {
+		groups, _ := stringsClaim(claims, a.cfg.APIGroupsClaim)
+		id.groups = append([]string{}, groups...)
+	}
+
+	if a.cfg.AccessPolicyClaim != "" {
+		policies, ok := stringsClaim(claims, a.cfg.AccessPolicyClaim)
+		if ok && len(policies) > 1 {
+			return "", id, errInvalidAccessPolicyClaim
+		} else if ok && len(policies) == 1 {
+			id.accessPolicy = policies[0]
+		}
+	}
+
+	if requestedTenants == "" {
+		return users.JoinTenantIDs(users.NormalizeTenantIDs(tenants)), id, nil
+	}
+	for _, tenant := range strings.Split(requestedTenants, "|") {
+		if !slices.Contains(tenants, tenant) {
+			return "", id, errTenantNotAllowed
+		}
+	}
+	return requestedTenants, id, nil
+}
+
+// stringsClaim returns the claim at the given dot-separated path, which must be a string
+// or an array of strings.
+func stringsClaim(claims jwt.MapClaims, path string) ([]string, bool) {
+	var value any = map[string]any(claims)
+	for _, name := range strings.Split(path, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[name]; !ok {
+			return nil, false
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return []string{v}, true
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, s)
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+type apiGroupsContextKey struct{}
+
+func contextWithAPIGroups(ctx context.Context, groups []string) context.Context {
+	if groups == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, apiGroupsContextKey{}, groups)
+}
+
+// apiGroupAllowed returns whether the request is allowed to access the API group. Requests
+// which have not been authenticated with a token restricted to some API groups are allowed.
+func apiGroupAllowed(ctx context.Context, group string) bool {
+	groups, ok := ctx.Value(apiGroupsContextKey{}).([]string)
+	return !ok || slices.Contains(groups, group)
+}
+
+// requireAPIGroup wraps an authenticated handler, rejecting the requests not allowed to
+// access the API group.
+func requireAPIGroup(group string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !apiGroupAllowed(r.Context(), group) {
+			http.Error(w, errAPIGroupNotAllowed.Error(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Wrap implements middleware.Interface.
+func (a *JWTAuthenticator) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r.Header.Get("Authorization"))
+		if !ok {
+			http.Error(w, errMissingBearerToken.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		tenantID, id, err := a.authenticate(token, r.Header.Get(user.OrgIDHeaderName))
+		if errors.Is(err, errTenantNotAllowed) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		// The header is also set because some components forward the request as is.
+		r.Header.Set(user.OrgIDHeaderName, tenantID)
+		ctx := contextWithAPIGroups(user.InjectOrgID(r.Context(), tenantID), id.groups)
+
+		// The access policy is bound to the token, so the one selected by the client is ignored.
+		if a.cfg.AccessPolicyClaim != "" {
+			r.Header.Del(requestmeta.AccessPolicyKey)
+			if id.accessPolicy != "" {
+				r.Header.Set(requestmeta.AccessPolicyKey, id.accessPolicy)
+			}
+			ctx = requestmeta.ContextWithAccessPolicy(ctx, id.accessPolicy)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func bearerToken(header string) (string, bool) {
+	const prefix = "Bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(header[len(prefix):]), true
+}
+
+// authenticateGRPC authenticates a gRPC request, requiring the token to grant access to
+// the API group.
+func (a *JWTAuthenticator) authenticateGRPC(ctx context.Context, group string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var token string
+	if values := md.Get("authorization"); len(values) > 0 {
+		token, _ = bearerToken(values[0])
+	}
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, errMissingBearerToken.Error())
+	}
+
+	var requestedTenants string
+	if values := md.Get(strings.ToLower(user.OrgIDHeaderName)); len(values) > 0 {
+		requestedTenants = values[0]
+	}
+
+	tenantID, id, err := a.authenticate(token, requestedTenants)
+	if errors.Is(err, errTenantNotAllowed) {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	} else if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if id.groups != nil && !slices.Contains(id.groups, group) {
+		return nil, status.Error(codes.PermissionDenied, errAPIGroupNotAllowed.Error())
+	}
+	return user.InjectOrgID(ctx, tenantID), nil
+}
+
+// grpcMethodGroup returns the API group of the gRPC methods authenticated with a JWT.
+func grpcMethodGroup(method string) string {
+	if strings.HasPrefix(method, "/distributor.") {
+		return APIGroupWrite
+	}
+	return APIGroupRead
+}
+
+// SetupJWTAuthMiddleware configures the gRPC server to authenticate the configured methods
+// with a JWT, and returns the HTTP authentication middleware. The other gRPC methods are
+// authenticated with the X-Scope-OrgID metadata, except the ones in noGRPCAuthOn.
+func SetupJWTAuthMiddleware(config *server.Config, a *JWTAuthenticator, noGRPCAuthOn []string) middleware.Interface {
+	ignoredMethods := map[string]bool{}
+	for _, m := range noGRPCAuthOn {
+		ignoredMethods[m] = true
+	}
+	jwtMethods := map[string]bool{}
+	for _, m := range a.cfg.GRPCMethods {
+		jwtMethods[m] = true
+	}
+
+	config.GRPCMiddleware = append(config.GRPCMiddleware, func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		switch {
+		case ignoredMethods[info.FullMethod]:
+			return handler(ctx, req)
+		case jwtMethods[info.FullMethod]:
+			ctx, err := a.authenticateGRPC(ctx, grpcMethodGroup(info.FullMethod))
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		default:
+			return middleware.ServerUserHeaderInterceptor(ctx, req, info, handler)
+		}
+	})
+
+	config.GRPCStreamMiddleware = append(config.GRPCStreamMiddleware, func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		switch {
+		case ignoredMethods[info.FullMethod]:
+			return handler(srv, ss)
+		case jwtMethods[info.FullMethod]:
+			ctx, err := a.authenticateGRPC(ss.Context(), grpcMethodGroup(info.FullMethod))
+			if err != nil {
+				return err
+			}
+			return handler(srv, jwtServerStream{ctx: ctx, ServerStream: ss})
+		default:
+			return middleware.StreamServerUserHeaderInterceptor(srv, ss, info, handler)
+		}
+	})
+
+	return a
+}
+
+type jwtServerStream struct {
+	ctx context.Context
+	grpc.ServerStream
+}
+
+func (ss jwtServerStream) Context() context.Context {
+	return ss.ctx
+}
@@ -0,0 +1,482 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/server"
+	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/cortexproject/cortex/pkg/util/requestmeta"
+)
+
+func rsaJWK(t *testing.T, kid string, key *rsa.PrivateKey) map[string]string {
+	t.Helper()
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(t *testing.T, kid string, key *ecdsa.PrivateKey) map[string]string {
+	t.Helper()
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func writeJWKS(t *testing.T, keys ...map[string]string) string {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(file, data, 0o600))
+	return file
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+	if _, ok := claims["exp"]; !ok {
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+	}
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestJWTAuthConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		cfg         JWTAuthConfig
+		authEnabled bool
+		expected    error
+	}{
+		"should pass when disabled": {},
+		"should pass with a JWKS file": {
+			cfg:         JWTAuthConfig{Enabled: true, JWKSFile: "jwks.json", TenantClaim: "tenant"},
+			authEnabled: true,
+		},
+		"should fail when auth is disabled": {
+			cfg:      JWTAuthConfig{Enabled: true, JWKSFile: "jwks.json", TenantClaim: "tenant"},
+			expected: errJWTAuthRequiresAuth,
+		},
+		"should fail without JWKS": {
+			cfg:         JWTAuthConfig{Enabled: true, TenantClaim: "tenant"},
+			authEnabled: true,
+			expected:    errJWTAuthMissingJWKS,
+		},
+		"should fail with both a JWKS file and URL": {
+			cfg:         JWTAuthConfig{Enabled: true, JWKSFile: "jwks.json", JWKSURL: "http://localhost/jwks", TenantClaim: "tenant"},
+			authEnabled: true,
+			expected:    errJWTAuthBothJWKS,
+		},
+		"should fail without tenant claim": {
+			cfg:         JWTAuthConfig{Enabled: true, JWKSFile: "jwks.json"},
+			authEnabled: true,
+			expected:    errJWTAuthMissingClaim,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testData.expected, testData.cfg.Validate(testData.authEnabled))
+		})
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		jwks         string
+		expectedKeys []string
+		expectedErr  string
+	}{
+		"should skip unsupported and encryption keys": {
+			jwks:         `{"keys": [{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"}, {"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}, {"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"}]}`,
+			expectedKeys: []string{"ed"},
+		},
+		"should fail without signature keys": {
+			jwks:        `{"keys": []}`,
+			expectedErr: "the JWKS contains no signature verification key",
+		},
+		"should fail on invalid EC point": {
+			jwks:        `{"keys": [{"kty": "EC", "kid": "ec", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`,
+			expectedErr: "point not on curve",
+		},
+		"should fail on duplicate key ID": {
+			jwks: func() string {
+				k := rsaJWK(t, "rsa", rsaKey)
+				data, _ := json.Marshal(map[string]any{"keys": []any{k, k}})
+				return string(data)
+			}(),
+			expectedErr: `duplicate key ID "rsa"`,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			keys, err := parseJWKS([]byte(testData.jwks))
+			if testData.expectedErr != "" {
+				require.ErrorContains(t, err, testData.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var ids []string
+			for id := range keys {
+				ids = append(ids, id)
+			}
+			assert.ElementsMatch(t, testData.expectedKeys, ids)
+		})
+	}
+}
+
+func TestJWTAuthenticator_HTTP(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	cfg := JWTAuthConfig{
+		Enabled:        true,
+		JWKSFile:       writeJWKS(t, rsaJWK(t, "rsa", rsaKey), ecJWK(t, "ec", ecKey)),
+		Issuer:         "https://issuer",
+		TenantClaim:    "cortex.tenants",
+		APIGroupsClaim: "cortex.groups",
+	}
+	authenticator, err := NewJWTAuthenticator(cfg, log.NewNopLogger())
+	require.NoError(t, err)
+
+	claims := func(tenants any, groups ...any) jwt.MapClaims {
+		cortex := map[string]any{"tenants": tenants}
+		if len(groups) > 0 {
+			cortex["groups"] = groups
+		}
+		return jwt.MapClaims{"iss": "https://issuer", "cortex": cortex}
+	}
+
+	tests := map[string]struct {
+		token           string
+		orgID           string
+		group           string
+		expectedStatus  int
+		expectedTenants string
+	}{
+		"should fail without token": {
+			group:          APIGroupRead,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"should pass with a RSA signed token": {
+			token:           signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, claims("user-1", APIGroupRead)),
+			group:           APIGroupRead,
+			expectedStatus:  http.StatusOK,
+			expectedTenants: "user-1",
+		},
+		"should pass with an ECDSA signed token without key ID": {
+			token:           signToken(t, jwt.SigningMethodES256, "", ecKey, claims("user-1", APIGroupRead)),
+			group:           APIGroupRead,
+			expectedStatus:  http.StatusOK,
+			expectedTenants: "user-1",
+		},
+		"should query all the tenants of the token": {
+			token:           signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, claims([]any{"user-2", "user-1"}, APIGroupRead)),
+			group:           APIGroupRead,
+			expectedStatus:  http.StatusOK,
+			expectedTenants: "user-1|user-2",
+		},
+		"should query the requested tenants": {
+			token:           signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, claims([]any{"user-1", "user-2", "user-3"}, APIGroupRead)),
+			orgID:           "user-1|user-3",
+			group:           APIGroupRead,
+			expectedStatus:  http.StatusOK,
+			expectedTenants: "user-1|user-3",
+		},
+		"should fail when requesting a tenant not in the token": {
+			token:          signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, claims("user-1", APIGroupRead)),
+			orgID:          "user-2",
+			group:          APIGroupRead,
+			expectedStatus: http.StatusForbidden,
+		},
+		"should fail when the API group is not in the token": {
+			token:          signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, claims("user-1", APIGroupRead)),
+			group:          APIGroupWrite,
+			expectedStatus: http.StatusForbidden,
+		},
+		"should fail when the token has no API groups": {
+			token:          signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, claims("user-1")),
+			group:          APIGroupRead,
+			expectedStatus: http.StatusForbidden,
+		},
+		"should fail without tenant claim": {
+			token:          signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, jwt.MapClaims{"iss": "https://issuer"}),
+			group:          APIGroupRead,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"should fail with an invalid tenant": {
+			token:          signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, claims("..", APIGroupRead)),
+			group:          APIGroupRead,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"should fail with an expired token": {
+			token:          signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, jwt.MapClaims{"iss": "https://issuer", "cortex": map[string]any{"tenants": "user-1"}, "exp": time.Now().Add(-time.Hour).Unix()}),
+			group:          APIGroupRead,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"should fail with another issuer": {
+			token:          signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, jwt.MapClaims{"iss": "https://other", "cortex": map[string]any{"tenants": "user-1"}}),
+			group:          APIGroupRead,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"should fail with a token signed by an unknown key": {
+			token:          signToken(t, jwt.SigningMethodRS256, "rsa", otherKey, claims("user-1", APIGroupRead)),
+			group:          APIGroupRead,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"should fail with a HMAC signed token": {
+			token:          signToken(t, jwt.SigningMethodHS256, "", []byte("secret"), claims("user-1", APIGroupRead)),
+			group:          APIGroupRead,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			var tenants string
+			handler := authenticator.Wrap(requireAPIGroup(testData.group, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tenants, _ = user.ExtractOrgID(r.Context())
+				assert.Equal(t, tenants, r.Header.Get(user.OrgIDHeaderName))
+			})))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/query", nil)
+			if testData.token != "" {
+				req.Header.Set("Authorization", "Bearer "+testData.token)
+			}
+			if testData.orgID != "" {
+				req.Header.Set(user.OrgIDHeaderName, testData.orgID)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, testData.expectedStatus, rec.Code, rec.Body.String())
+			assert.Equal(t, testData.expectedTenants, tenants)
+		})
+	}
+}
+
+func TestJWTAuthenticator_AccessPolicyClaim(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	authenticator, err := NewJWTAuthenticator(JWTAuthConfig{
+		Enabled:           true,
+		JWKSFile:          writeJWKS(t, rsaJWK(t, "rsa", key)),
+		TenantClaim:       "tenant",
+		AccessPolicyClaim: "cortex.policy",
+	}, log.NewNopLogger())
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		policy         any
+		headerPolicy   string
+		expectedStatus int
+		expectedPolicy string
+	}{
+		"should use the policy of the token": {
+			policy:         "team-a",
+			expectedStatus: http.StatusOK,
+			expectedPolicy: "team-a",
+		},
+		"should ignore the policy selected by the client": {
+			policy:         "team-a",
+			headerPolicy:   "team-b",
+			expectedStatus: http.StatusOK,
+			expectedPolicy: "team-a",
+		},
+		"should ignore the policy selected by the client when the token has none": {
+			headerPolicy:   "team-b",
+			expectedStatus: http.StatusOK,
+		},
+		"should fail with multiple policies": {
+			policy:         []any{"team-a", "team-b"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			claims := jwt.MapClaims{"tenant": "user-1"}
+			if testData.policy != nil {
+				claims["cortex"] = map[string]any{"policy": testData.policy}
+			}
+
+			var policy string
+			handler := (&HTTPHeaderMiddleware{}).Wrap(authenticator.Wrap(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				policy = requestmeta.AccessPolicyFromContext(r.Context())
+				assert.Equal(t, policy, r.Header.Get(requestmeta.AccessPolicyKey))
+			})))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/query", nil)
+			req.Header.Set("Authorization", "Bearer "+signToken(t, jwt.SigningMethodRS256, "rsa", key, claims))
+			if testData.headerPolicy != "" {
+				req.Header.Set(requestmeta.AccessPolicyKey, testData.headerPolicy)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, testData.expectedStatus, rec.Code, rec.Body.String())
+			assert.Equal(t, testData.expectedPolicy, policy)
+		})
+	}
+}
+
+func TestJWTAuthenticator_GRPC(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	cfg := JWTAuthConfig{
+		Enabled:        true,
+		JWKSFile:       writeJWKS(t, rsaJWK(t, "rsa", key)),
+		TenantClaim:    "tenant",
+		APIGroupsClaim: "groups",
+		GRPCMethods:    []string{"/distributor.Distributor/Push"},
+	}
+	authenticator, err := NewJWTAuthenticator(cfg, log.NewNopLogger())
+	require.NoError(t, err)
+
+	serverCfg := server.Config{}
+	SetupJWTAuthMiddleware(&serverCfg, authenticator, []string{"/grpc.health.v1.Health/Check"})
+	require.Len(t, serverCfg.GRPCMiddleware, 1)
+	interceptor := serverCfg.GRPCMiddleware[0]
+
+	tests := map[string]struct {
+		method          string
+		md              metadata.MD
+		expectedCode    codes.Code
+		expectedTenants string
+	}{
+		"should authenticate the configured methods with a token": {
+			method:          "/distributor.Distributor/Push",
+			md:              metadata.Pairs("authorization", "Bearer "+signToken(t, jwt.SigningMethodRS256, "rsa", key, jwt.MapClaims{"tenant": "user-1", "groups": []any{APIGroupWrite}})),
+			expectedTenants: "user-1",
+		},
+		"should fail without the write API group": {
+			method:       "/distributor.Distributor/Push",
+			md:           metadata.Pairs("authorization", "Bearer "+signToken(t, jwt.SigningMethodRS256, "rsa", key, jwt.MapClaims{"tenant": "user-1", "groups": []any{APIGroupRead}})),
+			expectedCode: codes.PermissionDenied,
+		},
+		"should fail without token": {
+			method:       "/distributor.Distributor/Push",
+			md:           metadata.Pairs("x-scope-orgid", "user-1"),
+			expectedCode: codes.Unauthenticated,
+		},
+		"should authenticate the other methods with the org ID": {
+			method:          "/cortex.Ingester/Push",
+			md:              metadata.Pairs("x-scope-orgid", "user-2"),
+			expectedTenants: "user-2",
+		},
+		"should not authenticate the ignored methods": {
+			method: "/grpc.health.v1.Health/Check",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), testData.md)
+
+			var tenants string
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testData.method}, func(ctx context.Context, _ any) (any, error) {
+				tenants, _ = user.ExtractOrgID(ctx)
+				return nil, nil
+			})
+			assert.Equal(t, testData.expectedCode, status.Code(err))
+			assert.Equal(t, testData.expectedTenants, tenants)
+		})
+	}
+}
+
+func TestJWKSKeySet_ReloadOnUnknownKey(t *testing.T) {
+	key1, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key2, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var (
+		jwks  atomic.Value
+		loads atomic.Int32
+	)
+	setJWKS := func(keys ...map[string]string) {
+		data, err := json.Marshal(map[string]any{"keys": keys})
+		require.NoError(t, err)
+		jwks.Store(data)
+	}
+	setJWKS(rsaJWK(t, "key-1", key1))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		loads.Add(1)
+		_, _ = w.Write(jwks.Load().([]byte))
+	}))
+	defer srv.Close()
+
+	authenticator, err := NewJWTAuthenticator(JWTAuthConfig{Enabled: true, JWKSURL: srv.URL, TenantClaim: "tenant"}, log.NewNopLogger())
+	require.NoError(t, err)
+	now := time.Now()
+	authenticator.keys.now = func() time.Time { return now }
+	require.Equal(t, int32(1), loads.Load())
+
+	token2 := signToken(t, jwt.SigningMethodRS256, "key-2", key2, jwt.MapClaims{"tenant": "user-1"})
+
+	// The key is rotated but the JWKS has just been loaded, so it's not reloaded yet.
+	setJWKS(rsaJWK(t, "key-1", key1), rsaJWK(t, "key-2", key2))
+	_, _, err = authenticator.authenticate(token2, "")
+	require.Error(t, err)
+	require.Equal(t, int32(1), loads.Load())
+
+	now = now.Add(jwksMinReloadPeriod)
+	tenant, _, err := authenticator.authenticate(token2, "")
+	require.NoError(t, err)
+	require.Equal(t, "user-1", tenant)
+	require.Equal(t, int32(2), loads.Load())
+
+	// Known keys don't trigger a reload.
+	token1 := signToken(t, jwt.SigningMethodRS256, "key-1", key1, jwt.MapClaims{"tenant": "user-1"})
+	now = now.Add(jwksMinReloadPeriod)
+	_, _, err = authenticator.authenticate(token1, "")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), loads.Load())
+}
@@ -39,6 +39,10 @@ func (h HTTPHeaderMiddleware) injectRequestContext(r *http.Request) *http.Reques
 	requestContextMap[requestmeta.RequestIdKey] = reqId
 	requestContextMap[requestmeta.RequestSourceKey] = requestmeta.SourceAPI
 
+	if policy := r.Header.Get(requestmeta.AccessPolicyKey); policy != "" {
+		requestContextMap[requestmeta.AccessPolicyKey] = policy
+	}
+
 	ctx := requestmeta.ContextWithRequestMetadataMap(r.Context(), requestContextMap)
 	return r.WithContext(ctx)
 }
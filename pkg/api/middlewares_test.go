@@ -141,3 +141,23 @@ func TestTargetHeaderAndRequestIdHeaderOverlap(t *testing.T) {
 	require.Equal(t, providedID, ctxMap[headerKey], "Header value should be correctly stored")
 	require.Equal(t, providedID, requestID, "Request ID should come from the overlapping header")
 }
+
+func TestAccessPolicyInjection(t *testing.T) {
+	middleware := HTTPHeaderMiddleware{
+		RequestIdHeader: "X-Request-ID",
+	}
+
+	h := http.Header{}
+	h.Add("X-Cortex-Access-Policy", "team-a")
+
+	req := &http.Request{
+		Method:     "GET",
+		RequestURI: "/test",
+		Body:       http.NoBody,
+		Header:     h,
+	}
+	req = req.WithContext(context.Background())
+	req = middleware.injectRequestContext(req)
+
+	require.Equal(t, "team-a", requestmeta.AccessPolicyFromContext(req.Context()))
+}
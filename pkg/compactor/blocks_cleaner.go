@@ -3,6 +3,7 @@ package compactor
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -79,6 +80,7 @@ type BlocksCleaner struct {
 	tenantBlocksMarkedForNoCompaction *prometheus.GaugeVec
 	tenantPartialBlocks               *prometheus.GaugeVec
 	tenantBucketIndexLastUpdate       *prometheus.GaugeVec
+	tenantStoredBytes                 *prometheus.GaugeVec
 	tenantBlocksCleanedTotal          *prometheus.CounterVec
 	tenantCleanDuration               *prometheus.GaugeVec
 	remainingPlannedCompactions       *prometheus.GaugeVec
@@ -182,6 +184,10 @@ func NewBlocksCleaner(
 			Name: "cortex_bucket_index_last_successful_update_timestamp_seconds",
 			Help: "Timestamp of the last successful update of a tenant's bucket index.",
 		}, commonLabels),
+		tenantStoredBytes: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_bucket_blocks_stored_bytes",
+			Help: "Total size in bytes of the blocks in the bucket, by compaction level and age range. Includes blocks marked for deletion, but not partial blocks.",
+		}, []string{userLabelName, "level", "age"}),
 		tenantBlocksCleanedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
 			Name: "cortex_bucket_blocks_cleaned_total",
 			Help: "Total number of blocks deleted for a tenant.",
@@ -423,6 +429,7 @@ func (c *BlocksCleaner) scanUsers(ctx context.Context) ([]string, []string, erro
 			c.tenantBlocksMarkedForNoCompaction.DeleteLabelValues(userID)
 			c.tenantPartialBlocks.DeleteLabelValues(userID)
 			c.tenantBucketIndexLastUpdate.DeleteLabelValues(userID)
+			c.tenantStoredBytes.DeletePartialMatch(prometheus.Labels{userLabelName: userID})
 			if c.cfg.ShardingStrategy == util.ShardingStrategyShuffle {
 				c.remainingPlannedCompactions.DeleteLabelValues(userID)
 				if c.cfg.CompactionStrategy == util.CompactionStrategyPartitioning {
@@ -529,6 +536,7 @@ func (c *BlocksCleaner) deleteUserMarkedForDeletion(ctx context.Context, userLog
 	c.tenantBlocksMarkedForDelete.DeleteLabelValues(userID)
 	c.tenantBlocksMarkedForNoCompaction.DeleteLabelValues(userID)
 	c.tenantPartialBlocks.DeleteLabelValues(userID)
+	c.tenantStoredBytes.DeletePartialMatch(prometheus.Labels{userLabelName: userID})
 
 	if deletedBlocks.Load() > 0 {
 		level.Info(userLogger).Log("msg", "deleted blocks for tenant marked for deletion", "deletedBlocks", deletedBlocks.Load())
@@ -767,6 +775,13 @@ func (c *BlocksCleaner) updateBucketMetrics(userID string, parquetEnabled bool,
 	c.tenantBlocksMarkedForNoCompaction.WithLabelValues(userID).Set(totalBlocksBlocksMarkedForNoCompaction)
 	c.tenantPartialBlocks.WithLabelValues(userID).Set(float64(partials))
 	c.tenantBucketIndexLastUpdate.WithLabelValues(userID).SetToCurrentTime()
+
+	now := time.Now()
+	c.tenantStoredBytes.DeletePartialMatch(prometheus.Labels{userLabelName: userID})
+	for _, b := range idx.Blocks {
+		c.tenantStoredBytes.WithLabelValues(userID, strconv.Itoa(b.CompactionLevel), b.AgeRange(now)).Add(float64(b.Size()))
+	}
+
 	if parquetEnabled {
 		c.tenantParquetBlocks.WithLabelValues(userID).Set(float64(len(idx.ParquetBlocks())))
 		remainingBlocksToConvert := 0
@@ -566,6 +566,9 @@ func TestBlocksCleaner_ShouldRemoveMetricsForTenantsNotBelongingAnymoreToTheShar
 		"cortex_bucket_blocks_partials_count",
 	))
 
+	// All blocks have the same compaction level and age range.
+	assert.Equal(t, 2, prom_testutil.CollectAndCount(cleaner.tenantStoredBytes))
+
 	// Override the users scanner to reconfigure it to only return a subset of users.
 	cleaner.usersScanner, err = users.NewScanner(users.UsersScannerConfig{
 		Strategy: users.UserScanStrategyList,
@@ -597,6 +600,8 @@ func TestBlocksCleaner_ShouldRemoveMetricsForTenantsNotBelongingAnymoreToTheShar
 		"cortex_bucket_blocks_marked_for_deletion_count",
 		"cortex_bucket_blocks_partials_count",
 	))
+	// The metrics of the tenants not owned anymore should be removed.
+	assert.Equal(t, 1, prom_testutil.CollectAndCount(cleaner.tenantStoredBytes))
 }
 
 func TestBlocksCleaner_ListBlocksOutsideRetentionPeriod(t *testing.T) {
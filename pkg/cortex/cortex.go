@@ -33,6 +33,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/cortexpb"
 	"github.com/cortexproject/cortex/pkg/distributor"
 	"github.com/cortexproject/cortex/pkg/engine"
+	"github.com/cortexproject/cortex/pkg/export"
 	"github.com/cortexproject/cortex/pkg/flusher"
 	"github.com/cortexproject/cortex/pkg/frontend"
 	frontendv1 "github.com/cortexproject/cortex/pkg/frontend/v1"
@@ -44,14 +45,17 @@ import (
 	"github.com/cortexproject/cortex/pkg/querier/tripperware"
 	"github.com/cortexproject/cortex/pkg/querier/tripperware/queryrange"
 	querier_worker "github.com/cortexproject/cortex/pkg/querier/worker"
+	"github.com/cortexproject/cortex/pkg/replicator"
 	"github.com/cortexproject/cortex/pkg/ring"
 	"github.com/cortexproject/cortex/pkg/ring/kv/memberlist"
 	"github.com/cortexproject/cortex/pkg/ruler"
 	"github.com/cortexproject/cortex/pkg/ruler/rulestore"
 	"github.com/cortexproject/cortex/pkg/scheduler"
+	"github.com/cortexproject/cortex/pkg/storage/bucket/encryption"
 	"github.com/cortexproject/cortex/pkg/storage/tsdb"
 	"github.com/cortexproject/cortex/pkg/storegateway"
 	"github.com/cortexproject/cortex/pkg/tracing"
+	"github.com/cortexproject/cortex/pkg/usage"
 	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/fakeauth"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
@@ -69,6 +73,9 @@ import (
 
 var (
 	errInvalidHTTPPrefix = errors.New("HTTP prefix should be empty or start with /")
+
+	errTimeBasedShardingWithoutLazyLoading  = errors.New("the store-gateway time-based sharding strategy requires the index-header lazy loading to be enabled")
+	errTimeBasedShardingGracePeriodTooShort = errors.New("the store-gateway time-based sharding transition grace period must be greater than or equal to the bucket store sync interval")
 )
 
 // The design pattern for Cortex is a series of config objects, which are
@@ -128,6 +135,9 @@ type Config struct {
 	RuntimeConfig       runtimeconfig.Config                       `yaml:"runtime_config"`
 	MemberlistKV        memberlist.KVConfig                        `yaml:"memberlist"`
 	QueryScheduler      scheduler.Config                           `yaml:"query_scheduler"`
+	Replicator          replicator.Config                          `yaml:"replicator"`
+	Export              export.Config                              `yaml:"export"`
+	UsageTracking       usage.Config                               `yaml:"usage_tracking"`
 
 	Tracing tracing.Config `yaml:"tracing"`
 }
@@ -179,6 +189,9 @@ func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	c.RuntimeConfig.RegisterFlags(f)
 	c.MemberlistKV.RegisterFlags(f)
 	c.QueryScheduler.RegisterFlags(f)
+	c.Replicator.RegisterFlags(f)
+	c.Export.RegisterFlags(f)
+	c.UsageTracking.RegisterFlags(f)
 	c.Tracing.RegisterFlags(f)
 }
 
@@ -199,6 +212,9 @@ func (c *Config) Validate(log log.Logger) error {
 		return errInvalidHTTPPrefix
 	}
 
+	if err := c.API.JWTAuth.Validate(c.AuthEnabled); err != nil {
+		return errors.Wrap(err, "invalid JWT authentication config")
+	}
 	if err := c.API.Validate(); err != nil {
 		return errors.Wrap(err, "invalid api config")
 	}
@@ -235,9 +251,18 @@ func (c *Config) Validate(log log.Logger) error {
 	if err := c.QueryRange.Validate(c.Querier); err != nil {
 		return errors.Wrap(err, "invalid query_range config")
 	}
+	if err := c.QueryScheduler.Validate(); err != nil {
+		return errors.Wrap(err, "invalid query_scheduler config")
+	}
 	if err := c.StoreGateway.Validate(c.LimitsConfig, c.ResourceMonitor.Resources); err != nil {
 		return errors.Wrap(err, "invalid store-gateway config")
 	}
+	if c.StoreGateway.ShardingEnabled && c.StoreGateway.ShardingStrategy == util.ShardingStrategyTime && !c.BlocksStorage.BucketStore.IndexHeaderLazyLoadingEnabled {
+		return errTimeBasedShardingWithoutLazyLoading
+	}
+	if c.StoreGateway.ShardingEnabled && c.StoreGateway.ShardingStrategy == util.ShardingStrategyTime && c.StoreGateway.TimeBasedSharding.TransitionGracePeriod < c.BlocksStorage.BucketStore.SyncInterval {
+		return errTimeBasedShardingGracePeriodTooShort
+	}
 	if err := c.Compactor.Validate(c.LimitsConfig); err != nil {
 		return errors.Wrap(err, "invalid compactor config")
 	}
@@ -252,6 +277,25 @@ func (c *Config) Validate(log log.Logger) error {
 		return errors.Wrap(err, "invalid ingester config")
 	}
 
+	if c.isModuleEnabled(Replicator) || c.Replicator.QuerierFailoverEnabled {
+		if err := c.Replicator.Validate(); err != nil {
+			return errors.Wrap(err, "invalid replicator config")
+		}
+	}
+	if err := c.validateQuerierFailover(); err != nil {
+		return err
+	}
+
+	if c.Export.Enabled {
+		if err := c.Export.Validate(); err != nil {
+			return errors.Wrap(err, "invalid export config")
+		}
+	}
+
+	if err := c.UsageTracking.Validate(); err != nil {
+		return errors.Wrap(err, "invalid usage tracking config")
+	}
+
 	if err := c.Tracing.Validate(); err != nil {
 		return errors.Wrap(err, "invalid tracing config")
 	}
@@ -263,6 +307,31 @@ func (c *Config) isModuleEnabled(m string) bool {
 	return slices.Contains(c.Target, m)
 }
 
+// validateQuerierFailover ensures that the blocks storage is only failed over to the
+// secondary storage by the targets which don't write to it.
+func (c *Config) validateQuerierFailover() error {
+	if !c.Replicator.QuerierFailoverEnabled {
+		return nil
+	}
+
+	// The export jobs write to the blocks storage.
+	if c.Export.Enabled {
+		return errors.New("the querier failover can't be enabled when the export is enabled")
+	}
+
+	// The query-frontends write the usage reports to the blocks storage.
+	if c.UsageTracking.Enabled {
+		return errors.New("the querier failover can't be enabled when the usage tracking is enabled")
+	}
+
+	for _, target := range c.Target {
+		if !slices.Contains([]string{Querier, StoreGateway, QueryFrontend, QueryScheduler}, target) {
+			return fmt.Errorf("the querier failover can't be enabled when running the %s target", target)
+		}
+	}
+	return nil
+}
+
 // validateYAMLEmptyNodes ensure that no empty node has been specified in the YAML config file.
 // When an empty node is defined in YAML, the YAML parser sets the whole struct to its zero value
 // and so we loose all default values. It's very difficult to detect this case for the user, so we
@@ -335,6 +404,7 @@ type Cortex struct {
 	QuerierEngine            engine.QueryEngine
 	QueryFrontendTripperware tripperware.Tripperware
 	ResourceMonitor          *resource.Monitor
+	UsageTracker             *usage.Tracker
 
 	Ruler            *ruler.Ruler
 	RulerStorage     rulestore.RuleStore
@@ -349,6 +419,12 @@ type Cortex struct {
 	// Queryables that the querier should use to query the long
 	// term storage. It depends on the storage engine used.
 	StoreQueryables []querier.QueryableWithFilter
+
+	// BlocksStoreQueryable is the queryable used to query the store-gateways.
+	BlocksStoreQueryable *querier.BlocksStoreQueryable
+
+	// ParquetQueryable is the queryable used to query the parquet files, if enabled.
+	ParquetQueryable prom_storage.Queryable
 }
 
 // New makes a new Cortex.
@@ -366,16 +442,45 @@ func New(cfg Config) (*Cortex, error) {
 		users.WithDefaultResolver(users.NewMultiResolver())
 	}
 
-	cfg.API.HTTPAuthMiddleware = fakeauth.SetupAuthMiddleware(&cfg.Server, cfg.AuthEnabled,
-		// Also don't check auth for these gRPC methods, since single call is used for multiple users (or no user like health check).
-		[]string{
-			"/grpc.health.v1.Health/Check",
-			"/frontend.Frontend/Process",
-			"/frontend.Frontend/NotifyClientShutdown",
-			"/schedulerpb.SchedulerForFrontend/FrontendLoop",
-			"/schedulerpb.SchedulerForQuerier/QuerierLoop",
-			"/schedulerpb.SchedulerForQuerier/NotifyQuerierShutdown",
-		})
+	// Don't check auth for these gRPC methods, since single call is used for multiple users (or no user like health check).
+	noGRPCAuthOn := []string{
+		"/grpc.health.v1.Health/Check",
+		"/frontend.Frontend/Process",
+		"/frontend.Frontend/NotifyClientShutdown",
+		"/schedulerpb.SchedulerForFrontend/FrontendLoop",
+		"/schedulerpb.SchedulerForQuerier/QuerierLoop",
+		"/schedulerpb.SchedulerForQuerier/NotifyQuerierShutdown",
+	}
+	if cfg.API.JWTAuth.Enabled {
+		util_log.WarnExperimentalUse("JWT authentication")
+
+		authenticator, err := api.NewJWTAuthenticator(cfg.API.JWTAuth, util_log.Logger)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to initialize JWT authentication")
+		}
+		cfg.API.HTTPAuthMiddleware = api.SetupJWTAuthMiddleware(&cfg.Server, authenticator, noGRPCAuthOn)
+	} else {
+		cfg.API.HTTPAuthMiddleware = fakeauth.SetupAuthMiddleware(&cfg.Server, cfg.AuthEnabled, noGRPCAuthOn)
+	}
+
+	if cfg.BlocksStorage.Encryption.Enabled {
+		util_log.WarnExperimentalUse("blocks storage client-side encryption")
+
+		middleware, err := encryption.NewMiddleware(cfg.BlocksStorage.Encryption, util_log.Logger)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to initialize blocks storage encryption")
+		}
+		cfg.BlocksStorage.Bucket.Middlewares = append(cfg.BlocksStorage.Bucket.Middlewares, middleware)
+	}
+
+	// The querier and store-gateway read the blocks replicated to the secondary storage.
+	if cfg.Replicator.QuerierFailoverEnabled {
+		util_log.WarnExperimentalUse("replicator querier failover")
+
+		middlewares := cfg.BlocksStorage.Bucket.Middlewares
+		cfg.BlocksStorage.Bucket = cfg.Replicator.BlocksStorage
+		cfg.BlocksStorage.Bucket.Middlewares = middlewares
+	}
 
 	cortex := &Cortex{
 		Cfg: cfg,
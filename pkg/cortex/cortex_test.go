@@ -36,6 +36,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/storage/bucket"
 	"github.com/cortexproject/cortex/pkg/storage/bucket/s3"
 	"github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
 	"github.com/cortexproject/cortex/pkg/util/services"
 	"github.com/cortexproject/cortex/pkg/util/users"
@@ -174,6 +175,39 @@ func TestConfigValidation(t *testing.T) {
 			},
 			expectedError: errInvalidHTTPPrefix,
 		},
+		{
+			name: "should fail validation for time-based sharding without index-header lazy loading",
+			getTestConfig: func() *Config {
+				configuration := newDefaultConfig()
+				configuration.StoreGateway.ShardingEnabled = true
+				configuration.StoreGateway.ShardingStrategy = util.ShardingStrategyTime
+				return configuration
+			},
+			expectedError: errTimeBasedShardingWithoutLazyLoading,
+		},
+		{
+			name: "should pass validation for time-based sharding with index-header lazy loading",
+			getTestConfig: func() *Config {
+				configuration := newDefaultConfig()
+				configuration.StoreGateway.ShardingEnabled = true
+				configuration.StoreGateway.ShardingStrategy = util.ShardingStrategyTime
+				configuration.BlocksStorage.BucketStore.IndexHeaderLazyLoadingEnabled = true
+				return configuration
+			},
+			expectedError: nil,
+		},
+		{
+			name: "should fail validation for time-based sharding with a transition grace period shorter than the sync interval",
+			getTestConfig: func() *Config {
+				configuration := newDefaultConfig()
+				configuration.StoreGateway.ShardingEnabled = true
+				configuration.StoreGateway.ShardingStrategy = util.ShardingStrategyTime
+				configuration.BlocksStorage.BucketStore.IndexHeaderLazyLoadingEnabled = true
+				configuration.StoreGateway.TimeBasedSharding.TransitionGracePeriod = time.Minute
+				return configuration
+			},
+			expectedError: errTimeBasedShardingGracePeriodTooShort,
+		},
 		{
 			name: "should fail validation for invalid resource to monitor",
 			getTestConfig: func() *Config {
@@ -239,6 +273,36 @@ func TestConfigValidation(t *testing.T) {
 			},
 			expectedError: fmt.Errorf("unsupported name validation scheme: unset"),
 		},
+		{
+			name: "should pass querier failover validation when running the querier",
+			getTestConfig: func() *Config {
+				configuration := newDefaultConfig()
+				configuration.Target = []string{Querier, StoreGateway}
+				configuration.Replicator.QuerierFailoverEnabled = true
+				return configuration
+			},
+			expectedError: nil,
+		},
+		{
+			name: "should fail querier failover validation when running a target writing to the storage",
+			getTestConfig: func() *Config {
+				configuration := newDefaultConfig()
+				configuration.Replicator.QuerierFailoverEnabled = true
+				return configuration
+			},
+			expectedError: fmt.Errorf("the querier failover can't be enabled when running the all target"),
+		},
+		{
+			name: "should fail querier failover validation when the export is enabled",
+			getTestConfig: func() *Config {
+				configuration := newDefaultConfig()
+				configuration.Target = []string{Querier}
+				configuration.Replicator.QuerierFailoverEnabled = true
+				configuration.Export.Enabled = true
+				return configuration
+			},
+			expectedError: fmt.Errorf("the querier failover can't be enabled when the export is enabled"),
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			err := tc.getTestConfig().Validate(nil)
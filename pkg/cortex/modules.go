@@ -34,6 +34,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/configs/db"
 	"github.com/cortexproject/cortex/pkg/distributor"
 	"github.com/cortexproject/cortex/pkg/engine"
+	"github.com/cortexproject/cortex/pkg/export"
 	"github.com/cortexproject/cortex/pkg/flusher"
 	"github.com/cortexproject/cortex/pkg/frontend"
 	"github.com/cortexproject/cortex/pkg/frontend/transport"
@@ -47,6 +48,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/querier/tripperware/queryrange"
 	querier_worker "github.com/cortexproject/cortex/pkg/querier/worker"
 	cortexquerysharding "github.com/cortexproject/cortex/pkg/querysharding"
+	"github.com/cortexproject/cortex/pkg/replicator"
 	"github.com/cortexproject/cortex/pkg/ring"
 	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
 	"github.com/cortexproject/cortex/pkg/ring/kv/memberlist"
@@ -54,6 +56,9 @@ import (
 	"github.com/cortexproject/cortex/pkg/scheduler"
 	"github.com/cortexproject/cortex/pkg/storage/bucket"
 	"github.com/cortexproject/cortex/pkg/storegateway"
+	"github.com/cortexproject/cortex/pkg/tracing"
+	"github.com/cortexproject/cortex/pkg/tracing/tailsampling"
+	"github.com/cortexproject/cortex/pkg/usage"
 	"github.com/cortexproject/cortex/pkg/util/grpcclient"
 	util_log "github.com/cortexproject/cortex/pkg/util/log"
 	"github.com/cortexproject/cortex/pkg/util/modules"
@@ -96,6 +101,9 @@ const (
 	QueryScheduler           string = "query-scheduler"
 	TenantFederation         string = "tenant-federation"
 	ResourceMonitor          string = "resource-monitor"
+	Replicator               string = "replicator"
+	Exporter                 string = "exporter"
+	UsageTracker             string = "usage-tracker"
 	All                      string = "all"
 )
 
@@ -166,6 +174,7 @@ func (t *Cortex) initRuntimeConfig() (services.Service, error) {
 	}
 	runtimeConfigLoader := runtimeConfigLoader{cfg: t.Cfg}
 	t.Cfg.RuntimeConfig.Loader = runtimeConfigLoader.load
+	t.Cfg.RuntimeConfig.Merger = runtimeConfigLoader.mergeTenantOverrides
 
 	// make sure to set default limits before we start loading configuration into memory
 	validation.SetDefaultLimitsForYAMLUnmarshalling(t.Cfg.LimitsConfig)
@@ -199,6 +208,21 @@ func (t *Cortex) initRuntimeConfig() (services.Service, error) {
 
 	t.RuntimeConfig = serv
 	t.API.RegisterRuntimeConfig(runtimeConfigHandler(t.RuntimeConfig, t.Cfg.LimitsConfig))
+
+	if err == nil && t.Cfg.RuntimeConfig.TenantOverrides.Enabled {
+		overridesAPI, err := newTenantOverridesAPI(serv, t.Cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		// Tenants can only access their overrides if they're allowed to change some limits.
+		var tenantHandler http.HandlerFunc
+		if len(t.Cfg.RuntimeConfig.TenantOverrides.TenantAllowedLimits) > 0 {
+			tenantHandler = overridesAPI.TenantHandler
+		}
+		t.API.RegisterTenantOverrides(overridesAPI.AdminHandler, tenantHandler)
+	}
+
 	return serv, err
 }
 
@@ -229,6 +253,7 @@ func (t *Cortex) initDistributorService() (serv services.Service, err error) {
 	t.Cfg.Distributor.DistributorRing.ListenPort = t.Cfg.Server.GRPCListenPort
 	t.Cfg.Distributor.ShuffleShardingLookbackPeriod = t.Cfg.Querier.ShuffleShardingIngestersLookbackPeriod
 	t.Cfg.Distributor.NameValidationScheme = t.Cfg.NameValidationScheme
+	t.Cfg.Distributor.UsageTracker = t.UsageTracker
 	t.Cfg.IngesterClient.GRPCClientConfig.SignWriteRequestsEnabled = t.Cfg.Distributor.SignWriteRequestsEnabled
 
 	// Check whether the distributor can join the distributors ring, which is
@@ -277,6 +302,29 @@ func (t *Cortex) initQueryable() (serv services.Service, err error) {
 	// Register the default endpoints that are always enabled for the querier module
 	t.API.RegisterQueryable(t.QuerierQueryable, t.Distributor)
 
+	// The unused metrics report includes the queries served by the store-gateways
+	// only if they keep track of them.
+	var storeGateways querier.QueriedMetricsQuerier
+	if t.Cfg.StoreGateway.QueriedMetricsTrackingEnabled && t.BlocksStoreQueryable != nil {
+		storeGateways = t.BlocksStoreQueryable
+	}
+	// These reports cover all the series of the tenant, so they are denied to the requests
+	// restricted by a query access policy.
+	t.API.RegisterUnusedMetrics(querier.DenyWithAccessPolicy(querier.UnusedMetricsHandler(t.Distributor, storeGateways), t.Overrides))
+	t.API.RegisterOutOfOrderSeries(querier.DenyWithAccessPolicy(querier.OutOfOrderSeriesHandler(t.Distributor), t.Overrides))
+
+	if t.BlocksStoreQueryable != nil {
+		var usageReader querier.UsageReader
+		if t.UsageTracker != nil {
+			usageReader = t.UsageTracker
+		}
+		t.API.RegisterUsage(querier.DenyWithAccessPolicy(querier.UsageHandler(t.BlocksStoreQueryable, usageReader), t.Overrides))
+	}
+
+	if t.Cfg.Querier.ParquetSQLAPIEnabled && t.ParquetQueryable != nil {
+		t.API.RegisterParquetSQL(querier.SQLHandler(t.ParquetQueryable, t.Overrides, t.Cfg.Querier.ParquetSQLMaxResultRows))
+	}
+
 	return nil, nil
 }
 
@@ -432,12 +480,14 @@ func (t *Cortex) initStoreQueryables() (services.Service, error) {
 		return nil, fmt.Errorf("failed to initialize querier: %v", err)
 	} else {
 		queriable = q
+		t.BlocksStoreQueryable = q
 		if t.Cfg.Querier.EnableParquetQueryable {
 			pq, err := querier.NewParquetQueryable(t.Cfg.Querier, t.Cfg.BlocksStorage, t.Overrides, q, util_log.Logger, prometheus.DefaultRegisterer)
 			if err != nil {
 				return nil, fmt.Errorf("failed to initialize parquet querier: %v", err)
 			}
 			queriable = pq
+			t.ParquetQueryable = pq
 		}
 		t.StoreQueryables = append(t.StoreQueryables, querier.UseAlwaysQueryable(queriable))
 		if s, ok := queriable.(services.Service); ok {
@@ -566,7 +616,7 @@ func (t *Cortex) initQueryFrontendTripperware() (serv services.Service, err erro
 		return nil, err
 	}
 
-	t.QueryFrontendTripperware = tripperware.NewQueryTripperware(util_log.Logger,
+	queryTripperware := tripperware.NewQueryTripperware(util_log.Logger,
 		prometheus.DefaultRegisterer,
 		t.Cfg.QueryRange.ForwardHeaders,
 		queryRangeMiddlewares,
@@ -580,7 +630,38 @@ func (t *Cortex) initQueryFrontendTripperware() (serv services.Service, err erro
 		t.Cfg.Querier.LookbackDelta,
 	)
 
-	return services.NewIdleService(nil, func(_ error) error {
+	// With the global query rate strategy, the query-frontends join a ring to know
+	// across how many instances the tenants query rate limits should be shared.
+	var (
+		frontendsLifecycler *ring.Lifecycler
+		frontends           tripperware.ReadLifecycler
+	)
+	if t.Overrides.QueryRateStrategy() == validation.GlobalQueryRateStrategy {
+		t.Cfg.Frontend.Ring.ListenPort = t.Cfg.Server.GRPCListenPort
+		frontendsLifecycler, err = ring.NewLifecycler(t.Cfg.Frontend.Ring.ToLifecyclerConfig(), nil, "query-frontend", frontend.RingKey, true, true, util_log.Logger, prometheus.WrapRegistererWithPrefix("cortex_", prometheus.DefaultRegisterer))
+		if err != nil {
+			return nil, err
+		}
+		frontends = frontendsLifecycler
+	}
+	rateLimitTripperware := tripperware.NewRateLimitTripperware(t.Overrides, frontends)
+
+	// The requests exceeding the rate limits are rejected before being processed.
+	t.QueryFrontendTripperware = func(next http.RoundTripper) http.RoundTripper {
+		return rateLimitTripperware(queryTripperware(next))
+	}
+
+	return services.NewIdleService(func(ctx context.Context) error {
+		if frontendsLifecycler != nil {
+			return services.StartAndAwaitRunning(ctx, frontendsLifecycler)
+		}
+		return nil
+	}, func(_ error) error {
+		if frontendsLifecycler != nil {
+			if err := services.StopAndAwaitTerminated(context.Background(), frontendsLifecycler); err != nil {
+				level.Warn(util_log.Logger).Log("msg", "failed to stop query-frontends lifecycler", "err", err)
+			}
+		}
 		if cache != nil {
 			cache.Stop()
 			cache = nil
@@ -599,7 +680,20 @@ func (t *Cortex) initQueryFrontend() (serv services.Service, err error) {
 	// Wrap roundtripper into Tripperware.
 	roundTripper = t.QueryFrontendTripperware(roundTripper)
 
-	handler := transport.NewHandler(t.Cfg.Frontend.Handler, t.Cfg.TenantFederation, roundTripper, util_log.Logger, prometheus.DefaultRegisterer)
+	t.Cfg.Frontend.Handler.UsageTracker = t.UsageTracker
+	var handler http.Handler = transport.NewHandler(t.Cfg.Frontend.Handler, t.Cfg.TenantFederation, roundTripper, util_log.Logger, prometheus.DefaultRegisterer)
+
+	// With the tail sampling, the query-frontend decides which traces of the queries are kept, and
+	// the other components ask it for its decisions.
+	if tailSampler := tracing.TailSampler(); tailSampler != nil {
+		addr, err := frontend.AdvertisedAddress(t.Cfg.Frontend, t.Cfg.Server.GRPCListenPort)
+		if err != nil {
+			return nil, err
+		}
+		tailSampler.EnableDecisions(addr)
+		tailsampling.RegisterTailSamplingServer(t.Server.GRPC, tailSampler)
+		handler = tailSampler.Middleware(t.Overrides).Wrap(handler)
+	}
 	t.API.RegisterQueryFrontendHandler(handler)
 
 	if frontendV1 != nil {
@@ -688,6 +782,7 @@ func (t *Cortex) initRuler() (serv services.Service, err error) {
 		queryable, _, queryEngine = querier.New(t.Cfg.Querier, t.Overrides, t.Distributor, t.StoreQueryables, rulerRegisterer, util_log.Logger, t.Overrides.RulesPartialData)
 	}
 
+	t.Cfg.Ruler.UsageTracker = t.UsageTracker
 	managerFactory := ruler.DefaultTenantManagerFactory(t.Cfg.Ruler, pusher, queryable, queryEngine, t.Overrides, metrics, prometheus.DefaultRegisterer)
 	manager, err = ruler.NewDefaultMultiTenantManager(t.Cfg.Ruler, t.Overrides, managerFactory, metrics, prometheus.DefaultRegisterer, util_log.Logger)
 
@@ -712,7 +807,7 @@ func (t *Cortex) initRuler() (serv services.Service, err error) {
 
 	// If the API is enabled, register the Ruler API
 	if t.Cfg.Ruler.EnableAPI {
-		t.API.RegisterRulerAPI(ruler.NewAPI(t.Ruler, t.RulerStorage, util_log.Logger))
+		t.API.RegisterRulerAPI(ruler.NewAPI(t.Ruler, t.RulerStorage, ruler.NewSLOQueryFunc(t.Cfg.Ruler, queryEngine, queryable, t.Overrides), util_log.Logger))
 	}
 
 	return t.Ruler, nil
@@ -771,6 +866,12 @@ func (t *Cortex) initParquetConverter() (serv services.Service, err error) {
 	return parquetconverter.NewConverter(t.Cfg.ParquetConverter, t.Cfg.BlocksStorage, t.Cfg.Compactor.BlockRanges.ToMilliseconds(), util_log.Logger, prometheus.DefaultRegisterer, t.Overrides)
 }
 
+func (t *Cortex) initReplicator() (serv services.Service, err error) {
+	util_log.WarnExperimentalUse("replicator")
+
+	return replicator.NewReplicator(t.Cfg.Replicator, t.Cfg.BlocksStorage.Bucket, t.Cfg.RulerStorage.Config, t.Cfg.AlertmanagerStorage.Config, util_log.Logger, prometheus.DefaultRegisterer)
+}
+
 func (t *Cortex) initCompactor() (serv services.Service, err error) {
 	t.Cfg.Compactor.ShardingRing.ListenPort = t.Cfg.Server.GRPCListenPort
 	ingestionReplicationFactor := t.Cfg.Ingester.LifecyclerConfig.RingConfig.ReplicationFactor
@@ -818,6 +919,7 @@ func (t *Cortex) initMemberlistKV() (services.Service, error) {
 
 	// Update the config.
 	t.Cfg.Distributor.DistributorRing.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
+	t.Cfg.Frontend.Ring.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
 	t.Cfg.Ingester.LifecyclerConfig.RingConfig.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
 	t.Cfg.StoreGateway.ShardingRing.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
 	t.Cfg.Compactor.ShardingRing.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
@@ -839,6 +941,38 @@ func (t *Cortex) initTenantDeletionAPI() (services.Service, error) {
 	return nil, nil
 }
 
+func (t *Cortex) initExporter() (services.Service, error) {
+	if !t.Cfg.Export.Enabled {
+		return nil, nil
+	}
+
+	util_log.WarnExperimentalUse("export")
+
+	exporter, err := export.NewExporter(t.Cfg.Export, t.Cfg.BlocksStorage, t.Overrides, t.QuerierQueryable, t.Overrides, util_log.Logger, prometheus.DefaultRegisterer)
+	if err != nil {
+		return nil, err
+	}
+
+	t.API.RegisterExport(exporter)
+	return exporter, nil
+}
+
+func (t *Cortex) initUsageTracker() (services.Service, error) {
+	if !t.Cfg.UsageTracking.Enabled {
+		return nil, nil
+	}
+
+	util_log.WarnExperimentalUse("usage tracking")
+
+	tracker, err := usage.NewTracker(t.Cfg.UsageTracking, t.Cfg.BlocksStorage, t.Overrides, util_log.Logger, prometheus.DefaultRegisterer)
+	if err != nil {
+		return nil, err
+	}
+
+	t.UsageTracker = tracker
+	return tracker, nil
+}
+
 func (t *Cortex) initQueryScheduler() (services.Service, error) {
 	if t.Cfg.TenantFederation.Enabled && t.Cfg.TenantFederation.RegexMatcherEnabled {
 		// If regex matcher enabled, we use regex validator to pass regex to the querier
@@ -913,6 +1047,9 @@ func (t *Cortex) setupModuleManager() error {
 	mm.RegisterModule(Purger, nil)
 	mm.RegisterModule(QueryScheduler, t.initQueryScheduler)
 	mm.RegisterModule(TenantFederation, t.initTenantFederation, modules.UserInvisibleModule)
+	mm.RegisterModule(Replicator, t.initReplicator)
+	mm.RegisterModule(Exporter, t.initExporter, modules.UserInvisibleModule)
+	mm.RegisterModule(UsageTracker, t.initUsageTracker, modules.UserInvisibleModule)
 	mm.RegisterModule(All, nil)
 
 	// Add dependencies
@@ -924,15 +1061,15 @@ func (t *Cortex) setupModuleManager() error {
 		Overrides:                {RuntimeConfig},
 		OverridesExporter:        {RuntimeConfig},
 		Distributor:              {DistributorService, API, GrpcClientService},
-		DistributorService:       {Ring, Overrides},
+		DistributorService:       {Ring, Overrides, UsageTracker},
 		Ingester:                 {IngesterService, Overrides, API},
 		IngesterService:          {Overrides, RuntimeConfig, MemberlistKV, ResourceMonitor},
 		Flusher:                  {Overrides, API},
 		Queryable:                {Overrides, DistributorService, Overrides, Ring, API, StoreQueryable, MemberlistKV},
-		Querier:                  {TenantFederation},
+		Querier:                  {TenantFederation, Exporter},
 		StoreQueryable:           {Overrides, Overrides, MemberlistKV, GrpcClientService},
-		QueryFrontendTripperware: {API, Overrides},
-		QueryFrontend:            {QueryFrontendTripperware},
+		QueryFrontendTripperware: {API, Overrides, MemberlistKV},
+		QueryFrontend:            {QueryFrontendTripperware, UsageTracker},
 		QueryScheduler:           {API, Overrides},
 		Ruler:                    {DistributorService, Overrides, StoreQueryable, RulerStorage},
 		RulerStorage:             {Overrides},
@@ -944,6 +1081,9 @@ func (t *Cortex) setupModuleManager() error {
 		TenantDeletion:           {API, Overrides},
 		Purger:                   {TenantDeletion},
 		TenantFederation:         {Queryable},
+		Replicator:               {API},
+		Exporter:                 {API, Overrides, Queryable},
+		UsageTracker:             {API, Overrides},
 		All:                      {QueryFrontend, Querier, Ingester, Distributor, Purger, StoreGateway, Ruler, Compactor, AlertManager},
 	}
 	if t.Cfg.ExternalPusher != nil && t.Cfg.ExternalQueryable != nil {
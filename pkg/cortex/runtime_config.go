@@ -31,6 +31,10 @@ type RuntimeConfigValues struct {
 	IngesterChunkStreaming *bool `yaml:"ingester_stream_chunks_when_using_blocks"`
 
 	IngesterLimits *ingester.InstanceLimits `yaml:"ingester_limits"`
+
+	// fileTenantLimits are the tenant limits loaded from the runtime config file,
+	// before merging the tenant overrides stored in the bucket.
+	fileTenantLimits map[string]*validation.Limits
 }
 
 // runtimeConfigTenantLimits provides per-tenant limit overrides based on a runtimeconfig.Manager
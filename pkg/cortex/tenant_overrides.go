@@ -0,0 +1,305 @@
+package cortex
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v2"
+
+	"github.com/cortexproject/cortex/pkg/util"
+	util_log "github.com/cortexproject/cortex/pkg/util/log"
+	"github.com/cortexproject/cortex/pkg/util/runtimeconfig"
+	"github.com/cortexproject/cortex/pkg/util/users"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+const (
+	// maxTenantOverridesSize is the max size of a tenant overrides patch request.
+	maxTenantOverridesSize = 1 << 20
+
+	changedByAdmin  = "admin"
+	changedByTenant = "tenant"
+)
+
+// mergeTenantOverrides is a runtimeconfig.Merger applying the tenant overrides stored in the bucket
+// on top of the tenant limits of the runtime config file. The overrides of a tenant failing the
+// validation are skipped, so that a tenant can't prevent the runtime config from being reloaded.
+func (l runtimeConfigLoader) mergeTenantOverrides(config any, tenantOverrides map[string][]byte) (any, error) {
+	values, ok := config.(*RuntimeConfigValues)
+	if !ok || values == nil {
+		values = &RuntimeConfigValues{}
+	}
+
+	values.fileTenantLimits = values.TenantLimits
+	if len(tenantOverrides) == 0 {
+		return values, nil
+	}
+
+	values.TenantLimits = maps.Clone(values.fileTenantLimits)
+	if values.TenantLimits == nil {
+		values.TenantLimits = map[string]*validation.Limits{}
+	}
+
+	for userID, buf := range tenantOverrides {
+		overrides := map[string]any{}
+		if err := yaml.Unmarshal(buf, &overrides); err != nil {
+			level.Warn(util_log.Logger).Log("msg", "skipped invalid tenant overrides", "tenant", userID, "err", err)
+			continue
+		}
+
+		limits, err := applyTenantOverrides(values.fileTenantLimits[userID], overrides)
+		if err == nil {
+			err = limits.Validate(l.cfg.NameValidationScheme, l.cfg.Distributor.ShardByAllLabels, l.cfg.Ingester.ActiveSeriesMetricsEnabled)
+		}
+		if err != nil {
+			level.Warn(util_log.Logger).Log("msg", "skipped invalid tenant overrides", "tenant", userID, "err", err)
+			continue
+		}
+
+		values.TenantLimits[userID] = limits
+	}
+
+	return values, nil
+}
+
+// applyTenantOverrides returns the limits resulting from overriding the base limits with the
+// given ones, keyed by YAML name. If the base limits are nil, the default limits are overridden.
+func applyTenantOverrides(base *validation.Limits, overrides map[string]any) (*validation.Limits, error) {
+	merged := map[any]any{}
+	if base != nil {
+		var err error
+		if merged, err = util.YAMLMarshalUnmarshal(base); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, value := range overrides {
+		merged[name] = value
+	}
+
+	buf, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+
+	// The limits are reset to the default ones when unmarshalled.
+	limits := &validation.Limits{}
+	if err := yaml.UnmarshalStrict(buf, limits); err != nil {
+		return nil, err
+	}
+
+	// The secrets are masked when marshalled, so they're restored from the base limits unless overridden.
+	if _, ok := overrides["ruler_alertmanager_config"]; !ok && base != nil {
+		limits.RulerAlertmanagerConfig.BasicAuthPassword = base.RulerAlertmanagerConfig.BasicAuthPassword
+	}
+	return limits, nil
+}
+
+type tenantOverridesResponse struct {
+	Overrides map[string]any     `yaml:"overrides"`
+	Limits    *validation.Limits `yaml:"limits"`
+}
+
+// tenantOverridesAPI serves the API to read, patch and delete the overrides of a tenant
+// stored in the runtime config bucket. Every change is logged for auditing purposes.
+type tenantOverridesAPI struct {
+	manager       *runtimeconfig.Manager
+	cfg           Config
+	allowedLimits map[string]struct{}
+	logger        log.Logger
+
+	// mtx serializes the read-modify-write of the changes applied through this instance. The
+	// bucket doesn't support conditional writes, so the changes must be all routed to a single
+	// instance to not overwrite each other.
+	mtx sync.Mutex
+}
+
+func newTenantOverridesAPI(manager *runtimeconfig.Manager, cfg Config, logger log.Logger) (*tenantOverridesAPI, error) {
+	a := &tenantOverridesAPI{
+		manager:       manager,
+		cfg:           cfg,
+		allowedLimits: map[string]struct{}{},
+		logger:        logger,
+	}
+
+	known, err := util.YAMLMarshalUnmarshal(cfg.LimitsConfig)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range cfg.RuntimeConfig.TenantOverrides.TenantAllowedLimits {
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("unknown limit %q in the tenant allowed limits", name)
+		}
+		a.allowedLimits[name] = struct{}{}
+	}
+
+	return a, nil
+}
+
+// AdminHandler serves the overrides of the tenant in the URL, without restrictions.
+func (a *tenantOverridesAPI) AdminHandler(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["tenant"]
+	if err := users.ValidTenantID(userID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	a.serve(w, r, userID, changedByAdmin)
+}
+
+// TenantHandler serves the overrides of the tenant of the request, which can only patch the
+// allowed limits.
+func (a *tenantOverridesAPI) TenantHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := users.TenantID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	a.serve(w, r, userID, changedByTenant)
+}
+
+func (a *tenantOverridesAPI) serve(w http.ResponseWriter, r *http.Request, userID, changedBy string) {
+	switch r.Method {
+	case http.MethodPatch:
+		a.patch(w, r, userID, changedBy)
+	case http.MethodDelete:
+		a.delete(w, r, userID, changedBy)
+	default:
+		a.get(w, r, userID)
+	}
+}
+
+func (a *tenantOverridesAPI) get(w http.ResponseWriter, r *http.Request, userID string) {
+	overrides, err := a.loadOverrides(r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	a.respond(w, userID, overrides)
+}
+
+func (a *tenantOverridesAPI) patch(w http.ResponseWriter, r *http.Request, userID, changedBy string) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxTenantOverridesSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// A null value removes the override of the limit.
+	patch := map[string]any{}
+	if err := yaml.Unmarshal(body, &patch); err != nil {
+		http.Error(w, fmt.Sprintf("invalid overrides: %s", err), http.StatusBadRequest)
+		return
+	}
+	if len(patch) == 0 {
+		http.Error(w, "no overrides to patch", http.StatusBadRequest)
+		return
+	}
+
+	if changedBy == changedByTenant {
+		for name := range patch {
+			if _, ok := a.allowedLimits[name]; !ok {
+				http.Error(w, fmt.Sprintf("the limit %s can't be changed by the tenant", name), http.StatusForbidden)
+				return
+			}
+		}
+	}
+
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	overrides, err := a.loadOverrides(r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for name, value := range patch {
+		if value == nil {
+			delete(overrides, name)
+		} else {
+			overrides[name] = value
+		}
+	}
+
+	limits, err := applyTenantOverrides(a.fileLimits(userID), overrides)
+	if err == nil {
+		err = limits.Validate(a.cfg.NameValidationScheme, a.cfg.Distributor.ShardByAllLabels, a.cfg.Ingester.ActiveSeriesMetricsEnabled)
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid overrides: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if len(overrides) == 0 {
+		err = a.manager.DeleteTenantOverrides(r.Context(), userID)
+	} else {
+		var buf []byte
+		if buf, err = yaml.Marshal(overrides); err == nil {
+			err = a.manager.SetTenantOverrides(r.Context(), userID, buf)
+		}
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	changes, _ := json.Marshal(patch)
+	level.Info(a.logger).Log("msg", "tenant overrides patched", "tenant", userID, "changed_by", changedBy, "remote_addr", r.RemoteAddr, "changes", string(changes))
+
+	a.respond(w, userID, overrides)
+}
+
+func (a *tenantOverridesAPI) delete(w http.ResponseWriter, r *http.Request, userID, changedBy string) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if err := a.manager.DeleteTenantOverrides(r.Context(), userID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	level.Info(a.logger).Log("msg", "tenant overrides deleted", "tenant", userID, "changed_by", changedBy, "remote_addr", r.RemoteAddr)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadOverrides returns the overrides of the tenant stored in the bucket, keyed by YAML name.
+func (a *tenantOverridesAPI) loadOverrides(r *http.Request, userID string) (map[string]any, error) {
+	overrides := map[string]any{}
+
+	buf, err := a.manager.GetTenantOverrides(r.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(buf, &overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// fileLimits returns the limits of the tenant in the runtime config file, or nil if there are none.
+func (a *tenantOverridesAPI) fileLimits(userID string) *validation.Limits {
+	if cfg, ok := a.manager.GetConfig().(*RuntimeConfigValues); ok && cfg != nil {
+		return cfg.fileTenantLimits[userID]
+	}
+	return nil
+}
+
+// respond writes the stored overrides of the tenant along with its currently applied limits.
+func (a *tenantOverridesAPI) respond(w http.ResponseWriter, userID string, overrides map[string]any) {
+	limits := &a.cfg.LimitsConfig
+	if cfg, ok := a.manager.GetConfig().(*RuntimeConfigValues); ok && cfg != nil && cfg.TenantLimits[userID] != nil {
+		limits = cfg.TenantLimits[userID]
+	}
+
+	util.WriteYAMLResponse(w, tenantOverridesResponse{Overrides: overrides, Limits: limits})
+}
@@ -0,0 +1,196 @@
+package cortex
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"github.com/weaveworks/common/user"
+	"gopkg.in/yaml.v2"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/runtimeconfig"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+func TestApplyTenantOverrides(t *testing.T) {
+	base := &validation.Limits{IngestionRate: 100, IngestionBurstSize: 200}
+
+	limits, err := applyTenantOverrides(base, map[string]any{"ingestion_rate": 50})
+	require.NoError(t, err)
+	assert.Equal(t, float64(50), limits.IngestionRate)
+	assert.Equal(t, 200, limits.IngestionBurstSize)
+
+	// The base limits should not be modified.
+	assert.Equal(t, float64(100), base.IngestionRate)
+
+	// The secrets of the base limits should be preserved.
+	base.RulerAlertmanagerConfig.BasicAuthPassword = flagext.Secret{Value: "password"}
+	limits, err = applyTenantOverrides(base, map[string]any{"ingestion_rate": 50})
+	require.NoError(t, err)
+	assert.Equal(t, "password", limits.RulerAlertmanagerConfig.BasicAuthPassword.Value)
+
+	limits, err = applyTenantOverrides(base, map[string]any{"ruler_alertmanager_config": map[string]any{"alertmanager_url": "http://alertmanager"}})
+	require.NoError(t, err)
+	assert.Empty(t, limits.RulerAlertmanagerConfig.BasicAuthPassword.Value)
+
+	_, err = applyTenantOverrides(base, map[string]any{"unknown_limit": 1})
+	require.Error(t, err)
+
+	_, err = applyTenantOverrides(base, map[string]any{"ingestion_rate": "xxx"})
+	require.Error(t, err)
+}
+
+func TestRuntimeConfigLoader_MergeTenantOverrides(t *testing.T) {
+	loader := runtimeConfigLoader{}
+	config, err := loader.load(strings.NewReader(`
+overrides:
+  user-1:
+    ingestion_rate: 100
+    ingestion_burst_size: 200
+`))
+	require.NoError(t, err)
+	fileLimits := config.(*RuntimeConfigValues).TenantLimits["user-1"]
+
+	merged, err := loader.mergeTenantOverrides(config, map[string][]byte{
+		"user-1": []byte("ingestion_rate: 50"),
+		"user-2": []byte("ingestion_burst_size: 10"),
+		"user-3": []byte("unknown_limit: 10"),
+	})
+	require.NoError(t, err)
+
+	values := merged.(*RuntimeConfigValues)
+	require.Len(t, values.TenantLimits, 2)
+	assert.Equal(t, float64(50), values.TenantLimits["user-1"].IngestionRate)
+	assert.Equal(t, 200, values.TenantLimits["user-1"].IngestionBurstSize)
+	assert.Equal(t, 10, values.TenantLimits["user-2"].IngestionBurstSize)
+
+	// The runtime config file limits should be preserved.
+	assert.Equal(t, map[string]*validation.Limits{"user-1": fileLimits}, values.fileTenantLimits)
+	assert.Equal(t, float64(100), fileLimits.IngestionRate)
+}
+
+func TestTenantOverridesAPI(t *testing.T) {
+	ctx := context.Background()
+
+	bkt := objstore.NewInMemBucket()
+	require.NoError(t, bkt.Upload(ctx, "runtime.yaml", strings.NewReader(`
+overrides:
+  user-1:
+    ingestion_rate: 100
+`)))
+
+	cfg := Config{}
+	flagext.DefaultValues(&cfg.LimitsConfig)
+	cfg.RuntimeConfig = runtimeconfig.Config{
+		ReloadPeriod:  time.Minute,
+		LoadPath:      "runtime.yaml",
+		StorageConfig: bucket.Config{Backend: bucket.Filesystem},
+		TenantOverrides: runtimeconfig.TenantOverridesConfig{
+			Enabled:             true,
+			Prefix:              "tenant-overrides",
+			TenantAllowedLimits: []string{"ingestion_burst_size"},
+		},
+	}
+	loader := runtimeConfigLoader{cfg: cfg}
+	cfg.RuntimeConfig.Loader = loader.load
+	cfg.RuntimeConfig.Merger = loader.mergeTenantOverrides
+
+	manager, err := runtimeconfig.New(cfg.RuntimeConfig, nil, log.NewNopLogger(), func(context.Context) (objstore.Bucket, error) { return bkt, nil })
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, manager))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(ctx, manager))
+	})
+
+	api, err := newTenantOverridesAPI(manager, cfg, log.NewNopLogger())
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.Path("/runtime_config/overrides/{tenant}").HandlerFunc(api.AdminHandler)
+	router.Path("/api/v1/overrides").HandlerFunc(api.TenantHandler)
+
+	do := func(method, path, body string) (int, tenantOverridesResponse) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req = req.WithContext(user.InjectOrgID(req.Context(), "user-1"))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		var resp tenantOverridesResponse
+		if recorder.Code == http.StatusOK {
+			require.NoError(t, yaml.Unmarshal(recorder.Body.Bytes(), &resp))
+		}
+		return recorder.Code, resp
+	}
+
+	t.Run("should return the runtime config file limits if there are no overrides", func(t *testing.T) {
+		code, resp := do("GET", "/runtime_config/overrides/user-1", "")
+		require.Equal(t, http.StatusOK, code)
+		assert.Empty(t, resp.Overrides)
+		assert.Equal(t, float64(100), resp.Limits.IngestionRate)
+	})
+
+	t.Run("should reject invalid overrides", func(t *testing.T) {
+		code, _ := do("PATCH", "/runtime_config/overrides/user-1", "unknown_limit: 1")
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, _ = do("PATCH", "/runtime_config/overrides/user-1", "ingestion_rate: xxx")
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, _ = do("PATCH", "/runtime_config/overrides/user-1", "")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("should patch the overrides through the admin API", func(t *testing.T) {
+		code, resp := do("PATCH", "/runtime_config/overrides/user-1", "ingestion_rate: 50\nmax_label_names_per_series: 10")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, map[string]any{"ingestion_rate": 50, "max_label_names_per_series": 10}, resp.Overrides)
+		assert.Equal(t, float64(50), resp.Limits.IngestionRate)
+		assert.Equal(t, 10, manager.GetConfig().(*RuntimeConfigValues).TenantLimits["user-1"].MaxLabelNamesPerSeries)
+	})
+
+	t.Run("should only allow tenants to patch the allowed limits", func(t *testing.T) {
+		code, _ := do("PATCH", "/api/v1/overrides", "ingestion_rate: 1000")
+		assert.Equal(t, http.StatusForbidden, code)
+
+		code, resp := do("PATCH", "/api/v1/overrides", "ingestion_burst_size: 1000\n")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, 1000, resp.Limits.IngestionBurstSize)
+		assert.Equal(t, float64(50), resp.Limits.IngestionRate)
+	})
+
+	t.Run("should remove an override patched with a null value", func(t *testing.T) {
+		code, resp := do("PATCH", "/runtime_config/overrides/user-1", "ingestion_rate: null")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, map[string]any{"ingestion_burst_size": 1000, "max_label_names_per_series": 10}, resp.Overrides)
+		assert.Equal(t, float64(100), resp.Limits.IngestionRate)
+	})
+
+	t.Run("should delete the overrides", func(t *testing.T) {
+		code, _ := do("DELETE", "/runtime_config/overrides/user-1", "")
+		require.Equal(t, http.StatusNoContent, code)
+
+		code, resp := do("GET", "/api/v1/overrides", "")
+		require.Equal(t, http.StatusOK, code)
+		assert.Empty(t, resp.Overrides)
+		assert.Equal(t, float64(100), resp.Limits.IngestionRate)
+	})
+}
+
+func TestNewTenantOverridesAPI_ShouldFailOnUnknownAllowedLimit(t *testing.T) {
+	cfg := Config{}
+	cfg.RuntimeConfig.TenantOverrides.TenantAllowedLimits = []string{"unknown_limit"}
+
+	_, err := newTenantOverridesAPI(nil, cfg, log.NewNopLogger())
+	require.ErrorContains(t, err, "unknown_limit")
+}
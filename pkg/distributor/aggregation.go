@@ -0,0 +1,691 @@
+package distributor
+
+import (
+	"context"
+	"flag"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/distributor/distributorpb"
+	"github.com/cortexproject/cortex/pkg/ring"
+	"github.com/cortexproject/cortex/pkg/util/grpcclient"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+const (
+	// aggregationFlushPeriod is how frequently the aggregator checks for intervals to emit.
+	aggregationFlushPeriod = time.Second
+
+	// aggregationRingNumTokens is the number of tokens each distributor registers in the
+	// distributors ring when the aggregation is enabled, to evenly shard the output series.
+	aggregationRingNumTokens = 128
+
+	// aggregationCounterStaleness is the minimum period after which the last sample of an
+	// input series of a rate or increase rule is forgotten.
+	aggregationCounterStaleness = 5 * time.Minute
+
+	// aggregationForwardWorkers is the number of goroutines forwarding the samples to
+	// aggregate to the distributors owning their output series.
+	aggregationForwardWorkers = 10
+)
+
+// Reasons of the samples discarded by the aggregator.
+const (
+	aggregationForwardQueueFull = "forward_queue_full"
+	aggregationNotOwned         = "not_owned"
+	aggregationRingError        = "ring_error"
+	aggregationMaxSeries        = "max_series"
+)
+
+var errAggregationDisabled = errors.New("streaming aggregation is disabled in this distributor")
+
+type AggregationConfig struct {
+	Enabled          bool              `yaml:"enabled"`
+	FlushDelay       time.Duration     `yaml:"flush_delay"`
+	ForwardTimeout   time.Duration     `yaml:"forward_timeout"`
+	ForwardQueueSize int               `yaml:"forward_queue_size"`
+	GRPCClientConfig grpcclient.Config `yaml:"grpc_client_config"`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet
+func (cfg *AggregationConfig) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "distributor.aggregation.enabled", false, "[Experimental] If enabled, the distributors aggregate the series matching the aggregation_rules of the tenants. The distributors join the distributors ring, which shards the output series across them.")
+	f.DurationVar(&cfg.FlushDelay, "distributor.aggregation.flush-delay", 30*time.Second, "How long to wait after the end of an interval before emitting the output samples, so that the samples received late are aggregated. The samples received after are discarded.")
+	f.DurationVar(&cfg.ForwardTimeout, "distributor.aggregation.forward-timeout", 2*time.Second, "Timeout for forwarding the samples to aggregate to the distributor owning their output series.")
+	f.IntVar(&cfg.ForwardQueueSize, "distributor.aggregation.forward-queue-size", 10000, "Maximum number of requests queued to be forwarded to the distributors owning their output series. The samples are forwarded asynchronously, and discarded if the queue is full.")
+	cfg.GRPCClientConfig.RegisterFlagsWithPrefix("distributor.aggregation.grpc-client-config", "", f)
+}
+
+type aggregationLimits interface {
+	AggregationRules(userID string) []validation.AggregationRule
+	AggregationMaxSeries(userID string) int
+}
+
+type aggregationContextKey int
+
+const skipAggregationKey aggregationContextKey = 0
+
+// contextWithoutAggregation marks the context of the push requests of the output series,
+// whose series are never aggregated again.
+func contextWithoutAggregation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipAggregationKey, true)
+}
+
+func skipAggregation(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipAggregationKey).(bool)
+	return skip
+}
+
+// aggregationRulesFor returns whether the series matches any of the rules, and whether
+// the series must be dropped because all the rules it matches drop their input.
+func aggregationRulesFor(rules []validation.AggregationRule, lbls labels.Labels) (matched, dropInput bool) {
+	dropInput = true
+	for _, rule := range rules {
+		if matchesAll(rule.Matchers, lbls) {
+			matched = true
+			dropInput = dropInput && rule.DropInput
+		}
+	}
+	return matched, matched && dropInput
+}
+
+func matchesAll(matchers []*labels.Matcher, lbls labels.Labels) bool {
+	for _, m := range matchers {
+		if !m.Matches(lbls.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+func aggregationOutputLabels(b *labels.Builder, rule validation.AggregationRule, lbls labels.Labels) labels.Labels {
+	b.Reset(lbls)
+	if len(rule.By) > 0 {
+		b.Keep(rule.By...)
+	} else {
+		b.Del(rule.Without...)
+	}
+	b.Set(labels.MetricName, rule.Output)
+	return b.Labels()
+}
+
+// aggregationOutput is the state of an output series within an interval.
+type aggregationOutput struct {
+	labels labels.Labels
+
+	// Latest sample of each input series, for the sum, count, min, max and avg operations.
+	latest map[uint64]cortexpb.Sample
+
+	// Sum of the counter increases of the input series, for the rate and increase operations.
+	increase    float64
+	hasIncrease bool
+}
+
+// aggregationCounter is the last sample of an input series of a rate or increase rule.
+type aggregationCounter struct {
+	value     float64
+	timestamp int64
+	lastSeen  time.Time
+}
+
+type aggregationRuleState struct {
+	rule validation.AggregationRule
+
+	// Samples of the intervals ending at or before flushedUntil are late.
+	flushedUntil int64
+	intervals    map[int64]map[uint64]*aggregationOutput
+	counters     map[uint64]*aggregationCounter
+
+	// Number of input series tracked, once per interval for the latest samples and once
+	// for the last sample of the counters.
+	series int
+}
+
+func newAggregationRuleState(rule validation.AggregationRule) *aggregationRuleState {
+	return &aggregationRuleState{
+		rule:      rule,
+		intervals: map[int64]map[uint64]*aggregationOutput{},
+		counters:  map[uint64]*aggregationCounter{},
+	}
+}
+
+func sameAggregationRule(a, b validation.AggregationRule) bool {
+	return a.Match == b.Match && a.Output == b.Output && a.Operation == b.Operation && a.Interval == b.Interval &&
+		slices.Equal(a.By, b.By) && slices.Equal(a.Without, b.Without)
+}
+
+type aggregationResult int
+
+const (
+	aggregationAdded aggregationResult = iota
+	aggregationLate
+	aggregationLimited
+)
+
+// add aggregates a sample of an input series. The sample is late if its interval has been
+// emitted or ended before lateBefore, and limited if it would track a new input series
+// while the tenant has reached its maximum number of series.
+func (s *aggregationRuleState) add(outputLabels labels.Labels, outputHash, inputHash uint64, sample cortexpb.Sample, lateBefore int64, full bool, now time.Time) aggregationResult {
+	interval := time.Duration(s.rule.Interval).Milliseconds()
+	// The interval ending at end covers the samples in (end-interval, end].
+	end := sample.TimestampMs - mod(sample.TimestampMs, interval)
+	if end != sample.TimestampMs {
+		end += interval
+	}
+	if end <= s.flushedUntil || end <= lateBefore {
+		return aggregationLate
+	}
+
+	switch s.rule.Operation {
+	case validation.AggregationRate, validation.AggregationIncrease:
+		counter, ok := s.counters[inputHash]
+		if !ok {
+			if full {
+				return aggregationLimited
+			}
+			s.counters[inputHash] = &aggregationCounter{value: sample.Value, timestamp: sample.TimestampMs, lastSeen: now}
+			s.series++
+			return aggregationAdded
+		}
+		if sample.TimestampMs <= counter.timestamp {
+			// Out of order or duplicate sample.
+			return aggregationAdded
+		}
+		delta := sample.Value - counter.value
+		if delta < 0 {
+			// Counter reset.
+			delta = sample.Value
+		}
+		output := s.output(end, outputLabels, outputHash)
+		output.increase += delta
+		output.hasIncrease = true
+		counter.value, counter.timestamp, counter.lastSeen = sample.Value, sample.TimestampMs, now
+
+	default:
+		var (
+			latest cortexpb.Sample
+			ok     bool
+		)
+		if output := s.intervals[end][outputHash]; output != nil {
+			latest, ok = output.latest[inputHash]
+		}
+		if !ok && full {
+			return aggregationLimited
+		}
+
+		output := s.output(end, outputLabels, outputHash)
+		if output.latest == nil {
+			output.latest = map[uint64]cortexpb.Sample{}
+		}
+		if !ok {
+			s.series++
+		}
+		if !ok || sample.TimestampMs >= latest.TimestampMs {
+			output.latest[inputHash] = sample
+		}
+	}
+	return aggregationAdded
+}
+
+// output returns the state of an output series within the interval ending at end, creating it if needed.
+func (s *aggregationRuleState) output(end int64, outputLabels labels.Labels, outputHash uint64) *aggregationOutput {
+	outputs, ok := s.intervals[end]
+	if !ok {
+		outputs = map[uint64]*aggregationOutput{}
+		s.intervals[end] = outputs
+	}
+	output, ok := outputs[outputHash]
+	if !ok {
+		// The labels of the request can't be retained.
+		output = &aggregationOutput{labels: cortexpb.FromLabelAdaptersToLabelsWithCopy(cortexpb.FromLabelsToLabelAdapters(outputLabels))}
+		outputs[outputHash] = output
+	}
+	return output
+}
+
+// flush removes the intervals ended before the given time, and returns their output samples.
+func (s *aggregationRuleState) flush(before int64, now time.Time) ([]labels.Labels, []cortexpb.Sample) {
+	var (
+		series  []labels.Labels
+		samples []cortexpb.Sample
+	)
+
+	ends := make([]int64, 0, len(s.intervals))
+	for end := range s.intervals {
+		if end <= before {
+			ends = append(ends, end)
+		}
+	}
+	slices.Sort(ends)
+
+	for _, end := range ends {
+		for _, output := range s.intervals[end] {
+			s.series -= len(output.latest)
+			value, ok := output.value(s.rule)
+			if !ok {
+				continue
+			}
+			series = append(series, output.labels)
+			samples = append(samples, cortexpb.Sample{TimestampMs: end, Value: value})
+		}
+		delete(s.intervals, end)
+		s.flushedUntil = end
+	}
+
+	staleness := max(aggregationCounterStaleness, 2*time.Duration(s.rule.Interval))
+	for h, counter := range s.counters {
+		if now.Sub(counter.lastSeen) > staleness {
+			delete(s.counters, h)
+			s.series--
+		}
+	}
+
+	return series, samples
+}
+
+func (o *aggregationOutput) value(rule validation.AggregationRule) (float64, bool) {
+	switch rule.Operation {
+	case validation.AggregationIncrease:
+		return o.increase, o.hasIncrease
+	case validation.AggregationRate:
+		return o.increase / time.Duration(rule.Interval).Seconds(), o.hasIncrease
+	}
+
+	if len(o.latest) == 0 {
+		return 0, false
+	}
+
+	var sum float64
+	minValue, maxValue := math.Inf(1), math.Inf(-1)
+	for _, s := range o.latest {
+		sum += s.Value
+		minValue = math.Min(minValue, s.Value)
+		maxValue = math.Max(maxValue, s.Value)
+	}
+
+	switch rule.Operation {
+	case validation.AggregationCount:
+		return float64(len(o.latest)), true
+	case validation.AggregationMin:
+		return minValue, true
+	case validation.AggregationMax:
+		return maxValue, true
+	case validation.AggregationAvg:
+		return sum / float64(len(o.latest)), true
+	}
+	return sum, true
+}
+
+func mod(a, b int64) int64 {
+	m := a % b
+	if m < 0 {
+		m += b
+	}
+	return m
+}
+
+// aggregator aggregates the series matching the aggregation rules of the tenants, and
+// periodically pushes the output series. Each output series is aggregated by the distributor
+// owning it in the distributors ring, to which the other distributors forward the samples.
+type aggregator struct {
+	services.Service
+
+	cfg    AggregationConfig
+	limits aggregationLimits
+	logger log.Logger
+	now    func() time.Time
+
+	// Distributors ring and address of this distributor in the ring. If the ring is nil,
+	// all the output series are owned by this distributor.
+	ring    ring.ReadRing
+	addr    string
+	clients func(addr string) (distributorpb.DistributorClient, error)
+
+	// Pushes the output series.
+	push func(ctx context.Context, req *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error)
+
+	mtx     sync.Mutex
+	tenants map[string]map[string]*aggregationRuleState
+
+	// Series to forward to the distributors owning their output series.
+	forwardQueue   chan aggregationForward
+	forwardWorkers sync.WaitGroup
+
+	aggregatedSamples *prometheus.CounterVec
+	lateSamples       *prometheus.CounterVec
+	discardedSamples  *prometheus.CounterVec
+	forwardedSamples  *prometheus.CounterVec
+	forwardFailures   *prometheus.CounterVec
+	outputSamples     *prometheus.CounterVec
+	outputFailures    *prometheus.CounterVec
+}
+
+func newAggregator(cfg AggregationConfig, limits aggregationLimits, r ring.ReadRing, addr string, clients func(addr string) (distributorpb.DistributorClient, error), push func(ctx context.Context, req *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error), logger log.Logger, reg prometheus.Registerer) *aggregator {
+	a := &aggregator{
+		cfg:     cfg,
+		limits:  limits,
+		logger:  logger,
+		now:     time.Now,
+		ring:    r,
+		addr:    addr,
+		clients: clients,
+		push:    push,
+		tenants: map[string]map[string]*aggregationRuleState{},
+
+		forwardQueue: make(chan aggregationForward, cfg.ForwardQueueSize),
+
+		aggregatedSamples: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_aggregation_samples_total",
+			Help: "The total number of samples aggregated by this distributor.",
+		}, []string{"user"}),
+		lateSamples: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_aggregation_late_samples_total",
+			Help: "The total number of samples discarded because received after their interval has been emitted.",
+		}, []string{"user"}),
+		discardedSamples: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_aggregation_discarded_samples_total",
+			Help: "The total number of samples to aggregate discarded by this distributor.",
+		}, []string{"user", "reason"}),
+		forwardedSamples: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_aggregation_forwarded_samples_total",
+			Help: "The total number of samples forwarded to the distributors owning their output series.",
+		}, []string{"user"}),
+		forwardFailures: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_aggregation_forward_failures_total",
+			Help: "The total number of samples which failed to be forwarded to the distributors owning their output series.",
+		}, []string{"user"}),
+		outputSamples: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_aggregation_output_samples_total",
+			Help: "The total number of output samples pushed by this distributor.",
+		}, []string{"user"}),
+		outputFailures: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_aggregation_output_failures_total",
+			Help: "The total number of output samples which failed to be pushed.",
+		}, []string{"user"}),
+	}
+
+	a.Service = services.NewTimerService(aggregationFlushPeriod, a.starting, a.iteration, a.stopping)
+	return a
+}
+
+// aggregationForward is a request to forward the series of a tenant to the distributor at addr.
+type aggregationForward struct {
+	userID     string
+	addr       string
+	series     []cortexpb.PreallocTimeseries
+	numSamples int
+}
+
+// Push aggregates the samples of the series whose output series are owned by this
+// distributor, and queues the series to forward to the distributors owning the other ones.
+// The series are copied before being queued, so they're not retained.
+func (a *aggregator) Push(userID string, series []cortexpb.PreallocTimeseries) {
+	forward := map[string][]cortexpb.PreallocTimeseries{}
+	a.aggregate(userID, series, forward)
+
+	for addr, series := range forward {
+		req := aggregationForward{userID: userID, addr: addr, series: series}
+		for _, ts := range series {
+			req.numSamples += len(ts.Samples)
+		}
+
+		select {
+		case a.forwardQueue <- req:
+		default:
+			a.discardedSamples.WithLabelValues(userID, aggregationForwardQueueFull).Add(float64(req.numSamples))
+		}
+	}
+}
+
+// forwardLoop forwards the queued series until the context is canceled.
+func (a *aggregator) forwardLoop(ctx context.Context) {
+	defer a.forwardWorkers.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case req := <-a.forwardQueue:
+			forwardCtx, cancel := context.WithTimeout(user.InjectOrgID(ctx, req.userID), a.cfg.ForwardTimeout)
+			err := a.forward(forwardCtx, req.addr, req.series)
+			cancel()
+
+			if err != nil {
+				level.Warn(a.logger).Log("msg", "failed to forward samples to aggregate", "distributor", req.addr, "user", req.userID, "err", err)
+				a.forwardFailures.WithLabelValues(req.userID).Add(float64(req.numSamples))
+				continue
+			}
+			a.forwardedSamples.WithLabelValues(req.userID).Add(float64(req.numSamples))
+		}
+	}
+}
+
+func (a *aggregator) forward(ctx context.Context, addr string, series []cortexpb.PreallocTimeseries) error {
+	c, err := a.clients(addr)
+	if err != nil {
+		return err
+	}
+	_, err = c.Aggregate(ctx, &cortexpb.WriteRequest{Timeseries: series, Source: cortexpb.API})
+	return err
+}
+
+// aggregate aggregates the samples of the series whose output series are owned by this
+// distributor. If forward is not nil, it's filled with a copy of the series to forward to
+// the owners of the other output series, otherwise they're discarded.
+func (a *aggregator) aggregate(userID string, series []cortexpb.PreallocTimeseries, forward map[string][]cortexpb.PreallocTimeseries) {
+	rules := a.limits.AggregationRules(userID)
+	if len(rules) == 0 {
+		return
+	}
+
+	now := a.now()
+	lateBefore := now.Add(-a.cfg.FlushDelay).UnixMilli()
+	b := labels.NewBuilder(labels.EmptyLabels())
+	bufDescs := make([]ring.InstanceDesc, 0, 1)
+	maxSeries := a.limits.AggregationMaxSeries(userID)
+	aggregated, late := 0, 0
+	discarded := map[string]int{}
+	var forwardedTo []string
+
+	a.mtx.Lock()
+	defer func() {
+		a.mtx.Unlock()
+
+		if aggregated > 0 {
+			a.aggregatedSamples.WithLabelValues(userID).Add(float64(aggregated))
+		}
+		if late > 0 {
+			a.lateSamples.WithLabelValues(userID).Add(float64(late))
+		}
+		for reason, n := range discarded {
+			a.discardedSamples.WithLabelValues(userID, reason).Add(float64(n))
+		}
+	}()
+
+	states := a.ruleStates(userID, rules)
+	trackedSeries := 0
+	for _, state := range states {
+		trackedSeries += state.series
+	}
+
+	for _, ts := range series {
+		lbls := cortexpb.FromLabelAdaptersToLabels(ts.Labels)
+		inputHash := lbls.Hash()
+		forwardedTo = forwardedTo[:0]
+
+		for _, rule := range rules {
+			if !matchesAll(rule.Matchers, lbls) {
+				continue
+			}
+
+			outputLabels := aggregationOutputLabels(b, rule, lbls)
+			owner, err := a.owner(userID, outputLabels, bufDescs)
+			if err != nil {
+				level.Warn(a.logger).Log("msg", "failed to find the owner of the aggregation output series", "user", userID, "series", outputLabels.String(), "err", err)
+				discarded[aggregationRingError] += len(ts.Samples)
+				continue
+			}
+
+			if owner != a.addr {
+				if forward == nil {
+					// The series has been forwarded by a distributor whose view of the ring differs.
+					discarded[aggregationNotOwned] += len(ts.Samples)
+				} else if !slices.Contains(forwardedTo, owner) {
+					forwardedTo = append(forwardedTo, owner)
+					forward[owner] = append(forward[owner], copyAggregationSeries(ts))
+				}
+				continue
+			}
+
+			state := states[rule.Output]
+			outputHash := outputLabels.Hash()
+			for _, s := range ts.Samples {
+				before := state.series
+				switch state.add(outputLabels, outputHash, inputHash, s, lateBefore, maxSeries > 0 && trackedSeries >= maxSeries, now) {
+				case aggregationAdded:
+					aggregated++
+				case aggregationLate:
+					late++
+				case aggregationLimited:
+					discarded[aggregationMaxSeries]++
+				}
+				trackedSeries += state.series - before
+			}
+		}
+	}
+}
+
+// ruleStates returns the state of the aggregation rules of the tenant, resetting the
+// state of the rules which changed. Must be called with the lock held.
+func (a *aggregator) ruleStates(userID string, rules []validation.AggregationRule) map[string]*aggregationRuleState {
+	states, ok := a.tenants[userID]
+	if !ok {
+		states = make(map[string]*aggregationRuleState, len(rules))
+		a.tenants[userID] = states
+	}
+
+	for _, rule := range rules {
+		if state, ok := states[rule.Output]; !ok || !sameAggregationRule(state.rule, rule) {
+			states[rule.Output] = newAggregationRuleState(rule)
+		}
+	}
+	for output := range states {
+		if !slices.ContainsFunc(rules, func(r validation.AggregationRule) bool { return r.Output == output }) {
+			delete(states, output)
+		}
+	}
+	return states
+}
+
+func (a *aggregator) owner(userID string, outputLabels labels.Labels, bufDescs []ring.InstanceDesc) (string, error) {
+	if a.ring == nil {
+		return a.addr, nil
+	}
+
+	token := shardByAllLabels(userID, cortexpb.FromLabelsToLabelAdapters(outputLabels))
+	set, err := a.ring.Get(token, ring.WriteNoExtend, bufDescs, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	return set.Instances[0].Addr, nil
+}
+
+// copyAggregationSeries copies the labels and float samples of a series, which are the
+// only parts aggregated.
+func copyAggregationSeries(ts cortexpb.PreallocTimeseries) cortexpb.PreallocTimeseries {
+	return cortexpb.PreallocTimeseries{TimeSeries: &cortexpb.TimeSeries{
+		Labels:  cortexpb.FromLabelsToLabelAdapters(cortexpb.FromLabelAdaptersToLabelsWithCopy(ts.Labels)),
+		Samples: slices.Clone(ts.Samples),
+	}}
+}
+
+func (a *aggregator) starting(ctx context.Context) error {
+	// The workers are stopped when the service context is canceled, on stopping.
+	for i := 0; i < aggregationForwardWorkers; i++ {
+		a.forwardWorkers.Add(1)
+		go a.forwardLoop(ctx)
+	}
+	return nil
+}
+
+func (a *aggregator) iteration(ctx context.Context) error {
+	a.flush(ctx, a.now().Add(-a.cfg.FlushDelay))
+	return nil
+}
+
+func (a *aggregator) stopping(_ error) error {
+	a.forwardWorkers.Wait()
+
+	// Emit the intervals which have ended, without waiting for the late samples.
+	a.flush(context.Background(), a.now())
+	return nil
+}
+
+// flush pushes the output samples of the intervals ended before the given time.
+func (a *aggregator) flush(ctx context.Context, before time.Time) {
+	type output struct {
+		series  []labels.Labels
+		samples []cortexpb.Sample
+	}
+
+	now := a.now()
+	outputs := map[string]*output{}
+
+	a.mtx.Lock()
+	for userID, states := range a.tenants {
+		rules := a.limits.AggregationRules(userID)
+		if len(rules) == 0 {
+			delete(a.tenants, userID)
+			continue
+		}
+		states = a.ruleStates(userID, rules)
+
+		for _, state := range states {
+			series, samples := state.flush(before.UnixMilli(), now)
+			if len(series) == 0 {
+				continue
+			}
+			o, ok := outputs[userID]
+			if !ok {
+				o = &output{}
+				outputs[userID] = o
+			}
+			o.series = append(o.series, series...)
+			o.samples = append(o.samples, samples...)
+		}
+	}
+	a.mtx.Unlock()
+
+	for userID, o := range outputs {
+		req := cortexpb.ToWriteRequest(o.series, o.samples, nil, nil, cortexpb.API)
+		pushCtx := contextWithoutAggregation(user.InjectOrgID(ctx, userID))
+		if _, err := a.push(pushCtx, req); err != nil {
+			level.Warn(a.logger).Log("msg", "failed to push the aggregation output series", "user", userID, "err", err)
+			a.outputFailures.WithLabelValues(userID).Add(float64(len(o.samples)))
+			continue
+		}
+		a.outputSamples.WithLabelValues(userID).Add(float64(len(o.samples)))
+	}
+}
+
+func (a *aggregator) cleanupUser(userID string) {
+	a.aggregatedSamples.DeleteLabelValues(userID)
+	a.lateSamples.DeleteLabelValues(userID)
+	a.discardedSamples.DeletePartialMatch(prometheus.Labels{"user": userID})
+	a.forwardedSamples.DeleteLabelValues(userID)
+	a.forwardFailures.DeleteLabelValues(userID)
+	a.outputSamples.DeleteLabelValues(userID)
+	a.outputFailures.DeleteLabelValues(userID)
+}
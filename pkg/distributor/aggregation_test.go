@@ -0,0 +1,453 @@
+package distributor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/distributor/distributorpb"
+	"github.com/cortexproject/cortex/pkg/ring"
+	"github.com/cortexproject/cortex/pkg/ring/kv"
+	"github.com/cortexproject/cortex/pkg/ring/kv/consul"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/test"
+	"github.com/cortexproject/cortex/pkg/util/users"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+type mockAggregationLimits map[string][]validation.AggregationRule
+
+func (m mockAggregationLimits) AggregationRules(userID string) []validation.AggregationRule {
+	return m[userID]
+}
+
+func (m mockAggregationLimits) AggregationMaxSeries(string) int {
+	return 0
+}
+
+type mockAggregationLimitsWithMaxSeries struct {
+	mockAggregationLimits
+	maxSeries int
+}
+
+func (m mockAggregationLimitsWithMaxSeries) AggregationMaxSeries(string) int {
+	return m.maxSeries
+}
+
+func aggregationRule(t *testing.T, rule validation.AggregationRule) validation.AggregationRule {
+	t.Helper()
+	matchers, err := parser.ParseMetricSelector(rule.Match)
+	require.NoError(t, err)
+	rule.Matchers = matchers
+	if rule.Interval == 0 {
+		rule.Interval = model.Duration(time.Minute)
+	}
+	return rule
+}
+
+// recordingPusher records the output series pushed by an aggregator, as strings.
+type recordingPusher struct {
+	mtx    sync.Mutex
+	series []string
+}
+
+func (p *recordingPusher) Push(ctx context.Context, req *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	userID, err := users.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !skipAggregation(ctx) {
+		return nil, errors.New("the output series must not be aggregated")
+	}
+	for _, ts := range req.Timeseries {
+		for _, s := range ts.Samples {
+			p.series = append(p.series, fmt.Sprintf("%s %s %g@%d", userID, cortexpb.FromLabelAdaptersToLabels(ts.Labels).String(), s.Value, s.TimestampMs))
+		}
+	}
+	return &cortexpb.WriteResponse{}, nil
+}
+
+func (p *recordingPusher) pushed() []string {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	result := p.series
+	p.series = nil
+	sort.Strings(result)
+	return result
+}
+
+func aggregationSeries(lbls labels.Labels, samples ...cortexpb.Sample) cortexpb.PreallocTimeseries {
+	return cortexpb.PreallocTimeseries{TimeSeries: &cortexpb.TimeSeries{
+		Labels:  cortexpb.FromLabelsToLabelAdapters(lbls),
+		Samples: samples,
+	}}
+}
+
+func TestAggregator_Operations(t *testing.T) {
+	podA := labels.FromStrings(labels.MetricName, "http_requests_total", "job", "api", "pod", "a")
+	podB := labels.FromStrings(labels.MetricName, "http_requests_total", "job", "api", "pod", "b")
+	other := labels.FromStrings(labels.MetricName, "http_requests_total", "job", "web", "pod", "c")
+
+	series := []cortexpb.PreallocTimeseries{
+		// Samples in the interval (0, 60s], except the last one which is in the next interval.
+		aggregationSeries(podA, cortexpb.Sample{TimestampMs: 15000, Value: 10}, cortexpb.Sample{TimestampMs: 45000, Value: 30}, cortexpb.Sample{TimestampMs: 75000, Value: 5}),
+		aggregationSeries(podB, cortexpb.Sample{TimestampMs: 30000, Value: 1}, cortexpb.Sample{TimestampMs: 60000, Value: 7}),
+		aggregationSeries(other, cortexpb.Sample{TimestampMs: 60000, Value: 100}),
+	}
+
+	tests := map[string]struct {
+		rule     validation.AggregationRule
+		expected []string
+	}{
+		"sum without pod": {
+			rule: validation.AggregationRule{Match: `{__name__="http_requests_total"}`, Output: "job:http_requests:sum", Operation: validation.AggregationSum, Without: []string{"pod"}},
+			expected: []string{
+				`user-1 {__name__="job:http_requests:sum", job="api"} 37@60000`,
+				`user-1 {__name__="job:http_requests:sum", job="api"} 5@120000`,
+				`user-1 {__name__="job:http_requests:sum", job="web"} 100@60000`,
+			},
+		},
+		"count by job": {
+			rule: validation.AggregationRule{Match: `{job="api"}`, Output: "job:http_requests:count", Operation: validation.AggregationCount, By: []string{"job"}},
+			expected: []string{
+				`user-1 {__name__="job:http_requests:count", job="api"} 1@120000`,
+				`user-1 {__name__="job:http_requests:count", job="api"} 2@60000`,
+			},
+		},
+		"min without pod and job": {
+			rule: validation.AggregationRule{Match: `{job="api"}`, Output: "http_requests:min", Operation: validation.AggregationMin, Without: []string{"pod", "job"}},
+			expected: []string{
+				`user-1 {__name__="http_requests:min"} 5@120000`,
+				`user-1 {__name__="http_requests:min"} 7@60000`,
+			},
+		},
+		"max": {
+			rule: validation.AggregationRule{Match: `{job="api"}`, Output: "http_requests:max", Operation: validation.AggregationMax, By: []string{"job"}},
+			expected: []string{
+				`user-1 {__name__="http_requests:max", job="api"} 30@60000`,
+				`user-1 {__name__="http_requests:max", job="api"} 5@120000`,
+			},
+		},
+		"avg": {
+			rule: validation.AggregationRule{Match: `{job="api"}`, Output: "http_requests:avg", Operation: validation.AggregationAvg, By: []string{"job"}},
+			expected: []string{
+				`user-1 {__name__="http_requests:avg", job="api"} 18.5@60000`,
+				`user-1 {__name__="http_requests:avg", job="api"} 5@120000`,
+			},
+		},
+		"increase with counter reset": {
+			rule: validation.AggregationRule{Match: `{job="api"}`, Output: "http_requests:increase", Operation: validation.AggregationIncrease, By: []string{"job"}},
+			expected: []string{
+				// pod a: 30-10, pod b: 7-1. Then pod a is reset to 5.
+				`user-1 {__name__="http_requests:increase", job="api"} 26@60000`,
+				`user-1 {__name__="http_requests:increase", job="api"} 5@120000`,
+			},
+		},
+		"rate": {
+			rule: validation.AggregationRule{Match: `{job="api"}`, Output: "http_requests:rate1m", Operation: validation.AggregationRate, By: []string{"job"}},
+			expected: []string{
+				`user-1 {__name__="http_requests:rate1m", job="api"} 0.08333333333333333@120000`,
+				`user-1 {__name__="http_requests:rate1m", job="api"} 0.43333333333333335@60000`,
+			},
+		},
+		"longer interval": {
+			rule: validation.AggregationRule{Match: `{job="api"}`, Output: "http_requests:sum2m", Operation: validation.AggregationSum, By: []string{"job"}, Interval: model.Duration(2 * time.Minute)},
+			expected: []string{
+				`user-1 {__name__="http_requests:sum2m", job="api"} 12@120000`,
+			},
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			pusher := &recordingPusher{}
+			limits := mockAggregationLimits{"user-1": {aggregationRule(t, testData.rule)}}
+			a := newAggregator(AggregationConfig{FlushDelay: 30 * time.Second}, limits, nil, "", nil, pusher.Push, log.NewNopLogger(), prometheus.NewPedanticRegistry())
+			a.now = func() time.Time { return time.UnixMilli(80000) }
+
+			a.aggregate("user-1", series, nil)
+			a.flush(context.Background(), time.UnixMilli(200000))
+			assert.Equal(t, testData.expected, pusher.pushed())
+		})
+	}
+}
+
+func TestAggregator_LateSamples(t *testing.T) {
+	pusher := &recordingPusher{}
+	reg := prometheus.NewPedanticRegistry()
+	rule := aggregationRule(t, validation.AggregationRule{Match: `{__name__="up"}`, Output: "up:sum", Operation: validation.AggregationSum, Without: []string{"instance"}})
+	a := newAggregator(AggregationConfig{FlushDelay: 30 * time.Second}, mockAggregationLimits{"user-1": {rule}}, nil, "", nil, pusher.Push, log.NewNopLogger(), reg)
+
+	now := time.UnixMilli(50000)
+	a.now = func() time.Time { return now }
+	series := func(ts int64, instance string) []cortexpb.PreallocTimeseries {
+		return []cortexpb.PreallocTimeseries{aggregationSeries(labels.FromStrings(labels.MetricName, "up", "instance", instance), cortexpb.Sample{TimestampMs: ts, Value: 1})}
+	}
+
+	a.aggregate("user-1", series(40000, "a"), nil)
+
+	// The interval isn't emitted before the flush delay.
+	require.NoError(t, a.iteration(context.Background()))
+	assert.Empty(t, pusher.pushed())
+
+	// Samples received within the flush delay are aggregated.
+	now = time.UnixMilli(85000)
+	a.aggregate("user-1", series(50000, "b"), nil)
+	require.NoError(t, a.iteration(context.Background()))
+	assert.Empty(t, pusher.pushed())
+
+	now = time.UnixMilli(90000)
+	require.NoError(t, a.iteration(context.Background()))
+	assert.Equal(t, []string{`user-1 {__name__="up:sum"} 2@60000`}, pusher.pushed())
+
+	// Samples of an emitted interval are discarded.
+	a.aggregate("user-1", series(55000, "c"), nil)
+	now = time.UnixMilli(200000)
+	require.NoError(t, a.iteration(context.Background()))
+	assert.Empty(t, pusher.pushed())
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(a.aggregatedSamples.WithLabelValues("user-1")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(a.lateSamples.WithLabelValues("user-1")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(a.outputSamples.WithLabelValues("user-1")))
+}
+
+func TestAggregator_RuleChanges(t *testing.T) {
+	pusher := &recordingPusher{}
+	rule := aggregationRule(t, validation.AggregationRule{Match: `{__name__="up"}`, Output: "up:sum", Operation: validation.AggregationSum, Without: []string{"instance"}})
+	limits := mockAggregationLimits{"user-1": {rule}}
+	a := newAggregator(AggregationConfig{}, limits, nil, "", nil, pusher.Push, log.NewNopLogger(), prometheus.NewPedanticRegistry())
+	a.now = func() time.Time { return time.UnixMilli(0) }
+
+	a.aggregate("user-1", []cortexpb.PreallocTimeseries{aggregationSeries(labels.FromStrings(labels.MetricName, "up", "instance", "a"), cortexpb.Sample{TimestampMs: 1000, Value: 1})}, nil)
+
+	// The state of a rule is reset when it changes, and removed when it's deleted.
+	changed := rule
+	changed.Operation = validation.AggregationMax
+	limits["user-1"] = []validation.AggregationRule{changed}
+	a.flush(context.Background(), time.UnixMilli(100000))
+	assert.Empty(t, pusher.pushed())
+
+	a.aggregate("user-1", []cortexpb.PreallocTimeseries{aggregationSeries(labels.FromStrings(labels.MetricName, "up", "instance", "a"), cortexpb.Sample{TimestampMs: 101000, Value: 1})}, nil)
+	delete(limits, "user-1")
+	a.flush(context.Background(), time.UnixMilli(200000))
+	assert.Empty(t, pusher.pushed())
+	assert.Empty(t, a.tenants)
+}
+
+func TestAggregator_MaxSeries(t *testing.T) {
+	pusher := &recordingPusher{}
+	reg := prometheus.NewPedanticRegistry()
+	sumRule := aggregationRule(t, validation.AggregationRule{Match: `{__name__="up"}`, Output: "up:sum", Operation: validation.AggregationSum, Without: []string{"instance"}})
+	increaseRule := aggregationRule(t, validation.AggregationRule{Match: `{__name__="requests_total"}`, Output: "requests:increase1m", Operation: validation.AggregationIncrease, Without: []string{"instance"}})
+	limits := mockAggregationLimitsWithMaxSeries{mockAggregationLimits: mockAggregationLimits{"user-1": {sumRule, increaseRule}}, maxSeries: 3}
+	a := newAggregator(AggregationConfig{}, limits, nil, "", nil, pusher.Push, log.NewNopLogger(), reg)
+	a.now = func() time.Time { return time.UnixMilli(0) }
+
+	series := func(name, instance string, samples ...cortexpb.Sample) cortexpb.PreallocTimeseries {
+		return aggregationSeries(labels.FromStrings(labels.MetricName, name, "instance", instance), samples...)
+	}
+
+	// The counter of an increase rule and the latest sample of an input series per interval are tracked.
+	a.aggregate("user-1", []cortexpb.PreallocTimeseries{
+		series("requests_total", "a", cortexpb.Sample{TimestampMs: 1000, Value: 1}, cortexpb.Sample{TimestampMs: 2000, Value: 3}),
+		series("up", "a", cortexpb.Sample{TimestampMs: 1000, Value: 1}, cortexpb.Sample{TimestampMs: 61000, Value: 1}),
+		series("up", "b", cortexpb.Sample{TimestampMs: 1000, Value: 1}),
+	}, nil)
+	assert.Equal(t, float64(4), testutil.ToFloat64(a.aggregatedSamples.WithLabelValues("user-1")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(a.discardedSamples.WithLabelValues("user-1", aggregationMaxSeries)))
+
+	// Flushing an interval releases its series.
+	a.flush(context.Background(), time.UnixMilli(60000))
+	assert.Equal(t, []string{`user-1 {__name__="requests:increase1m"} 2@60000`, `user-1 {__name__="up:sum"} 1@60000`}, pusher.pushed())
+
+	a.aggregate("user-1", []cortexpb.PreallocTimeseries{series("up", "b", cortexpb.Sample{TimestampMs: 62000, Value: 1})}, nil)
+	assert.Equal(t, float64(5), testutil.ToFloat64(a.aggregatedSamples.WithLabelValues("user-1")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(a.discardedSamples.WithLabelValues("user-1", aggregationMaxSeries)))
+}
+
+// aggregatorClient is a distributor client calling the Aggregate method of an aggregator.
+type aggregatorClient struct {
+	a *aggregator
+}
+
+func (c aggregatorClient) Push(context.Context, *cortexpb.WriteRequest, ...grpc.CallOption) (*cortexpb.WriteResponse, error) {
+	return nil, errors.New("unexpected call")
+}
+
+func (c aggregatorClient) Aggregate(ctx context.Context, req *cortexpb.WriteRequest, _ ...grpc.CallOption) (*cortexpb.WriteResponse, error) {
+	userID, err := users.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.a.aggregate(userID, req.Timeseries, nil)
+	return &cortexpb.WriteResponse{}, nil
+}
+
+// newAggregationTestRing returns a distributors ring whose instances, by address, own the given tokens.
+func newAggregationTestRing(t *testing.T, tokens map[string][]uint32) *ring.Ring {
+	kvStore, closer := consul.NewInMemoryClient(ring.GetCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+
+	desc := ring.NewDesc()
+	for addr, instanceTokens := range tokens {
+		desc.AddIngester("distributor-"+addr, addr, "", instanceTokens, ring.ACTIVE, time.Now())
+	}
+	require.NoError(t, kvStore.CAS(context.Background(), ringKey, func(any) (any, bool, error) {
+		return desc, true, nil
+	}))
+
+	var ringCfg ring.Config
+	flagext.DefaultValues(&ringCfg)
+	ringCfg.KVStore = kv.Config{Mock: kvStore}
+	ringCfg.ReplicationFactor = 1
+	r, err := ring.New(ringCfg, "distributor", ringKey, log.NewNopLogger(), nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), r))
+	t.Cleanup(func() { r.StopAsync() })
+	test.Poll(t, time.Second, len(tokens), func() any { return r.InstancesCount() })
+	return r
+}
+
+func TestAggregator_ShardsOutputSeriesAcrossDistributors(t *testing.T) {
+	var tokensA, tokensB []uint32
+	for i := uint32(0); i < 64; i++ {
+		tokensA = append(tokensA, i*(1<<26))
+		tokensB = append(tokensB, i*(1<<26)+(1<<25))
+	}
+	r := newAggregationTestRing(t, map[string][]uint32{"a": tokensA, "b": tokensB})
+
+	rule := aggregationRule(t, validation.AggregationRule{Match: `{__name__="up"}`, Output: "up:sum", Operation: validation.AggregationSum, By: []string{"job"}})
+	limits := mockAggregationLimits{"user-1": {rule}}
+
+	pushers := map[string]*recordingPusher{"a": {}, "b": {}}
+	aggregators := map[string]*aggregator{}
+	clients := func(addr string) (distributorpb.DistributorClient, error) {
+		return aggregatorClient{a: aggregators[addr]}, nil
+	}
+	for addr, pusher := range pushers {
+		a := newAggregator(AggregationConfig{ForwardTimeout: time.Second, ForwardQueueSize: 100}, limits, r, addr, clients, pusher.Push, log.NewNopLogger(), prometheus.NewPedanticRegistry())
+		a.now = func() time.Time { return time.UnixMilli(0) }
+		require.NoError(t, services.StartAndAwaitRunning(context.Background(), a))
+		t.Cleanup(func() { a.StopAsync() })
+		aggregators[addr] = a
+	}
+
+	// The series of each job are spread across both distributors.
+	var expected []string
+	for job := 0; job < 20; job++ {
+		for instance := 0; instance < 4; instance++ {
+			series := aggregationSeries(labels.FromStrings(labels.MetricName, "up", "instance", fmt.Sprint(instance), "job", fmt.Sprint(job)), cortexpb.Sample{TimestampMs: 1000, Value: 1})
+			addr := []string{"a", "b"}[instance%2]
+			aggregators[addr].Push("user-1", []cortexpb.PreallocTimeseries{series})
+		}
+		expected = append(expected, fmt.Sprintf(`user-1 {__name__="up:sum", job="%d"} 4@60000`, job))
+	}
+	sort.Strings(expected)
+
+	// The series are forwarded asynchronously.
+	test.Poll(t, 5*time.Second, float64(80), func() any {
+		return testutil.ToFloat64(aggregators["a"].aggregatedSamples.WithLabelValues("user-1")) + testutil.ToFloat64(aggregators["b"].aggregatedSamples.WithLabelValues("user-1"))
+	})
+
+	var actual []string
+	for addr, a := range aggregators {
+		a.flush(context.Background(), time.UnixMilli(100000))
+		pushed := pushers[addr].pushed()
+		assert.NotEmpty(t, pushed, addr)
+		actual = append(actual, pushed...)
+	}
+	sort.Strings(actual)
+	assert.Equal(t, expected, actual)
+
+	// The series forwarded by a distributor whose view of the ring differs are discarded.
+	series := aggregationSeries(labels.FromStrings(labels.MetricName, "up", "instance", "0", "job", "0"), cortexpb.Sample{TimestampMs: 1000, Value: 1})
+	for _, a := range aggregators {
+		a.aggregate("user-1", []cortexpb.PreallocTimeseries{series}, nil)
+	}
+	assert.Equal(t, float64(1), testutil.ToFloat64(aggregators["a"].discardedSamples.WithLabelValues("user-1", aggregationNotOwned))+testutil.ToFloat64(aggregators["b"].discardedSamples.WithLabelValues("user-1", aggregationNotOwned)))
+}
+
+func TestAggregator_PushShouldDiscardTheSeriesToForwardIfTheQueueIsFull(t *testing.T) {
+	rule := aggregationRule(t, validation.AggregationRule{Match: `{__name__="up"}`, Output: "up:sum", Operation: validation.AggregationSum, By: []string{"job"}})
+	r := newAggregationTestRing(t, map[string][]uint32{"b": {0}})
+	a := newAggregator(AggregationConfig{ForwardQueueSize: 1}, mockAggregationLimits{"user-1": {rule}}, r, "a", nil, (&recordingPusher{}).Push, log.NewNopLogger(), prometheus.NewPedanticRegistry())
+
+	// The aggregator isn't running, so the queued series are never forwarded.
+	for job := 0; job < 2; job++ {
+		a.Push("user-1", []cortexpb.PreallocTimeseries{
+			aggregationSeries(labels.FromStrings(labels.MetricName, "up", "job", fmt.Sprint(job)), cortexpb.Sample{TimestampMs: 1000, Value: 1}, cortexpb.Sample{TimestampMs: 2000, Value: 1}),
+		})
+	}
+	assert.Len(t, a.forwardQueue, 1)
+	assert.Equal(t, float64(2), testutil.ToFloat64(a.discardedSamples.WithLabelValues("user-1", aggregationForwardQueueFull)))
+}
+
+func TestDistributor_PushWithAggregationRules(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.AggregationRules = []validation.AggregationRule{
+		aggregationRule(t, validation.AggregationRule{Match: `{__name__="foo"}`, Output: "foo:sum", Operation: validation.AggregationSum, Without: []string{"sample"}, DropInput: true}),
+		aggregationRule(t, validation.AggregationRule{Match: `{__name__="foo", sample="0"}`, Output: "foo:count", Operation: validation.AggregationCount, Without: []string{"sample"}}),
+	}
+
+	ds, ingesters, _, _ := prepare(t, prepConfig{
+		numIngesters:       3,
+		happyIngesters:     3,
+		numDistributors:    1,
+		shardByAllLabels:   true,
+		limits:             limits,
+		aggregationEnabled: true,
+	})
+	d := ds[0]
+	require.NotNil(t, d.aggregator)
+
+	now := time.Now()
+	ctx := user.InjectOrgID(context.Background(), "user-1")
+	_, err := d.Push(ctx, makeWriteRequest(now.UnixMilli(), 3, 0, 0))
+	require.NoError(t, err)
+
+	ingestedSeries := func() []string {
+		unique := map[string]struct{}{}
+		for _, ing := range ingesters {
+			for _, ts := range ing.series() {
+				unique[cortexpb.FromLabelAdaptersToLabels(ts.Labels).String()] = struct{}{}
+			}
+		}
+		result := slices.Collect(maps.Keys(unique))
+		sort.Strings(result)
+		return result
+	}
+
+	// Only the series matching a rule not dropping its input is ingested.
+	assert.Equal(t, []string{`{__name__="foo", bar="baz", sample="0"}`}, ingestedSeries())
+
+	d.aggregator.flush(context.Background(), now.Add(time.Hour))
+	assert.Equal(t, []string{
+		`{__name__="foo", bar="baz", sample="0"}`,
+		`{__name__="foo:count", bar="baz"}`,
+		`{__name__="foo:sum", bar="baz"}`,
+	}, ingestedSeries())
+}
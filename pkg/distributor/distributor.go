@@ -29,11 +29,13 @@ import (
 	"go.uber.org/atomic"
 
 	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/distributor/distributorpb"
 	"github.com/cortexproject/cortex/pkg/ha"
 	"github.com/cortexproject/cortex/pkg/ingester"
 	ingester_client "github.com/cortexproject/cortex/pkg/ingester/client"
 	"github.com/cortexproject/cortex/pkg/ring"
 	ring_client "github.com/cortexproject/cortex/pkg/ring/client"
+	"github.com/cortexproject/cortex/pkg/usage"
 	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/extract"
 	"github.com/cortexproject/cortex/pkg/util/labelset"
@@ -99,6 +101,7 @@ type Distributor struct {
 	// Per-user rate limiter.
 	ingestionRateLimiter                *limiter.RateLimiter
 	nativeHistogramIngestionRateLimiter *limiter.RateLimiter
+	labelSetIngestionRateLimiter        *limiter.RateLimiter
 
 	// Manager for subservices (HA Tracker, distributor ring and client pool)
 	subservices        *services.Manager
@@ -137,6 +140,9 @@ type Distributor struct {
 
 	// Map to track label sets from user.
 	labelSetTracker *labelset.LabelSetTracker
+
+	// Streaming aggregation of the series matching the aggregation rules. Nil if disabled.
+	aggregator *aggregator
 }
 
 // Config contains the configuration required to
@@ -161,6 +167,9 @@ type Config struct {
 	// Distributors ring
 	DistributorRing RingConfig `yaml:"ring"`
 
+	// Streaming aggregation
+	Aggregation AggregationConfig `yaml:"aggregation"`
+
 	// for testing and for extending the ingester by adding calls to the client
 	IngesterClientFactory ring_client.PoolFactory `yaml:"-"`
 
@@ -189,6 +198,9 @@ type Config struct {
 
 	// Inject from global config
 	NameValidationScheme model.ValidationScheme `yaml:"-"`
+
+	// UsageTracker tracks the samples ingested for each tenant, if the usage tracking is enabled.
+	UsageTracker *usage.Tracker `yaml:"-"`
 }
 
 type InstanceLimits struct {
@@ -209,6 +221,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.PoolConfig.RegisterFlags(f)
 	cfg.HATrackerConfig.RegisterFlags(f)
 	cfg.DistributorRing.RegisterFlags(f)
+	cfg.Aggregation.RegisterFlags(f)
 
 	f.IntVar(&cfg.MaxRecvMsgSize, "distributor.max-recv-msg-size", 100<<20, "remote_write API max receive message size (bytes).")
 	f.IntVar(&cfg.OTLPMaxRecvMsgSize, "distributor.otlp-max-recv-msg-size", 100<<20, "Maximum OTLP request size in bytes that the Distributor can accept.")
@@ -281,14 +294,19 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 	// limiting.
 	var ingestionRateStrategy limiter.RateLimiterStrategy
 	var nativeHistogramIngestionRateStrategy limiter.RateLimiterStrategy
+	var labelSetIngestionRateStrategy limiter.RateLimiterStrategy
 	var distributorsLifeCycler *ring.Lifecycler
 	var distributorsRing *ring.Ring
 
-	if !canJoinDistributorsRing {
-		ingestionRateStrategy = newInfiniteIngestionRateStrategy()
-		nativeHistogramIngestionRateStrategy = newInfiniteIngestionRateStrategy()
-	} else if limits.IngestionRateStrategy() == validation.GlobalIngestionRateStrategy {
-		distributorsLifeCycler, err = ring.NewLifecycler(cfg.DistributorRing.ToLifecyclerConfig(), nil, "distributor", ringKey, true, true, log, prometheus.WrapRegistererWithPrefix("cortex_", reg))
+	// The distributors ring is required by the global rate limiter, and to shard the streaming
+	// aggregation. Internal dependencies which can't join the ring don't aggregate their series.
+	if canJoinDistributorsRing && (limits.IngestionRateStrategy() == validation.GlobalIngestionRateStrategy || cfg.Aggregation.Enabled) {
+		lifecyclerCfg := cfg.DistributorRing.ToLifecyclerConfig()
+		if cfg.Aggregation.Enabled {
+			lifecyclerCfg.NumTokens = aggregationRingNumTokens
+		}
+
+		distributorsLifeCycler, err = ring.NewLifecycler(lifecyclerCfg, nil, "distributor", ringKey, true, true, log, prometheus.WrapRegistererWithPrefix("cortex_", reg))
 		if err != nil {
 			return nil, err
 		}
@@ -298,12 +316,20 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 			return nil, errors.Wrap(err, "failed to initialize distributors' ring client")
 		}
 		subservices = append(subservices, distributorsLifeCycler, distributorsRing)
+	}
 
+	if !canJoinDistributorsRing {
+		ingestionRateStrategy = newInfiniteIngestionRateStrategy()
+		nativeHistogramIngestionRateStrategy = newInfiniteIngestionRateStrategy()
+		labelSetIngestionRateStrategy = newInfiniteIngestionRateStrategy()
+	} else if limits.IngestionRateStrategy() == validation.GlobalIngestionRateStrategy {
 		ingestionRateStrategy = newGlobalIngestionRateStrategy(limits, distributorsLifeCycler)
 		nativeHistogramIngestionRateStrategy = newGlobalNativeHistogramIngestionRateStrategy(limits, distributorsLifeCycler)
+		labelSetIngestionRateStrategy = newLabelSetIngestionRateStrategy(limits, distributorsLifeCycler)
 	} else {
 		ingestionRateStrategy = newLocalIngestionRateStrategy(limits)
 		nativeHistogramIngestionRateStrategy = newLocalNativeHistogramIngestionRateStrategy(limits)
+		labelSetIngestionRateStrategy = newLabelSetIngestionRateStrategy(limits, nil)
 	}
 
 	d := &Distributor{
@@ -316,6 +342,7 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 		limits:                              limits,
 		ingestionRateLimiter:                limiter.NewRateLimiter(ingestionRateStrategy, 10*time.Second),
 		nativeHistogramIngestionRateLimiter: limiter.NewRateLimiter(nativeHistogramIngestionRateStrategy, 10*time.Second),
+		labelSetIngestionRateLimiter:        limiter.NewRateLimiter(labelSetIngestionRateStrategy, 10*time.Second),
 		HATracker:                           haTracker,
 		ingestionRate:                       util_math.NewEWMARate(0.2, instanceIngestionRateTickInterval),
 
@@ -465,6 +492,22 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 	d.replicationFactor.Set(float64(ingestersRing.ReplicationFactor()))
 	d.activeUsers = users.NewActiveUsersCleanupWithDefaultValues(d.cleanupInactiveUser)
 
+	if cfg.Aggregation.Enabled && canJoinDistributorsRing {
+		util_log.WarnExperimentalUse("Distributor: streaming aggregation")
+
+		clientsPool := newDistributorClientPool(cfg.Aggregation.GRPCClientConfig, log, reg)
+		clients := func(addr string) (distributorpb.DistributorClient, error) {
+			c, err := clientsPool.GetClientFor(addr)
+			if err != nil {
+				return nil, err
+			}
+			return c.(distributorpb.DistributorClient), nil
+		}
+
+		d.aggregator = newAggregator(cfg.Aggregation, limits, distributorsRing, distributorsLifeCycler.Addr, clients, d.Push, log, reg)
+		subservices = append(subservices, clientsPool, d.aggregator)
+	}
+
 	subservices = append(subservices, d.ingesterPool, d.activeUsers)
 	d.subservices, err = services.NewManager(subservices...)
 	if err != nil {
@@ -541,6 +584,10 @@ func (d *Distributor) cleanupInactiveUser(userID string) {
 	}
 
 	validation.DeletePerUserValidationMetrics(d.validateMetrics, userID, d.log)
+
+	if d.aggregator != nil {
+		d.aggregator.cleanupUser(userID)
+	}
 }
 
 // Called after distributor is asked to stop via StopAsync.
@@ -799,24 +846,32 @@ func (d *Distributor) Push(ctx context.Context, req *cortexpb.WriteRequest) (*co
 	}
 
 	// A WriteRequest can only contain series or metadata but not both. This might change in the future.
-	seriesKeys, nhSeriesKeys, validatedTimeseries, nhValidatedTimeseries, validatedFloatSamples, validatedHistogramSamples, validatedExemplars, firstPartialErr, err := d.prepareSeriesKeys(ctx, req, userID, limits, removeReplica)
+	labelSets := d.newLabelSetSamples(now, userID)
+	seriesKeys, nhSeriesKeys, validatedTimeseries, nhValidatedTimeseries, aggregationInput, validatedFloatSamples, validatedHistogramSamples, validatedExemplars, firstPartialErr, err := d.prepareSeriesKeys(ctx, req, userID, limits, removeReplica, labelSets)
 	if err != nil {
 		return nil, err
 	}
 	metadataKeys, validatedMetadata, firstPartialErr := d.prepareMetadataKeys(req, limits, userID, firstPartialErr)
 
-	d.receivedSamples.WithLabelValues(userID, sampleMetricTypeFloat).Add(float64(validatedFloatSamples))
-	d.receivedSamples.WithLabelValues(userID, sampleMetricTypeHistogram).Add(float64(validatedHistogramSamples))
-	d.receivedExemplars.WithLabelValues(userID).Add(float64(validatedExemplars))
+	// The received samples exclude the samples rejected by the LabelSets ingestion rate limits,
+	// which are only applied once the samples are accepted by the per-tenant ingestion rate limit.
+	updateReceivedMetrics := func() {
+		d.receivedSamples.WithLabelValues(userID, sampleMetricTypeFloat).Add(float64(validatedFloatSamples))
+		d.receivedSamples.WithLabelValues(userID, sampleMetricTypeHistogram).Add(float64(validatedHistogramSamples))
+		d.receivedExemplars.WithLabelValues(userID).Add(float64(validatedExemplars))
+		labelSets.updateMetrics()
+	}
 	d.receivedMetadata.WithLabelValues(userID).Add(float64(len(validatedMetadata)))
 
-	if len(seriesKeys) == 0 && len(nhSeriesKeys) == 0 && len(metadataKeys) == 0 {
+	if len(seriesKeys) == 0 && len(nhSeriesKeys) == 0 && len(metadataKeys) == 0 && len(aggregationInput) == 0 {
+		updateReceivedMetrics()
 		return &cortexpb.WriteResponse{}, firstPartialErr
 	}
 
 	totalSamples := validatedFloatSamples + validatedHistogramSamples
 	totalN := totalSamples + validatedExemplars + len(validatedMetadata)
 	if !d.ingestionRateLimiter.AllowN(now, userID, totalN) {
+		updateReceivedMetrics()
 		d.validateMetrics.DiscardedSamples.WithLabelValues(validation.RateLimited, userID).Add(float64(totalSamples))
 		d.validateMetrics.DiscardedExemplars.WithLabelValues(validation.RateLimited, userID).Add(float64(validatedExemplars))
 		d.validateMetrics.DiscardedMetadata.WithLabelValues(validation.RateLimited, userID).Add(float64(len(validatedMetadata)))
@@ -829,6 +884,34 @@ func (d *Distributor) Push(ctx context.Context, req *cortexpb.WriteRequest) (*co
 	// totalN included samples and metadata. Ingester follows this pattern when computing its ingestion rate.
 	d.ingestionRate.Add(int64(totalN))
 
+	// The LabelSets ingestion rate limits are applied once the samples have been accepted by the per-tenant
+	// ingestion rate limit, so that the samples it rejects are not counted in the rate of the LabelSets.
+	seriesKeys, validatedTimeseries = labelSets.filterRateLimited(seriesKeys, validatedTimeseries)
+	nhSeriesKeys, nhValidatedTimeseries = labelSets.filterRateLimited(nhSeriesKeys, nhValidatedTimeseries)
+	_, aggregationInput = labelSets.filterRateLimited(nil, aggregationInput)
+	validatedFloatSamples -= labelSets.floatSamples
+	validatedHistogramSamples -= labelSets.histogramSamples
+	validatedExemplars -= labelSets.exemplars
+	updateReceivedMetrics()
+
+	if labelSets.err != nil {
+		if firstPartialErr == nil {
+			firstPartialErr = labelSets.err
+		}
+		if len(seriesKeys) == 0 && len(nhSeriesKeys) == 0 && len(metadataKeys) == 0 && len(aggregationInput) == 0 {
+			return &cortexpb.WriteResponse{}, firstPartialErr
+		}
+	}
+
+	if len(aggregationInput) > 0 {
+		d.aggregator.Push(userID, aggregationInput)
+
+		// All the series may have been dropped after being aggregated.
+		if len(seriesKeys) == 0 && len(nhSeriesKeys) == 0 && len(metadataKeys) == 0 {
+			return &cortexpb.WriteResponse{}, firstPartialErr
+		}
+	}
+
 	var nativeHistogramErr error
 
 	if !d.nativeHistogramIngestionRateLimiter.AllowN(now, userID, validatedHistogramSamples) {
@@ -861,6 +944,7 @@ func (d *Distributor) Push(ctx context.Context, req *cortexpb.WriteRequest) (*co
 	if err != nil {
 		return nil, err
 	}
+	d.cfg.UsageTracker.AddSamplesIngested(userID, validatedFloatSamples+validatedHistogramSamples)
 
 	resp := &cortexpb.WriteResponse{}
 	if d.cfg.RemoteWriteV2Enabled {
@@ -879,6 +963,27 @@ func (d *Distributor) Push(ctx context.Context, req *cortexpb.WriteRequest) (*co
 	return resp, firstPartialErr
 }
 
+// Aggregate implements distributorpb.DistributorServer. It aggregates the series forwarded
+// by the other distributors, whose output series are owned by this distributor.
+func (d *Distributor) Aggregate(ctx context.Context, req *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
+	defer func() {
+		cortexpb.ReuseSlice(req.Timeseries)
+		req.Free()
+	}()
+
+	if d.aggregator == nil {
+		return nil, errAggregationDisabled
+	}
+
+	userID, err := users.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d.aggregator.aggregate(userID, req.Timeseries, nil)
+	return &cortexpb.WriteResponse{}, nil
+}
+
 func (d *Distributor) updateLabelSetMetrics() {
 	activeUserSet := make(map[string]map[uint64]struct{})
 	for _, user := range d.activeUsers.ActiveUsers() {
@@ -899,6 +1004,7 @@ func (d *Distributor) updateLabelSetMetrics() {
 		d.receivedSamplesPerLabelSet.DeleteLabelValues(user, sampleMetricTypeFloat, labelSetStr)
 		d.receivedSamplesPerLabelSet.DeleteLabelValues(user, sampleMetricTypeHistogram, labelSetStr)
 	})
+	d.validateMetrics.UpdateLabelSet(activeUserSet, d.log)
 }
 
 func (d *Distributor) cleanStaleIngesterMetrics() {
@@ -1009,12 +1115,13 @@ func (d *Distributor) prepareMetadataKeys(req *cortexpb.WriteRequest, limits *va
 }
 
 type samplesLabelSetEntry struct {
-	floatSamples     int64
-	histogramSamples int64
-	labels           labels.Labels
+	floatSamples       int64
+	histogramSamples   int64
+	rateLimitedSamples int64
+	labels             labels.Labels
 }
 
-func (d *Distributor) prepareSeriesKeys(ctx context.Context, req *cortexpb.WriteRequest, userID string, limits *validation.Limits, removeReplica bool) ([]uint32, []uint32, []cortexpb.PreallocTimeseries, []cortexpb.PreallocTimeseries, int, int, int, error, error) {
+func (d *Distributor) prepareSeriesKeys(ctx context.Context, req *cortexpb.WriteRequest, userID string, limits *validation.Limits, removeReplica bool, labelSets *labelSetSamples) ([]uint32, []uint32, []cortexpb.PreallocTimeseries, []cortexpb.PreallocTimeseries, []cortexpb.PreallocTimeseries, int, int, int, error, error) {
 	pSpan, _ := opentracing.StartSpanFromContext(ctx, "prepareSeriesKeys")
 	defer pSpan.Finish()
 
@@ -1027,12 +1134,15 @@ func (d *Distributor) prepareSeriesKeys(ctx context.Context, req *cortexpb.Write
 	validatedFloatSamples := 0
 	validatedHistogramSamples := 0
 	validatedExemplars := 0
-	limitsPerLabelSet := d.limits.LimitsPerLabelSet(userID)
 
-	var (
-		labelSetCounters map[uint64]*samplesLabelSetEntry
-		firstPartialErr  error
-	)
+	// Series matching the aggregation rules, aggregated in addition to or instead of being ingested.
+	var aggregationRules []validation.AggregationRule
+	var aggregationInput []cortexpb.PreallocTimeseries
+	if d.aggregator != nil && !skipAggregation(ctx) {
+		aggregationRules = limits.AggregationRules
+	}
+
+	var firstPartialErr error
 
 	latestSampleTimestampMs := int64(0)
 	defer func() {
@@ -1047,7 +1157,7 @@ func (d *Distributor) prepareSeriesKeys(ctx context.Context, req *cortexpb.Write
 	skipLabelNameValidation := d.cfg.SkipLabelNameValidation || req.GetSkipLabelNameValidation()
 	for _, ts := range req.Timeseries {
 		if len(ts.Labels) == 0 {
-			return nil, nil, nil, nil, 0, 0, 0, nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", "empty labels found")
+			return nil, nil, nil, nil, nil, 0, 0, 0, nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", "empty labels found")
 		}
 
 		if limits.AcceptHASamples && limits.AcceptMixedHASamples {
@@ -1138,7 +1248,7 @@ func (d *Distributor) prepareSeriesKeys(ctx context.Context, req *cortexpb.Write
 		// label and dropped labels (if any)
 		key, err := d.tokenForLabels(userID, ts.Labels)
 		if err != nil {
-			return nil, nil, nil, nil, 0, 0, 0, nil, err
+			return nil, nil, nil, nil, nil, 0, 0, 0, nil, err
 		}
 		validatedSeries, validationErr := d.validateSeries(ts, userID, skipLabelNameValidation, limits)
 
@@ -1155,21 +1265,20 @@ func (d *Distributor) prepareSeriesKeys(ctx context.Context, req *cortexpb.Write
 			continue
 		}
 
-		matchedLabelSetLimits := validation.LimitsPerLabelSetsForSeries(limitsPerLabelSet, cortexpb.FromLabelAdaptersToLabels(validatedSeries.Labels))
-		if len(matchedLabelSetLimits) > 0 && labelSetCounters == nil {
-			// TODO: use pool.
-			labelSetCounters = make(map[uint64]*samplesLabelSetEntry, len(matchedLabelSetLimits))
-		}
-		for _, l := range matchedLabelSetLimits {
-			if c, exists := labelSetCounters[l.Hash]; exists {
-				c.floatSamples += int64(len(ts.Samples))
-				c.histogramSamples += int64(len(ts.Histograms))
-			} else {
-				labelSetCounters[l.Hash] = &samplesLabelSetEntry{
-					floatSamples:     int64(len(ts.Samples)),
-					histogramSamples: int64(len(ts.Histograms)),
-					labels:           l.LabelSet,
-				}
+		labelSets.add(validatedSeries)
+
+		validatedFloatSamples += len(ts.Samples)
+		validatedHistogramSamples += len(ts.Histograms)
+		validatedExemplars += len(ts.Exemplars)
+
+		// Only float samples are aggregated.
+		if len(aggregationRules) > 0 && len(ts.Histograms) == 0 {
+			matched, dropInput := aggregationRulesFor(aggregationRules, cortexpb.FromLabelAdaptersToLabels(validatedSeries.Labels))
+			if matched {
+				aggregationInput = append(aggregationInput, validatedSeries)
+			}
+			if dropInput {
+				continue
 			}
 		}
 
@@ -1180,22 +1289,183 @@ func (d *Distributor) prepareSeriesKeys(ctx context.Context, req *cortexpb.Write
 			seriesKeys = append(seriesKeys, key)
 			validatedTimeseries = append(validatedTimeseries, validatedSeries)
 		}
-		validatedFloatSamples += len(ts.Samples)
-		validatedHistogramSamples += len(ts.Histograms)
-		validatedExemplars += len(ts.Exemplars)
 	}
-	for h, counter := range labelSetCounters {
-		d.labelSetTracker.Track(userID, h, counter.labels)
+	return seriesKeys, nhSeriesKeys, validatedTimeseries, nhValidatedTimeseries, aggregationInput, validatedFloatSamples, validatedHistogramSamples, validatedExemplars, firstPartialErr, nil
+}
+
+// rateLimitedLabelSets returns the LabelSets whose ingestion rate limit is exceeded by
+// adding n samples. The samples are only counted in the rate of the LabelSets if none of
+// them is exceeded, since the series is rejected otherwise.
+func (d *Distributor) rateLimitedLabelSets(now time.Time, userID string, matchedLabelSetLimits []validation.LimitsPerLabelSet, n int) []validation.LimitsPerLabelSet {
+	var (
+		rateLimited []validation.LimitsPerLabelSet
+		keys        []string
+	)
+	for _, l := range matchedLabelSetLimits {
+		if l.Limits.IngestionRate <= 0 {
+			continue
+		}
+		rateLimited = append(rateLimited, l)
+		keys = append(keys, labelSetRateLimiterKey(userID, l))
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	denied := d.labelSetIngestionRateLimiter.AllowAllN(now, keys, n)
+	if len(denied) == 0 {
+		return nil
+	}
+
+	limited := make([]validation.LimitsPerLabelSet, 0, len(denied))
+	for _, i := range denied {
+		limited = append(limited, rateLimited[i])
+	}
+	return limited
+}
+
+// labelSetSamples tracks the samples of the series matching the LabelSets of a user, and discards the
+// series exceeding the ingestion rate limit of any of their LabelSets. The samples of the discarded
+// series are accounted to all their LabelSets, like the ingester does for the series limit.
+type labelSetSamples struct {
+	d                 *Distributor
+	now               time.Time
+	userID            string
+	limitsPerLabelSet []validation.LimitsPerLabelSet
+	rateLimitEnabled  bool
+
+	// matched and rateLimited are cached for each series, since an aggregated series may also be ingested.
+	matched     map[*cortexpb.TimeSeries][]validation.LimitsPerLabelSet
+	rateLimited map[*cortexpb.TimeSeries]bool
+	counters    map[uint64]*samplesLabelSetEntry
+
+	// Samples and exemplars discarded by the rate limits.
+	floatSamples     int
+	histogramSamples int
+	exemplars        int
+	err              error
+}
+
+func (d *Distributor) newLabelSetSamples(now time.Time, userID string) *labelSetSamples {
+	limitsPerLabelSet := d.limits.LimitsPerLabelSet(userID)
+
+	return &labelSetSamples{
+		d:                 d,
+		now:               now,
+		userID:            userID,
+		limitsPerLabelSet: limitsPerLabelSet,
+		rateLimitEnabled:  slices.ContainsFunc(limitsPerLabelSet, func(l validation.LimitsPerLabelSet) bool { return l.Limits.IngestionRate > 0 }),
+	}
+}
+
+// add tracks the samples of a validated series.
+func (l *labelSetSamples) add(ts cortexpb.PreallocTimeseries) {
+	matchedLabelSetLimits := validation.LimitsPerLabelSetsForSeries(l.limitsPerLabelSet, cortexpb.FromLabelAdaptersToLabels(ts.Labels))
+	if len(matchedLabelSetLimits) == 0 {
+		return
+	}
+
+	if l.counters == nil {
+		// TODO: use pool.
+		l.counters = make(map[uint64]*samplesLabelSetEntry, len(matchedLabelSetLimits))
+		l.matched = map[*cortexpb.TimeSeries][]validation.LimitsPerLabelSet{}
+	}
+	l.matched[ts.TimeSeries] = matchedLabelSetLimits
+
+	for _, ls := range matchedLabelSetLimits {
+		c, exists := l.counters[ls.Hash]
+		if !exists {
+			c = &samplesLabelSetEntry{labels: ls.LabelSet}
+			l.counters[ls.Hash] = c
+		}
+		c.floatSamples += int64(len(ts.Samples))
+		c.histogramSamples += int64(len(ts.Histograms))
+	}
+}
+
+// filterRateLimited removes the rate limited series, along with their keys if any, in place.
+func (l *labelSetSamples) filterRateLimited(keys []uint32, series []cortexpb.PreallocTimeseries) ([]uint32, []cortexpb.PreallocTimeseries) {
+	if !l.rateLimitEnabled {
+		return keys, series
+	}
+
+	n := 0
+	for i, ts := range series {
+		if l.isRateLimited(ts) {
+			continue
+		}
+		if keys != nil {
+			keys[n] = keys[i]
+		}
+		series[n] = ts
+		n++
+	}
+
+	if keys != nil {
+		keys = keys[:n]
+	}
+	return keys, series[:n]
+}
+
+func (l *labelSetSamples) isRateLimited(ts cortexpb.PreallocTimeseries) bool {
+	matchedLabelSetLimits := l.matched[ts.TimeSeries]
+	if len(matchedLabelSetLimits) == 0 {
+		return false
+	}
+	if limited, ok := l.rateLimited[ts.TimeSeries]; ok {
+		return limited
+	}
+	if l.rateLimited == nil {
+		l.rateLimited = map[*cortexpb.TimeSeries]bool{}
+	}
+
+	// Only the validated samples of the series are counted in the rate of its LabelSets.
+	samples := len(ts.Samples) + len(ts.Histograms)
+	rateLimited := l.d.rateLimitedLabelSets(l.now, l.userID, matchedLabelSetLimits, samples)
+	l.rateLimited[ts.TimeSeries] = len(rateLimited) > 0
+	if len(rateLimited) == 0 {
+		return false
+	}
+
+	for _, ls := range matchedLabelSetLimits {
+		c := l.counters[ls.Hash]
+		c.floatSamples -= int64(len(ts.Samples))
+		c.histogramSamples -= int64(len(ts.Histograms))
+		c.rateLimitedSamples += int64(samples)
+	}
+
+	l.floatSamples += len(ts.Samples)
+	l.histogramSamples += len(ts.Histograms)
+	l.exemplars += len(ts.Exemplars)
+
+	if l.err == nil {
+		// Return a 429 to tell the client it is going too fast, like for the per-tenant ingestion rate limit.
+		l.err = httpgrpc.Errorf(http.StatusTooManyRequests, "ingestion rate limit (%v) of the labelset %s exceeded while adding %d samples", l.d.labelSetIngestionRateLimiter.Limit(l.now, labelSetRateLimiterKey(l.userID, rateLimited[0])), rateLimited[0].LabelSet.String(), samples)
+	}
+	return true
+}
+
+func (l *labelSetSamples) updateMetrics() {
+	for h, counter := range l.counters {
+		l.d.labelSetTracker.Track(l.userID, h, counter.labels)
 		labelSetStr := counter.labels.String()
 		if counter.floatSamples > 0 {
-			d.receivedSamplesPerLabelSet.WithLabelValues(userID, sampleMetricTypeFloat, labelSetStr).Add(float64(counter.floatSamples))
+			l.d.receivedSamplesPerLabelSet.WithLabelValues(l.userID, sampleMetricTypeFloat, labelSetStr).Add(float64(counter.floatSamples))
 		}
 		if counter.histogramSamples > 0 {
-			d.receivedSamplesPerLabelSet.WithLabelValues(userID, sampleMetricTypeHistogram, labelSetStr).Add(float64(counter.histogramSamples))
+			l.d.receivedSamplesPerLabelSet.WithLabelValues(l.userID, sampleMetricTypeHistogram, labelSetStr).Add(float64(counter.histogramSamples))
+		}
+		if counter.rateLimitedSamples > 0 {
+			l.d.validateMetrics.LabelSetTracker.Track(l.userID, h, counter.labels)
+			l.d.validateMetrics.DiscardedSamplesPerLabelSet.WithLabelValues(validation.PerLabelSetRateLimited, l.userID, labelSetStr).Add(float64(counter.rateLimitedSamples))
 		}
 	}
-
-	return seriesKeys, nhSeriesKeys, validatedTimeseries, nhValidatedTimeseries, validatedFloatSamples, validatedHistogramSamples, validatedExemplars, firstPartialErr, nil
+	if samples := l.floatSamples + l.histogramSamples; samples > 0 {
+		l.d.validateMetrics.DiscardedSamples.WithLabelValues(validation.PerLabelSetRateLimited, l.userID).Add(float64(samples))
+	}
+	if l.exemplars > 0 {
+		l.d.validateMetrics.DiscardedExemplars.WithLabelValues(validation.PerLabelSetRateLimited, l.userID).Add(float64(l.exemplars))
+	}
 }
 
 func sortLabelsIfNeeded(labels []cortexpb.LabelAdapter) {
@@ -1642,6 +1912,142 @@ func (d *Distributor) UserStats(ctx context.Context) (*ingester.UserStats, error
 	return totalStats, nil
 }
 
+// MetricsUsage returns the number of in-memory series of each metric name of the user, along with the
+// last time each metric name has been queried, aggregated across all ingesters.
+func (d *Distributor) MetricsUsage(ctx context.Context) (*ingester_client.MetricsUsageResponse, error) {
+	replicationSet, err := d.GetIngestersForMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure we get a successful response from all of them.
+	replicationSet.MaxErrors = 0
+
+	req := &ingester_client.MetricsUsageRequest{}
+	resps, err := d.ForReplicationSet(ctx, replicationSet, false, false, func(ctx context.Context, client ingester_client.IngesterClient) (any, error) {
+		return client.MetricsUsage(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		trackedSince int64
+		usages       = map[string]*ingester_client.MetricUsage{}
+	)
+	for _, resp := range resps {
+		r := resp.(*ingester_client.MetricsUsageResponse)
+
+		// The queried metrics are tracked across all ingesters only since the most recent tracking start.
+		trackedSince = max(trackedSince, r.TrackedSinceTimestampMs)
+
+		for _, m := range r.Metrics {
+			usage, ok := usages[m.MetricName]
+			if !ok {
+				usage = &ingester_client.MetricUsage{MetricName: m.MetricName}
+				usages[m.MetricName] = usage
+			}
+			usage.NumSeries += m.NumSeries
+			usage.LastQueriedTimestampMs = max(usage.LastQueriedTimestampMs, m.LastQueriedTimestampMs)
+		}
+	}
+
+	factor := uint64(d.ingestersRing.ReplicationFactor())
+	result := &ingester_client.MetricsUsageResponse{
+		Metrics:                 make([]ingester_client.MetricUsage, 0, len(usages)),
+		TrackedSinceTimestampMs: trackedSince,
+	}
+	for _, usage := range usages {
+		usage.NumSeries /= factor
+		result.Metrics = append(result.Metrics, *usage)
+	}
+
+	return result, nil
+}
+
+// OutOfOrderSeriesStats returns the groups of series of the user producing the most out-of-order,
+// out-of-bounds, too old and duplicate timestamp samples, aggregated across all ingesters. At most
+// limit groups are returned, 0 meaning no limit.
+func (d *Distributor) OutOfOrderSeriesStats(ctx context.Context, limit int) (*ingester_client.OutOfOrderSeriesStatsResponse, error) {
+	replicationSet, err := d.GetIngestersForMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure we get a successful response from all of them.
+	replicationSet.MaxErrors = 0
+
+	req := &ingester_client.OutOfOrderSeriesStatsRequest{}
+	resps, err := d.ForReplicationSet(ctx, replicationSet, false, false, func(ctx context.Context, client ingester_client.IngesterClient) (any, error) {
+		return client.OutOfOrderSeriesStats(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		trackedSince int64
+		groups       = map[string]*ingester_client.OutOfOrderSeriesStats{}
+	)
+	for _, resp := range resps {
+		r := resp.(*ingester_client.OutOfOrderSeriesStatsResponse)
+
+		// The counts cover the discarded samples since the earliest tracking start, but the ingesters
+		// which started tracking later only count the samples discarded since. The ingesters not
+		// tracking the tenant report no tracking start.
+		if r.TrackedSinceTimestampMs > 0 && (trackedSince == 0 || r.TrackedSinceTimestampMs < trackedSince) {
+			trackedSince = r.TrackedSinceTimestampMs
+		}
+
+		for _, s := range r.Series {
+			key := cortexpb.FromLabelAdaptersToLabels(s.Labels).String()
+			group, ok := groups[key]
+			if !ok {
+				group = &ingester_client.OutOfOrderSeriesStats{Labels: s.Labels}
+				groups[key] = group
+			}
+			group.OutOfOrder += s.OutOfOrder
+			group.OutOfBounds += s.OutOfBounds
+			group.TooOld += s.TooOld
+			group.DuplicateTimestamp += s.DuplicateTimestamp
+			group.LastDiscardedTimestampMs = max(group.LastDiscardedTimestampMs, s.LastDiscardedTimestampMs)
+		}
+	}
+
+	// Each sample is usually discarded by all the ingesters it is replicated to, so the counts summed
+	// across the ingesters are divided by the replication factor. They're rounded up so that the samples
+	// discarded by some of their replicas only, e.g. out of order on a replica only, are still reported,
+	// which makes the counts approximate.
+	factor := uint64(d.ingestersRing.ReplicationFactor())
+	result := &ingester_client.OutOfOrderSeriesStatsResponse{
+		Series:                  make([]ingester_client.OutOfOrderSeriesStats, 0, len(groups)),
+		TrackedSinceTimestampMs: trackedSince,
+	}
+	for _, group := range groups {
+		group.OutOfOrder = divideRoundingUp(group.OutOfOrder, factor)
+		group.OutOfBounds = divideRoundingUp(group.OutOfBounds, factor)
+		group.TooOld = divideRoundingUp(group.TooOld, factor)
+		group.DuplicateTimestamp = divideRoundingUp(group.DuplicateTimestamp, factor)
+		result.Series = append(result.Series, *group)
+	}
+
+	sort.Slice(result.Series, func(i, j int) bool {
+		if ti, tj := result.Series[i].Total(), result.Series[j].Total(); ti != tj {
+			return ti > tj
+		}
+		return labels.Compare(cortexpb.FromLabelAdaptersToLabels(result.Series[i].Labels), cortexpb.FromLabelAdaptersToLabels(result.Series[j].Labels)) < 0
+	})
+	if limit > 0 && len(result.Series) > limit {
+		result.Series = result.Series[:limit]
+	}
+
+	return result, nil
+}
+
+func divideRoundingUp(n, d uint64) uint64 {
+	return (n + d - 1) / d
+}
+
 // AllUserStats returns statistics about all users.
 // Note it does not divide by the ReplicationFactor like UserStats()
 func (d *Distributor) AllUserStats(ctx context.Context) ([]ingester.UserIDStats, int, error) {
@@ -0,0 +1,76 @@
+package distributor
+
+import (
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/cortexproject/cortex/pkg/distributor/distributorpb"
+	ring_client "github.com/cortexproject/cortex/pkg/ring/client"
+	"github.com/cortexproject/cortex/pkg/util/grpcclient"
+)
+
+// newDistributorClientPool returns a pool of clients to the other distributors, used to forward
+// the samples to aggregate to the distributor owning their output series.
+func newDistributorClientPool(clientCfg grpcclient.Config, logger log.Logger, reg prometheus.Registerer) *ring_client.Pool {
+	// We prefer sane defaults instead of exposing further config options.
+	poolCfg := ring_client.PoolConfig{
+		CheckInterval:      time.Minute,
+		HealthCheckEnabled: true,
+		HealthCheckTimeout: 10 * time.Second,
+	}
+
+	requestDuration := promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cortex_distributor_client_request_duration_seconds",
+		Help:    "Time spent executing requests from a distributor to another distributor.",
+		Buckets: prometheus.ExponentialBuckets(0.008, 4, 7),
+	}, []string{"operation", "status_code"})
+
+	clientsCount := promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+		Name: "cortex_distributor_clients",
+		Help: "The current number of distributor clients in the pool.",
+	})
+
+	factory := func(addr string) (ring_client.PoolClient, error) {
+		return dialDistributorClient(clientCfg, addr, requestDuration)
+	}
+
+	return ring_client.NewPool("distributor", poolCfg, nil, factory, clientsCount, logger)
+}
+
+func dialDistributorClient(clientCfg grpcclient.Config, addr string, requestDuration *prometheus.HistogramVec) (*distributorClient, error) {
+	opts, err := clientCfg.DialOption(grpcclient.Instrument(requestDuration))
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to dial distributor %s", addr)
+	}
+
+	return &distributorClient{
+		DistributorClient: distributorpb.NewDistributorClient(conn),
+		HealthClient:      grpc_health_v1.NewHealthClient(conn),
+		conn:              conn,
+	}, nil
+}
+
+type distributorClient struct {
+	distributorpb.DistributorClient
+	grpc_health_v1.HealthClient
+	conn *grpc.ClientConn
+}
+
+func (c *distributorClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *distributorClient) String() string {
+	return c.conn.Target()
+}
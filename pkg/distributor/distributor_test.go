@@ -2713,6 +2713,111 @@ func TestDistributor_MetricsForLabelMatchers_SingleSlowIngester(t *testing.T) {
 	}
 }
 
+func TestDistributor_MetricsUsage(t *testing.T) {
+	t.Parallel()
+	const numIngesters = 5
+
+	ds, ingesters, _, _ := prepare(t, prepConfig{
+		numIngesters:     numIngesters,
+		happyIngesters:   numIngesters,
+		numDistributors:  1,
+		shardByAllLabels: true,
+	})
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	for _, lbls := range []labels.Labels{
+		labels.FromStrings(labels.MetricName, "test_1", "status", "200"),
+		labels.FromStrings(labels.MetricName, "test_1", "status", "500"),
+		labels.FromStrings(labels.MetricName, "test_2"),
+	} {
+		_, err := ds[0].Push(ctx, mockWriteRequest([]labels.Labels{lbls}, 1, 100000, false))
+		require.NoError(t, err)
+	}
+
+	// The push returns once a quorum of ingesters succeeded, so wait until all the replicas received the series.
+	test.Poll(t, time.Second, 3*3, func() any {
+		numSeries := 0
+		for _, ing := range ingesters {
+			ing.Lock()
+			numSeries += len(ing.timeseries)
+			ing.Unlock()
+		}
+		return numSeries
+	})
+
+	// Only the ingesters holding the series of a metric report when it has been last queried.
+	expectedLastQueried := int64(0)
+	for idx, ing := range ingesters {
+		ing.Lock()
+		ing.trackedSince = int64((idx + 1) * 100)
+		ing.lastQueried = map[string]int64{"test_1": int64((idx + 1) * 1000)}
+		for _, ts := range ing.timeseries {
+			if cortexpb.FromLabelAdaptersToLabels(ts.Labels).Get(labels.MetricName) == "test_1" {
+				expectedLastQueried = max(expectedLastQueried, int64((idx+1)*1000))
+			}
+		}
+		ing.Unlock()
+	}
+
+	res, err := ds[0].MetricsUsage(ctx)
+	require.NoError(t, err)
+
+	// The number of series is divided by the replication factor, while the most recent
+	// query and tracking start are picked among the ingesters.
+	assert.Equal(t, int64(numIngesters*100), res.TrackedSinceTimestampMs)
+	assert.ElementsMatch(t, []client.MetricUsage{
+		{MetricName: "test_1", NumSeries: 2, LastQueriedTimestampMs: expectedLastQueried},
+		{MetricName: "test_2", NumSeries: 1},
+	}, res.Metrics)
+	assert.Equal(t, numIngesters, countMockIngestersCalls(ingesters, "MetricsUsage"))
+}
+
+func TestDistributor_OutOfOrderSeriesStats(t *testing.T) {
+	t.Parallel()
+	const numIngesters = 3
+
+	ds, ingesters, _, _ := prepare(t, prepConfig{
+		numIngesters:     numIngesters,
+		happyIngesters:   numIngesters,
+		numDistributors:  1,
+		shardByAllLabels: true,
+	})
+
+	jobA := cortexpb.FromLabelsToLabelAdapters(labels.FromStrings(labels.MetricName, "up", "job", "a"))
+	jobB := cortexpb.FromLabelsToLabelAdapters(labels.FromStrings(labels.MetricName, "up", "job", "b"))
+	jobC := cortexpb.FromLabelsToLabelAdapters(labels.FromStrings(labels.MetricName, "up", "job", "c"))
+
+	// Each discarded sample is reported by all the ingesters it is replicated to.
+	for idx, ing := range ingesters {
+		ing.Lock()
+		ing.trackedSince = int64((idx + 1) * 100)
+		ing.outOfOrderStats = []client.OutOfOrderSeriesStats{
+			{Labels: jobA, OutOfOrder: 2, LastDiscardedTimestampMs: int64((idx + 1) * 1000)},
+			{Labels: jobB, OutOfOrder: 3, TooOld: 1, LastDiscardedTimestampMs: 1000},
+			{Labels: jobC, DuplicateTimestamp: 1, LastDiscardedTimestampMs: 1000},
+		}
+		if idx == 0 {
+			// A sample discarded by a single replica only.
+			ing.outOfOrderStats[1].OutOfBounds = 1
+		}
+		ing.Unlock()
+	}
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	res, err := ds[0].OutOfOrderSeriesStats(ctx, 2)
+	require.NoError(t, err)
+
+	// The counts are divided by the replication factor, rounding up, and the groups sorted by
+	// number of discarded samples, while the most recent discard and the earliest tracking start
+	// are picked among the ingesters tracking the tenant.
+	assert.Equal(t, int64(100), res.TrackedSinceTimestampMs)
+	assert.Equal(t, []client.OutOfOrderSeriesStats{
+		{Labels: jobB, OutOfOrder: 3, OutOfBounds: 1, TooOld: 1, LastDiscardedTimestampMs: 1000},
+		{Labels: jobA, OutOfOrder: 2, LastDiscardedTimestampMs: numIngesters * 1000},
+	}, res.Series)
+	assert.Equal(t, numIngesters, countMockIngestersCalls(ingesters, "OutOfOrderSeriesStats"))
+}
+
 func TestDistributor_MetricsForLabelMatchers(t *testing.T) {
 	t.Parallel()
 	const numIngesters = 5
@@ -3132,6 +3237,7 @@ type prepConfig struct {
 	useStreamPush                bool
 	nameValidationScheme         model.ValidationScheme
 	remoteTimeout                time.Duration
+	aggregationEnabled           bool
 }
 
 type prepState struct {
@@ -3278,6 +3384,7 @@ func prepare(tb testing.TB, cfg prepConfig) ([]*Distributor, []*mockIngester, []
 		}
 
 		distributorCfg.RemoteWriteV2Enabled = cfg.remoteWriteV2Enabled
+		distributorCfg.Aggregation.Enabled = cfg.aggregationEnabled
 
 		overrides := validation.NewOverrides(*cfg.limits, nil)
 
@@ -3551,6 +3658,12 @@ type mockIngester struct {
 	queryDelay time.Duration
 	calls      map[string]int
 	lblsValues []string
+
+	// Metrics usage tracking.
+	lastQueried  map[string]int64
+	trackedSince int64
+
+	outOfOrderStats []client.OutOfOrderSeriesStats
 }
 
 func newMockIngester(id int, ps *prepState, cfg prepConfig) *mockIngester {
@@ -3816,6 +3929,45 @@ func (i *mockIngester) MetricsForLabelMatchers(ctx context.Context, req *client.
 	return &response, nil
 }
 
+func (i *mockIngester) MetricsUsage(ctx context.Context, req *client.MetricsUsageRequest, opts ...grpc.CallOption) (*client.MetricsUsageResponse, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	i.trackCall("MetricsUsage")
+
+	if !i.happy.Load() {
+		return nil, errFail
+	}
+
+	seriesPerMetric := map[string]uint64{}
+	for _, ts := range i.timeseries {
+		seriesPerMetric[cortexpb.FromLabelAdaptersToLabels(ts.Labels).Get(labels.MetricName)]++
+	}
+
+	resp := &client.MetricsUsageResponse{TrackedSinceTimestampMs: i.trackedSince}
+	for name, numSeries := range seriesPerMetric {
+		resp.Metrics = append(resp.Metrics, client.MetricUsage{
+			MetricName:             name,
+			NumSeries:              numSeries,
+			LastQueriedTimestampMs: i.lastQueried[name],
+		})
+	}
+	return resp, nil
+}
+
+func (i *mockIngester) OutOfOrderSeriesStats(ctx context.Context, req *client.OutOfOrderSeriesStatsRequest, opts ...grpc.CallOption) (*client.OutOfOrderSeriesStatsResponse, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	i.trackCall("OutOfOrderSeriesStats")
+
+	if !i.happy.Load() {
+		return nil, errFail
+	}
+
+	return &client.OutOfOrderSeriesStatsResponse{Series: i.outOfOrderStats, TrackedSinceTimestampMs: i.trackedSince}, nil
+}
+
 func (i *mockIngester) MetricsMetadata(ctx context.Context, req *client.MetricsMetadataRequest, opts ...grpc.CallOption) (*client.MetricsMetadataResponse, error) {
 	i.Lock()
 	defer i.Unlock()
@@ -4521,6 +4673,133 @@ func TestDistributor_PushLabelSetMetrics(t *testing.T) {
 		`), "cortex_distributor_received_samples_per_labelset_total"))
 }
 
+func TestDistributor_PushLabelSetRateLimits(t *testing.T) {
+	t.Parallel()
+
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	limits.LimitsPerLabelSet = []validation.LimitsPerLabelSet{
+		{Hash: 0, LabelSet: labels.FromStrings("cluster", "one"), Limits: validation.LimitsPerLabelSetEntry{IngestionRate: 1, IngestionBurstSize: 2}},
+		{Hash: 1, LabelSet: labels.FromStrings("cluster", "two")},
+	}
+
+	ds, _, regs, _ := prepare(t, prepConfig{
+		numIngesters:     2,
+		happyIngesters:   2,
+		numDistributors:  1,
+		shardByAllLabels: true,
+		limits:           &limits,
+	})
+
+	inputSeries := []labels.Labels{
+		labels.FromStrings("__name__", "foo", "cluster", "one"),
+		labels.FromStrings("__name__", "bar", "cluster", "one"),
+		labels.FromStrings("__name__", "foo", "cluster", "two"),
+	}
+	ctx := user.InjectOrgID(context.Background(), "user")
+
+	// The first request fits the labelset burst size.
+	_, err := ds[0].Push(ctx, mockWriteRequest(inputSeries, 1, 1, false))
+	require.NoError(t, err)
+
+	// The samples of the rate limited labelset should be discarded, while the other series are ingested.
+	_, err = ds[0].Push(ctx, mockWriteRequest(inputSeries, 2, 2, false))
+	require.Error(t, err)
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	assert.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+	assert.Contains(t, string(resp.Body), `ingestion rate limit (1) of the labelset {cluster="one"} exceeded`)
+
+	require.NoError(t, testutil.GatherAndCompare(regs[0], strings.NewReader(`
+		# HELP cortex_discarded_samples_per_labelset_total The total number of samples that were discarded for each labelset.
+		# TYPE cortex_discarded_samples_per_labelset_total counter
+		cortex_discarded_samples_per_labelset_total{labelset="{cluster=\"one\"}",reason="per_labelset_rate_limited",user="user"} 2
+		# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+		# TYPE cortex_discarded_samples_total counter
+		cortex_discarded_samples_total{reason="per_labelset_rate_limited",user="user"} 2
+		# HELP cortex_distributor_received_samples_per_labelset_total The total number of received samples per label set, excluding rejected and deduped samples.
+		# TYPE cortex_distributor_received_samples_per_labelset_total counter
+		cortex_distributor_received_samples_per_labelset_total{labelset="{cluster=\"one\"}",type="float",user="user"} 2
+		cortex_distributor_received_samples_per_labelset_total{labelset="{cluster=\"two\"}",type="float",user="user"} 2
+		`), "cortex_discarded_samples_per_labelset_total", "cortex_discarded_samples_total", "cortex_distributor_received_samples_per_labelset_total"))
+	assert.Equal(t, float64(4), testutil.ToFloat64(ds[0].receivedSamples.WithLabelValues("user", "float")))
+
+	// Removing the limit should remove the per-labelset metrics.
+	limits.LimitsPerLabelSet = limits.LimitsPerLabelSet[1:]
+	ds[0].limits = validation.NewOverrides(limits, nil)
+	ds[0].updateLabelSetMetrics()
+	require.NoError(t, testutil.GatherAndCompare(regs[0], strings.NewReader(""), "cortex_discarded_samples_per_labelset_total"))
+}
+
+func TestDistributor_PushLabelSetRateLimitsShouldNotConsumeTheRateOfTheOtherLabelSetsWhenRejected(t *testing.T) {
+	t.Parallel()
+
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	limits.LimitsPerLabelSet = []validation.LimitsPerLabelSet{
+		{Hash: 0, LabelSet: labels.FromStrings("cluster", "one"), Limits: validation.LimitsPerLabelSetEntry{IngestionRate: 1, IngestionBurstSize: 2}},
+		{Hash: 1, LabelSet: labels.FromStrings("env", "prod"), Limits: validation.LimitsPerLabelSetEntry{IngestionRate: 1, IngestionBurstSize: 1}},
+	}
+
+	ds, _, _, _ := prepare(t, prepConfig{
+		numIngesters:     2,
+		happyIngesters:   2,
+		numDistributors:  1,
+		shardByAllLabels: true,
+		limits:           &limits,
+	})
+	ctx := user.InjectOrgID(context.Background(), "user")
+
+	// The series match both labelsets, but the second one only exceeds the rate limit of the second labelset.
+	_, err := ds[0].Push(ctx, mockWriteRequest([]labels.Labels{
+		labels.FromStrings("__name__", "foo", "cluster", "one", "env", "prod"),
+		labels.FromStrings("__name__", "bar", "cluster", "one", "env", "prod"),
+	}, 1, 1, false))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `ingestion rate limit (1) of the labelset {env="prod"} exceeded`)
+
+	// The rejected samples should not have been counted in the rate of the first labelset.
+	_, err = ds[0].Push(ctx, mockWriteRequest([]labels.Labels{labels.FromStrings("__name__", "foo", "cluster", "one")}, 1, 2, false))
+	require.NoError(t, err)
+}
+
+func TestDistributor_PushLabelSetRateLimitsShouldNotConsumeTheRateWhenRejectedByTheTenantRateLimit(t *testing.T) {
+	t.Parallel()
+
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	limits.IngestionRate = 1
+	limits.IngestionBurstSize = 2
+	limits.LimitsPerLabelSet = []validation.LimitsPerLabelSet{
+		{Hash: 0, LabelSet: labels.FromStrings("cluster", "one"), Limits: validation.LimitsPerLabelSetEntry{IngestionRate: 1, IngestionBurstSize: 2}},
+	}
+
+	ds, _, _, _ := prepare(t, prepConfig{
+		numIngesters:     2,
+		happyIngesters:   2,
+		numDistributors:  1,
+		shardByAllLabels: true,
+		limits:           &limits,
+	})
+	ctx := user.InjectOrgID(context.Background(), "user")
+
+	// The request exceeds the tenant burst size.
+	_, err := ds[0].Push(ctx, mockWriteRequest([]labels.Labels{
+		labels.FromStrings("__name__", "foo", "cluster", "one"),
+		labels.FromStrings("__name__", "bar", "cluster", "one"),
+		labels.FromStrings("__name__", "baz", "cluster", "one"),
+	}, 1, 1, false))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ingestion rate limit (1) exceeded")
+
+	// The rejected samples should not have been counted in the rate of the labelset.
+	_, err = ds[0].Push(ctx, mockWriteRequest([]labels.Labels{
+		labels.FromStrings("__name__", "foo", "cluster", "one"),
+		labels.FromStrings("__name__", "bar", "cluster", "one"),
+	}, 1, 2, false))
+	require.NoError(t, err)
+}
+
 func countMockIngestersCalls(ingesters []*mockIngester, name string) int {
 	count := 0
 	for i := range ingesters {
@@ -29,21 +29,22 @@ const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 func init() { proto.RegisterFile("distributor.proto", fileDescriptor_c518e33639ca565d) }
 
 var fileDescriptor_c518e33639ca565d = []byte{
-	// 212 bytes of a gzipped FileDescriptorProto
+	// 225 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x4c, 0xc9, 0x2c, 0x2e,
 	0x29, 0xca, 0x4c, 0x2a, 0x2d, 0xc9, 0x2f, 0xd2, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0x46,
 	0x12, 0x92, 0x12, 0x49, 0xcf, 0x4f, 0xcf, 0x07, 0x8b, 0xeb, 0x83, 0x58, 0x10, 0x25, 0x52, 0x96,
 	0xe9, 0x99, 0x25, 0x19, 0xa5, 0x49, 0x7a, 0xc9, 0xf9, 0xb9, 0xfa, 0xc9, 0xf9, 0x45, 0x25, 0xa9,
 	0x15, 0x05, 0x45, 0xf9, 0x59, 0xa9, 0xc9, 0x25, 0x50, 0x9e, 0x7e, 0x41, 0x76, 0x3a, 0x4c, 0x22,
-	0x09, 0xca, 0x80, 0x68, 0x35, 0xf2, 0xe0, 0xe2, 0x76, 0x41, 0x98, 0x2f, 0x64, 0xc9, 0xc5, 0x12,
-	0x50, 0x5a, 0x9c, 0x21, 0x24, 0xa6, 0x07, 0x53, 0xae, 0x17, 0x5e, 0x94, 0x59, 0x92, 0x1a, 0x94,
-	0x5a, 0x58, 0x9a, 0x5a, 0x5c, 0x22, 0x25, 0x8e, 0x21, 0x5e, 0x5c, 0x90, 0x9f, 0x57, 0x9c, 0xaa,
-	0xc4, 0xe0, 0xe4, 0x7c, 0xe1, 0xa1, 0x1c, 0xc3, 0x8d, 0x87, 0x72, 0x0c, 0x1f, 0x1e, 0xca, 0x31,
-	0x36, 0x3c, 0x92, 0x63, 0x5c, 0xf1, 0x48, 0x8e, 0xf1, 0xc4, 0x23, 0x39, 0xc6, 0x0b, 0x8f, 0xe4,
-	0x18, 0x1f, 0x3c, 0x92, 0x63, 0x7c, 0xf1, 0x48, 0x8e, 0xe1, 0xc3, 0x23, 0x39, 0xc6, 0x09, 0x8f,
-	0xe5, 0x18, 0x2e, 0x3c, 0x96, 0x63, 0xb8, 0xf1, 0x58, 0x8e, 0x21, 0x8a, 0x17, 0xc9, 0x77, 0x05,
-	0x49, 0x49, 0x6c, 0x60, 0x57, 0x19, 0x03, 0x02, 0x00, 0x00, 0xff, 0xff, 0x0d, 0x5b, 0x37, 0x6f,
-	0x08, 0x01, 0x00, 0x00,
+	0x09, 0xca, 0x80, 0x68, 0x35, 0xea, 0x60, 0xe4, 0xe2, 0x76, 0x41, 0x58, 0x20, 0x64, 0xc9, 0xc5,
+	0x12, 0x50, 0x5a, 0x9c, 0x21, 0x24, 0xa6, 0x07, 0x53, 0xaf, 0x17, 0x5e, 0x94, 0x59, 0x92, 0x1a,
+	0x94, 0x5a, 0x58, 0x9a, 0x5a, 0x5c, 0x22, 0x25, 0x8e, 0x21, 0x5e, 0x5c, 0x90, 0x9f, 0x57, 0x9c,
+	0xaa, 0xc4, 0x20, 0x64, 0xc7, 0xc5, 0xe9, 0x98, 0x9e, 0x5e, 0x94, 0x9a, 0x9e, 0x58, 0x92, 0x4a,
+	0x86, 0x7e, 0x27, 0xe7, 0x0b, 0x0f, 0xe5, 0x18, 0x6e, 0x3c, 0x94, 0x63, 0xf8, 0xf0, 0x50, 0x8e,
+	0xb1, 0xe1, 0x91, 0x1c, 0xe3, 0x8a, 0x47, 0x72, 0x8c, 0x27, 0x1e, 0xc9, 0x31, 0x5e, 0x78, 0x24,
+	0xc7, 0xf8, 0xe0, 0x91, 0x1c, 0xe3, 0x8b, 0x47, 0x72, 0x0c, 0x1f, 0x1e, 0xc9, 0x31, 0x4e, 0x78,
+	0x2c, 0xc7, 0x70, 0xe1, 0xb1, 0x1c, 0xc3, 0x8d, 0xc7, 0x72, 0x0c, 0x51, 0xbc, 0x48, 0xc1, 0x53,
+	0x90, 0x94, 0xc4, 0x06, 0xf6, 0x96, 0x31, 0x60, 0x00, 0x90, 0x45, 0x9a, 0x5c, 0x49, 0x01, 0x00,
+	0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -59,6 +60,9 @@ const _ = grpc.SupportPackageIsVersion4
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type DistributorClient interface {
 	Push(ctx context.Context, in *cortexpb.WriteRequest, opts ...grpc.CallOption) (*cortexpb.WriteResponse, error)
+	// Aggregate receives the samples matching the aggregation rules whose output
+	// series are owned by this distributor.
+	Aggregate(ctx context.Context, in *cortexpb.WriteRequest, opts ...grpc.CallOption) (*cortexpb.WriteResponse, error)
 }
 
 type distributorClient struct {
@@ -78,9 +82,21 @@ func (c *distributorClient) Push(ctx context.Context, in *cortexpb.WriteRequest,
 	return out, nil
 }
 
+func (c *distributorClient) Aggregate(ctx context.Context, in *cortexpb.WriteRequest, opts ...grpc.CallOption) (*cortexpb.WriteResponse, error) {
+	out := new(cortexpb.WriteResponse)
+	err := c.cc.Invoke(ctx, "/distributor.Distributor/Aggregate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DistributorServer is the server API for Distributor service.
 type DistributorServer interface {
 	Push(context.Context, *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error)
+	// Aggregate receives the samples matching the aggregation rules whose output
+	// series are owned by this distributor.
+	Aggregate(context.Context, *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error)
 }
 
 // UnimplementedDistributorServer can be embedded to have forward compatible implementations.
@@ -90,6 +106,9 @@ type UnimplementedDistributorServer struct {
 func (*UnimplementedDistributorServer) Push(ctx context.Context, req *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Push not implemented")
 }
+func (*UnimplementedDistributorServer) Aggregate(ctx context.Context, req *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Aggregate not implemented")
+}
 
 func RegisterDistributorServer(s *grpc.Server, srv DistributorServer) {
 	s.RegisterService(&_Distributor_serviceDesc, srv)
@@ -113,6 +132,24 @@ func _Distributor_Push_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Distributor_Aggregate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(cortexpb.WriteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistributorServer).Aggregate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/distributor.Distributor/Aggregate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistributorServer).Aggregate(ctx, req.(*cortexpb.WriteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Distributor_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "distributor.Distributor",
 	HandlerType: (*DistributorServer)(nil),
@@ -121,6 +158,10 @@ var _Distributor_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Push",
 			Handler:    _Distributor_Push_Handler,
 		},
+		{
+			MethodName: "Aggregate",
+			Handler:    _Distributor_Aggregate_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "distributor.proto",
@@ -1,6 +1,9 @@
 package distributor
 
 import (
+	"strconv"
+	"strings"
+
 	"golang.org/x/time/rate"
 
 	"github.com/cortexproject/cortex/pkg/util/limiter"
@@ -118,3 +121,67 @@ func (s *globalStrategyNativeHistogram) Burst(tenantID string) int {
 	// to keep it easier to understand for users / operators.
 	return s.limits.NativeHistogramIngestionBurstSize(tenantID)
 }
+
+// labelSetStrategy is the ingestion rate strategy of the limits per LabelSet. Rate
+// limiters are keyed by labelSetRateLimiterKey(). If the ring is nil, the limits are
+// applied individually to each distributor (local strategy), otherwise they're evenly
+// shared across the healthy distributors (global strategy).
+type labelSetStrategy struct {
+	limits *validation.Overrides
+	ring   ReadLifecycler
+}
+
+func newLabelSetIngestionRateStrategy(limits *validation.Overrides, ring ReadLifecycler) limiter.RateLimiterStrategy {
+	return &labelSetStrategy{
+		limits: limits,
+		ring:   ring,
+	}
+}
+
+func (s *labelSetStrategy) Limit(key string) float64 {
+	limit, ok := s.labelSetLimit(key)
+	if !ok || limit.Limits.IngestionRate <= 0 {
+		return float64(rate.Inf)
+	}
+
+	if s.ring == nil {
+		return limit.Limits.IngestionRate
+	}
+
+	numDistributors := s.ring.HealthyInstancesCount()
+	if numDistributors == 0 {
+		return limit.Limits.IngestionRate
+	}
+	return limit.Limits.IngestionRate / float64(numDistributors)
+}
+
+func (s *labelSetStrategy) Burst(key string) int {
+	limit, ok := s.labelSetLimit(key)
+	if ok && limit.Limits.IngestionBurstSize > 0 {
+		return limit.Limits.IngestionBurstSize
+	}
+
+	tenantID, _, _ := strings.Cut(key, labelSetRateLimiterKeySeparator)
+	return s.limits.IngestionBurstSize(tenantID)
+}
+
+func (s *labelSetStrategy) labelSetLimit(key string) (validation.LimitsPerLabelSet, bool) {
+	tenantID, hash, ok := strings.Cut(key, labelSetRateLimiterKeySeparator)
+	if !ok {
+		return validation.LimitsPerLabelSet{}, false
+	}
+
+	for _, limit := range s.limits.LimitsPerLabelSet(tenantID) {
+		if strconv.FormatUint(limit.Hash, 10) == hash {
+			return limit, true
+		}
+	}
+	return validation.LimitsPerLabelSet{}, false
+}
+
+// labelSetRateLimiterKeySeparator can't be part of a tenant ID.
+const labelSetRateLimiterKeySeparator = "/"
+
+func labelSetRateLimiterKey(tenantID string, limit validation.LimitsPerLabelSet) string {
+	return tenantID + labelSetRateLimiterKeySeparator + strconv.FormatUint(limit.Hash, 10)
+}
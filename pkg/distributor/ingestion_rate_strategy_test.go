@@ -3,6 +3,7 @@ package distributor
 import (
 	"testing"
 
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -121,6 +122,67 @@ func TestIngestionRateStrategy(t *testing.T) {
 	}
 }
 
+func TestLabelSetIngestionRateStrategy(t *testing.T) {
+	t.Parallel()
+
+	limits := validation.Limits{
+		IngestionBurstSize: 10000,
+		LimitsPerLabelSet: []validation.LimitsPerLabelSet{
+			{Hash: 1, LabelSet: labels.FromStrings("team", "a"), Limits: validation.LimitsPerLabelSetEntry{IngestionRate: 1000, IngestionBurstSize: 2000}},
+			{Hash: 2, LabelSet: labels.FromStrings("team", "b"), Limits: validation.LimitsPerLabelSetEntry{IngestionRate: 100}},
+			{Hash: 3, LabelSet: labels.FromStrings("team", "c"), Limits: validation.LimitsPerLabelSetEntry{MaxSeries: 10}},
+		},
+	}
+	overrides := validation.NewOverrides(limits, nil)
+
+	ring := newReadLifecyclerMock()
+	ring.On("HealthyInstancesCount").Return(2)
+
+	tests := map[string]struct {
+		ring          ReadLifecycler
+		key           string
+		expectedLimit float64
+		expectedBurst int
+	}{
+		"local strategy should return the configured limits": {
+			key:           labelSetRateLimiterKey("test", limits.LimitsPerLabelSet[0]),
+			expectedLimit: 1000,
+			expectedBurst: 2000,
+		},
+		"global strategy should share the limit across the number of distributors": {
+			ring:          ring,
+			key:           labelSetRateLimiterKey("test", limits.LimitsPerLabelSet[0]),
+			expectedLimit: 500,
+			expectedBurst: 2000,
+		},
+		"should default the burst size to the tenant one": {
+			key:           labelSetRateLimiterKey("test", limits.LimitsPerLabelSet[1]),
+			expectedLimit: 100,
+			expectedBurst: 10000,
+		},
+		"should not limit a labelset without ingestion rate": {
+			key:           labelSetRateLimiterKey("test", limits.LimitsPerLabelSet[2]),
+			expectedLimit: float64(rate.Inf),
+			expectedBurst: 10000,
+		},
+		"should not limit a labelset not configured anymore": {
+			key:           labelSetRateLimiterKey("test", validation.LimitsPerLabelSet{Hash: 4}),
+			expectedLimit: float64(rate.Inf),
+			expectedBurst: 10000,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			strategy := newLabelSetIngestionRateStrategy(overrides, testData.ring)
+			assert.Equal(t, testData.expectedLimit, strategy.Limit(testData.key))
+			assert.Equal(t, testData.expectedBurst, strategy.Burst(testData.key))
+		})
+	}
+}
+
 type readLifecyclerMock struct {
 	mock.Mock
 }
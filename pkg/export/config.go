@@ -0,0 +1,50 @@
+package export
+
+import (
+	"flag"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	errInvalidMaxConcurrentJobs = errors.New("the max concurrent export jobs must be greater than 0")
+	errInvalidSplitInterval     = errors.New("the export split interval must be greater than 0")
+	errInvalidHeartbeatTimeout  = errors.New("the export heartbeat timeout must be greater than 0")
+	errMissingDataDir           = errors.New("the export data directory must be set")
+)
+
+// Config holds the export config.
+type Config struct {
+	Enabled           bool          `yaml:"enabled"`
+	MaxConcurrentJobs int           `yaml:"max_concurrent_jobs"`
+	SplitInterval     time.Duration `yaml:"split_interval"`
+	HeartbeatTimeout  time.Duration `yaml:"heartbeat_timeout"`
+	DataDir           string        `yaml:"data_dir"`
+}
+
+// RegisterFlags registers the export flags.
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "export.enabled", false, "If true, the querier exposes the export API, which asynchronously exports the series of a tenant to the exports prefix of the tenant in the blocks storage.")
+	f.IntVar(&cfg.MaxConcurrentJobs, "export.max-concurrent-jobs", 2, "Maximum number of export jobs run concurrently by each querier. The other jobs submitted to the querier are pending until a job completes.")
+	f.DurationVar(&cfg.SplitInterval, "export.split-interval", 2*time.Hour, "The time range of the series queried at once by an export job. Each interval is written to separate files. It's reduced to the max query length of the tenant, if lower.")
+	f.DurationVar(&cfg.HeartbeatTimeout, "export.heartbeat-timeout", 5*time.Minute, "Pending or running export jobs whose state hasn't been updated for longer than this timeout are reported as failed, for example because the querier running them has been stopped.")
+	f.StringVar(&cfg.DataDir, "export.data-dir", "./export/", "Directory used to build the temporary TSDB blocks of the Parquet exports.")
+}
+
+// Validate the config.
+func (cfg *Config) Validate() error {
+	if cfg.MaxConcurrentJobs <= 0 {
+		return errInvalidMaxConcurrentJobs
+	}
+	if cfg.SplitInterval <= 0 {
+		return errInvalidSplitInterval
+	}
+	if cfg.HeartbeatTimeout <= 0 {
+		return errInvalidHeartbeatTimeout
+	}
+	if cfg.DataDir == "" {
+		return errMissingDataDir
+	}
+	return nil
+}
@@ -0,0 +1,589 @@
+package export
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/oklog/ulid/v2"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/thanos-io/objstore"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/requestmeta"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+const (
+	// defaultColDuration is the duration of the chunks columns, the same as the parquet converter.
+	defaultColDuration = 8 * time.Hour
+
+	// finishTimeout is the timeout to write the final state of a job, whose context may be canceled.
+	finishTimeout = time.Minute
+)
+
+// Exporter runs the export jobs of the tenants. The jobs query the series from the ingesters
+// and the long-term storage, and write them to the exports prefix of the tenant in the blocks
+// storage. The state of the jobs is stored alongside the exported files, so that it can be
+// read from any querier.
+type Exporter struct {
+	services.Service
+
+	cfg          Config
+	bucketClient objstore.Bucket
+	cfgProvider  bucket.TenantConfigProvider
+	queryable    storage.Queryable
+	limits       *validation.Overrides
+	logger       log.Logger
+
+	// slots limits the number of jobs running concurrently.
+	slots chan struct{}
+
+	// submitMtx serializes the submissions of the jobs, so that the limit of active jobs
+	// per tenant can't be exceeded by concurrent submissions to this exporter.
+	submitMtx sync.Mutex
+
+	// jobs are the pending and running jobs of this exporter, by tenant and job ID.
+	jobsMtx sync.Mutex
+	jobs    map[string]*runningJob
+	jobsWG  sync.WaitGroup
+
+	// jobsCtx is the parent context of the jobs, canceled when the exporter stops.
+	jobsCtx    context.Context
+	jobsCancel context.CancelFunc
+
+	jobsFinished   *prometheus.CounterVec
+	jobsRunning    prometheus.Gauge
+	writtenSamples prometheus.Counter
+	writtenBytes   prometheus.Counter
+}
+
+type runningJob struct {
+	userID string
+	cancel context.CancelFunc
+
+	// mtx protects the fields below, and serializes the writes of the job state.
+	mtx      sync.Mutex
+	job      *Job
+	canceled bool
+}
+
+// snapshot returns a copy of the job state.
+func (rj *runningJob) snapshot() *Job {
+	rj.mtx.Lock()
+	defer rj.mtx.Unlock()
+
+	job := *rj.job
+	job.Files = append([]File(nil), rj.job.Files...)
+	return &job
+}
+
+// update applies the input function to the job state and writes it to the bucket.
+func (rj *runningJob) update(ctx context.Context, userBkt objstore.Bucket, fn func(job *Job)) error {
+	rj.mtx.Lock()
+	defer rj.mtx.Unlock()
+
+	fn(rj.job)
+	rj.job.UpdatedAt = time.Now()
+	return writeJob(ctx, userBkt, rj.job)
+}
+
+// NewExporter makes a new Exporter, writing the exports to the blocks storage.
+func NewExporter(cfg Config, storageCfg cortex_tsdb.BlocksStorageConfig, cfgProvider bucket.TenantConfigProvider, queryable storage.Queryable, limits *validation.Overrides, logger log.Logger, reg prometheus.Registerer) (*Exporter, error) {
+	bucketClient, err := bucket.NewClient(context.Background(), storageCfg.Bucket, nil, "exporter", logger, reg)
+	if err != nil {
+		return nil, errors.Wrap(err, "create bucket client")
+	}
+
+	return newExporter(cfg, bucketClient, cfgProvider, queryable, limits, logger, reg), nil
+}
+
+func newExporter(cfg Config, bkt objstore.Bucket, cfgProvider bucket.TenantConfigProvider, queryable storage.Queryable, limits *validation.Overrides, logger log.Logger, reg prometheus.Registerer) *Exporter {
+	e := &Exporter{
+		cfg:          cfg,
+		bucketClient: bkt,
+		cfgProvider:  cfgProvider,
+		queryable:    queryable,
+		limits:       limits,
+		logger:       logger,
+		slots:        make(chan struct{}, cfg.MaxConcurrentJobs),
+		jobs:         map[string]*runningJob{},
+
+		jobsFinished: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_export_jobs_finished_total",
+			Help: "Total number of export jobs finished, by status.",
+		}, []string{"status"}),
+		jobsRunning: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "cortex_export_jobs_running",
+			Help: "Number of export jobs currently running.",
+		}),
+		writtenSamples: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_export_written_samples_total",
+			Help: "Total number of samples written by the export jobs.",
+		}),
+		writtenBytes: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_export_written_bytes_total",
+			Help: "Total number of bytes written by the export jobs.",
+		}),
+	}
+	e.jobsCtx, e.jobsCancel = context.WithCancel(context.Background())
+	e.Service = services.NewBasicService(e.starting, e.running, e.stopping)
+	return e
+}
+
+func (e *Exporter) starting(_ context.Context) error {
+	if err := os.MkdirAll(e.cfg.DataDir, 0750); err != nil {
+		return errors.Wrap(err, "create export data directory")
+	}
+
+	// Remove the heads left over by a previous run.
+	dirs, err := filepath.Glob(filepath.Join(e.cfg.DataDir, "head-*"))
+	if err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		if err := os.RemoveAll(dir); err != nil {
+			return errors.Wrap(err, "remove export head directory")
+		}
+	}
+	return nil
+}
+
+func (e *Exporter) running(ctx context.Context) error {
+	// The jobs are updated several times within the heartbeat timeout, so that they're
+	// not reported as failed if an update is delayed.
+	ticker := time.NewTicker(e.cfg.HeartbeatTimeout / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.heartbeat(ctx)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (e *Exporter) stopping(_ error) error {
+	e.jobsCancel()
+	e.jobsWG.Wait()
+	return nil
+}
+
+// heartbeat updates the state of the local jobs, and cancels the jobs which have been
+// canceled through another querier.
+func (e *Exporter) heartbeat(ctx context.Context) {
+	e.jobsMtx.Lock()
+	jobs := make([]*runningJob, 0, len(e.jobs))
+	for _, rj := range e.jobs {
+		jobs = append(jobs, rj)
+	}
+	e.jobsMtx.Unlock()
+
+	for _, rj := range jobs {
+		userBkt := e.userBucket(rj.userID)
+		jobID := rj.snapshot().ID
+
+		if e.canceledRemotely(ctx, rj, userBkt) {
+			continue
+		}
+		if err := rj.update(ctx, userBkt, func(*Job) {}); err != nil {
+			level.Warn(e.logger).Log("msg", "failed to update the export job state", "user", rj.userID, "job", jobID, "err", err)
+		}
+	}
+}
+
+// canceledRemotely cancels the local job if it has been canceled through another querier.
+func (e *Exporter) canceledRemotely(ctx context.Context, rj *runningJob, userBkt objstore.Bucket) bool {
+	stored, err := readJob(ctx, userBkt, rj.snapshot().ID)
+	if err != nil || stored.Status != StatusCanceled {
+		return false
+	}
+
+	e.cancelRunningJob(rj)
+	return true
+}
+
+func (e *Exporter) userBucket(userID string) objstore.Bucket {
+	return bucket.NewUserBucketClient(userID, e.bucketClient, e.cfgProvider)
+}
+
+func jobKey(userID, jobID string) string {
+	return userID + "/" + jobID
+}
+
+func (e *Exporter) runningJob(userID, jobID string) *runningJob {
+	e.jobsMtx.Lock()
+	defer e.jobsMtx.Unlock()
+	return e.jobs[jobKey(userID, jobID)]
+}
+
+// resolve reports as failed the active jobs of other queriers whose state hasn't been
+// updated within the heartbeat timeout.
+func (e *Exporter) resolve(job *Job) *Job {
+	if job.Active() && time.Since(job.UpdatedAt) > e.cfg.HeartbeatTimeout {
+		job.Status = StatusFailed
+		job.Error = fmt.Sprintf("the export job has been interrupted, its state hasn't been updated for more than %s", e.cfg.HeartbeatTimeout)
+	}
+	return job
+}
+
+// Job returns the job of the tenant.
+func (e *Exporter) Job(ctx context.Context, userID, jobID string) (*Job, error) {
+	if rj := e.runningJob(userID, jobID); rj != nil {
+		return rj.snapshot(), nil
+	}
+
+	job, err := readJob(ctx, e.userBucket(userID), jobID)
+	if err != nil {
+		return nil, err
+	}
+	return e.resolve(job), nil
+}
+
+// Jobs returns the jobs of the tenant, sorted by creation time.
+func (e *Exporter) Jobs(ctx context.Context, userID string) ([]*Job, error) {
+	jobs, err := listJobs(ctx, e.userBucket(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, job := range jobs {
+		if rj := e.runningJob(userID, job.ID); rj != nil {
+			jobs[i] = rj.snapshot()
+		} else {
+			jobs[i] = e.resolve(job)
+		}
+	}
+	return jobs, nil
+}
+
+// Submit creates a job exporting the series matching the input matchers, between start and
+// end, and runs it asynchronously. The job is restricted to the series of the query access
+// policy of the request, if any.
+func (e *Exporter) Submit(ctx context.Context, userID, selector string, matchers []*labels.Matcher, start, end time.Time, format Format) (*Job, error) {
+	e.submitMtx.Lock()
+	defer e.submitMtx.Unlock()
+
+	if limit := e.limits.ExportMaxConcurrentJobs(userID); limit > 0 {
+		jobs, err := e.Jobs(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		active := 0
+		for _, job := range jobs {
+			if job.Active() {
+				active++
+			}
+		}
+		if active >= limit {
+			return nil, validation.LimitError(fmt.Sprintf("the tenant has %d pending or running export jobs, which is the limit", active))
+		}
+	}
+
+	now := time.Now()
+	job := &Job{
+		ID:           ulid.MustNew(ulid.Timestamp(now), nil).String(),
+		Selector:     selector,
+		Start:        start,
+		End:          end,
+		Format:       format,
+		AccessPolicy: requestmeta.AccessPolicyFromContext(ctx),
+		Status:       StatusPending,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		Progress:     Progress{ExportedUntil: start},
+	}
+	if err := writeJob(ctx, e.userBucket(userID), job); err != nil {
+		return nil, err
+	}
+
+	jobCtx, cancel := context.WithCancel(e.jobsCtx)
+	rj := &runningJob{userID: userID, cancel: cancel, job: job}
+	snapshot := rj.snapshot()
+
+	e.jobsMtx.Lock()
+	e.jobs[jobKey(userID, job.ID)] = rj
+	e.jobsWG.Add(1)
+	e.jobsMtx.Unlock()
+
+	go e.run(jobCtx, rj, matchers)
+
+	level.Info(e.logger).Log("msg", "export job submitted", "user", userID, "job", job.ID, "selector", selector, "start", start, "end", end, "format", format)
+	return snapshot, nil
+}
+
+// Cancel cancels the job if it's active, and otherwise deletes the job and its files. It
+// returns true if the job has been canceled.
+func (e *Exporter) Cancel(ctx context.Context, userID, jobID string) (bool, error) {
+	if rj := e.runningJob(userID, jobID); rj != nil {
+		e.cancelRunningJob(rj)
+		return true, nil
+	}
+
+	userBkt := e.userBucket(userID)
+	job, err := readJob(ctx, userBkt, jobID)
+	if err != nil {
+		return false, err
+	}
+
+	if e.resolve(job).Active() {
+		// The job is run by another querier, which cancels it on its next heartbeat.
+		job.Status = StatusCanceled
+		job.UpdatedAt = time.Now()
+		return true, writeJob(ctx, userBkt, job)
+	}
+
+	return false, deleteJob(ctx, userBkt, jobID)
+}
+
+func (e *Exporter) cancelRunningJob(rj *runningJob) {
+	rj.mtx.Lock()
+	rj.canceled = true
+	rj.mtx.Unlock()
+
+	rj.cancel()
+}
+
+// run waits for a free slot and runs the job.
+func (e *Exporter) run(ctx context.Context, rj *runningJob, matchers []*labels.Matcher) {
+	defer e.jobsWG.Done()
+	defer rj.cancel()
+	defer func() {
+		e.jobsMtx.Lock()
+		delete(e.jobs, jobKey(rj.userID, rj.job.ID))
+		e.jobsMtx.Unlock()
+	}()
+
+	userBkt := e.userBucket(rj.userID)
+
+	var err error
+	select {
+	case e.slots <- struct{}{}:
+		e.jobsRunning.Inc()
+		err = e.export(ctx, rj, userBkt, matchers)
+		e.jobsRunning.Dec()
+		<-e.slots
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	e.finish(rj, userBkt, err)
+}
+
+// finish writes the final state of the job.
+func (e *Exporter) finish(rj *runningJob, userBkt objstore.Bucket, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+
+	rj.mtx.Lock()
+	canceled := rj.canceled
+	rj.mtx.Unlock()
+
+	status, errMsg := StatusCompleted, ""
+	switch {
+	case canceled:
+		status = StatusCanceled
+	case err != nil && errors.Is(err, context.Canceled):
+		status, errMsg = StatusFailed, "the export job has been interrupted by the shutdown of the querier"
+	case err != nil:
+		status, errMsg = StatusFailed, err.Error()
+	}
+
+	// The files of the jobs which haven't completed are partial, including the ones
+	// uploaded before exceeding a limit.
+	if status != StatusCompleted {
+		if err := deleteFiles(ctx, userBkt, rj.job.ID); err != nil {
+			level.Warn(e.logger).Log("msg", "failed to delete the files of the export job", "user", rj.userID, "job", rj.job.ID, "status", status, "err", err)
+		}
+	}
+
+	updateErr := rj.update(ctx, userBkt, func(job *Job) {
+		job.Status = status
+		job.Error = errMsg
+		if status != StatusCompleted {
+			job.Files = nil
+		}
+	})
+	if updateErr != nil {
+		level.Error(e.logger).Log("msg", "failed to write the final state of the export job", "user", rj.userID, "job", rj.job.ID, "err", updateErr)
+	}
+
+	e.jobsFinished.WithLabelValues(string(status)).Inc()
+	level.Info(e.logger).Log("msg", "export job finished", "user", rj.userID, "job", rj.job.ID, "status", status, "err", errMsg)
+}
+
+// export exports the series of the job, one split interval at a time.
+func (e *Exporter) export(ctx context.Context, rj *runningJob, userBkt objstore.Bucket, matchers []*labels.Matcher) error {
+	job := rj.snapshot()
+	ctx = user.InjectOrgID(ctx, rj.userID)
+	if job.AccessPolicy != "" {
+		ctx = requestmeta.ContextWithAccessPolicy(ctx, job.AccessPolicy)
+	}
+
+	if err := rj.update(ctx, userBkt, func(job *Job) { job.Status = StatusRunning }); err != nil {
+		return err
+	}
+
+	// Each split interval is a single query, so it can't be longer than the max query length.
+	split := e.cfg.SplitInterval.Milliseconds()
+	if maxQueryLength := e.limits.MaxQueryLength(rj.userID).Milliseconds(); maxQueryLength > 0 && maxQueryLength < split {
+		split = maxQueryLength
+	}
+	maxBytes := e.limits.ExportMaxBytes(rj.userID)
+	filesBkt := objstore.NewPrefixedBucket(userBkt, filesDir(job.ID))
+
+	written := int64(0)
+	mint, maxt := util.TimeToMillis(job.Start), util.TimeToMillis(job.End)
+	for part, start := 0, mint; start <= maxt; part, start = part+1, start+split {
+		end := min(start+split-1, maxt)
+
+		remaining := int64(0)
+		if maxBytes > 0 {
+			remaining = maxBytes - written
+			if remaining <= 0 {
+				return errBytesLimitExceeded(maxBytes)
+			}
+		}
+
+		files, stats, err := e.exportSplit(ctx, rj.userID, filesBkt, job.Format, part, start, end, matchers, remaining)
+		if errors.As(err, new(errBytesLimitExceeded)) {
+			return errBytesLimitExceeded(maxBytes)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "export the series between %s and %s", util.TimeFromMillis(start).UTC().Format(time.RFC3339), util.TimeFromMillis(end).UTC().Format(time.RFC3339))
+		}
+
+		bytes := int64(0)
+		for _, f := range files {
+			bytes += f.Size
+		}
+		written += bytes
+		e.writtenSamples.Add(float64(stats.samples))
+		e.writtenBytes.Add(float64(bytes))
+
+		if e.canceledRemotely(ctx, rj, userBkt) {
+			return ctx.Err()
+		}
+
+		err = rj.update(ctx, userBkt, func(job *Job) {
+			job.Files = append(job.Files, files...)
+			job.Progress.Series += stats.series
+			job.Progress.Samples += stats.samples
+			job.Progress.Bytes += bytes
+			job.updateProgress(util.TimeFromMillis(end))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportSplit exports the series between mint and maxt, both included, and returns the
+// written files. If maxBytes is greater than 0, the export fails if the files are bigger.
+func (e *Exporter) exportSplit(ctx context.Context, userID string, bkt objstore.Bucket, format Format, part int, mint, maxt int64, matchers []*labels.Matcher, maxBytes int64) ([]File, splitStats, error) {
+	q, err := e.queryable.Querier(mint, maxt)
+	if err != nil {
+		return nil, splitStats{}, err
+	}
+	defer q.Close()
+
+	set := q.Select(ctx, true, &storage.SelectHints{Start: mint, End: maxt}, matchers...)
+
+	switch format {
+	case FormatParquet:
+		return e.exportParquet(ctx, userID, bkt, fmt.Sprintf("part-%05d", part), set, mint, maxt, maxBytes)
+	default:
+		return exportOpenMetrics(ctx, bkt, fmt.Sprintf("part-%05d.om.gz", part), set, maxBytes)
+	}
+}
+
+// exportOpenMetrics streams the series of the set to a gzipped OpenMetrics file. No file is
+// written if the set has no series.
+func exportOpenMetrics(ctx context.Context, bkt objstore.Bucket, name string, set storage.SeriesSet, maxBytes int64) ([]File, splitStats, error) {
+	if !set.Next() {
+		return nil, splitStats{}, set.Err()
+	}
+
+	var (
+		stats    splitStats
+		writeErr error
+		pr, pw   = io.Pipe()
+		lw       = &limitedWriter{w: pw, limit: maxBytes}
+		done     = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+
+		gw := gzip.NewWriter(lw)
+		stats, writeErr = writeOpenMetrics(gw, &peekedSeriesSet{SeriesSet: set, peeked: true})
+		if writeErr == nil {
+			writeErr = gw.Close()
+		}
+		pw.CloseWithError(writeErr)
+	}()
+
+	err := bkt.Upload(ctx, name, pr)
+	// Unblock the writer if the upload failed before reading all the data.
+	_ = pr.CloseWithError(errors.New("upload terminated"))
+	<-done
+
+	if writeErr != nil {
+		return nil, stats, writeErr
+	}
+	if err != nil {
+		return nil, stats, errors.Wrapf(err, "upload %s", name)
+	}
+	return []File{{Name: name, Size: lw.written}}, stats, nil
+}
+
+func (e *Exporter) exportParquet(ctx context.Context, userID string, bkt objstore.Bucket, name string, set storage.SeriesSet, mint, maxt int64, maxBytes int64) ([]File, splitStats, error) {
+	w := &parquetWriter{
+		dir:         e.cfg.DataDir,
+		sortColumns: append([]string{labels.MetricName}, e.limits.ParquetConverterSortColumns(userID)...),
+		logger:      log.With(e.logger, "user", userID),
+	}
+	stats, ok, err := w.write(ctx, bkt, name, set, mint, maxt)
+	if err != nil || !ok {
+		return nil, stats, err
+	}
+
+	var files []File
+	size := int64(0)
+	err = bkt.Iter(ctx, name, func(file string) error {
+		attrs, err := bkt.Attributes(ctx, file)
+		if err != nil {
+			return err
+		}
+		files = append(files, File{Name: path.Clean(file), Size: attrs.Size})
+		size += attrs.Size
+		return nil
+	}, objstore.WithRecursiveIter())
+	if err != nil {
+		return nil, stats, errors.Wrapf(err, "list the files of %s", name)
+	}
+
+	if maxBytes > 0 && size > maxBytes {
+		return nil, stats, errBytesLimitExceeded(maxBytes)
+	}
+	return files, stats, nil
+}
@@ -0,0 +1,405 @@
+package export
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/prometheus/prometheus/util/annotations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"go.uber.org/atomic"
+
+	"github.com/cortexproject/cortex/pkg/querier"
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/requestmeta"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/test"
+	cortex_testutil "github.com/cortexproject/cortex/pkg/util/testutil"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+// mockQuerier returns the samples of the series within the queried time range.
+type mockQuerier struct {
+	series     []storage.Series
+	mint, maxt int64
+
+	// block, if set, blocks the queries until their context is canceled.
+	block bool
+}
+
+func (m *mockQuerier) Select(ctx context.Context, _ bool, _ *storage.SelectHints, matchers ...*labels.Matcher) storage.SeriesSet {
+	if m.block {
+		<-ctx.Done()
+		return storage.ErrSeriesSet(ctx.Err())
+	}
+
+	var selected []storage.Series
+	for _, s := range m.series {
+		if !matchesAll(s.Labels(), matchers) {
+			continue
+		}
+
+		var samples []chunks.Sample
+		it := s.Iterator(nil)
+		for it.Next() != 0 {
+			if t, v := it.At(); t >= m.mint && t <= m.maxt {
+				samples = append(samples, sample{t: t, f: v})
+			}
+		}
+		if len(samples) > 0 {
+			selected = append(selected, storage.NewListSeries(s.Labels(), samples))
+		}
+	}
+	return &seriesSet{series: selected, i: -1}
+}
+
+func matchesAll(lbls labels.Labels, matchers []*labels.Matcher) bool {
+	for _, m := range matchers {
+		if !m.Matches(lbls.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *mockQuerier) LabelValues(context.Context, string, *storage.LabelHints, ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	return nil, nil, nil
+}
+
+func (m *mockQuerier) LabelNames(context.Context, *storage.LabelHints, ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	return nil, nil, nil
+}
+
+func (m *mockQuerier) Close() error {
+	return nil
+}
+
+type seriesSet struct {
+	series []storage.Series
+	i      int
+}
+
+func (s *seriesSet) Next() bool                        { s.i++; return s.i < len(s.series) }
+func (s *seriesSet) At() storage.Series                { return s.series[s.i] }
+func (s *seriesSet) Err() error                        { return nil }
+func (s *seriesSet) Warnings() annotations.Annotations { return nil }
+
+func mockQueryable(block bool, series ...storage.Series) storage.Queryable {
+	return storage.QueryableFunc(func(mint, maxt int64) (storage.Querier, error) {
+		return &mockQuerier{series: series, mint: mint, maxt: maxt, block: block}, nil
+	})
+}
+
+// newSeries returns a series with a sample every 30 minutes, from 0 to 5h.
+func newSeries(lbls labels.Labels) storage.Series {
+	var samples []chunks.Sample
+	for ts := time.Duration(0); ts <= 5*time.Hour; ts += 30 * time.Minute {
+		samples = append(samples, sample{t: ts.Milliseconds(), f: float64(ts / time.Minute)})
+	}
+	return storage.NewListSeries(lbls, samples)
+}
+
+func defaultLimits() validation.Limits {
+	limits := validation.Limits{}
+	flagext.DefaultValues(&limits)
+	return limits
+}
+
+func prepareExporter(t *testing.T, queryable storage.Queryable, limits validation.Limits) (*Exporter, objstore.Bucket) {
+	cfg := Config{}
+	flagext.DefaultValues(&cfg)
+	cfg.DataDir = t.TempDir()
+
+	// The in-memory bucket can't be used, because it serializes the uploads of the files
+	// written concurrently by the parquet converter.
+	bkt, _ := cortex_testutil.PrepareFilesystemBucket(t)
+	e := newExporter(cfg, bkt, nil, queryable, validation.NewOverrides(limits, nil), log.NewNopLogger(), prometheus.NewPedanticRegistry())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), e))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), e))
+	})
+	return e, bkt
+}
+
+func submit(t *testing.T, e *Exporter, selector string, end time.Duration, format Format) *Job {
+	matchers, err := parser.ParseMetricSelector(selector)
+	require.NoError(t, err)
+
+	job, err := e.Submit(context.Background(), "user-1", selector, matchers, time.UnixMilli(0).UTC(), time.UnixMilli(end.Milliseconds()).UTC(), format)
+	require.NoError(t, err)
+	return job
+}
+
+func waitStatus(t *testing.T, e *Exporter, jobID string, status Status) *Job {
+	test.Poll(t, 10*time.Second, status, func() any {
+		job, err := e.Job(context.Background(), "user-1", jobID)
+		require.NoError(t, err)
+		return job.Status
+	})
+
+	job, err := e.Job(context.Background(), "user-1", jobID)
+	require.NoError(t, err)
+	return job
+}
+
+func TestExporter_OpenMetrics(t *testing.T) {
+	e, bkt := prepareExporter(t, mockQueryable(false,
+		newSeries(labels.FromStrings(labels.MetricName, "up", "job", "api")),
+		newSeries(labels.FromStrings(labels.MetricName, "up", "job", "db")),
+		newSeries(labels.FromStrings(labels.MetricName, "other")),
+	), defaultLimits())
+
+	job := submit(t, e, `{__name__="up"}`, 5*time.Hour, FormatOpenMetrics)
+	assert.Equal(t, StatusPending, job.Status)
+
+	job = waitStatus(t, e, job.ID, StatusCompleted)
+	assert.Empty(t, job.Error)
+	assert.Equal(t, 100.0, job.Progress.Percent)
+	assert.Equal(t, time.UnixMilli(5*time.Hour.Milliseconds()).UTC(), job.Progress.ExportedUntil.UTC())
+	// The series are counted once for each of the 3 split intervals.
+	assert.Equal(t, int64(6), job.Progress.Series)
+	assert.Equal(t, int64(22), job.Progress.Samples)
+
+	require.Len(t, job.Files, 3)
+	assert.Equal(t, []string{"part-00000.om.gz", "part-00001.om.gz", "part-00002.om.gz"}, fileNames(job.Files))
+
+	size := int64(0)
+	for _, f := range job.Files {
+		size += f.Size
+	}
+	assert.Equal(t, size, job.Progress.Bytes)
+
+	// The first split interval exports the samples until 2h, excluded.
+	r, err := bkt.Get(context.Background(), path.Join("user-1", filesDir(job.ID), "part-00000.om.gz"))
+	require.NoError(t, err)
+	gr, err := gzip.NewReader(r)
+	require.NoError(t, err)
+	content, err := io.ReadAll(gr)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 10)
+	assert.Equal(t, "# TYPE up unknown", lines[0])
+	assert.Equal(t, `up{job="api"} 0 0`, lines[1])
+	assert.Equal(t, `up{job="api"} 90 5400`, lines[4])
+	assert.Equal(t, `up{job="db"} 0 0`, lines[5])
+	assert.Equal(t, "# EOF", lines[9])
+
+	// The state of the job is stored in the bucket.
+	stored, err := readJob(context.Background(), bucket.NewUserBucketClient("user-1", bkt, nil), job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, stored.Status)
+	assert.Equal(t, job.Files, stored.Files)
+}
+
+func TestExporter_Parquet(t *testing.T) {
+	e, _ := prepareExporter(t, mockQueryable(false,
+		newSeries(labels.FromStrings(labels.MetricName, "up", "job", "api")),
+		newSeries(labels.FromStrings(labels.MetricName, "up", "job", "db")),
+	), defaultLimits())
+
+	job := submit(t, e, `up`, 3*time.Hour, FormatParquet)
+	job = waitStatus(t, e, job.ID, StatusCompleted)
+
+	assert.Equal(t, []string{
+		"part-00000/0.chunks.parquet", "part-00000/0.labels.parquet",
+		"part-00001/0.chunks.parquet", "part-00001/0.labels.parquet",
+	}, fileNames(job.Files))
+	assert.Equal(t, int64(4), job.Progress.Series)
+	assert.Equal(t, int64(14), job.Progress.Samples)
+	for _, f := range job.Files {
+		assert.Positive(t, f.Size)
+	}
+}
+
+func TestExporter_MaxBytes(t *testing.T) {
+	for _, format := range []Format{FormatOpenMetrics, FormatParquet} {
+		t.Run(string(format), func(t *testing.T) {
+			limits := defaultLimits()
+			limits.ExportMaxBytes = 10
+
+			e, bkt := prepareExporter(t, mockQueryable(false, newSeries(labels.FromStrings(labels.MetricName, "up"))), limits)
+
+			job := submit(t, e, `up`, 5*time.Hour, format)
+			job = waitStatus(t, e, job.ID, StatusFailed)
+			assert.Equal(t, "the export exceeds the limit of 10 bytes", job.Error)
+
+			// The partial files of the failed job are deleted.
+			assert.Empty(t, job.Files)
+			var files []string
+			require.NoError(t, bkt.Iter(context.Background(), path.Join("user-1", filesDir(job.ID)), func(name string) error {
+				files = append(files, name)
+				return nil
+			}, objstore.WithRecursiveIter()))
+			assert.Empty(t, files)
+		})
+	}
+}
+
+// slowUploadBucket delays the uploads, widening the window between the check of the active
+// jobs of a submission and the write of the job.
+type slowUploadBucket struct {
+	objstore.Bucket
+}
+
+func (b slowUploadBucket) Upload(ctx context.Context, name string, r io.Reader, opts ...objstore.ObjectUploadOption) error {
+	time.Sleep(50 * time.Millisecond)
+	return b.Bucket.Upload(ctx, name, r, opts...)
+}
+
+func TestExporter_MaxConcurrentJobsWithConcurrentSubmissions(t *testing.T) {
+	cfg := Config{}
+	flagext.DefaultValues(&cfg)
+	cfg.DataDir = t.TempDir()
+
+	bkt, _ := cortex_testutil.PrepareFilesystemBucket(t)
+	queryable := mockQueryable(true, newSeries(labels.FromStrings(labels.MetricName, "up")))
+	e := newExporter(cfg, slowUploadBucket{Bucket: bkt}, nil, queryable, validation.NewOverrides(defaultLimits(), nil), log.NewNopLogger(), prometheus.NewPedanticRegistry())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), e))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), e))
+	})
+
+	matchers, err := parser.ParseMetricSelector(`up`)
+	require.NoError(t, err)
+
+	var (
+		wg        sync.WaitGroup
+		submitted atomic.Int64
+	)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := e.Submit(context.Background(), "user-1", `up`, matchers, time.Unix(0, 0), time.Unix(3600, 0), FormatOpenMetrics); err == nil {
+				submitted.Inc()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(1), submitted.Load())
+}
+
+func TestExporter_AccessPolicy(t *testing.T) {
+	limits := defaultLimits()
+	limits.QueryAccessPolicies = []validation.QueryAccessPolicy{{
+		Name:     "api-only",
+		Selector: `{job="api"}`,
+		Matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "job", "api")},
+	}}
+	queryable := querier.NewAccessPolicyQueryable(mockQueryable(false,
+		newSeries(labels.FromStrings(labels.MetricName, "up", "job", "api")),
+		newSeries(labels.FromStrings(labels.MetricName, "up", "job", "db")),
+	), validation.NewOverrides(limits, nil))
+	e, _ := prepareExporter(t, queryable, limits)
+
+	// The job runs with the access policy of the request which submitted it.
+	matchers, err := parser.ParseMetricSelector(`up`)
+	require.NoError(t, err)
+	ctx := requestmeta.ContextWithAccessPolicy(context.Background(), "api-only")
+	job, err := e.Submit(ctx, "user-1", `up`, matchers, time.UnixMilli(0).UTC(), time.UnixMilli(time.Hour.Milliseconds()).UTC(), FormatOpenMetrics)
+	require.NoError(t, err)
+	assert.Equal(t, "api-only", job.AccessPolicy)
+
+	job = waitStatus(t, e, job.ID, StatusCompleted)
+	assert.Equal(t, int64(1), job.Progress.Series)
+}
+
+func TestExporter_MaxConcurrentJobsAndCancel(t *testing.T) {
+	e, bkt := prepareExporter(t, mockQueryable(true, newSeries(labels.FromStrings(labels.MetricName, "up"))), defaultLimits())
+	ctx := context.Background()
+
+	job := submit(t, e, `up`, time.Hour, FormatOpenMetrics)
+	waitStatus(t, e, job.ID, StatusRunning)
+
+	matchers, err := parser.ParseMetricSelector(`up`)
+	require.NoError(t, err)
+	_, err = e.Submit(ctx, "user-1", `up`, matchers, time.Unix(0, 0), time.Unix(3600, 0), FormatOpenMetrics)
+	require.EqualError(t, err, "the tenant has 1 pending or running export jobs, which is the limit")
+	assert.ErrorAs(t, err, new(validation.LimitError))
+
+	// The jobs of the other tenants aren't limited.
+	_, err = e.Submit(ctx, "user-2", `up`, matchers, time.Unix(0, 0), time.Unix(3600, 0), FormatOpenMetrics)
+	require.NoError(t, err)
+
+	canceled, err := e.Cancel(ctx, "user-1", job.ID)
+	require.NoError(t, err)
+	assert.True(t, canceled)
+	waitStatus(t, e, job.ID, StatusCanceled)
+
+	// A finished job is deleted.
+	canceled, err = e.Cancel(ctx, "user-1", job.ID)
+	require.NoError(t, err)
+	assert.False(t, canceled)
+	_, err = e.Job(ctx, "user-1", job.ID)
+	assert.ErrorIs(t, err, errJobNotFound)
+
+	exists, err := bkt.Exists(ctx, path.Join("user-1", jobPath(job.ID)))
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestExporter_JobsOfOtherQueriers(t *testing.T) {
+	e, bkt := prepareExporter(t, mockQueryable(false), defaultLimits())
+	userBkt := bucket.NewUserBucketClient("user-1", bkt, nil)
+	ctx := context.Background()
+
+	now := time.Now()
+	interrupted := &Job{ID: "interrupted", Status: StatusRunning, CreatedAt: now.Add(-time.Hour), UpdatedAt: now.Add(-time.Hour)}
+	running := &Job{ID: "running", Status: StatusRunning, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, writeJob(ctx, userBkt, interrupted))
+	require.NoError(t, writeJob(ctx, userBkt, running))
+
+	jobs, err := e.Jobs(ctx, "user-1")
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "interrupted", jobs[0].ID)
+	assert.Equal(t, StatusFailed, jobs[0].Status)
+	assert.Equal(t, "the export job has been interrupted, its state hasn't been updated for more than 5m0s", jobs[0].Error)
+	assert.Equal(t, "running", jobs[1].ID)
+	assert.Equal(t, StatusRunning, jobs[1].Status)
+
+	// The interrupted jobs aren't counted in the concurrent jobs.
+	matchers, err := parser.ParseMetricSelector(`up`)
+	require.NoError(t, err)
+	_, err = e.Submit(ctx, "user-1", `up`, matchers, time.Unix(0, 0), time.Unix(3600, 0), FormatOpenMetrics)
+	require.EqualError(t, err, "the tenant has 1 pending or running export jobs, which is the limit")
+
+	// The job run by another querier is canceled through its stored state.
+	canceled, err := e.Cancel(ctx, "user-1", "running")
+	require.NoError(t, err)
+	assert.True(t, canceled)
+	stored, err := readJob(ctx, userBkt, "running")
+	require.NoError(t, err)
+	assert.Equal(t, StatusCanceled, stored.Status)
+
+	// The interrupted job is deleted.
+	canceled, err = e.Cancel(ctx, "user-1", "interrupted")
+	require.NoError(t, err)
+	assert.False(t, canceled)
+	_, err = readJob(ctx, userBkt, "interrupted")
+	assert.ErrorIs(t, err, errJobNotFound)
+}
+
+func fileNames(files []File) []string {
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	return names
+}
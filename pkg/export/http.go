@@ -0,0 +1,215 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/users"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+const (
+	statusSuccess = "success"
+	statusError   = "error"
+)
+
+type response struct {
+	Status string `json:"status"`
+	Data   any    `json:"data,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+	util.WriteJSONResponse(w, response{Status: statusError, Error: err.Error()})
+}
+
+func writeSuccess(w http.ResponseWriter, status int, data any) {
+	w.WriteHeader(status)
+	util.WriteJSONResponse(w, response{Status: statusSuccess, Data: data})
+}
+
+func writeJobError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errJobNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
+
+// SubmitHandler submits an export job of the series matching the selector between the start
+// and end times.
+func (e *Exporter) SubmitHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := users.TenantID(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	selector := r.FormValue("selector")
+	matchers, err := parser.ParseMetricSelector(selector)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid selector %q: %w", selector, err))
+		return
+	}
+
+	start, err := parseTime(r.FormValue("start"), "start")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	end, err := parseTime(r.FormValue("end"), "end")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if end.Before(start) {
+		writeError(w, http.StatusBadRequest, errors.New("the end time must be after the start time"))
+		return
+	}
+	if maxTimeRange := e.limits.ExportMaxTimeRange(userID); maxTimeRange > 0 && end.Sub(start) > maxTimeRange {
+		writeError(w, http.StatusBadRequest, validation.LimitError(fmt.Sprintf("the export time range (%s) exceeds the limit (%s)", end.Sub(start), maxTimeRange)))
+		return
+	}
+
+	format := Format(r.FormValue("format"))
+	switch format {
+	case "":
+		format = FormatOpenMetrics
+	case FormatOpenMetrics, FormatParquet:
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported format %q, supported formats are %s and %s", format, FormatOpenMetrics, FormatParquet))
+		return
+	}
+
+	job, err := e.Submit(r.Context(), userID, selector, matchers, start, end, format)
+	if err != nil {
+		if errors.As(err, new(validation.LimitError)) {
+			writeError(w, http.StatusTooManyRequests, err)
+			return
+		}
+		level.Error(e.logger).Log("msg", "failed to submit the export job", "user", userID, "err", err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeSuccess(w, http.StatusAccepted, job)
+}
+
+func parseTime(value, name string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("the %s time is required", name)
+	}
+	ms, err := util.ParseTime(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s time %q, expected a unix timestamp in seconds or a RFC3339 date", name, value)
+	}
+	return util.TimeFromMillis(ms).UTC(), nil
+}
+
+// ListHandler lists the export jobs of the tenant.
+func (e *Exporter) ListHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := users.TenantID(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	jobs, err := e.Jobs(r.Context(), userID)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	writeSuccess(w, http.StatusOK, jobs)
+}
+
+// StatusHandler returns the state and progress of an export job.
+func (e *Exporter) StatusHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := users.TenantID(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	job, err := e.Job(r.Context(), userID, mux.Vars(r)["id"])
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	writeSuccess(w, http.StatusOK, job)
+}
+
+// DeleteHandler cancels an export job if it's pending or running, and otherwise deletes the
+// job and its files.
+func (e *Exporter) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := users.TenantID(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	canceled, err := e.Cancel(r.Context(), userID, mux.Vars(r)["id"])
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	if canceled {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// FileHandler downloads a file written by an export job.
+func (e *Exporter) FileHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := users.TenantID(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	vars := mux.Vars(r)
+	job, err := e.Job(r.Context(), userID, vars["id"])
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+
+	// Only the files of the job can be downloaded.
+	var file *File
+	for i := range job.Files {
+		if job.Files[i].Name == vars["file"] {
+			file = &job.Files[i]
+			break
+		}
+	}
+	if file == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("file %q not found in the export job", vars["file"]))
+		return
+	}
+
+	userBkt := e.userBucket(userID)
+	reader, err := userBkt.Get(r.Context(), path.Join(filesDir(job.ID), file.Name))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer reader.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(file.Size, 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(file.Name)))
+	if _, err := io.Copy(w, reader); err != nil {
+		level.Warn(e.logger).Log("msg", "failed to download the export file", "user", userID, "job", job.ID, "file", file.Name, "err", err)
+	}
+}
@@ -0,0 +1,158 @@
+package export
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+)
+
+func newRouter(e *Exporter) *mux.Router {
+	router := mux.NewRouter()
+	router.Path("/api/v1/exports").Methods(http.MethodPost).HandlerFunc(e.SubmitHandler)
+	router.Path("/api/v1/exports").Methods(http.MethodGet).HandlerFunc(e.ListHandler)
+	router.Path("/api/v1/exports/{id}").Methods(http.MethodGet).HandlerFunc(e.StatusHandler)
+	router.Path("/api/v1/exports/{id}").Methods(http.MethodDelete).HandlerFunc(e.DeleteHandler)
+	router.Path("/api/v1/exports/{id}/files/{file:.+}").Methods(http.MethodGet).HandlerFunc(e.FileHandler)
+	return router
+}
+
+func doRequest(router http.Handler, method, target string, params url.Values) *httptest.ResponseRecorder {
+	var body io.Reader
+	if params != nil {
+		body = strings.NewReader(params.Encode())
+	}
+	req := httptest.NewRequest(method, target, body)
+	if params != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	req = req.WithContext(user.InjectOrgID(req.Context(), "user-1"))
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestSubmitHandler_Validation(t *testing.T) {
+	limits := defaultLimits()
+	limits.ExportMaxTimeRange = model.Duration(24 * time.Hour)
+	e, _ := prepareExporter(t, mockQueryable(false), limits)
+	router := newRouter(e)
+
+	tests := map[string]struct {
+		params        url.Values
+		expectedError string
+	}{
+		"invalid selector": {
+			params:        url.Values{"selector": {"up{"}, "start": {"0"}, "end": {"3600"}},
+			expectedError: `invalid selector "up{": 1:4: parse error: unexpected end of input inside braces`,
+		},
+		"missing start": {
+			params:        url.Values{"selector": {"up"}, "end": {"3600"}},
+			expectedError: "the start time is required",
+		},
+		"invalid end": {
+			params:        url.Values{"selector": {"up"}, "start": {"0"}, "end": {"tomorrow"}},
+			expectedError: `invalid end time "tomorrow", expected a unix timestamp in seconds or a RFC3339 date`,
+		},
+		"end before start": {
+			params:        url.Values{"selector": {"up"}, "start": {"3600"}, "end": {"0"}},
+			expectedError: "the end time must be after the start time",
+		},
+		"time range exceeding the limit": {
+			params:        url.Values{"selector": {"up"}, "start": {"2024-01-01T00:00:00Z"}, "end": {"2024-01-03T00:00:00Z"}},
+			expectedError: "the export time range (48h0m0s) exceeds the limit (24h0m0s)",
+		},
+		"unsupported format": {
+			params:        url.Values{"selector": {"up"}, "start": {"0"}, "end": {"3600"}, "format": {"csv"}},
+			expectedError: `unsupported format "csv", supported formats are openmetrics and parquet`,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := doRequest(router, http.MethodPost, "/api/v1/exports", testData.params)
+			require.Equal(t, http.StatusBadRequest, resp.Code, resp.Body.String())
+
+			res := response{}
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+			assert.Equal(t, statusError, res.Status)
+			assert.Equal(t, testData.expectedError, res.Error)
+		})
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	e, _ := prepareExporter(t, mockQueryable(false, newSeries(labels.FromStrings(labels.MetricName, "up", "job", "api"))), defaultLimits())
+	router := newRouter(e)
+
+	// Submit the job.
+	resp := doRequest(router, http.MethodPost, "/api/v1/exports", url.Values{"selector": {`up{job="api"}`}, "start": {"0"}, "end": {"3600"}})
+	require.Equal(t, http.StatusAccepted, resp.Code, resp.Body.String())
+	submitted := decodeJob(t, resp)
+	assert.Equal(t, `up{job="api"}`, submitted.Selector)
+	assert.Equal(t, FormatOpenMetrics, submitted.Format)
+	assert.Equal(t, StatusPending, submitted.Status)
+
+	// Wait until it's completed.
+	waitStatus(t, e, submitted.ID, StatusCompleted)
+	resp = doRequest(router, http.MethodGet, "/api/v1/exports/"+submitted.ID, nil)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	job := decodeJob(t, resp)
+	assert.Equal(t, StatusCompleted, job.Status)
+	require.Len(t, job.Files, 1)
+
+	// List the jobs.
+	resp = doRequest(router, http.MethodGet, "/api/v1/exports", nil)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	list := struct {
+		Data []*Job `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, submitted.ID, list.Data[0].ID)
+
+	// Download the file.
+	resp = doRequest(router, http.MethodGet, "/api/v1/exports/"+submitted.ID+"/files/part-00000.om.gz", nil)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Equal(t, `attachment; filename="part-00000.om.gz"`, resp.Header().Get("Content-Disposition"))
+	gr, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	content, err := io.ReadAll(gr)
+	require.NoError(t, err)
+	assert.Equal(t, "# TYPE up unknown\nup{job=\"api\"} 0 0\nup{job=\"api\"} 30 1800\nup{job=\"api\"} 60 3600\n# EOF\n", string(content))
+
+	// Only the files of the job can be downloaded.
+	resp = doRequest(router, http.MethodGet, "/api/v1/exports/"+submitted.ID+"/files/job.json", nil)
+	require.Equal(t, http.StatusNotFound, resp.Code, resp.Body.String())
+
+	// Delete the job.
+	resp = doRequest(router, http.MethodDelete, "/api/v1/exports/"+submitted.ID, nil)
+	require.Equal(t, http.StatusNoContent, resp.Code, resp.Body.String())
+	resp = doRequest(router, http.MethodGet, "/api/v1/exports/"+submitted.ID, nil)
+	require.Equal(t, http.StatusNotFound, resp.Code, resp.Body.String())
+	resp = doRequest(router, http.MethodDelete, "/api/v1/exports/"+submitted.ID, nil)
+	require.Equal(t, http.StatusNotFound, resp.Code, resp.Body.String())
+}
+
+func decodeJob(t *testing.T, resp *httptest.ResponseRecorder) *Job {
+	res := struct {
+		Status string `json:"status"`
+		Data   *Job   `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+	require.Equal(t, statusSuccess, res.Status)
+	return res.Data
+}
@@ -0,0 +1,210 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/thanos-io/objstore"
+)
+
+const (
+	// ExportsPrefix is the prefix of the exports in the bucket of a tenant.
+	ExportsPrefix = "exports"
+
+	// JobFilename is the name of the file storing the state of an export job.
+	JobFilename = "job.json"
+
+	// FilesPrefix is the prefix of the exported files in the directory of an export job.
+	FilesPrefix = "files"
+)
+
+// Format is the format of the exported files.
+type Format string
+
+const (
+	// FormatOpenMetrics exports the samples as gzipped OpenMetrics text.
+	FormatOpenMetrics Format = "openmetrics"
+
+	// FormatParquet exports the series with the schema of the parquet converter.
+	FormatParquet Format = "parquet"
+)
+
+// Status is the status of an export job.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+)
+
+var errJobNotFound = errors.New("export job not found")
+
+// Job is the state of an export job, which is stored in the bucket of the tenant so that
+// it can be read by any querier.
+type Job struct {
+	ID        string    `json:"id"`
+	Selector  string    `json:"selector"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Format    Format    `json:"format"`
+	Status    Status    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Progress  Progress  `json:"progress"`
+	Files     []File    `json:"files,omitempty"`
+
+	// AccessPolicy is the query access policy of the request which submitted the job.
+	AccessPolicy string `json:"access_policy,omitempty"`
+}
+
+// Progress is the progress of an export job.
+type Progress struct {
+	// ExportedUntil is the end of the time range exported so far.
+	ExportedUntil time.Time `json:"exported_until"`
+	Percent       float64   `json:"percent"`
+
+	// Series is the number of series exported, counted once for each split interval.
+	Series  int64 `json:"series"`
+	Samples int64 `json:"samples"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// File is a file written by an export job.
+type File struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// Active returns true if the job is pending or running.
+func (j *Job) Active() bool {
+	return j.Status == StatusPending || j.Status == StatusRunning
+}
+
+// updateProgress updates the progress of the job once the series until the input time have
+// been exported.
+func (j *Job) updateProgress(exportedUntil time.Time) {
+	j.Progress.ExportedUntil = exportedUntil
+	if total := j.End.Sub(j.Start); total > 0 {
+		j.Progress.Percent = float64(exportedUntil.Sub(j.Start)) / float64(total) * 100
+	} else {
+		j.Progress.Percent = 100
+	}
+}
+
+func jobPath(jobID string) string {
+	return path.Join(ExportsPrefix, jobID, JobFilename)
+}
+
+func jobDir(jobID string) string {
+	return path.Join(ExportsPrefix, jobID)
+}
+
+func filesDir(jobID string) string {
+	return path.Join(ExportsPrefix, jobID, FilesPrefix)
+}
+
+// readJob reads the job from the bucket of the tenant.
+func readJob(ctx context.Context, userBkt objstore.Bucket, jobID string) (*Job, error) {
+	r, err := userBkt.Get(ctx, jobPath(jobID))
+	if userBkt.IsObjNotFoundErr(err) {
+		return nil, errJobNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "read export job %s", jobID)
+	}
+	defer r.Close()
+
+	job := &Job{}
+	if err := json.NewDecoder(r).Decode(job); err != nil {
+		return nil, errors.Wrapf(err, "decode export job %s", jobID)
+	}
+	return job, nil
+}
+
+// writeJob writes the job to the bucket of the tenant.
+func writeJob(ctx context.Context, userBkt objstore.Bucket, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return errors.Wrapf(err, "encode export job %s", job.ID)
+	}
+	return errors.Wrapf(userBkt.Upload(ctx, jobPath(job.ID), bytes.NewReader(data)), "write export job %s", job.ID)
+}
+
+// listJobs returns the jobs of the tenant, sorted by creation time.
+func listJobs(ctx context.Context, userBkt objstore.Bucket) ([]*Job, error) {
+	var ids []string
+	err := userBkt.Iter(ctx, ExportsPrefix, func(name string) error {
+		ids = append(ids, path.Base(strings.TrimSuffix(name, objstore.DirDelim)))
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list export jobs")
+	}
+
+	jobs := make([]*Job, 0, len(ids))
+	for _, id := range ids {
+		job, err := readJob(ctx, userBkt, id)
+		if errors.Is(err, errJobNotFound) {
+			// The job is being deleted.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs, nil
+}
+
+// deleteFiles deletes the files exported by the job.
+func deleteFiles(ctx context.Context, userBkt objstore.Bucket, jobID string) error {
+	return errors.Wrapf(deleteDir(ctx, userBkt, filesDir(jobID)), "delete the files of export job %s", jobID)
+}
+
+// deleteJob deletes the job and its files. The job file is deleted last, so that a job
+// partially deleted is still listed.
+func deleteJob(ctx context.Context, userBkt objstore.Bucket, jobID string) error {
+	if err := deleteFiles(ctx, userBkt, jobID); err != nil {
+		return err
+	}
+	if err := deleteDir(ctx, userBkt, jobDir(jobID)); err != nil {
+		return errors.Wrapf(err, "delete export job %s", jobID)
+	}
+	return nil
+}
+
+func deleteDir(ctx context.Context, bkt objstore.Bucket, dir string) error {
+	var names []string
+	err := bkt.Iter(ctx, dir, func(name string) error {
+		names = append(names, name)
+		return nil
+	}, objstore.WithRecursiveIter())
+	if err != nil {
+		return err
+	}
+
+	// Delete the job file last.
+	sort.Slice(names, func(i, j int) bool {
+		return path.Base(names[j]) == JobFilename && path.Base(names[i]) != JobFilename
+	})
+	for _, name := range names {
+		if err := bkt.Delete(ctx, name); err != nil && !bkt.IsObjNotFoundErr(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,318 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+	"github.com/prometheus-community/parquet-common/convert"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/thanos-io/objstore"
+
+	util_log "github.com/cortexproject/cortex/pkg/util/log"
+)
+
+// errBytesLimitExceeded is returned when an export job writes more bytes than allowed.
+type errBytesLimitExceeded int64
+
+func (e errBytesLimitExceeded) Error() string {
+	return fmt.Sprintf("the export exceeds the limit of %d bytes", int64(e))
+}
+
+// splitStats are the stats of the series exported for a split interval.
+type splitStats struct {
+	series  int64
+	samples int64
+}
+
+// writeOpenMetrics writes the float samples of the series of the sorted set in the OpenMetrics
+// text format, streaming them. The native histograms can't be represented in this format and are
+// skipped. The series are grouped by metric name, as required by the format: the series of a
+// metric are contiguous in the sorted set, except the ones with a label sorting before the metric
+// name, which come first in the set and are kept until their metric is written.
+func writeOpenMetrics(w io.Writer, set storage.SeriesSet) (splitStats, error) {
+	stats := splitStats{}
+	bw := bufio.NewWriter(w)
+
+	var (
+		it      chunkenc.Iterator
+		pending = map[string][]storage.Series{}
+		current string
+		started bool
+	)
+	writeSeries := func(s storage.Series) error {
+		series := formatSeries(s.Labels())
+		written := false
+
+		it = s.Iterator(it)
+		for vt := it.Next(); vt != chunkenc.ValNone; vt = it.Next() {
+			if vt != chunkenc.ValFloat {
+				continue
+			}
+			t, v := it.At()
+			if _, err := fmt.Fprintf(bw, "%s %s %s\n", series, formatValue(v), formatTimestamp(t)); err != nil {
+				return err
+			}
+			stats.samples++
+			written = true
+		}
+		if written {
+			stats.series++
+		}
+		return it.Err()
+	}
+	// startFamily writes the metrics pending before the input one, and starts the input one.
+	startFamily := func(name string, last bool) error {
+		names := make([]string, 0, len(pending))
+		for n := range pending {
+			if last || n < name {
+				names = append(names, n)
+			}
+		}
+		sort.Strings(names)
+		if !last {
+			names = append(names, name)
+		}
+
+		for _, n := range names {
+			if _, err := fmt.Fprintf(bw, "# TYPE %s unknown\n", n); err != nil {
+				return err
+			}
+			for _, s := range pending[n] {
+				if err := writeSeries(s); err != nil {
+					return err
+				}
+			}
+			delete(pending, n)
+		}
+		return nil
+	}
+
+	for set.Next() {
+		s := set.At()
+		lbls := s.Labels()
+		name := lbls.Get(labels.MetricName)
+
+		if sortsBeforeMetricName(lbls) {
+			pending[name] = append(pending[name], s)
+			continue
+		}
+		if !started || name != current {
+			if err := startFamily(name, false); err != nil {
+				return stats, err
+			}
+			current, started = name, true
+		}
+		if err := writeSeries(s); err != nil {
+			return stats, err
+		}
+	}
+	if err := set.Err(); err != nil {
+		return stats, err
+	}
+	if err := startFamily("", true); err != nil {
+		return stats, err
+	}
+
+	if _, err := bw.WriteString("# EOF\n"); err != nil {
+		return stats, err
+	}
+	return stats, bw.Flush()
+}
+
+// sortsBeforeMetricName returns whether the series has a label sorting before the metric name,
+// or no metric name at all.
+func sortsBeforeMetricName(lbls labels.Labels) bool {
+	first := ""
+	lbls.Range(func(l labels.Label) {
+		if first == "" {
+			first = l.Name
+		}
+	})
+	return first != labels.MetricName
+}
+
+// peekedSeriesSet is a series set whose first Next has already been called, and returned true.
+type peekedSeriesSet struct {
+	storage.SeriesSet
+	peeked bool
+}
+
+func (s *peekedSeriesSet) Next() bool {
+	if s.peeked {
+		s.peeked = false
+		return true
+	}
+	return s.SeriesSet.Next()
+}
+
+func formatSeries(lbls labels.Labels) string {
+	b := strings.Builder{}
+	b.WriteString(lbls.Get(labels.MetricName))
+
+	first := true
+	lbls.Range(func(l labels.Label) {
+		if l.Name == labels.MetricName {
+			return
+		}
+		if first {
+			b.WriteByte('{')
+			first = false
+		} else {
+			b.WriteByte(',')
+		}
+		b.WriteString(l.Name)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabelValue(l.Value))
+		b.WriteByte('"')
+	})
+	if !first {
+		b.WriteByte('}')
+	}
+	return b.String()
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueReplacer.Replace(v)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// formatTimestamp formats a timestamp in milliseconds to seconds, as required by the format.
+func formatTimestamp(t int64) string {
+	return strconv.FormatFloat(float64(t)/1000, 'f', -1, 64)
+}
+
+// limitedWriter counts the bytes written and fails once the limit, if greater than 0, is exceeded.
+type limitedWriter struct {
+	w       io.Writer
+	written int64
+	limit   int64
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.limit > 0 && w.written+int64(len(p)) > w.limit {
+		return 0, errBytesLimitExceeded(w.limit)
+	}
+	n, err := w.w.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
+// parquetWriter writes the series to Parquet files with the schema of the parquet converter.
+// The series are first appended to a temporary TSDB head, which is then converted.
+type parquetWriter struct {
+	dir         string
+	sortColumns []string
+	logger      log.Logger
+}
+
+// write writes the series of the set, whose samples are in the [mint, maxt] range, to the bucket
+// under the input name. It returns false if there were no samples to write.
+func (w *parquetWriter) write(ctx context.Context, bkt objstore.Bucket, name string, set storage.SeriesSet, mint, maxt int64) (splitStats, bool, error) {
+	stats := splitStats{}
+
+	dir, err := os.MkdirTemp(w.dir, "head-")
+	if err != nil {
+		return stats, false, errors.Wrap(err, "create head directory")
+	}
+	defer os.RemoveAll(dir)
+
+	opts := tsdb.DefaultHeadOptions()
+	opts.ChunkDirRoot = filepath.Join(dir, "chunks")
+	// The head only accepts the samples newer than its max time minus half the chunk range,
+	// so that the range is doubled to accept the samples of any series.
+	opts.ChunkRange = 2 * (maxt - mint + 1)
+	opts.EnableNativeHistograms.Store(true)
+	head, err := tsdb.NewHead(nil, util_log.GoKitLogToSlog(w.logger), nil, nil, opts, nil)
+	if err != nil {
+		return stats, false, errors.Wrap(err, "create head")
+	}
+	defer head.Close()
+
+	var it chunkenc.Iterator
+	for set.Next() {
+		s := set.At()
+		app := head.Appender(ctx)
+		ref := storage.SeriesRef(0)
+		samples := int64(0)
+
+		it = s.Iterator(it)
+		for vt := it.Next(); vt != chunkenc.ValNone; vt = it.Next() {
+			switch vt {
+			case chunkenc.ValFloat:
+				t, v := it.At()
+				ref, err = app.Append(ref, s.Labels(), t, v)
+			case chunkenc.ValHistogram:
+				t, h := it.AtHistogram(nil)
+				ref, err = app.AppendHistogram(ref, s.Labels(), t, h, nil)
+			case chunkenc.ValFloatHistogram:
+				t, fh := it.AtFloatHistogram(nil)
+				ref, err = app.AppendHistogram(ref, s.Labels(), t, nil, fh)
+			}
+			if err != nil {
+				_ = app.Rollback()
+				return stats, false, errors.Wrap(err, "append sample")
+			}
+			samples++
+		}
+		if err := it.Err(); err != nil {
+			_ = app.Rollback()
+			return stats, false, err
+		}
+		if err := app.Commit(); err != nil {
+			return stats, false, errors.Wrap(err, "commit samples")
+		}
+
+		if samples > 0 {
+			stats.series++
+			stats.samples += samples
+		}
+	}
+	if err := set.Err(); err != nil {
+		return stats, false, err
+	}
+	if stats.samples == 0 {
+		return stats, false, nil
+	}
+
+	_, err = convert.ConvertTSDBBlock(
+		ctx,
+		bkt,
+		mint,
+		maxt+1,
+		[]convert.Convertible{head},
+		util_log.GoKitLogToSlog(w.logger),
+		convert.WithName(name),
+		convert.WithColDuration(defaultColDuration),
+		convert.WithSortBy(w.sortColumns...),
+	)
+	if err != nil {
+		return stats, false, errors.Wrap(err, "convert series to parquet")
+	}
+	return stats, true, nil
+}
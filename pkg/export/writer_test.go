@@ -0,0 +1,89 @@
+package export
+
+import (
+	"bytes"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/prometheus/prometheus/tsdb/tsdbutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteOpenMetrics(t *testing.T) {
+	series := []storage.Series{
+		storage.NewListSeries(labels.FromStrings(labels.MetricName, "up", "job", "api"), []chunks.Sample{
+			sample{t: 1000, f: 1}, sample{t: 2500, f: 0},
+		}),
+		// The series of a metric aren't contiguous in the sorted set when their labels sort before the metric name.
+		storage.NewListSeries(labels.FromStrings(labels.MetricName, "http_requests_total", "Host", "a"), []chunks.Sample{
+			sample{t: 1000, f: math.NaN()}, sample{t: 2000, f: math.Inf(1)},
+		}),
+		storage.NewListSeries(labels.FromStrings(labels.MetricName, "up", "job", "quoted \"db\"\nwith \\ slash"), []chunks.Sample{
+			sample{t: 1000, f: 1e-20},
+		}),
+		storage.NewListSeries(labels.FromStrings(labels.MetricName, "http_requests_total", "code", "200"), []chunks.Sample{
+			sample{t: 1000, f: 10},
+		}),
+		// The native histograms can't be represented in the format.
+		storage.NewListSeries(labels.FromStrings(labels.MetricName, "latency"), []chunks.Sample{
+			sample{t: 1000, h: tsdbutil.GenerateTestHistogram(1)},
+		}),
+	}
+
+	// The series are sorted, as returned by the queriers.
+	slices.SortFunc(series, func(a, b storage.Series) int { return labels.Compare(a.Labels(), b.Labels()) })
+
+	buf := &bytes.Buffer{}
+	stats, err := writeOpenMetrics(buf, &seriesSet{series: series, i: -1})
+	require.NoError(t, err)
+
+	assert.Equal(t, `# TYPE http_requests_total unknown
+http_requests_total{Host="a"} NaN 1
+http_requests_total{Host="a"} +Inf 2
+http_requests_total{code="200"} 10 1
+# TYPE latency unknown
+# TYPE up unknown
+up{job="api"} 1 1
+up{job="api"} 0 2.5
+up{job="quoted \"db\"\nwith \\ slash"} 1e-20 1
+# EOF
+`, buf.String())
+	assert.Equal(t, splitStats{series: 4, samples: 6}, stats)
+}
+
+func TestLimitedWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := &limitedWriter{w: buf, limit: 5}
+
+	_, err := w.Write([]byte("abc"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("def"))
+	require.EqualError(t, err, "the export exceeds the limit of 5 bytes")
+	assert.Equal(t, int64(3), w.written)
+	assert.Equal(t, "abc", buf.String())
+}
+
+type sample struct {
+	t int64
+	f float64
+	h *histogram.Histogram
+}
+
+func (s sample) T() int64                      { return s.t }
+func (s sample) F() float64                    { return s.f }
+func (s sample) H() *histogram.Histogram       { return s.h }
+func (s sample) FH() *histogram.FloatHistogram { return nil }
+func (s sample) Type() chunkenc.ValueType {
+	if s.h != nil {
+		return chunkenc.ValHistogram
+	}
+	return chunkenc.ValFloat
+}
+func (s sample) Copy() chunks.Sample { return s }
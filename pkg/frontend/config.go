@@ -2,7 +2,9 @@ package frontend
 
 import (
 	"flag"
+	"net"
 	"net/http"
+	"strconv"
 
 	"github.com/go-kit/log"
 	"github.com/pkg/errors"
@@ -21,12 +23,15 @@ type CombinedFrontendConfig struct {
 	FrontendV2 v2.Config               `yaml:",inline"`
 
 	DownstreamURL string `yaml:"downstream_url"`
+
+	Ring RingConfig `yaml:"ring" doc:"description=The query-frontends hash ring configuration. This option is required only if the global query rate strategy is used."`
 }
 
 func (cfg *CombinedFrontendConfig) RegisterFlags(f *flag.FlagSet) {
 	cfg.Handler.RegisterFlags(f)
 	cfg.FrontendV1.RegisterFlags(f)
 	cfg.FrontendV2.RegisterFlags(f)
+	cfg.Ring.RegisterFlags(f)
 
 	f.StringVar(&cfg.DownstreamURL, "frontend.downstream-url", "", "URL of downstream Prometheus.")
 }
@@ -46,18 +51,12 @@ func InitFrontend(cfg CombinedFrontendConfig, limits v1.Limits, grpcListenPort i
 
 	case cfg.FrontendV2.SchedulerAddress != "":
 		// If query-scheduler address is configured, use Frontend.
-		if cfg.FrontendV2.Addr == "" {
-			addr, err := util.GetFirstAddressOf(cfg.FrontendV2.InfNames)
-			if err != nil {
-				return nil, nil, nil, errors.Wrap(err, "failed to get frontend address")
-			}
-
-			cfg.FrontendV2.Addr = addr
-		}
-
-		if cfg.FrontendV2.Port == 0 {
-			cfg.FrontendV2.Port = grpcListenPort
+		addr, port, err := advertisedAddress(cfg, grpcListenPort)
+		if err != nil {
+			return nil, nil, nil, err
 		}
+		cfg.FrontendV2.Addr = addr
+		cfg.FrontendV2.Port = port
 
 		fr, err := v2.NewFrontend(cfg.FrontendV2, limits, log, reg, retry)
 		return transport.AdaptGrpcRoundTripperToHTTPRoundTripper(fr), nil, fr, err
@@ -71,3 +70,29 @@ func InitFrontend(cfg CombinedFrontendConfig, limits v1.Limits, grpcListenPort i
 		return transport.AdaptGrpcRoundTripperToHTTPRoundTripper(fr), fr, nil, nil
 	}
 }
+
+// AdvertisedAddress returns the gRPC address the query-frontend advertises to the other components.
+func AdvertisedAddress(cfg CombinedFrontendConfig, grpcListenPort int) (string, error) {
+	addr, port, err := advertisedAddress(cfg, grpcListenPort)
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(addr, strconv.Itoa(port)), nil
+}
+
+func advertisedAddress(cfg CombinedFrontendConfig, grpcListenPort int) (string, int, error) {
+	addr := cfg.FrontendV2.Addr
+	if addr == "" {
+		var err error
+		addr, err = util.GetFirstAddressOf(cfg.FrontendV2.InfNames)
+		if err != nil {
+			return "", 0, errors.Wrap(err, "failed to get frontend address")
+		}
+	}
+
+	port := cfg.FrontendV2.Port
+	if port == 0 {
+		port = grpcListenPort
+	}
+	return addr, port, nil
+}
@@ -28,6 +28,7 @@ import (
 	querier_stats "github.com/cortexproject/cortex/pkg/querier/stats"
 	"github.com/cortexproject/cortex/pkg/querier/tenantfederation"
 	"github.com/cortexproject/cortex/pkg/querier/tripperware"
+	"github.com/cortexproject/cortex/pkg/usage"
 	"github.com/cortexproject/cortex/pkg/util"
 	util_api "github.com/cortexproject/cortex/pkg/util/api"
 	"github.com/cortexproject/cortex/pkg/util/limiter"
@@ -87,6 +88,9 @@ type HandlerConfig struct {
 	MaxBodySize               int64         `yaml:"max_body_size"`
 	QueryStatsEnabled         bool          `yaml:"query_stats_enabled"`
 	EnabledRulerQueryStatsLog bool          `yaml:"enabled_ruler_query_stats_log"`
+
+	// UsageTracker tracks the bytes fetched by the queries of each tenant, if the usage tracking is enabled.
+	UsageTracker *usage.Tracker `yaml:"-"`
 }
 
 func (cfg *HandlerConfig) RegisterFlags(f *flag.FlagSet) {
@@ -344,6 +348,7 @@ func (f *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 
 		f.reportQueryStats(r, source, userID, queryString, queryResponseTime, stats, err, statusCode, resp)
+		f.trackQueriedBytes(tenantIDs, stats)
 	}
 
 	hs := w.Header()
@@ -438,6 +443,19 @@ func (f *Handler) reportSlowQuery(r *http.Request, queryString url.Values, query
 	level.Info(util_log.WithContext(r.Context(), f.log)).Log(logMessage...)
 }
 
+// trackQueriedBytes adds the bytes fetched by the query to the usage of its tenants. The bytes
+// fetched by a federated query are split evenly across the tenants.
+func (f *Handler) trackQueriedBytes(tenantIDs []string, stats *querier_stats.QueryStats) {
+	if f.cfg.UsageTracker == nil {
+		return
+	}
+
+	fetchedBytes := stats.LoadFetchedDataBytes() / uint64(len(tenantIDs))
+	for _, userID := range tenantIDs {
+		f.cfg.UsageTracker.AddQueriedBytes(userID, fetchedBytes)
+	}
+}
+
 func (f *Handler) reportQueryStats(r *http.Request, source, userID string, queryString url.Values, queryResponseTime time.Duration, stats *querier_stats.QueryStats, error error, statusCode int, resp *http.Response) {
 	wallTime := stats.LoadWallTime()
 	queryStorageWallTime := stats.LoadQueryStorageWallTime()
@@ -28,10 +28,14 @@ import (
 	"github.com/cortexproject/cortex/pkg/querier"
 	querier_stats "github.com/cortexproject/cortex/pkg/querier/stats"
 	"github.com/cortexproject/cortex/pkg/querier/tenantfederation"
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/usage"
 	util_api "github.com/cortexproject/cortex/pkg/util/api"
 	"github.com/cortexproject/cortex/pkg/util/limiter"
 	util_log "github.com/cortexproject/cortex/pkg/util/log"
 	"github.com/cortexproject/cortex/pkg/util/requestmeta"
+	"github.com/cortexproject/cortex/pkg/util/services"
 	"github.com/cortexproject/cortex/pkg/util/users"
 )
 
@@ -879,3 +883,42 @@ func TestHandler_RemoteReadRequest_DoesNotParseQueryString(t *testing.T) {
 	// Verify that the request body is still readable (not replaced with empty buffer)
 	require.NotEmpty(t, string(bodyBytes))
 }
+
+func TestHandler_UsageTracking(t *testing.T) {
+	users.WithDefaultResolver(users.NewMultiResolver())
+	t.Cleanup(func() { users.WithDefaultResolver(users.NewSingleResolver()) })
+
+	storageCfg := cortex_tsdb.BlocksStorageConfig{Bucket: bucket.Config{Backend: bucket.Filesystem}}
+	storageCfg.Bucket.Filesystem.Directory = t.TempDir()
+	tracker, err := usage.NewTracker(usage.Config{Enabled: true, FlushPeriod: time.Hour, InstanceID: "frontend-1"}, storageCfg, nil, log.NewNopLogger(), nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), tracker))
+
+	roundTripper := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		querier_stats.FromContext(req.Context()).AddFetchedDataBytes(1000)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("{}")),
+		}, nil
+	})
+	handler := NewHandler(HandlerConfig{QueryStatsEnabled: true, UsageTracker: tracker}, tenantfederation.Config{}, roundTripper, log.NewNopLogger(), nil)
+
+	// The bytes fetched by a federated query are split across its tenants.
+	for _, orgID := range []string{"org1", "org1|org2"} {
+		req := httptest.NewRequest("GET", "http://fake", nil)
+		req.Header.Set("X-Scope-OrgId", orgID)
+		resp := httptest.NewRecorder()
+		middleware.Merge(middleware.AuthenticateUser).Wrap(handler).ServeHTTP(resp, req)
+		require.Equal(t, http.StatusOK, resp.Code)
+	}
+
+	// The tracked usage is written when the tracker stops.
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), tracker))
+
+	for userID, expected := range map[string]int64{"org1": 1500, "org2": 500} {
+		byDay, err := tracker.ReadUsage(context.Background(), userID, time.Now(), time.Now())
+		require.NoError(t, err)
+		require.Len(t, byDay, 1)
+		assert.Equal(t, usage.Usage{QueriedBytes: expected}, byDay[0].Usage)
+	}
+}
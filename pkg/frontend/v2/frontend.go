@@ -87,6 +87,7 @@ type frontendRequest struct {
 	request      *httpgrpc.HTTPRequest
 	userID       string
 	statsEnabled bool
+	deadline     time.Time
 
 	cancel context.CancelFunc
 
@@ -206,6 +207,10 @@ func (f *Frontend) RoundTripGRPC(ctx context.Context, req *httpgrpc.HTTPRequest)
 
 			retryOnTooManyOutstandingRequests: f.cfg.RetryOnTooManyOutstandingRequests && f.schedulerWorkers.getWorkersCount() > 1,
 		}
+		// The scheduler drops the request if it's still queued once the caller stopped waiting for it.
+		if deadline, ok := ctx.Deadline(); ok {
+			freq.deadline = deadline
+		}
 
 		f.requests.put(freq)
 		defer f.requests.delete(freq.queryID)
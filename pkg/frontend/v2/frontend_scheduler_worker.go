@@ -261,6 +261,7 @@ func (w *frontendSchedulerWorker) schedulerLoop(loop schedulerpb.SchedulerForFro
 				HttpRequest:     req.request,
 				FrontendAddress: w.frontendAddr,
 				StatsEnabled:    req.statsEnabled,
+				Deadline:        deadlineMillis(req.deadline),
 			})
 
 			if err != nil {
@@ -329,3 +330,11 @@ func (w *frontendSchedulerWorker) schedulerLoop(loop schedulerpb.SchedulerForFro
 		}
 	}
 }
+
+// deadlineMillis returns the deadline as unix timestamp in milliseconds, or 0 if there's no deadline.
+func deadlineMillis(deadline time.Time) int64 {
+	if deadline.IsZero() {
+		return 0
+	}
+	return util.TimeToMillis(deadline)
+}
@@ -218,6 +218,10 @@ func TestFrontendCancellation(t *testing.T) {
 		require.True(t, ms.msgs[0].Type == schedulerpb.ENQUEUE)
 		require.True(t, ms.msgs[1].Type == schedulerpb.CANCEL)
 		require.True(t, ms.msgs[0].QueryID == ms.msgs[1].QueryID)
+
+		// The scheduler is told when the frontend stops waiting for the request.
+		deadline, _ := ctx.Deadline()
+		require.Equal(t, deadline.UnixMilli(), ms.msgs[0].Deadline)
 	})
 }
 
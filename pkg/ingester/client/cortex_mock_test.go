@@ -81,3 +81,13 @@ func (m *IngesterServerMock) MetricsMetadata(ctx context.Context, r *MetricsMeta
 	args := m.Called(ctx, r)
 	return args.Get(0).(*MetricsMetadataResponse), args.Error(1)
 }
+
+func (m *IngesterServerMock) MetricsUsage(ctx context.Context, r *MetricsUsageRequest) (*MetricsUsageResponse, error) {
+	args := m.Called(ctx, r)
+	return args.Get(0).(*MetricsUsageResponse), args.Error(1)
+}
+
+func (m *IngesterServerMock) OutOfOrderSeriesStats(ctx context.Context, r *OutOfOrderSeriesStatsRequest) (*OutOfOrderSeriesStatsResponse, error) {
+	args := m.Called(ctx, r)
+	return args.Get(0).(*OutOfOrderSeriesStatsResponse), args.Error(1)
+}
@@ -65,3 +65,8 @@ func (m *LabelMatcher) MatcherType() (labels.MatchType, error) {
 	}
 	return t, nil
 }
+
+// Total returns the number of discarded samples of the series, whatever the reason.
+func (m *OutOfOrderSeriesStats) Total() uint64 {
+	return m.OutOfOrder + m.OutOfBounds + m.TooOld + m.DuplicateTimestamp
+}
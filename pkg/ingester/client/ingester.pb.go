@@ -1475,6 +1475,317 @@ func (m *TimeSeriesFile) GetData() []byte {
 	return nil
 }
 
+type MetricsUsageRequest struct {
+}
+
+func (m *MetricsUsageRequest) Reset()      { *m = MetricsUsageRequest{} }
+func (*MetricsUsageRequest) ProtoMessage() {}
+func (*MetricsUsageRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{27}
+}
+func (m *MetricsUsageRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MetricsUsageRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MetricsUsageRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *MetricsUsageRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MetricsUsageRequest.Merge(m, src)
+}
+func (m *MetricsUsageRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *MetricsUsageRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MetricsUsageRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MetricsUsageRequest proto.InternalMessageInfo
+
+type MetricsUsageResponse struct {
+	Metrics []MetricUsage `protobuf:"bytes,1,rep,name=metrics,proto3" json:"metrics"`
+	// The time since when the queried metrics have been tracked.
+	TrackedSinceTimestampMs int64 `protobuf:"varint,2,opt,name=tracked_since_timestamp_ms,json=trackedSinceTimestampMs,proto3" json:"tracked_since_timestamp_ms,omitempty"`
+}
+
+func (m *MetricsUsageResponse) Reset()      { *m = MetricsUsageResponse{} }
+func (*MetricsUsageResponse) ProtoMessage() {}
+func (*MetricsUsageResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{28}
+}
+func (m *MetricsUsageResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MetricsUsageResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MetricsUsageResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *MetricsUsageResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MetricsUsageResponse.Merge(m, src)
+}
+func (m *MetricsUsageResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *MetricsUsageResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_MetricsUsageResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MetricsUsageResponse proto.InternalMessageInfo
+
+func (m *MetricsUsageResponse) GetMetrics() []MetricUsage {
+	if m != nil {
+		return m.Metrics
+	}
+	return nil
+}
+
+func (m *MetricsUsageResponse) GetTrackedSinceTimestampMs() int64 {
+	if m != nil {
+		return m.TrackedSinceTimestampMs
+	}
+	return 0
+}
+
+type MetricUsage struct {
+	MetricName string `protobuf:"bytes,1,opt,name=metric_name,json=metricName,proto3" json:"metric_name,omitempty"`
+	NumSeries  uint64 `protobuf:"varint,2,opt,name=num_series,json=numSeries,proto3" json:"num_series,omitempty"`
+	// 0 if the metric has not been queried since the tracking started.
+	LastQueriedTimestampMs int64 `protobuf:"varint,3,opt,name=last_queried_timestamp_ms,json=lastQueriedTimestampMs,proto3" json:"last_queried_timestamp_ms,omitempty"`
+}
+
+func (m *MetricUsage) Reset()      { *m = MetricUsage{} }
+func (*MetricUsage) ProtoMessage() {}
+func (*MetricUsage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{29}
+}
+func (m *MetricUsage) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MetricUsage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MetricUsage.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *MetricUsage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MetricUsage.Merge(m, src)
+}
+func (m *MetricUsage) XXX_Size() int {
+	return m.Size()
+}
+func (m *MetricUsage) XXX_DiscardUnknown() {
+	xxx_messageInfo_MetricUsage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MetricUsage proto.InternalMessageInfo
+
+func (m *MetricUsage) GetMetricName() string {
+	if m != nil {
+		return m.MetricName
+	}
+	return ""
+}
+
+func (m *MetricUsage) GetNumSeries() uint64 {
+	if m != nil {
+		return m.NumSeries
+	}
+	return 0
+}
+
+func (m *MetricUsage) GetLastQueriedTimestampMs() int64 {
+	if m != nil {
+		return m.LastQueriedTimestampMs
+	}
+	return 0
+}
+
+type OutOfOrderSeriesStatsRequest struct {
+}
+
+func (m *OutOfOrderSeriesStatsRequest) Reset()      { *m = OutOfOrderSeriesStatsRequest{} }
+func (*OutOfOrderSeriesStatsRequest) ProtoMessage() {}
+func (*OutOfOrderSeriesStatsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{30}
+}
+func (m *OutOfOrderSeriesStatsRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *OutOfOrderSeriesStatsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_OutOfOrderSeriesStatsRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *OutOfOrderSeriesStatsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OutOfOrderSeriesStatsRequest.Merge(m, src)
+}
+func (m *OutOfOrderSeriesStatsRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *OutOfOrderSeriesStatsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_OutOfOrderSeriesStatsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OutOfOrderSeriesStatsRequest proto.InternalMessageInfo
+
+type OutOfOrderSeriesStatsResponse struct {
+	Series []OutOfOrderSeriesStats `protobuf:"bytes,1,rep,name=series,proto3" json:"series"`
+	// The time since when the discarded samples have been tracked.
+	TrackedSinceTimestampMs int64 `protobuf:"varint,2,opt,name=tracked_since_timestamp_ms,json=trackedSinceTimestampMs,proto3" json:"tracked_since_timestamp_ms,omitempty"`
+}
+
+func (m *OutOfOrderSeriesStatsResponse) Reset()      { *m = OutOfOrderSeriesStatsResponse{} }
+func (*OutOfOrderSeriesStatsResponse) ProtoMessage() {}
+func (*OutOfOrderSeriesStatsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{31}
+}
+func (m *OutOfOrderSeriesStatsResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *OutOfOrderSeriesStatsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_OutOfOrderSeriesStatsResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *OutOfOrderSeriesStatsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OutOfOrderSeriesStatsResponse.Merge(m, src)
+}
+func (m *OutOfOrderSeriesStatsResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *OutOfOrderSeriesStatsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_OutOfOrderSeriesStatsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OutOfOrderSeriesStatsResponse proto.InternalMessageInfo
+
+func (m *OutOfOrderSeriesStatsResponse) GetSeries() []OutOfOrderSeriesStats {
+	if m != nil {
+		return m.Series
+	}
+	return nil
+}
+
+func (m *OutOfOrderSeriesStatsResponse) GetTrackedSinceTimestampMs() int64 {
+	if m != nil {
+		return m.TrackedSinceTimestampMs
+	}
+	return 0
+}
+
+type OutOfOrderSeriesStats struct {
+	// The metric name and the labels the series are grouped by.
+	Labels                   []github_com_cortexproject_cortex_pkg_cortexpb.LabelAdapter `protobuf:"bytes,1,rep,name=labels,proto3,customtype=github.com/cortexproject/cortex/pkg/cortexpb.LabelAdapter" json:"labels"`
+	OutOfOrder               uint64                                                      `protobuf:"varint,2,opt,name=out_of_order,json=outOfOrder,proto3" json:"out_of_order,omitempty"`
+	OutOfBounds              uint64                                                      `protobuf:"varint,3,opt,name=out_of_bounds,json=outOfBounds,proto3" json:"out_of_bounds,omitempty"`
+	TooOld                   uint64                                                      `protobuf:"varint,4,opt,name=too_old,json=tooOld,proto3" json:"too_old,omitempty"`
+	DuplicateTimestamp       uint64                                                      `protobuf:"varint,5,opt,name=duplicate_timestamp,json=duplicateTimestamp,proto3" json:"duplicate_timestamp,omitempty"`
+	LastDiscardedTimestampMs int64                                                       `protobuf:"varint,6,opt,name=last_discarded_timestamp_ms,json=lastDiscardedTimestampMs,proto3" json:"last_discarded_timestamp_ms,omitempty"`
+}
+
+func (m *OutOfOrderSeriesStats) Reset()      { *m = OutOfOrderSeriesStats{} }
+func (*OutOfOrderSeriesStats) ProtoMessage() {}
+func (*OutOfOrderSeriesStats) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{32}
+}
+func (m *OutOfOrderSeriesStats) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *OutOfOrderSeriesStats) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_OutOfOrderSeriesStats.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *OutOfOrderSeriesStats) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OutOfOrderSeriesStats.Merge(m, src)
+}
+func (m *OutOfOrderSeriesStats) XXX_Size() int {
+	return m.Size()
+}
+func (m *OutOfOrderSeriesStats) XXX_DiscardUnknown() {
+	xxx_messageInfo_OutOfOrderSeriesStats.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OutOfOrderSeriesStats proto.InternalMessageInfo
+
+func (m *OutOfOrderSeriesStats) GetOutOfOrder() uint64 {
+	if m != nil {
+		return m.OutOfOrder
+	}
+	return 0
+}
+
+func (m *OutOfOrderSeriesStats) GetOutOfBounds() uint64 {
+	if m != nil {
+		return m.OutOfBounds
+	}
+	return 0
+}
+
+func (m *OutOfOrderSeriesStats) GetTooOld() uint64 {
+	if m != nil {
+		return m.TooOld
+	}
+	return 0
+}
+
+func (m *OutOfOrderSeriesStats) GetDuplicateTimestamp() uint64 {
+	if m != nil {
+		return m.DuplicateTimestamp
+	}
+	return 0
+}
+
+func (m *OutOfOrderSeriesStats) GetLastDiscardedTimestampMs() int64 {
+	if m != nil {
+		return m.LastDiscardedTimestampMs
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterEnum("cortex.MatchType", MatchType_name, MatchType_value)
 	proto.RegisterType((*ReadRequest)(nil), "cortex.ReadRequest")
@@ -1504,102 +1815,126 @@ func init() {
 	proto.RegisterType((*LabelMatchers)(nil), "cortex.LabelMatchers")
 	proto.RegisterType((*LabelMatcher)(nil), "cortex.LabelMatcher")
 	proto.RegisterType((*TimeSeriesFile)(nil), "cortex.TimeSeriesFile")
+	proto.RegisterType((*MetricsUsageRequest)(nil), "cortex.MetricsUsageRequest")
+	proto.RegisterType((*MetricsUsageResponse)(nil), "cortex.MetricsUsageResponse")
+	proto.RegisterType((*MetricUsage)(nil), "cortex.MetricUsage")
+	proto.RegisterType((*OutOfOrderSeriesStatsRequest)(nil), "cortex.OutOfOrderSeriesStatsRequest")
+	proto.RegisterType((*OutOfOrderSeriesStatsResponse)(nil), "cortex.OutOfOrderSeriesStatsResponse")
+	proto.RegisterType((*OutOfOrderSeriesStats)(nil), "cortex.OutOfOrderSeriesStats")
 }
 
 func init() { proto.RegisterFile("ingester.proto", fileDescriptor_60f6df4f3586b478) }
 
 var fileDescriptor_60f6df4f3586b478 = []byte{
-	// 1439 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x58, 0x4b, 0x73, 0x13, 0x47,
-	0x10, 0xd6, 0xea, 0x65, 0xa9, 0xf5, 0x40, 0x1e, 0x1b, 0x2c, 0x44, 0x58, 0xc1, 0x52, 0x24, 0xaa,
-	0x24, 0xc8, 0xe0, 0x24, 0x55, 0x90, 0x07, 0x94, 0x05, 0x06, 0x0c, 0x18, 0xc3, 0xda, 0x40, 0x2a,
-	0x95, 0xd4, 0xd6, 0x5a, 0x1a, 0xcb, 0x1b, 0x76, 0xb5, 0xcb, 0xce, 0x88, 0x02, 0x4e, 0x49, 0xe5,
-	0x07, 0x24, 0x87, 0xfc, 0x81, 0xdc, 0x72, 0x4d, 0x55, 0x7e, 0x04, 0x47, 0x1f, 0x72, 0xa0, 0x38,
-	0xb8, 0x82, 0xb8, 0x24, 0x37, 0xf2, 0x0f, 0x52, 0x3b, 0x33, 0xfb, 0xb4, 0xfc, 0x20, 0x05, 0xb9,
-	0x69, 0xbb, 0xbf, 0xe9, 0xe9, 0xfe, 0xe6, 0x9b, 0xe9, 0xb6, 0xa1, 0x6a, 0x0c, 0xfa, 0x98, 0x50,
-	0xec, 0xb6, 0x1d, 0xd7, 0xa6, 0x36, 0xca, 0x77, 0x6d, 0x97, 0xe2, 0x47, 0x8d, 0xe9, 0xbe, 0xdd,
-	0xb7, 0x99, 0x69, 0xd6, 0xfb, 0xc5, 0xbd, 0x8d, 0x73, 0x7d, 0x83, 0x6e, 0x0c, 0xd7, 0xda, 0x5d,
-	0xdb, 0x9a, 0xe5, 0x40, 0xc7, 0xb5, 0xbf, 0xc5, 0x5d, 0x2a, 0xbe, 0x66, 0x9d, 0xfb, 0x7d, 0xdf,
-	0xb1, 0x26, 0x7e, 0xf0, 0xa5, 0xca, 0x17, 0x50, 0x52, 0xb1, 0xde, 0x53, 0xf1, 0x83, 0x21, 0x26,
-	0x14, 0xb5, 0x61, 0xe2, 0xc1, 0x10, 0xbb, 0x06, 0x26, 0x75, 0xe9, 0x58, 0xa6, 0x55, 0x9a, 0x9b,
-	0x6e, 0x0b, 0xf8, 0xed, 0x21, 0x76, 0x1f, 0x0b, 0x98, 0xea, 0x83, 0x94, 0x0b, 0x50, 0xe6, 0xcb,
-	0x89, 0x63, 0x0f, 0x08, 0x46, 0xb3, 0x30, 0xe1, 0x62, 0x32, 0x34, 0xa9, 0xbf, 0xfe, 0x60, 0x62,
-	0x3d, 0xc7, 0xa9, 0x3e, 0x4a, 0xb9, 0x0e, 0x95, 0x98, 0x07, 0x7d, 0x0a, 0x40, 0x0d, 0x0b, 0x93,
-	0x71, 0x49, 0x38, 0x6b, 0xed, 0x55, 0xc3, 0xc2, 0x2b, 0xcc, 0xd7, 0xc9, 0x3e, 0xdd, 0x6a, 0xa6,
-	0xd4, 0x08, 0x5a, 0xf9, 0x39, 0x0d, 0xe5, 0x68, 0x9e, 0xe8, 0x43, 0x40, 0x84, 0xea, 0x2e, 0xd5,
-	0x18, 0x88, 0xea, 0x96, 0xa3, 0x59, 0x5e, 0x50, 0xa9, 0x95, 0x51, 0x6b, 0xcc, 0xb3, 0xea, 0x3b,
-	0x96, 0x08, 0x6a, 0x41, 0x0d, 0x0f, 0x7a, 0x71, 0x6c, 0x9a, 0x61, 0xab, 0x78, 0xd0, 0x8b, 0x22,
-	0x4f, 0x43, 0xc1, 0xd2, 0x69, 0x77, 0x03, 0xbb, 0xa4, 0x9e, 0x89, 0xf3, 0x74, 0x43, 0x5f, 0xc3,
-	0xe6, 0x12, 0x77, 0xaa, 0x01, 0x0a, 0x3d, 0x81, 0x8c, 0x8a, 0xd7, 0xeb, 0x7f, 0x4f, 0x1c, 0x93,
-	0x5a, 0xa5, 0xb9, 0x23, 0x61, 0x41, 0x4b, 0x98, 0x10, 0xbd, 0x8f, 0xef, 0x19, 0x74, 0xa3, 0x33,
-	0x5c, 0x57, 0xf1, 0x7a, 0xe7, 0x9a, 0x57, 0xd7, 0xe6, 0x56, 0x53, 0x7a, 0xbe, 0xd5, 0x3c, 0xff,
-	0x3a, 0x27, 0xbb, 0x3d, 0x96, 0xea, 0x6d, 0xaa, 0xfc, 0x22, 0xc1, 0xf4, 0xc2, 0x23, 0x6c, 0x39,
-	0xa6, 0xee, 0xfe, 0x2f, 0xf4, 0x9c, 0xd9, 0x46, 0xcf, 0xc1, 0x71, 0xf4, 0x90, 0x90, 0x1f, 0xe5,
-	0x6b, 0x98, 0x62, 0xa9, 0xad, 0x50, 0x17, 0xeb, 0x56, 0xa0, 0x86, 0x0b, 0x50, 0xea, 0x6e, 0x0c,
-	0x07, 0xf7, 0x63, 0x72, 0x98, 0xf1, 0x83, 0x85, 0x62, 0xb8, 0xe8, 0x81, 0x84, 0x22, 0xa2, 0x2b,
-	0xae, 0x65, 0x0b, 0xe9, 0x5a, 0x46, 0x59, 0x81, 0x83, 0x09, 0x02, 0xde, 0x80, 0xda, 0xfe, 0x90,
-	0x00, 0xb1, 0x72, 0xee, 0xea, 0xe6, 0x10, 0x13, 0x9f, 0xd4, 0xa3, 0x00, 0xa6, 0x67, 0xd5, 0x06,
-	0xba, 0x85, 0x19, 0x99, 0x45, 0xb5, 0xc8, 0x2c, 0x37, 0x75, 0x0b, 0xef, 0xc0, 0x79, 0xfa, 0x35,
-	0x38, 0xcf, 0xec, 0xc9, 0x79, 0x96, 0x89, 0x6c, 0x2f, 0xce, 0xd1, 0x34, 0xe4, 0x4c, 0xc3, 0x32,
-	0x68, 0x3d, 0xc7, 0x22, 0xf2, 0x0f, 0xe5, 0x2c, 0x4c, 0xc5, 0xaa, 0x12, 0x4c, 0x1d, 0x87, 0x32,
-	0x2f, 0xeb, 0x21, 0xb3, 0x33, 0xae, 0x8a, 0x6a, 0xc9, 0x0c, 0xa1, 0xca, 0x79, 0x38, 0x1c, 0x59,
-	0x99, 0x38, 0xc9, 0x7d, 0xac, 0xff, 0x5d, 0x82, 0xc9, 0x1b, 0x3e, 0x51, 0xe4, 0x6d, 0x8b, 0x34,
-	0xa8, 0x3e, 0x13, 0xa9, 0xfe, 0x3f, 0xd0, 0xa8, 0x7c, 0x22, 0x64, 0x20, 0xb2, 0x16, 0xf5, 0x36,
-	0xa1, 0x14, 0xca, 0xc0, 0x2f, 0x17, 0x02, 0x1d, 0x10, 0xe5, 0x33, 0xa8, 0x87, 0xcb, 0x12, 0x64,
-	0xed, 0xb9, 0x18, 0x41, 0xed, 0x0e, 0xc1, 0xee, 0x0a, 0xd5, 0xa9, 0x4f, 0x94, 0xf2, 0x7d, 0x1a,
-	0x26, 0x23, 0x46, 0x11, 0xea, 0xa4, 0xdf, 0x4b, 0x0c, 0x7b, 0xa0, 0xb9, 0x3a, 0xe5, 0x92, 0x94,
-	0xd4, 0x4a, 0x60, 0x55, 0x75, 0x8a, 0x3d, 0xd5, 0x0e, 0x86, 0x96, 0x26, 0x2e, 0x82, 0xc7, 0x58,
-	0x56, 0x2d, 0x0e, 0x86, 0x16, 0x57, 0xbf, 0x77, 0x08, 0xba, 0x63, 0x68, 0x89, 0x48, 0x19, 0x16,
-	0xa9, 0xa6, 0x3b, 0xc6, 0x62, 0x2c, 0x58, 0x1b, 0xa6, 0xdc, 0xa1, 0x89, 0x93, 0xf0, 0x2c, 0x83,
-	0x4f, 0x7a, 0xae, 0x38, 0xfe, 0x04, 0x54, 0xf4, 0x2e, 0x35, 0x1e, 0x62, 0x7f, 0xff, 0x1c, 0xdb,
-	0xbf, 0xcc, 0x8d, 0x22, 0x85, 0x13, 0x50, 0x31, 0x6d, 0xbd, 0x87, 0x7b, 0xda, 0x9a, 0x69, 0x77,
-	0xef, 0x93, 0x7a, 0x9e, 0x83, 0xb8, 0xb1, 0xc3, 0x6c, 0xca, 0x37, 0x30, 0xe5, 0x51, 0xb0, 0x78,
-	0x29, 0x4e, 0xc2, 0x0c, 0x4c, 0x0c, 0x09, 0x76, 0x35, 0xa3, 0x27, 0x2e, 0x64, 0xde, 0xfb, 0x5c,
-	0xec, 0xa1, 0x53, 0x90, 0xed, 0xe9, 0x54, 0x67, 0x05, 0x97, 0xe6, 0x0e, 0xfb, 0x47, 0xbd, 0x8d,
-	0x46, 0x95, 0xc1, 0x94, 0x2b, 0x80, 0x3c, 0x17, 0x89, 0x47, 0x3f, 0x03, 0x39, 0xe2, 0x19, 0xc4,
-	0xfb, 0x71, 0x24, 0x1a, 0x25, 0x91, 0x89, 0xca, 0x91, 0xca, 0x53, 0x09, 0xe4, 0x25, 0x4c, 0x5d,
-	0xa3, 0x4b, 0x2e, 0xdb, 0x6e, 0x5c, 0x59, 0x6f, 0x59, 0xf7, 0x67, 0xa1, 0xec, 0x4b, 0x57, 0x23,
-	0x98, 0xee, 0xfe, 0x40, 0x97, 0x7c, 0xe8, 0x0a, 0xa6, 0xe1, 0x8d, 0xc9, 0x46, 0xdf, 0x8b, 0xeb,
-	0xd0, 0xdc, 0xb1, 0x12, 0x41, 0x50, 0x0b, 0xf2, 0x16, 0x83, 0x08, 0x86, 0x6a, 0xd1, 0xf6, 0xe7,
-	0xd9, 0x55, 0xe1, 0x57, 0x6e, 0xc3, 0xc9, 0x1d, 0x82, 0x25, 0x6e, 0xc8, 0xfe, 0x43, 0x3a, 0x70,
-	0x48, 0x84, 0x5c, 0xc2, 0x54, 0xf7, 0x8e, 0xd1, 0x67, 0x38, 0xa8, 0x47, 0x8a, 0xbe, 0x00, 0x2d,
-	0xa8, 0xb1, 0x1f, 0x9a, 0x83, 0x5d, 0x4d, 0xec, 0x21, 0x98, 0x64, 0xf6, 0x5b, 0xd8, 0xe5, 0xf1,
-	0xd0, 0xa1, 0x20, 0x87, 0x0c, 0x17, 0x95, 0xd8, 0x71, 0x19, 0x66, 0xb6, 0xed, 0x28, 0xd2, 0xfe,
-	0x18, 0x0a, 0x96, 0xb0, 0x89, 0xc4, 0xeb, 0xc9, 0xc4, 0x83, 0x35, 0x01, 0x52, 0xf9, 0x47, 0x82,
-	0x03, 0x89, 0x5e, 0xe7, 0xa5, 0xb9, 0xee, 0xda, 0x96, 0xe6, 0x0f, 0x8a, 0xa1, 0xb6, 0xab, 0x9e,
-	0x7d, 0x51, 0x98, 0x17, 0x7b, 0x51, 0xf1, 0xa7, 0x63, 0xe2, 0x1f, 0x40, 0x9e, 0x3d, 0x29, 0x7e,
-	0x93, 0x9e, 0x0a, 0x53, 0x61, 0xd4, 0xdf, 0xd2, 0x0d, 0xb7, 0x33, 0xef, 0xf5, 0xbd, 0xe7, 0x5b,
-	0xcd, 0xd7, 0x9a, 0x31, 0xf9, 0xfa, 0xf9, 0x9e, 0xee, 0x50, 0xec, 0xaa, 0x62, 0x17, 0xf4, 0x01,
-	0xe4, 0x79, 0x6b, 0xae, 0x67, 0xd9, 0x7e, 0x15, 0x5f, 0x73, 0xd1, 0xee, 0x2d, 0x20, 0xca, 0x8f,
-	0x12, 0xe4, 0x78, 0xa5, 0x6f, 0xeb, 0x22, 0x34, 0xa0, 0x80, 0x07, 0x5d, 0xbb, 0x67, 0x0c, 0xfa,
-	0xec, 0x00, 0x73, 0x6a, 0xf0, 0x8d, 0x90, 0x78, 0x17, 0x3c, 0xa5, 0x97, 0xc5, 0xe5, 0x9f, 0x87,
-	0x4a, 0x4c, 0x91, 0xb1, 0x29, 0x50, 0xda, 0xcf, 0x14, 0xa8, 0x68, 0x50, 0x8e, 0x7a, 0xd0, 0x49,
-	0xc8, 0xd2, 0xc7, 0x0e, 0x7f, 0x92, 0xab, 0x73, 0x93, 0xfe, 0x6a, 0xe6, 0x5e, 0x7d, 0xec, 0x60,
-	0x95, 0xb9, 0xbd, 0x6c, 0xd8, 0x30, 0xc1, 0x8f, 0x8f, 0xfd, 0xf6, 0xc4, 0xcb, 0x3a, 0xa9, 0xd0,
-	0x1e, 0xff, 0x50, 0x7e, 0x90, 0xa0, 0x1a, 0x2a, 0xe5, 0xb2, 0x61, 0xe2, 0x37, 0x21, 0x94, 0x06,
-	0x14, 0xd6, 0x0d, 0x13, 0xb3, 0x1c, 0xf8, 0x76, 0xc1, 0xf7, 0x38, 0xa6, 0xde, 0xbf, 0x06, 0xc5,
-	0xa0, 0x04, 0x54, 0x84, 0xdc, 0xc2, 0xed, 0x3b, 0xf3, 0x37, 0x6a, 0x29, 0x54, 0x81, 0xe2, 0xcd,
-	0xe5, 0x55, 0x8d, 0x7f, 0x4a, 0xe8, 0x00, 0x94, 0xd4, 0x85, 0x2b, 0x0b, 0x5f, 0x6a, 0x4b, 0xf3,
-	0xab, 0x17, 0xaf, 0xd6, 0xd2, 0x08, 0x41, 0x95, 0x1b, 0x6e, 0x2e, 0x0b, 0x5b, 0x66, 0xee, 0xb7,
-	0x02, 0x14, 0xfc, 0x1c, 0xd1, 0x39, 0xc8, 0xde, 0x1a, 0x92, 0x0d, 0x74, 0x28, 0x54, 0xea, 0x3d,
-	0xd7, 0xa0, 0x58, 0xdc, 0xe8, 0xc6, 0xcc, 0x36, 0x3b, 0xbf, 0x77, 0x4a, 0x0a, 0x2d, 0x02, 0x78,
-	0x4b, 0xf9, 0x33, 0x82, 0xde, 0x09, 0x81, 0xdc, 0xb2, 0xcf, 0x30, 0x2d, 0xe9, 0xb4, 0x84, 0x2e,
-	0x41, 0x29, 0x32, 0xab, 0xa2, 0xb1, 0x7f, 0x22, 0x35, 0x8e, 0xc4, 0xac, 0xf1, 0xd7, 0x4b, 0x49,
-	0x9d, 0x96, 0xd0, 0x32, 0x54, 0x99, 0xcb, 0x1f, 0x4c, 0x49, 0x90, 0x54, 0x7b, 0xdc, 0xb0, 0xde,
-	0x38, 0xba, 0x83, 0x37, 0xa8, 0xf0, 0x2a, 0x94, 0x22, 0xe3, 0x17, 0x6a, 0xc4, 0xb4, 0x18, 0x9b,
-	0x51, 0xc3, 0xe4, 0xc6, 0x4c, 0x7a, 0x4a, 0x0a, 0xdd, 0x15, 0x73, 0x58, 0x74, 0x90, 0xdb, 0x35,
-	0xde, 0xf1, 0x31, 0xbe, 0x31, 0x25, 0x2f, 0x00, 0x84, 0x23, 0x0f, 0x3a, 0x1c, 0x5b, 0x14, 0x9d,
-	0xf9, 0x1a, 0x8d, 0x71, 0xae, 0x20, 0xbd, 0x15, 0xa8, 0x25, 0x27, 0xa7, 0xdd, 0x82, 0x1d, 0xdb,
-	0xee, 0x1a, 0x93, 0x5b, 0x07, 0x8a, 0x41, 0xd7, 0x47, 0xf5, 0x31, 0x83, 0x00, 0x0f, 0xb6, 0xf3,
-	0x88, 0xa0, 0xa4, 0xd0, 0x65, 0x28, 0xcf, 0x9b, 0xe6, 0x7e, 0xc2, 0x34, 0xa2, 0x1e, 0x92, 0x8c,
-	0x63, 0x06, 0x0d, 0x24, 0xd9, 0x05, 0xd1, 0xbb, 0xc1, 0x1b, 0xb1, 0xeb, 0xf4, 0xd0, 0x78, 0x6f,
-	0x4f, 0x5c, 0xb0, 0xdb, 0x13, 0x38, 0xba, 0x6b, 0xcf, 0xdd, 0xf7, 0x9e, 0xa7, 0xf6, 0xc0, 0x8d,
-	0x61, 0x7d, 0x15, 0x0e, 0x24, 0x5a, 0x25, 0x92, 0x13, 0x51, 0x12, 0x5d, 0xbb, 0xd1, 0xdc, 0xd1,
-	0xef, 0xc7, 0xed, 0x7c, 0xbe, 0xf9, 0x42, 0x4e, 0x3d, 0x7b, 0x21, 0xa7, 0x5e, 0xbd, 0x90, 0xa5,
-	0xef, 0x46, 0xb2, 0xf4, 0xeb, 0x48, 0x96, 0x9e, 0x8e, 0x64, 0x69, 0x73, 0x24, 0x4b, 0x7f, 0x8e,
-	0x64, 0xe9, 0xaf, 0x91, 0x9c, 0x7a, 0x35, 0x92, 0xa5, 0x9f, 0x5e, 0xca, 0xa9, 0xcd, 0x97, 0x72,
-	0xea, 0xd9, 0x4b, 0x39, 0xf5, 0x55, 0xbe, 0x6b, 0x1a, 0x78, 0x40, 0xd7, 0xf2, 0xec, 0x3f, 0x23,
-	0x1f, 0xfd, 0x1b, 0x00, 0x00, 0xff, 0xff, 0x3c, 0x76, 0xeb, 0xb3, 0x84, 0x11, 0x00, 0x00,
+	// 1717 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x58, 0x4f, 0x73, 0xd4, 0xc8,
+	0x15, 0x1f, 0xcd, 0x8c, 0xc7, 0x9e, 0x37, 0x33, 0x66, 0xdc, 0xe3, 0x3f, 0x63, 0x19, 0xcb, 0x46,
+	0xc4, 0x89, 0x2b, 0x09, 0x36, 0x98, 0xa4, 0x0a, 0x42, 0x80, 0xf2, 0x80, 0x01, 0x03, 0x66, 0xb0,
+	0x6c, 0x20, 0x95, 0x4a, 0x4a, 0x25, 0x8f, 0x7a, 0x6c, 0xc5, 0xd2, 0x68, 0x90, 0x5a, 0x14, 0x70,
+	0x4a, 0x2a, 0x97, 0xdc, 0x92, 0x43, 0x2e, 0x39, 0xe6, 0xb6, 0x1f, 0x60, 0x3f, 0x04, 0x47, 0x1f,
+	0xb6, 0xb6, 0x28, 0x0e, 0xae, 0xc5, 0x54, 0x6d, 0xed, 0xde, 0xd8, 0x6f, 0xb0, 0xa5, 0xee, 0x96,
+	0x46, 0x92, 0x35, 0xb6, 0xd9, 0x05, 0x6e, 0xa3, 0xf7, 0x7e, 0xef, 0xf5, 0x7b, 0xbf, 0x7e, 0xdd,
+	0xef, 0xf5, 0xc0, 0xb0, 0xd1, 0xd9, 0xc6, 0x2e, 0xc1, 0xce, 0x42, 0xd7, 0xb1, 0x89, 0x8d, 0x0a,
+	0x2d, 0xdb, 0x21, 0xf8, 0xb9, 0x38, 0xba, 0x6d, 0x6f, 0xdb, 0x54, 0xb4, 0xe8, 0xff, 0x62, 0x5a,
+	0xf1, 0xf2, 0xb6, 0x41, 0x76, 0xbc, 0xad, 0x85, 0x96, 0x6d, 0x2d, 0x32, 0x60, 0xd7, 0xb1, 0xff,
+	0x86, 0x5b, 0x84, 0x7f, 0x2d, 0x76, 0x77, 0xb7, 0x03, 0xc5, 0x16, 0xff, 0xc1, 0x4c, 0xe5, 0xab,
+	0x50, 0x52, 0xb0, 0xa6, 0x2b, 0xf8, 0xa9, 0x87, 0x5d, 0x82, 0x16, 0x60, 0xf0, 0xa9, 0x87, 0x1d,
+	0x03, 0xbb, 0x75, 0x61, 0x36, 0x37, 0x5f, 0x5a, 0x1a, 0x5d, 0xe0, 0xf0, 0x75, 0x0f, 0x3b, 0x2f,
+	0x38, 0x4c, 0x09, 0x40, 0xf2, 0x75, 0x28, 0x33, 0x73, 0xb7, 0x6b, 0x77, 0x5c, 0x8c, 0x16, 0x61,
+	0xd0, 0xc1, 0xae, 0x67, 0x92, 0xc0, 0x7e, 0x2c, 0x61, 0xcf, 0x70, 0x4a, 0x80, 0x92, 0xef, 0x41,
+	0x25, 0xa6, 0x41, 0x7f, 0x00, 0x20, 0x86, 0x85, 0xdd, 0xb4, 0x20, 0xba, 0x5b, 0x0b, 0x9b, 0x86,
+	0x85, 0x37, 0xa8, 0xae, 0x91, 0x7f, 0xb5, 0x3f, 0x93, 0x51, 0x22, 0x68, 0xf9, 0xbf, 0x59, 0x28,
+	0x47, 0xe3, 0x44, 0xbf, 0x05, 0xe4, 0x12, 0xcd, 0x21, 0x2a, 0x05, 0x11, 0xcd, 0xea, 0xaa, 0x96,
+	0xef, 0x54, 0x98, 0xcf, 0x29, 0x55, 0xaa, 0xd9, 0x0c, 0x14, 0x6b, 0x2e, 0x9a, 0x87, 0x2a, 0xee,
+	0xe8, 0x71, 0x6c, 0x96, 0x62, 0x87, 0x71, 0x47, 0x8f, 0x22, 0xcf, 0xc3, 0x90, 0xa5, 0x91, 0xd6,
+	0x0e, 0x76, 0xdc, 0x7a, 0x2e, 0xce, 0xd3, 0x7d, 0x6d, 0x0b, 0x9b, 0x6b, 0x4c, 0xa9, 0x84, 0x28,
+	0xf4, 0x12, 0x72, 0x0a, 0x6e, 0xd7, 0xbf, 0x1f, 0x9c, 0x15, 0xe6, 0x4b, 0x4b, 0x53, 0xbd, 0x84,
+	0xd6, 0xb0, 0xeb, 0x6a, 0xdb, 0xf8, 0x89, 0x41, 0x76, 0x1a, 0x5e, 0x5b, 0xc1, 0xed, 0xc6, 0x5d,
+	0x3f, 0xaf, 0xbd, 0xfd, 0x19, 0xe1, 0xcd, 0xfe, 0xcc, 0xb5, 0x0f, 0xd9, 0xd9, 0xc3, 0xbe, 0x14,
+	0x7f, 0x51, 0xf9, 0xff, 0x02, 0x8c, 0xae, 0x3c, 0xc7, 0x56, 0xd7, 0xd4, 0x9c, 0xcf, 0x42, 0xcf,
+	0x85, 0x43, 0xf4, 0x8c, 0xa5, 0xd1, 0xe3, 0xf6, 0xf8, 0x91, 0xff, 0x02, 0x35, 0x1a, 0xda, 0x06,
+	0x71, 0xb0, 0x66, 0x85, 0xd5, 0x70, 0x1d, 0x4a, 0xad, 0x1d, 0xaf, 0xb3, 0x1b, 0x2b, 0x87, 0x89,
+	0xc0, 0x59, 0xaf, 0x18, 0x6e, 0xf8, 0x20, 0x5e, 0x11, 0x51, 0x8b, 0xbb, 0xf9, 0xa1, 0x6c, 0x35,
+	0x27, 0x6f, 0xc0, 0x58, 0x82, 0x80, 0x8f, 0x50, 0x6d, 0x5f, 0x09, 0x80, 0x68, 0x3a, 0x8f, 0x35,
+	0xd3, 0xc3, 0x6e, 0x40, 0xea, 0x34, 0x80, 0xe9, 0x4b, 0xd5, 0x8e, 0x66, 0x61, 0x4a, 0x66, 0x51,
+	0x29, 0x52, 0xc9, 0x03, 0xcd, 0xc2, 0x7d, 0x38, 0xcf, 0x7e, 0x00, 0xe7, 0xb9, 0x63, 0x39, 0xcf,
+	0xcf, 0x0a, 0x27, 0xe0, 0x1c, 0x8d, 0xc2, 0x80, 0x69, 0x58, 0x06, 0xa9, 0x0f, 0x50, 0x8f, 0xec,
+	0x43, 0xbe, 0x04, 0xb5, 0x58, 0x56, 0x9c, 0xa9, 0x33, 0x50, 0x66, 0x69, 0x3d, 0xa3, 0x72, 0xca,
+	0x55, 0x51, 0x29, 0x99, 0x3d, 0xa8, 0x7c, 0x0d, 0x26, 0x23, 0x96, 0x89, 0x9d, 0x3c, 0x81, 0xfd,
+	0x97, 0x02, 0x8c, 0xdc, 0x0f, 0x88, 0x72, 0x3f, 0x75, 0x91, 0x86, 0xd9, 0xe7, 0x22, 0xd9, 0xff,
+	0x04, 0x1a, 0xe5, 0xdf, 0x03, 0x8a, 0x46, 0xcd, 0xf3, 0x9d, 0x81, 0x52, 0xaf, 0x0c, 0x82, 0x74,
+	0x21, 0xac, 0x03, 0x57, 0xbe, 0x02, 0xf5, 0x9e, 0x59, 0x82, 0xac, 0x63, 0x8d, 0x11, 0x54, 0x1f,
+	0xb9, 0xd8, 0xd9, 0x20, 0x1a, 0x09, 0x88, 0x92, 0xff, 0x91, 0x85, 0x91, 0x88, 0x90, 0xbb, 0x9a,
+	0x0b, 0x7a, 0x89, 0x61, 0x77, 0x54, 0x47, 0x23, 0xac, 0x24, 0x05, 0xa5, 0x12, 0x4a, 0x15, 0x8d,
+	0x60, 0xbf, 0x6a, 0x3b, 0x9e, 0xa5, 0xf2, 0x83, 0xe0, 0x33, 0x96, 0x57, 0x8a, 0x1d, 0xcf, 0x62,
+	0xd5, 0xef, 0x6f, 0x82, 0xd6, 0x35, 0xd4, 0x84, 0xa7, 0x1c, 0xf5, 0x54, 0xd5, 0xba, 0xc6, 0x6a,
+	0xcc, 0xd9, 0x02, 0xd4, 0x1c, 0xcf, 0xc4, 0x49, 0x78, 0x9e, 0xc2, 0x47, 0x7c, 0x55, 0x1c, 0x7f,
+	0x16, 0x2a, 0x5a, 0x8b, 0x18, 0xcf, 0x70, 0xb0, 0xfe, 0x00, 0x5d, 0xbf, 0xcc, 0x84, 0x3c, 0x84,
+	0xb3, 0x50, 0x31, 0x6d, 0x4d, 0xc7, 0xba, 0xba, 0x65, 0xda, 0xad, 0x5d, 0xb7, 0x5e, 0x60, 0x20,
+	0x26, 0x6c, 0x50, 0x99, 0xfc, 0x57, 0xa8, 0xf9, 0x14, 0xac, 0xde, 0x8c, 0x93, 0x30, 0x01, 0x83,
+	0x9e, 0x8b, 0x1d, 0xd5, 0xd0, 0xf9, 0x81, 0x2c, 0xf8, 0x9f, 0xab, 0x3a, 0x3a, 0x07, 0x79, 0x5d,
+	0x23, 0x1a, 0x4d, 0xb8, 0xb4, 0x34, 0x19, 0x6c, 0xf5, 0x21, 0x1a, 0x15, 0x0a, 0x93, 0x6f, 0x03,
+	0xf2, 0x55, 0x6e, 0xdc, 0xfb, 0x05, 0x18, 0x70, 0x7d, 0x01, 0xbf, 0x3f, 0xa6, 0xa2, 0x5e, 0x12,
+	0x91, 0x28, 0x0c, 0x29, 0xbf, 0x12, 0x40, 0x5a, 0xc3, 0xc4, 0x31, 0x5a, 0xee, 0x2d, 0xdb, 0x89,
+	0x57, 0xd6, 0x27, 0xae, 0xfb, 0x4b, 0x50, 0x0e, 0x4a, 0x57, 0x75, 0x31, 0x39, 0xfa, 0x82, 0x2e,
+	0x05, 0xd0, 0x0d, 0x4c, 0x7a, 0x27, 0x26, 0x1f, 0xbd, 0x2f, 0xee, 0xc1, 0x4c, 0xdf, 0x4c, 0x38,
+	0x41, 0xf3, 0x50, 0xb0, 0x28, 0x84, 0x33, 0x54, 0x8d, 0xb6, 0x3f, 0x5f, 0xae, 0x70, 0xbd, 0xbc,
+	0x0e, 0x73, 0x7d, 0x9c, 0x25, 0x4e, 0xc8, 0xc9, 0x5d, 0x76, 0x61, 0x9c, 0xbb, 0x5c, 0xc3, 0x44,
+	0xf3, 0xb7, 0x31, 0x60, 0x38, 0xcc, 0x47, 0x88, 0xde, 0x00, 0xf3, 0x50, 0xa5, 0x3f, 0xd4, 0x2e,
+	0x76, 0x54, 0xbe, 0x06, 0x67, 0x92, 0xca, 0x1f, 0x62, 0x87, 0xf9, 0x43, 0xe3, 0x61, 0x0c, 0x39,
+	0x56, 0x54, 0x7c, 0xc5, 0x26, 0x4c, 0x1c, 0x5a, 0x91, 0x87, 0xfd, 0x3b, 0x18, 0xb2, 0xb8, 0x8c,
+	0x07, 0x5e, 0x4f, 0x06, 0x1e, 0xda, 0x84, 0x48, 0xf9, 0x07, 0x01, 0x4e, 0x25, 0x7a, 0x9d, 0x1f,
+	0x66, 0xdb, 0xb1, 0x2d, 0x35, 0x18, 0x14, 0x7b, 0xb5, 0x3d, 0xec, 0xcb, 0x57, 0xb9, 0x78, 0x55,
+	0x8f, 0x16, 0x7f, 0x36, 0x56, 0xfc, 0x1d, 0x28, 0xd0, 0x2b, 0x25, 0x68, 0xd2, 0xb5, 0x5e, 0x28,
+	0x94, 0xfa, 0x87, 0x9a, 0xe1, 0x34, 0x96, 0xfd, 0xbe, 0xf7, 0x66, 0x7f, 0xe6, 0x83, 0x66, 0x4c,
+	0x66, 0xbf, 0xac, 0x6b, 0x5d, 0x82, 0x1d, 0x85, 0xaf, 0x82, 0x7e, 0x03, 0x05, 0xd6, 0x9a, 0xeb,
+	0x79, 0xba, 0x5e, 0x25, 0xa8, 0xb9, 0x68, 0xf7, 0xe6, 0x10, 0xf9, 0xdf, 0x02, 0x0c, 0xb0, 0x4c,
+	0x3f, 0xd5, 0x41, 0x10, 0x61, 0x08, 0x77, 0x5a, 0xb6, 0x6e, 0x74, 0xb6, 0xe9, 0x06, 0x0e, 0x28,
+	0xe1, 0x37, 0x42, 0xfc, 0x5e, 0xf0, 0x2b, 0xbd, 0xcc, 0x0f, 0xff, 0x32, 0x54, 0x62, 0x15, 0x19,
+	0x9b, 0x02, 0x85, 0x93, 0x4c, 0x81, 0xb2, 0x0a, 0xe5, 0xa8, 0x06, 0xcd, 0x41, 0x9e, 0xbc, 0xe8,
+	0xb2, 0x2b, 0x79, 0x78, 0x69, 0x24, 0xb0, 0xa6, 0xea, 0xcd, 0x17, 0x5d, 0xac, 0x50, 0xb5, 0x1f,
+	0x0d, 0x1d, 0x26, 0xd8, 0xf6, 0xd1, 0xdf, 0x7e, 0xf1, 0xd2, 0x4e, 0xca, 0x6b, 0x8f, 0x7d, 0xc8,
+	0xff, 0x14, 0x60, 0xb8, 0x57, 0x29, 0xb7, 0x0c, 0x13, 0x7f, 0x8c, 0x42, 0x11, 0x61, 0xa8, 0x6d,
+	0x98, 0x98, 0xc6, 0xc0, 0x96, 0x0b, 0xbf, 0x53, 0x99, 0x1a, 0x83, 0x1a, 0x3f, 0x00, 0x8f, 0xfc,
+	0x79, 0x34, 0x68, 0x50, 0xff, 0x12, 0x60, 0x34, 0x2e, 0xe7, 0xa7, 0xe2, 0x22, 0x0c, 0xb2, 0xa3,
+	0x13, 0xf0, 0x58, 0x0b, 0x99, 0xa0, 0x62, 0x8a, 0xe6, 0xf5, 0x11, 0x20, 0xd1, 0x15, 0x10, 0x89,
+	0xa3, 0xb5, 0x76, 0xb1, 0xae, 0xba, 0x46, 0xa7, 0x85, 0xd3, 0xb6, 0x7c, 0x82, 0x23, 0x36, 0x7c,
+	0x40, 0x64, 0xef, 0xfd, 0x50, 0x4a, 0x11, 0xdf, 0x7e, 0xc3, 0x65, 0x7e, 0xa3, 0x53, 0x1b, 0x30,
+	0x11, 0x1d, 0xdb, 0x8e, 0xe9, 0x8f, 0x97, 0x61, 0xd2, 0xd4, 0x5c, 0xa2, 0xb2, 0x77, 0x51, 0xea,
+	0xc0, 0x36, 0xee, 0x03, 0xd6, 0x99, 0x3e, 0x1a, 0x8a, 0x04, 0xa7, 0x9b, 0x1e, 0x69, 0xb6, 0x9b,
+	0x8e, 0x8e, 0x1d, 0xe6, 0x2e, 0xd6, 0xd6, 0xff, 0x27, 0xc0, 0x74, 0x1f, 0x00, 0xa7, 0xef, 0x0a,
+	0x14, 0x62, 0x03, 0xec, 0x74, 0xc0, 0x5e, 0xaa, 0x59, 0x70, 0xce, 0x98, 0xc9, 0xcf, 0xa3, 0xf1,
+	0xeb, 0x2c, 0x8c, 0xa5, 0x2e, 0x12, 0xb9, 0x5b, 0x84, 0xcf, 0x72, 0xb7, 0xcc, 0x42, 0xd9, 0xf6,
+	0x88, 0x6a, 0xb7, 0x55, 0xdb, 0x0f, 0x85, 0xef, 0x10, 0xd8, 0x61, 0x70, 0x48, 0x86, 0x0a, 0x47,
+	0x6c, 0xd9, 0x5e, 0x47, 0x67, 0xdb, 0x92, 0x57, 0x4a, 0x14, 0xd2, 0xa0, 0x22, 0xff, 0x04, 0x10,
+	0xdb, 0x56, 0x6d, 0x53, 0xa7, 0xf5, 0x9c, 0x57, 0x0a, 0xc4, 0xb6, 0x9b, 0xa6, 0x8e, 0x16, 0xa1,
+	0xa6, 0x7b, 0x5d, 0xd3, 0x68, 0x69, 0x24, 0xc2, 0x10, 0x9f, 0x53, 0x50, 0xa8, 0x0a, 0xb9, 0x41,
+	0x57, 0x61, 0x8a, 0x16, 0x84, 0x6e, 0xb8, 0x2d, 0xcd, 0xd1, 0x93, 0x25, 0x51, 0xa0, 0xbc, 0xd6,
+	0x7d, 0xc8, 0xcd, 0x00, 0x11, 0x21, 0xf6, 0xd7, 0x77, 0xa1, 0x18, 0x5e, 0x02, 0xa8, 0x08, 0x03,
+	0x2b, 0xeb, 0x8f, 0x96, 0xef, 0x57, 0x33, 0xa8, 0x02, 0xc5, 0x07, 0xcd, 0x4d, 0x95, 0x7d, 0x0a,
+	0xe8, 0x14, 0x94, 0x94, 0x95, 0xdb, 0x2b, 0x7f, 0x52, 0xd7, 0x96, 0x37, 0x6f, 0xdc, 0xa9, 0x66,
+	0x11, 0x82, 0x61, 0x26, 0x78, 0xd0, 0xe4, 0xb2, 0xdc, 0xd2, 0xb7, 0x45, 0x18, 0x0a, 0x4e, 0x39,
+	0xba, 0x0c, 0xf9, 0x87, 0x9e, 0xbb, 0x83, 0xc6, 0x7b, 0xfb, 0xf1, 0xc4, 0x31, 0x48, 0x70, 0x46,
+	0xc5, 0x89, 0x43, 0x72, 0x56, 0x64, 0x72, 0x06, 0xad, 0x02, 0xf8, 0xa6, 0xac, 0x11, 0xa3, 0xd3,
+	0x3d, 0x20, 0x93, 0x9c, 0xd0, 0xcd, 0xbc, 0x70, 0x5e, 0x40, 0x37, 0xa1, 0x14, 0x79, 0xed, 0xa1,
+	0xd4, 0x3f, 0x19, 0xc4, 0xa9, 0x98, 0x34, 0xde, 0xff, 0xe5, 0xcc, 0x79, 0x01, 0x35, 0x61, 0x98,
+	0xaa, 0x82, 0xa7, 0x9d, 0x1b, 0x06, 0xb5, 0x90, 0xf6, 0xdc, 0x15, 0xa7, 0xfb, 0x68, 0xc3, 0x0c,
+	0xef, 0x40, 0x29, 0xf2, 0x80, 0x41, 0x62, 0xec, 0x36, 0x8f, 0xbd, 0xf2, 0xc4, 0xa9, 0x54, 0x5d,
+	0xe8, 0xe9, 0x31, 0x8c, 0x44, 0x14, 0x3c, 0xcd, 0xa3, 0xfc, 0x9d, 0x49, 0xd1, 0xa5, 0xa4, 0xbc,
+	0x02, 0xd0, 0x7b, 0x34, 0xa0, 0xc9, 0x98, 0x51, 0xf4, 0xd5, 0x24, 0x8a, 0x69, 0xaa, 0x30, 0xbc,
+	0x0d, 0xa8, 0x26, 0xdf, 0x1e, 0x47, 0x39, 0x9b, 0x3d, 0xac, 0x4a, 0x89, 0xad, 0x01, 0xc5, 0x70,
+	0x6e, 0x46, 0xf5, 0x94, 0x51, 0x9a, 0x39, 0xeb, 0x3f, 0x64, 0xcb, 0x19, 0x74, 0x0b, 0xca, 0xcb,
+	0xa6, 0x79, 0x12, 0x37, 0x62, 0x54, 0xe3, 0x26, 0xfd, 0x98, 0x30, 0xd1, 0x67, 0x8e, 0x44, 0xbf,
+	0x8c, 0xf7, 0x96, 0x7e, 0xf3, 0xb7, 0xf8, 0xab, 0x63, 0x71, 0xe1, 0x6a, 0x2f, 0x61, 0xfa, 0xc8,
+	0xa9, 0xf5, 0xc4, 0x6b, 0x9e, 0x3b, 0x06, 0x97, 0xc2, 0xfa, 0x26, 0x9c, 0x4a, 0x0c, 0x9b, 0x48,
+	0x4a, 0x78, 0x49, 0xcc, 0xbd, 0xe2, 0x4c, 0x5f, 0x7d, 0x98, 0xd1, 0x3d, 0x28, 0x47, 0x3b, 0x35,
+	0x9a, 0x4a, 0x98, 0x44, 0xfb, 0xba, 0x78, 0x3a, 0x5d, 0x19, 0x3a, 0x6b, 0xf7, 0x6b, 0x12, 0xbf,
+	0x38, 0xb2, 0x51, 0x05, 0xee, 0xe7, 0x8e, 0x41, 0x05, 0xeb, 0x34, 0xfe, 0xb8, 0xf7, 0x56, 0xca,
+	0xbc, 0x7e, 0x2b, 0x65, 0xde, 0xbf, 0x95, 0x84, 0xbf, 0x1f, 0x48, 0xc2, 0x17, 0x07, 0x92, 0xf0,
+	0xea, 0x40, 0x12, 0xf6, 0x0e, 0x24, 0xe1, 0x9b, 0x03, 0x49, 0xf8, 0xee, 0x40, 0xca, 0xbc, 0x3f,
+	0x90, 0x84, 0xff, 0xbc, 0x93, 0x32, 0x7b, 0xef, 0xa4, 0xcc, 0xeb, 0x77, 0x52, 0xe6, 0xcf, 0x85,
+	0x96, 0x69, 0xe0, 0x0e, 0xd9, 0x2a, 0xd0, 0x3f, 0x44, 0x2f, 0xfe, 0x38, 0x00, 0x0c, 0xb8, 0x49,
+	0x99, 0x7b, 0x15, 0x00, 0x00,
 }
 
 func (x MatchType) String() string {
@@ -2451,56 +2786,236 @@ func (this *TimeSeriesFile) Equal(that interface{}) bool {
 	}
 	return true
 }
-func (this *ReadRequest) GoString() string {
-	if this == nil {
-		return "nil"
-	}
-	s := make([]string, 0, 5)
-	s = append(s, "&client.ReadRequest{")
-	if this.Queries != nil {
-		s = append(s, "Queries: "+fmt.Sprintf("%#v", this.Queries)+",\n")
+func (this *MetricsUsageRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *ReadResponse) GoString() string {
-	if this == nil {
-		return "nil"
+
+	that1, ok := that.(*MetricsUsageRequest)
+	if !ok {
+		that2, ok := that.(MetricsUsageRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	s := make([]string, 0, 5)
-	s = append(s, "&client.ReadResponse{")
-	if this.Results != nil {
-		s = append(s, "Results: "+fmt.Sprintf("%#v", this.Results)+",\n")
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
+	return true
 }
-func (this *QueryResponse) GoString() string {
-	if this == nil {
-		return "nil"
+func (this *MetricsUsageResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
 	}
-	s := make([]string, 0, 5)
-	s = append(s, "&client.QueryResponse{")
-	if this.Timeseries != nil {
-		vs := make([]*cortexpb.TimeSeries, len(this.Timeseries))
-		for i := range vs {
-			vs[i] = &this.Timeseries[i]
+
+	that1, ok := that.(*MetricsUsageResponse)
+	if !ok {
+		that2, ok := that.(MetricsUsageResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
 		}
-		s = append(s, "Timeseries: "+fmt.Sprintf("%#v", vs)+",\n")
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *QueryRequest) GoString() string {
-	if this == nil {
-		return "nil"
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
 	}
-	s := make([]string, 0, 8)
-	s = append(s, "&client.QueryRequest{")
-	s = append(s, "StartTimestampMs: "+fmt.Sprintf("%#v", this.StartTimestampMs)+",\n")
-	s = append(s, "EndTimestampMs: "+fmt.Sprintf("%#v", this.EndTimestampMs)+",\n")
-	if this.Matchers != nil {
-		s = append(s, "Matchers: "+fmt.Sprintf("%#v", this.Matchers)+",\n")
+	if len(this.Metrics) != len(that1.Metrics) {
+		return false
+	}
+	for i := range this.Metrics {
+		if !this.Metrics[i].Equal(&that1.Metrics[i]) {
+			return false
+		}
+	}
+	if this.TrackedSinceTimestampMs != that1.TrackedSinceTimestampMs {
+		return false
+	}
+	return true
+}
+func (this *MetricUsage) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*MetricUsage)
+	if !ok {
+		that2, ok := that.(MetricUsage)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.MetricName != that1.MetricName {
+		return false
+	}
+	if this.NumSeries != that1.NumSeries {
+		return false
+	}
+	if this.LastQueriedTimestampMs != that1.LastQueriedTimestampMs {
+		return false
+	}
+	return true
+}
+func (this *OutOfOrderSeriesStatsRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*OutOfOrderSeriesStatsRequest)
+	if !ok {
+		that2, ok := that.(OutOfOrderSeriesStatsRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	return true
+}
+func (this *OutOfOrderSeriesStatsResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*OutOfOrderSeriesStatsResponse)
+	if !ok {
+		that2, ok := that.(OutOfOrderSeriesStatsResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Series) != len(that1.Series) {
+		return false
+	}
+	for i := range this.Series {
+		if !this.Series[i].Equal(&that1.Series[i]) {
+			return false
+		}
+	}
+	if this.TrackedSinceTimestampMs != that1.TrackedSinceTimestampMs {
+		return false
+	}
+	return true
+}
+func (this *OutOfOrderSeriesStats) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*OutOfOrderSeriesStats)
+	if !ok {
+		that2, ok := that.(OutOfOrderSeriesStats)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Labels) != len(that1.Labels) {
+		return false
+	}
+	for i := range this.Labels {
+		if !this.Labels[i].Equal(that1.Labels[i]) {
+			return false
+		}
+	}
+	if this.OutOfOrder != that1.OutOfOrder {
+		return false
+	}
+	if this.OutOfBounds != that1.OutOfBounds {
+		return false
+	}
+	if this.TooOld != that1.TooOld {
+		return false
+	}
+	if this.DuplicateTimestamp != that1.DuplicateTimestamp {
+		return false
+	}
+	if this.LastDiscardedTimestampMs != that1.LastDiscardedTimestampMs {
+		return false
+	}
+	return true
+}
+func (this *ReadRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&client.ReadRequest{")
+	if this.Queries != nil {
+		s = append(s, "Queries: "+fmt.Sprintf("%#v", this.Queries)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ReadResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&client.ReadResponse{")
+	if this.Results != nil {
+		s = append(s, "Results: "+fmt.Sprintf("%#v", this.Results)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *QueryResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&client.QueryResponse{")
+	if this.Timeseries != nil {
+		vs := make([]*cortexpb.TimeSeries, len(this.Timeseries))
+		for i := range vs {
+			vs[i] = &this.Timeseries[i]
+		}
+		s = append(s, "Timeseries: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *QueryRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 8)
+	s = append(s, "&client.QueryRequest{")
+	s = append(s, "StartTimestampMs: "+fmt.Sprintf("%#v", this.StartTimestampMs)+",\n")
+	s = append(s, "EndTimestampMs: "+fmt.Sprintf("%#v", this.EndTimestampMs)+",\n")
+	if this.Matchers != nil {
+		s = append(s, "Matchers: "+fmt.Sprintf("%#v", this.Matchers)+",\n")
 	}
 	s = append(s, "MessageWithBufRef: "+fmt.Sprintf("%#v", this.MessageWithBufRef)+",\n")
 	s = append(s, "}")
@@ -2804,6 +3319,85 @@ func (this *TimeSeriesFile) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *MetricsUsageRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 4)
+	s = append(s, "&client.MetricsUsageRequest{")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *MetricsUsageResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&client.MetricsUsageResponse{")
+	if this.Metrics != nil {
+		vs := make([]*MetricUsage, len(this.Metrics))
+		for i := range vs {
+			vs[i] = &this.Metrics[i]
+		}
+		s = append(s, "Metrics: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	s = append(s, "TrackedSinceTimestampMs: "+fmt.Sprintf("%#v", this.TrackedSinceTimestampMs)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *MetricUsage) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 7)
+	s = append(s, "&client.MetricUsage{")
+	s = append(s, "MetricName: "+fmt.Sprintf("%#v", this.MetricName)+",\n")
+	s = append(s, "NumSeries: "+fmt.Sprintf("%#v", this.NumSeries)+",\n")
+	s = append(s, "LastQueriedTimestampMs: "+fmt.Sprintf("%#v", this.LastQueriedTimestampMs)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *OutOfOrderSeriesStatsRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 4)
+	s = append(s, "&client.OutOfOrderSeriesStatsRequest{")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *OutOfOrderSeriesStatsResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&client.OutOfOrderSeriesStatsResponse{")
+	if this.Series != nil {
+		vs := make([]*OutOfOrderSeriesStats, len(this.Series))
+		for i := range vs {
+			vs[i] = &this.Series[i]
+		}
+		s = append(s, "Series: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	s = append(s, "TrackedSinceTimestampMs: "+fmt.Sprintf("%#v", this.TrackedSinceTimestampMs)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *OutOfOrderSeriesStats) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 10)
+	s = append(s, "&client.OutOfOrderSeriesStats{")
+	s = append(s, "Labels: "+fmt.Sprintf("%#v", this.Labels)+",\n")
+	s = append(s, "OutOfOrder: "+fmt.Sprintf("%#v", this.OutOfOrder)+",\n")
+	s = append(s, "OutOfBounds: "+fmt.Sprintf("%#v", this.OutOfBounds)+",\n")
+	s = append(s, "TooOld: "+fmt.Sprintf("%#v", this.TooOld)+",\n")
+	s = append(s, "DuplicateTimestamp: "+fmt.Sprintf("%#v", this.DuplicateTimestamp)+",\n")
+	s = append(s, "LastDiscardedTimestampMs: "+fmt.Sprintf("%#v", this.LastDiscardedTimestampMs)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func valueToGoStringIngester(v interface{}, typ string) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -2838,6 +3432,8 @@ type IngesterClient interface {
 	MetricsForLabelMatchers(ctx context.Context, in *MetricsForLabelMatchersRequest, opts ...grpc.CallOption) (*MetricsForLabelMatchersResponse, error)
 	MetricsForLabelMatchersStream(ctx context.Context, in *MetricsForLabelMatchersRequest, opts ...grpc.CallOption) (Ingester_MetricsForLabelMatchersStreamClient, error)
 	MetricsMetadata(ctx context.Context, in *MetricsMetadataRequest, opts ...grpc.CallOption) (*MetricsMetadataResponse, error)
+	MetricsUsage(ctx context.Context, in *MetricsUsageRequest, opts ...grpc.CallOption) (*MetricsUsageResponse, error)
+	OutOfOrderSeriesStats(ctx context.Context, in *OutOfOrderSeriesStatsRequest, opts ...grpc.CallOption) (*OutOfOrderSeriesStatsResponse, error)
 }
 
 type ingesterClient struct {
@@ -3079,6 +3675,24 @@ func (c *ingesterClient) MetricsMetadata(ctx context.Context, in *MetricsMetadat
 	return out, nil
 }
 
+func (c *ingesterClient) MetricsUsage(ctx context.Context, in *MetricsUsageRequest, opts ...grpc.CallOption) (*MetricsUsageResponse, error) {
+	out := new(MetricsUsageResponse)
+	err := c.cc.Invoke(ctx, "/cortex.Ingester/MetricsUsage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingesterClient) OutOfOrderSeriesStats(ctx context.Context, in *OutOfOrderSeriesStatsRequest, opts ...grpc.CallOption) (*OutOfOrderSeriesStatsResponse, error) {
+	out := new(OutOfOrderSeriesStatsResponse)
+	err := c.cc.Invoke(ctx, "/cortex.Ingester/OutOfOrderSeriesStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // IngesterServer is the server API for Ingester service.
 type IngesterServer interface {
 	Push(context.Context, *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error)
@@ -3094,6 +3708,8 @@ type IngesterServer interface {
 	MetricsForLabelMatchers(context.Context, *MetricsForLabelMatchersRequest) (*MetricsForLabelMatchersResponse, error)
 	MetricsForLabelMatchersStream(*MetricsForLabelMatchersRequest, Ingester_MetricsForLabelMatchersStreamServer) error
 	MetricsMetadata(context.Context, *MetricsMetadataRequest) (*MetricsMetadataResponse, error)
+	MetricsUsage(context.Context, *MetricsUsageRequest) (*MetricsUsageResponse, error)
+	OutOfOrderSeriesStats(context.Context, *OutOfOrderSeriesStatsRequest) (*OutOfOrderSeriesStatsResponse, error)
 }
 
 // UnimplementedIngesterServer can be embedded to have forward compatible implementations.
@@ -3139,6 +3755,12 @@ func (*UnimplementedIngesterServer) MetricsForLabelMatchersStream(req *MetricsFo
 func (*UnimplementedIngesterServer) MetricsMetadata(ctx context.Context, req *MetricsMetadataRequest) (*MetricsMetadataResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method MetricsMetadata not implemented")
 }
+func (*UnimplementedIngesterServer) MetricsUsage(ctx context.Context, req *MetricsUsageRequest) (*MetricsUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MetricsUsage not implemented")
+}
+func (*UnimplementedIngesterServer) OutOfOrderSeriesStats(ctx context.Context, req *OutOfOrderSeriesStatsRequest) (*OutOfOrderSeriesStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OutOfOrderSeriesStats not implemented")
+}
 
 func RegisterIngesterServer(s *grpc.Server, srv IngesterServer) {
 	s.RegisterService(&_Ingester_serviceDesc, srv)
@@ -3398,6 +4020,42 @@ func _Ingester_MetricsMetadata_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Ingester_MetricsUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MetricsUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngesterServer).MetricsUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cortex.Ingester/MetricsUsage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngesterServer).MetricsUsage(ctx, req.(*MetricsUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ingester_OutOfOrderSeriesStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OutOfOrderSeriesStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngesterServer).OutOfOrderSeriesStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cortex.Ingester/OutOfOrderSeriesStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngesterServer).OutOfOrderSeriesStats(ctx, req.(*OutOfOrderSeriesStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Ingester_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "cortex.Ingester",
 	HandlerType: (*IngesterServer)(nil),
@@ -3434,6 +4092,14 @@ var _Ingester_serviceDesc = grpc.ServiceDesc{
 			MethodName: "MetricsMetadata",
 			Handler:    _Ingester_MetricsMetadata_Handler,
 		},
+		{
+			MethodName: "MetricsUsage",
+			Handler:    _Ingester_MetricsUsage_Handler,
+		},
+		{
+			MethodName: "OutOfOrderSeriesStats",
+			Handler:    _Ingester_OutOfOrderSeriesStats_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -4593,6 +5259,238 @@ func (m *TimeSeriesFile) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *MetricsUsageRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MetricsUsageRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *MetricsUsageRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	return len(dAtA) - i, nil
+}
+
+func (m *MetricsUsageResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MetricsUsageResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *MetricsUsageResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.TrackedSinceTimestampMs != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.TrackedSinceTimestampMs))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Metrics) > 0 {
+		for iNdEx := len(m.Metrics) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Metrics[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *MetricUsage) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MetricUsage) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *MetricUsage) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.LastQueriedTimestampMs != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.LastQueriedTimestampMs))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.NumSeries != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.NumSeries))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.MetricName) > 0 {
+		i -= len(m.MetricName)
+		copy(dAtA[i:], m.MetricName)
+		i = encodeVarintIngester(dAtA, i, uint64(len(m.MetricName)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *OutOfOrderSeriesStatsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *OutOfOrderSeriesStatsRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *OutOfOrderSeriesStatsRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	return len(dAtA) - i, nil
+}
+
+func (m *OutOfOrderSeriesStatsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *OutOfOrderSeriesStatsResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *OutOfOrderSeriesStatsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.TrackedSinceTimestampMs != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.TrackedSinceTimestampMs))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Series) > 0 {
+		for iNdEx := len(m.Series) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Series[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *OutOfOrderSeriesStats) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *OutOfOrderSeriesStats) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *OutOfOrderSeriesStats) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.LastDiscardedTimestampMs != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.LastDiscardedTimestampMs))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.DuplicateTimestamp != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.DuplicateTimestamp))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.TooOld != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.TooOld))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.OutOfBounds != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.OutOfBounds))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.OutOfOrder != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.OutOfOrder))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Labels) > 0 {
+		for iNdEx := len(m.Labels) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size := m.Labels[iNdEx].Size()
+				i -= size
+				if _, err := m.Labels[iNdEx].MarshalTo(dAtA[i:]); err != nil {
+					return 0, err
+				}
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintIngester(dAtA []byte, offset int, v uint64) int {
 	offset -= sovIngester(v)
 	base := offset
@@ -5098,56 +5996,159 @@ func (m *TimeSeriesFile) Size() (n int) {
 	return n
 }
 
-func sovIngester(x uint64) (n int) {
-	return (math_bits.Len64(x|1) + 6) / 7
-}
-func sozIngester(x uint64) (n int) {
-	return sovIngester(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+func (m *MetricsUsageRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	return n
 }
-func (this *ReadRequest) String() string {
-	if this == nil {
-		return "nil"
+
+func (m *MetricsUsageResponse) Size() (n int) {
+	if m == nil {
+		return 0
 	}
-	repeatedStringForQueries := "[]*QueryRequest{"
-	for _, f := range this.Queries {
-		repeatedStringForQueries += strings.Replace(f.String(), "QueryRequest", "QueryRequest", 1) + ","
+	var l int
+	_ = l
+	if len(m.Metrics) > 0 {
+		for _, e := range m.Metrics {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
 	}
-	repeatedStringForQueries += "}"
-	s := strings.Join([]string{`&ReadRequest{`,
-		`Queries:` + repeatedStringForQueries + `,`,
-		`}`,
-	}, "")
-	return s
+	if m.TrackedSinceTimestampMs != 0 {
+		n += 1 + sovIngester(uint64(m.TrackedSinceTimestampMs))
+	}
+	return n
 }
-func (this *ReadResponse) String() string {
-	if this == nil {
-		return "nil"
+
+func (m *MetricUsage) Size() (n int) {
+	if m == nil {
+		return 0
 	}
-	repeatedStringForResults := "[]*QueryResponse{"
-	for _, f := range this.Results {
-		repeatedStringForResults += strings.Replace(f.String(), "QueryResponse", "QueryResponse", 1) + ","
+	var l int
+	_ = l
+	l = len(m.MetricName)
+	if l > 0 {
+		n += 1 + l + sovIngester(uint64(l))
 	}
-	repeatedStringForResults += "}"
-	s := strings.Join([]string{`&ReadResponse{`,
-		`Results:` + repeatedStringForResults + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *QueryResponse) String() string {
-	if this == nil {
-		return "nil"
+	if m.NumSeries != 0 {
+		n += 1 + sovIngester(uint64(m.NumSeries))
 	}
-	repeatedStringForTimeseries := "[]TimeSeries{"
-	for _, f := range this.Timeseries {
-		repeatedStringForTimeseries += fmt.Sprintf("%v", f) + ","
+	if m.LastQueriedTimestampMs != 0 {
+		n += 1 + sovIngester(uint64(m.LastQueriedTimestampMs))
 	}
-	repeatedStringForTimeseries += "}"
-	s := strings.Join([]string{`&QueryResponse{`,
-		`Timeseries:` + repeatedStringForTimeseries + `,`,
-		`}`,
-	}, "")
-	return s
+	return n
+}
+
+func (m *OutOfOrderSeriesStatsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	return n
+}
+
+func (m *OutOfOrderSeriesStatsResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Series) > 0 {
+		for _, e := range m.Series {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	if m.TrackedSinceTimestampMs != 0 {
+		n += 1 + sovIngester(uint64(m.TrackedSinceTimestampMs))
+	}
+	return n
+}
+
+func (m *OutOfOrderSeriesStats) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Labels) > 0 {
+		for _, e := range m.Labels {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	if m.OutOfOrder != 0 {
+		n += 1 + sovIngester(uint64(m.OutOfOrder))
+	}
+	if m.OutOfBounds != 0 {
+		n += 1 + sovIngester(uint64(m.OutOfBounds))
+	}
+	if m.TooOld != 0 {
+		n += 1 + sovIngester(uint64(m.TooOld))
+	}
+	if m.DuplicateTimestamp != 0 {
+		n += 1 + sovIngester(uint64(m.DuplicateTimestamp))
+	}
+	if m.LastDiscardedTimestampMs != 0 {
+		n += 1 + sovIngester(uint64(m.LastDiscardedTimestampMs))
+	}
+	return n
+}
+
+func sovIngester(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozIngester(x uint64) (n int) {
+	return sovIngester(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *ReadRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForQueries := "[]*QueryRequest{"
+	for _, f := range this.Queries {
+		repeatedStringForQueries += strings.Replace(f.String(), "QueryRequest", "QueryRequest", 1) + ","
+	}
+	repeatedStringForQueries += "}"
+	s := strings.Join([]string{`&ReadRequest{`,
+		`Queries:` + repeatedStringForQueries + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ReadResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForResults := "[]*QueryResponse{"
+	for _, f := range this.Results {
+		repeatedStringForResults += strings.Replace(f.String(), "QueryResponse", "QueryResponse", 1) + ","
+	}
+	repeatedStringForResults += "}"
+	s := strings.Join([]string{`&ReadResponse{`,
+		`Results:` + repeatedStringForResults + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *QueryResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForTimeseries := "[]TimeSeries{"
+	for _, f := range this.Timeseries {
+		repeatedStringForTimeseries += fmt.Sprintf("%v", f) + ","
+	}
+	repeatedStringForTimeseries += "}"
+	s := strings.Join([]string{`&QueryResponse{`,
+		`Timeseries:` + repeatedStringForTimeseries + `,`,
+		`}`,
+	}, "")
+	return s
 }
 func (this *QueryRequest) String() string {
 	if this == nil {
@@ -5477,6 +6478,83 @@ func (this *TimeSeriesFile) String() string {
 	}, "")
 	return s
 }
+func (this *MetricsUsageRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&MetricsUsageRequest{`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *MetricsUsageResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForMetrics := "[]MetricUsage{"
+	for _, f := range this.Metrics {
+		repeatedStringForMetrics += strings.Replace(strings.Replace(f.String(), "MetricUsage", "MetricUsage", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForMetrics += "}"
+	s := strings.Join([]string{`&MetricsUsageResponse{`,
+		`Metrics:` + repeatedStringForMetrics + `,`,
+		`TrackedSinceTimestampMs:` + fmt.Sprintf("%v", this.TrackedSinceTimestampMs) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *MetricUsage) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&MetricUsage{`,
+		`MetricName:` + fmt.Sprintf("%v", this.MetricName) + `,`,
+		`NumSeries:` + fmt.Sprintf("%v", this.NumSeries) + `,`,
+		`LastQueriedTimestampMs:` + fmt.Sprintf("%v", this.LastQueriedTimestampMs) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *OutOfOrderSeriesStatsRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&OutOfOrderSeriesStatsRequest{`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *OutOfOrderSeriesStatsResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForSeries := "[]OutOfOrderSeriesStats{"
+	for _, f := range this.Series {
+		repeatedStringForSeries += strings.Replace(strings.Replace(f.String(), "OutOfOrderSeriesStats", "OutOfOrderSeriesStats", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForSeries += "}"
+	s := strings.Join([]string{`&OutOfOrderSeriesStatsResponse{`,
+		`Series:` + repeatedStringForSeries + `,`,
+		`TrackedSinceTimestampMs:` + fmt.Sprintf("%v", this.TrackedSinceTimestampMs) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *OutOfOrderSeriesStats) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&OutOfOrderSeriesStats{`,
+		`Labels:` + fmt.Sprintf("%v", this.Labels) + `,`,
+		`OutOfOrder:` + fmt.Sprintf("%v", this.OutOfOrder) + `,`,
+		`OutOfBounds:` + fmt.Sprintf("%v", this.OutOfBounds) + `,`,
+		`TooOld:` + fmt.Sprintf("%v", this.TooOld) + `,`,
+		`DuplicateTimestamp:` + fmt.Sprintf("%v", this.DuplicateTimestamp) + `,`,
+		`LastDiscardedTimestampMs:` + fmt.Sprintf("%v", this.LastDiscardedTimestampMs) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func valueToStringIngester(v interface{}) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -8534,6 +9612,629 @@ func (m *TimeSeriesFile) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *MetricsUsageRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MetricsUsageRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MetricsUsageRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MetricsUsageResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MetricsUsageResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MetricsUsageResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metrics", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Metrics = append(m.Metrics, MetricUsage{})
+			if err := m.Metrics[len(m.Metrics)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TrackedSinceTimestampMs", wireType)
+			}
+			m.TrackedSinceTimestampMs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TrackedSinceTimestampMs |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MetricUsage) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MetricUsage: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MetricUsage: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MetricName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.MetricName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NumSeries", wireType)
+			}
+			m.NumSeries = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.NumSeries |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LastQueriedTimestampMs", wireType)
+			}
+			m.LastQueriedTimestampMs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.LastQueriedTimestampMs |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *OutOfOrderSeriesStatsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: OutOfOrderSeriesStatsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: OutOfOrderSeriesStatsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *OutOfOrderSeriesStatsResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: OutOfOrderSeriesStatsResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: OutOfOrderSeriesStatsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Series", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Series = append(m.Series, OutOfOrderSeriesStats{})
+			if err := m.Series[len(m.Series)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TrackedSinceTimestampMs", wireType)
+			}
+			m.TrackedSinceTimestampMs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TrackedSinceTimestampMs |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *OutOfOrderSeriesStats) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: OutOfOrderSeriesStats: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: OutOfOrderSeriesStats: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Labels", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Labels = append(m.Labels, github_com_cortexproject_cortex_pkg_cortexpb.LabelAdapter{})
+			if err := m.Labels[len(m.Labels)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OutOfOrder", wireType)
+			}
+			m.OutOfOrder = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.OutOfOrder |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OutOfBounds", wireType)
+			}
+			m.OutOfBounds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.OutOfBounds |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TooOld", wireType)
+			}
+			m.TooOld = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TooOld |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DuplicateTimestamp", wireType)
+			}
+			m.DuplicateTimestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DuplicateTimestamp |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LastDiscardedTimestampMs", wireType)
+			}
+			m.LastDiscardedTimestampMs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.LastDiscardedTimestampMs |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipIngester(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -63,6 +63,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/util/limiter"
 	logutil "github.com/cortexproject/cortex/pkg/util/log"
 	util_math "github.com/cortexproject/cortex/pkg/util/math"
+	"github.com/cortexproject/cortex/pkg/util/queriedmetrics"
 	"github.com/cortexproject/cortex/pkg/util/resource"
 	"github.com/cortexproject/cortex/pkg/util/services"
 	"github.com/cortexproject/cortex/pkg/util/spanlogger"
@@ -109,6 +110,9 @@ var (
 	errNoUserDb         = errors.New("no user db")
 	errLabelsOutOfOrder = errors.New("labels out of order")
 
+	errQueriedMetricsTrackingDisabled = errors.New("queried metrics tracking is disabled, it requires -ingester.active-queried-series-metrics-enabled")
+	errOutOfOrderSeriesStatsDisabled  = errors.New("out-of-order series stats are disabled, they require -ingester.out-of-order-series-stats-enabled")
+
 	tsChunksPool zeropool.Pool[[]client.TimeSeriesChunk]
 )
 
@@ -131,6 +135,12 @@ type Config struct {
 	ActiveQueriedSeriesMetricsWindowDuration time.Duration            `yaml:"active_queried_series_metrics_window_duration"`
 	ActiveQueriedSeriesMetricsSampleRate     float64                  `yaml:"active_queried_series_metrics_sample_rate"`
 	ActiveQueriedSeriesMetricsWindows        cortex_tsdb.DurationList `yaml:"active_queried_series_metrics_windows"`
+	QueriedMetricsMaxMetrics                 int                      `yaml:"queried_metrics_max_metrics"`
+
+	OutOfOrderSeriesStatsEnabled     bool                   `yaml:"out_of_order_series_stats_enabled"`
+	OutOfOrderSeriesStatsMaxSeries   int                    `yaml:"out_of_order_series_stats_max_series"`
+	OutOfOrderSeriesStatsLabels      flagext.StringSliceCSV `yaml:"out_of_order_series_stats_labels"`
+	OutOfOrderSeriesStatsIdleTimeout time.Duration          `yaml:"out_of_order_series_stats_idle_timeout"`
 
 	// Use blocks storage.
 	BlocksStorageConfig cortex_tsdb.BlocksStorageConfig `yaml:"-"`
@@ -200,6 +210,13 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.Float64Var(&cfg.ActiveQueriedSeriesMetricsSampleRate, "ingester.active-queried-series-metrics-sample-rate", 1.0, "Sampling rate for active queried series tracking (1.0 = 100% sampling, 0.1 = 10% sampling). By default, all queries are sampled.")
 	cfg.ActiveQueriedSeriesMetricsWindows = cortex_tsdb.DurationList{2 * time.Hour}
 	f.Var(&cfg.ActiveQueriedSeriesMetricsWindows, "ingester.active-queried-series-metrics-windows", "Time windows to expose queried series metric. Each window tracks queried series within that time period.")
+	f.IntVar(&cfg.QueriedMetricsMaxMetrics, "ingester.queried-metrics-max-metrics", 100000, "The maximum number of queried metric names tracked per tenant, when the active queried series metrics are enabled. Once reached, the least recently queried metric names are evicted. 0 to disable the limit.")
+
+	cfg.OutOfOrderSeriesStatsLabels = []string{"job", "instance"}
+	f.BoolVar(&cfg.OutOfOrderSeriesStatsEnabled, "ingester.out-of-order-series-stats-enabled", false, "[Experimental] Enable tracking of the series producing the most out-of-order, out-of-bounds, too old and duplicate timestamp samples per tenant, exposed by the out-of-order series API.")
+	f.IntVar(&cfg.OutOfOrderSeriesStatsMaxSeries, "ingester.out-of-order-series-stats-max-series", 100, "Maximum number of groups of series tracked per tenant. When reached, the group with the fewest discarded samples is replaced.")
+	f.Var(&cfg.OutOfOrderSeriesStatsLabels, "ingester.out-of-order-series-stats-labels", "Comma-separated list of labels the series are grouped by, in addition to the metric name. The labels should identify the exporter sending the series.")
+	f.DurationVar(&cfg.OutOfOrderSeriesStatsIdleTimeout, "ingester.out-of-order-series-stats-idle-timeout", time.Hour, "After what time without discarded samples a group of series is no longer tracked.")
 
 	f.BoolVar(&cfg.UploadCompactedBlocksEnabled, "ingester.upload-compacted-blocks-enabled", true, "Enable uploading compacted blocks.")
 	f.StringVar(&cfg.IgnoreSeriesLimitForMetricNames, "ingester.ignore-series-limit-for-metric-names", "", "Comma-separated list of metric names, for which -ingester.max-series-per-metric and -ingester.max-global-series-per-metric limits will be ignored. Does not affect max-series-per-user or max-global-series-per-metric limits.")
@@ -228,6 +245,10 @@ func (cfg *Config) Validate(monitoredResources flagext.StringSliceCSV) error {
 		return err
 	}
 
+	if cfg.OutOfOrderSeriesStatsEnabled && cfg.OutOfOrderSeriesStatsMaxSeries <= 0 {
+		return fmt.Errorf("out-of-order series stats max series must be > 0, got %d", cfg.OutOfOrderSeriesStatsMaxSeries)
+	}
+
 	// Validate active queried series metrics windows
 	if cfg.ActiveQueriedSeriesMetricsEnabled {
 		if len(cfg.ActiveQueriedSeriesMetricsWindows) == 0 {
@@ -366,6 +387,8 @@ type userTSDB struct {
 	userID              string
 	activeSeries        *ActiveSeries
 	activeQueriedSeries *ActiveQueriedSeries
+	queriedMetrics      *queriedmetrics.Tracker
+	outOfOrderStats     *outOfOrderSeriesStats
 	seriesInMetric      *metricCounter
 	labelSetCounter     *labelSetCounter
 	limiter             *Limiter
@@ -1071,6 +1094,7 @@ func (i *Ingester) updateLoop(ctx context.Context) error {
 		select {
 		case <-metadataPurgeTicker.C:
 			i.purgeUserMetricsMetadata()
+			i.purgeOutOfOrderSeriesStats()
 		case <-ingestionRateTicker.C:
 			i.ingestionRate.Tick()
 		case <-rateUpdateTicker.C:
@@ -1367,21 +1391,33 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 			case errors.Is(cause, storage.ErrOutOfBounds):
 				sampleOutOfBoundsCount++
 				i.validateMetrics.DiscardedSeriesTracker.Track(sampleOutOfBounds, userID, copiedLabels.Hash())
+				if db.outOfOrderStats != nil {
+					db.outOfOrderStats.track(copiedLabels, sampleOutOfBounds, startAppend)
+				}
 				updateFirstPartial(func() error { return wrappedTSDBIngestErr(err, model.Time(timestampMs), lbls) })
 
 			case errors.Is(cause, storage.ErrOutOfOrderSample):
 				sampleOutOfOrderCount++
 				i.validateMetrics.DiscardedSeriesTracker.Track(sampleOutOfOrder, userID, copiedLabels.Hash())
+				if db.outOfOrderStats != nil {
+					db.outOfOrderStats.track(copiedLabels, sampleOutOfOrder, startAppend)
+				}
 				updateFirstPartial(func() error { return wrappedTSDBIngestErr(err, model.Time(timestampMs), lbls) })
 
 			case errors.Is(cause, storage.ErrDuplicateSampleForTimestamp):
 				newValueForTimestampCount++
 				i.validateMetrics.DiscardedSeriesTracker.Track(newValueForTimestamp, userID, copiedLabels.Hash())
+				if db.outOfOrderStats != nil {
+					db.outOfOrderStats.track(copiedLabels, newValueForTimestamp, startAppend)
+				}
 				updateFirstPartial(func() error { return wrappedTSDBIngestErr(err, model.Time(timestampMs), lbls) })
 
 			case errors.Is(cause, storage.ErrTooOldSample):
 				sampleTooOldCount++
 				i.validateMetrics.DiscardedSeriesTracker.Track(sampleTooOld, userID, copiedLabels.Hash())
+				if db.outOfOrderStats != nil {
+					db.outOfOrderStats.track(copiedLabels, sampleTooOld, startAppend)
+				}
 				updateFirstPartial(func() error { return wrappedTSDBIngestErr(err, model.Time(timestampMs), lbls) })
 
 			case errors.Is(cause, errMaxSeriesPerUserLimitExceeded):
@@ -2308,6 +2344,75 @@ func (i *Ingester) UserStats(ctx context.Context, req *client.UserStatsRequest)
 	}, nil
 }
 
+// MetricsUsage returns the number of in-memory series of each metric name of the current user, along
+// with the last time each metric name has been queried.
+func (i *Ingester) MetricsUsage(ctx context.Context, _ *client.MetricsUsageRequest) (*client.MetricsUsageResponse, error) {
+	if err := i.checkRunning(); err != nil {
+		return nil, err
+	}
+
+	if !i.cfg.ActiveQueriedSeriesMetricsEnabled {
+		return nil, errQueriedMetricsTrackingDisabled
+	}
+
+	userID, err := users.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := i.getTSDB(userID)
+	if err != nil || db == nil {
+		return &client.MetricsUsageResponse{}, nil
+	}
+
+	lastQueried := db.queriedMetrics.LastQueried()
+	seriesPerMetric := db.seriesInMetric.seriesPerMetric()
+
+	resp := &client.MetricsUsageResponse{
+		Metrics:                 make([]client.MetricUsage, 0, len(seriesPerMetric)),
+		TrackedSinceTimestampMs: db.queriedMetrics.Since().UnixMilli(),
+	}
+	for name, numSeries := range seriesPerMetric {
+		usage := client.MetricUsage{
+			MetricName: name,
+			NumSeries:  uint64(numSeries),
+		}
+		if last, ok := lastQueried[name]; ok {
+			usage.LastQueriedTimestampMs = last.UnixMilli()
+		}
+		resp.Metrics = append(resp.Metrics, usage)
+	}
+
+	return resp, nil
+}
+
+// OutOfOrderSeriesStats returns the groups of series of the current user producing the most out-of-order,
+// out-of-bounds, too old and duplicate timestamp samples.
+func (i *Ingester) OutOfOrderSeriesStats(ctx context.Context, _ *client.OutOfOrderSeriesStatsRequest) (*client.OutOfOrderSeriesStatsResponse, error) {
+	if err := i.checkRunning(); err != nil {
+		return nil, err
+	}
+
+	if !i.cfg.OutOfOrderSeriesStatsEnabled {
+		return nil, errOutOfOrderSeriesStatsDisabled
+	}
+
+	userID, err := users.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := i.getTSDB(userID)
+	if err != nil || db == nil || db.outOfOrderStats == nil {
+		return &client.OutOfOrderSeriesStatsResponse{}, nil
+	}
+
+	return &client.OutOfOrderSeriesStatsResponse{
+		Series:                  db.outOfOrderStats.stats(),
+		TrackedSinceTimestampMs: db.outOfOrderStats.since.UnixMilli(),
+	}, nil
+}
+
 func (i *Ingester) userStats() []UserIDStats {
 	i.stoppedMtx.RLock()
 	defer i.stoppedMtx.RUnlock()
@@ -2629,6 +2734,13 @@ func (i *Ingester) queryStreamChunks(ctx context.Context, userID string, db *use
 		}
 	}
 
+	// Queried metric names are tracked for every request, regardless of the sampling,
+	// because a metric name missed would be wrongly reported as unused.
+	var queriedMetricNames map[string]struct{}
+	if db.queriedMetrics != nil {
+		queriedMetricNames = map[string]struct{}{}
+	}
+
 	for ss.Next() {
 		series := ss.At()
 		lbls := series.Labels()
@@ -2646,6 +2758,9 @@ func (i *Ingester) queryStreamChunks(ctx context.Context, userID string, db *use
 			hash := lbls.Hash()
 			queriedSeriesHashes = append(queriedSeriesHashes, hash)
 		}
+		if queriedMetricNames != nil {
+			queriedMetricNames[lbls.Get(labels.MetricName)] = struct{}{}
+		}
 
 		// convert labels to LabelAdapter
 		ts := client.TimeSeriesChunk{
@@ -2716,6 +2831,10 @@ func (i *Ingester) queryStreamChunks(ctx context.Context, userID string, db *use
 		i.activeQueriedSeriesService.UpdateSeriesBatch(db.activeQueriedSeries, queriedSeriesHashes, now, db.userID)
 	}
 
+	if db.queriedMetrics != nil {
+		db.queriedMetrics.Track(queriedMetricNames, now)
+	}
+
 	// Final flush any existing metrics
 	if batchSizeBytes != 0 {
 		err = client.SendQueryStream(stream, &client.QueryStreamResponse{
@@ -2836,7 +2955,10 @@ func (i *Ingester) createTSDB(userID string) (*userTSDB, error) {
 		postingCache = i.expandedPostingsCacheFactory.NewExpandedPostingsCache(userID, i.metrics.expandedPostingsCacheMetrics)
 	}
 
-	var activeQueriedSeries *ActiveQueriedSeries
+	var (
+		activeQueriedSeries *ActiveQueriedSeries
+		queriedMetrics      *queriedmetrics.Tracker
+	)
 	if i.cfg.ActiveQueriedSeriesMetricsEnabled {
 		activeQueriedSeries = NewActiveQueriedSeries(
 			i.cfg.ActiveQueriedSeriesMetricsWindows,
@@ -2844,12 +2966,20 @@ func (i *Ingester) createTSDB(userID string) (*userTSDB, error) {
 			i.cfg.ActiveQueriedSeriesMetricsSampleRate,
 			i.logger,
 		)
+		queriedMetrics = queriedmetrics.NewTracker(time.Now(), i.cfg.QueriedMetricsMaxMetrics)
+	}
+
+	var outOfOrderStats *outOfOrderSeriesStats
+	if i.cfg.OutOfOrderSeriesStatsEnabled {
+		outOfOrderStats = newOutOfOrderSeriesStats(i.cfg.OutOfOrderSeriesStatsLabels, i.cfg.OutOfOrderSeriesStatsMaxSeries, time.Now())
 	}
 
 	userDB := &userTSDB{
 		userID:              userID,
 		activeSeries:        NewActiveSeries(),
 		activeQueriedSeries: activeQueriedSeries,
+		queriedMetrics:      queriedMetrics,
+		outOfOrderStats:     outOfOrderStats,
 		seriesInMetric:      newMetricCounter(i.limiter, i.cfg.getIgnoreSeriesLimitForMetricNamesMap()),
 		labelSetCounter:     newLabelSetCounter(i.limiter),
 		ingestedAPISamples:  util_math.NewEWMARate(0.2, i.cfg.RateUpdatePeriod),
@@ -3542,6 +3672,22 @@ func (i *Ingester) purgeUserMetricsMetadata() {
 	}
 }
 
+func (i *Ingester) purgeOutOfOrderSeriesStats() {
+	if !i.cfg.OutOfOrderSeriesStatsEnabled {
+		return
+	}
+
+	deadline := time.Now().Add(-i.cfg.OutOfOrderSeriesStatsIdleTimeout)
+	for _, userID := range i.getTSDBUsers() {
+		userDB, err := i.getTSDB(userID)
+		if err != nil || userDB == nil || userDB.outOfOrderStats == nil {
+			continue
+		}
+
+		userDB.outOfOrderStats.purge(deadline)
+	}
+}
+
 // This method will flush all data. It is called as part of Lifecycler's shutdown (if flush on shutdown is configured), or from the flusher.
 //
 // When called as during Lifecycler shutdown, this happens as part of normal Ingester shutdown (see stopping method).
@@ -7683,3 +7683,136 @@ func TestIngester_checkRegexMatcherLimits(t *testing.T) {
 		})
 	}
 }
+
+func TestIngester_MetricsUsage(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.LifecyclerConfig.JoinAfter = 0
+	cfg.ActiveQueriedSeriesMetricsEnabled = true
+	cfg.ActiveQueriedSeriesMetricsWindowDuration = time.Minute
+	cfg.ActiveQueriedSeriesMetricsSampleRate = 0.0001 // Queried metric names should be tracked regardless of the sampling.
+	cfg.ActiveQueriedSeriesMetricsWindows = cortex_tsdb.DurationList{time.Hour}
+
+	i, err := prepareIngesterWithBlocksStorage(t, cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	// Wait until the ingester is ACTIVE
+	test.Poll(t, 100*time.Millisecond, ring.ACTIVE, func() any {
+		return i.lifecycler.GetState()
+	})
+
+	ctx := user.InjectOrgID(context.Background(), "test-user")
+	startTime := time.Now()
+
+	for _, lbls := range []labels.Labels{
+		labels.FromStrings(labels.MetricName, "queried_metric", "series", "1"),
+		labels.FromStrings(labels.MetricName, "queried_metric", "series", "2"),
+		labels.FromStrings(labels.MetricName, "unused_metric", "series", "1"),
+	} {
+		req, _ := mockWriteRequest(t, lbls, 1, startTime.UnixMilli())
+		_, err := i.Push(ctx, req)
+		require.NoError(t, err)
+	}
+
+	s := &mockQueryStreamServer{ctx: ctx}
+	require.NoError(t, i.QueryStream(&client.QueryRequest{
+		StartTimestampMs: startTime.Add(-time.Hour).UnixMilli(),
+		EndTimestampMs:   startTime.Add(time.Hour).UnixMilli(),
+		Matchers:         []*client.LabelMatcher{{Type: client.EQUAL, Name: labels.MetricName, Value: "queried_metric"}},
+	}, s))
+	require.Len(t, s.series, 2)
+
+	res, err := i.MetricsUsage(ctx, &client.MetricsUsageRequest{})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, res.TrackedSinceTimestampMs, startTime.UnixMilli())
+
+	usage := map[string]client.MetricUsage{}
+	for _, m := range res.Metrics {
+		usage[m.MetricName] = m
+	}
+	require.Len(t, usage, 2)
+	assert.Equal(t, uint64(2), usage["queried_metric"].NumSeries)
+	assert.GreaterOrEqual(t, usage["queried_metric"].LastQueriedTimestampMs, startTime.UnixMilli())
+	assert.Equal(t, uint64(1), usage["unused_metric"].NumSeries)
+	assert.Zero(t, usage["unused_metric"].LastQueriedTimestampMs)
+
+	// A tenant without data has no metrics.
+	res, err = i.MetricsUsage(user.InjectOrgID(context.Background(), "another-user"), &client.MetricsUsageRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, res.Metrics)
+}
+
+func TestIngester_OutOfOrderSeriesStats(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.LifecyclerConfig.JoinAfter = 0
+	cfg.OutOfOrderSeriesStatsEnabled = true
+	cfg.OutOfOrderSeriesStatsMaxSeries = 10
+	cfg.OutOfOrderSeriesStatsLabels = []string{"job"}
+
+	i, err := prepareIngesterWithBlocksStorage(t, cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	// Wait until the ingester is ACTIVE
+	test.Poll(t, 100*time.Millisecond, ring.ACTIVE, func() any {
+		return i.lifecycler.GetState()
+	})
+
+	ctx := user.InjectOrgID(context.Background(), "test-user")
+	startTime := time.Now()
+	ts := startTime.UnixMilli()
+
+	for _, sample := range []struct {
+		lbls      labels.Labels
+		value     float64
+		timestamp int64
+		expectErr bool
+	}{
+		{lbls: labels.FromStrings(labels.MetricName, "up", "job", "a", "pod", "1"), value: 1, timestamp: ts},
+		{lbls: labels.FromStrings(labels.MetricName, "up", "job", "a", "pod", "1"), value: 1, timestamp: ts - 1, expectErr: true},
+		{lbls: labels.FromStrings(labels.MetricName, "up", "job", "a", "pod", "2"), value: 1, timestamp: ts},
+		{lbls: labels.FromStrings(labels.MetricName, "up", "job", "a", "pod", "2"), value: 2, timestamp: ts, expectErr: true},
+		{lbls: labels.FromStrings(labels.MetricName, "up", "job", "b"), value: 1, timestamp: ts},
+	} {
+		req, _ := mockWriteRequest(t, sample.lbls, sample.value, sample.timestamp)
+		_, err := i.Push(ctx, req)
+		if sample.expectErr {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+
+	res, err := i.OutOfOrderSeriesStats(ctx, &client.OutOfOrderSeriesStatsRequest{})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, res.TrackedSinceTimestampMs, startTime.UnixMilli())
+	require.Len(t, res.Series, 1)
+	assert.Equal(t, cortexpb.FromLabelsToLabelAdapters(labels.FromStrings(labels.MetricName, "up", "job", "a")), res.Series[0].Labels)
+	assert.Equal(t, uint64(1), res.Series[0].OutOfOrder)
+	assert.Equal(t, uint64(1), res.Series[0].DuplicateTimestamp)
+	assert.GreaterOrEqual(t, res.Series[0].LastDiscardedTimestampMs, startTime.UnixMilli())
+
+	// A tenant without data has no series.
+	res, err = i.OutOfOrderSeriesStats(user.InjectOrgID(context.Background(), "another-user"), &client.OutOfOrderSeriesStatsRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, res.Series)
+}
+
+func TestIngester_OutOfOrderSeriesStats_Disabled(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.LifecyclerConfig.JoinAfter = 0
+
+	i, err := prepareIngesterWithBlocksStorage(t, cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	test.Poll(t, 100*time.Millisecond, ring.ACTIVE, func() any {
+		return i.lifecycler.GetState()
+	})
+
+	_, err = i.OutOfOrderSeriesStats(user.InjectOrgID(context.Background(), "test-user"), &client.OutOfOrderSeriesStatsRequest{})
+	require.ErrorIs(t, err, errOutOfOrderSeriesStatsDisabled)
+}
@@ -0,0 +1,170 @@
+package ingester
+
+import (
+	"container/heap"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+// outOfOrderSeriesStats keeps track of the groups of series producing the most samples discarded
+// because they are out of order, out of bounds, too old or have a duplicate timestamp. The series are
+// grouped by metric name and the configured labels, so that a group identifies the exporter sending them.
+//
+// The number of tracked groups is bounded with the space-saving algorithm: when the tracker is full,
+// the group with the fewest discarded samples is replaced by the new one, which inherits its count.
+// This way, a group frequently discarded is never evicted by a stream of groups rarely discarded.
+type outOfOrderSeriesStats struct {
+	since     time.Time
+	names     []string
+	maxGroups int
+
+	mtx    sync.Mutex
+	groups map[uint64]*outOfOrderSeriesGroup
+	heap   outOfOrderSeriesHeap
+	buf    []byte
+}
+
+type outOfOrderSeriesGroup struct {
+	hash   uint64
+	labels labels.Labels
+
+	outOfOrder         uint64
+	outOfBounds        uint64
+	tooOld             uint64
+	duplicateTimestamp uint64
+	lastDiscarded      time.Time
+
+	// priority is the number of discarded samples of the group, including the ones inherited
+	// from the group it replaced.
+	priority uint64
+	index    int
+}
+
+func newOutOfOrderSeriesStats(groupByLabels []string, maxGroups int, since time.Time) *outOfOrderSeriesStats {
+	// The labels must be sorted to be hashed.
+	names := append([]string{labels.MetricName}, groupByLabels...)
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	return &outOfOrderSeriesStats{
+		since:     since,
+		names:     names,
+		maxGroups: maxGroups,
+		groups:    map[uint64]*outOfOrderSeriesGroup{},
+	}
+}
+
+// track records a sample of the input series discarded for the input reason.
+func (s *outOfOrderSeriesStats) track(lbls labels.Labels, reason string, now time.Time) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var hash uint64
+	hash, s.buf = lbls.HashForLabels(s.buf, s.names...)
+
+	g, ok := s.groups[hash]
+	if !ok {
+		g = &outOfOrderSeriesGroup{hash: hash, labels: lbls.MatchLabels(true, s.names...)}
+		if len(s.heap) >= s.maxGroups {
+			evicted := s.heap[0]
+			delete(s.groups, evicted.hash)
+			g.priority = evicted.priority
+			g.index = 0
+			s.heap[0] = g
+		} else {
+			heap.Push(&s.heap, g)
+		}
+		s.groups[hash] = g
+	}
+
+	switch reason {
+	case sampleOutOfOrder:
+		g.outOfOrder++
+	case sampleOutOfBounds:
+		g.outOfBounds++
+	case sampleTooOld:
+		g.tooOld++
+	case newValueForTimestamp:
+		g.duplicateTimestamp++
+	}
+	g.lastDiscarded = now
+	g.priority++
+	heap.Fix(&s.heap, g.index)
+}
+
+// purge removes the groups whose samples have not been discarded since the input time.
+func (s *outOfOrderSeriesStats) purge(before time.Time) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for hash, g := range s.groups {
+		if g.lastDiscarded.Before(before) {
+			heap.Remove(&s.heap, g.index)
+			delete(s.groups, hash)
+		}
+	}
+}
+
+// stats returns the tracked groups, sorted by number of discarded samples in descending order.
+func (s *outOfOrderSeriesStats) stats() []client.OutOfOrderSeriesStats {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	out := make([]client.OutOfOrderSeriesStats, 0, len(s.groups))
+	for _, g := range s.groups {
+		out = append(out, client.OutOfOrderSeriesStats{
+			Labels:                   cortexpb.FromLabelsToLabelAdapters(g.labels),
+			OutOfOrder:               g.outOfOrder,
+			OutOfBounds:              g.outOfBounds,
+			TooOld:                   g.tooOld,
+			DuplicateTimestamp:       g.duplicateTimestamp,
+			LastDiscardedTimestampMs: g.lastDiscarded.UnixMilli(),
+		})
+	}
+	sortOutOfOrderSeriesStats(out)
+	return out
+}
+
+// sortOutOfOrderSeriesStats sorts the input stats by number of discarded samples in descending order.
+func sortOutOfOrderSeriesStats(stats []client.OutOfOrderSeriesStats) {
+	sort.Slice(stats, func(i, j int) bool {
+		if ti, tj := stats[i].Total(), stats[j].Total(); ti != tj {
+			return ti > tj
+		}
+		return labels.Compare(cortexpb.FromLabelAdaptersToLabels(stats[i].Labels), cortexpb.FromLabelAdaptersToLabels(stats[j].Labels)) < 0
+	})
+}
+
+// outOfOrderSeriesHeap is a min-heap of the groups by priority.
+type outOfOrderSeriesHeap []*outOfOrderSeriesGroup
+
+func (h outOfOrderSeriesHeap) Len() int           { return len(h) }
+func (h outOfOrderSeriesHeap) Less(i, j int) bool { return h[i].priority < h[j].priority }
+
+func (h outOfOrderSeriesHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *outOfOrderSeriesHeap) Push(x any) {
+	g := x.(*outOfOrderSeriesGroup)
+	g.index = len(*h)
+	*h = append(*h, g)
+}
+
+func (h *outOfOrderSeriesHeap) Pop() any {
+	old := *h
+	n := len(old)
+	g := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return g
+}
@@ -0,0 +1,74 @@
+package ingester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+func TestOutOfOrderSeriesStats(t *testing.T) {
+	now := time.Now()
+	s := newOutOfOrderSeriesStats([]string{"job", "instance"}, 10, now)
+
+	// The series are grouped by metric name and the configured labels.
+	s.track(labels.FromStrings(labels.MetricName, "up", "job", "a", "instance", "1", "pod", "x"), sampleOutOfOrder, now)
+	s.track(labels.FromStrings(labels.MetricName, "up", "job", "a", "instance", "1", "pod", "y"), sampleOutOfOrder, now)
+	s.track(labels.FromStrings(labels.MetricName, "up", "job", "a", "instance", "1", "pod", "y"), newValueForTimestamp, now.Add(time.Second))
+	s.track(labels.FromStrings(labels.MetricName, "up", "job", "b"), sampleOutOfBounds, now)
+	s.track(labels.FromStrings(labels.MetricName, "up", "job", "b"), sampleTooOld, now)
+	s.track(labels.FromStrings(labels.MetricName, "down", "job", "b"), sampleTooOld, now)
+
+	assert.Equal(t, []client.OutOfOrderSeriesStats{
+		{
+			Labels:                   cortexpb.FromLabelsToLabelAdapters(labels.FromStrings(labels.MetricName, "up", "instance", "1", "job", "a")),
+			OutOfOrder:               2,
+			DuplicateTimestamp:       1,
+			LastDiscardedTimestampMs: now.Add(time.Second).UnixMilli(),
+		},
+		{
+			Labels:                   cortexpb.FromLabelsToLabelAdapters(labels.FromStrings(labels.MetricName, "up", "job", "b")),
+			OutOfBounds:              1,
+			TooOld:                   1,
+			LastDiscardedTimestampMs: now.UnixMilli(),
+		},
+		{
+			Labels:                   cortexpb.FromLabelsToLabelAdapters(labels.FromStrings(labels.MetricName, "down", "job", "b")),
+			TooOld:                   1,
+			LastDiscardedTimestampMs: now.UnixMilli(),
+		},
+	}, s.stats())
+
+	// The groups without discarded samples since the purge time are removed.
+	s.purge(now.Add(time.Millisecond))
+	stats := s.stats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, uint64(3), stats[0].Total())
+}
+
+func TestOutOfOrderSeriesStats_MaxGroups(t *testing.T) {
+	now := time.Now()
+	s := newOutOfOrderSeriesStats([]string{"job"}, 2, now)
+
+	frequent := labels.FromStrings(labels.MetricName, "up", "job", "frequent")
+	for range 10 {
+		s.track(frequent, sampleOutOfOrder, now)
+	}
+
+	// A stream of groups rarely discarded doesn't evict the group frequently discarded.
+	for _, job := range []string{"a", "b", "c", "d"} {
+		s.track(labels.FromStrings(labels.MetricName, "up", "job", job), sampleOutOfOrder, now)
+	}
+
+	stats := s.stats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, cortexpb.FromLabelsToLabelAdapters(frequent), stats[0].Labels)
+	assert.Equal(t, uint64(10), stats[0].OutOfOrder)
+	assert.Equal(t, cortexpb.FromLabelsToLabelAdapters(labels.FromStrings(labels.MetricName, "up", "job", "d")), stats[1].Labels)
+	assert.Equal(t, uint64(1), stats[1].OutOfOrder)
+}
@@ -86,6 +86,20 @@ func (m *metricCounter) increaseSeriesForMetric(metric string) {
 	shard.mtx.Unlock()
 }
 
+// seriesPerMetric returns the number of series of each metric name.
+func (m *metricCounter) seriesPerMetric() map[string]int {
+	out := map[string]int{}
+	for i := range m.shards {
+		shard := &m.shards[i]
+		shard.mtx.Lock()
+		for metric, count := range shard.m {
+			out[metric] = count
+		}
+		shard.mtx.Unlock()
+	}
+	return out
+}
+
 type labelSetCounterEntry struct {
 	count  int
 	labels labels.Labels
@@ -0,0 +1,182 @@
+package querier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/util/annotations"
+
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/requestmeta"
+	"github.com/cortexproject/cortex/pkg/util/users"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+// AccessPolicyLimits is the interface of the limits used by the access policy queryables.
+type AccessPolicyLimits interface {
+	QueryAccessPolicy(userID, name string) (validation.QueryAccessPolicy, bool)
+	MandatoryQueryAccessPolicy(userID string) string
+}
+
+// accessPolicies returns the query access policies applying to the request: the mandatory
+// policy of the tenant and the policy selected by the request, if any. The tenant is read
+// from the context, so when querying multiple tenants the policies are resolved for each of them.
+func accessPolicies(ctx context.Context, limits AccessPolicyLimits) ([]validation.QueryAccessPolicy, error) {
+	userID, err := users.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []validation.QueryAccessPolicy
+	for _, name := range []string{limits.MandatoryQueryAccessPolicy(userID), requestmeta.AccessPolicyFromContext(ctx)} {
+		if name == "" {
+			continue
+		}
+
+		policy, ok := limits.QueryAccessPolicy(userID, name)
+		if !ok {
+			return nil, validation.AccessDeniedError(fmt.Sprintf("query access policy %s not found", name))
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// accessPolicyMatchers returns the matchers of the query access policies applying to the request.
+func accessPolicyMatchers(ctx context.Context, limits AccessPolicyLimits) ([]*labels.Matcher, error) {
+	policies, err := accessPolicies(ctx, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	var matchers []*labels.Matcher
+	for _, policy := range policies {
+		matchers = append(matchers, policy.Matchers...)
+	}
+	return matchers, nil
+}
+
+// DenyWithAccessPolicy wraps a handler reporting about all the series of the tenant, which can't
+// be restricted to the series of a query access policy, rejecting the requests a policy applies to.
+func DenyWithAccessPolicy(next http.Handler, limits AccessPolicyLimits) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policies, err := accessPolicies(r.Context(), limits)
+		if err == nil && len(policies) > 0 {
+			err = validation.AccessDeniedError(fmt.Sprintf("this API reports about all the series of the tenant, which is not allowed with the query access policy %s", policies[len(policies)-1].Name))
+		}
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.As(err, new(validation.AccessDeniedError)) {
+				status = http.StatusForbidden
+			}
+			w.WriteHeader(status)
+			util.WriteJSONResponse(w, metadataErrorResult{Status: statusError, Error: err.Error()})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func withAccessPolicyMatchers(policyMatchers, matchers []*labels.Matcher) []*labels.Matcher {
+	if len(policyMatchers) == 0 {
+		return matchers
+	}
+
+	merged := make([]*labels.Matcher, 0, len(matchers)+len(policyMatchers))
+	merged = append(merged, matchers...)
+	return append(merged, policyMatchers...)
+}
+
+// NewAccessPolicyQueryable returns a queryable adding the matchers of the query access
+// policies applying to the request to every Select, LabelNames and LabelValues call.
+func NewAccessPolicyQueryable(q storage.Queryable, limits AccessPolicyLimits) storage.Queryable {
+	return accessPolicyQueryable{q: q, limits: limits}
+}
+
+type accessPolicyQueryable struct {
+	q      storage.Queryable
+	limits AccessPolicyLimits
+}
+
+func (a accessPolicyQueryable) Querier(mint, maxt int64) (storage.Querier, error) {
+	q, err := a.q.Querier(mint, maxt)
+	if err != nil {
+		return nil, err
+	}
+	return accessPolicyQuerier{q: q, limits: a.limits}, nil
+}
+
+type accessPolicyQuerier struct {
+	q      storage.Querier
+	limits AccessPolicyLimits
+}
+
+func (a accessPolicyQuerier) Select(ctx context.Context, sortSeries bool, hints *storage.SelectHints, matchers ...*labels.Matcher) storage.SeriesSet {
+	policyMatchers, err := accessPolicyMatchers(ctx, a.limits)
+	if err != nil {
+		return storage.ErrSeriesSet(err)
+	}
+	return a.q.Select(ctx, sortSeries, hints, withAccessPolicyMatchers(policyMatchers, matchers)...)
+}
+
+func (a accessPolicyQuerier) LabelValues(ctx context.Context, name string, hints *storage.LabelHints, matchers ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	policyMatchers, err := accessPolicyMatchers(ctx, a.limits)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a.q.LabelValues(ctx, name, hints, withAccessPolicyMatchers(policyMatchers, matchers)...)
+}
+
+func (a accessPolicyQuerier) LabelNames(ctx context.Context, hints *storage.LabelHints, matchers ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	policyMatchers, err := accessPolicyMatchers(ctx, a.limits)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a.q.LabelNames(ctx, hints, withAccessPolicyMatchers(policyMatchers, matchers)...)
+}
+
+func (a accessPolicyQuerier) Close() error {
+	return a.q.Close()
+}
+
+// NewAccessPolicyExemplarQueryable returns an exemplar queryable adding the matchers of
+// the query access policies applying to the request to every matcher set.
+func NewAccessPolicyExemplarQueryable(q storage.ExemplarQueryable, limits AccessPolicyLimits) storage.ExemplarQueryable {
+	return accessPolicyExemplarQueryable{q: q, limits: limits}
+}
+
+type accessPolicyExemplarQueryable struct {
+	q      storage.ExemplarQueryable
+	limits AccessPolicyLimits
+}
+
+func (a accessPolicyExemplarQueryable) ExemplarQuerier(ctx context.Context) (storage.ExemplarQuerier, error) {
+	policyMatchers, err := accessPolicyMatchers(ctx, a.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := a.q.ExemplarQuerier(ctx)
+	if err != nil || len(policyMatchers) == 0 {
+		return q, err
+	}
+	return accessPolicyExemplarQuerier{q: q, matchers: policyMatchers}, nil
+}
+
+type accessPolicyExemplarQuerier struct {
+	q        storage.ExemplarQuerier
+	matchers []*labels.Matcher
+}
+
+func (a accessPolicyExemplarQuerier) Select(start, end int64, matchers ...[]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	merged := make([][]*labels.Matcher, 0, len(matchers))
+	for _, m := range matchers {
+		merged = append(merged, withAccessPolicyMatchers(a.matchers, m))
+	}
+	return a.q.Select(start, end, merged...)
+}
@@ -0,0 +1,237 @@
+package querier
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/util/annotations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/util/requestmeta"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+type mockAccessPolicyLimits struct {
+	policies  map[string][]validation.QueryAccessPolicy
+	mandatory map[string]string
+}
+
+func (m mockAccessPolicyLimits) QueryAccessPolicy(userID, name string) (validation.QueryAccessPolicy, bool) {
+	for _, policy := range m.policies[userID] {
+		if policy.Name == name {
+			return policy, true
+		}
+	}
+	return validation.QueryAccessPolicy{}, false
+}
+
+func (m mockAccessPolicyLimits) MandatoryQueryAccessPolicy(userID string) string {
+	return m.mandatory[userID]
+}
+
+type matchersRecordingQuerier struct {
+	matchers []*labels.Matcher
+}
+
+func (m *matchersRecordingQuerier) Select(_ context.Context, _ bool, _ *storage.SelectHints, matchers ...*labels.Matcher) storage.SeriesSet {
+	m.matchers = matchers
+	return storage.EmptySeriesSet()
+}
+
+func (m *matchersRecordingQuerier) LabelValues(_ context.Context, _ string, _ *storage.LabelHints, matchers ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	m.matchers = matchers
+	return nil, nil, nil
+}
+
+func (m *matchersRecordingQuerier) LabelNames(_ context.Context, _ *storage.LabelHints, matchers ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	m.matchers = matchers
+	return nil, nil, nil
+}
+
+func (m *matchersRecordingQuerier) Close() error {
+	return nil
+}
+
+type matchersRecordingExemplarQuerier struct {
+	matchers []*labels.Matcher
+}
+
+func (m *matchersRecordingExemplarQuerier) ExemplarQuerier(context.Context) (storage.ExemplarQuerier, error) {
+	return m, nil
+}
+
+func (m *matchersRecordingExemplarQuerier) Select(_, _ int64, matchers ...[]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	m.matchers = nil
+	for _, set := range matchers {
+		m.matchers = append(m.matchers, set...)
+	}
+	return nil, nil
+}
+
+func TestAccessPolicyQueryable(t *testing.T) {
+	t.Parallel()
+
+	teamA := labels.MustNewMatcher(labels.MatchRegexp, "namespace", "team-a-.*")
+	prod := labels.MustNewMatcher(labels.MatchEqual, "env", "prod")
+	limits := mockAccessPolicyLimits{
+		policies: map[string][]validation.QueryAccessPolicy{
+			"user-1": {{Name: "team-a", Matchers: []*labels.Matcher{teamA}}},
+			"user-3": {{Name: "team-a", Matchers: []*labels.Matcher{teamA}}, {Name: "prod", Matchers: []*labels.Matcher{prod}}},
+		},
+		mandatory: map[string]string{"user-3": "prod"},
+	}
+	metricMatcher := labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "up")
+
+	tests := map[string]struct {
+		userID           string
+		policy           string
+		expectedMatchers []*labels.Matcher
+		expectedErr      error
+	}{
+		"should not change the matchers without policy": {
+			userID:           "user-1",
+			expectedMatchers: []*labels.Matcher{metricMatcher},
+		},
+		"should add the matchers of the selected policy": {
+			userID:           "user-1",
+			policy:           "team-a",
+			expectedMatchers: []*labels.Matcher{metricMatcher, teamA},
+		},
+		"should deny access with an unknown policy": {
+			userID:      "user-1",
+			policy:      "team-b",
+			expectedErr: validation.AccessDeniedError("query access policy team-b not found"),
+		},
+		"should add the matchers of the mandatory policy without policy": {
+			userID:           "user-3",
+			expectedMatchers: []*labels.Matcher{metricMatcher, prod},
+		},
+		"should add the matchers of the mandatory policy to the ones of the selected policy": {
+			userID:           "user-3",
+			policy:           "team-a",
+			expectedMatchers: []*labels.Matcher{metricMatcher, prod, teamA},
+		},
+		"should resolve the policy for the tenant": {
+			userID:      "user-2",
+			policy:      "team-a",
+			expectedErr: validation.AccessDeniedError("query access policy team-a not found"),
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := user.InjectOrgID(context.Background(), testData.userID)
+			if testData.policy != "" {
+				ctx = requestmeta.ContextWithAccessPolicy(ctx, testData.policy)
+			}
+
+			calls := map[string]func(q storage.Querier) error{
+				"Select": func(q storage.Querier) error {
+					return q.Select(ctx, true, nil, metricMatcher).Err()
+				},
+				"LabelNames": func(q storage.Querier) error {
+					_, _, err := q.LabelNames(ctx, nil, metricMatcher)
+					return err
+				},
+				"LabelValues": func(q storage.Querier) error {
+					_, _, err := q.LabelValues(ctx, "job", nil, metricMatcher)
+					return err
+				},
+				"Exemplars": func(storage.Querier) error {
+					upstream := &matchersRecordingExemplarQuerier{}
+					q, err := NewAccessPolicyExemplarQueryable(upstream, limits).ExemplarQuerier(ctx)
+					if err != nil {
+						return err
+					}
+					_, err = q.Select(0, 1, []*labels.Matcher{metricMatcher})
+					assert.Equal(t, testData.expectedMatchers, upstream.matchers)
+					return err
+				},
+			}
+
+			for call, fn := range calls {
+				upstream := &matchersRecordingQuerier{}
+				queryable := NewAccessPolicyQueryable(storage.QueryableFunc(func(_, _ int64) (storage.Querier, error) {
+					return upstream, nil
+				}), limits)
+
+				q, err := queryable.Querier(0, 1)
+				require.NoError(t, err)
+
+				err = fn(q)
+				if testData.expectedErr != nil {
+					assert.Equal(t, testData.expectedErr, err, call)
+					continue
+				}
+				require.NoError(t, err, call)
+				if call != "Exemplars" {
+					assert.Equal(t, testData.expectedMatchers, upstream.matchers, call)
+				}
+			}
+		})
+	}
+}
+
+func TestDenyWithAccessPolicy(t *testing.T) {
+	t.Parallel()
+
+	limits := mockAccessPolicyLimits{
+		policies: map[string][]validation.QueryAccessPolicy{
+			"user-1": {{Name: "team-a"}},
+			"user-2": {{Name: "team-a"}},
+		},
+		mandatory: map[string]string{"user-2": "team-a"},
+	}
+	handler := DenyWithAccessPolicy(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), limits)
+
+	tests := map[string]struct {
+		userID         string
+		policy         string
+		expectedStatus int
+	}{
+		"should allow the requests without policy": {
+			userID:         "user-1",
+			expectedStatus: http.StatusOK,
+		},
+		"should deny the requests selecting a policy": {
+			userID:         "user-1",
+			policy:         "team-a",
+			expectedStatus: http.StatusForbidden,
+		},
+		"should deny the requests of the tenants with a mandatory policy": {
+			userID:         "user-2",
+			expectedStatus: http.StatusForbidden,
+		},
+		"should deny the requests selecting an unknown policy": {
+			userID:         "user-1",
+			policy:         "team-b",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := user.InjectOrgID(context.Background(), testData.userID)
+			if testData.policy != "" {
+				ctx = requestmeta.ContextWithAccessPolicy(ctx, testData.policy)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+			assert.Equal(t, testData.expectedStatus, rec.Code)
+		})
+	}
+}
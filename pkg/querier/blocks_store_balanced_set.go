@@ -17,6 +17,7 @@ import (
 	"github.com/thanos-io/thanos/pkg/extprom"
 
 	"github.com/cortexproject/cortex/pkg/ring/client"
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
 	"github.com/cortexproject/cortex/pkg/util/services"
 )
 
@@ -60,7 +61,7 @@ func (s *blocksStoreBalancedSet) resolve(ctx context.Context) error {
 	return nil
 }
 
-func (s *blocksStoreBalancedSet) GetClientsFor(_ string, blockIDs []ulid.ULID, exclude map[ulid.ULID][]string, _ map[ulid.ULID]map[string]int) (map[BlocksStoreClient][]ulid.ULID, error) {
+func (s *blocksStoreBalancedSet) GetClientsFor(_ string, blocks bucketindex.Blocks, exclude map[ulid.ULID][]string, _ map[ulid.ULID]map[string]int) (map[BlocksStoreClient][]ulid.ULID, error) {
 	addresses := s.dnsProvider.Addresses()
 	if len(addresses) == 0 {
 		return nil, fmt.Errorf("no address resolved for the store-gateway service addresses %s", strings.Join(s.serviceAddresses, ","))
@@ -74,7 +75,9 @@ func (s *blocksStoreBalancedSet) GetClientsFor(_ string, blockIDs []ulid.ULID, e
 	// Pick a non excluded client for each block.
 	clients := map[BlocksStoreClient][]ulid.ULID{}
 
-	for _, blockID := range blockIDs {
+	for _, b := range blocks {
+		blockID := b.ID
+
 		// Pick the first non excluded store-gateway instance.
 		addr := getFirstNonExcludedAddr(addresses, exclude[blockID])
 		if addr == "" {
@@ -92,6 +95,25 @@ func (s *blocksStoreBalancedSet) GetClientsFor(_ string, blockIDs []ulid.ULID, e
 	return clients, nil
 }
 
+func (s *blocksStoreBalancedSet) GetAllClients(_ string) ([]BlocksStoreClient, error) {
+	addresses := s.dnsProvider.Addresses()
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("no address resolved for the store-gateway service addresses %s", strings.Join(s.serviceAddresses, ","))
+	}
+
+	clients := make([]BlocksStoreClient, 0, len(addresses))
+	for _, addr := range addresses {
+		c, err := s.clientsPool.GetClientFor(addr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get store-gateway client for %s", addr)
+		}
+
+		clients = append(clients, c.(BlocksStoreClient))
+	}
+
+	return clients, nil
+}
+
 func getFirstNonExcludedAddr(addresses, exclude []string) string {
 	for _, addr := range addresses {
 		if !slices.Contains(exclude, addr) {
@@ -23,6 +23,7 @@ import (
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/util/annotations"
+	"github.com/thanos-io/objstore"
 	"github.com/thanos-io/thanos/pkg/block"
 	"github.com/thanos-io/thanos/pkg/compact/downsample"
 	"github.com/thanos-io/thanos/pkg/extprom"
@@ -76,7 +77,11 @@ type BlocksStoreSet interface {
 	// GetClientsFor returns the store gateway clients that should be used to
 	// query the set of blocks in input. The exclude parameter is the map of
 	// blocks -> store-gateway addresses that should be excluded.
-	GetClientsFor(userID string, blockIDs []ulid.ULID, exclude map[ulid.ULID][]string, attemptedBlocksZones map[ulid.ULID]map[string]int) (map[BlocksStoreClient][]ulid.ULID, error)
+	GetClientsFor(userID string, blocks bucketindex.Blocks, exclude map[ulid.ULID][]string, attemptedBlocksZones map[ulid.ULID]map[string]int) (map[BlocksStoreClient][]ulid.ULID, error)
+
+	// GetAllClients returns the clients of all the healthy store gateways which may
+	// serve the blocks of the input user.
+	GetAllClients(userID string) ([]BlocksStoreClient, error)
 }
 
 // BlocksFinder is the interface used to find blocks for a given user and time range.
@@ -145,6 +150,10 @@ type BlocksStoreQueryable struct {
 	storeGatewayConsistencyCheckMaxAttempts int
 	storeGatewaySeriesBatchSize             int64
 
+	// Used to read the bucket index of a tenant, if created from config.
+	bucketClient objstore.Bucket
+	cfgProvider  bucket.TenantConfigProvider
+
 	// Subservices manager.
 	subservices        *services.Manager
 	subservicesWatcher *services.FailureWatcher
@@ -238,7 +247,7 @@ func NewBlocksStoreQueryableFromConfig(querierCfg Config, gatewayCfg storegatewa
 			return nil, errors.Wrap(err, "failed to create store-gateway ring client")
 		}
 
-		stores, err = newBlocksStoreReplicationSet(storesRing, gatewayCfg.ShardingStrategy, randomLoadBalancing, limits, querierCfg.StoreGatewayClient, logger, reg, storesRingCfg.ZoneAwarenessEnabled, gatewayCfg.ShardingRing.ZoneStableShuffleSharding)
+		stores, err = newBlocksStoreReplicationSet(storesRing, gatewayCfg.ShardingStrategy, gatewayCfg.TimeBasedSharding, randomLoadBalancing, limits, querierCfg.StoreGatewayClient, logger, reg, storesRingCfg.ZoneAwarenessEnabled, gatewayCfg.ShardingRing.ZoneStableShuffleSharding)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create store set")
 		}
@@ -262,7 +271,24 @@ func NewBlocksStoreQueryableFromConfig(querierCfg Config, gatewayCfg storegatewa
 		reg,
 	)
 
-	return NewBlocksStoreQueryable(stores, finder, consistency, limits, querierCfg, logger, reg)
+	q, err := NewBlocksStoreQueryable(stores, finder, consistency, limits, querierCfg, logger, reg)
+	if err != nil {
+		return nil, err
+	}
+
+	q.bucketClient = bucketClient
+	q.cfgProvider = limits
+	return q, nil
+}
+
+// ReadBucketIndex reads the bucket index of a tenant from the storage. It returns
+// bucketindex.ErrIndexNotFound if the tenant has no bucket index.
+func (q *BlocksStoreQueryable) ReadBucketIndex(ctx context.Context, userID string) (*bucketindex.Index, error) {
+	if q.bucketClient == nil {
+		return nil, errors.New("the bucket client is not configured")
+	}
+
+	return bucketindex.ReadIndex(ctx, q.bucketClient, userID, q.cfgProvider, q.logger)
 }
 
 func (q *BlocksStoreQueryable) starting(ctx context.Context) error {
@@ -290,6 +316,53 @@ func (q *BlocksStoreQueryable) stopping(_ error) error {
 	return services.StopManagerAndAwaitStopped(context.Background(), q.subservices)
 }
 
+// QueriedMetrics returns the metric names queried for the user across all store-gateways,
+// along with the last time they have been queried. The tracking start time is the most recent
+// one among the store-gateways, given that's the period covered by all of them.
+func (q *BlocksStoreQueryable) QueriedMetrics(ctx context.Context, userID string) (*storegatewaypb.QueriedMetricsResponse, error) {
+	clients, err := q.stores.GetAllClients(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		reqCtx  = grpc_metadata.AppendToOutgoingContext(ctx, cortex_tsdb.TenantIDExternalLabel, userID)
+		g, gCtx = errgroup.WithContext(reqCtx)
+		mtx     sync.Mutex
+
+		lastQueried  = map[string]int64{}
+		trackedSince int64
+	)
+
+	for _, c := range clients {
+		g.Go(func() error {
+			resp, err := c.QueriedMetrics(gCtx, &storegatewaypb.QueriedMetricsRequest{})
+			if err != nil {
+				return errors.Wrapf(err, "failed to fetch queried metrics from %s", c.RemoteAddress())
+			}
+
+			mtx.Lock()
+			defer mtx.Unlock()
+
+			trackedSince = max(trackedSince, resp.TrackedSinceTimestampMs)
+			for _, m := range resp.Metrics {
+				lastQueried[m.MetricName] = max(lastQueried[m.MetricName], m.LastQueriedTimestampMs)
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	resp := &storegatewaypb.QueriedMetricsResponse{TrackedSinceTimestampMs: trackedSince}
+	for name, last := range lastQueried {
+		resp.Metrics = append(resp.Metrics, &storegatewaypb.QueriedMetric{MetricName: name, LastQueriedTimestampMs: last})
+	}
+	return resp, nil
+}
+
 // Querier returns a new Querier on the storage.
 func (q *BlocksStoreQueryable) Querier(mint, maxt int64) (storage.Querier, error) {
 	if s := q.State(); s != services.Running {
@@ -533,7 +606,7 @@ func (q *blocksStoreQuerier) queryWithConsistencyCheck(ctx context.Context, logg
 
 	var (
 		// At the beginning the list of blocks to query are all known blocks.
-		remainingBlocks = knownBlocks.GetULIDs()
+		remainingBlocks = knownBlocks
 		attemptedBlocks = map[ulid.ULID][]string{}
 		touchedStores   = map[string]struct{}{}
 
@@ -591,7 +664,7 @@ func (q *blocksStoreQuerier) queryWithConsistencyCheck(ctx context.Context, logg
 		level.Debug(logger).Log("msg", "consistency check failed", "attempt", attempt, "missing blocks", strings.Join(convertULIDsToString(missingBlocks), " "))
 
 		// The next attempt should just query the missing blocks.
-		remainingBlocks = missingBlocks
+		remainingBlocks = filterBlocksByIDs(knownBlocks, missingBlocks)
 	}
 
 	// After we exhausted retries, if retryable error is not nil return the retryable error.
@@ -601,7 +674,7 @@ func (q *blocksStoreQuerier) queryWithConsistencyCheck(ctx context.Context, logg
 	}
 
 	// We've not been able to query all expected blocks after all retries.
-	err = fmt.Errorf("consistency check failed because some blocks were not queried: %s", strings.Join(convertULIDsToString(remainingBlocks), " "))
+	err = fmt.Errorf("consistency check failed because some blocks were not queried: %s", strings.Join(convertULIDsToString(remainingBlocks.GetULIDs()), " "))
 	level.Warn(util_log.WithContext(ctx, logger)).Log("msg", "failed consistency check", "err", err)
 	return err
 }
@@ -1168,6 +1241,22 @@ func createLabelValuesRequest(minT, maxT, limit int64, label string, blockIDs []
 	return req, nil
 }
 
+// filterBlocksByIDs returns the blocks whose ID is in the input list.
+func filterBlocksByIDs(blocks bucketindex.Blocks, ids []ulid.ULID) bucketindex.Blocks {
+	set := make(map[ulid.ULID]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+
+	filtered := make(bucketindex.Blocks, 0, len(ids))
+	for _, b := range blocks {
+		if _, ok := set[b.ID]; ok {
+			filtered = append(filtered, b)
+		}
+	}
+	return filtered
+}
+
 func convertULIDsToString(ids []ulid.ULID) []string {
 	res := make([]string, len(ids))
 	for idx, id := range ids {
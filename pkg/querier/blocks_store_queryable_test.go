@@ -2597,6 +2597,40 @@ func TestBlocksStoreQuerier_PromQLExecution(t *testing.T) {
 	}
 }
 
+func TestBlocksStoreQueryable_QueriedMetrics(t *testing.T) {
+	t.Parallel()
+
+	stores := &blocksStoreSetMock{allClients: []BlocksStoreClient{
+		&storeGatewayClientMock{remoteAddr: "1.1.1.1", mockedQueriedMetrics: &storegatewaypb.QueriedMetricsResponse{
+			TrackedSinceTimestampMs: 100,
+			Metrics: []*storegatewaypb.QueriedMetric{
+				{MetricName: "metric_1", LastQueriedTimestampMs: 1000},
+				{MetricName: "metric_2", LastQueriedTimestampMs: 3000},
+			},
+		}},
+		&storeGatewayClientMock{remoteAddr: "2.2.2.2", mockedQueriedMetrics: &storegatewaypb.QueriedMetricsResponse{
+			TrackedSinceTimestampMs: 200,
+			Metrics: []*storegatewaypb.QueriedMetric{
+				{MetricName: "metric_1", LastQueriedTimestampMs: 2000},
+			},
+		}},
+	}}
+	q := &BlocksStoreQueryable{stores: stores}
+
+	resp, err := q.QueriedMetrics(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(200), resp.TrackedSinceTimestampMs)
+	assert.ElementsMatch(t, []*storegatewaypb.QueriedMetric{
+		{MetricName: "metric_1", LastQueriedTimestampMs: 2000},
+		{MetricName: "metric_2", LastQueriedTimestampMs: 3000},
+	}, resp.Metrics)
+
+	// The request should fail if any store-gateway fails.
+	stores.allClients = append(stores.allClients, &storeGatewayClientMock{remoteAddr: "3.3.3.3"})
+	_, err = q.QueriedMetrics(context.Background(), "user-1")
+	require.Error(t, err)
+}
+
 func TestBlocksStoreQuerier_isRetryableError(t *testing.T) {
 	require.True(t, isRetryableError(status.Error(codes.Unavailable, "")))
 	require.True(t, isRetryableError(storegateway.ErrTooManyInflightRequests))
@@ -2617,13 +2651,14 @@ type blocksStoreSetMock struct {
 	mockedResponses []any
 	nextResult      int
 	queriedBlocks   []ulid.ULID
+	allClients      []BlocksStoreClient
 }
 
-func (m *blocksStoreSetMock) GetClientsFor(_ string, b []ulid.ULID, _ map[ulid.ULID][]string, _ map[ulid.ULID]map[string]int) (map[BlocksStoreClient][]ulid.ULID, error) {
+func (m *blocksStoreSetMock) GetClientsFor(_ string, b bucketindex.Blocks, _ map[ulid.ULID][]string, _ map[ulid.ULID]map[string]int) (map[BlocksStoreClient][]ulid.ULID, error) {
 	if m.nextResult >= len(m.mockedResponses) {
 		panic("not enough mocked results")
 	}
-	m.queriedBlocks = append(m.queriedBlocks, b...)
+	m.queriedBlocks = append(m.queriedBlocks, b.GetULIDs()...)
 
 	res := m.mockedResponses[m.nextResult]
 	m.nextResult++
@@ -2638,6 +2673,10 @@ func (m *blocksStoreSetMock) GetClientsFor(_ string, b []ulid.ULID, _ map[ulid.U
 	return nil, errors.New("unknown data type in the mocked result")
 }
 
+func (m *blocksStoreSetMock) GetAllClients(_ string) ([]BlocksStoreClient, error) {
+	return m.allClients, nil
+}
+
 func (m *blocksStoreSetMock) Reset() {
 	m.nextResult = 0
 	m.queriedBlocks = nil
@@ -2661,6 +2700,7 @@ type storeGatewayClientMock struct {
 	mockedLabelNamesResponse  *storepb.LabelNamesResponse
 	mockedLabelValuesResponse *storepb.LabelValuesResponse
 	mockedLabelValuesErr      error
+	mockedQueriedMetrics      *storegatewaypb.QueriedMetricsResponse
 	lastSeriesRequest         *storepb.SeriesRequest // capture the last received SeriesRequest to use test.
 }
 
@@ -2690,6 +2730,13 @@ func (m *storeGatewayClientMock) LabelValues(_ context.Context, r *storepb.Label
 	return m.mockedLabelValuesResponse, m.mockedLabelValuesErr
 }
 
+func (m *storeGatewayClientMock) QueriedMetrics(_ context.Context, _ *storegatewaypb.QueriedMetricsRequest, _ ...grpc.CallOption) (*storegatewaypb.QueriedMetricsResponse, error) {
+	if m.mockedQueriedMetrics == nil {
+		return nil, errors.New("queried metrics not mocked")
+	}
+	return m.mockedQueriedMetrics, nil
+}
+
 func (m *storeGatewayClientMock) RemoteAddress() string {
 	return m.remoteAddr
 }
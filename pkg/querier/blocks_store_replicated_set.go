@@ -6,6 +6,7 @@ import (
 	"math"
 	"math/rand"
 	"slices"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/oklog/ulid/v2"
@@ -15,6 +16,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/ring"
 	"github.com/cortexproject/cortex/pkg/ring/client"
 	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
 	"github.com/cortexproject/cortex/pkg/storegateway"
 	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/services"
@@ -35,6 +37,7 @@ type blocksStoreReplicationSet struct {
 	storesRing        *ring.Ring
 	clientsPool       *client.Pool
 	shardingStrategy  string
+	timeBasedSharding storegateway.TimeBasedShardingConfig
 	balancingStrategy loadBalancingStrategy
 	limits            BlocksStoreLimits
 
@@ -49,6 +52,7 @@ type blocksStoreReplicationSet struct {
 func newBlocksStoreReplicationSet(
 	storesRing *ring.Ring,
 	shardingStrategy string,
+	timeBasedSharding storegateway.TimeBasedShardingConfig,
 	balancingStrategy loadBalancingStrategy,
 	limits BlocksStoreLimits,
 	clientConfig ClientConfig,
@@ -61,6 +65,7 @@ func newBlocksStoreReplicationSet(
 		storesRing:        storesRing,
 		clientsPool:       newStoreGatewayClientPool(client.NewRingServiceDiscovery(storesRing), clientConfig, logger, reg),
 		shardingStrategy:  shardingStrategy,
+		timeBasedSharding: timeBasedSharding,
 		balancingStrategy: balancingStrategy,
 		limits:            limits,
 
@@ -104,27 +109,39 @@ func (s *blocksStoreReplicationSet) stopping(_ error) error {
 	return services.StopManagerAndAwaitStopped(context.Background(), s.subservices)
 }
 
-func (s *blocksStoreReplicationSet) GetClientsFor(userID string, blockIDs []ulid.ULID, exclude map[ulid.ULID][]string, attemptedBlocksZones map[ulid.ULID]map[string]int) (map[BlocksStoreClient][]ulid.ULID, error) {
+func (s *blocksStoreReplicationSet) GetClientsFor(userID string, blocks bucketindex.Blocks, exclude map[ulid.ULID][]string, attemptedBlocksZones map[ulid.ULID]map[string]int) (map[BlocksStoreClient][]ulid.ULID, error) {
 	shards := map[string][]ulid.ULID{}
-
-	// If shuffle sharding is enabled, we should build a subring for the user,
-	// otherwise we just use the full ring.
-	var userRing ring.ReadRing
-	if s.shardingStrategy == util.ShardingStrategyShuffle {
-		userRing = storegateway.GetShuffleShardingSubring(s.storesRing, userID, s.limits, s.zoneStableShuffleSharding)
-	} else {
-		userRing = s.storesRing
-	}
+	userRing := s.userRing(userID)
+	now := time.Now()
 
 	// Find the replication set of each block we need to query.
-	for _, blockID := range blockIDs {
-		// Do not reuse the same buffer across multiple Get() calls because we do retain the
-		// returned replication set.
-		bufDescs, bufHosts, bufZones := ring.MakeBuffersForGet()
+	for _, b := range blocks {
+		blockID := b.ID
+
+		// With the time-based sharding strategy, recent blocks are replicated across more store-gateways,
+		// while old blocks may be loaded by a dedicated set of store-gateways. While a block transitions
+		// from recent to old, it's queried from the store-gateways of both rings.
+		blockRings := []storegateway.BlockRing{{Ring: userRing, ReplicationFactor: userRing.ReplicationFactor()}}
+		if s.shardingStrategy == util.ShardingStrategyTime {
+			blockRings = s.timeBasedSharding.Rings(userRing, b.MaxTime, now)
+		}
 
-		set, err := userRing.Get(cortex_tsdb.HashBlockID(blockID), storegateway.BlocksRead, bufDescs, bufHosts, bufZones)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to get store-gateway replication set owning the block %s", blockID.String())
+		var set ring.ReplicationSet
+		for _, br := range blockRings {
+			// Do not reuse the same buffer across multiple Get() calls because we do retain the
+			// returned replication set.
+			bufDescs, bufHosts, bufZones := ring.MakeBuffersForGet()
+
+			ringSet, err := br.Ring.GetWithReplicationFactor(cortex_tsdb.HashBlockID(blockID), storegateway.BlocksRead, br.ReplicationFactor, bufDescs, bufHosts, bufZones)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get store-gateway replication set owning the block %s", blockID.String())
+			}
+
+			for _, instance := range ringSet.Instances {
+				if !set.Includes(instance.Addr) {
+					set.Instances = append(set.Instances, instance)
+				}
+			}
 		}
 
 		// Pick a non excluded store-gateway instance.
@@ -158,6 +175,35 @@ func (s *blocksStoreReplicationSet) GetClientsFor(userID string, blockIDs []ulid
 	return clients, nil
 }
 
+func (s *blocksStoreReplicationSet) GetAllClients(userID string) ([]BlocksStoreClient, error) {
+	set, err := s.userRing(userID).GetAllHealthy(storegateway.BlocksRead)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get store-gateway instances")
+	}
+
+	clients := make([]BlocksStoreClient, 0, len(set.Instances))
+	for _, instance := range set.Instances {
+		c, err := s.clientsPool.GetClientFor(instance.Addr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get store-gateway client for %s", instance.Addr)
+		}
+
+		clients = append(clients, c.(BlocksStoreClient))
+	}
+
+	return clients, nil
+}
+
+// userRing returns the ring of the store-gateways which may own the blocks of the user.
+// If shuffle sharding is enabled, we should build a subring for the user, otherwise we
+// just use the full ring.
+func (s *blocksStoreReplicationSet) userRing(userID string) ring.ReadRing {
+	if s.shardingStrategy == util.ShardingStrategyShuffle {
+		return storegateway.GetShuffleShardingSubring(s.storesRing, userID, s.limits, s.zoneStableShuffleSharding)
+	}
+	return s.storesRing
+}
+
 func getNonExcludedInstance(set ring.ReplicationSet, exclude []string, balancingStrategy loadBalancingStrategy, zoneAwarenessEnabled bool, attemptedZones map[string]int) ring.InstanceDesc {
 	if balancingStrategy == randomLoadBalancing {
 		// Randomize the list of instances to not always query the same one.
@@ -3,6 +3,7 @@ package querier
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
@@ -18,6 +19,8 @@ import (
 	"github.com/cortexproject/cortex/pkg/ring"
 	"github.com/cortexproject/cortex/pkg/ring/kv/consul"
 	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+	"github.com/cortexproject/cortex/pkg/storegateway"
 	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
 	"github.com/cortexproject/cortex/pkg/util/services"
@@ -67,8 +70,12 @@ func TestBlocksStoreReplicationSet_GetClientsFor(t *testing.T) {
 		shardingStrategy     string
 		tenantShardSize      float64
 		replicationFactor    int
+		recentBlocksRF       int
+		oldBlocksShardSize   int
 		setup                func(*ring.Desc)
 		queryBlocks          []ulid.ULID
+		recentBlocks         []ulid.ULID
+		transitioningBlocks  []ulid.ULID
 		exclude              map[ulid.ULID][]string
 		attemptedBlocksZones map[ulid.ULID]map[string]int
 		zoneAwarenessEnabled bool
@@ -220,6 +227,97 @@ func TestBlocksStoreReplicationSet_GetClientsFor(t *testing.T) {
 			},
 		},
 		//
+		// Sharding strategy: time-based
+		//
+		"time-based sharding, recent block replicated with a higher RF": {
+			shardingStrategy:  util.ShardingStrategyTime,
+			replicationFactor: 1,
+			recentBlocksRF:    2,
+			setup: func(d *ring.Desc) {
+				d.AddIngester("instance-1", "127.0.0.1", "", []uint32{block1Hash + 1}, ring.ACTIVE, registeredAt)
+				d.AddIngester("instance-2", "127.0.0.2", "", []uint32{block2Hash + 1}, ring.ACTIVE, registeredAt)
+			},
+			queryBlocks:  []ulid.ULID{block1},
+			recentBlocks: []ulid.ULID{block1},
+			exclude: map[ulid.ULID][]string{
+				block1: {"127.0.0.1"},
+			},
+			expectedClients: map[string][]ulid.ULID{
+				"127.0.0.2": {block1},
+			},
+		},
+		"time-based sharding, old block replicated with the ring RF": {
+			shardingStrategy:  util.ShardingStrategyTime,
+			replicationFactor: 1,
+			recentBlocksRF:    2,
+			setup: func(d *ring.Desc) {
+				d.AddIngester("instance-1", "127.0.0.1", "", []uint32{block1Hash + 1}, ring.ACTIVE, registeredAt)
+				d.AddIngester("instance-2", "127.0.0.2", "", []uint32{block2Hash + 1}, ring.ACTIVE, registeredAt)
+			},
+			queryBlocks: []ulid.ULID{block1},
+			exclude: map[ulid.ULID][]string{
+				block1: {"127.0.0.1"},
+			},
+			expectedErr: fmt.Errorf("no store-gateway instance left after checking exclude for block %s", block1.String()),
+		},
+		"time-based sharding, recent and old blocks": {
+			shardingStrategy:  util.ShardingStrategyTime,
+			replicationFactor: 1,
+			recentBlocksRF:    2,
+			setup: func(d *ring.Desc) {
+				d.AddIngester("instance-1", "127.0.0.1", "", []uint32{block1Hash + 1}, ring.ACTIVE, registeredAt)
+				d.AddIngester("instance-2", "127.0.0.2", "", []uint32{block2Hash + 1}, ring.ACTIVE, registeredAt)
+				d.AddIngester("instance-3", "127.0.0.3", "", []uint32{block3Hash + 1}, ring.ACTIVE, registeredAt)
+			},
+			queryBlocks:  []ulid.ULID{block1, block2},
+			recentBlocks: []ulid.ULID{block2},
+			exclude: map[ulid.ULID][]string{
+				block2: {"127.0.0.2"},
+			},
+			expectedClients: map[string][]ulid.ULID{
+				"127.0.0.1": {block1},
+				"127.0.0.3": {block2},
+			},
+		},
+		"time-based sharding, block transitioning from recent to old queried from the store-gateways of both rings": {
+			shardingStrategy:   util.ShardingStrategyTime,
+			replicationFactor:  1,
+			recentBlocksRF:     1,
+			oldBlocksShardSize: 1,
+			setup: func(d *ring.Desc) {
+				d.AddIngester("instance-1", "127.0.0.1", "", []uint32{block1Hash + 1}, ring.ACTIVE, registeredAt)
+				d.AddIngester("instance-2", "127.0.0.2", "", []uint32{block2Hash + 1}, ring.ACTIVE, registeredAt)
+			},
+			queryBlocks:         []ulid.ULID{block2},
+			transitioningBlocks: []ulid.ULID{block2},
+			// The store-gateway dedicated to the old blocks hasn't loaded the block yet.
+			exclude: map[ulid.ULID][]string{
+				block2: {"127.0.0.1"},
+			},
+			expectedClients: map[string][]ulid.ULID{
+				"127.0.0.2": {block2},
+			},
+		},
+		"time-based sharding, old blocks loaded by the dedicated store-gateways": {
+			shardingStrategy:   util.ShardingStrategyTime,
+			replicationFactor:  1,
+			recentBlocksRF:     2,
+			oldBlocksShardSize: 1,
+			setup: func(d *ring.Desc) {
+				d.AddIngester("instance-1", "127.0.0.1", "", []uint32{block1Hash + 1}, ring.ACTIVE, registeredAt)
+				d.AddIngester("instance-2", "127.0.0.2", "", []uint32{block2Hash + 1}, ring.ACTIVE, registeredAt)
+			},
+			queryBlocks:  []ulid.ULID{block1, block2, block3},
+			recentBlocks: []ulid.ULID{block3},
+			exclude: map[ulid.ULID][]string{
+				block3: {"127.0.0.1"},
+			},
+			expectedClients: map[string][]ulid.ULID{
+				"127.0.0.1": {block1, block2},
+				"127.0.0.2": {block3},
+			},
+		},
+		//
 		// Sharding strategy: shuffle sharding
 		//
 		"shuffle sharding, single instance in the ring with RF = 1, SS = 1": {
@@ -583,8 +681,15 @@ func TestBlocksStoreReplicationSet_GetClientsFor(t *testing.T) {
 				storeGatewayTenantShardSize: testData.tenantShardSize,
 			}
 
+			timeBasedSharding := storegateway.TimeBasedShardingConfig{
+				RecentBlocksPeriod:            time.Hour,
+				RecentBlocksReplicationFactor: testData.recentBlocksRF,
+				OldBlocksShardSize:            testData.oldBlocksShardSize,
+				TransitionGracePeriod:         15 * time.Minute,
+			}
+
 			reg := prometheus.NewPedanticRegistry()
-			s, err := newBlocksStoreReplicationSet(r, testData.shardingStrategy, noLoadBalancing, limits, ClientConfig{}, log.NewNopLogger(), reg, testData.zoneAwarenessEnabled, true)
+			s, err := newBlocksStoreReplicationSet(r, testData.shardingStrategy, timeBasedSharding, noLoadBalancing, limits, ClientConfig{}, log.NewNopLogger(), reg, testData.zoneAwarenessEnabled, true)
 			require.NoError(t, err)
 			require.NoError(t, services.StartAndAwaitRunning(ctx, s))
 			defer services.StopAndAwaitTerminated(ctx, s) //nolint:errcheck
@@ -595,7 +700,17 @@ func TestBlocksStoreReplicationSet_GetClientsFor(t *testing.T) {
 				return err == nil && len(all.Instances) > 0
 			})
 
-			clients, err := s.GetClientsFor(userID, testData.queryBlocks, testData.exclude, testData.attemptedBlocksZones)
+			blocks := blocksFromIDs(testData.queryBlocks...)
+			for _, b := range blocks {
+				if slices.Contains(testData.recentBlocks, b.ID) {
+					b.MaxTime = time.Now().UnixMilli()
+				}
+				if slices.Contains(testData.transitioningBlocks, b.ID) {
+					b.MaxTime = time.Now().Add(-time.Hour - 5*time.Minute).UnixMilli()
+				}
+			}
+
+			clients, err := s.GetClientsFor(userID, blocks, testData.exclude, testData.attemptedBlocksZones)
 			assert.Equal(t, testData.expectedErr, err)
 
 			if testData.expectedErr == nil {
@@ -646,7 +761,7 @@ func TestBlocksStoreReplicationSet_GetClientsFor_ShouldSupportRandomLoadBalancin
 
 	limits := &blocksStoreLimitsMock{}
 	reg := prometheus.NewPedanticRegistry()
-	s, err := newBlocksStoreReplicationSet(r, util.ShardingStrategyDefault, randomLoadBalancing, limits, ClientConfig{}, log.NewNopLogger(), reg, false, false)
+	s, err := newBlocksStoreReplicationSet(r, util.ShardingStrategyDefault, storegateway.TimeBasedShardingConfig{}, randomLoadBalancing, limits, ClientConfig{}, log.NewNopLogger(), reg, false, false)
 	require.NoError(t, err)
 	require.NoError(t, services.StartAndAwaitRunning(ctx, s))
 	defer services.StopAndAwaitTerminated(ctx, s) //nolint:errcheck
@@ -662,7 +777,7 @@ func TestBlocksStoreReplicationSet_GetClientsFor_ShouldSupportRandomLoadBalancin
 	distribution := map[string]int{}
 
 	for range numRuns {
-		clients, err := s.GetClientsFor(userID, []ulid.ULID{block1}, nil, nil)
+		clients, err := s.GetClientsFor(userID, blocksFromIDs(block1), nil, nil)
 		require.NoError(t, err)
 		require.Len(t, clients, 1)
 
@@ -715,7 +830,7 @@ func TestBlocksStoreReplicationSet_GetClientsFor_ZoneAwareness(t *testing.T) {
 
 	limits := &blocksStoreLimitsMock{}
 	reg := prometheus.NewPedanticRegistry()
-	s, err := newBlocksStoreReplicationSet(r, util.ShardingStrategyDefault, randomLoadBalancing, limits, ClientConfig{}, log.NewNopLogger(), reg, true, false)
+	s, err := newBlocksStoreReplicationSet(r, util.ShardingStrategyDefault, storegateway.TimeBasedShardingConfig{}, randomLoadBalancing, limits, ClientConfig{}, log.NewNopLogger(), reg, true, false)
 	require.NoError(t, err)
 	require.NoError(t, services.StartAndAwaitRunning(ctx, s))
 	defer services.StopAndAwaitTerminated(ctx, s) //nolint:errcheck
@@ -737,7 +852,7 @@ func TestBlocksStoreReplicationSet_GetClientsFor_ZoneAwareness(t *testing.T) {
 		attemptedBlocksZone := map[ulid.ULID]map[string]int{
 			block1: blocksMap[i%3],
 		}
-		clients, err := s.GetClientsFor(userID, []ulid.ULID{block1}, nil, attemptedBlocksZone)
+		clients, err := s.GetClientsFor(userID, blocksFromIDs(block1), nil, attemptedBlocksZone)
 		require.NoError(t, err)
 		require.Len(t, clients, 1)
 		for c := range clients {
@@ -751,6 +866,14 @@ func TestBlocksStoreReplicationSet_GetClientsFor_ZoneAwareness(t *testing.T) {
 	}
 }
 
+func blocksFromIDs(ids ...ulid.ULID) bucketindex.Blocks {
+	blocks := make(bucketindex.Blocks, 0, len(ids))
+	for _, id := range ids {
+		blocks = append(blocks, &bucketindex.Block{ID: id})
+	}
+	return blocks
+}
+
 func getStoreGatewayClientAddrs(clients map[BlocksStoreClient][]ulid.ULID) map[string][]ulid.ULID {
 	addrs := map[string][]ulid.ULID{}
 	for c, blockIDs := range clients {
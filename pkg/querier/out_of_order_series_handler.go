@@ -0,0 +1,79 @@
+package querier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/util"
+)
+
+// OutOfOrderSeriesStatsQuerier returns the groups of series of a tenant producing the most
+// out-of-order, out-of-bounds, too old and duplicate timestamp samples in the ingesters.
+type OutOfOrderSeriesStatsQuerier interface {
+	OutOfOrderSeriesStats(ctx context.Context, limit int) (*client.OutOfOrderSeriesStatsResponse, error)
+}
+
+type outOfOrderSeriesSuccessResult struct {
+	Status string               `json:"status"`
+	Data   outOfOrderSeriesData `json:"data"`
+}
+
+type outOfOrderSeriesData struct {
+	TrackedSince time.Time          `json:"tracked_since"`
+	Series       []outOfOrderSeries `json:"series"`
+}
+
+type outOfOrderSeries struct {
+	Labels             map[string]string `json:"labels"`
+	OutOfOrder         uint64            `json:"out_of_order"`
+	OutOfBounds        uint64            `json:"out_of_bounds"`
+	TooOld             uint64            `json:"too_old"`
+	DuplicateTimestamp uint64            `json:"duplicate_timestamp"`
+	LastDiscarded      time.Time         `json:"last_discarded"`
+}
+
+// OutOfOrderSeriesHandler returns the groups of series of a tenant producing the most discarded
+// samples, sorted by number of discarded samples. The series are grouped by metric name and the
+// labels configured in the ingesters. The optional limit parameter sets the number of groups returned.
+func OutOfOrderSeriesHandler(ingesters OutOfOrderSeriesStatsQuerier) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := 0
+		if s := r.FormValue("limit"); s != "" {
+			var err error
+			if limit, err = strconv.Atoi(s); err != nil || limit < 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				util.WriteJSONResponse(w, metadataErrorResult{Status: statusError, Error: fmt.Sprintf("invalid limit: %s", s)})
+				return
+			}
+		}
+
+		stats, err := ingesters.OutOfOrderSeriesStats(r.Context(), limit)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			util.WriteJSONResponse(w, metadataErrorResult{Status: statusError, Error: err.Error()})
+			return
+		}
+
+		data := outOfOrderSeriesData{
+			TrackedSince: time.UnixMilli(stats.TrackedSinceTimestampMs).UTC(),
+			Series:       make([]outOfOrderSeries, 0, len(stats.Series)),
+		}
+		for _, s := range stats.Series {
+			data.Series = append(data.Series, outOfOrderSeries{
+				Labels:             cortexpb.FromLabelAdaptersToLabels(s.Labels).Map(),
+				OutOfOrder:         s.OutOfOrder,
+				OutOfBounds:        s.OutOfBounds,
+				TooOld:             s.TooOld,
+				DuplicateTimestamp: s.DuplicateTimestamp,
+				LastDiscarded:      time.UnixMilli(s.LastDiscardedTimestampMs).UTC(),
+			})
+		}
+
+		util.WriteJSONResponse(w, outOfOrderSeriesSuccessResult{Status: statusSuccess, Data: data})
+	})
+}
@@ -0,0 +1,108 @@
+package querier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+type outOfOrderSeriesStatsQuerierMock struct {
+	resp  *client.OutOfOrderSeriesStatsResponse
+	err   error
+	limit int
+}
+
+func (m *outOfOrderSeriesStatsQuerierMock) OutOfOrderSeriesStats(_ context.Context, limit int) (*client.OutOfOrderSeriesStatsResponse, error) {
+	m.limit = limit
+	return m.resp, m.err
+}
+
+func TestOutOfOrderSeriesHandler(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().Truncate(time.Millisecond).UTC()
+	resp := &client.OutOfOrderSeriesStatsResponse{
+		TrackedSinceTimestampMs: now.Add(-time.Hour).UnixMilli(),
+		Series: []client.OutOfOrderSeriesStats{
+			{
+				Labels:                   cortexpb.FromLabelsToLabelAdapters(labels.FromStrings(labels.MetricName, "up", "job", "a")),
+				OutOfOrder:               3,
+				DuplicateTimestamp:       1,
+				LastDiscardedTimestampMs: now.UnixMilli(),
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		query          string
+		expectedStatus int
+		expectedLimit  int
+	}{
+		"should return all the series by default": {
+			expectedStatus: http.StatusOK,
+		},
+		"should honor the limit parameter": {
+			query:          "?limit=10",
+			expectedStatus: http.StatusOK,
+			expectedLimit:  10,
+		},
+		"should fail on invalid limit parameter": {
+			query:          "?limit=-1",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ingesters := &outOfOrderSeriesStatsQuerierMock{resp: resp}
+			handler := OutOfOrderSeriesHandler(ingesters)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/v1/out_of_order_series"+testData.query, nil))
+
+			require.Equal(t, testData.expectedStatus, recorder.Code)
+			if testData.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var result outOfOrderSeriesSuccessResult
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+			assert.Equal(t, testData.expectedLimit, ingesters.limit)
+			assert.Equal(t, outOfOrderSeriesSuccessResult{
+				Status: statusSuccess,
+				Data: outOfOrderSeriesData{
+					TrackedSince: now.Add(-time.Hour),
+					Series: []outOfOrderSeries{{
+						Labels:             map[string]string{labels.MetricName: "up", "job": "a"},
+						OutOfOrder:         3,
+						DuplicateTimestamp: 1,
+						LastDiscarded:      now,
+					}},
+				},
+			}, result)
+		})
+	}
+
+	t.Run("should fail if the ingesters request fails", func(t *testing.T) {
+		t.Parallel()
+
+		handler := OutOfOrderSeriesHandler(&outOfOrderSeriesStatsQuerierMock{err: errors.New("stats disabled")})
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/v1/out_of_order_series", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "stats disabled")
+	})
+}
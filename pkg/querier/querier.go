@@ -101,6 +101,10 @@ type Config struct {
 	ParquetQueryableDefaultBlockStore string                  `yaml:"parquet_queryable_default_block_store"`
 	ParquetQueryableFallbackDisabled  bool                    `yaml:"parquet_queryable_fallback_disabled"`
 
+	// SQL API over the Parquet files.
+	ParquetSQLAPIEnabled    bool `yaml:"parquet_sql_api_enabled"`
+	ParquetSQLMaxResultRows int  `yaml:"parquet_sql_max_result_rows"`
+
 	DistributedExecEnabled bool `yaml:"distributed_exec_enabled" doc:"hidden"`
 
 	HonorProjectionHints bool `yaml:"honor_projection_hints"`
@@ -115,6 +119,8 @@ var (
 	errInvalidSeriesBatchSize                         = errors.New("store gateway series batch size should be greater or equal than 0")
 	errInvalidIngesterQueryMaxAttempts                = errors.New("ingester query max attempts should be greater or equal than 1")
 	errInvalidParquetQueryableDefaultBlockStore       = errors.New("unsupported parquet queryable default block store. Supported options are tsdb and parquet")
+	errParquetSQLAPIRequiresParquetQueryable          = errors.New("the parquet SQL API requires the parquet queryable to be enabled")
+	errInvalidParquetSQLMaxResultRows                 = errors.New("the parquet SQL max result rows should be greater or equal than 0")
 )
 
 // RegisterFlags adds the flags required to config this to the given FlagSet.
@@ -159,6 +165,8 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&cfg.HonorProjectionHints, "querier.honor-projection-hints", false, "[Experimental] If true, querier will honor projection hints and only materialize requested labels. Today, projection is only effective when Parquet Queryable is enabled. Projection is only applied when not querying mixed block types (parquet and non-parquet) and not querying ingesters.")
 	f.BoolVar(&cfg.DistributedExecEnabled, "querier.distributed-exec-enabled", false, "Experimental: Enables distributed execution of queries by passing logical query plan fragments to downstream components.")
 	f.BoolVar(&cfg.ParquetQueryableFallbackDisabled, "querier.parquet-queryable-fallback-disabled", false, "[Experimental] Disable Parquet queryable to fallback queries to Store Gateway if the block is not available as Parquet files but available in TSDB. Setting this to true will disable the fallback and users can remove Store Gateway. But need to make sure Parquet files are created before it is queryable.")
+	f.BoolVar(&cfg.ParquetSQLAPIEnabled, "querier.parquet-sql-api-enabled", false, "[Experimental] If true, the /api/v1/sql endpoint runs SQL queries over the blocks of a tenant, queried from the parquet files. Requires -querier.enable-parquet-queryable=true.")
+	f.IntVar(&cfg.ParquetSQLMaxResultRows, "querier.parquet-sql-max-result-rows", 10000, "[Experimental] Maximum number of rows returned by a SQL query, which also limits the number of groups of an aggregation and the number of distinct values it counts. 0 to disable the limit.")
 }
 
 // Validate the config
@@ -198,6 +206,14 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	if cfg.ParquetSQLAPIEnabled && !cfg.EnableParquetQueryable {
+		return errParquetSQLAPIRequiresParquetQueryable
+	}
+
+	if cfg.ParquetSQLMaxResultRows < 0 {
+		return errInvalidParquetSQLMaxResultRows
+	}
+
 	if err := cfg.ThanosEngine.Validate(); err != nil {
 		return err
 	}
@@ -230,8 +246,8 @@ func New(cfg Config, limits *validation.Overrides, distributor Distributor, stor
 			QueryStoreAfter:     cfg.QueryStoreAfter,
 		}
 	}
-	queryable := NewQueryable(distributorQueryable, ns, cfg, limits)
-	exemplarQueryable := newDistributorExemplarQueryable(distributor)
+	queryable := NewAccessPolicyQueryable(NewQueryable(distributorQueryable, ns, cfg, limits), limits)
+	exemplarQueryable := NewAccessPolicyExemplarQueryable(newDistributorExemplarQueryable(distributor), limits)
 
 	lazyQueryable := storage.QueryableFunc(func(mint int64, maxt int64) (storage.Querier, error) {
 		querier, err := queryable.Querier(mint, maxt)
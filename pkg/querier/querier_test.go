@@ -1666,6 +1666,18 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			expected: errInvalidParquetQueryableDefaultBlockStore,
 		},
+		"should fail if the parquet SQL API is enabled without the parquet queryable": {
+			setup: func(cfg *Config) {
+				cfg.ParquetSQLAPIEnabled = true
+			},
+			expected: errParquetSQLAPIRequiresParquetQueryable,
+		},
+		"should fail if invalid parquet SQL max result rows": {
+			setup: func(cfg *Config) {
+				cfg.ParquetSQLMaxResultRows = -1
+			},
+			expected: errInvalidParquetSQLMaxResultRows,
+		},
 		"should if if invalid series batch size": {
 			setup: func(cfg *Config) {
 				cfg.StoreGatewaySeriesBatchSize = -1
@@ -0,0 +1,85 @@
+package querier
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/prometheus/storage"
+
+	"github.com/cortexproject/cortex/pkg/querier/sqlquery"
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/limiter"
+	"github.com/cortexproject/cortex/pkg/util/users"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+type sqlSuccessResult struct {
+	Status string           `json:"status"`
+	Data   *sqlquery.Result `json:"data"`
+}
+
+// SQLHandler runs the SQL queries of a tenant over its blocks, which are queried through the
+// Parquet queryable, restricted to the series of the query access policy of the request, if any.
+// The number of rows returned by a query is limited to maxResultRows, if greater than 0.
+func SQLHandler(queryable storage.Queryable, limits *validation.Overrides, maxResultRows int) http.Handler {
+	queryable = NewAccessPolicyQueryable(queryable, limits)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError := func(status int, err error) {
+			w.WriteHeader(status)
+			util.WriteJSONResponse(w, metadataErrorResult{Status: statusError, Error: err.Error()})
+		}
+
+		userID, err := users.TenantID(r.Context())
+		if err != nil {
+			writeError(http.StatusBadRequest, err)
+			return
+		}
+
+		// The Arrow IPC output is not implemented, since it requires the Arrow library.
+		if format := r.FormValue("format"); format == "arrow" {
+			writeError(http.StatusNotImplemented, errors.New("the arrow format is not supported yet, the only supported format is json"))
+			return
+		} else if format != "" && format != "json" {
+			writeError(http.StatusBadRequest, fmt.Errorf("unsupported format %q, the only supported format is json", format))
+			return
+		}
+
+		query, err := sqlquery.Parse(r.FormValue("query"))
+		if err != nil {
+			writeError(http.StatusBadRequest, err)
+			return
+		}
+		plan, err := sqlquery.Compile(query)
+		if err != nil {
+			writeError(http.StatusBadRequest, err)
+			return
+		}
+
+		queryLength := time.Duration(plan.MaxTime()-plan.MinTime()) * time.Millisecond
+		if maxQueryLength := limits.MaxQueryLength(userID); maxQueryLength > 0 && queryLength > maxQueryLength {
+			writeError(http.StatusBadRequest, validation.LimitError(fmt.Sprintf(validation.ErrQueryTooLong, queryLength, maxQueryLength)))
+			return
+		}
+
+		// The blocks are queried from the Parquet files, even if the default block store is TSDB.
+		ctx := AddBlockStoreTypeToContext(r.Context(), string(parquetBlockStore))
+		ctx = limiter.AddQueryLimiterToContext(ctx, limiter.NewQueryLimiter(limits.MaxFetchedSeriesPerQuery(userID), limits.MaxFetchedChunkBytesPerQuery(userID), limits.MaxChunksPerQuery(userID), limits.MaxFetchedDataBytesPerQuery(userID)))
+
+		result, err := plan.Exec(ctx, queryable, maxResultRows)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.As(err, new(validation.LimitError)) {
+				status = http.StatusUnprocessableEntity
+			} else if errors.As(err, new(validation.AccessDeniedError)) {
+				status = http.StatusForbidden
+			}
+			writeError(status, err)
+			return
+		}
+
+		util.WriteJSONResponse(w, sqlSuccessResult{Status: statusSuccess, Data: result})
+	})
+}
@@ -0,0 +1,184 @@
+package querier
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/oklog/ulid/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/integration/e2e"
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	"github.com/cortexproject/cortex/pkg/storage/bucket/filesystem"
+	"github.com/cortexproject/cortex/pkg/storage/parquet"
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/parquetutil"
+	"github.com/cortexproject/cortex/pkg/util/requestmeta"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	cortex_testutil "github.com/cortexproject/cortex/pkg/util/testutil"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+func TestSQLHandler(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bkt, tempDir := cortex_testutil.PrepareFilesystemBucket(t)
+
+	lbls := []labels.Labels{
+		labels.FromStrings(labels.MetricName, "up", "job", "api", "pod", "api-1"),
+		labels.FromStrings(labels.MetricName, "up", "job", "api", "pod", "api-2"),
+		labels.FromStrings(labels.MetricName, "up", "job", "db", "pod", "db-1"),
+	}
+	// Each series has a single sample, whose value is the index of the series.
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	blockID, err := e2e.CreateBlock(ctx, rnd, tempDir, lbls, 1, 0, 1000, 100, 1000)
+	require.NoError(t, err)
+
+	blockDir := filepath.Join(tempDir, blockID.String())
+	userBkt := bucket.NewUserBucketClient("user-1", bkt, nil)
+	require.NoError(t, block.Upload(ctx, log.NewNopLogger(), userBkt, blockDir, metadata.NoneFunc))
+	require.NoError(t, convertBlockToParquet(t, ctx, userBkt, blockID, blockDir))
+
+	finder := &blocksFinderMock{}
+	finder.On("GetBlocks", mock.Anything, "user-1", mock.Anything, mock.Anything, mock.Anything).Return(bucketindex.Blocks{
+		&bucketindex.Block{ID: blockID, Parquet: &parquet.ConverterMarkMeta{Version: parquet.CurrentVersion}},
+	}, map[ulid.ULID]*bucketindex.BlockDeletionMark(nil), nil)
+
+	config := Config{
+		StoreGatewayConsistencyCheckMaxAttempts: 3,
+		ParquetShardCache:                       parquetutil.CacheConfig{ParquetShardCacheSize: 100},
+		// The SQL queries use the Parquet files, even if the default block store is TSDB.
+		ParquetQueryableDefaultBlockStore: string(tsdbBlockStore),
+		ParquetQueryableFallbackDisabled:  true,
+	}
+	storageCfg := cortex_tsdb.BlocksStorageConfig{
+		Bucket: bucket.Config{Backend: "filesystem", Filesystem: filesystem.Config{Directory: tempDir}},
+	}
+	blocksStoreQueryable := &BlocksStoreQueryable{finder: finder, Service: services.NewIdleService(nil, nil)}
+	queryable, err := NewParquetQueryable(config, storageCfg, defaultOverrides(t, 0), blocksStoreQueryable, log.NewNopLogger(), prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, queryable.(services.Service)))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(ctx, queryable.(services.Service)))
+	})
+
+	limits := validation.Limits{}
+	flagext.DefaultValues(&limits)
+	limits.QueryAccessPolicies = []validation.QueryAccessPolicy{{
+		Name:     "api-only",
+		Selector: `{job="api"}`,
+		Matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "job", "api")},
+	}}
+	handler := SQLHandler(queryable, validation.NewOverrides(limits, nil), 2)
+
+	tests := map[string]struct {
+		userID         string
+		policy         string
+		params         url.Values
+		expectedStatus int
+		expectedBody   string
+	}{
+		"should return the series cardinality by job": {
+			userID: "user-1",
+			params: url.Values{"query": {
+				"SELECT job, count(*) AS series, count(DISTINCT pod) AS pods FROM series WHERE timestamp >= 0 AND timestamp <= 1 GROUP BY job ORDER BY series DESC",
+			}},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"status":"success","data":{` +
+				`"columns":[{"name":"job","type":"string"},{"name":"series","type":"integer"},{"name":"pods","type":"integer"}],` +
+				`"rows":[["api",2,2],["db",1,1]]}}`,
+		},
+		"should aggregate the samples": {
+			userID: "user-1",
+			params: url.Values{"query": {
+				"SELECT pod, count(*), sum(value) FROM samples WHERE job = 'api' AND timestamp >= 0 AND timestamp <= 1 GROUP BY pod",
+			}},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"status":"success","data":{` +
+				`"columns":[{"name":"pod","type":"string"},{"name":"count(*)","type":"integer"},{"name":"sum(value)","type":"float"}],` +
+				`"rows":[["api-1",1,0],["api-2",1,1]]}}`,
+		},
+		"should only query the series of the access policy": {
+			userID: "user-1",
+			policy: "api-only",
+			params: url.Values{"query": {
+				"SELECT job, count(*) AS series FROM series WHERE timestamp >= 0 AND timestamp <= 1 GROUP BY job",
+			}},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"status":"success","data":{` +
+				`"columns":[{"name":"job","type":"string"},{"name":"series","type":"integer"}],` +
+				`"rows":[["api",2]]}}`,
+		},
+		"should fail with an unknown access policy": {
+			userID:         "user-1",
+			policy:         "unknown",
+			params:         url.Values{"query": {"SELECT pod FROM series WHERE timestamp >= 0 AND timestamp <= 1"}},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"status":"error","error":"query access policy unknown not found"}`,
+		},
+		"should fail if the result has too many rows": {
+			userID:         "user-1",
+			params:         url.Values{"query": {"SELECT pod FROM series WHERE timestamp >= 0 AND timestamp <= 1"}},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"status":"error","error":"the query returns more than 2 rows, use LIMIT or aggregate the rows"}`,
+		},
+		"should fail on an invalid query": {
+			userID:         "user-1",
+			params:         url.Values{"query": {"SELECT pod FROM series"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":"error","error":"the query must filter the timestamp column with both a lower and an upper bound"}`,
+		},
+		"should fail on an unsupported format": {
+			userID:         "user-1",
+			params:         url.Values{"query": {"SELECT pod FROM series"}, "format": {"csv"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":"error","error":"unsupported format \"csv\", the only supported format is json"}`,
+		},
+		"should fail on the arrow format, which is not implemented": {
+			userID:         "user-1",
+			params:         url.Values{"query": {"SELECT pod FROM series"}, "format": {"arrow"}},
+			expectedStatus: http.StatusNotImplemented,
+			expectedBody:   `{"status":"error","error":"the arrow format is not supported yet, the only supported format is json"}`,
+		},
+		"should fail without tenant": {
+			params:         url.Values{"query": {"SELECT pod FROM series WHERE timestamp >= 0 AND timestamp <= 1"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":"error","error":"no org id"}`,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/sql?"+testData.params.Encode(), nil)
+			if testData.userID != "" {
+				req = req.WithContext(user.InjectOrgID(req.Context(), testData.userID))
+			}
+			if testData.policy != "" {
+				req = req.WithContext(requestmeta.ContextWithAccessPolicy(req.Context(), testData.policy))
+			}
+
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+
+			require.Equal(t, testData.expectedStatus, resp.Code, resp.Body.String())
+			assert.JSONEq(t, testData.expectedBody, resp.Body.String())
+		})
+	}
+}
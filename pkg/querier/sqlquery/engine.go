@@ -0,0 +1,722 @@
+package sqlquery
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+// ColumnType is the type of the values of a result column.
+type ColumnType string
+
+const (
+	ColumnTypeString    ColumnType = "string"
+	ColumnTypeFloat     ColumnType = "float"
+	ColumnTypeInteger   ColumnType = "integer"
+	ColumnTypeTimestamp ColumnType = "timestamp"
+	ColumnTypeLabels    ColumnType = "labels"
+)
+
+// Column is a result column.
+type Column struct {
+	Name string     `json:"name"`
+	Type ColumnType `json:"type"`
+}
+
+// Result is the result of a query. The values of the rows are strings, Float, int64 (integers
+// and timestamps in milliseconds), map[string]string (labels) or nil.
+type Result struct {
+	Columns []Column `json:"columns"`
+	Rows    [][]any  `json:"rows"`
+}
+
+// Float is a float value, encoded in JSON as a string when it's not a finite number.
+type Float float64
+
+func (f Float) MarshalJSON() ([]byte, error) {
+	v := float64(f)
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return []byte(`"` + strconv.FormatFloat(v, 'f', -1, 64) + `"`), nil
+	}
+	return []byte(strconv.FormatFloat(v, 'g', -1, 64)), nil
+}
+
+// column is a result column, along with the expression computing it.
+type column struct {
+	Column
+
+	expr Expr
+
+	// allLabels is true for the column of the labels of the series selected by SELECT *.
+	allLabels bool
+}
+
+type orderKey struct {
+	column int
+	desc   bool
+}
+
+// Plan is a validated query, ready to be executed.
+type Plan struct {
+	query *Query
+
+	mint, maxt   int64
+	matchers     []*labels.Matcher
+	valueFilters []func(float64) bool
+
+	columns    []column
+	aggregated bool
+	order      []orderKey
+}
+
+// MinTime returns the lower bound of the queried time range, in milliseconds.
+func (p *Plan) MinTime() int64 {
+	return p.mint
+}
+
+// MaxTime returns the upper bound of the queried time range, in milliseconds.
+func (p *Plan) MaxTime() int64 {
+	return p.maxt
+}
+
+// Compile validates the query and plans its execution.
+func Compile(q *Query) (*Plan, error) {
+	p := &Plan{query: q, mint: math.MinInt64, maxt: math.MaxInt64}
+
+	if err := p.compileWhere(); err != nil {
+		return nil, err
+	}
+	if err := p.compileSelect(); err != nil {
+		return nil, err
+	}
+	if err := p.compileOrderBy(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *Plan) compileWhere() error {
+	hasMatcher := false
+
+	for _, cond := range p.query.Where {
+		switch cond.Column {
+		case ColumnTimestamp:
+			if err := p.compileTimeCondition(cond); err != nil {
+				return err
+			}
+
+		case ColumnValue:
+			if p.query.Table != TableSamples {
+				return fmt.Errorf("the %s column is only available in the %s table", ColumnValue, TableSamples)
+			}
+			filter, err := compileValueCondition(cond)
+			if err != nil {
+				return err
+			}
+			p.valueFilters = append(p.valueFilters, filter)
+
+		default:
+			m, err := compileLabelCondition(cond)
+			if err != nil {
+				return err
+			}
+			p.matchers = append(p.matchers, m)
+			hasMatcher = hasMatcher || !m.Matches("")
+		}
+	}
+
+	if p.mint == math.MinInt64 || p.maxt == math.MaxInt64 {
+		return fmt.Errorf("the query must filter the %s column with both a lower and an upper bound", ColumnTimestamp)
+	}
+	if p.mint > p.maxt {
+		return fmt.Errorf("the %s conditions select an empty time range", ColumnTimestamp)
+	}
+
+	// The storage requires at least one matcher which doesn't match the empty string, and all the
+	// series have a metric name.
+	if !hasMatcher {
+		p.matchers = append(p.matchers, labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, ".+"))
+	}
+	return nil
+}
+
+func (p *Plan) compileTimeCondition(cond Condition) error {
+	t, err := util.ParseTime(cond.Values[0].Text)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q, expected a unix timestamp in seconds or a RFC3339 date", ColumnTimestamp, cond.Values[0].Text)
+	}
+
+	switch cond.Op {
+	case "=":
+		p.mint, p.maxt = max(p.mint, t), min(p.maxt, t)
+	case ">":
+		p.mint = max(p.mint, t+1)
+	case ">=":
+		p.mint = max(p.mint, t)
+	case "<":
+		p.maxt = min(p.maxt, t-1)
+	case "<=":
+		p.maxt = min(p.maxt, t)
+	default:
+		return fmt.Errorf("operator %s is not supported on the %s column", cond.Op, ColumnTimestamp)
+	}
+	return nil
+}
+
+func compileValueCondition(cond Condition) (func(float64) bool, error) {
+	if !cond.Values[0].IsNumber {
+		return nil, fmt.Errorf("the %s column can only be compared to a number", ColumnValue)
+	}
+	// The literal has been validated by the parser.
+	x, _ := strconv.ParseFloat(cond.Values[0].Text, 64)
+
+	switch cond.Op {
+	case "=":
+		return func(v float64) bool { return v == x }, nil
+	case "!=":
+		return func(v float64) bool { return v != x }, nil
+	case "<":
+		return func(v float64) bool { return v < x }, nil
+	case "<=":
+		return func(v float64) bool { return v <= x }, nil
+	case ">":
+		return func(v float64) bool { return v > x }, nil
+	case ">=":
+		return func(v float64) bool { return v >= x }, nil
+	default:
+		return nil, fmt.Errorf("operator %s is not supported on the %s column", cond.Op, ColumnValue)
+	}
+}
+
+func compileLabelCondition(cond Condition) (*labels.Matcher, error) {
+	var (
+		matchType labels.MatchType
+		value     = cond.Values[0].Text
+	)
+
+	switch cond.Op {
+	case "=":
+		matchType = labels.MatchEqual
+	case "!=":
+		matchType = labels.MatchNotEqual
+	case "=~":
+		matchType = labels.MatchRegexp
+	case "!~":
+		matchType = labels.MatchNotRegexp
+	case "IN", "NOT IN":
+		values := make([]string, 0, len(cond.Values))
+		for _, v := range cond.Values {
+			values = append(values, regexp.QuoteMeta(v.Text))
+		}
+		matchType, value = labels.MatchRegexp, strings.Join(values, "|")
+		if cond.Op == "NOT IN" {
+			matchType = labels.MatchNotRegexp
+		}
+	case "LIKE", "NOT LIKE":
+		matchType, value = labels.MatchRegexp, likeToRegexp(value)
+		if cond.Op == "NOT LIKE" {
+			matchType = labels.MatchNotRegexp
+		}
+	default:
+		return nil, fmt.Errorf("operator %s is not supported on the label %s", cond.Op, cond.Column)
+	}
+
+	m, err := labels.NewMatcher(matchType, cond.Column, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition on the label %s: %w", cond.Column, err)
+	}
+	return m, nil
+}
+
+// likeToRegexp converts a LIKE pattern, in which % matches any sequence of characters and _
+// matches any character, to a regular expression.
+func likeToRegexp(pattern string) string {
+	var sb strings.Builder
+	for _, c := range pattern {
+		switch c {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
+
+func (p *Plan) compileSelect() error {
+	q := p.query
+
+	for _, column := range q.GroupBy {
+		if column == ColumnTimestamp || column == ColumnValue {
+			return fmt.Errorf("GROUP BY only supports labels")
+		}
+	}
+
+	p.aggregated = len(q.GroupBy) > 0
+	for _, item := range q.Select {
+		p.aggregated = p.aggregated || item.Expr.IsAggregation()
+	}
+
+	for _, item := range q.Select {
+		if item.Star {
+			if p.aggregated {
+				return fmt.Errorf("SELECT * is not supported in aggregation queries")
+			}
+			p.columns = append(p.columns, column{Column: Column{Name: "labels", Type: ColumnTypeLabels}, allLabels: true})
+			if q.Table == TableSamples {
+				p.columns = append(p.columns,
+					column{Column: Column{Name: ColumnTimestamp, Type: ColumnTypeTimestamp}, expr: Expr{Column: ColumnTimestamp}},
+					column{Column: Column{Name: ColumnValue, Type: ColumnTypeFloat}, expr: Expr{Column: ColumnValue}},
+				)
+			}
+			continue
+		}
+
+		expr := item.Expr
+		if q.Table != TableSamples && (expr.Column == ColumnTimestamp || expr.Column == ColumnValue) {
+			return fmt.Errorf("the %s column is only available in the %s table", expr.Column, TableSamples)
+		}
+		if p.aggregated && !expr.IsAggregation() && !slices.Contains(q.GroupBy, expr.Column) {
+			return fmt.Errorf("the %s column must be in the GROUP BY clause or be aggregated", expr.Column)
+		}
+
+		typ, err := exprType(expr)
+		if err != nil {
+			return err
+		}
+		p.columns = append(p.columns, column{Column: Column{Name: item.Name(), Type: typ}, expr: expr})
+	}
+	return nil
+}
+
+func exprType(expr Expr) (ColumnType, error) {
+	if expr.Distinct && expr.Func != FuncCount {
+		return "", fmt.Errorf("DISTINCT is only supported by %s()", FuncCount)
+	}
+
+	switch expr.Func {
+	case "":
+		return columnType(expr.Column), nil
+	case FuncCount:
+		return ColumnTypeInteger, nil
+	case FuncSum, FuncAvg:
+		if expr.Column != ColumnValue {
+			return "", fmt.Errorf("%s() only supports the %s column", expr.Func, ColumnValue)
+		}
+		return ColumnTypeFloat, nil
+	default:
+		if expr.Column != ColumnValue && expr.Column != ColumnTimestamp {
+			return "", fmt.Errorf("%s() only supports the %s and %s columns", expr.Func, ColumnValue, ColumnTimestamp)
+		}
+		return columnType(expr.Column), nil
+	}
+}
+
+func columnType(name string) ColumnType {
+	switch name {
+	case ColumnTimestamp:
+		return ColumnTypeTimestamp
+	case ColumnValue:
+		return ColumnTypeFloat
+	default:
+		return ColumnTypeString
+	}
+}
+
+func (p *Plan) compileOrderBy() error {
+	for _, item := range p.query.OrderBy {
+		idx := -1
+
+		switch {
+		case item.Position > 0:
+			if item.Position > len(p.columns) {
+				return fmt.Errorf("ORDER BY position %d is out of range", item.Position)
+			}
+			idx = item.Position - 1
+		case !item.Expr.IsAggregation():
+			// A column name can refer to an alias.
+			idx = slices.IndexFunc(p.columns, func(c column) bool { return c.Name == item.Expr.Column })
+		}
+		if idx < 0 {
+			idx = slices.IndexFunc(p.columns, func(c column) bool { return !c.allLabels && c.expr == item.Expr })
+		}
+		if idx < 0 {
+			return fmt.Errorf("ORDER BY %s must refer to a selected column", item.Expr)
+		}
+
+		p.order = append(p.order, orderKey{column: idx, desc: item.Desc})
+	}
+	return nil
+}
+
+// row is a row of the queried table.
+type row struct {
+	lbls  labels.Labels
+	t     int64
+	v     float64
+	valid bool
+}
+
+func (r *row) get(name string) any {
+	switch name {
+	case ColumnTimestamp:
+		return r.t
+	case ColumnValue:
+		return Float(r.v)
+	default:
+		return r.lbls.Get(name)
+	}
+}
+
+// Exec executes the query against the queryable. The number of result rows is limited to
+// maxRows, if greater than 0. The limit also applies while the query is executed, to the
+// rows kept to be sorted, to the groups of an aggregation and to the values counted by a
+// DISTINCT aggregation, so that the memory used by a query is bounded.
+func (p *Plan) Exec(ctx context.Context, queryable storage.Queryable, maxRows int) (*Result, error) {
+	q, err := queryable.Querier(p.mint, p.maxt)
+	if err != nil {
+		return nil, err
+	}
+	defer q.Close()
+
+	hints := &storage.SelectHints{Start: p.mint, End: p.maxt}
+	if p.query.Table == TableSeries {
+		hints.Func = "series"
+	}
+
+	var (
+		rows   [][]any
+		groups = map[string]*group{}
+		it     chunkenc.Iterator
+		r      row
+	)
+
+	// Without ORDER BY, the rows of non aggregation queries can be streamed up to the limit.
+	done := func() bool {
+		return !p.aggregated && len(p.order) == 0 && p.query.Limit >= 0 && len(rows) >= p.query.Limit
+	}
+	process := func() error {
+		if p.aggregated {
+			return p.aggregate(groups, &r, maxRows)
+		}
+
+		rows = append(rows, p.project(&r))
+		if maxRows <= 0 || len(rows) <= maxRows {
+			return nil
+		}
+		if p.query.Limit < 0 || p.query.Limit > maxRows {
+			return tooManyRowsError(maxRows)
+		}
+		// The rows are sorted, and only the first ones are kept, once enough rows are
+		// accumulated for the sorting cost to be amortized.
+		if len(rows) >= maxRows+p.query.Limit {
+			p.sort(rows)
+			rows = slices.Delete(rows, p.query.Limit, len(rows))
+		}
+		return nil
+	}
+
+	set := q.Select(ctx, true, hints, p.matchers...)
+	for !done() && set.Next() {
+		s := set.At()
+		r = row{lbls: s.Labels()}
+
+		if p.query.Table == TableSeries {
+			if err := process(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		it = s.Iterator(it)
+		for vt := it.Next(); vt != chunkenc.ValNone && !done(); vt = it.Next() {
+			// Only the float samples are supported.
+			if vt != chunkenc.ValFloat {
+				continue
+			}
+			r.t, r.v = it.At()
+			if r.t < p.mint || r.t > p.maxt || !p.matchValue(r.v) {
+				continue
+			}
+			if err := process(); err != nil {
+				return nil, err
+			}
+		}
+		if err := it.Err(); err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if err := set.Err(); err != nil {
+		return nil, err
+	}
+
+	if p.aggregated {
+		rows = p.aggregationRows(groups, maxRows)
+	}
+
+	p.sort(rows)
+	if p.query.Limit >= 0 && len(rows) > p.query.Limit {
+		rows = rows[:p.query.Limit]
+	}
+	if maxRows > 0 && len(rows) > maxRows {
+		return nil, tooManyRowsError(maxRows)
+	}
+
+	result := &Result{Columns: make([]Column, 0, len(p.columns)), Rows: rows}
+	for _, c := range p.columns {
+		result.Columns = append(result.Columns, c.Column)
+	}
+	if result.Rows == nil {
+		result.Rows = [][]any{}
+	}
+	return result, nil
+}
+
+func tooManyRowsError(maxRows int) error {
+	return validation.LimitError(fmt.Sprintf("the query returns more than %d rows, use LIMIT or aggregate the rows", maxRows))
+}
+
+func (p *Plan) matchValue(v float64) bool {
+	for _, filter := range p.valueFilters {
+		if !filter(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Plan) project(r *row) []any {
+	values := make([]any, 0, len(p.columns))
+	for _, c := range p.columns {
+		if c.allLabels {
+			values = append(values, r.lbls.Map())
+			continue
+		}
+		values = append(values, r.get(c.expr.Column))
+	}
+	return values
+}
+
+// group holds the state of the aggregations of the rows of a group.
+type group struct {
+	key          []string
+	accumulators []*accumulator
+}
+
+// aggregate adds the row to its group. The number of groups, and of values counted by each
+// DISTINCT aggregation, is limited to maxRows, if greater than 0.
+func (p *Plan) aggregate(groups map[string]*group, r *row, maxRows int) error {
+	key := make([]string, 0, len(p.query.GroupBy))
+	for _, name := range p.query.GroupBy {
+		key = append(key, r.lbls.Get(name))
+	}
+
+	id := strings.Join(key, "\xff")
+	g, ok := groups[id]
+	if !ok {
+		if maxRows > 0 && len(groups) >= maxRows {
+			return validation.LimitError(fmt.Sprintf("the query aggregates more than %d groups, use a more selective WHERE or fewer GROUP BY columns", maxRows))
+		}
+		g = p.newGroup(key, maxRows)
+		groups[id] = g
+	}
+
+	for _, acc := range g.accumulators {
+		if acc == nil {
+			continue
+		}
+		if err := acc.add(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Plan) newGroup(key []string, maxRows int) *group {
+	g := &group{key: key, accumulators: make([]*accumulator, len(p.columns))}
+	for i, c := range p.columns {
+		if c.expr.IsAggregation() {
+			g.accumulators[i] = &accumulator{expr: c.expr, maxDistinct: maxRows}
+		}
+	}
+	return g
+}
+
+func (p *Plan) aggregationRows(groups map[string]*group, maxRows int) [][]any {
+	// An aggregation without GROUP BY always returns a row, even if no row matches.
+	if len(groups) == 0 && len(p.query.GroupBy) == 0 {
+		groups[""] = p.newGroup(nil, maxRows)
+	}
+
+	ids := make([]string, 0, len(groups))
+	for id := range groups {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	rows := make([][]any, 0, len(groups))
+	for _, id := range ids {
+		g := groups[id]
+		values := make([]any, 0, len(p.columns))
+		for i, c := range p.columns {
+			if acc := g.accumulators[i]; acc != nil {
+				values = append(values, acc.result())
+				continue
+			}
+			values = append(values, g.key[slices.Index(p.query.GroupBy, c.expr.Column)])
+		}
+		rows = append(rows, values)
+	}
+	return rows
+}
+
+func (p *Plan) sort(rows [][]any) {
+	if len(p.order) == 0 {
+		return
+	}
+
+	slices.SortStableFunc(rows, func(a, b []any) int {
+		for _, key := range p.order {
+			c := compareValues(a[key.column], b[key.column])
+			if key.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+}
+
+// compareValues compares two values of the same column. The nil values come first.
+func compareValues(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case int64:
+		return cmp.Compare(a, b.(int64))
+	case Float:
+		// NaN values come first.
+		return cmp.Compare(a, b.(Float))
+	case map[string]string:
+		return labels.Compare(labels.FromMap(a), labels.FromMap(b.(map[string]string)))
+	default:
+		return 0
+	}
+}
+
+// accumulator computes an aggregation over the rows of a group.
+type accumulator struct {
+	expr Expr
+
+	// maxDistinct is the maximum number of values counted by a DISTINCT aggregation, if greater than 0.
+	maxDistinct int
+
+	count    int64
+	sum      float64
+	min, max float64
+	distinct map[string]struct{}
+}
+
+func (a *accumulator) add(r *row) error {
+	if a.expr.Column == "" {
+		a.count++
+		return nil
+	}
+
+	switch a.expr.Column {
+	case ColumnTimestamp:
+		return a.addValue(float64(r.t), strconv.FormatInt(r.t, 10))
+	case ColumnValue:
+		return a.addValue(r.v, strconv.FormatFloat(r.v, 'g', -1, 64))
+	default:
+		// A missing label is counted as a NULL value.
+		if value := r.lbls.Get(a.expr.Column); value != "" {
+			return a.addValue(0, value)
+		}
+		return nil
+	}
+}
+
+func (a *accumulator) addValue(v float64, key string) error {
+	if a.expr.Distinct {
+		if a.distinct == nil {
+			a.distinct = map[string]struct{}{}
+		}
+		if _, ok := a.distinct[key]; !ok && a.maxDistinct > 0 && len(a.distinct) >= a.maxDistinct {
+			return validation.LimitError(fmt.Sprintf("the query counts more than %d distinct values", a.maxDistinct))
+		}
+		a.distinct[key] = struct{}{}
+		return nil
+	}
+
+	if a.count == 0 || v < a.min || math.IsNaN(a.min) {
+		a.min = v
+	}
+	if a.count == 0 || v > a.max || math.IsNaN(a.max) {
+		a.max = v
+	}
+	a.sum += v
+	a.count++
+	return nil
+}
+
+func (a *accumulator) result() any {
+	switch a.expr.Func {
+	case FuncCount:
+		if a.expr.Distinct {
+			return int64(len(a.distinct))
+		}
+		return a.count
+	case FuncSum:
+		if a.count == 0 {
+			return nil
+		}
+		return Float(a.sum)
+	case FuncAvg:
+		if a.count == 0 {
+			return nil
+		}
+		return Float(a.sum / float64(a.count))
+	}
+
+	// min() and max().
+	if a.count == 0 {
+		return nil
+	}
+	v := a.min
+	if a.expr.Func == FuncMax {
+		v = a.max
+	}
+	if a.expr.Column == ColumnTimestamp {
+		return int64(v)
+	}
+	return Float(v)
+}
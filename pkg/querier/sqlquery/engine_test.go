@@ -0,0 +1,255 @@
+package sqlquery
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/util/annotations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/querier/series"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+type mockQuerier struct {
+	series []storage.Series
+
+	hints    *storage.SelectHints
+	matchers []*labels.Matcher
+}
+
+func (m *mockQuerier) Select(_ context.Context, sortSeries bool, hints *storage.SelectHints, matchers ...*labels.Matcher) storage.SeriesSet {
+	m.hints, m.matchers = hints, matchers
+
+	var selected []storage.Series
+	for _, s := range m.series {
+		if matchesAll(s.Labels(), matchers) {
+			selected = append(selected, s)
+		}
+	}
+	return series.NewConcreteSeriesSet(sortSeries, selected)
+}
+
+func matchesAll(lbls labels.Labels, matchers []*labels.Matcher) bool {
+	for _, m := range matchers {
+		if !m.Matches(lbls.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *mockQuerier) LabelValues(context.Context, string, *storage.LabelHints, ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	return nil, nil, nil
+}
+
+func (m *mockQuerier) LabelNames(context.Context, *storage.LabelHints, ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	return nil, nil, nil
+}
+
+func (m *mockQuerier) Close() error {
+	return nil
+}
+
+func TestPlan_Exec(t *testing.T) {
+	newSeries := func(lbls labels.Labels, values ...float64) storage.Series {
+		samples := make([]model.SamplePair, 0, len(values))
+		for i, v := range values {
+			samples = append(samples, model.SamplePair{Timestamp: model.Time(i * 1000), Value: model.SampleValue(v)})
+		}
+		return series.NewConcreteSeries(lbls, samples)
+	}
+
+	querier := &mockQuerier{series: []storage.Series{
+		newSeries(labels.FromStrings(labels.MetricName, "up", "job", "api", "pod", "api-1"), 1, 1, 0),
+		newSeries(labels.FromStrings(labels.MetricName, "up", "job", "api", "pod", "api-2"), 1, 1, 1),
+		newSeries(labels.FromStrings(labels.MetricName, "up", "job", "db", "pod", "db-1"), 0, 1, math.NaN()),
+		newSeries(labels.FromStrings(labels.MetricName, "http_requests_total", "job", "api", "pod", "api-1", "code", "200"), 10, 20, 30),
+		newSeries(labels.FromStrings(labels.MetricName, "http_requests_total", "job", "api", "pod", "api-1", "code", "500"), 1, 2, 3),
+	}}
+	queryable := storage.QueryableFunc(func(int64, int64) (storage.Querier, error) {
+		return querier, nil
+	})
+
+	const timeRange = "timestamp >= 0 AND timestamp <= 2"
+
+	tests := map[string]struct {
+		query            string
+		maxRows          int
+		expectedColumns  []Column
+		expectedRows     [][]any
+		expectedErr      string
+		expectedMatchers []string
+		expectedFunc     string
+	}{
+		"series cardinality by job": {
+			query:            "SELECT job, count(*) AS series, count(DISTINCT pod) FROM series WHERE " + timeRange + " GROUP BY job ORDER BY series DESC",
+			expectedColumns:  []Column{{"job", ColumnTypeString}, {"series", ColumnTypeInteger}, {"count(DISTINCT pod)", ColumnTypeInteger}},
+			expectedRows:     [][]any{{"api", int64(4), int64(2)}, {"db", int64(1), int64(1)}},
+			expectedMatchers: []string{`__name__=~".+"`},
+			expectedFunc:     "series",
+		},
+		"series with a missing label": {
+			query:            "SELECT code, count(*), count(code) FROM series WHERE job = 'api' AND " + timeRange + " GROUP BY code",
+			expectedColumns:  []Column{{"code", ColumnTypeString}, {"count(*)", ColumnTypeInteger}, {"count(code)", ColumnTypeInteger}},
+			expectedRows:     [][]any{{"", int64(2), int64(0)}, {"200", int64(1), int64(1)}, {"500", int64(1), int64(1)}},
+			expectedMatchers: []string{`job="api"`},
+			expectedFunc:     "series",
+		},
+		"list of series": {
+			query:           "SELECT * FROM series WHERE __name__ = 'up' AND job IN ('db', 'other') AND " + timeRange,
+			expectedColumns: []Column{{"labels", ColumnTypeLabels}},
+			expectedRows:    [][]any{{map[string]string{"__name__": "up", "job": "db", "pod": "db-1"}}},
+			expectedFunc:    "series",
+		},
+		"aggregation of samples": {
+			query: "SELECT __name__, code, count(*), sum(value), avg(value), min(value), max(value), min(timestamp), max(timestamp) FROM samples " +
+				"WHERE pod LIKE 'api-_' AND " + timeRange + " GROUP BY __name__, code ORDER BY sum(value) DESC",
+			expectedColumns: []Column{
+				{"__name__", ColumnTypeString}, {"code", ColumnTypeString}, {"count(*)", ColumnTypeInteger},
+				{"sum(value)", ColumnTypeFloat}, {"avg(value)", ColumnTypeFloat}, {"min(value)", ColumnTypeFloat}, {"max(value)", ColumnTypeFloat},
+				{"min(timestamp)", ColumnTypeTimestamp}, {"max(timestamp)", ColumnTypeTimestamp},
+			},
+			expectedRows: [][]any{
+				{"http_requests_total", "200", int64(3), Float(60), Float(20), Float(10), Float(30), int64(0), int64(2000)},
+				{"http_requests_total", "500", int64(3), Float(6), Float(2), Float(1), Float(3), int64(0), int64(2000)},
+				{"up", "", int64(6), Float(5), Float(5.0 / 6), Float(0), Float(1), int64(0), int64(2000)},
+			},
+			expectedMatchers: []string{`pod=~"api-."`},
+		},
+		"samples filtered by time and value": {
+			query:           "SELECT pod, timestamp, value FROM samples WHERE __name__ = 'up' AND value < 1 AND timestamp > 0 AND timestamp <= 2 ORDER BY 1",
+			expectedColumns: []Column{{"pod", ColumnTypeString}, {ColumnTimestamp, ColumnTypeTimestamp}, {ColumnValue, ColumnTypeFloat}},
+			expectedRows:    [][]any{{"api-1", int64(2000), Float(0)}},
+		},
+		"samples limited without ORDER BY": {
+			query:           "SELECT * FROM samples WHERE code = '500' AND " + timeRange + " LIMIT 2",
+			expectedColumns: []Column{{"labels", ColumnTypeLabels}, {ColumnTimestamp, ColumnTypeTimestamp}, {ColumnValue, ColumnTypeFloat}},
+			expectedRows: [][]any{
+				{map[string]string{"__name__": "http_requests_total", "job": "api", "pod": "api-1", "code": "500"}, int64(0), Float(1)},
+				{map[string]string{"__name__": "http_requests_total", "job": "api", "pod": "api-1", "code": "500"}, int64(1000), Float(2)},
+			},
+		},
+		"aggregation without matching rows": {
+			query:           "SELECT count(*), sum(value) FROM samples WHERE job = 'unknown' AND " + timeRange,
+			expectedColumns: []Column{{"count(*)", ColumnTypeInteger}, {"sum(value)", ColumnTypeFloat}},
+			expectedRows:    [][]any{{int64(0), nil}},
+		},
+		"max result rows": {
+			query:       "SELECT pod FROM series WHERE " + timeRange,
+			maxRows:     4,
+			expectedErr: "the query returns more than 4 rows, use LIMIT or aggregate the rows",
+		},
+		"samples sorted and limited within the max result rows": {
+			query:           "SELECT pod, value FROM samples WHERE " + timeRange + " ORDER BY value DESC LIMIT 2",
+			maxRows:         3,
+			expectedColumns: []Column{{"pod", ColumnTypeString}, {ColumnValue, ColumnTypeFloat}},
+			expectedRows:    [][]any{{"api-1", Float(30)}, {"api-1", Float(20)}},
+		},
+		"max result rows with a LIMIT over the max result rows": {
+			query:       "SELECT pod, value FROM samples WHERE " + timeRange + " ORDER BY value DESC LIMIT 5",
+			maxRows:     3,
+			expectedErr: "the query returns more than 3 rows, use LIMIT or aggregate the rows",
+		},
+		"max result rows applied to the groups": {
+			query:       "SELECT pod, count(*) FROM series WHERE " + timeRange + " GROUP BY pod ORDER BY 2 DESC LIMIT 1",
+			maxRows:     2,
+			expectedErr: "the query aggregates more than 2 groups, use a more selective WHERE or fewer GROUP BY columns",
+		},
+		"max result rows applied to the distinct values": {
+			query:       "SELECT count(DISTINCT pod) FROM series WHERE " + timeRange,
+			maxRows:     2,
+			expectedErr: "the query counts more than 2 distinct values",
+		},
+		"missing time range": {
+			query:       "SELECT pod FROM series WHERE timestamp > 0",
+			expectedErr: "the query must filter the timestamp column with both a lower and an upper bound",
+		},
+		"invalid timestamp": {
+			query:       "SELECT pod FROM series WHERE timestamp > 'yesterday'",
+			expectedErr: `invalid timestamp "yesterday", expected a unix timestamp in seconds or a RFC3339 date`,
+		},
+		"value column in the series table": {
+			query:       "SELECT pod, value FROM series WHERE " + timeRange,
+			expectedErr: "the value column is only available in the samples table",
+		},
+		"column neither grouped nor aggregated": {
+			query:       "SELECT job, pod, count(*) FROM series WHERE " + timeRange + " GROUP BY job",
+			expectedErr: "the pod column must be in the GROUP BY clause or be aggregated",
+		},
+		"sum of a label": {
+			query:       "SELECT sum(pod) FROM samples WHERE " + timeRange,
+			expectedErr: "sum() only supports the value column",
+		},
+		"ORDER BY an unselected column": {
+			query:       "SELECT job FROM series WHERE " + timeRange + " ORDER BY pod",
+			expectedErr: "ORDER BY pod must refer to a selected column",
+		},
+		"comparison of a label": {
+			query:       "SELECT job FROM series WHERE code > 200 AND " + timeRange,
+			expectedErr: "operator > is not supported on the label code",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, err := Parse(testData.query)
+			require.NoError(t, err)
+
+			plan, err := Compile(q)
+			if err == nil {
+				var result *Result
+				result, err = plan.Exec(context.Background(), queryable, testData.maxRows)
+				if err == nil {
+					require.Empty(t, testData.expectedErr)
+					assert.Equal(t, testData.expectedColumns, result.Columns)
+					assert.Equal(t, testData.expectedRows, result.Rows)
+				}
+			}
+			if testData.expectedErr != "" {
+				require.EqualError(t, err, testData.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, plan.MinTime(), querier.hints.Start)
+			assert.Equal(t, plan.MaxTime(), querier.hints.End)
+			assert.Equal(t, testData.expectedFunc, querier.hints.Func)
+			if testData.expectedMatchers != nil {
+				var matchers []string
+				for _, m := range querier.matchers {
+					matchers = append(matchers, m.String())
+				}
+				assert.Equal(t, testData.expectedMatchers, matchers)
+			}
+		})
+	}
+}
+
+func TestPlan_Exec_MaxRowsIsALimitError(t *testing.T) {
+	q, err := Parse("SELECT * FROM series WHERE timestamp >= 0 AND timestamp <= 1")
+	require.NoError(t, err)
+	plan, err := Compile(q)
+	require.NoError(t, err)
+
+	queryable := storage.QueryableFunc(func(int64, int64) (storage.Querier, error) {
+		return &mockQuerier{series: []storage.Series{
+			series.NewConcreteSeries(labels.FromStrings(labels.MetricName, "a"), nil),
+			series.NewConcreteSeries(labels.FromStrings(labels.MetricName, "b"), nil),
+		}}, nil
+	})
+	_, err = plan.Exec(context.Background(), queryable, 1)
+	assert.ErrorAs(t, err, new(validation.LimitError))
+}
+
+func TestFloat_MarshalJSON(t *testing.T) {
+	data, err := json.Marshal([]any{Float(1.5), Float(math.NaN()), Float(math.Inf(1)), Float(math.Inf(-1))})
+	require.NoError(t, err)
+	assert.Equal(t, `[1.5,"NaN","+Inf","-Inf"]`, string(data))
+}
@@ -0,0 +1,147 @@
+package sqlquery
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenQuotedIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenComma
+	tokenLeftParen
+	tokenRightParen
+	tokenStar
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// isKeyword returns whether the token is the given keyword. The keywords are case insensitive,
+// and a quoted identifier is never a keyword.
+func (t token) isKeyword(keyword string) bool {
+	return t.kind == tokenIdent && strings.EqualFold(t.text, keyword)
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of query"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+var operators = []string{"<=", ">=", "<>", "!=", "=~", "!~", "=", "<", ">"}
+
+// lex splits the query into tokens.
+func lex(query string) ([]token, error) {
+	var tokens []token
+
+	for pos := 0; pos < len(query); {
+		c := query[pos]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+
+		case c == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: pos})
+			pos++
+
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, text: "(", pos: pos})
+			pos++
+
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, text: ")", pos: pos})
+			pos++
+
+		case c == '*':
+			tokens = append(tokens, token{kind: tokenStar, text: "*", pos: pos})
+			pos++
+
+		case c == '\'' || c == '"':
+			text, end, err := lexQuoted(query, pos)
+			if err != nil {
+				return nil, err
+			}
+			kind := tokenString
+			if c == '"' {
+				kind = tokenQuotedIdent
+			}
+			tokens = append(tokens, token{kind: kind, text: text, pos: pos})
+			pos = end
+
+		case isDigit(c) || (c == '-' || c == '.') && pos+1 < len(query) && isDigit(query[pos+1]):
+			end := pos + 1
+			for end < len(query) && (isDigit(query[end]) || query[end] == '.' || query[end] == 'e' || query[end] == 'E' ||
+				(query[end] == '-' || query[end] == '+') && (query[end-1] == 'e' || query[end-1] == 'E')) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: query[pos:end], pos: pos})
+			pos = end
+
+		case c == '_' || isLetter(c):
+			end := pos + 1
+			for end < len(query) && (query[end] == '_' || isDigit(query[end]) || isLetter(query[end])) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: query[pos:end], pos: pos})
+			pos = end
+
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(query[pos:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, pos)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: pos})
+			pos += len(op)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(query)}), nil
+}
+
+// lexQuoted reads the string or identifier quoted at the given position, in which the quote is
+// escaped by doubling it, and returns it along with the position following the closing quote.
+func lexQuoted(query string, pos int) (string, int, error) {
+	quote := query[pos]
+	var sb strings.Builder
+
+	for i := pos + 1; i < len(query); i++ {
+		if query[i] != quote {
+			sb.WriteByte(query[i])
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == quote {
+			sb.WriteByte(quote)
+			i++
+			continue
+		}
+		return sb.String(), i + 1, nil
+	}
+
+	return "", 0, fmt.Errorf("unterminated quoted string at position %d", pos)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
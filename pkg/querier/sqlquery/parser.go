@@ -0,0 +1,456 @@
+package sqlquery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Table is a table which can be queried.
+type Table string
+
+const (
+	// TableSeries has a row per series, with a column per label.
+	TableSeries Table = "series"
+
+	// TableSamples has a row per float sample, with a column per label of its series and the
+	// timestamp and value columns.
+	TableSamples Table = "samples"
+)
+
+const (
+	// ColumnTimestamp is the column of the sample timestamps, in milliseconds.
+	ColumnTimestamp = "timestamp"
+
+	// ColumnValue is the column of the sample values.
+	ColumnValue = "value"
+)
+
+// Aggregation functions.
+const (
+	FuncCount = "count"
+	FuncSum   = "sum"
+	FuncAvg   = "avg"
+	FuncMin   = "min"
+	FuncMax   = "max"
+)
+
+var aggregationFuncs = []string{FuncCount, FuncSum, FuncAvg, FuncMin, FuncMax}
+
+// Query is a parsed SQL query.
+type Query struct {
+	Select  []SelectItem
+	Table   Table
+	Where   []Condition
+	GroupBy []string
+	OrderBy []OrderItem
+
+	// Limit is the maximum number of rows returned, or -1 if there's no LIMIT clause.
+	Limit int
+}
+
+// Expr is a column or an aggregation of a column.
+type Expr struct {
+	// Func is the aggregation function, or empty for a column.
+	Func string
+
+	// Column is the column name, or empty for count(*).
+	Column string
+
+	Distinct bool
+}
+
+// IsAggregation returns whether the expression aggregates the rows.
+func (e Expr) IsAggregation() bool {
+	return e.Func != ""
+}
+
+func (e Expr) String() string {
+	switch {
+	case e.Func == "":
+		return e.Column
+	case e.Column == "":
+		return e.Func + "(*)"
+	case e.Distinct:
+		return e.Func + "(DISTINCT " + e.Column + ")"
+	default:
+		return e.Func + "(" + e.Column + ")"
+	}
+}
+
+// SelectItem is an expression of the SELECT clause, or * for all the columns.
+type SelectItem struct {
+	Star  bool
+	Expr  Expr
+	Alias string
+}
+
+// Name returns the name of the result column.
+func (s SelectItem) Name() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+	return s.Expr.String()
+}
+
+// Condition is a condition of the WHERE clause, comparing a column to one or more values.
+type Condition struct {
+	Column string
+
+	// Op is one of =, !=, =~, !~, <, <=, >, >=, IN, NOT IN, LIKE and NOT LIKE.
+	Op     string
+	Values []Literal
+}
+
+// Literal is a string or number value.
+type Literal struct {
+	Text     string
+	IsNumber bool
+}
+
+// OrderItem is an expression of the ORDER BY clause.
+type OrderItem struct {
+	Expr Expr
+
+	// Position is the 1-based position of the ordered SELECT expression, if ordering by position.
+	Position int
+
+	Desc bool
+}
+
+// Parse parses the SQL query.
+func Parse(query string) (*Query, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	q, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) unexpected(t token, expected string) error {
+	return fmt.Errorf("unexpected %s at position %d, expected %s", t, t.pos, expected)
+}
+
+// acceptKeyword consumes the next token if it's the given keyword.
+func (p *parser) acceptKeyword(keyword string) bool {
+	if p.peek().isKeyword(keyword) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if t := p.next(); !t.isKeyword(keyword) {
+		return p.unexpected(t, keyword)
+	}
+	return nil
+}
+
+func (p *parser) expect(kind tokenKind, expected string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, p.unexpected(t, expected)
+	}
+	return t, nil
+}
+
+// parseIdent parses a column name, which can be double-quoted.
+func (p *parser) parseIdent() (string, error) {
+	t := p.next()
+	if t.kind != tokenQuotedIdent && (t.kind != tokenIdent || isReservedKeyword(t.text)) {
+		return "", p.unexpected(t, "column name")
+	}
+	return t.text, nil
+}
+
+func (p *parser) parseQuery() (*Query, error) {
+	q := &Query{Limit: -1}
+
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	for {
+		item, err := p.parseSelectItem()
+		if err != nil {
+			return nil, err
+		}
+		q.Select = append(q.Select, item)
+		if p.peek().kind != tokenComma {
+			break
+		}
+		p.next()
+	}
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	table, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	q.Table = Table(strings.ToLower(table))
+	if q.Table != TableSeries && q.Table != TableSamples {
+		return nil, fmt.Errorf("unknown table %q, supported tables are %s and %s", table, TableSeries, TableSamples)
+	}
+
+	if p.acceptKeyword("WHERE") {
+		for {
+			cond, err := p.parseCondition()
+			if err != nil {
+				return nil, err
+			}
+			q.Where = append(q.Where, cond)
+			if !p.acceptKeyword("AND") {
+				break
+			}
+		}
+	}
+
+	if p.acceptKeyword("GROUP") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			column, err := p.parseIdent()
+			if err != nil {
+				return nil, err
+			}
+			q.GroupBy = append(q.GroupBy, column)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			item, err := p.parseOrderItem()
+			if err != nil {
+				return nil, err
+			}
+			q.OrderBy = append(q.OrderBy, item)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if p.acceptKeyword("LIMIT") {
+		t, err := p.expect(tokenNumber, "number")
+		if err != nil {
+			return nil, err
+		}
+		limit, err := strconv.Atoi(t.text)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid limit %q", t.text)
+		}
+		q.Limit = limit
+	}
+
+	if t := p.next(); t.kind != tokenEOF {
+		return nil, p.unexpected(t, "end of query")
+	}
+	return q, nil
+}
+
+func (p *parser) parseSelectItem() (SelectItem, error) {
+	if p.peek().kind == tokenStar {
+		p.next()
+		return SelectItem{Star: true}, nil
+	}
+
+	expr, err := p.parseExpr()
+	if err != nil {
+		return SelectItem{}, err
+	}
+	item := SelectItem{Expr: expr}
+
+	if p.acceptKeyword("AS") {
+		if item.Alias, err = p.parseIdent(); err != nil {
+			return SelectItem{}, err
+		}
+	}
+	return item, nil
+}
+
+// parseExpr parses a column or an aggregation function call.
+func (p *parser) parseExpr() (Expr, error) {
+	t := p.peek()
+	if t.kind == tokenIdent && isAggregationFunc(t.text) && p.tokens[p.pos+1].kind == tokenLeftParen {
+		p.next()
+		p.next()
+		expr := Expr{Func: strings.ToLower(t.text)}
+
+		if p.peek().kind == tokenStar {
+			if expr.Func != FuncCount {
+				return Expr{}, fmt.Errorf("%s(*) is not supported", expr.Func)
+			}
+			p.next()
+		} else {
+			expr.Distinct = p.acceptKeyword("DISTINCT")
+			column, err := p.parseIdent()
+			if err != nil {
+				return Expr{}, err
+			}
+			expr.Column = column
+		}
+
+		if _, err := p.expect(tokenRightParen, ")"); err != nil {
+			return Expr{}, err
+		}
+		return expr, nil
+	}
+
+	column, err := p.parseIdent()
+	if err != nil {
+		return Expr{}, err
+	}
+	return Expr{Column: column}, nil
+}
+
+func (p *parser) parseCondition() (Condition, error) {
+	column, err := p.parseIdent()
+	if err != nil {
+		return Condition{}, err
+	}
+	cond := Condition{Column: column}
+
+	t := p.next()
+	switch {
+	case t.kind == tokenOperator:
+		cond.Op = t.text
+		if cond.Op == "<>" {
+			cond.Op = "!="
+		}
+	case t.isKeyword("IN"), t.isKeyword("LIKE"):
+		cond.Op = strings.ToUpper(t.text)
+	case t.isKeyword("NOT"):
+		op := p.next()
+		if !op.isKeyword("IN") && !op.isKeyword("LIKE") {
+			return Condition{}, p.unexpected(op, "IN or LIKE")
+		}
+		cond.Op = "NOT " + strings.ToUpper(op.text)
+	default:
+		return Condition{}, p.unexpected(t, "comparison operator")
+	}
+
+	if cond.Op != "IN" && cond.Op != "NOT IN" {
+		value, err := p.parseLiteral()
+		if err != nil {
+			return Condition{}, err
+		}
+		cond.Values = []Literal{value}
+		return cond, nil
+	}
+
+	if _, err := p.expect(tokenLeftParen, "("); err != nil {
+		return Condition{}, err
+	}
+	for {
+		value, err := p.parseLiteral()
+		if err != nil {
+			return Condition{}, err
+		}
+		cond.Values = append(cond.Values, value)
+		if p.peek().kind != tokenComma {
+			break
+		}
+		p.next()
+	}
+	if _, err := p.expect(tokenRightParen, ")"); err != nil {
+		return Condition{}, err
+	}
+	return cond, nil
+}
+
+func (p *parser) parseLiteral() (Literal, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString:
+		return Literal{Text: t.text}, nil
+	case tokenNumber:
+		if _, err := strconv.ParseFloat(t.text, 64); err != nil {
+			return Literal{}, fmt.Errorf("invalid number %q at position %d", t.text, t.pos)
+		}
+		return Literal{Text: t.text, IsNumber: true}, nil
+	default:
+		return Literal{}, p.unexpected(t, "string or number")
+	}
+}
+
+func (p *parser) parseOrderItem() (OrderItem, error) {
+	var item OrderItem
+
+	if t := p.peek(); t.kind == tokenNumber {
+		p.next()
+		position, err := strconv.Atoi(t.text)
+		if err != nil || position < 1 {
+			return OrderItem{}, fmt.Errorf("invalid ORDER BY position %q", t.text)
+		}
+		item.Position = position
+	} else {
+		expr, err := p.parseExpr()
+		if err != nil {
+			return OrderItem{}, err
+		}
+		item.Expr = expr
+	}
+
+	if p.acceptKeyword("DESC") {
+		item.Desc = true
+	} else {
+		p.acceptKeyword("ASC")
+	}
+	return item, nil
+}
+
+var reservedKeywords = []string{"SELECT", "FROM", "WHERE", "AND", "OR", "NOT", "IN", "LIKE", "GROUP", "ORDER", "BY", "ASC", "DESC", "LIMIT", "AS", "DISTINCT"}
+
+// isReservedKeyword returns whether the identifier is a keyword, which needs to be double-quoted
+// to be used as a column name.
+func isReservedKeyword(ident string) bool {
+	for _, k := range reservedKeywords {
+		if strings.EqualFold(ident, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func isAggregationFunc(ident string) bool {
+	for _, f := range aggregationFuncs {
+		if strings.EqualFold(ident, f) {
+			return true
+		}
+	}
+	return false
+}
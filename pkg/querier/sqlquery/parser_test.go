@@ -0,0 +1,101 @@
+package sqlquery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		query       string
+		expected    *Query
+		expectedErr string
+	}{
+		"aggregation with all the clauses": {
+			query: `select job, count(*) AS series, count(DISTINCT "pod") from series ` +
+				`where timestamp >= '2024-01-01T00:00:00Z' and timestamp < 1704070800 and env in ('prod', 'staging') and instance not like 'test-%' and __name__ =~ 'http_.*' and code <> 500 ` +
+				`group by job order by 2 desc, job limit 10`,
+			expected: &Query{
+				Select: []SelectItem{
+					{Expr: Expr{Column: "job"}},
+					{Expr: Expr{Func: FuncCount}, Alias: "series"},
+					{Expr: Expr{Func: FuncCount, Column: "pod", Distinct: true}},
+				},
+				Table: TableSeries,
+				Where: []Condition{
+					{Column: ColumnTimestamp, Op: ">=", Values: []Literal{{Text: "2024-01-01T00:00:00Z"}}},
+					{Column: ColumnTimestamp, Op: "<", Values: []Literal{{Text: "1704070800", IsNumber: true}}},
+					{Column: "env", Op: "IN", Values: []Literal{{Text: "prod"}, {Text: "staging"}}},
+					{Column: "instance", Op: "NOT LIKE", Values: []Literal{{Text: "test-%"}}},
+					{Column: "__name__", Op: "=~", Values: []Literal{{Text: "http_.*"}}},
+					{Column: "code", Op: "!=", Values: []Literal{{Text: "500", IsNumber: true}}},
+				},
+				GroupBy: []string{"job"},
+				OrderBy: []OrderItem{{Position: 2, Desc: true}, {Expr: Expr{Column: "job"}}},
+				Limit:   10,
+			},
+		},
+		"samples with quoted strings and negative numbers": {
+			query: `SELECT *, max(value) FROM samples WHERE "group" = 'it''s' AND value > -1.5e3 ORDER BY max(value) ASC`,
+			expected: &Query{
+				Select: []SelectItem{{Star: true}, {Expr: Expr{Func: FuncMax, Column: ColumnValue}}},
+				Table:  TableSamples,
+				Where: []Condition{
+					{Column: "group", Op: "=", Values: []Literal{{Text: "it's"}}},
+					{Column: ColumnValue, Op: ">", Values: []Literal{{Text: "-1.5e3", IsNumber: true}}},
+				},
+				OrderBy: []OrderItem{{Expr: Expr{Func: FuncMax, Column: ColumnValue}}},
+				Limit:   -1,
+			},
+		},
+		"unknown table": {
+			query:       `SELECT job FROM metrics`,
+			expectedErr: `unknown table "metrics", supported tables are series and samples`,
+		},
+		"OR is not supported": {
+			query:       `SELECT job FROM series WHERE job = 'a' OR job = 'b'`,
+			expectedErr: `unexpected "OR" at position 39, expected end of query`,
+		},
+		"keyword used as column name": {
+			query:       `SELECT group FROM series`,
+			expectedErr: `unexpected "group" at position 7, expected column name`,
+		},
+		"sum of all the columns": {
+			query:       `SELECT sum(*) FROM samples`,
+			expectedErr: `sum(*) is not supported`,
+		},
+		"unterminated string": {
+			query:       `SELECT job FROM series WHERE job = 'a`,
+			expectedErr: `unterminated quoted string at position 35`,
+		},
+		"unexpected character": {
+			query:       `SELECT job; FROM series`,
+			expectedErr: `unexpected character ';' at position 10`,
+		},
+		"missing query": {
+			query:       ``,
+			expectedErr: `unexpected end of query at position 0, expected SELECT`,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, err := Parse(testData.query)
+			if testData.expectedErr != "" {
+				require.EqualError(t, err, testData.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testData.expected, q)
+		})
+	}
+}
+
+func TestExpr_String(t *testing.T) {
+	assert.Equal(t, "job", Expr{Column: "job"}.String())
+	assert.Equal(t, "count(*)", Expr{Func: FuncCount}.String())
+	assert.Equal(t, "count(DISTINCT pod)", Expr{Func: FuncCount, Column: "pod", Distinct: true}.String())
+	assert.Equal(t, "avg(value)", Expr{Func: FuncAvg, Column: ColumnValue}.String())
+}
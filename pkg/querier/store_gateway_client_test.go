@@ -81,3 +81,7 @@ func (m *mockStoreGatewayServer) LabelNames(context.Context, *storepb.LabelNames
 func (m *mockStoreGatewayServer) LabelValues(context.Context, *storepb.LabelValuesRequest) (*storepb.LabelValuesResponse, error) {
 	return nil, nil
 }
+
+func (m *mockStoreGatewayServer) QueriedMetrics(context.Context, *storegatewaypb.QueriedMetricsRequest) (*storegatewaypb.QueriedMetricsResponse, error) {
+	return nil, nil
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/querier/tripperware"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
 	util_log "github.com/cortexproject/cortex/pkg/util/log"
+	"github.com/cortexproject/cortex/pkg/util/requestmeta"
 	"github.com/cortexproject/cortex/pkg/util/spanlogger"
 	"github.com/cortexproject/cortex/pkg/util/users"
 	"github.com/cortexproject/cortex/pkg/util/validation"
@@ -161,6 +162,17 @@ func (t splitter) GenerateCacheKey(ctx context.Context, userID string, r tripper
 	return fmt.Sprintf("%s:%s:%d:%d", userID, r.GetQuery(), r.GetStep(), currentInterval)
 }
 
+// cacheKeyTenant returns the tenant part of the cache key. It includes the query access
+// policy selected by the request, if any, so that results are never shared across policies.
+// The policy is separated by a character tenant IDs can't contain.
+func cacheKeyTenant(ctx context.Context, tenantIDs []string) string {
+	tenant := users.JoinTenantIDs(tenantIDs)
+	if policy := requestmeta.AccessPolicyFromContext(ctx); policy != "" {
+		return tenant + "@" + policy
+	}
+	return tenant
+}
+
 // ShouldCacheFn checks whether the current request should go to cache
 // or not. If not, just send the request to next handler.
 type ShouldCacheFn func(r tripperware.Request) bool
@@ -240,7 +252,7 @@ func (s resultsCache) Do(ctx context.Context, r tripperware.Request) (tripperwar
 		return s.next.Do(ctx, r)
 	}
 
-	key := s.splitter.GenerateCacheKey(ctx, users.JoinTenantIDs(tenantIDs), r)
+	key := s.splitter.GenerateCacheKey(ctx, cacheKeyTenant(ctx, tenantIDs), r)
 
 	var (
 		extents  []tripperware.Extent
@@ -22,6 +22,7 @@ import (
 	querier_stats "github.com/cortexproject/cortex/pkg/querier/stats"
 	"github.com/cortexproject/cortex/pkg/querier/tripperware"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/requestmeta"
 	"github.com/cortexproject/cortex/pkg/util/users"
 )
 
@@ -1307,6 +1308,43 @@ func TestResultsCache(t *testing.T) {
 	require.Equal(t, 2, calls)
 }
 
+func TestResultsCache_ShouldNotShareResultsAcrossAccessPolicies(t *testing.T) {
+	t.Parallel()
+	calls := 0
+	cfg := ResultsCacheConfig{
+		CacheConfig: cache.Config{
+			Cache: cache.NewMockCache(),
+		},
+	}
+	rcm, _, err := NewResultsCacheMiddleware(
+		log.NewNopLogger(),
+		cfg,
+		splitter(day),
+		mockLimits{},
+		PrometheusCodec,
+		PrometheusResponseExtractor{},
+		nil,
+		nil,
+	)
+	require.NoError(t, err)
+
+	rc := rcm.Wrap(tripperware.HandlerFunc(func(_ context.Context, req tripperware.Request) (tripperware.Response, error) {
+		calls++
+		return parsedResponse, nil
+	}))
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	for i, policy := range []string{"", "team-a", "team-b", "team-a", ""} {
+		policyCtx := ctx
+		if policy != "" {
+			policyCtx = requestmeta.ContextWithAccessPolicy(ctx, policy)
+		}
+		_, err = rc.Do(policyCtx, parsedRequest)
+		require.NoError(t, err)
+		require.Equal(t, min(i+1, 3), calls, "policy %q", policy)
+	}
+}
+
 func TestResultsCacheRecent(t *testing.T) {
 	t.Parallel()
 	var cfg ResultsCacheConfig
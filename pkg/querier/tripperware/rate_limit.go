@@ -0,0 +1,155 @@
+package tripperware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/weaveworks/common/httpgrpc"
+	"golang.org/x/time/rate"
+
+	"github.com/cortexproject/cortex/pkg/util/limiter"
+	"github.com/cortexproject/cortex/pkg/util/users"
+)
+
+const (
+	// API types whose requests rate can be limited.
+	rateLimitedQuery      = "query"
+	rateLimitedQueryRange = "query_range"
+	rateLimitedSeries     = "series"
+	rateLimitedLabels     = "labels"
+	rateLimitedRemoteRead = "remote_read"
+
+	// rateLimitRecheckPeriod is how frequently the per-tenant limits are reloaded.
+	rateLimitRecheckPeriod = 10 * time.Second
+)
+
+// RateLimits allows us to specify per-tenant limits on the requests rate of each API type.
+type RateLimits interface {
+	QueryRate(userID string) float64
+	QueryBurstSize(userID string) int
+	QueryRangeRate(userID string) float64
+	QueryRangeBurstSize(userID string) int
+	SeriesQueryRate(userID string) float64
+	SeriesQueryBurstSize(userID string) int
+	LabelsQueryRate(userID string) float64
+	LabelsQueryBurstSize(userID string) int
+	RemoteReadRate(userID string) float64
+	RemoteReadBurstSize(userID string) int
+}
+
+// ReadLifecycler represents the read interface to the query-frontends lifecycler.
+type ReadLifecycler interface {
+	HealthyInstancesCount() int
+}
+
+// NewRateLimitTripperware returns a Tripperware rejecting with HTTP 429 the requests of
+// a tenant exceeding the rate limit of the requested API type. If the lifecycler is not
+// nil, the limits are evenly shared across the healthy query-frontends (global strategy),
+// otherwise they're applied individually to each query-frontend (local strategy).
+func NewRateLimitTripperware(limits RateLimits, frontends ReadLifecycler) Tripperware {
+	newLimiter := func(limit func(string) float64, burst func(string) int) *limiter.RateLimiter {
+		return limiter.NewRateLimiter(&queryRateStrategy{limit: limit, burst: burst, frontends: frontends}, rateLimitRecheckPeriod)
+	}
+
+	limiters := map[string]*limiter.RateLimiter{
+		rateLimitedQuery:      newLimiter(limits.QueryRate, limits.QueryBurstSize),
+		rateLimitedQueryRange: newLimiter(limits.QueryRangeRate, limits.QueryRangeBurstSize),
+		rateLimitedSeries:     newLimiter(limits.SeriesQueryRate, limits.SeriesQueryBurstSize),
+		rateLimitedLabels:     newLimiter(limits.LabelsQueryRate, limits.LabelsQueryBurstSize),
+		rateLimitedRemoteRead: newLimiter(limits.RemoteReadRate, limits.RemoteReadBurstSize),
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+			l, ok := limiters[rateLimitedAPIType(r)]
+			if !ok {
+				return next.RoundTrip(r)
+			}
+
+			tenantIDs, err := users.TenantIDs(r.Context())
+			if err != nil {
+				return nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+			}
+
+			// The request is only counted in the rate of its tenants if all of them allow it.
+			now := time.Now()
+			if denied := l.AllowAllN(now, tenantIDs, 1); len(denied) > 0 {
+				tenantID := tenantIDs[denied[0]]
+				return nil, rateLimitedError(rateLimitedAPIType(r), tenantID, l.Limit(now, tenantID))
+			}
+
+			return next.RoundTrip(r)
+		})
+	}
+}
+
+func rateLimitedAPIType(r *http.Request) string {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/query"):
+		return rateLimitedQuery
+	case strings.HasSuffix(r.URL.Path, "/query_range"):
+		return rateLimitedQueryRange
+	case strings.HasSuffix(r.URL.Path, "/series"):
+		return rateLimitedSeries
+	case strings.HasSuffix(r.URL.Path, "/labels"), strings.HasSuffix(r.URL.Path, "/values"):
+		return rateLimitedLabels
+	case strings.HasSuffix(r.URL.Path, "/read"):
+		return rateLimitedRemoteRead
+	default:
+		return ""
+	}
+}
+
+// rateLimitedError returns the HTTP 429 error, along with the number of seconds
+// after which a new request is expected to be allowed.
+func rateLimitedError(apiType, tenantID string, limit float64) error {
+	retryAfter := 1
+	if limit > 0 {
+		retryAfter = max(1, int(math.Ceil(1/limit)))
+	}
+
+	return httpgrpc.ErrorFromHTTPResponse(&httpgrpc.HTTPResponse{
+		Code: http.StatusTooManyRequests,
+		Headers: []*httpgrpc.Header{
+			{Key: "Retry-After", Values: []string{strconv.Itoa(retryAfter)}},
+		},
+		Body: []byte(fmt.Sprintf("the request has been rejected because the tenant %s exceeded the %s requests rate limit of %v per second", tenantID, apiType, limit)),
+	})
+}
+
+// queryRateStrategy is a limiter.RateLimiterStrategy returning the rate limit of an API type.
+type queryRateStrategy struct {
+	limit     func(string) float64
+	burst     func(string) int
+	frontends ReadLifecycler
+}
+
+func (s *queryRateStrategy) Limit(tenantID string) float64 {
+	limit := s.limit(tenantID)
+	if limit <= 0 {
+		return float64(rate.Inf)
+	}
+
+	if s.frontends == nil {
+		return limit
+	}
+
+	numFrontends := s.frontends.HealthyInstancesCount()
+	if numFrontends == 0 {
+		return limit
+	}
+	return limit / float64(numFrontends)
+}
+
+func (s *queryRateStrategy) Burst(tenantID string) int {
+	// The meaning of burst doesn't change for the global strategy, in order
+	// to keep it easier to understand for users / operators.
+	if burst := s.burst(tenantID); burst > 0 {
+		return burst
+	}
+	return max(1, int(math.Ceil(s.limit(tenantID))))
+}
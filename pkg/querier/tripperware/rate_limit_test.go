@@ -0,0 +1,147 @@
+package tripperware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/users"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+type frontendsLifecyclerMock struct {
+	healthy int
+}
+
+func (m *frontendsLifecyclerMock) HealthyInstancesCount() int {
+	return m.healthy
+}
+
+func TestRateLimitTripperware(t *testing.T) {
+	t.Parallel()
+
+	limits := validation.Limits{}
+	flagext.DefaultValues(&limits)
+	limits.QueryRangeRate = 1
+	limits.QueryRangeBurstSize = 2
+	limits.SeriesQueryRate = 0.1
+	limits.LabelsQueryRate = 2
+
+	tests := map[string]struct {
+		path      string
+		frontends ReadLifecycler
+
+		// Expected number of allowed requests out of 5 sent in a row.
+		expectedAllowed    int
+		expectedRetryAfter string
+	}{
+		"should not limit the API types without a rate limit": {
+			path:            "/api/v1/query",
+			expectedAllowed: 5,
+		},
+		"should not limit the other API types": {
+			path:            "/api/v1/metadata",
+			expectedAllowed: 5,
+		},
+		"should allow up to the burst size": {
+			path:               "/api/v1/query_range",
+			expectedAllowed:    2,
+			expectedRetryAfter: "1",
+		},
+		"should default the burst size to the rate": {
+			path:               "/api/v1/series",
+			expectedAllowed:    1,
+			expectedRetryAfter: "10",
+		},
+		"should share the label names and values rate limit": {
+			path:               "/api/v1/label/job/values",
+			expectedAllowed:    2,
+			expectedRetryAfter: "1",
+		},
+		"should share the rate limit across the query-frontends with the global strategy": {
+			path:               "/api/v1/query_range",
+			frontends:          &frontendsLifecyclerMock{healthy: 4},
+			expectedAllowed:    2,
+			expectedRetryAfter: "4",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			next := RoundTripFunc(func(*http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			})
+			rt := NewRateLimitTripperware(validation.NewOverrides(limits, nil), testData.frontends)(next)
+
+			allowed := 0
+			var lastErr error
+			for range 5 {
+				req := httptest.NewRequest("GET", testData.path, nil)
+				req = req.WithContext(user.InjectOrgID(req.Context(), "user-1"))
+
+				resp, err := rt.RoundTrip(req)
+				if err != nil {
+					lastErr = err
+					continue
+				}
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+				allowed++
+			}
+
+			assert.Equal(t, testData.expectedAllowed, allowed)
+			if testData.expectedRetryAfter == "" {
+				require.NoError(t, lastErr)
+				return
+			}
+
+			resp, ok := httpgrpc.HTTPResponseFromError(lastErr)
+			require.True(t, ok)
+			assert.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+			require.Len(t, resp.Headers, 1)
+			assert.Equal(t, "Retry-After", resp.Headers[0].Key)
+			assert.Equal(t, []string{testData.expectedRetryAfter}, resp.Headers[0].Values)
+		})
+	}
+}
+
+func TestRateLimitTripperware_ShouldNotConsumeTheRateOfTheOtherTenantsWhenRejected(t *testing.T) {
+	users.WithDefaultResolver(users.NewMultiResolver())
+	t.Cleanup(func() { users.WithDefaultResolver(users.NewSingleResolver()) })
+
+	limits := validation.Limits{}
+	flagext.DefaultValues(&limits)
+	limits.QueryRangeRate = 0.001
+	limits.QueryRangeBurstSize = 2
+
+	next := RoundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+	rt := NewRateLimitTripperware(validation.NewOverrides(limits, nil), nil)(next)
+
+	roundTrip := func(orgID string) error {
+		req := httptest.NewRequest("GET", "/api/v1/query_range", nil)
+		req = req.WithContext(user.InjectOrgID(req.Context(), orgID))
+		_, err := rt.RoundTrip(req)
+		return err
+	}
+
+	// user-1 exhausts its rate limit.
+	require.NoError(t, roundTrip("user-1"))
+	require.NoError(t, roundTrip("user-1"))
+
+	// The federated queries are rejected without being counted in the rate of user-2.
+	for range 3 {
+		require.Error(t, roundTrip("user-2|user-1"))
+	}
+	require.NoError(t, roundTrip("user-2"))
+	require.NoError(t, roundTrip("user-2"))
+	require.Error(t, roundTrip("user-2"))
+}
@@ -0,0 +1,136 @@
+package querier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/storegateway/storegatewaypb"
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/users"
+)
+
+const defaultUnusedFor = model.Duration(7 * 24 * time.Hour)
+
+// MetricsUsageQuerier returns the number of in-memory series of each metric of a tenant,
+// along with the last time they have been queried from the ingesters.
+type MetricsUsageQuerier interface {
+	MetricsUsage(ctx context.Context) (*client.MetricsUsageResponse, error)
+}
+
+// QueriedMetricsQuerier returns the last time each metric of a tenant has been queried
+// from the store-gateways.
+type QueriedMetricsQuerier interface {
+	QueriedMetrics(ctx context.Context, userID string) (*storegatewaypb.QueriedMetricsResponse, error)
+}
+
+type unusedMetricsSuccessResult struct {
+	Status string            `json:"status"`
+	Data   unusedMetricsData `json:"data"`
+}
+
+type unusedMetricsData struct {
+	UnusedFor    model.Duration `json:"unused_for"`
+	TrackedSince time.Time      `json:"tracked_since"`
+
+	// Complete is false if the queried metrics haven't been tracked for the whole
+	// unused_for period, in which case a metric may have been queried before.
+	Complete bool           `json:"complete"`
+	Metrics  []unusedMetric `json:"metrics"`
+}
+
+type unusedMetric struct {
+	MetricName  string     `json:"metric_name"`
+	NumSeries   uint64     `json:"num_series"`
+	LastQueried *time.Time `json:"last_queried,omitempty"`
+}
+
+// UnusedMetricsHandler returns the metrics of a tenant which are ingested but have not been
+// queried for the period in the unused_for parameter, sorted by number of series. The
+// store-gateways querier is optional and, if nil, only the ingesters queries are considered.
+func UnusedMetricsHandler(ingesters MetricsUsageQuerier, storeGateways QueriedMetricsQuerier) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		unusedFor := defaultUnusedFor
+		if s := r.FormValue("unused_for"); s != "" {
+			var err error
+			if unusedFor, err = model.ParseDuration(s); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				util.WriteJSONResponse(w, metadataErrorResult{Status: statusError, Error: fmt.Sprintf("invalid unused_for: %s", err)})
+				return
+			}
+		}
+
+		usage, err := ingesters.MetricsUsage(r.Context())
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			util.WriteJSONResponse(w, metadataErrorResult{Status: statusError, Error: err.Error()})
+			return
+		}
+
+		trackedSince := usage.TrackedSinceTimestampMs
+		lastQueried := make(map[string]int64, len(usage.Metrics))
+		for _, m := range usage.Metrics {
+			lastQueried[m.MetricName] = m.LastQueriedTimestampMs
+		}
+
+		if storeGateways != nil {
+			userID, err := users.TenantID(r.Context())
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				util.WriteJSONResponse(w, metadataErrorResult{Status: statusError, Error: err.Error()})
+				return
+			}
+
+			queried, err := storeGateways.QueriedMetrics(r.Context(), userID)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				util.WriteJSONResponse(w, metadataErrorResult{Status: statusError, Error: err.Error()})
+				return
+			}
+
+			trackedSince = max(trackedSince, queried.TrackedSinceTimestampMs)
+			for _, m := range queried.Metrics {
+				// Metrics not ingested anymore are not reported.
+				if last, ok := lastQueried[m.MetricName]; ok {
+					lastQueried[m.MetricName] = max(last, m.LastQueriedTimestampMs)
+				}
+			}
+		}
+
+		threshold := time.Now().Add(-time.Duration(unusedFor)).UnixMilli()
+		data := unusedMetricsData{
+			UnusedFor:    unusedFor,
+			TrackedSince: time.UnixMilli(trackedSince).UTC(),
+			Complete:     trackedSince <= threshold,
+			Metrics:      []unusedMetric{},
+		}
+
+		for _, m := range usage.Metrics {
+			last := lastQueried[m.MetricName]
+			if m.NumSeries == 0 || last > threshold {
+				continue
+			}
+
+			metric := unusedMetric{MetricName: m.MetricName, NumSeries: m.NumSeries}
+			if last > 0 {
+				t := time.UnixMilli(last).UTC()
+				metric.LastQueried = &t
+			}
+			data.Metrics = append(data.Metrics, metric)
+		}
+
+		sort.Slice(data.Metrics, func(i, j int) bool {
+			if data.Metrics[i].NumSeries != data.Metrics[j].NumSeries {
+				return data.Metrics[i].NumSeries > data.Metrics[j].NumSeries
+			}
+			return data.Metrics[i].MetricName < data.Metrics[j].MetricName
+		})
+
+		util.WriteJSONResponse(w, unusedMetricsSuccessResult{Status: statusSuccess, Data: data})
+	})
+}
@@ -0,0 +1,132 @@
+package querier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/storegateway/storegatewaypb"
+)
+
+type metricsUsageQuerierMock struct {
+	resp *client.MetricsUsageResponse
+	err  error
+}
+
+func (m *metricsUsageQuerierMock) MetricsUsage(context.Context) (*client.MetricsUsageResponse, error) {
+	return m.resp, m.err
+}
+
+type queriedMetricsQuerierMock struct {
+	resp *storegatewaypb.QueriedMetricsResponse
+}
+
+func (m *queriedMetricsQuerierMock) QueriedMetrics(context.Context, string) (*storegatewaypb.QueriedMetricsResponse, error) {
+	return m.resp, nil
+}
+
+func TestUnusedMetricsHandler(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	recent := now.Add(-time.Hour).UnixMilli()
+	old := now.Add(-10 * 24 * time.Hour).UnixMilli()
+
+	ingesters := &metricsUsageQuerierMock{resp: &client.MetricsUsageResponse{
+		TrackedSinceTimestampMs: now.Add(-30 * 24 * time.Hour).UnixMilli(),
+		Metrics: []client.MetricUsage{
+			{MetricName: "queried_recently", NumSeries: 10, LastQueriedTimestampMs: recent},
+			{MetricName: "queried_long_ago", NumSeries: 5, LastQueriedTimestampMs: old},
+			{MetricName: "never_queried", NumSeries: 20},
+			{MetricName: "queried_from_store_gateways", NumSeries: 30, LastQueriedTimestampMs: old},
+		},
+	}}
+	storeGateways := &queriedMetricsQuerierMock{resp: &storegatewaypb.QueriedMetricsResponse{
+		TrackedSinceTimestampMs: now.Add(-2 * 24 * time.Hour).UnixMilli(),
+		Metrics: []*storegatewaypb.QueriedMetric{
+			{MetricName: "queried_from_store_gateways", LastQueriedTimestampMs: recent},
+			{MetricName: "not_ingested", LastQueriedTimestampMs: old},
+		},
+	}}
+
+	tests := map[string]struct {
+		storeGateways    QueriedMetricsQuerier
+		query            string
+		expectedStatus   int
+		expectedMetrics  []string
+		expectedComplete bool
+	}{
+		"should report the metrics not queried in the last 7 days by default": {
+			expectedStatus:   http.StatusOK,
+			expectedMetrics:  []string{"queried_from_store_gateways", "never_queried", "queried_long_ago"},
+			expectedComplete: true,
+		},
+		"should honor the unused_for parameter": {
+			query:            "?unused_for=30m",
+			expectedStatus:   http.StatusOK,
+			expectedMetrics:  []string{"queried_from_store_gateways", "never_queried", "queried_recently", "queried_long_ago"},
+			expectedComplete: true,
+		},
+		"should consider the metrics queried from the store-gateways": {
+			storeGateways:    storeGateways,
+			expectedStatus:   http.StatusOK,
+			expectedMetrics:  []string{"never_queried", "queried_long_ago"},
+			expectedComplete: false,
+		},
+		"should fail on invalid unused_for parameter": {
+			query:          "?unused_for=xxx",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := UnusedMetricsHandler(ingesters, testData.storeGateways)
+			req := httptest.NewRequest("GET", "/api/v1/unused_metrics"+testData.query, nil)
+			req = req.WithContext(user.InjectOrgID(req.Context(), "user-1"))
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			require.Equal(t, testData.expectedStatus, recorder.Code)
+			if testData.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var result unusedMetricsSuccessResult
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+			assert.Equal(t, statusSuccess, result.Status)
+			assert.Equal(t, testData.expectedComplete, result.Data.Complete)
+
+			var names []string
+			for _, m := range result.Data.Metrics {
+				names = append(names, m.MetricName)
+				if m.MetricName == "never_queried" {
+					assert.Nil(t, m.LastQueried)
+				}
+			}
+			assert.Equal(t, testData.expectedMetrics, names)
+		})
+	}
+
+	t.Run("should fail if the ingesters request fails", func(t *testing.T) {
+		t.Parallel()
+
+		handler := UnusedMetricsHandler(&metricsUsageQuerierMock{err: errors.New("tracking disabled")}, nil)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/v1/unused_metrics", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "tracking disabled")
+	})
+}
@@ -0,0 +1,132 @@
+package querier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+	"github.com/cortexproject/cortex/pkg/usage"
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/users"
+)
+
+// BucketIndexReader reads the bucket index of a tenant.
+type BucketIndexReader interface {
+	ReadBucketIndex(ctx context.Context, userID string) (*bucketindex.Index, error)
+}
+
+// UsageReader reads the usage tracked for a tenant by day.
+type UsageReader interface {
+	ReadUsage(ctx context.Context, userID string, start, end time.Time) ([]usage.DayUsage, error)
+}
+
+// maxUsagePeriodDays is the max number of days the tracked usage can be requested for.
+const maxUsagePeriodDays = 366
+
+type usageSuccessResult struct {
+	Status string    `json:"status"`
+	Data   usageData `json:"data"`
+}
+
+type usageData struct {
+	Storage bucketindex.StorageUsage `json:"storage"`
+
+	// StorageUpdatedAt is when the bucket index the storage usage is based on has been updated.
+	StorageUpdatedAt *time.Time `json:"storage_updated_at,omitempty"`
+
+	// Period is the usage tracked during the requested days, if the usage tracking is enabled.
+	Period *usagePeriod `json:"period,omitempty"`
+}
+
+type usagePeriod struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	usage.Usage
+	ByDay []usage.DayUsage `json:"by_day"`
+}
+
+// UsageHandler returns the storage used by the blocks of a tenant, by compaction level and age range,
+// according to its bucket index, and the samples ingested, the bytes queried and the rule evaluations of
+// the tenant by day, between the start and end days included, when the usage reader is not nil. The period
+// defaults to the current month.
+func UsageHandler(reader BucketIndexReader, usageReader UsageReader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := users.TenantID(r.Context())
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			util.WriteJSONResponse(w, metadataErrorResult{Status: statusError, Error: err.Error()})
+			return
+		}
+
+		now := time.Now()
+		start, end, err := parseUsagePeriod(r, now)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			util.WriteJSONResponse(w, metadataErrorResult{Status: statusError, Error: err.Error()})
+			return
+		}
+
+		// A tenant without bucket index has no blocks in the storage yet.
+		idx, err := reader.ReadBucketIndex(r.Context(), userID)
+		if errors.Is(err, bucketindex.ErrIndexNotFound) {
+			idx, err = &bucketindex.Index{}, nil
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			util.WriteJSONResponse(w, metadataErrorResult{Status: statusError, Error: err.Error()})
+			return
+		}
+
+		data := usageData{Storage: idx.StorageUsage(now)}
+		if idx.UpdatedAt > 0 {
+			updatedAt := idx.GetUpdatedAt().UTC()
+			data.StorageUpdatedAt = &updatedAt
+		}
+
+		if usageReader != nil {
+			byDay, err := usageReader.ReadUsage(r.Context(), userID, start, end)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				util.WriteJSONResponse(w, metadataErrorResult{Status: statusError, Error: err.Error()})
+				return
+			}
+
+			data.Period = &usagePeriod{Start: start.Format(usage.DayFormat), End: end.Format(usage.DayFormat), ByDay: byDay}
+			for _, u := range byDay {
+				data.Period.Add(u.Usage)
+			}
+		}
+
+		util.WriteJSONResponse(w, usageSuccessResult{Status: statusSuccess, Data: data})
+	})
+}
+
+// parseUsagePeriod returns the start and end days of the usage period, in UTC.
+func parseUsagePeriod(r *http.Request, now time.Time) (time.Time, time.Time, error) {
+	now = now.UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var err error
+	if v := r.FormValue("start"); v != "" {
+		if start, err = time.Parse(usage.DayFormat, v); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start day %q, the expected format is YYYY-MM-DD", v)
+		}
+	}
+	if v := r.FormValue("end"); v != "" {
+		if end, err = time.Parse(usage.DayFormat, v); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end day %q, the expected format is YYYY-MM-DD", v)
+		}
+	}
+
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, errors.New("the end day must not be before the start day")
+	}
+	if end.Sub(start) >= maxUsagePeriodDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("the usage period must not be longer than %d days", maxUsagePeriodDays)
+	}
+	return start, end, nil
+}
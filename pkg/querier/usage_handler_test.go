@@ -0,0 +1,181 @@
+package querier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+	"github.com/cortexproject/cortex/pkg/usage"
+)
+
+type bucketIndexReaderMock struct {
+	indexes map[string]*bucketindex.Index
+}
+
+func (m *bucketIndexReaderMock) ReadBucketIndex(_ context.Context, userID string) (*bucketindex.Index, error) {
+	if idx, ok := m.indexes[userID]; ok {
+		return idx, nil
+	}
+	return nil, bucketindex.ErrIndexNotFound
+}
+
+type usageReaderMock struct {
+	usage map[string][]usage.DayUsage
+}
+
+func (m *usageReaderMock) ReadUsage(_ context.Context, userID string, start, end time.Time) ([]usage.DayUsage, error) {
+	var out []usage.DayUsage
+	for _, u := range m.usage[userID] {
+		if u.Day >= start.Format(usage.DayFormat) && u.Day <= end.Format(usage.DayFormat) {
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+func TestUsageHandler(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	reader := &bucketIndexReaderMock{indexes: map[string]*bucketindex.Index{
+		"user-1": {
+			UpdatedAt: now.Unix(),
+			Blocks: bucketindex.Blocks{
+				{MaxTime: now.UnixMilli(), CompactionLevel: 1, IndexSize: 10, ChunksSize: 90},
+				{MaxTime: now.Add(-10 * 24 * time.Hour).UnixMilli(), CompactionLevel: 3, IndexSize: 100, ChunksSize: 900},
+			},
+		},
+	}}
+
+	tests := map[string]struct {
+		userID             string
+		expectedStatus     int
+		expectedTotalBytes int64
+		expectedUpdatedAt  bool
+	}{
+		"should return the storage usage of the tenant": {
+			userID:             "user-1",
+			expectedStatus:     http.StatusOK,
+			expectedTotalBytes: 1100,
+			expectedUpdatedAt:  true,
+		},
+		"should return an empty storage usage if the tenant has no bucket index": {
+			userID:         "user-2",
+			expectedStatus: http.StatusOK,
+		},
+		"should fail without tenant": {
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest("GET", "/api/v1/usage", nil)
+			if testData.userID != "" {
+				req = req.WithContext(user.InjectOrgID(req.Context(), testData.userID))
+			}
+			recorder := httptest.NewRecorder()
+			UsageHandler(reader, nil).ServeHTTP(recorder, req)
+
+			require.Equal(t, testData.expectedStatus, recorder.Code)
+			if testData.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var result usageSuccessResult
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+			assert.Equal(t, statusSuccess, result.Status)
+			assert.Equal(t, testData.expectedTotalBytes, result.Data.Storage.TotalBytes)
+			assert.Equal(t, testData.expectedUpdatedAt, result.Data.StorageUpdatedAt != nil)
+			assert.Len(t, result.Data.Storage.ByAge, len(bucketindex.BlockAgeRanges))
+			assert.Nil(t, result.Data.Period)
+		})
+	}
+}
+
+func TestUsageHandler_Period(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	today := now.Format(usage.DayFormat)
+	reader := &bucketIndexReaderMock{}
+	usageReader := &usageReaderMock{usage: map[string][]usage.DayUsage{
+		"user-1": {
+			{Day: "2020-09-30", Usage: usage.Usage{SamplesIngested: 1}},
+			{Day: "2020-10-01", Usage: usage.Usage{SamplesIngested: 10, QueriedBytes: 100}},
+			{Day: "2020-10-02", Usage: usage.Usage{SamplesIngested: 20, RuleEvaluations: 3}},
+			{Day: today, Usage: usage.Usage{QueriedBytes: 5}},
+		},
+	}}
+
+	tests := map[string]struct {
+		query          string
+		expectedStatus int
+		expectedPeriod *usagePeriod
+	}{
+		"should return the usage of the requested days": {
+			query:          "start=2020-10-01&end=2020-10-02",
+			expectedStatus: http.StatusOK,
+			expectedPeriod: &usagePeriod{
+				Start: "2020-10-01",
+				End:   "2020-10-02",
+				Usage: usage.Usage{SamplesIngested: 30, QueriedBytes: 100, RuleEvaluations: 3},
+				ByDay: []usage.DayUsage{
+					{Day: "2020-10-01", Usage: usage.Usage{SamplesIngested: 10, QueriedBytes: 100}},
+					{Day: "2020-10-02", Usage: usage.Usage{SamplesIngested: 20, RuleEvaluations: 3}},
+				},
+			},
+		},
+		"should default to the current month": {
+			expectedStatus: http.StatusOK,
+			expectedPeriod: &usagePeriod{
+				Start: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).Format(usage.DayFormat),
+				End:   today,
+				Usage: usage.Usage{QueriedBytes: 5},
+				ByDay: []usage.DayUsage{{Day: today, Usage: usage.Usage{QueriedBytes: 5}}},
+			},
+		},
+		"should fail on an invalid day": {
+			query:          "start=2020-10-01T00:00:00Z",
+			expectedStatus: http.StatusBadRequest,
+		},
+		"should fail if the end is before the start": {
+			query:          "start=2020-10-02&end=2020-10-01",
+			expectedStatus: http.StatusBadRequest,
+		},
+		"should fail if the period is too long": {
+			query:          "start=2024-01-01&end=2025-01-01",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest("GET", "/api/v1/usage?"+testData.query, nil)
+			req = req.WithContext(user.InjectOrgID(req.Context(), "user-1"))
+			recorder := httptest.NewRecorder()
+			UsageHandler(reader, usageReader).ServeHTTP(recorder, req)
+
+			require.Equal(t, testData.expectedStatus, recorder.Code)
+			if testData.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var result usageSuccessResult
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+			assert.Equal(t, testData.expectedPeriod, result.Data.Period)
+		})
+	}
+}
@@ -158,25 +158,30 @@ func (sp *schedulerProcessor) querierLoop(c schedulerpb.SchedulerForQuerier_Quer
 			if request.StatsEnabled {
 				level.Info(logger).Log("msg", "started running request")
 			}
-			sp.runRequest(ctx, logger, request.QueryID, request.FrontendAddress, request.StatsEnabled, request.HttpRequest)
+			start := time.Now()
+			stats := sp.runRequest(ctx, logger, request.QueryID, request.FrontendAddress, request.StatsEnabled, request.HttpRequest)
 
 			if err = ctx.Err(); err != nil {
 				return
 			}
 
-			// Report back to scheduler that processing of the query has finished.
-			if err := c.Send(&schedulerpb.QuerierToScheduler{}); err != nil {
+			// Report back to scheduler that processing of the query has finished, along with the resources
+			// it consumed, used by the scheduler to share the queriers fairly between tenants.
+			if err := c.Send(&schedulerpb.QuerierToScheduler{
+				QuerierWallTime: time.Since(start),
+				FetchedBytes:    stats.LoadFetchedChunkBytes() + stats.LoadFetchedDataBytes(),
+			}); err != nil {
 				level.Error(logger).Log("msg", "error notifying scheduler about finished query", "err", err, "addr", address)
 			}
 		}()
 	}
 }
 
-func (sp *schedulerProcessor) runRequest(ctx context.Context, logger log.Logger, queryID uint64, frontendAddress string, statsEnabled bool, request *httpgrpc.HTTPRequest) {
-	var stats *querier_stats.QueryStats
-	if statsEnabled {
-		stats, ctx = querier_stats.ContextWithEmptyStats(ctx)
-	}
+// runRequest handles the request and sends the response to the frontend. The statistics of the request are
+// always tracked, because the scheduler accounts them to the tenant, but they're only sent to the frontend
+// if enabled.
+func (sp *schedulerProcessor) runRequest(ctx context.Context, logger log.Logger, queryID uint64, frontendAddress string, statsEnabled bool, request *httpgrpc.HTTPRequest) *querier_stats.QueryStats {
+	stats, ctx := querier_stats.ContextWithEmptyStats(ctx)
 
 	response, err := sp.handler.Handle(ctx, request)
 	if err != nil {
@@ -194,7 +199,7 @@ func (sp *schedulerProcessor) runRequest(ctx context.Context, logger log.Logger,
 	}
 
 	if err = ctx.Err(); err != nil {
-		return
+		return stats
 	}
 
 	// Ensure responses that are too big are not retried.
@@ -213,7 +218,10 @@ func (sp *schedulerProcessor) runRequest(ctx context.Context, logger log.Logger,
 		// To prevent querier panic, the panic could happen when the go-routines not-exited
 		// yet in `fetchSeriesFromStores` are increment query-stats while progressing
 		// (*QueryResultRequest).MarshalToSizedBuffer under the same query-stat objects are used.
-		copiedStats := stats.Copy()
+		var copiedStats *querier_stats.QueryStats
+		if statsEnabled {
+			copiedStats = stats.Copy()
+		}
 		// Response is empty and uninteresting.
 		_, err = c.(frontendv2pb.FrontendForQuerierClient).QueryResult(ctx, &frontendv2pb.QueryResultRequest{
 			QueryID:      queryID,
@@ -224,6 +232,7 @@ func (sp *schedulerProcessor) runRequest(ctx context.Context, logger log.Logger,
 	if err != nil {
 		level.Error(logger).Log("msg", "error notifying frontend about finished query", "err", err, "frontend", frontendAddress)
 	}
+	return stats
 }
 
 func (sp *schedulerProcessor) createFrontendClient(addr string) (client.PoolClient, error) {
@@ -173,3 +173,54 @@ func Test_ToShowNotPanic_RelatedIssue6599(t *testing.T) {
 
 	sp.processQueriesOnSingleStream(ctx, nil, lis.Addr().String())
 }
+
+func TestSchedulerProcessor_ShouldReportUsageToScheduler(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	recvCall := atomic.Uint32{}
+	sent := make(chan *schedulerpb.QuerierToScheduler, 2)
+
+	querierLoopClient := &mockQuerierLoopClient{}
+	querierLoopClient.ctx = ctx
+	querierLoopClient.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+		sent <- args.Get(0).(*schedulerpb.QuerierToScheduler)
+	}).Return(nil)
+	querierLoopClient.On("Context").Return(querierLoopClient.ctx)
+	querierLoopClient.On("Recv").Return(func() (*schedulerpb.SchedulerToQuerier, error) {
+		if recvCall.Add(1) == 1 {
+			return &schedulerpb.SchedulerToQuerier{
+				QueryID:         1,
+				HttpRequest:     &httpgrpc.HTTPRequest{},
+				FrontendAddress: "127.0.0.1:1",
+				UserID:          "user-1",
+			}, nil
+		}
+		<-querierLoopClient.ctx.Done()
+		return nil, context.Canceled
+	})
+
+	// The statistics are tracked even if they're not enabled for the request.
+	requestHandler := &mockRequestHandler{}
+	requestHandler.On("Handle", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stat := stats.FromContext(args.Get(0).(context.Context))
+		stat.AddFetchedChunkBytes(10)
+		stat.AddFetchedDataBytes(5)
+		time.Sleep(10 * time.Millisecond)
+	}).Return(&httpgrpc.HTTPResponse{}, nil)
+
+	sp, _ := newSchedulerProcessor(Config{}, requestHandler, log.NewNopLogger(), nil, "")
+	schedulerClient := &mockSchedulerForQuerierClient{}
+	schedulerClient.On("QuerierLoop", mock.Anything, mock.Anything).Return(querierLoopClient, nil)
+	sp.schedulerClientFactory = func(conn *grpc.ClientConn) schedulerpb.SchedulerForQuerierClient {
+		return schedulerClient
+	}
+
+	go sp.processQueriesOnSingleStream(ctx, nil, "scheduler")
+
+	// The first message registers the querier.
+	<-sent
+	msg := <-sent
+	require.Equal(t, uint64(15), msg.FetchedBytes)
+	require.GreaterOrEqual(t, msg.QuerierWallTime, 10*time.Millisecond)
+}
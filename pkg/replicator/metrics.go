@@ -0,0 +1,59 @@
+package replicator
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type metrics struct {
+	runsStarted          prometheus.Counter
+	runsCompleted        prometheus.Counter
+	runsFailed           prometheus.Counter
+	lastRunSuccess       prometheus.Gauge
+	tenantFailures       *prometheus.CounterVec
+	objectsReplicated    *prometheus.CounterVec
+	objectsDeleted       *prometheus.CounterVec
+	bytesReplicated      *prometheus.CounterVec
+	tenantReplicationLag *prometheus.GaugeVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	return &metrics{
+		runsStarted: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_replicator_runs_started_total",
+			Help: "Total number of replication runs started.",
+		}),
+		runsCompleted: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_replicator_runs_completed_total",
+			Help: "Total number of replication runs successfully completed.",
+		}),
+		runsFailed: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_replicator_runs_failed_total",
+			Help: "Total number of replication runs failed.",
+		}),
+		lastRunSuccess: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "cortex_replicator_last_successful_run_timestamp_seconds",
+			Help: "Unix timestamp of the last successful replication run.",
+		}),
+		tenantFailures: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_replicator_tenant_failures_total",
+			Help: "Total number of failed replications of a tenant.",
+		}, []string{"source"}),
+		objectsReplicated: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_replicator_objects_replicated_total",
+			Help: "Total number of objects copied to the secondary storage.",
+		}, []string{"source"}),
+		objectsDeleted: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_replicator_objects_deleted_total",
+			Help: "Total number of objects deleted from the secondary storage.",
+		}, []string{"source"}),
+		bytesReplicated: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_replicator_replicated_bytes_total",
+			Help: "Total number of bytes copied to the secondary storage.",
+		}, []string{"source"}),
+		tenantReplicationLag: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_replicator_tenant_replication_lag_seconds",
+			Help: "Time elapsed since the start of the last successful replication of the tenant. The secondary storage contains at least the objects of the tenant in the primary storage at that time.",
+		}, []string{"source", "user"}),
+	}
+}
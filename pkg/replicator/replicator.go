@@ -0,0 +1,347 @@
+package replicator
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/thanos-io/objstore"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	"github.com/cortexproject/cortex/pkg/util/concurrency"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/services"
+)
+
+const (
+	// SourceBlocks is the source replicating the blocks storage.
+	SourceBlocks = "blocks"
+
+	// SourceRules is the source replicating the ruler storage.
+	SourceRules = "rules"
+
+	// SourceAlertmanager is the source replicating the alertmanager storage.
+	SourceAlertmanager = "alertmanager"
+)
+
+var (
+	supportedSources = []string{SourceBlocks, SourceRules, SourceAlertmanager}
+
+	errInvalidReplicationInterval = errors.New("the replication interval must be greater than 0")
+	errInvalidTenantConcurrency   = errors.New("the tenant concurrency must be greater than 0")
+	errInvalidTenantDeletionDelay = errors.New("the tenant deletion delay must be greater than or equal to 0")
+	errUnsupportedSource          = errors.New("unsupported replication source")
+)
+
+// Config holds the replicator config.
+type Config struct {
+	ReplicationInterval    time.Duration          `yaml:"replication_interval"`
+	TenantConcurrency      int                    `yaml:"tenant_concurrency"`
+	TenantDeletionDelay    time.Duration          `yaml:"tenant_deletion_delay"`
+	Sources                flagext.StringSliceCSV `yaml:"sources"`
+	QuerierFailoverEnabled bool                   `yaml:"querier_failover_enabled"`
+
+	BlocksStorage       bucket.Config `yaml:"blocks_storage" doc:"description=The secondary storage of the blocks."`
+	RulerStorage        bucket.Config `yaml:"ruler_storage" doc:"description=The secondary storage of the rules."`
+	AlertmanagerStorage bucket.Config `yaml:"alertmanager_storage" doc:"description=The secondary storage of the alertmanager configs and state."`
+}
+
+// RegisterFlags registers the replicator flags.
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	cfg.Sources = []string{SourceBlocks}
+
+	f.DurationVar(&cfg.ReplicationInterval, "replicator.replication-interval", 5*time.Minute, "How frequently the replicator copies the changes of the primary storage to the secondary storage.")
+	f.IntVar(&cfg.TenantConcurrency, "replicator.tenant-concurrency", 4, "Number of tenants replicated concurrently.")
+	f.DurationVar(&cfg.TenantDeletionDelay, "replicator.tenant-deletion-delay", 6*time.Hour, "For how long a tenant must be missing from the primary storage before its objects are deleted from the secondary storage. The tenants are never deleted from the secondary storage while the primary storage has no tenants at all.")
+	f.Var(&cfg.Sources, "replicator.sources", fmt.Sprintf("Comma separated list of the storages to replicate. Supported values are: %s.", strings.Join(supportedSources, ", ")))
+	f.BoolVar(&cfg.QuerierFailoverEnabled, "replicator.querier-failover-enabled", false, "If true, the querier and store-gateway read the blocks from the secondary blocks storage instead of the primary one, to keep serving queries when the primary storage is unavailable. It can only be enabled when running the querier and store-gateway targets, which don't write to the storage.")
+
+	cfg.BlocksStorage.RegisterFlagsWithPrefix("replicator.blocks-storage.", f)
+	cfg.RulerStorage.RegisterFlagsWithPrefix("replicator.ruler-storage.", f)
+	cfg.AlertmanagerStorage.RegisterFlagsWithPrefix("replicator.alertmanager-storage.", f)
+}
+
+// Validate the config.
+func (cfg *Config) Validate() error {
+	if cfg.ReplicationInterval <= 0 {
+		return errInvalidReplicationInterval
+	}
+	if cfg.TenantConcurrency <= 0 {
+		return errInvalidTenantConcurrency
+	}
+	if cfg.TenantDeletionDelay < 0 {
+		return errInvalidTenantDeletionDelay
+	}
+
+	for _, s := range cfg.Sources {
+		if !slices.Contains(supportedSources, s) {
+			return errors.Wrap(errUnsupportedSource, s)
+		}
+	}
+
+	if cfg.enabled(SourceBlocks) || cfg.QuerierFailoverEnabled {
+		if err := cfg.BlocksStorage.Validate(); err != nil {
+			return errors.Wrap(err, "invalid secondary blocks storage config")
+		}
+	}
+	if cfg.enabled(SourceRules) {
+		if err := cfg.RulerStorage.Validate(); err != nil {
+			return errors.Wrap(err, "invalid secondary ruler storage config")
+		}
+	}
+	if cfg.enabled(SourceAlertmanager) {
+		if err := cfg.AlertmanagerStorage.Validate(); err != nil {
+			return errors.Wrap(err, "invalid secondary alertmanager storage config")
+		}
+	}
+
+	return nil
+}
+
+func (cfg *Config) enabled(source string) bool {
+	return slices.Contains(cfg.Sources, source)
+}
+
+// Replicator asynchronously mirrors the objects of the tenants from the primary storages to
+// secondary storages, which may use another backend, for disaster recovery.
+type Replicator struct {
+	services.Service
+
+	cfg     Config
+	logger  log.Logger
+	sources []*source
+	metrics *metrics
+
+	// lastReplication is the start time of the last successful replication of each tenant, by source.
+	lastReplicationMtx sync.Mutex
+	lastReplication    map[string]map[string]time.Time
+
+	// missingSince is the time since when each tenant of the secondary storage has been
+	// missing from the primary storage, by source. It's only accessed by the replication loop.
+	missingSince map[string]map[string]time.Time
+}
+
+// NewReplicator makes a new Replicator replicating the enabled sources from the input primary storages.
+func NewReplicator(cfg Config, blocksStorage, rulerStorage, alertmanagerStorage bucket.Config, logger log.Logger, reg prometheus.Registerer) (*Replicator, error) {
+	sources := map[string]struct {
+		layout             layout
+		primary, secondary bucket.Config
+	}{
+		SourceBlocks:       {layout: blocksLayout, primary: blocksStorage, secondary: cfg.BlocksStorage},
+		SourceRules:        {layout: rulesLayout, primary: rulerStorage, secondary: cfg.RulerStorage},
+		SourceAlertmanager: {layout: alertmanagerLayout, primary: alertmanagerStorage, secondary: cfg.AlertmanagerStorage},
+	}
+
+	var primaries, secondaries []objstore.Bucket
+	for _, name := range cfg.Sources {
+		s := sources[name]
+		if !slices.Contains(bucket.SupportedBackends, s.primary.Backend) {
+			return nil, fmt.Errorf("the %s storage can't be replicated from the %s backend", name, s.primary.Backend)
+		}
+
+		// The objects are copied as they are stored, for example encrypted.
+		s.primary.Middlewares = nil
+
+		primary, err := bucket.NewClient(context.Background(), s.primary, nil, "replicator-"+name, logger, reg)
+		if err != nil {
+			return nil, err
+		}
+		secondary, err := bucket.NewClient(context.Background(), s.secondary, nil, "replicator-"+name+"-secondary", logger, reg)
+		if err != nil {
+			return nil, err
+		}
+		primaries = append(primaries, primary)
+		secondaries = append(secondaries, secondary)
+	}
+
+	return newReplicator(cfg, primaries, secondaries, logger, reg), nil
+}
+
+func newReplicator(cfg Config, primaries, secondaries []objstore.Bucket, logger log.Logger, reg prometheus.Registerer) *Replicator {
+	layouts := map[string]layout{SourceBlocks: blocksLayout, SourceRules: rulesLayout, SourceAlertmanager: alertmanagerLayout}
+
+	r := &Replicator{
+		cfg:             cfg,
+		logger:          logger,
+		metrics:         newMetrics(reg),
+		lastReplication: map[string]map[string]time.Time{},
+		missingSince:    map[string]map[string]time.Time{},
+	}
+	for i, name := range cfg.Sources {
+		r.sources = append(r.sources, &source{
+			name:      name,
+			layout:    layouts[name],
+			primary:   primaries[i],
+			secondary: secondaries[i],
+			metrics:   r.metrics,
+		})
+		r.lastReplication[name] = map[string]time.Time{}
+		r.missingSince[name] = map[string]time.Time{}
+	}
+
+	r.Service = services.NewBasicService(nil, r.running, nil)
+	return r
+}
+
+func (r *Replicator) running(ctx context.Context) error {
+	// Replicate once at startup, so that the secondary storage is updated after a restart.
+	r.replicate(ctx)
+
+	ticker := time.NewTicker(r.cfg.ReplicationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.replicate(ctx)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// replicate runs a replication of all the sources.
+func (r *Replicator) replicate(ctx context.Context) {
+	r.metrics.runsStarted.Inc()
+	level.Info(r.logger).Log("msg", "started replication")
+
+	failed := false
+	for _, s := range r.sources {
+		if err := r.replicateSource(ctx, s); err != nil {
+			level.Error(r.logger).Log("msg", "failed to replicate storage", "source", s.name, "err", err)
+			failed = true
+		}
+	}
+
+	if failed {
+		r.metrics.runsFailed.Inc()
+		return
+	}
+
+	r.metrics.runsCompleted.Inc()
+	r.metrics.lastRunSuccess.SetToCurrentTime()
+	level.Info(r.logger).Log("msg", "successfully completed replication")
+}
+
+func (r *Replicator) replicateSource(ctx context.Context, s *source) error {
+	start := time.Now()
+
+	primaryTenants, err := s.listTenants(ctx, s.primary)
+	if err != nil {
+		return err
+	}
+	secondaryTenants, err := s.listTenants(ctx, s.secondary)
+	if err != nil {
+		return err
+	}
+
+	tenants := append(sortedKeys(primaryTenants), r.deletedTenants(s.name, primaryTenants, secondaryTenants, start)...)
+
+	var failedTenants int
+	var failedTenantsMtx sync.Mutex
+
+	err = concurrency.ForEachUser(ctx, tenants, r.cfg.TenantConcurrency, func(ctx context.Context, userID string) error {
+		if err := s.replicateTenant(ctx, userID); err != nil {
+			level.Warn(r.logger).Log("msg", "failed to replicate tenant", "source", s.name, "user", userID, "err", err)
+			s.metrics.tenantFailures.WithLabelValues(s.name).Inc()
+
+			failedTenantsMtx.Lock()
+			failedTenants++
+			failedTenantsMtx.Unlock()
+			return nil
+		}
+
+		r.tenantReplicated(s.name, userID, start, primaryTenants)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.replicateGlobalObjects(ctx); err != nil {
+		return err
+	}
+
+	r.updateReplicationLag(s.name)
+
+	if failedTenants > 0 {
+		return fmt.Errorf("failed to replicate %d tenants", failedTenants)
+	}
+	return nil
+}
+
+// deletedTenants returns the tenants only in the secondary storage whose objects must be removed,
+// because they've been missing from the primary storage for at least the tenant deletion delay.
+// The secondary storage being the copy used for disaster recovery, no tenant is deleted when
+// the primary storage has no tenants at all, which is more likely caused by a misconfiguration
+// or an incident of the primary storage than by the deletion of all the tenants.
+func (r *Replicator) deletedTenants(source string, primaryTenants, secondaryTenants map[string]struct{}, now time.Time) []string {
+	missingSince := r.missingSince[source]
+	for userID := range missingSince {
+		if _, ok := secondaryTenants[userID]; !ok {
+			delete(missingSince, userID)
+		}
+	}
+
+	var deleted []string
+	for userID := range secondaryTenants {
+		if _, ok := primaryTenants[userID]; ok {
+			delete(missingSince, userID)
+			continue
+		}
+
+		// The tenant isn't replicated anymore.
+		r.forgetTenant(source, userID)
+
+		if len(primaryTenants) == 0 {
+			continue
+		}
+		if _, ok := missingSince[userID]; !ok {
+			missingSince[userID] = now
+		}
+		if now.Sub(missingSince[userID]) >= r.cfg.TenantDeletionDelay {
+			deleted = append(deleted, userID)
+		}
+	}
+
+	if len(primaryTenants) == 0 && len(secondaryTenants) > 0 {
+		level.Warn(r.logger).Log("msg", "the primary storage has no tenants, skipping the deletion of the tenants from the secondary storage", "source", source)
+	}
+
+	slices.Sort(deleted)
+	return deleted
+}
+
+func (r *Replicator) tenantReplicated(source, userID string, start time.Time, primaryTenants map[string]struct{}) {
+	r.lastReplicationMtx.Lock()
+	defer r.lastReplicationMtx.Unlock()
+
+	if _, ok := primaryTenants[userID]; ok {
+		r.lastReplication[source][userID] = start
+	}
+}
+
+func (r *Replicator) forgetTenant(source, userID string) {
+	r.lastReplicationMtx.Lock()
+	defer r.lastReplicationMtx.Unlock()
+
+	delete(r.lastReplication[source], userID)
+	r.metrics.tenantReplicationLag.DeleteLabelValues(source, userID)
+}
+
+func (r *Replicator) updateReplicationLag(source string) {
+	r.lastReplicationMtx.Lock()
+	defer r.lastReplicationMtx.Unlock()
+
+	for userID, last := range r.lastReplication[source] {
+		r.metrics.tenantReplicationLag.WithLabelValues(source, userID).Set(time.Since(last).Seconds())
+	}
+}
@@ -0,0 +1,403 @@
+package replicator
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/oklog/ulid/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	prom_testutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	"github.com/cortexproject/cortex/pkg/storage/bucket/filesystem"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+)
+
+// recordingBucket records the order of the objects uploaded to and deleted from the bucket.
+type recordingBucket struct {
+	objstore.Bucket
+
+	mtx    sync.Mutex
+	events []string
+}
+
+func (b *recordingBucket) Upload(ctx context.Context, name string, r io.Reader, opts ...objstore.ObjectUploadOption) error {
+	b.mtx.Lock()
+	b.events = append(b.events, "upload "+name)
+	b.mtx.Unlock()
+	return b.Bucket.Upload(ctx, name, r, opts...)
+}
+
+func (b *recordingBucket) Delete(ctx context.Context, name string) error {
+	b.mtx.Lock()
+	b.events = append(b.events, "delete "+name)
+	b.mtx.Unlock()
+	return b.Bucket.Delete(ctx, name)
+}
+
+func (b *recordingBucket) reset() []string {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	events := b.events
+	b.events = nil
+	return events
+}
+
+func newTestBuckets(t *testing.T) (objstore.Bucket, *recordingBucket) {
+	primary, err := filesystem.NewBucketClient(filesystem.Config{Directory: t.TempDir()})
+	require.NoError(t, err)
+	secondary, err := filesystem.NewBucketClient(filesystem.Config{Directory: t.TempDir()})
+	require.NoError(t, err)
+	return primary, &recordingBucket{Bucket: secondary}
+}
+
+func newTestReplicator(source string, primary, secondary objstore.Bucket, reg prometheus.Registerer) *Replicator {
+	cfg := Config{
+		ReplicationInterval: time.Minute,
+		TenantConcurrency:   2,
+		Sources:             []string{source},
+	}
+	return newReplicator(cfg, []objstore.Bucket{primary}, []objstore.Bucket{secondary}, log.NewNopLogger(), reg)
+}
+
+func upload(t *testing.T, bkt objstore.Bucket, name, content string) {
+	require.NoError(t, bkt.Upload(context.Background(), name, strings.NewReader(content)))
+}
+
+func read(t *testing.T, bkt objstore.Bucket, name string) string {
+	r, err := bkt.Get(context.Background(), name)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, r.Close()) }()
+
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func listAll(t *testing.T, bkt objstore.Bucket) []string {
+	var objects []string
+	require.NoError(t, bkt.Iter(context.Background(), "", func(name string) error {
+		objects = append(objects, name)
+		return nil
+	}, objstore.WithRecursiveIter()))
+	return objects
+}
+
+func TestReplicator_Blocks(t *testing.T) {
+	ctx := context.Background()
+	primary, secondary := newTestBuckets(t)
+	r := newTestReplicator(SourceBlocks, primary, secondary, prometheus.NewPedanticRegistry())
+
+	block1, block2, block3 := ulid.MustNew(1, nil), ulid.MustNew(2, nil), ulid.MustNew(3, nil)
+	block1Dir, block2Dir := path.Join("user-1", block1.String()), path.Join("user-1", block2.String())
+
+	upload(t, primary, path.Join(block1Dir, "chunks", "000001"), "chunks")
+	upload(t, primary, path.Join(block1Dir, "index"), "index")
+	upload(t, primary, path.Join(block1Dir, metadata.MetaFilename), "meta")
+	// The upload of block2 is in progress.
+	upload(t, primary, path.Join(block2Dir, "index"), "index")
+	upload(t, primary, path.Join("user-1", "bucket-index.json.gz"), "bucket-index-1")
+	upload(t, primary, path.Join("user-2", block3.String(), metadata.MetaFilename), "meta")
+	upload(t, primary, path.Join("__markers__", "user-2", "tenant-deletion-mark.json"), "mark")
+	upload(t, primary, "user-index.json.gz", "user-index")
+
+	r.replicate(ctx)
+
+	assert.Equal(t, []string{
+		"upload " + path.Join(block1Dir, "chunks", "000001"),
+		"upload " + path.Join(block1Dir, "index"),
+		"upload " + path.Join(block1Dir, metadata.MetaFilename),
+		"upload " + path.Join("user-1", "bucket-index.json.gz"),
+	}, filterEvents(secondary.reset(), "user-1/"))
+
+	assert.ElementsMatch(t, []string{
+		"__markers__/user-2/tenant-deletion-mark.json",
+		path.Join(block1Dir, "chunks", "000001"),
+		path.Join(block1Dir, "index"),
+		path.Join(block1Dir, metadata.MetaFilename),
+		"user-1/bucket-index.json.gz",
+		path.Join("user-2", block3.String(), metadata.MetaFilename),
+		"user-index.json.gz",
+	}, listAll(t, secondary))
+
+	// Nothing is copied again when the primary storage hasn't changed.
+	r.replicate(ctx)
+	assert.Empty(t, secondary.reset())
+
+	// The updated objects are copied again.
+	upload(t, primary, path.Join("user-1", "bucket-index.json.gz"), "bucket-index-2")
+	r.replicate(ctx)
+	assert.Equal(t, []string{"upload user-1/bucket-index.json.gz"}, secondary.reset())
+	assert.Equal(t, "bucket-index-2", read(t, secondary, "user-1/bucket-index.json.gz"))
+
+	// The blocks marked for deletion are still replicated, and then deleted in the same order as in the primary storage.
+	upload(t, primary, path.Join(block1Dir, metadata.DeletionMarkFilename), "deletion-mark")
+	r.replicate(ctx)
+	assert.Equal(t, []string{"upload " + path.Join(block1Dir, metadata.DeletionMarkFilename)}, secondary.reset())
+
+	require.NoError(t, primary.Delete(ctx, path.Join(block1Dir, metadata.MetaFilename)))
+	r.replicate(ctx)
+	assert.Equal(t, []string{
+		"delete " + path.Join(block1Dir, metadata.MetaFilename),
+		"delete " + path.Join(block1Dir, "chunks", "000001"),
+		"delete " + path.Join(block1Dir, "index"),
+		"delete " + path.Join(block1Dir, metadata.DeletionMarkFilename),
+	}, secondary.reset())
+
+	// The objects of the deleted tenants are deleted.
+	require.NoError(t, primary.Delete(ctx, path.Join("user-2", block3.String(), metadata.MetaFilename)))
+	require.NoError(t, primary.Delete(ctx, path.Join("__markers__", "user-2", "tenant-deletion-mark.json")))
+	r.replicate(ctx)
+	assert.ElementsMatch(t, []string{
+		"user-1/bucket-index.json.gz",
+		"user-index.json.gz",
+	}, listAll(t, secondary))
+
+	assert.Equal(t, float64(6), prom_testutil.ToFloat64(r.metrics.runsCompleted))
+	assert.Equal(t, float64(0), prom_testutil.ToFloat64(r.metrics.runsFailed))
+}
+
+func TestReplicator_RulesAndAlertmanager(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("rules", func(t *testing.T) {
+		primary, secondary := newTestBuckets(t)
+		r := newTestReplicator(SourceRules, primary, secondary, prometheus.NewPedanticRegistry())
+
+		upload(t, primary, "rules/user-1/namespace-1/group-1", "group-1")
+		upload(t, primary, "rules/user-2/namespace-1/group-1", "group-1")
+		upload(t, primary, "rules/user-index.json.gz", "user-index")
+
+		r.replicate(ctx)
+		assert.ElementsMatch(t, listAll(t, primary), listAll(t, secondary))
+
+		require.NoError(t, primary.Delete(ctx, "rules/user-2/namespace-1/group-1"))
+		upload(t, primary, "rules/user-1/namespace-1/group-1", "updated group-1")
+		r.replicate(ctx)
+		assert.ElementsMatch(t, listAll(t, primary), listAll(t, secondary))
+		assert.Equal(t, "updated group-1", read(t, secondary, "rules/user-1/namespace-1/group-1"))
+	})
+
+	t.Run("alertmanager", func(t *testing.T) {
+		primary, secondary := newTestBuckets(t)
+		r := newTestReplicator(SourceAlertmanager, primary, secondary, prometheus.NewPedanticRegistry())
+
+		upload(t, primary, "alerts/user-1", "config-1")
+		upload(t, primary, "alerts/user-2", "config-2")
+		upload(t, primary, "alertmanager/user-1/fullstate", "state-1")
+		upload(t, primary, "alerts/user-index.json.gz", "user-index")
+
+		r.replicate(ctx)
+		assert.ElementsMatch(t, listAll(t, primary), listAll(t, secondary))
+
+		require.NoError(t, primary.Delete(ctx, "alerts/user-2"))
+		require.NoError(t, primary.Delete(ctx, "alertmanager/user-1/fullstate"))
+		r.replicate(ctx)
+		assert.ElementsMatch(t, []string{"alerts/user-1", "alerts/user-index.json.gz"}, listAll(t, secondary))
+	})
+}
+
+func TestReplicator_ReplicationLag(t *testing.T) {
+	ctx := context.Background()
+	primary, secondary := newTestBuckets(t)
+	reg := prometheus.NewPedanticRegistry()
+	r := newTestReplicator(SourceRules, primary, secondary, reg)
+
+	upload(t, primary, "rules/user-1/namespace-1/group-1", "group-1")
+	upload(t, primary, "rules/user-2/namespace-1/group-1", "group-1")
+
+	r.replicate(ctx)
+	assert.Equal(t, 2, prom_testutil.CollectAndCount(reg, "cortex_replicator_tenant_replication_lag_seconds"))
+
+	lag := prom_testutil.ToFloat64(r.metrics.tenantReplicationLag.WithLabelValues(SourceRules, "user-1"))
+	assert.GreaterOrEqual(t, lag, float64(0))
+	assert.Less(t, lag, time.Minute.Seconds())
+
+	// The lag of the tenants deleted from both the storages is removed.
+	require.NoError(t, primary.Delete(ctx, "rules/user-2/namespace-1/group-1"))
+	r.replicate(ctx)
+	assert.Equal(t, 1, prom_testutil.CollectAndCount(reg, "cortex_replicator_tenant_replication_lag_seconds"))
+}
+
+func TestReplicator_BlocksCopiedAsStored(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	primaryCfg := bucket.Config{Backend: bucket.Filesystem}
+	primaryCfg.Filesystem.Directory = dir
+	// The middlewares of the primary storage, like the encryption, are not applied to the replicated objects.
+	primaryCfg.Middlewares = []func(objstore.InstrumentedBucket) (objstore.InstrumentedBucket, error){
+		func(bkt objstore.InstrumentedBucket) (objstore.InstrumentedBucket, error) {
+			return nil, assert.AnError
+		},
+	}
+
+	cfg := Config{ReplicationInterval: time.Minute, TenantConcurrency: 1, Sources: []string{SourceBlocks}}
+	cfg.BlocksStorage.Backend = bucket.Filesystem
+	cfg.BlocksStorage.Filesystem.Directory = t.TempDir()
+
+	r, err := NewReplicator(cfg, primaryCfg, bucket.Config{}, bucket.Config{}, log.NewNopLogger(), prometheus.NewPedanticRegistry())
+	require.NoError(t, err)
+
+	primary, err := filesystem.NewBucketClient(filesystem.Config{Directory: dir})
+	require.NoError(t, err)
+	require.NoError(t, primary.Upload(ctx, "user-1/bucket-index.json.gz", bytes.NewReader([]byte("bucket-index"))))
+
+	r.replicate(ctx)
+	assert.Equal(t, float64(1), prom_testutil.ToFloat64(r.metrics.runsCompleted))
+}
+
+func TestReplicator_TenantDeletionDelay(t *testing.T) {
+	ctx := context.Background()
+	primary, secondary := newTestBuckets(t)
+	r := newTestReplicator(SourceRules, primary, secondary, prometheus.NewPedanticRegistry())
+	r.cfg.TenantDeletionDelay = time.Hour
+
+	upload(t, primary, "rules/user-1/namespace-1/group-1", "group-1")
+	upload(t, primary, "rules/user-2/namespace-1/group-1", "group-1")
+	r.replicate(ctx)
+
+	// The tenants missing from the primary storage are kept until the deletion delay has passed.
+	require.NoError(t, primary.Delete(ctx, "rules/user-2/namespace-1/group-1"))
+	r.replicate(ctx)
+	assert.ElementsMatch(t, []string{"rules/user-1/namespace-1/group-1", "rules/user-2/namespace-1/group-1"}, listAll(t, secondary))
+
+	// A tenant back in the primary storage before the deletion delay is kept.
+	upload(t, primary, "rules/user-2/namespace-1/group-1", "group-1")
+	r.replicate(ctx)
+	assert.Empty(t, r.missingSince[SourceRules])
+
+	require.NoError(t, primary.Delete(ctx, "rules/user-2/namespace-1/group-1"))
+	r.replicate(ctx)
+	r.missingSince[SourceRules]["user-2"] = time.Now().Add(-2 * time.Hour)
+	r.replicate(ctx)
+	assert.ElementsMatch(t, []string{"rules/user-1/namespace-1/group-1"}, listAll(t, secondary))
+
+	// The deleted tenants are forgotten.
+	r.replicate(ctx)
+	assert.Empty(t, r.missingSince[SourceRules])
+}
+
+func TestReplicator_TenantsNotDeletedWhenThePrimaryStorageIsEmpty(t *testing.T) {
+	ctx := context.Background()
+	primary, secondary := newTestBuckets(t)
+	r := newTestReplicator(SourceRules, primary, secondary, prometheus.NewPedanticRegistry())
+
+	upload(t, primary, "rules/user-1/namespace-1/group-1", "group-1")
+	upload(t, primary, "rules/user-2/namespace-1/group-1", "group-1")
+	r.replicate(ctx)
+	secondary.reset()
+
+	require.NoError(t, primary.Delete(ctx, "rules/user-1/namespace-1/group-1"))
+	require.NoError(t, primary.Delete(ctx, "rules/user-2/namespace-1/group-1"))
+	r.replicate(ctx)
+	assert.Empty(t, secondary.reset())
+	assert.ElementsMatch(t, []string{"rules/user-1/namespace-1/group-1", "rules/user-2/namespace-1/group-1"}, listAll(t, secondary))
+}
+
+func TestReplicator_UpdatedObjectsComparedByContent(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	primary, err := filesystem.NewBucketClient(filesystem.Config{Directory: dir})
+	require.NoError(t, err)
+	_, secondary := newTestBuckets(t)
+	r := newTestReplicator(SourceRules, primary, secondary, prometheus.NewPedanticRegistry())
+
+	const name = "rules/user-1/namespace-1/group-1"
+	upload(t, primary, name, "group-1")
+	r.replicate(ctx)
+	assert.Equal(t, []string{"upload " + name}, secondary.reset())
+
+	// The object is updated with the same size, and its last modified time in the primary storage is older
+	// than the one of the replica, for example because of a clock skew between the storages.
+	upload(t, primary, name, "group-2")
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, name), past, past))
+	r.replicate(ctx)
+	assert.Equal(t, []string{"upload " + name}, secondary.reset())
+	assert.Equal(t, "group-2", read(t, secondary, name))
+
+	// Nothing is copied again when the object hasn't changed since.
+	r.replicate(ctx)
+	assert.Empty(t, secondary.reset())
+
+	// After a restart, the objects are compared by content with their replicas.
+	r = newTestReplicator(SourceRules, primary, secondary, prometheus.NewPedanticRegistry())
+	r.replicate(ctx)
+	assert.Empty(t, secondary.reset())
+
+	r = newTestReplicator(SourceRules, primary, secondary, prometheus.NewPedanticRegistry())
+	upload(t, primary, name, "group-3")
+	require.NoError(t, os.Chtimes(filepath.Join(dir, name), past, past))
+	r.replicate(ctx)
+	assert.Equal(t, []string{"upload " + name}, secondary.reset())
+	assert.Equal(t, "group-3", read(t, secondary, name))
+}
+
+func TestConfig_Validate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		setup    func(cfg *Config)
+		expected error
+	}{
+		"should pass with the default config": {
+			setup: func(*Config) {},
+		},
+		"should fail with an unsupported source": {
+			setup:    func(cfg *Config) { cfg.Sources = []string{SourceBlocks, "unknown"} },
+			expected: errUnsupportedSource,
+		},
+		"should fail with an invalid replication interval": {
+			setup:    func(cfg *Config) { cfg.ReplicationInterval = 0 },
+			expected: errInvalidReplicationInterval,
+		},
+		"should fail with an invalid tenant concurrency": {
+			setup:    func(cfg *Config) { cfg.TenantConcurrency = 0 },
+			expected: errInvalidTenantConcurrency,
+		},
+		"should fail with an invalid tenant deletion delay": {
+			setup:    func(cfg *Config) { cfg.TenantDeletionDelay = -time.Minute },
+			expected: errInvalidTenantDeletionDelay,
+		},
+		"should fail with an invalid secondary storage": {
+			setup:    func(cfg *Config) { cfg.BlocksStorage.Backend = "unknown" },
+			expected: bucket.ErrUnsupportedStorageBackend,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{}
+			flagext.DefaultValues(&cfg)
+			tc.setup(&cfg)
+
+			err := cfg.Validate()
+			if tc.expected == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.expected)
+			}
+		})
+	}
+}
+
+func filterEvents(events []string, prefix string) []string {
+	var filtered []string
+	for _, e := range events {
+		if strings.HasPrefix(strings.SplitN(e, " ", 2)[1], prefix) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
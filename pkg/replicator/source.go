@@ -0,0 +1,482 @@
+package replicator
+
+import (
+	"context"
+	"crypto/sha256"
+	"hash"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/pkg/errors"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+	"github.com/cortexproject/cortex/pkg/util/users"
+)
+
+// layout describes where the objects of each tenant are stored in the bucket of a source.
+type layout struct {
+	// tenantDirs are the directories containing a directory, or an object, per tenant.
+	tenantDirs []string
+
+	// tenantPrefixes returns the prefixes of the objects of the tenant. A prefix ending
+	// with the directory delimiter is a directory, otherwise it's a single object.
+	tenantPrefixes func(userID string) []string
+
+	// globalObjects are the objects which don't belong to any tenant.
+	globalObjects []string
+
+	// blocks is true if the tenants objects are TSDB blocks.
+	blocks bool
+}
+
+var (
+	blocksLayout = layout{
+		tenantDirs: []string{"", users.GlobalMarkersDir + objstore.DirDelim},
+		tenantPrefixes: func(userID string) []string {
+			return []string{userID + objstore.DirDelim, path.Join(users.GlobalMarkersDir, userID) + objstore.DirDelim}
+		},
+		globalObjects: []string{users.UserIndexCompressedFilename},
+		blocks:        true,
+	}
+
+	// The layout of the ruler storage, see the ruler bucketclient package.
+	rulesLayout = layout{
+		tenantDirs: []string{"rules/"},
+		tenantPrefixes: func(userID string) []string {
+			return []string{"rules/" + userID + objstore.DirDelim}
+		},
+		globalObjects: []string{path.Join("rules", users.UserIndexCompressedFilename)},
+	}
+
+	// The layout of the alertmanager storage, see the alertmanager bucketclient package.
+	alertmanagerLayout = layout{
+		tenantDirs: []string{"alerts/", "alertmanager/"},
+		tenantPrefixes: func(userID string) []string {
+			return []string{"alerts/" + userID, "alertmanager/" + userID + objstore.DirDelim}
+		},
+		globalObjects: []string{path.Join("alerts", users.UserIndexCompressedFilename)},
+	}
+)
+
+// source replicates the objects of a primary bucket to a secondary bucket.
+type source struct {
+	name      string
+	layout    layout
+	primary   objstore.Bucket
+	secondary objstore.Bucket
+	metrics   *metrics
+
+	// replicas are the mutable objects replicated to the secondary bucket.
+	replicasMtx sync.Mutex
+	replicas    map[string]replica
+}
+
+// replica is a mutable object replicated to the secondary bucket.
+type replica struct {
+	// attrs are the attributes of the object in the primary bucket when it's been replicated.
+	attrs objstore.ObjectAttributes
+
+	// hash is the SHA-256 of the replicated content.
+	hash [sha256.Size]byte
+}
+
+// listTenants returns the tenants which have objects in the input bucket.
+func (s *source) listTenants(ctx context.Context, bkt objstore.Bucket) (map[string]struct{}, error) {
+	tenants := map[string]struct{}{}
+	for _, dir := range s.layout.tenantDirs {
+		err := bkt.Iter(ctx, dir, func(name string) error {
+			userID := strings.TrimSuffix(strings.TrimPrefix(name, dir), objstore.DirDelim)
+			if userID == "" || userID == users.GlobalMarkersDir || slices.Contains(s.layout.globalObjects, name) {
+				return nil
+			}
+			// A tenant is a directory, unless the layout stores a single object per tenant in this directory.
+			if !strings.HasSuffix(name, objstore.DirDelim) && !slices.Contains(s.layout.tenantPrefixes(userID), name) {
+				return nil
+			}
+
+			tenants[userID] = struct{}{}
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "list the tenants of %s", s.name)
+		}
+	}
+	return tenants, nil
+}
+
+// listObjects returns the objects of the tenant in the input bucket.
+func (s *source) listObjects(ctx context.Context, bkt objstore.Bucket, userID string) (map[string]struct{}, error) {
+	objects := map[string]struct{}{}
+	for _, prefix := range s.layout.tenantPrefixes(userID) {
+		if !strings.HasSuffix(prefix, objstore.DirDelim) {
+			ok, err := bkt.Exists(ctx, prefix)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				objects[prefix] = struct{}{}
+			}
+			continue
+		}
+
+		err := bkt.Iter(ctx, prefix, func(name string) error {
+			objects[name] = struct{}{}
+			return nil
+		}, objstore.WithRecursiveIter())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return objects, nil
+}
+
+// blockFile returns the block and the file within the block of the input object of the tenant, if it's a block file.
+func (s *source) blockFile(userID, name string) (ulid.ULID, string, bool) {
+	if !s.layout.blocks {
+		return ulid.ULID{}, "", false
+	}
+
+	parts := strings.SplitN(name, objstore.DirDelim, 3)
+	if len(parts) != 3 || parts[0] != userID {
+		return ulid.ULID{}, "", false
+	}
+	id, err := ulid.Parse(parts[1])
+	if err != nil {
+		return ulid.ULID{}, "", false
+	}
+	return id, parts[2], true
+}
+
+// immutable returns whether the input object can't be updated once uploaded, so that
+// it doesn't need to be compared with its replica.
+func (s *source) immutable(userID, name string) bool {
+	_, file, ok := s.blockFile(userID, name)
+	return ok && !strings.HasSuffix(file, ".json")
+}
+
+// replicateTenant makes the objects of the tenant in the secondary bucket match the
+// ones in the primary bucket. The blocks are copied before their meta.json and the
+// bucket index is copied last, so that the secondary bucket is consistent for the
+// readers at any time. Likewise, the blocks are deleted in the same order as in the
+// primary bucket: meta.json first and deletion mark last.
+func (s *source) replicateTenant(ctx context.Context, userID string) error {
+	primary, err := s.listObjects(ctx, s.primary, userID)
+	if err != nil {
+		return errors.Wrapf(err, "list the objects of tenant %s in the primary storage", userID)
+	}
+	secondary, err := s.listObjects(ctx, s.secondary, userID)
+	if err != nil {
+		return errors.Wrapf(err, "list the objects of tenant %s in the secondary storage", userID)
+	}
+
+	primaryBlocks := s.groupBlocks(userID, primary)
+	secondaryBlocks := s.groupBlocks(userID, secondary)
+
+	for id, files := range primaryBlocks {
+		// Skip the blocks being uploaded, or being deleted. The blocks marked for deletion are
+		// replicated, because they're still referenced by the bucket index until deleted.
+		if _, ok := files[metadata.MetaFilename]; !ok {
+			continue
+		}
+
+		for _, file := range sortedKeys(files) {
+			if file != metadata.MetaFilename {
+				if err := s.replicateObject(ctx, userID, path.Join(userID, id.String(), file), secondary); err != nil {
+					return err
+				}
+			}
+		}
+		if err := s.replicateObject(ctx, userID, path.Join(userID, id.String(), metadata.MetaFilename), secondary); err != nil {
+			return err
+		}
+	}
+
+	var bucketIndex string
+	for _, name := range sortedKeys(primary) {
+		if _, _, ok := s.blockFile(userID, name); ok {
+			continue
+		}
+		if s.layout.blocks && name == path.Join(userID, bucketindex.IndexCompressedFilename) {
+			bucketIndex = name
+			continue
+		}
+		if err := s.replicateObject(ctx, userID, name, secondary); err != nil {
+			return err
+		}
+	}
+	if bucketIndex != "" {
+		if err := s.replicateObject(ctx, userID, bucketIndex, secondary); err != nil {
+			return err
+		}
+	}
+
+	for id, files := range secondaryBlocks {
+		_, exists := primaryBlocks[id]
+		_, complete := primaryBlocks[id][metadata.MetaFilename]
+		_, replicated := files[metadata.MetaFilename]
+		// Keep the partial blocks whose upload is still in progress in the primary storage.
+		if exists && (complete || !replicated) {
+			continue
+		}
+
+		if err := s.deleteBlock(ctx, userID, id, files); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range sortedKeys(secondary) {
+		if _, ok := primary[name]; ok {
+			continue
+		}
+		if _, _, ok := s.blockFile(userID, name); ok {
+			continue
+		}
+		if err := s.deleteObject(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *source) groupBlocks(userID string, objects map[string]struct{}) map[ulid.ULID]map[string]struct{} {
+	blocks := map[ulid.ULID]map[string]struct{}{}
+	for name := range objects {
+		id, file, ok := s.blockFile(userID, name)
+		if !ok {
+			continue
+		}
+		if blocks[id] == nil {
+			blocks[id] = map[string]struct{}{}
+		}
+		blocks[id][file] = struct{}{}
+	}
+	return blocks
+}
+
+func (s *source) deleteBlock(ctx context.Context, userID string, id ulid.ULID, files map[string]struct{}) error {
+	ordered := make([]string, 0, len(files))
+	if _, ok := files[metadata.MetaFilename]; ok {
+		ordered = append(ordered, metadata.MetaFilename)
+	}
+	for _, file := range sortedKeys(files) {
+		if file != metadata.MetaFilename && file != metadata.DeletionMarkFilename {
+			ordered = append(ordered, file)
+		}
+	}
+	if _, ok := files[metadata.DeletionMarkFilename]; ok {
+		ordered = append(ordered, metadata.DeletionMarkFilename)
+	}
+
+	for _, file := range ordered {
+		if err := s.deleteObject(ctx, path.Join(userID, id.String(), file)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replicateGlobalObjects replicates the objects which don't belong to any tenant.
+func (s *source) replicateGlobalObjects(ctx context.Context) error {
+	for _, name := range s.layout.globalObjects {
+		exists, err := s.primary.Exists(ctx, name)
+		if err != nil {
+			return err
+		}
+		replicated, err := s.secondary.Exists(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case exists:
+			secondary := map[string]struct{}{}
+			if replicated {
+				secondary[name] = struct{}{}
+			}
+			if err := s.replicateObject(ctx, "", name, secondary); err != nil {
+				return err
+			}
+		case replicated:
+			if err := s.deleteObject(ctx, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// replicateObject copies the object to the secondary bucket, unless it has already
+// been replicated and hasn't changed since.
+func (s *source) replicateObject(ctx context.Context, userID, name string, secondary map[string]struct{}) error {
+	if _, ok := secondary[name]; ok {
+		if s.immutable(userID, name) {
+			return nil
+		}
+
+		changed, err := s.changed(ctx, name)
+		if err != nil || !changed {
+			return err
+		}
+	}
+
+	// The attributes are read before the content, so that an update in between is detected at the next replication.
+	mutable := !s.immutable(userID, name)
+	var attrs objstore.ObjectAttributes
+	if mutable {
+		var err error
+		attrs, err = s.primary.Attributes(ctx, name)
+		if s.primary.IsObjNotFoundErr(err) {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "read the attributes of %s from the primary storage", name)
+		}
+	}
+
+	r, err := s.primary.Get(ctx, name)
+	if s.primary.IsObjNotFoundErr(err) {
+		// The object has been deleted since the listing.
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "read %s from the primary storage", name)
+	}
+	defer func() { _ = r.Close() }()
+
+	cr := &countingReader{r: r, hash: sha256.New()}
+	if err := s.secondary.Upload(ctx, name, cr); err != nil {
+		return errors.Wrapf(err, "upload %s to the secondary storage", name)
+	}
+	if mutable {
+		rep := replica{attrs: attrs}
+		cr.hash.Sum(rep.hash[:0])
+		s.setReplica(name, rep)
+	}
+
+	s.metrics.objectsReplicated.WithLabelValues(s.name).Inc()
+	s.metrics.bytesReplicated.WithLabelValues(s.name).Add(float64(cr.n))
+	return nil
+}
+
+// changed returns whether the object has been updated in the primary bucket since it's been replicated.
+// The attributes of the two buckets aren't comparable, since their backends may differ and the replica
+// is written after the original, so the object is unchanged if its attributes in the primary bucket
+// are the same as when it's been replicated. Otherwise, or if the object hasn't been replicated since
+// the startup, the content of the object is compared with the replicated content.
+func (s *source) changed(ctx context.Context, name string) (bool, error) {
+	attrs, err := s.primary.Attributes(ctx, name)
+	if s.primary.IsObjNotFoundErr(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	s.replicasMtx.Lock()
+	rep, replicated := s.replicas[name]
+	s.replicasMtx.Unlock()
+
+	if replicated && attrs.Size == rep.attrs.Size && attrs.LastModified.Equal(rep.attrs.LastModified) {
+		return false, nil
+	}
+
+	if !replicated {
+		rep.hash, err = hashObject(ctx, s.secondary, name)
+		if s.secondary.IsObjNotFoundErr(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+
+	sum, err := hashObject(ctx, s.primary, name)
+	if s.primary.IsObjNotFoundErr(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if sum != rep.hash {
+		return true, nil
+	}
+
+	s.setReplica(name, replica{attrs: attrs, hash: sum})
+	return false, nil
+}
+
+func (s *source) setReplica(name string, rep replica) {
+	s.replicasMtx.Lock()
+	defer s.replicasMtx.Unlock()
+
+	if s.replicas == nil {
+		s.replicas = map[string]replica{}
+	}
+	s.replicas[name] = rep
+}
+
+// hashObject returns the SHA-256 of the content of the object.
+func hashObject(ctx context.Context, bkt objstore.Bucket, name string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+
+	r, err := bkt.Get(ctx, name)
+	if err != nil {
+		return sum, err
+	}
+	defer func() { _ = r.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return sum, err
+	}
+	h.Sum(sum[:0])
+	return sum, nil
+}
+
+func (s *source) deleteObject(ctx context.Context, name string) error {
+	if err := s.secondary.Delete(ctx, name); err != nil && !s.secondary.IsObjNotFoundErr(err) {
+		return errors.Wrapf(err, "delete %s from the secondary storage", name)
+	}
+
+	s.replicasMtx.Lock()
+	delete(s.replicas, name)
+	s.replicasMtx.Unlock()
+
+	s.metrics.objectsDeleted.WithLabelValues(s.name).Inc()
+	return nil
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// countingReader counts and hashes the bytes read.
+type countingReader struct {
+	r    io.Reader
+	n    int64
+	hash hash.Hash
+}
+
+// ObjectSize implements objstore.ObjectSizer.
+func (c *countingReader) ObjectSize() (int64, error) {
+	return objstore.TryToGetSize(c.r)
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	c.hash.Write(p[:n])
+	return n, err
+}
@@ -54,6 +54,9 @@ type BasicLifecyclerConfig struct {
 	NumTokens               int
 	TokensGeneratorStrategy string
 
+	// TokensRebalance configures the periodic rebalancing of the instance tokens.
+	TokensRebalance TokensRebalanceConfig
+
 	// If true lifecycler doesn't unregister instance from the ring when it's stopping. Default value is false,
 	// which means unregistering.
 	KeepInstanceInTheRingOnShutdown bool
@@ -210,11 +213,22 @@ func (l *BasicLifecycler) running(ctx context.Context) error {
 		heartbeatTickerChan = heartbeatTicker.C
 	}
 
+	var rebalanceTickerChan <-chan time.Time
+	if l.cfg.TokensRebalance.Period > 0 {
+		rebalanceTicker := time.NewTicker(l.cfg.TokensRebalance.Period)
+		defer rebalanceTicker.Stop()
+
+		rebalanceTickerChan = rebalanceTicker.C
+	}
+
 	for {
 		select {
 		case <-heartbeatTickerChan:
 			l.heartbeat(ctx)
 
+		case <-rebalanceTickerChan:
+			l.rebalanceTokens(ctx)
+
 		case f := <-l.actorChan:
 			f()
 
@@ -475,6 +489,43 @@ func (l *BasicLifecycler) heartbeat(ctx context.Context) {
 	l.metrics.heartbeats.Inc()
 }
 
+// rebalanceTokens moves some of the instance tokens to get closer to an even ownership
+// within its zone. This function is guaranteed to be called within the lifecycler main goroutine.
+func (l *BasicLifecycler) rebalanceTokens(ctx context.Context) {
+	if l.GetState() != ACTIVE {
+		return
+	}
+
+	moved := 0
+	err := l.updateInstance(ctx, func(r *Desc, i *InstanceDesc) bool {
+		var tokens Tokens
+		now := time.Now()
+
+		tokens, moved = RebalanceTokens(r, l.cfg.ID, l.cfg.TokensRebalance, now)
+		if moved == 0 {
+			return false
+		}
+
+		i.Tokens = tokens
+		i.TokensRebalancedTimestamp = now.Unix()
+		return true
+	})
+
+	if err != nil {
+		level.Warn(l.logger).Log("msg", "failed to rebalance tokens in the ring", "ring", l.ringName, "err", err)
+		return
+	}
+	if moved == 0 {
+		return
+	}
+
+	tokens := l.GetTokens()
+	l.metrics.tokensOwned.Set(float64(len(tokens)))
+	l.metrics.tokensRebalanced.Add(float64(moved))
+	l.delegate.OnRingInstanceTokens(l, tokens)
+	level.Info(l.logger).Log("msg", "rebalanced tokens", "ring", l.ringName, "moved", moved)
+}
+
 // changeState of the instance within the ring. This function is guaranteed
 // to be called within the lifecycler main goroutine.
 func (l *BasicLifecycler) changeState(ctx context.Context, state InstanceState) error {
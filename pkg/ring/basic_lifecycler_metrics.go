@@ -6,9 +6,10 @@ import (
 )
 
 type BasicLifecyclerMetrics struct {
-	heartbeats  prometheus.Counter
-	tokensOwned prometheus.Gauge
-	tokensToOwn prometheus.Gauge
+	heartbeats       prometheus.Counter
+	tokensOwned      prometheus.Gauge
+	tokensToOwn      prometheus.Gauge
+	tokensRebalanced prometheus.Counter
 }
 
 func NewBasicLifecyclerMetrics(ringName string, reg prometheus.Registerer) *BasicLifecyclerMetrics {
@@ -28,5 +29,10 @@ func NewBasicLifecyclerMetrics(ringName string, reg prometheus.Registerer) *Basi
 			Help:        "The number of tokens to own in the ring.",
 			ConstLabels: prometheus.Labels{"name": ringName},
 		}),
+		tokensRebalanced: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name:        "ring_member_tokens_rebalanced_total",
+			Help:        "The total number of tokens moved to rebalance the ring ownership.",
+			ConstLabels: prometheus.Labels{"name": ringName},
+		}),
 	}
 }
@@ -393,6 +393,42 @@ func TestBasicLifecycler_TokensObservePeriod(t *testing.T) {
 	assert.NotContains(t, lifecycler.GetTokens(), uint32(3))
 }
 
+func TestBasicLifecycler_TokensRebalance(t *testing.T) {
+	ctx := context.Background()
+	cfg := prepareBasicLifecyclerConfig()
+	cfg.NumTokens = 3
+	cfg.TokensRebalance = TokensRebalanceConfig{Period: 10 * time.Millisecond, MaxTokensPerStep: 1, Tolerance: 0.05}
+
+	lifecycler, delegate, store, err := prepareBasicLifecycler(t, cfg)
+	require.NoError(t, err)
+
+	// Register another instance in the same zone, so that the lifecycler instance owns 75% of the zone.
+	const quarter = uint32(1 << 30)
+	require.NoError(t, store.CAS(ctx, testRingKey, func(in any) (out any, retry bool, err error) {
+		ringDesc := GetOrCreateRingDesc(in)
+		ringDesc.AddIngester("other-id", "127.0.0.1:12346", cfg.Zone, Tokens{3 * quarter, maxTokenValue - 1, maxTokenValue}, ACTIVE, time.Now())
+		return ringDesc, true, nil
+	}))
+
+	delegate.onRegister = func(_ *BasicLifecycler, _ Desc, _ bool, _ string, _ InstanceDesc) (InstanceState, Tokens) {
+		return ACTIVE, Tokens{quarter, 2 * quarter, 3*quarter - 1}
+	}
+
+	defer services.StopAndAwaitTerminated(ctx, lifecycler) //nolint:errcheck
+	require.NoError(t, services.StartAndAwaitRunning(ctx, lifecycler))
+
+	test.Poll(t, time.Second*5, true, func() any {
+		desc, _ := getInstanceFromStore(t, store, testInstanceID)
+		return desc.GetTokensRebalancedTimestamp() > 0
+	})
+
+	desc, _ := getInstanceFromStore(t, store, testInstanceID)
+	assert.Len(t, desc.GetTokens(), 3)
+	assert.NotEqual(t, Tokens{quarter, 2 * quarter, 3*quarter - 1}, Tokens(desc.GetTokens()))
+	assert.Equal(t, Tokens(desc.GetTokens()), lifecycler.GetTokens())
+	assert.Greater(t, testutil.ToFloat64(lifecycler.metrics.tokensRebalanced), float64(0))
+}
+
 func TestBasicLifecycler_updateInstance_ShouldAddInstanceToTheRingIfDoesNotExistEvenIfNotChanged(t *testing.T) {
 	ctx := context.Background()
 	cfg := prepareBasicLifecyclerConfig()
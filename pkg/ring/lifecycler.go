@@ -56,6 +56,8 @@ type LifecyclerConfig struct {
 	UnregisterOnShutdown     bool          `yaml:"unregister_on_shutdown"`
 	ReadinessCheckRingHealth bool          `yaml:"readiness_check_ring_health"`
 
+	TokensRebalance TokensRebalanceConfig `yaml:"tokens_rebalance"`
+
 	// For testing, you can override the address and ID of this ingester
 	Addr string `yaml:"address" doc:"hidden"`
 	Port int    `doc:"hidden"`
@@ -88,6 +90,7 @@ func (cfg *LifecyclerConfig) RegisterFlagsWithPrefix(prefix string, f *flag.Flag
 	f.DurationVar(&cfg.MinReadyDuration, prefix+"min-ready-duration", 15*time.Second, "Minimum duration to wait after the internal readiness checks have passed but before succeeding the readiness endpoint. This is used to slowdown deployment controllers (eg. Kubernetes) after an instance is ready and before they proceed with a rolling update, to give the rest of the cluster instances enough time to receive ring updates.")
 	f.DurationVar(&cfg.FinalSleep, prefix+"final-sleep", 30*time.Second, "Duration to sleep for before exiting, to ensure metrics are scraped.")
 	f.StringVar(&cfg.TokensFilePath, prefix+"tokens-file-path", "", "File path where tokens are stored. If empty, tokens are not stored at shutdown and restored at startup.")
+	cfg.TokensRebalance.RegisterFlagsWithPrefix(prefix, f)
 
 	hostname, err := os.Hostname()
 	if err != nil {
@@ -109,7 +112,7 @@ func (cfg *LifecyclerConfig) Validate() error {
 		return errInvalidTokensGeneratorStrategy
 	}
 
-	return nil
+	return cfg.TokensRebalance.Validate()
 }
 
 // Lifecycler is responsible for managing the lifecycle of entries in the ring.
@@ -553,6 +556,14 @@ func (i *Lifecycler) loop(ctx context.Context) error {
 		startHeartbeat()
 	}
 
+	var rebalanceTickerChan <-chan time.Time
+	if i.cfg.TokensRebalance.Period > 0 {
+		rebalanceTicker := time.NewTicker(i.cfg.TokensRebalance.Period)
+		defer rebalanceTicker.Stop()
+
+		rebalanceTickerChan = rebalanceTicker.C
+	}
+
 	for {
 		select {
 		case <-i.autojoinChan:
@@ -616,6 +627,8 @@ func (i *Lifecycler) loop(ctx context.Context) error {
 
 		case <-heartbeatTickerChan:
 			i.heartbeat(ctx)
+		case <-rebalanceTickerChan:
+			i.rebalanceTokens(ctx)
 		case f := <-i.actorChan:
 			f()
 
@@ -863,6 +876,51 @@ func (i *Lifecycler) RenewTokens(ratio float64, ctx context.Context) {
 	}
 }
 
+// rebalanceTokens moves some of the instance tokens to get closer to an even ownership within
+// its zone. The instance is marked as rebalanced in the ring, so that the read path can keep
+// querying it for the ranges it owned before the move.
+func (i *Lifecycler) rebalanceTokens(ctx context.Context) {
+	if i.GetState() != ACTIVE {
+		return
+	}
+
+	var (
+		newTokens Tokens
+		moved     int
+	)
+	err := i.KVStore.CAS(ctx, i.RingKey, func(in any) (out any, retry bool, err error) {
+		if in == nil {
+			return nil, false, nil
+		}
+
+		ringDesc := in.(*Desc)
+		now := time.Now()
+		newTokens, moved = RebalanceTokens(ringDesc, i.ID, i.cfg.TokensRebalance, now)
+		if moved == 0 {
+			return nil, false, nil
+		}
+
+		instanceDesc := ringDesc.Ingesters[i.ID]
+		instanceDesc.Tokens = newTokens
+		instanceDesc.Timestamp = now.Unix()
+		instanceDesc.TokensRebalancedTimestamp = now.Unix()
+		ringDesc.Ingesters[i.ID] = instanceDesc
+		return ringDesc, true, nil
+	})
+
+	if err != nil {
+		level.Error(i.logger).Log("msg", "failed to rebalance tokens", "ring", i.RingName, "err", err)
+		return
+	}
+	if moved == 0 {
+		return
+	}
+
+	i.setTokens(newTokens)
+	i.lifecyclerMetrics.tokensRebalanced.Add(float64(moved))
+	level.Info(i.logger).Log("msg", "rebalanced tokens", "ring", i.RingName, "moved", moved)
+}
+
 // Verifies that tokens that this ingester has registered to the ring still belong to it.
 // Gossiping ring may change the ownership of tokens in case of conflicts.
 // If ingester doesn't own its tokens anymore, this method generates new tokens and puts them to the ring.
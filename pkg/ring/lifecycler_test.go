@@ -126,6 +126,56 @@ func TestLifecycler_RenewTokens(t *testing.T) {
 	require.Equal(t, 51, diff)
 }
 
+func TestLifecycler_TokensRebalance(t *testing.T) {
+	ringStore, closer := consul.NewInMemoryClient(GetCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+
+	var ringConfig Config
+	flagext.DefaultValues(&ringConfig)
+	ringConfig.KVStore.Mock = ringStore
+
+	ctx := context.Background()
+	lifecyclerConfig := testLifecyclerConfig(ringConfig, "ing1")
+	lifecyclerConfig.NumTokens = 3
+	lifecyclerConfig.TokensRebalance = TokensRebalanceConfig{Period: 10 * time.Millisecond, MaxTokensPerStep: 1, Tolerance: 0.05}
+
+	// The instance owns 75% of the zone when it joins the ring.
+	const quarter = uint32(1 << 30)
+	originalTokens := Tokens{quarter, 2 * quarter, 3*quarter - 1}
+	require.NoError(t, ringStore.CAS(ctx, ringKey, func(in any) (out any, retry bool, err error) {
+		ringDesc := NewDesc()
+		ringDesc.AddIngester("ing1", "0.0.0.0:1", lifecyclerConfig.Zone, originalTokens, ACTIVE, time.Now())
+		ringDesc.AddIngester("ing2", "0.0.0.0:2", lifecyclerConfig.Zone, Tokens{3 * quarter, maxTokenValue - 1, maxTokenValue}, ACTIVE, time.Now())
+		return ringDesc, true, nil
+	}))
+
+	l1, err := NewLifecycler(lifecyclerConfig, &nopFlushTransferer{}, "ingester", ringKey, true, true, log.NewNopLogger(), nil)
+	require.NoError(t, err)
+
+	require.NoError(t, services.StartAndAwaitRunning(ctx, l1))
+	defer services.StopAndAwaitTerminated(ctx, l1) // nolint:errcheck
+
+	waitRingInstance(t, 3*time.Second, l1, func(instance InstanceDesc) error {
+		if instance.TokensRebalancedTimestamp == 0 {
+			return errors.New("tokens should be rebalanced")
+		}
+		return nil
+	})
+
+	newTokens := l1.getTokens()
+	require.Len(t, newTokens, 3)
+	require.IsIncreasing(t, newTokens)
+	require.NotEqual(t, originalTokens, newTokens)
+
+	test.Poll(t, 3*time.Second, true, func() any {
+		desc, err := ringStore.Get(ctx, ringKey)
+		require.NoError(t, err)
+
+		ownership, _ := desc.(*Desc).GetOwnership(true).Instance("ing1")
+		return ownership.Ownership >= 50*(1-lifecyclerConfig.TokensRebalance.Tolerance) && ownership.Ownership <= 50*(1+lifecyclerConfig.TokensRebalance.Tolerance)
+	})
+}
+
 func TestLifecycler_DefferedJoin(t *testing.T) {
 	ringStore, closer := consul.NewInMemoryClient(GetCodec(), log.NewNopLogger(), nil)
 	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
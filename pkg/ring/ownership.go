@@ -0,0 +1,219 @@
+package ring
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// InstanceOwnership describes how much of the ring is owned by a single instance.
+type InstanceOwnership struct {
+	ID        string `json:"id"`
+	Zone      string `json:"zone"`
+	State     string `json:"state"`
+	NumTokens int    `json:"tokens"`
+	// Ownership is the percentage of the ring (or of the instance's zone, when zone
+	// awareness is enabled) owned by the instance.
+	Ownership float64 `json:"ownership"`
+	// DiffOwnership is the percentage difference between the actual and the expected
+	// ownership, where the expected ownership is an even split among the instances.
+	DiffOwnership float64 `json:"diff_ownership"`
+}
+
+// ZoneOwnership describes how much of the ring is owned by a single zone.
+type ZoneOwnership struct {
+	Zone         string  `json:"zone"`
+	NumInstances int     `json:"instances"`
+	NumTokens    int     `json:"tokens"`
+	Ownership    float64 `json:"ownership"`
+}
+
+// OwnershipReport contains the token ownership of every instance and zone in the ring.
+type OwnershipReport struct {
+	Instances []InstanceOwnership `json:"instances"`
+	Zones     []ZoneOwnership     `json:"zones"`
+}
+
+// Instance returns the ownership of the instance with the given ID.
+func (r OwnershipReport) Instance(id string) (InstanceOwnership, bool) {
+	for _, i := range r.Instances {
+		if i.ID == id {
+			return i, true
+		}
+	}
+	return InstanceOwnership{}, false
+}
+
+// GetOwnership computes the token ownership of each instance and zone in the ring.
+// When zoneAware is true, each instance ownership is computed relative to the tokens
+// of its own zone, since each zone holds a full copy of the data.
+func (d *Desc) GetOwnership(zoneAware bool) OwnershipReport {
+	tokensInfo := d.getTokensInfo()
+
+	ownedByInstance := map[string]int64{}
+	if zoneAware {
+		for _, zonalTokens := range d.getTokensByZone() {
+			countOwnedTokens(zonalTokens, tokensInfo, ownedByInstance)
+		}
+	} else {
+		countOwnedTokens(d.GetTokens(), tokensInfo, ownedByInstance)
+	}
+
+	instancesByZone := map[string]int{}
+	for _, instance := range d.Ingesters {
+		instancesByZone[instance.Zone]++
+	}
+
+	report := OwnershipReport{}
+	zones := map[string]*ZoneOwnership{}
+
+	for id, instance := range d.Ingesters {
+		ownership := float64(ownedByInstance[id]) / float64(math.MaxUint32+1) * 100
+
+		expected := 100 / float64(len(d.Ingesters))
+		if zoneAware {
+			expected = 100 / float64(instancesByZone[instance.Zone])
+		}
+
+		diff := float64(-100)
+		if ownership > 0 {
+			diff = (1 - expected/ownership) * 100
+		}
+
+		report.Instances = append(report.Instances, InstanceOwnership{
+			ID:            id,
+			Zone:          instance.Zone,
+			State:         instance.State.String(),
+			NumTokens:     len(instance.Tokens),
+			Ownership:     ownership,
+			DiffOwnership: diff,
+		})
+
+		zone, ok := zones[instance.Zone]
+		if !ok {
+			zone = &ZoneOwnership{Zone: instance.Zone}
+			zones[instance.Zone] = zone
+		}
+		zone.NumInstances++
+		zone.NumTokens += len(instance.Tokens)
+		zone.Ownership += ownership
+	}
+
+	for _, zone := range zones {
+		report.Zones = append(report.Zones, *zone)
+	}
+
+	sort.Slice(report.Instances, func(i, j int) bool { return report.Instances[i].ID < report.Instances[j].ID })
+	sort.Slice(report.Zones, func(i, j int) bool { return report.Zones[i].Zone < report.Zones[j].Zone })
+
+	return report
+}
+
+// countOwnedTokens adds to owned the range of the ring owned by each instance, given the
+// sorted list of tokens.
+func countOwnedTokens(tokens []uint32, tokensInfo map[uint32]instanceInfo, owned map[string]int64) {
+	for i := 1; i <= len(tokens); i++ {
+		index := i % len(tokens)
+		info := tokensInfo[tokens[index]]
+		owned[info.InstanceID] += tokenDistance(tokens[i-1], tokens[index])
+	}
+}
+
+// SimulatedInstance is an instance added to the ring by SimulateOwnership.
+type SimulatedInstance struct {
+	ID   string
+	Zone string
+}
+
+// OwnershipChange is the ownership of an instance before and after a simulated change.
+type OwnershipChange struct {
+	ID      string  `json:"id"`
+	Zone    string  `json:"zone"`
+	Before  float64 `json:"before"`
+	After   float64 `json:"after"`
+	Added   bool    `json:"added,omitempty"`
+	Removed bool    `json:"removed,omitempty"`
+}
+
+// SimulationConfig configures a "what if" ownership simulation.
+type SimulationConfig struct {
+	Add       []SimulatedInstance
+	Remove    []string
+	NumTokens int
+	ZoneAware bool
+	Generator TokenGenerator
+}
+
+// SimulateOwnership previews how the ring ownership would change by adding and removing
+// the given instances, without modifying the ring. New instances are given tokens by the
+// configured token generator, one after the other, as it happens when instances join the ring.
+func SimulateOwnership(d *Desc, cfg SimulationConfig) ([]OwnershipChange, error) {
+	if len(cfg.Add) > 0 && cfg.NumTokens <= 0 {
+		return nil, fmt.Errorf("the number of tokens must be positive to add instances")
+	}
+
+	generator := cfg.Generator
+	if generator == nil {
+		generator = NewRandomTokenGenerator()
+	}
+
+	before := d.GetOwnership(cfg.ZoneAware)
+	simulated := d.Clone().(*Desc)
+	if simulated.Ingesters == nil {
+		simulated.Ingesters = map[string]InstanceDesc{}
+	}
+
+	for _, id := range cfg.Remove {
+		if _, ok := simulated.Ingesters[id]; !ok {
+			return nil, fmt.Errorf("instance %s not found in the ring", id)
+		}
+		simulated.RemoveIngester(id)
+	}
+
+	for _, instance := range cfg.Add {
+		if _, ok := simulated.Ingesters[instance.ID]; ok {
+			return nil, fmt.Errorf("instance %s already exists in the ring", instance.ID)
+		}
+		simulated.AddIngester(instance.ID, "", instance.Zone, nil, ACTIVE, time.Now())
+		tokens := generator.GenerateTokens(simulated, instance.ID, instance.Zone, cfg.NumTokens, true)
+		simulated.AddIngester(instance.ID, "", instance.Zone, tokens, ACTIVE, time.Now())
+	}
+
+	after := simulated.GetOwnership(cfg.ZoneAware)
+
+	removed := map[string]bool{}
+	for _, id := range cfg.Remove {
+		removed[id] = true
+	}
+
+	changes := make([]OwnershipChange, 0, len(before.Instances)+len(cfg.Add))
+	for _, b := range before.Instances {
+		change := OwnershipChange{ID: b.ID, Zone: b.Zone, Before: b.Ownership, Removed: removed[b.ID]}
+		if a, ok := after.Instance(b.ID); ok {
+			change.After = a.Ownership
+		}
+		changes = append(changes, change)
+	}
+	for _, instance := range cfg.Add {
+		a, _ := after.Instance(instance.ID)
+		changes = append(changes, OwnershipChange{ID: instance.ID, Zone: instance.Zone, After: a.Ownership, Added: true})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].ID < changes[j].ID })
+	return changes, nil
+}
+
+// NewTokenGenerator returns the TokenGenerator for the given strategy. An empty
+// strategy returns the default random generator.
+func NewTokenGenerator(strategy string) (TokenGenerator, error) {
+	switch strings.ToLower(strategy) {
+	case "", randomTokenStrategy:
+		return NewRandomTokenGenerator(), nil
+	case minimizeSpreadTokenStrategy:
+		return NewMinimizeSpreadTokenGenerator(), nil
+	default:
+		return nil, errInvalidTokensGeneratorStrategy
+	}
+}
@@ -0,0 +1,120 @@
+package ring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDesc_GetOwnership(t *testing.T) {
+	t.Parallel()
+
+	const quarter = uint32(1 << 30)
+
+	tests := map[string]struct {
+		ring      *Desc
+		zoneAware bool
+		expected  map[string]float64
+		zones     map[string]float64
+	}{
+		"single zone with even tokens": {
+			ring: &Desc{Ingesters: map[string]InstanceDesc{
+				"instance-1": {Zone: "zone-a", Tokens: []uint32{0, 2 * quarter}},
+				"instance-2": {Zone: "zone-a", Tokens: []uint32{quarter, 3 * quarter}},
+			}},
+			expected: map[string]float64{"instance-1": 50, "instance-2": 50},
+			zones:    map[string]float64{"zone-a": 100},
+		},
+		"single zone with uneven tokens": {
+			ring: &Desc{Ingesters: map[string]InstanceDesc{
+				"instance-1": {Zone: "zone-a", Tokens: []uint32{quarter}},
+				"instance-2": {Zone: "zone-a", Tokens: []uint32{0}},
+			}},
+			expected: map[string]float64{"instance-1": 25, "instance-2": 75},
+			zones:    map[string]float64{"zone-a": 100},
+		},
+		"instance without tokens": {
+			ring: &Desc{Ingesters: map[string]InstanceDesc{
+				"instance-1": {Zone: "zone-a", Tokens: []uint32{quarter}},
+				"instance-2": {Zone: "zone-a"},
+			}},
+			expected: map[string]float64{"instance-1": 100, "instance-2": 0},
+			zones:    map[string]float64{"zone-a": 100},
+		},
+		"zone aware": {
+			ring: &Desc{Ingesters: map[string]InstanceDesc{
+				"instance-1": {Zone: "zone-a", Tokens: []uint32{0, 2 * quarter}},
+				"instance-2": {Zone: "zone-a", Tokens: []uint32{quarter, 3 * quarter}},
+				"instance-3": {Zone: "zone-b", Tokens: []uint32{quarter + 1}},
+			}},
+			zoneAware: true,
+			expected:  map[string]float64{"instance-1": 50, "instance-2": 50, "instance-3": 100},
+			zones:     map[string]float64{"zone-a": 100, "zone-b": 100},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			report := testData.ring.GetOwnership(testData.zoneAware)
+
+			require.Len(t, report.Instances, len(testData.expected))
+			for id, expected := range testData.expected {
+				actual, ok := report.Instance(id)
+				require.True(t, ok)
+				assert.InDelta(t, expected, actual.Ownership, 0.0001, id)
+			}
+
+			require.Len(t, report.Zones, len(testData.zones))
+			for _, zone := range report.Zones {
+				assert.InDelta(t, testData.zones[zone.Zone], zone.Ownership, 0.0001, zone.Zone)
+			}
+		})
+	}
+}
+
+func TestSimulateOwnership(t *testing.T) {
+	t.Parallel()
+
+	ring := NewDesc()
+	ring.AddIngester("instance-1", "", "zone-a", []uint32{0}, ACTIVE, time.Now())
+	ring.AddIngester("instance-2", "", "zone-a", []uint32{1 << 31}, ACTIVE, time.Now())
+
+	t.Run("add instance", func(t *testing.T) {
+		changes, err := SimulateOwnership(ring, SimulationConfig{
+			Add:       []SimulatedInstance{{ID: "instance-3", Zone: "zone-a"}},
+			NumTokens: 128,
+			Generator: NewMinimizeSpreadTokenGenerator(),
+		})
+		require.NoError(t, err)
+		require.Len(t, changes, 3)
+
+		assert.Equal(t, "instance-3", changes[2].ID)
+		assert.True(t, changes[2].Added)
+		assert.Zero(t, changes[2].Before)
+		assert.InDelta(t, 33.3, changes[2].After, 1)
+
+		// The input ring must not be modified.
+		assert.Len(t, ring.Ingesters, 2)
+	})
+
+	t.Run("remove instance", func(t *testing.T) {
+		changes, err := SimulateOwnership(ring, SimulationConfig{Remove: []string{"instance-1"}})
+		require.NoError(t, err)
+		assert.Equal(t, []OwnershipChange{
+			{ID: "instance-1", Zone: "zone-a", Before: 50, After: 0, Removed: true},
+			{ID: "instance-2", Zone: "zone-a", Before: 50, After: 100},
+		}, changes)
+	})
+
+	t.Run("remove unknown instance", func(t *testing.T) {
+		_, err := SimulateOwnership(ring, SimulationConfig{Remove: []string{"unknown"}})
+		require.Error(t, err)
+	})
+
+	t.Run("add existing instance", func(t *testing.T) {
+		_, err := SimulateOwnership(ring, SimulationConfig{Add: []SimulatedInstance{{ID: "instance-1"}}, NumTokens: 1})
+		require.Error(t, err)
+	})
+}
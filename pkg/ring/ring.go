@@ -44,6 +44,10 @@ type ReadRing interface {
 	// to avoid memory allocation; can be nil, or created with ring.MakeBuffersForGet().
 	Get(key uint32, op Operation, bufDescs []InstanceDesc, bufHosts []string, bufZones map[string]int) (ReplicationSet, error)
 
+	// GetWithReplicationFactor is like Get() but uses the given replication factor instead of
+	// the one configured for the ring.
+	GetWithReplicationFactor(key uint32, op Operation, replicationFactor int, bufDescs []InstanceDesc, bufHosts []string, bufZones map[string]int) (ReplicationSet, error)
+
 	// GetAllHealthy returns all healthy instances in the ring, for the given operation.
 	// This function doesn't check if the quorum is honored, so doesn't fail if the number
 	// of unhealthy instances is greater than the tolerated max unavailable.
@@ -378,6 +382,11 @@ func (r *Ring) updateRingState(ringDesc *Desc) {
 // - Stability: given the same ring, two invocations returns the same set for same operation.
 // - Consistency: adding/removing 1 instance from the ring returns set with no more than 1 difference for same operation.
 func (r *Ring) Get(key uint32, op Operation, bufDescs []InstanceDesc, bufHosts []string, bufZones map[string]int) (ReplicationSet, error) {
+	return r.GetWithReplicationFactor(key, op, r.cfg.ReplicationFactor, bufDescs, bufHosts, bufZones)
+}
+
+// GetWithReplicationFactor implements ReadRing.
+func (r *Ring) GetWithReplicationFactor(key uint32, op Operation, rf int, bufDescs []InstanceDesc, bufHosts []string, bufZones map[string]int) (ReplicationSet, error) {
 	r.mtx.RLock()
 	defer r.mtx.RUnlock()
 	if r.ringDesc == nil || len(r.ringTokens) == 0 {
@@ -385,7 +394,7 @@ func (r *Ring) Get(key uint32, op Operation, bufDescs []InstanceDesc, bufHosts [
 	}
 
 	var (
-		replicationFactor      = r.cfg.ReplicationFactor
+		replicationFactor      = rf
 		instances              = bufDescs[:0]
 		start                  = searchToken(r.ringTokens, key)
 		iterations             = 0
@@ -452,7 +461,7 @@ func (r *Ring) Get(key uint32, op Operation, bufDescs []InstanceDesc, bufHosts [
 		instances = append(instances, instance)
 	}
 
-	healthyInstances, maxFailure, err := r.strategy.Filter(instances, op, r.cfg.ReplicationFactor, r.cfg.HeartbeatTimeout, r.cfg.ZoneAwarenessEnabled, r.KVClient.LastUpdateTime(r.key))
+	healthyInstances, maxFailure, err := r.strategy.Filter(instances, op, rf, r.cfg.HeartbeatTimeout, r.cfg.ZoneAwarenessEnabled, r.KVClient.LastUpdateTime(r.key))
 	if err != nil {
 		return ReplicationSet{}, err
 	}
@@ -825,6 +834,17 @@ func (r *Ring) shuffleShard(identifier string, size int, lookbackPeriod time.Dur
 			tokens = r.ringTokens
 		}
 
+		// If the lookback is enabled, the instances which have moved their tokens within the lookback
+		// period may hold series for token ranges they don't own anymore, so we include them in the
+		// subring before selecting the other instances.
+		if lookbackPeriod > 0 {
+			for instanceID, instance := range r.ringDesc.Ingesters {
+				if (!r.cfg.ZoneAwarenessEnabled || instance.Zone == zone) && instance.TokensRebalancedTimestamp >= lookbackUntil {
+					shard[instanceID] = instance
+				}
+			}
+		}
+
 		// Initialise the random generator used to select instances in the ring.
 		// Since we consider each zone like an independent ring, we have to use dedicated
 		// pseudo-random generator for each zone, in order to guarantee the "consistency"
@@ -127,6 +127,13 @@ type InstanceDesc struct {
 	// was already registered before "now". If unknown (0), it should be left as is, and the
 	// code will properly deal with that.
 	RegisteredTimestamp int64 `protobuf:"varint,8,opt,name=registered_timestamp,json=registeredTimestamp,proto3" json:"registered_timestamp,omitempty"`
+	// Unix timestamp (with seconds precision) of the last time the instance has moved
+	// some of its tokens to rebalance the ring ownership. It's 0 if the tokens have never
+	// been rebalanced.
+	//
+	// This field is used by the read path to find out the instances which could have owned
+	// a specific token in the past, similarly to the registered timestamp.
+	TokensRebalancedTimestamp int64 `protobuf:"varint,9,opt,name=tokens_rebalanced_timestamp,json=tokensRebalancedTimestamp,proto3" json:"tokens_rebalanced_timestamp,omitempty"`
 }
 
 func (m *InstanceDesc) Reset()      { *m = InstanceDesc{} }
@@ -203,6 +210,13 @@ func (m *InstanceDesc) GetRegisteredTimestamp() int64 {
 	return 0
 }
 
+func (m *InstanceDesc) GetTokensRebalancedTimestamp() int64 {
+	if m != nil {
+		return m.TokensRebalancedTimestamp
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterEnum("ring.InstanceState", InstanceState_name, InstanceState_value)
 	proto.RegisterType((*Desc)(nil), "ring.Desc")
@@ -213,34 +227,35 @@ func init() {
 func init() { proto.RegisterFile("ring.proto", fileDescriptor_26381ed67e202a6e) }
 
 var fileDescriptor_26381ed67e202a6e = []byte{
-	// 423 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x92, 0x41, 0x6b, 0xd4, 0x40,
-	0x1c, 0xc5, 0xe7, 0xbf, 0x99, 0xa4, 0xd9, 0xff, 0xb6, 0x65, 0x98, 0x16, 0x89, 0x45, 0xc6, 0xd0,
-	0x53, 0xf4, 0xb0, 0xe2, 0xea, 0x41, 0x04, 0x0f, 0x5b, 0x37, 0x4a, 0xc2, 0x92, 0x96, 0xb8, 0x14,
-	0xf4, 0x22, 0xb1, 0x3b, 0x84, 0x50, 0x9b, 0x94, 0x64, 0x14, 0xea, 0xc9, 0x8f, 0xe0, 0x17, 0xf0,
-	0xee, 0x47, 0xe9, 0x71, 0x4f, 0xd2, 0x93, 0xb8, 0xd9, 0x8b, 0xc7, 0x7e, 0x04, 0x99, 0xa4, 0x25,
-	0xdd, 0xdb, 0x7b, 0xff, 0xf7, 0xf2, 0x7e, 0x09, 0x04, 0xb1, 0xcc, 0xf2, 0x74, 0x78, 0x5e, 0x16,
-	0xaa, 0xe0, 0x54, 0xeb, 0xbd, 0xdd, 0xb4, 0x48, 0x8b, 0xe6, 0xf0, 0x44, 0xab, 0x36, 0xdb, 0xff,
-	0x09, 0x48, 0x27, 0xb2, 0x3a, 0xe1, 0xaf, 0xb0, 0x9f, 0xe5, 0xa9, 0xac, 0x94, 0x2c, 0x2b, 0x07,
-	0x5c, 0xc3, 0x1b, 0x8c, 0xee, 0x0f, 0x9b, 0x11, 0x1d, 0x0f, 0x83, 0xdb, 0xcc, 0xcf, 0x55, 0x79,
-	0x71, 0x40, 0x2f, 0xff, 0x3c, 0x24, 0x71, 0xf7, 0xc4, 0xde, 0x11, 0x6e, 0xaf, 0x57, 0x38, 0x43,
-	0xe3, 0x54, 0x5e, 0x38, 0xe0, 0x82, 0xd7, 0x8f, 0xb5, 0xe4, 0x1e, 0x9a, 0x5f, 0x93, 0xcf, 0x5f,
-	0xa4, 0xd3, 0x73, 0xc1, 0x1b, 0x8c, 0x78, 0x3b, 0x1f, 0xe4, 0x95, 0x4a, 0xf2, 0x13, 0xa9, 0x31,
-	0x71, 0x5b, 0x78, 0xd9, 0x7b, 0x01, 0x21, 0xb5, 0x7b, 0xcc, 0xd8, 0xff, 0x0d, 0xb8, 0x79, 0xb7,
-	0xc1, 0x39, 0xd2, 0x64, 0x3e, 0x2f, 0x6f, 0x76, 0x1b, 0xcd, 0x1f, 0x60, 0x5f, 0x65, 0x67, 0xb2,
-	0x52, 0xc9, 0xd9, 0x79, 0x33, 0x6e, 0xc4, 0xdd, 0x81, 0x3f, 0x42, 0xb3, 0x52, 0x89, 0x92, 0x8e,
-	0xe1, 0x82, 0xb7, 0x3d, 0xda, 0x59, 0xc7, 0xbe, 0xd3, 0x51, 0xdc, 0x36, 0xf8, 0x3d, 0xb4, 0x54,
-	0x71, 0x2a, 0xf3, 0xca, 0xb1, 0x5c, 0xc3, 0xdb, 0x8a, 0x6f, 0x9c, 0x86, 0x7e, 0x2b, 0x72, 0xe9,
-	0x6c, 0xb4, 0x50, 0xad, 0xf9, 0x53, 0xdc, 0x2d, 0x65, 0x9a, 0xe9, 0x2f, 0x96, 0xf3, 0x8f, 0x1d,
-	0xdf, 0x6e, 0xf8, 0x3b, 0x5d, 0x36, 0xbb, 0x8d, 0x42, 0x6a, 0x53, 0x66, 0x86, 0xd4, 0x36, 0x99,
-	0xf5, 0xf8, 0x03, 0x6e, 0xad, 0xbd, 0x02, 0x47, 0xb4, 0xc6, 0xaf, 0x67, 0xc1, 0xb1, 0xcf, 0x08,
-	0x1f, 0xe0, 0xc6, 0xd4, 0x1f, 0x1f, 0x07, 0xd1, 0x5b, 0x06, 0xda, 0x1c, 0xf9, 0xd1, 0x44, 0x9b,
-	0x9e, 0x36, 0xe1, 0x61, 0x10, 0x69, 0x63, 0x70, 0x1b, 0xe9, 0xd4, 0x7f, 0x33, 0x63, 0x94, 0x6f,
-	0xa2, 0x1d, 0xfb, 0xe3, 0xc9, 0x61, 0x34, 0x7d, 0xcf, 0xcc, 0x83, 0xe7, 0x8b, 0xa5, 0x20, 0x57,
-	0x4b, 0x41, 0xae, 0x97, 0x02, 0xbe, 0xd7, 0x02, 0x7e, 0xd5, 0x02, 0x2e, 0x6b, 0x01, 0x8b, 0x5a,
-	0xc0, 0xdf, 0x5a, 0xc0, 0xbf, 0x5a, 0x90, 0xeb, 0x5a, 0xc0, 0x8f, 0x95, 0x20, 0x8b, 0x95, 0x20,
-	0x57, 0x2b, 0x41, 0x3e, 0x59, 0xcd, 0x1f, 0xf1, 0xec, 0x7f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x75,
-	0x1d, 0x75, 0xff, 0x3b, 0x02, 0x00, 0x00,
+	// 442 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x52, 0xbf, 0x6e, 0xd3, 0x40,
+	0x1c, 0xbe, 0xb3, 0xcf, 0xae, 0xf3, 0x4b, 0x5b, 0x9d, 0xae, 0x15, 0x72, 0x0b, 0x3a, 0xac, 0x4e,
+	0x86, 0x21, 0x88, 0xc0, 0x80, 0x90, 0x40, 0x4a, 0x89, 0x41, 0x8e, 0xa2, 0xb4, 0x3a, 0xa2, 0x4a,
+	0xb0, 0x54, 0x6e, 0x73, 0xb2, 0xac, 0xb6, 0x76, 0x65, 0x1f, 0x48, 0x65, 0xe2, 0x11, 0x98, 0xd8,
+	0xd8, 0x79, 0x94, 0x8e, 0x19, 0x3b, 0x21, 0xe2, 0x2c, 0x8c, 0x7d, 0x04, 0x74, 0x76, 0x1b, 0x27,
+	0xdb, 0xf7, 0xdd, 0xf7, 0xcf, 0x3f, 0xc9, 0x00, 0x79, 0x92, 0xc6, 0x9d, 0xcb, 0x3c, 0x53, 0x19,
+	0x23, 0x1a, 0xef, 0x6e, 0xc7, 0x59, 0x9c, 0x55, 0x0f, 0xcf, 0x34, 0xaa, 0xb5, 0xbd, 0x5f, 0x18,
+	0x48, 0x5f, 0x16, 0xa7, 0xec, 0x0d, 0xb4, 0x92, 0x34, 0x96, 0x85, 0x92, 0x79, 0xe1, 0x62, 0xcf,
+	0xf4, 0xdb, 0xdd, 0x9d, 0x4e, 0x55, 0xa2, 0xe5, 0x4e, 0x78, 0xaf, 0x05, 0xa9, 0xca, 0xaf, 0xf6,
+	0xc9, 0xf5, 0x9f, 0xc7, 0x48, 0x34, 0x89, 0xdd, 0x43, 0xd8, 0x5c, 0xb5, 0x30, 0x0a, 0xe6, 0x99,
+	0xbc, 0x72, 0xb1, 0x87, 0xfd, 0x96, 0xd0, 0x90, 0xf9, 0x60, 0x7d, 0x8d, 0xce, 0xbf, 0x48, 0xd7,
+	0xf0, 0xb0, 0xdf, 0xee, 0xb2, 0xba, 0x3e, 0x4c, 0x0b, 0x15, 0xa5, 0xa7, 0x52, 0xcf, 0x88, 0xda,
+	0xf0, 0xda, 0x78, 0x85, 0x07, 0xc4, 0x31, 0xa8, 0xb9, 0xf7, 0xd3, 0x80, 0xf5, 0x65, 0x07, 0x63,
+	0x40, 0xa2, 0xc9, 0x24, 0xbf, 0xeb, 0xad, 0x30, 0x7b, 0x04, 0x2d, 0x95, 0x5c, 0xc8, 0x42, 0x45,
+	0x17, 0x97, 0x55, 0xb9, 0x29, 0x9a, 0x07, 0xf6, 0x04, 0xac, 0x42, 0x45, 0x4a, 0xba, 0xa6, 0x87,
+	0xfd, 0xcd, 0xee, 0xd6, 0xea, 0xec, 0x47, 0x2d, 0x89, 0xda, 0xc1, 0x1e, 0x80, 0xad, 0xb2, 0x33,
+	0x99, 0x16, 0xae, 0xed, 0x99, 0xfe, 0x86, 0xb8, 0x63, 0x7a, 0xf4, 0x5b, 0x96, 0x4a, 0x77, 0xad,
+	0x1e, 0xd5, 0x98, 0x3d, 0x87, 0xed, 0x5c, 0xc6, 0x89, 0xbe, 0x58, 0x4e, 0x8e, 0x9b, 0x7d, 0xa7,
+	0xda, 0xdf, 0x6a, 0xb4, 0xf1, 0xe2, 0x4b, 0xde, 0xc2, 0xc3, 0xba, 0xf0, 0x38, 0x97, 0x27, 0xd1,
+	0xb9, 0x9e, 0x5f, 0x4e, 0xb6, 0xaa, 0xe4, 0x4e, 0x6d, 0x11, 0x0b, 0xc7, 0x22, 0x3f, 0x20, 0x0e,
+	0xa1, 0xd6, 0x80, 0x38, 0x16, 0xb5, 0x9f, 0x7e, 0x86, 0x8d, 0x95, 0x13, 0x18, 0x80, 0xdd, 0x7b,
+	0x37, 0x0e, 0x8f, 0x02, 0x8a, 0x58, 0x1b, 0xd6, 0x86, 0x41, 0xef, 0x28, 0x1c, 0x7d, 0xa0, 0x58,
+	0x93, 0xc3, 0x60, 0xd4, 0xd7, 0xc4, 0xd0, 0x64, 0x70, 0x10, 0x8e, 0x34, 0x31, 0x99, 0x03, 0x64,
+	0x18, 0xbc, 0x1f, 0x53, 0xc2, 0xd6, 0xc1, 0x11, 0x41, 0xaf, 0x7f, 0x30, 0x1a, 0x7e, 0xa2, 0xd6,
+	0xfe, 0xcb, 0xe9, 0x8c, 0xa3, 0x9b, 0x19, 0x47, 0xb7, 0x33, 0x8e, 0xbf, 0x97, 0x1c, 0xff, 0x2e,
+	0x39, 0xbe, 0x2e, 0x39, 0x9e, 0x96, 0x1c, 0xff, 0x2d, 0x39, 0xfe, 0x57, 0x72, 0x74, 0x5b, 0x72,
+	0xfc, 0x63, 0xce, 0xd1, 0x74, 0xce, 0xd1, 0xcd, 0x9c, 0xa3, 0x13, 0xbb, 0xfa, 0xa3, 0x5e, 0xfc,
+	0x1f, 0x00, 0x76, 0x8e, 0x4a, 0x03, 0x7b, 0x02, 0x00, 0x00,
 }
 
 func (x InstanceState) String() string {
@@ -323,6 +338,9 @@ func (this *InstanceDesc) Equal(that interface{}) bool {
 	if this.RegisteredTimestamp != that1.RegisteredTimestamp {
 		return false
 	}
+	if this.TokensRebalancedTimestamp != that1.TokensRebalancedTimestamp {
+		return false
+	}
 	return true
 }
 func (this *Desc) GoString() string {
@@ -351,7 +369,7 @@ func (this *InstanceDesc) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 10)
+	s := make([]string, 0, 11)
 	s = append(s, "&ring.InstanceDesc{")
 	s = append(s, "Addr: "+fmt.Sprintf("%#v", this.Addr)+",\n")
 	s = append(s, "Timestamp: "+fmt.Sprintf("%#v", this.Timestamp)+",\n")
@@ -359,6 +377,7 @@ func (this *InstanceDesc) GoString() string {
 	s = append(s, "Tokens: "+fmt.Sprintf("%#v", this.Tokens)+",\n")
 	s = append(s, "Zone: "+fmt.Sprintf("%#v", this.Zone)+",\n")
 	s = append(s, "RegisteredTimestamp: "+fmt.Sprintf("%#v", this.RegisteredTimestamp)+",\n")
+	s = append(s, "TokensRebalancedTimestamp: "+fmt.Sprintf("%#v", this.TokensRebalancedTimestamp)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -437,6 +456,11 @@ func (m *InstanceDesc) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.TokensRebalancedTimestamp != 0 {
+		i = encodeVarintRing(dAtA, i, uint64(m.TokensRebalancedTimestamp))
+		i--
+		dAtA[i] = 0x48
+	}
 	if m.RegisteredTimestamp != 0 {
 		i = encodeVarintRing(dAtA, i, uint64(m.RegisteredTimestamp))
 		i--
@@ -546,6 +570,9 @@ func (m *InstanceDesc) Size() (n int) {
 	if m.RegisteredTimestamp != 0 {
 		n += 1 + sovRing(uint64(m.RegisteredTimestamp))
 	}
+	if m.TokensRebalancedTimestamp != 0 {
+		n += 1 + sovRing(uint64(m.TokensRebalancedTimestamp))
+	}
 	return n
 }
 
@@ -586,6 +613,7 @@ func (this *InstanceDesc) String() string {
 		`Tokens:` + fmt.Sprintf("%v", this.Tokens) + `,`,
 		`Zone:` + fmt.Sprintf("%v", this.Zone) + `,`,
 		`RegisteredTimestamp:` + fmt.Sprintf("%v", this.RegisteredTimestamp) + `,`,
+		`TokensRebalancedTimestamp:` + fmt.Sprintf("%v", this.TokensRebalancedTimestamp) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1006,6 +1034,25 @@ func (m *InstanceDesc) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TokensRebalancedTimestamp", wireType)
+			}
+			m.TokensRebalancedTimestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRing
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TokensRebalancedTimestamp |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRing(dAtA[iNdEx:])
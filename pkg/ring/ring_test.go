@@ -933,6 +933,40 @@ func TestRing_Get_ExtendedReplicationSet(t *testing.T) {
 	}
 }
 
+func TestRing_GetWithReplicationFactor(t *testing.T) {
+	now := time.Now()
+	ringDesc := &Desc{Ingesters: map[string]InstanceDesc{
+		"instance-1": {Addr: "127.0.0.1", State: ACTIVE, Timestamp: now.Unix(), Tokens: []uint32{100}},
+		"instance-2": {Addr: "127.0.0.2", State: ACTIVE, Timestamp: now.Unix(), Tokens: []uint32{200}},
+		"instance-3": {Addr: "127.0.0.3", State: ACTIVE, Timestamp: now.Unix(), Tokens: []uint32{300}},
+	}}
+
+	ring := Ring{
+		cfg:                 Config{HeartbeatTimeout: time.Minute, ReplicationFactor: 1},
+		ringDesc:            ringDesc,
+		ringTokens:          ringDesc.GetTokens(),
+		ringTokensByZone:    ringDesc.getTokensByZone(),
+		ringInstanceByToken: ringDesc.getTokensInfo(),
+		ringZones:           getZones(ringDesc.getTokensByZone()),
+		strategy:            NewDefaultReplicationStrategy(),
+		KVClient:            &MockClient{},
+	}
+
+	set, err := ring.Get(150, Read, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.2"}, set.GetAddresses())
+
+	set, err = ring.GetWithReplicationFactor(150, Read, 2, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.2", "127.0.0.3"}, set.GetAddresses())
+	assert.Equal(t, 0, set.MaxErrors)
+
+	set, err = ring.GetWithReplicationFactor(150, Read, 3, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.2", "127.0.0.3", "127.0.0.1"}, set.GetAddresses())
+	assert.Equal(t, 1, set.MaxErrors)
+}
+
 func TestRing_GetAllHealthy(t *testing.T) {
 	const heartbeatTimeout = time.Minute
 	now := time.Now()
@@ -2305,6 +2339,11 @@ func TestRing_ShuffleShardWithLookback(t *testing.T) {
 		expected     []string
 	}
 
+	withTokensRebalancedAt := func(desc InstanceDesc, rebalancedAt time.Time) InstanceDesc {
+		desc.TokensRebalancedTimestamp = rebalancedAt.Unix()
+		return desc
+	}
+
 	tests := map[string]struct {
 		timeline []event
 	}{
@@ -2392,6 +2431,25 @@ func TestRing_ShuffleShardWithLookback(t *testing.T) {
 				{what: test, shardSize: 2, expected: []string{"instance-1", "instance-2" /* lookback: */, "instance-3" /* side effect:*/, "instance-4"}},
 			},
 		},
+		"single zone, shard size = 1, instance tokens recently rebalanced": {
+			timeline: []event{
+				{what: add, instanceID: "instance-1", instanceDesc: generateRingInstanceWithInfo("instance-1", "zone-a", []uint32{userToken(userID, "zone-a", 0) + 1}, now.Add(-2*lookbackPeriod))},
+				{what: add, instanceID: "instance-2", instanceDesc: generateRingInstanceWithInfo("instance-2", "zone-a", []uint32{userToken(userID, "zone-a", 0) + 2}, now.Add(-2*lookbackPeriod))},
+				{what: add, instanceID: "instance-3", instanceDesc: generateRingInstanceWithInfo("instance-3", "zone-a", []uint32{userToken(userID, "zone-a", 0) + 3}, now.Add(-2*lookbackPeriod))},
+				{what: test, shardSize: 1, expected: []string{"instance-1"}},
+				// Rebalance instance-1 tokens, so that the series of the tenant are now written to instance-2.
+				{what: add, instanceID: "instance-1", instanceDesc: withTokensRebalancedAt(generateRingInstanceWithInfo("instance-1", "zone-a", []uint32{userToken(userID, "zone-a", 0) + 4}, now.Add(-2*lookbackPeriod)), now)},
+				{what: test, shardSize: 1, expected: []string{"instance-2" /* lookback: */, "instance-1"}},
+			},
+		},
+		"single zone, shard size = 1, instance tokens rebalanced before the lookback period": {
+			timeline: []event{
+				{what: add, instanceID: "instance-1", instanceDesc: withTokensRebalancedAt(generateRingInstanceWithInfo("instance-1", "zone-a", []uint32{userToken(userID, "zone-a", 0) + 4}, now.Add(-3*lookbackPeriod)), now.Add(-2*lookbackPeriod))},
+				{what: add, instanceID: "instance-2", instanceDesc: generateRingInstanceWithInfo("instance-2", "zone-a", []uint32{userToken(userID, "zone-a", 0) + 2}, now.Add(-3*lookbackPeriod))},
+				{what: add, instanceID: "instance-3", instanceDesc: generateRingInstanceWithInfo("instance-3", "zone-a", []uint32{userToken(userID, "zone-a", 0) + 3}, now.Add(-3*lookbackPeriod))},
+				{what: test, shardSize: 1, expected: []string{"instance-2"}},
+			},
+		},
 		"single zone, increase shard size": {
 			timeline: []event{
 				{what: add, instanceID: "instance-1", instanceDesc: generateRingInstanceWithInfo("instance-1", "zone-a", []uint32{userToken(userID, "zone-a", 0) + 1}, now.Add(-2*lookbackPeriod))},
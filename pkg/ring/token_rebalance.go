@@ -0,0 +1,204 @@
+package ring
+
+import (
+	"flag"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	errInvalidTokensRebalanceMaxTokensPerStep = errors.New("the tokens rebalance max tokens per step must be greater than 0 when tokens rebalancing is enabled")
+	errInvalidTokensRebalanceTolerance        = errors.New("the tokens rebalance tolerance must be between 0 and 1")
+)
+
+// TokensRebalanceConfig configures the periodic rebalancing of an instance tokens.
+type TokensRebalanceConfig struct {
+	Period           time.Duration `yaml:"period"`
+	MaxTokensPerStep int           `yaml:"max_tokens_per_step"`
+	Tolerance        float64       `yaml:"tolerance"`
+}
+
+// RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet.
+func (cfg *TokensRebalanceConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.DurationVar(&cfg.Period, prefix+"tokens-rebalance.period", 0, "EXPERIMENTAL: Period at which the instance moves some of its tokens to get closer to an even ownership within its zone. Each step is skipped if any instance in the zone is not ACTIVE or has moved its tokens in the last period. 0 to disable.")
+	f.IntVar(&cfg.MaxTokensPerStep, prefix+"tokens-rebalance.max-tokens-per-step", 4, "EXPERIMENTAL: Maximum number of tokens moved at each rebalancing step.")
+	f.Float64Var(&cfg.Tolerance, prefix+"tokens-rebalance.tolerance", 0.05, "EXPERIMENTAL: Tokens are not rebalanced while the instance ownership differs from the even ownership of its zone by less than this ratio.")
+}
+
+func (cfg *TokensRebalanceConfig) Validate() error {
+	if cfg.Period <= 0 {
+		return nil
+	}
+	if cfg.MaxTokensPerStep <= 0 {
+		return errInvalidTokensRebalanceMaxTokensPerStep
+	}
+	if cfg.Tolerance < 0 || cfg.Tolerance >= 1 {
+		return errInvalidTokensRebalanceTolerance
+	}
+	return nil
+}
+
+type zonalToken struct {
+	token    uint32
+	instance string
+}
+
+// RebalanceTokens computes a new set of tokens for the instance with the given ID which gets its
+// ownership closer to an even spread among the instances of its zone. At most cfg.MaxTokensPerStep
+// tokens are moved, and only the tokens of the given instance are changed. It returns the new sorted
+// tokens and the number of tokens moved, which is 0 if no rebalancing is needed or possible.
+func RebalanceTokens(d *Desc, id string, cfg TokensRebalanceConfig, now time.Time) (Tokens, int) {
+	instance, ok := d.Ingesters[id]
+	if !ok || instance.State != ACTIVE || len(instance.Tokens) == 0 {
+		return nil, 0
+	}
+
+	// Only one instance per zone is allowed to move its tokens in a period, and only while
+	// the zone is stable, in order to not chase the moves of the other instances.
+	var tokens []zonalToken
+	numInstances := 0
+	for otherID, other := range d.Ingesters {
+		if other.Zone != instance.Zone {
+			continue
+		}
+		if other.State != ACTIVE {
+			return nil, 0
+		}
+		if otherID != id && cfg.Period > 0 && other.TokensRebalancedTimestamp > now.Add(-cfg.Period).Unix() {
+			return nil, 0
+		}
+		numInstances++
+		for _, t := range other.Tokens {
+			tokens = append(tokens, zonalToken{token: t, instance: otherID})
+		}
+	}
+	if numInstances < 2 {
+		return nil, 0
+	}
+
+	used := make(map[uint32]struct{}, len(tokens))
+	for _, t := range d.GetTokens() {
+		used[t] = struct{}{}
+	}
+
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].token < tokens[j].token })
+
+	expected := float64(maxTokenValue+1) / float64(numInstances)
+	deviation := func(owned float64) float64 {
+		return (owned - expected) * (owned - expected)
+	}
+
+	moved := 0
+	for moved < cfg.MaxTokensPerStep {
+		owned := zonalOwnership(tokens)
+		if math.Abs(owned[id]-expected)/expected <= cfg.Tolerance {
+			break
+		}
+
+		// Pick the token whose removal gets the ring closer to the even ownership. The range
+		// owned by the removed token is taken by the instance owning the next token.
+		removeIdx, removeDelta := -1, math.Inf(1)
+		for i, t := range tokens {
+			if t.instance != id {
+				continue
+			}
+			delta := float64(0)
+			if next := tokens[(i+1)%len(tokens)].instance; next != id {
+				r := float64(tokenDistance(tokens[(i-1+len(tokens))%len(tokens)].token, t.token))
+				delta = deviation(owned[id]-r) + deviation(owned[next]+r) - deviation(owned[id]) - deviation(owned[next])
+			}
+			if delta < removeDelta {
+				removeIdx, removeDelta = i, delta
+			}
+		}
+		if removeIdx < 0 {
+			break
+		}
+
+		removed := tokens[removeIdx]
+		candidate := append(append(make([]zonalToken, 0, len(tokens)), tokens[:removeIdx]...), tokens[removeIdx+1:]...)
+		owned = zonalOwnership(candidate)
+
+		// Pick the range of another instance where to place the new token. The optimal amount
+		// of ownership to take from an instance is half the difference between the two ownerships.
+		var (
+			addPrev   uint32
+			addDist   int64
+			addDelta  = math.Inf(1)
+			addExists bool
+		)
+		for i, t := range candidate {
+			if t.instance == id {
+				continue
+			}
+			prev := candidate[(i-1+len(candidate))%len(candidate)].token
+			rangeSize := tokenDistance(prev, t.token)
+			if rangeSize < 2 {
+				continue
+			}
+			dist := int64((owned[t.instance] - owned[id]) / 2)
+			dist = max(1, min(dist, rangeSize-1))
+			delta := deviation(owned[id]+float64(dist)) + deviation(owned[t.instance]-float64(dist)) - deviation(owned[id]) - deviation(owned[t.instance])
+			if delta < addDelta {
+				addPrev, addDist, addDelta, addExists = prev, dist, delta, true
+			}
+		}
+
+		if !addExists || removeDelta+addDelta >= 0 {
+			break
+		}
+
+		newToken, ok := freeToken(addPrev, addDist, used)
+		if !ok {
+			break
+		}
+
+		delete(used, removed.token)
+		used[newToken] = struct{}{}
+
+		idx := sort.Search(len(candidate), func(i int) bool { return candidate[i].token >= newToken })
+		candidate = append(candidate, zonalToken{})
+		copy(candidate[idx+1:], candidate[idx:])
+		candidate[idx] = zonalToken{token: newToken, instance: id}
+		tokens = candidate
+		moved++
+	}
+
+	if moved == 0 {
+		return nil, 0
+	}
+
+	result := make(Tokens, 0, len(instance.Tokens))
+	for _, t := range tokens {
+		if t.instance == id {
+			result = append(result, t.token)
+		}
+	}
+	return result, moved
+}
+
+// freeToken returns the token at the given distance after prev, going backward towards prev
+// if it's already used.
+func freeToken(prev uint32, dist int64, used map[uint32]struct{}) (uint32, bool) {
+	for ; dist > 0; dist-- {
+		token := uint32((int64(prev) + dist) % (maxTokenValue + 1))
+		if _, taken := used[token]; !taken {
+			return token, true
+		}
+	}
+	return 0, false
+}
+
+// zonalOwnership returns the range of the ring owned by each instance, given the sorted list
+// of tokens of a zone.
+func zonalOwnership(tokens []zonalToken) map[string]float64 {
+	owned := map[string]float64{}
+	for i, t := range tokens {
+		prev := tokens[(i-1+len(tokens))%len(tokens)].token
+		owned[t.instance] += float64(tokenDistance(prev, t.token))
+	}
+	return owned
+}
@@ -0,0 +1,188 @@
+package ring
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokensRebalanceConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		cfg      TokensRebalanceConfig
+		expected error
+	}{
+		"disabled": {
+			cfg: TokensRebalanceConfig{Period: 0, MaxTokensPerStep: 0},
+		},
+		"valid": {
+			cfg: TokensRebalanceConfig{Period: time.Minute, MaxTokensPerStep: 4, Tolerance: 0.05},
+		},
+		"invalid max tokens per step": {
+			cfg:      TokensRebalanceConfig{Period: time.Minute, MaxTokensPerStep: 0},
+			expected: errInvalidTokensRebalanceMaxTokensPerStep,
+		},
+		"invalid tolerance": {
+			cfg:      TokensRebalanceConfig{Period: time.Minute, MaxTokensPerStep: 4, Tolerance: 1},
+			expected: errInvalidTokensRebalanceTolerance,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expected, testData.cfg.Validate())
+		})
+	}
+}
+
+func TestRebalanceTokens(t *testing.T) {
+	t.Parallel()
+
+	const quarter = uint32(1 << 30)
+
+	now := time.Now()
+	cfg := TokensRebalanceConfig{Period: time.Minute, MaxTokensPerStep: 2, Tolerance: 0.05}
+
+	unbalanced := func() *Desc {
+		d := NewDesc()
+		d.AddIngester("instance-1", "", "zone-a", []uint32{quarter, 2 * quarter, 3*quarter - 1}, ACTIVE, now)
+		d.AddIngester("instance-2", "", "zone-a", []uint32{3 * quarter, maxTokenValue - 1, maxTokenValue}, ACTIVE, now)
+		d.AddIngester("instance-3", "", "zone-b", []uint32{10, 20, 30}, ACTIVE, now)
+		return d
+	}
+
+	t.Run("should move at most the max number of tokens per step", func(t *testing.T) {
+		d := unbalanced()
+		before := d.GetOwnership(true)
+
+		tokens, moved := RebalanceTokens(d, "instance-1", TokensRebalanceConfig{Period: time.Minute, MaxTokensPerStep: 1}, now)
+		require.Equal(t, 1, moved)
+		require.Len(t, tokens, 3)
+		assert.True(t, sort.IsSorted(tokens))
+
+		d.AddIngester("instance-1", "", "zone-a", tokens, ACTIVE, now)
+		after := d.GetOwnership(true)
+
+		beforeOwnership, _ := before.Instance("instance-1")
+		afterOwnership, _ := after.Instance("instance-1")
+		assert.Less(t, math.Abs(afterOwnership.Ownership-50), math.Abs(beforeOwnership.Ownership-50))
+	})
+
+	t.Run("should converge to an even ownership within the tolerance", func(t *testing.T) {
+		d := unbalanced()
+
+		for i := 0; i < 10; i++ {
+			tokens, moved := RebalanceTokens(d, "instance-1", cfg, now)
+			if moved == 0 {
+				break
+			}
+			d.AddIngester("instance-1", "", "zone-a", tokens, ACTIVE, now)
+		}
+
+		ownership, _ := d.GetOwnership(true).Instance("instance-1")
+		assert.InDelta(t, 50, ownership.Ownership, 50*cfg.Tolerance)
+
+		// The tokens of the other instances must not change.
+		assert.Equal(t, []uint32{3 * quarter, maxTokenValue - 1, maxTokenValue}, d.Ingesters["instance-2"].Tokens)
+		assert.Equal(t, []uint32{10, 20, 30}, d.Ingesters["instance-3"].Tokens)
+	})
+
+	t.Run("should not move tokens if the ownership is already within the tolerance", func(t *testing.T) {
+		d := NewDesc()
+		d.AddIngester("instance-1", "", "zone-a", []uint32{quarter, 3 * quarter}, ACTIVE, now)
+		d.AddIngester("instance-2", "", "zone-a", []uint32{2 * quarter, maxTokenValue}, ACTIVE, now)
+
+		_, moved := RebalanceTokens(d, "instance-1", cfg, now)
+		assert.Zero(t, moved)
+	})
+
+	t.Run("should not move tokens if an instance in the zone is not ACTIVE", func(t *testing.T) {
+		d := unbalanced()
+		d.AddIngester("instance-4", "", "zone-a", nil, JOINING, now)
+
+		_, moved := RebalanceTokens(d, "instance-1", cfg, now)
+		assert.Zero(t, moved)
+	})
+
+	t.Run("should ignore instances not ACTIVE in other zones", func(t *testing.T) {
+		d := unbalanced()
+		d.AddIngester("instance-4", "", "zone-b", nil, LEAVING, now)
+
+		_, moved := RebalanceTokens(d, "instance-1", cfg, now)
+		assert.Positive(t, moved)
+	})
+
+	t.Run("should not move tokens if another instance in the zone has been rebalanced within the period", func(t *testing.T) {
+		d := unbalanced()
+		instance := d.Ingesters["instance-2"]
+		instance.TokensRebalancedTimestamp = now.Add(-cfg.Period / 2).Unix()
+		d.Ingesters["instance-2"] = instance
+
+		_, moved := RebalanceTokens(d, "instance-1", cfg, now)
+		assert.Zero(t, moved)
+
+		_, moved = RebalanceTokens(d, "instance-1", cfg, now.Add(cfg.Period))
+		assert.Positive(t, moved)
+	})
+
+	t.Run("should not move tokens of an instance alone in its zone", func(t *testing.T) {
+		d := unbalanced()
+
+		_, moved := RebalanceTokens(d, "instance-3", cfg, now)
+		assert.Zero(t, moved)
+	})
+}
+
+func TestRebalanceTokens_ShouldReduceTheSpreadOfRandomTokens(t *testing.T) {
+	t.Parallel()
+
+	const (
+		numInstances = 10
+		numTokens    = 64
+	)
+
+	now := time.Now()
+	cfg := TokensRebalanceConfig{MaxTokensPerStep: 4, Tolerance: 0.02}
+
+	d := NewDesc()
+	g := NewRandomTokenGenerator()
+	for i := 0; i < numInstances; i++ {
+		id := fmt.Sprintf("instance-%d", i)
+		d.AddIngester(id, "", "zone-a", g.GenerateTokens(d, id, "zone-a", numTokens, true), ACTIVE, now)
+	}
+
+	spread := func() float64 {
+		minOwnership, maxOwnership := math.MaxFloat64, float64(0)
+		for _, instance := range d.GetOwnership(true).Instances {
+			minOwnership = math.Min(minOwnership, instance.Ownership)
+			maxOwnership = math.Max(maxOwnership, instance.Ownership)
+		}
+		return maxOwnership - minOwnership
+	}
+
+	initialSpread := spread()
+	r := rand.New(rand.NewSource(now.UnixNano()))
+	for step := 0; step < 50*numInstances; step++ {
+		id := fmt.Sprintf("instance-%d", r.Intn(numInstances))
+		tokens, moved := RebalanceTokens(d, id, cfg, now)
+		if moved == 0 {
+			continue
+		}
+
+		require.LessOrEqual(t, moved, cfg.MaxTokensPerStep)
+		require.Len(t, tokens, numTokens)
+		d.AddIngester(id, "", "zone-a", tokens, ACTIVE, now)
+	}
+
+	// The tokens must still be unique in the ring.
+	assert.Len(t, d.GetTokens(), numInstances*numTokens)
+	assert.Less(t, spread(), initialSpread)
+	assert.Less(t, spread(), 1.0)
+}
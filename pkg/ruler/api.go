@@ -85,6 +85,18 @@ type alertingRule struct {
 	Type           v1.RuleType   `json:"type"`
 	LastEvaluation time.Time     `json:"lastEvaluation"`
 	EvaluationTime float64       `json:"evaluationTime"`
+	// Only returned when the evaluation_history parameter is true.
+	EvaluationHistory []ruleEvaluation `json:"evaluationHistory,omitempty"`
+}
+
+// ruleEvaluation is the outcome of a past evaluation of a rule.
+type ruleEvaluation struct {
+	Timestamp        time.Time `json:"timestamp"`
+	EvaluationTime   float64   `json:"evaluationTime"`
+	Samples          int64     `json:"samples"`
+	Health           string    `json:"health"`
+	LastError        string    `json:"lastError"`
+	MissedIterations int64     `json:"missedIterations"`
 }
 
 type recordingRule struct {
@@ -96,6 +108,8 @@ type recordingRule struct {
 	Type           v1.RuleType   `json:"type"`
 	LastEvaluation time.Time     `json:"lastEvaluation"`
 	EvaluationTime float64       `json:"evaluationTime"`
+	// Only returned when the evaluation_history parameter is true.
+	EvaluationHistory []ruleEvaluation `json:"evaluationHistory,omitempty"`
 }
 
 type listRulesPaginationRequest struct {
@@ -108,15 +122,20 @@ type API struct {
 	ruler *Ruler
 	store rulestore.RuleStore
 
+	// sloQueryFunc is used to query the error budget remaining of the SLOs.
+	sloQueryFunc SLOQueryFunc
+
 	logger log.Logger
 }
 
-// NewAPI returns a new API struct with the provided ruler and rule store
-func NewAPI(r *Ruler, s rulestore.RuleStore, logger log.Logger) *API {
+// NewAPI returns a new API struct with the provided ruler, rule store and query function
+// used to report the error budget remaining of the SLOs.
+func NewAPI(r *Ruler, s rulestore.RuleStore, sloQueryFunc SLOQueryFunc, logger log.Logger) *API {
 	return &API{
-		ruler:  r,
-		store:  s,
-		logger: logger,
+		ruler:        r,
+		store:        s,
+		sloQueryFunc: sloQueryFunc,
+		logger:       logger,
 	}
 }
 
@@ -166,6 +185,12 @@ func (a *API) PrometheusRules(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	evaluationHistory, err := parseEvaluationHistory(req)
+	if err != nil {
+		util_api.RespondError(logger, w, v1.ErrBadData, fmt.Sprintf("invalid parameter %q: %s", "evaluation_history", err.Error()), http.StatusBadRequest)
+		return
+	}
+
 	paginationRequest, err := parseListRulesPaginationRequest(req)
 	if err != nil {
 		util_api.RespondError(logger, w, v1.ErrBadData, err.Error(), http.StatusBadRequest)
@@ -183,6 +208,8 @@ func (a *API) PrometheusRules(w http.ResponseWriter, req *http.Request) {
 		ExcludeAlerts:  excludeAlerts,
 		MaxRuleGroups:  paginationRequest.MaxRuleGroups,
 		NextToken:      paginationRequest.NextToken,
+
+		IncludeEvaluationHistory: evaluationHistory,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -227,30 +254,32 @@ func (a *API) PrometheusRules(w http.ResponseWriter, req *http.Request) {
 					alerts = append(alerts, alert)
 				}
 				grp.Rules[i] = alertingRule{
-					State:          rl.GetState(),
-					Name:           rl.Rule.GetAlert(),
-					Query:          rl.Rule.GetExpr(),
-					Duration:       rl.Rule.For.Seconds(),
-					Labels:         cortexpb.FromLabelAdaptersToLabels(rl.Rule.Labels),
-					Annotations:    cortexpb.FromLabelAdaptersToLabels(rl.Rule.Annotations),
-					Alerts:         alerts,
-					Health:         rl.GetHealth(),
-					LastError:      rl.GetLastError(),
-					LastEvaluation: rl.GetEvaluationTimestamp(),
-					EvaluationTime: rl.GetEvaluationDuration().Seconds(),
-					Type:           v1.RuleTypeAlerting,
-					KeepFiringFor:  rl.Rule.KeepFiringFor.Seconds(),
+					State:             rl.GetState(),
+					Name:              rl.Rule.GetAlert(),
+					Query:             rl.Rule.GetExpr(),
+					Duration:          rl.Rule.For.Seconds(),
+					Labels:            cortexpb.FromLabelAdaptersToLabels(rl.Rule.Labels),
+					Annotations:       cortexpb.FromLabelAdaptersToLabels(rl.Rule.Annotations),
+					Alerts:            alerts,
+					Health:            rl.GetHealth(),
+					LastError:         rl.GetLastError(),
+					LastEvaluation:    rl.GetEvaluationTimestamp(),
+					EvaluationTime:    rl.GetEvaluationDuration().Seconds(),
+					Type:              v1.RuleTypeAlerting,
+					KeepFiringFor:     rl.Rule.KeepFiringFor.Seconds(),
+					EvaluationHistory: toRuleEvaluations(rl.EvaluationHistory),
 				}
 			} else {
 				grp.Rules[i] = recordingRule{
-					Name:           rl.Rule.GetRecord(),
-					Query:          rl.Rule.GetExpr(),
-					Labels:         cortexpb.FromLabelAdaptersToLabels(rl.Rule.Labels),
-					Health:         rl.GetHealth(),
-					LastError:      rl.GetLastError(),
-					LastEvaluation: rl.GetEvaluationTimestamp(),
-					EvaluationTime: rl.GetEvaluationDuration().Seconds(),
-					Type:           v1.RuleTypeRecording,
+					Name:              rl.Rule.GetRecord(),
+					Query:             rl.Rule.GetExpr(),
+					Labels:            cortexpb.FromLabelAdaptersToLabels(rl.Rule.Labels),
+					Health:            rl.GetHealth(),
+					LastError:         rl.GetLastError(),
+					LastEvaluation:    rl.GetEvaluationTimestamp(),
+					EvaluationTime:    rl.GetEvaluationDuration().Seconds(),
+					Type:              v1.RuleTypeRecording,
+					EvaluationHistory: toRuleEvaluations(rl.EvaluationHistory),
 				}
 			}
 		}
@@ -280,6 +309,25 @@ func (a *API) PrometheusRules(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+func toRuleEvaluations(history []RuleEvaluationDesc) []ruleEvaluation {
+	if len(history) == 0 {
+		return nil
+	}
+
+	evaluations := make([]ruleEvaluation, 0, len(history))
+	for _, e := range history {
+		evaluations = append(evaluations, ruleEvaluation{
+			Timestamp:        e.Timestamp,
+			EvaluationTime:   e.Duration.Seconds(),
+			Samples:          e.Samples,
+			Health:           e.Health,
+			LastError:        e.LastError,
+			MissedIterations: e.MissedIterations,
+		})
+	}
+	return evaluations
+}
+
 func parseListRulesPaginationRequest(req *http.Request) (listRulesPaginationRequest, error) {
 	var (
 		returnMaxRuleGroups = int32(-1)
@@ -332,6 +380,20 @@ func parseExcludeAlerts(r *http.Request) (bool, error) {
 	return excludeAlerts, nil
 }
 
+func parseEvaluationHistory(r *http.Request) (bool, error) {
+	evaluationHistoryParam := strings.ToLower(r.URL.Query().Get("evaluation_history"))
+
+	if evaluationHistoryParam == "" {
+		return false, nil
+	}
+
+	evaluationHistory, err := strconv.ParseBool(evaluationHistoryParam)
+	if err != nil {
+		return false, fmt.Errorf("error converting evaluation_history: %w", err)
+	}
+	return evaluationHistory, nil
+}
+
 func (a *API) PrometheusAlerts(w http.ResponseWriter, req *http.Request) {
 	logger := util_log.WithContext(req.Context(), a.logger)
 	userID, err := users.TenantID(req.Context())
@@ -561,6 +623,11 @@ func (a *API) CreateRuleGroup(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if namespace == SLONamespace {
+		http.Error(w, ErrManagedNamespace.Error(), http.StatusBadRequest)
+		return
+	}
+
 	payload, err := io.ReadAll(req.Body)
 	if err != nil {
 		level.Error(logger).Log("msg", "unable to read rule group payload", "err", err.Error())
@@ -643,6 +710,11 @@ func (a *API) DeleteNamespace(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if namespace == SLONamespace {
+		http.Error(w, ErrManagedNamespace.Error(), http.StatusBadRequest)
+		return
+	}
+
 	err = a.store.DeleteNamespace(req.Context(), userID, namespace)
 	if err != nil {
 		if err == rulestore.ErrGroupNamespaceNotFound {
@@ -665,6 +737,11 @@ func (a *API) DeleteRuleGroup(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if namespace == SLONamespace {
+		http.Error(w, ErrManagedNamespace.Error(), http.StatusBadRequest)
+		return
+	}
+
 	err = a.store.DeleteRuleGroup(req.Context(), userID, namespace, groupName)
 	if err != nil {
 		if err == rulestore.ErrGroupNotFound {
@@ -677,3 +754,283 @@ func (a *API) DeleteRuleGroup(w http.ResponseWriter, req *http.Request) {
 
 	respondAccepted(w, logger)
 }
+
+// parseSLOName parses the SLO name from the provided set of params, in this
+// api these params are derived from the url path
+func parseSLOName(params map[string]string) (string, error) {
+	name, exists := params["name"]
+	if !exists {
+		return "", ErrNoSLOName
+	}
+
+	return url.PathUnescape(name)
+}
+
+// ListSLOs returns the SLOs of the tenant.
+func (a *API) ListSLOs(w http.ResponseWriter, req *http.Request) {
+	logger := util_log.WithContext(req.Context(), a.logger)
+
+	userID, err := users.TenantID(req.Context())
+	if err != nil {
+		util_api.RespondError(logger, w, v1.ErrBadData, user.ErrNoOrgID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rgs, err := a.store.ListRuleGroupsForUserAndNamespace(req.Context(), userID, SLONamespace)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(rgs) == 0 {
+		http.Error(w, ErrNoSLO.Error(), http.StatusNotFound)
+		return
+	}
+
+	_, err = a.store.LoadRuleGroups(req.Context(), map[string]rulespb.RuleGroupList{userID: rgs})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	slos := make([]SLO, 0, len(rgs))
+	for _, rg := range rgs {
+		s, err := sloFromProto(rg)
+		if err != nil {
+			level.Warn(logger).Log("msg", "skipping rule group without SLO in the SLO namespace", "user", userID, "group", rg.Name, "err", err)
+			continue
+		}
+		slos = append(slos, s)
+	}
+	sort.Slice(slos, func(i, j int) bool { return slos[i].Name < slos[j].Name })
+
+	marshalAndSend(slos, w, logger)
+}
+
+// GetSLO returns a SLO of the tenant.
+func (a *API) GetSLO(w http.ResponseWriter, req *http.Request) {
+	logger := util_log.WithContext(req.Context(), a.logger)
+
+	_, s, ok := a.getSLO(w, req, logger)
+	if !ok {
+		return
+	}
+
+	marshalAndSend(s, w, logger)
+}
+
+// CreateSLO creates or updates a SLO of the tenant, and regenerates its managed rule group.
+func (a *API) CreateSLO(w http.ResponseWriter, req *http.Request) {
+	logger := util_log.WithContext(req.Context(), a.logger)
+
+	userID, err := users.TenantID(req.Context())
+	if err != nil {
+		util_api.RespondError(logger, w, v1.ErrBadData, user.ErrNoOrgID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload, err := io.ReadAll(req.Body)
+	if err != nil {
+		level.Error(logger).Log("msg", "unable to read SLO payload", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s := SLO{}
+	if err := yaml.Unmarshal(payload, &s); err != nil {
+		level.Error(logger).Log("msg", "unable to unmarshal SLO payload", "err", err.Error())
+		http.Error(w, ErrBadSLO.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rgProto, err := sloToProto(userID, s)
+	if err != nil {
+		level.Error(logger).Log("msg", "unable to generate the SLO rule group", "err", err.Error(), "user", userID)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if errs := a.ruler.manager.ValidateRuleGroup(rulespb.FromProto(rgProto)); len(errs) > 0 {
+		e := []string{}
+		for _, err := range errs {
+			e = append(e, err.Error())
+		}
+
+		http.Error(w, strings.Join(e, ", "), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.ruler.AssertMaxRulesPerRuleGroup(userID, len(rgProto.Rules)); err != nil {
+		level.Error(logger).Log("msg", "limit validation failure", "err", err.Error(), "user", userID)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if a.ruler.HasMaxRuleGroupsLimit(userID) {
+		rgs, err := a.store.ListRuleGroupsForUserAndNamespace(req.Context(), userID, "")
+		if err != nil {
+			level.Error(logger).Log("msg", "unable to fetch current rule groups for validation", "err", err.Error(), "user", userID)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Updating a SLO doesn't add a rule group.
+		count := len(rgs) + 1
+		for _, rg := range rgs {
+			if rg.Namespace == SLONamespace && rg.Name == s.Name {
+				count--
+				break
+			}
+		}
+
+		if err := a.ruler.AssertMaxRuleGroups(userID, count); err != nil {
+			level.Error(logger).Log("msg", "limit validation failure", "err", err.Error(), "user", userID)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	level.Debug(logger).Log("msg", "attempting to store SLO rule group", "userID", userID, "slo", s.Name)
+	if err := a.store.SetRuleGroup(req.Context(), userID, SLONamespace, rgProto); err != nil {
+		level.Error(logger).Log("msg", "unable to store SLO rule group", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondAccepted(w, logger)
+}
+
+// DeleteSLO deletes a SLO of the tenant and its managed rule group.
+func (a *API) DeleteSLO(w http.ResponseWriter, req *http.Request) {
+	logger := util_log.WithContext(req.Context(), a.logger)
+
+	userID, err := users.TenantID(req.Context())
+	if err != nil {
+		util_api.RespondError(logger, w, v1.ErrBadData, user.ErrNoOrgID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	name, err := parseSLOName(mux.Vars(req))
+	if err != nil {
+		util_api.RespondError(logger, w, v1.ErrBadData, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = a.store.DeleteRuleGroup(req.Context(), userID, SLONamespace, name)
+	if err != nil {
+		if err == rulestore.ErrGroupNotFound {
+			http.Error(w, ErrNoSLO.Error(), http.StatusNotFound)
+			return
+		}
+		util_api.RespondError(logger, w, v1.ErrServer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondAccepted(w, logger)
+}
+
+// SLOErrorBudget is the error budget remaining of a SLO over its window, as a ratio
+// of the whole error budget. It's negative when the error budget is exhausted.
+type SLOErrorBudget struct {
+	Name      string                 `json:"name"`
+	Service   string                 `json:"service"`
+	Objective float64                `json:"objective"`
+	Window    string                 `json:"window"`
+	Series    []SLOErrorBudgetSeries `json:"series"`
+}
+
+// SLOErrorBudgetSeries is the error budget remaining of a series of the SLI.
+type SLOErrorBudgetSeries struct {
+	Labels    labels.Labels `json:"labels"`
+	Remaining string        `json:"remaining"`
+}
+
+// SLOErrorBudget returns the current error budget remaining of a SLO of the tenant, as
+// recorded by its managed rule group.
+func (a *API) SLOErrorBudget(w http.ResponseWriter, req *http.Request) {
+	logger := util_log.WithContext(req.Context(), a.logger)
+
+	userID, s, ok := a.getSLO(w, req, logger)
+	if !ok {
+		return
+	}
+
+	if a.sloQueryFunc == nil {
+		util_api.RespondError(logger, w, v1.ErrServer, "querying the SLOs error budget is not supported", http.StatusNotImplemented)
+		return
+	}
+
+	qs := fmt.Sprintf("%s{%s=%q}", sloErrorBudgetRemainingRecord, sloLabel, s.Name)
+	vector, err := a.sloQueryFunc(req.Context(), userID, qs, time.Now())
+	if err != nil {
+		util_api.RespondError(logger, w, v1.ErrExec, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	budget := SLOErrorBudget{
+		Name:      s.Name,
+		Service:   s.Service,
+		Objective: s.Objective,
+		Window:    s.Window.String(),
+		Series:    make([]SLOErrorBudgetSeries, 0, len(vector)),
+	}
+	for _, sample := range vector {
+		budget.Series = append(budget.Series, SLOErrorBudgetSeries{
+			Labels:    sample.Metric.DropMetricName(),
+			Remaining: strconv.FormatFloat(sample.F, 'f', -1, 64),
+		})
+	}
+
+	b, err := json.Marshal(&util_api.Response{
+		Status: "success",
+		Data:   budget,
+	})
+	if err != nil {
+		level.Error(logger).Log("msg", "error marshaling json response", "err", err)
+		util_api.RespondError(logger, w, v1.ErrServer, "unable to marshal the requested data", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if n, err := w.Write(b); err != nil {
+		level.Error(logger).Log("msg", "error writing response", "bytesWritten", n, "err", err)
+	}
+}
+
+// getSLO loads the SLO named in the request path. It writes the error response and
+// returns false if the SLO can't be loaded.
+func (a *API) getSLO(w http.ResponseWriter, req *http.Request, logger log.Logger) (string, SLO, bool) {
+	userID, err := users.TenantID(req.Context())
+	if err != nil {
+		util_api.RespondError(logger, w, v1.ErrBadData, user.ErrNoOrgID.Error(), http.StatusBadRequest)
+		return "", SLO{}, false
+	}
+
+	name, err := parseSLOName(mux.Vars(req))
+	if err != nil {
+		util_api.RespondError(logger, w, v1.ErrBadData, err.Error(), http.StatusBadRequest)
+		return "", SLO{}, false
+	}
+
+	rg, err := a.store.GetRuleGroup(req.Context(), userID, SLONamespace, name)
+	if err != nil {
+		if errors.Is(err, rulestore.ErrGroupNotFound) {
+			http.Error(w, ErrNoSLO.Error(), http.StatusNotFound)
+			return "", SLO{}, false
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return "", SLO{}, false
+	}
+
+	s, err := sloFromProto(rg)
+	if err != nil {
+		util_api.RespondError(logger, w, v1.ErrServer, err.Error(), http.StatusInternalServerError)
+		return "", SLO{}, false
+	}
+
+	return userID, s, true
+}
@@ -14,6 +14,8 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
 	"github.com/stretchr/testify/require"
 	"github.com/weaveworks/common/user"
 
@@ -254,7 +256,7 @@ func TestRuler_rules(t *testing.T) {
 	r := newTestRuler(t, cfg, store, nil)
 	defer services.StopAndAwaitTerminated(context.Background(), r) //nolint:errcheck
 
-	a := NewAPI(r, r.store, log.NewNopLogger())
+	a := NewAPI(r, r.store, nil, log.NewNopLogger())
 
 	req := requestFor(t, "GET", "https://localhost:8080/api/prom/api/v1/rules", nil, "user1")
 	w := httptest.NewRecorder()
@@ -313,7 +315,7 @@ func TestRuler_rules_special_characters(t *testing.T) {
 	r := newTestRuler(t, cfg, store, nil)
 	defer services.StopAndAwaitTerminated(context.Background(), r) //nolint:errcheck
 
-	a := NewAPI(r, r.store, log.NewNopLogger())
+	a := NewAPI(r, r.store, nil, log.NewNopLogger())
 
 	req := requestFor(t, http.MethodGet, "https://localhost:8080/api/prom/api/v1/rules", nil, "user1")
 	w := httptest.NewRecorder()
@@ -371,7 +373,7 @@ func TestRuler_rules_limit(t *testing.T) {
 	r := newTestRuler(t, cfg, store, nil)
 	defer services.StopAndAwaitTerminated(context.Background(), r) //nolint:errcheck
 
-	a := NewAPI(r, r.store, log.NewNopLogger())
+	a := NewAPI(r, r.store, nil, log.NewNopLogger())
 
 	req := requestFor(t, http.MethodGet, "https://localhost:8080/api/prom/api/v1/rules", nil, "user1")
 	w := httptest.NewRecorder()
@@ -429,7 +431,7 @@ func TestRuler_alerts(t *testing.T) {
 	r := newTestRuler(t, cfg, store, nil)
 	defer r.StopAsync()
 
-	a := NewAPI(r, r.store, log.NewNopLogger())
+	a := NewAPI(r, r.store, nil, log.NewNopLogger())
 
 	req := requestFor(t, http.MethodGet, "https://localhost:8080/api/prom/api/v1/alerts", nil, "user1")
 	w := httptest.NewRecorder()
@@ -464,7 +466,7 @@ func TestRuler_Create(t *testing.T) {
 	r := newTestRuler(t, cfg, store, nil)
 	defer services.StopAndAwaitTerminated(context.Background(), r) //nolint:errcheck
 
-	a := NewAPI(r, r.store, log.NewNopLogger())
+	a := NewAPI(r, r.store, nil, log.NewNopLogger())
 
 	tc := []struct {
 		name   string
@@ -567,7 +569,7 @@ func TestRuler_DeleteNamespace(t *testing.T) {
 	r := newTestRuler(t, cfg, store, nil)
 	defer services.StopAndAwaitTerminated(context.Background(), r) //nolint:errcheck
 
-	a := NewAPI(r, r.store, log.NewNopLogger())
+	a := NewAPI(r, r.store, nil, log.NewNopLogger())
 
 	router := mux.NewRouter()
 	router.Path("/api/v1/rules/{namespace}").Methods(http.MethodDelete).HandlerFunc(a.DeleteNamespace)
@@ -607,7 +609,7 @@ func TestRuler_LimitsPerGroup(t *testing.T) {
 
 	r.limits = &ruleLimits{maxRuleGroups: 1, maxRulesPerRuleGroup: 1}
 
-	a := NewAPI(r, r.store, log.NewNopLogger())
+	a := NewAPI(r, r.store, nil, log.NewNopLogger())
 
 	tc := []struct {
 		name   string
@@ -661,7 +663,7 @@ func TestRuler_RulerGroupLimits(t *testing.T) {
 
 	r.limits = &ruleLimits{maxRuleGroups: 1, maxRulesPerRuleGroup: 1}
 
-	a := NewAPI(r, r.store, log.NewNopLogger())
+	a := NewAPI(r, r.store, nil, log.NewNopLogger())
 
 	tc := []struct {
 		name   string
@@ -720,7 +722,7 @@ func TestRuler_ProtoToRuleGroupYamlConvertion(t *testing.T) {
 	r := newTestRuler(t, cfg, store, nil)
 	defer services.StopAndAwaitTerminated(context.Background(), r) //nolint:errcheck
 
-	a := NewAPI(r, r.store, log.NewNopLogger())
+	a := NewAPI(r, r.store, nil, log.NewNopLogger())
 
 	tc := []struct {
 		name   string
@@ -774,6 +776,102 @@ rules:
 	}
 }
 
+func TestRuler_SLOs(t *testing.T) {
+	store := newMockRuleStore(make(map[string]rulespb.RuleGroupList), nil)
+	cfg := defaultRulerConfig(t)
+
+	r := newTestRuler(t, cfg, store, nil)
+	defer services.StopAndAwaitTerminated(context.Background(), r) //nolint:errcheck
+
+	var query string
+	queryFunc := func(_ context.Context, userID, qs string, _ time.Time) (promql.Vector, error) {
+		require.Equal(t, "user1", userID)
+		query = qs
+		return promql.Vector{{Metric: labels.FromStrings(labels.MetricName, "slo:error_budget_remaining:ratio", "slo", "api-availability"), F: 0.25}}, nil
+	}
+	a := NewAPI(r, r.store, queryFunc, log.NewNopLogger())
+
+	router := mux.NewRouter()
+	router.Path("/api/v1/slos").Methods(http.MethodGet).HandlerFunc(a.ListSLOs)
+	router.Path("/api/v1/slos").Methods(http.MethodPost).HandlerFunc(a.CreateSLO)
+	router.Path("/api/v1/slos/{name}").Methods(http.MethodGet).HandlerFunc(a.GetSLO)
+	router.Path("/api/v1/slos/{name}").Methods(http.MethodDelete).HandlerFunc(a.DeleteSLO)
+	router.Path("/api/v1/slos/{name}/error_budget").Methods(http.MethodGet).HandlerFunc(a.SLOErrorBudget)
+	router.Path("/api/v1/rules/{namespace}").Methods(http.MethodPost).HandlerFunc(a.CreateRuleGroup)
+	router.Path("/api/v1/rules/{namespace}/{groupName}").Methods(http.MethodGet).HandlerFunc(a.GetRuleGroup)
+	router.Path("/api/v1/rules/{namespace}/{groupName}").Methods(http.MethodDelete).HandlerFunc(a.DeleteRuleGroup)
+
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, requestFor(t, method, url, strings.NewReader(body), "user1"))
+		return w
+	}
+
+	slo := `name: api-availability
+service: api
+sli:
+    good_query: sum(rate(http_requests_total{code!~"5.."}[$window]))
+    total_query: sum(rate(http_requests_total[$window]))
+objective: 0.999
+window: 30d
+`
+
+	// No SLO yet.
+	w := do(http.MethodGet, "https://localhost:8080/api/v1/slos", "")
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	// Invalid SLO.
+	w = do(http.MethodPost, "https://localhost:8080/api/v1/slos", strings.Replace(slo, "objective: 0.999", "objective: 99.9", 1))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "invalid SLO objective 99.9")
+
+	// Create the SLO.
+	w = do(http.MethodPost, "https://localhost:8080/api/v1/slos", slo)
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	w = do(http.MethodGet, "https://localhost:8080/api/v1/slos/api-availability", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, slo, w.Body.String())
+
+	w = do(http.MethodGet, "https://localhost:8080/api/v1/slos", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "- name: api-availability\n  service: api\n  sli:\n    good_query: sum(rate(http_requests_total{code!~\"5..\"}[$window]))\n    total_query: sum(rate(http_requests_total[$window]))\n  objective: 0.999\n  window: 30d\n", w.Body.String())
+
+	// The managed rule group is generated from the SLO, and regenerated when it changes.
+	w = do(http.MethodGet, "https://localhost:8080/api/v1/rules/cortex_slos/api-availability", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "record: slo:error_budget_remaining:ratio")
+	require.Contains(t, w.Body.String(), "expr: 1 - (slo:period_error:ratio{slo=\"api-availability\"} / (1 - 0.999))")
+
+	w = do(http.MethodPost, "https://localhost:8080/api/v1/slos", strings.Replace(slo, "objective: 0.999", "objective: 0.99", 1))
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	w = do(http.MethodGet, "https://localhost:8080/api/v1/rules/cortex_slos/api-availability", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "expr: 1 - (slo:period_error:ratio{slo=\"api-availability\"} / (1 - 0.99))")
+
+	// The managed rule group can't be edited with the rules API.
+	w = do(http.MethodPost, "https://localhost:8080/api/v1/rules/cortex_slos", "name: api-availability\nrules:\n- record: up_rule\n  expr: up\n")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, ErrManagedNamespace.Error()+"\n", w.Body.String())
+
+	w = do(http.MethodDelete, "https://localhost:8080/api/v1/rules/cortex_slos/api-availability", "")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// The error budget remaining is read from the managed rule group.
+	w = do(http.MethodGet, "https://localhost:8080/api/v1/slos/api-availability/error_budget", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `slo:error_budget_remaining:ratio{slo="api-availability"}`, query)
+	require.JSONEq(t, `{"status":"success","data":{"name":"api-availability","service":"api","objective":0.99,"window":"30d","series":[{"labels":{"slo":"api-availability"},"remaining":"0.25"}]}}`, w.Body.String())
+
+	w = do(http.MethodGet, "https://localhost:8080/api/v1/slos/unknown/error_budget", "")
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	// Delete the SLO.
+	w = do(http.MethodDelete, "https://localhost:8080/api/v1/slos/api-availability", "")
+	require.Equal(t, http.StatusAccepted, w.Code)
+}
+
 func requestFor(t *testing.T, method string, url string, body io.Reader, userID string) *http.Request {
 	t.Helper()
 
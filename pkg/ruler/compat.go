@@ -27,6 +27,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/querier"
 	"github.com/cortexproject/cortex/pkg/querier/stats"
 	"github.com/cortexproject/cortex/pkg/ring/client"
+	"github.com/cortexproject/cortex/pkg/usage"
 	util_log "github.com/cortexproject/cortex/pkg/util/log"
 	promql_util "github.com/cortexproject/cortex/pkg/util/promql"
 	"github.com/cortexproject/cortex/pkg/util/requestmeta"
@@ -164,6 +165,7 @@ type RulesLimits interface {
 	RulerQueryOffset(userID string) time.Duration
 	DisabledRuleGroups(userID string) validation.DisabledRuleGroups
 	RulerExternalLabels(userID string) labels.Labels
+	RulerAlertmanagerConfig(userID string) validation.RulerAlertmanagerConfig
 }
 
 type QueryExecutor func(ctx context.Context, qs string, t time.Time) (promql.Vector, error)
@@ -418,10 +420,28 @@ func buildQueryFunc(
 	metricsFunc := metricsQueryFunc(baseQueryFunc, totalQueries, failedQueries)
 
 	// apply statistic middleware
+	queryFunc := metricsFunc
 	if cfg.EnableQueryStats {
-		return recordAndReportRuleQueryMetrics(metricsFunc, userID, metrics, logger)
+		queryFunc = recordAndReportRuleQueryMetrics(metricsFunc, userID, metrics, logger)
+	}
+
+	// record the samples of each rule for the evaluation history
+	if cfg.RuleEvaluationHistorySize > 0 {
+		queryFunc = recordRuleSamplesQueryFunc(queryFunc)
+	}
+
+	// track the rule evaluations in the usage of the tenant
+	if cfg.UsageTracker != nil {
+		queryFunc = usageQueryFunc(queryFunc, cfg.UsageTracker, userID)
+	}
+	return queryFunc
+}
+
+func usageQueryFunc(qf rules.QueryFunc, tracker *usage.Tracker, userID string) rules.QueryFunc {
+	return func(ctx context.Context, qs string, t time.Time) (promql.Vector, error) {
+		tracker.AddRuleEvaluations(userID, 1)
+		return qf(ctx, qs, t)
 	}
-	return metricsFunc
 }
 
 type QueryableError struct {
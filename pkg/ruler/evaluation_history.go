@@ -0,0 +1,181 @@
+package ruler
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/promql"
+	promRules "github.com/prometheus/prometheus/rules"
+)
+
+type ruleSamplesRecorderKey struct{}
+
+// ruleSamplesRecorder records the number of samples returned by the queries of the rules evaluated
+// during a rule group evaluation, keyed by rule. The rules of a group can be evaluated concurrently.
+type ruleSamplesRecorder struct {
+	mtx     sync.Mutex
+	samples map[string]int64
+}
+
+func (r *ruleSamplesRecorder) record(rule promRules.RuleDetail, samples int) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.samples[ruleSamplesKey(rule)] = int64(samples)
+}
+
+func (r *ruleSamplesRecorder) get(rule promRules.RuleDetail) int64 {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.samples[ruleSamplesKey(rule)]
+}
+
+// ruleSamplesKey identifies a rule within its group. The rules with the same key are identical,
+// so they return the same samples.
+func ruleSamplesKey(rule promRules.RuleDetail) string {
+	return strings.Join([]string{rule.Kind, rule.Name, rule.Labels.String(), rule.Query}, "\xff")
+}
+
+// recordRuleSamplesQueryFunc records the number of samples returned by the queries in the
+// ruleSamplesRecorder injected in the context by the rule evaluation history, if any. The rule
+// being evaluated is taken from the context, where the Prometheus rules inject it.
+func recordRuleSamplesQueryFunc(qf promRules.QueryFunc) promRules.QueryFunc {
+	return func(ctx context.Context, qs string, t time.Time) (promql.Vector, error) {
+		result, err := qf(ctx, qs, t)
+		if recorder, ok := ctx.Value(ruleSamplesRecorderKey{}).(*ruleSamplesRecorder); ok && err == nil {
+			recorder.record(promRules.FromOriginContext(ctx), len(result))
+		}
+		return result, err
+	}
+}
+
+// ruleEvaluationHistory keeps, for each rule, the outcome of its last evaluations in memory,
+// so that skipped, slow or failing evaluations can be investigated after the fact.
+type ruleEvaluationHistory struct {
+	size int
+
+	mtx    sync.RWMutex
+	groups map[string]*groupEvaluationHistory
+}
+
+type groupEvaluationHistory struct {
+	lastEvaluation time.Time
+	rules          []*ruleEvaluations
+}
+
+// ruleEvaluations is a ring buffer of the evaluations of a rule.
+type ruleEvaluations struct {
+	name        string
+	evaluations []RuleEvaluationDesc
+	next        int
+}
+
+func newRuleEvaluationHistory(size int) *ruleEvaluationHistory {
+	return &ruleEvaluationHistory{
+		size:   size,
+		groups: map[string]*groupEvaluationHistory{},
+	}
+}
+
+// wrapIterationFunc returns a GroupEvalIterationFunc recording the outcome of the rules
+// evaluated by the input one.
+func (h *ruleEvaluationHistory) wrapIterationFunc(next promRules.GroupEvalIterationFunc) promRules.GroupEvalIterationFunc {
+	return func(ctx context.Context, g *promRules.Group, evalTimestamp time.Time) {
+		recorder := &ruleSamplesRecorder{samples: map[string]int64{}}
+		next(context.WithValue(ctx, ruleSamplesRecorderKey{}, recorder), g, evalTimestamp)
+		h.record(g, evalTimestamp, recorder)
+	}
+}
+
+func (h *ruleEvaluationHistory) record(g *promRules.Group, evalTimestamp time.Time, recorder *ruleSamplesRecorder) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	key := promRules.GroupKey(g.File(), g.Name())
+	group, ok := h.groups[key]
+	if !ok {
+		group = &groupEvaluationHistory{}
+		h.groups[key] = group
+	}
+
+	// The iterations skipped because the previous evaluation took longer than the interval.
+	var missed int64
+	if !group.lastEvaluation.IsZero() && g.Interval() > 0 {
+		missed = max(int64(evalTimestamp.Sub(group.lastEvaluation)/g.Interval())-1, 0)
+	}
+	group.lastEvaluation = evalTimestamp
+
+	rules := g.Rules()
+	if len(group.rules) != len(rules) {
+		resized := make([]*ruleEvaluations, len(rules))
+		copy(resized, group.rules)
+		group.rules = resized
+	}
+
+	for i, rule := range rules {
+		// Reset the history of a rule replaced by a group update.
+		if group.rules[i] == nil || group.rules[i].name != rule.Name() {
+			group.rules[i] = &ruleEvaluations{name: rule.Name(), evaluations: make([]RuleEvaluationDesc, 0, h.size)}
+		}
+
+		evaluation := RuleEvaluationDesc{
+			Timestamp:        rule.GetEvaluationTimestamp(),
+			Duration:         rule.GetEvaluationDuration(),
+			Samples:          recorder.get(promRules.NewRuleDetail(rule)),
+			Health:           string(rule.Health()),
+			MissedIterations: missed,
+		}
+		if err := rule.LastError(); err != nil {
+			evaluation.LastError = err.Error()
+		}
+		group.rules[i].add(evaluation, h.size)
+	}
+}
+
+func (r *ruleEvaluations) add(evaluation RuleEvaluationDesc, size int) {
+	// The rule hasn't been evaluated, for example because the group evaluation was canceled.
+	if n := len(r.evaluations); n > 0 && !r.evaluations[(r.next+n-1)%n].Timestamp.Before(evaluation.Timestamp) {
+		return
+	}
+
+	if len(r.evaluations) < size {
+		r.evaluations = append(r.evaluations, evaluation)
+		return
+	}
+	r.evaluations[r.next] = evaluation
+	r.next = (r.next + 1) % size
+}
+
+// get returns the evaluations of the i-th rule of the input group, ordered by evaluation time.
+func (h *ruleEvaluationHistory) get(g *promRules.Group, i int, name string) []RuleEvaluationDesc {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+
+	group, ok := h.groups[promRules.GroupKey(g.File(), g.Name())]
+	if !ok || i >= len(group.rules) || group.rules[i] == nil || group.rules[i].name != name {
+		return nil
+	}
+
+	r := group.rules[i]
+	out := make([]RuleEvaluationDesc, 0, len(r.evaluations))
+	out = append(out, r.evaluations[r.next:]...)
+	return append(out, r.evaluations[:r.next]...)
+}
+
+// retain removes the history of the rule groups which are not in the input ones.
+func (h *ruleEvaluationHistory) retain(groups []*promRules.Group) {
+	keys := make(map[string]struct{}, len(groups))
+	for _, g := range groups {
+		keys[promRules.GroupKey(g.File(), g.Name())] = struct{}{}
+	}
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	for key := range h.groups {
+		if _, ok := keys[key]; !ok {
+			delete(h.groups, key)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package ruler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+	promRules "github.com/prometheus/prometheus/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleEvaluationHistory(t *testing.T) {
+	const (
+		query    = `sum(rate(http_requests_total[5m]))`
+		interval = time.Minute
+	)
+
+	expr, err := parser.ParseExpr(query)
+	require.NoError(t, err)
+	rule := promRules.NewRecordingRule("job:http_requests:rate5m", expr, labels.EmptyLabels())
+	// Another rule with the same query, returning a different number of samples.
+	otherRule := promRules.NewRecordingRule("job:http_requests:rate5m:other", expr, labels.EmptyLabels())
+
+	evalErr := errors.New("query timed out")
+	start := time.Now().Truncate(interval)
+
+	// Simulates the rule group evaluation, failing the evaluation at the input timestamp if requested.
+	iterationFunc := func(ctx context.Context, g *promRules.Group, ts time.Time) {
+		qf := recordRuleSamplesQueryFunc(func(ctx context.Context, _ string, _ time.Time) (promql.Vector, error) {
+			if promRules.FromOriginContext(ctx).Name == otherRule.Name() {
+				return make(promql.Vector, 5), nil
+			}
+			return make(promql.Vector, 3), nil
+		})
+		_, _ = qf(promRules.NewOriginContext(ctx, promRules.NewRuleDetail(rule)), query, ts)
+		_, _ = qf(promRules.NewOriginContext(ctx, promRules.NewRuleDetail(otherRule)), query, ts)
+		otherRule.SetEvaluationTimestamp(ts)
+
+		rule.SetEvaluationTimestamp(ts)
+		rule.SetEvaluationDuration(2 * time.Second)
+		if ts.Equal(start.Add(interval)) {
+			rule.SetHealth(promRules.HealthBad)
+			rule.SetLastError(evalErr)
+		} else {
+			rule.SetHealth(promRules.HealthGood)
+			rule.SetLastError(nil)
+		}
+	}
+
+	h := newRuleEvaluationHistory(3)
+	g := promRules.NewGroup(promRules.GroupOptions{
+		Name:     "group",
+		File:     "namespace",
+		Interval: interval,
+		Rules:    []promRules.Rule{rule, otherRule},
+	})
+	eval := h.wrapIterationFunc(iterationFunc)
+
+	// Evaluate the group 4 times, missing 2 iterations before the last one.
+	for _, ts := range []time.Time{start, start.Add(interval), start.Add(2 * interval), start.Add(5 * interval)} {
+		eval(context.Background(), g, ts)
+	}
+
+	// The oldest evaluation has been evicted.
+	history := h.get(g, 0, rule.Name())
+	require.Equal(t, []RuleEvaluationDesc{
+		{Timestamp: start.Add(interval), Duration: 2 * time.Second, Samples: 3, Health: string(promRules.HealthBad), LastError: evalErr.Error()},
+		{Timestamp: start.Add(2 * interval), Duration: 2 * time.Second, Samples: 3, Health: string(promRules.HealthGood)},
+		{Timestamp: start.Add(5 * interval), Duration: 2 * time.Second, Samples: 3, Health: string(promRules.HealthGood), MissedIterations: 2},
+	}, history)
+
+	// The samples are recorded per rule, even if the rules have the same query.
+	otherHistory := h.get(g, 1, otherRule.Name())
+	require.Len(t, otherHistory, 3)
+	assert.Equal(t, int64(5), otherHistory[2].Samples)
+
+	// The history is keyed by rule, so a rule replaced by a group update has no history.
+	assert.Empty(t, h.get(g, 0, "another_rule"))
+	assert.Empty(t, h.get(g, 1, rule.Name()))
+
+	// The history of the rule groups which are not loaded anymore is removed.
+	h.retain([]*promRules.Group{g})
+	assert.Len(t, h.get(g, 0, rule.Name()), 3)
+	h.retain(nil)
+	assert.Empty(t, h.get(g, 0, rule.Name()))
+}
+
+func TestRuleEvaluations_Add(t *testing.T) {
+	now := time.Now()
+	r := &ruleEvaluations{}
+
+	r.add(RuleEvaluationDesc{Timestamp: now}, 2)
+	// An evaluation which isn't newer than the last one is not recorded.
+	r.add(RuleEvaluationDesc{Timestamp: now}, 2)
+	assert.Len(t, r.evaluations, 1)
+
+	r.add(RuleEvaluationDesc{Timestamp: now.Add(time.Second)}, 2)
+	r.add(RuleEvaluationDesc{Timestamp: now.Add(2 * time.Second)}, 2)
+	r.add(RuleEvaluationDesc{Timestamp: now.Add(time.Second)}, 2)
+	require.Len(t, r.evaluations, 2)
+	assert.Equal(t, now.Add(time.Second), r.evaluations[r.next].Timestamp)
+	assert.Equal(t, now.Add(2*time.Second), r.evaluations[(r.next+1)%2].Timestamp)
+}
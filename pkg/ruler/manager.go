@@ -16,7 +16,6 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
-	"github.com/prometheus/prometheus/config"
 	"github.com/prometheus/prometheus/discovery"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/rulefmt"
@@ -32,7 +31,7 @@ import (
 
 type DefaultMultiTenantManager struct {
 	cfg             Config
-	notifierCfg     *config.Config
+	limits          RulesLimits
 	managerFactory  ManagerFactory
 	ruleEvalMetrics *RuleEvalMetrics
 	frontendPool    *client.Pool
@@ -67,12 +66,18 @@ type DefaultMultiTenantManager struct {
 	ruleCacheMtx sync.RWMutex
 	syncRuleMtx  sync.Mutex
 
+	// Per-user outcome of the last reload, guarded by syncRuleMtx.
+	reloadStatuses map[string]reloadStatus
+
 	ruleGroupIterationFunc promRules.GroupEvalIterationFunc
+
+	// Nil if the rule evaluation history is disabled.
+	evaluationHistory *ruleEvaluationHistory
 }
 
 func NewDefaultMultiTenantManager(cfg Config, limits RulesLimits, managerFactory ManagerFactory, evalMetrics *RuleEvalMetrics, reg prometheus.Registerer, logger log.Logger) (*DefaultMultiTenantManager, error) {
-	ncfg, err := buildNotifierConfig(&cfg)
-	if err != nil {
+	// The notifier config is built per tenant, but we validate the global one upfront.
+	if _, err := buildNotifierConfig(&cfg); err != nil {
 		return nil, err
 	}
 
@@ -95,7 +100,7 @@ func NewDefaultMultiTenantManager(cfg Config, limits RulesLimits, managerFactory
 
 	m := &DefaultMultiTenantManager{
 		cfg:                       cfg,
-		notifierCfg:               ncfg,
+		limits:                    limits,
 		managerFactory:            managerFactory,
 		frontendPool:              newFrontendPool(cfg, logger, reg),
 		ruleEvalMetrics:           evalMetrics,
@@ -106,6 +111,7 @@ func NewDefaultMultiTenantManager(cfg Config, limits RulesLimits, managerFactory
 		userManagers:              map[string]RulesManager{},
 		userManagerMetrics:        userManagerMetrics,
 		ruleCache:                 map[string][]*promRules.Group{},
+		reloadStatuses:            map[string]reloadStatus{},
 		managersTotal: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
 			Namespace: "cortex",
 			Name:      "ruler_managers_total",
@@ -133,6 +139,9 @@ func NewDefaultMultiTenantManager(cfg Config, limits RulesLimits, managerFactory
 	if cfg.RulesBackupEnabled() {
 		m.rulesBackupManager = newRulesBackupManager(cfg, logger, reg)
 	}
+	if cfg.RuleEvaluationHistorySize > 0 {
+		m.evaluationHistory = newRuleEvaluationHistory(cfg.RuleEvaluationHistorySize)
+	}
 	return m, nil
 }
 
@@ -166,6 +175,7 @@ func (r *DefaultMultiTenantManager) SyncRuleGroups(ctx context.Context, ruleGrou
 			r.removeNotifier(userID)
 			r.mapper.cleanupUser(userID)
 			r.userExternalLabels.remove(userID)
+			delete(r.reloadStatuses, userID)
 			r.lastReloadSuccessful.DeleteLabelValues(userID)
 			r.lastReloadSuccessfulTimestamp.DeleteLabelValues(userID)
 			r.configUpdatesTotal.DeleteLabelValues(userID)
@@ -178,6 +188,15 @@ func (r *DefaultMultiTenantManager) SyncRuleGroups(ctx context.Context, ruleGrou
 	}
 
 	r.managersTotal.Set(float64(len(r.userManagers)))
+
+	// Drop the evaluation history of the rule groups removed or moved to another ruler.
+	if r.evaluationHistory != nil {
+		var groups []*promRules.Group
+		for _, mngr := range r.userManagers {
+			groups = append(groups, mngr.RuleGroups()...)
+		}
+		r.evaluationHistory.retain(groups)
+	}
 }
 
 func (r *DefaultMultiTenantManager) updateRuleCache(user string, rules []*promRules.Group) {
@@ -222,32 +241,62 @@ func (r *DefaultMultiTenantManager) syncRulesToManager(ctx context.Context, user
 		return
 	}
 
+	status := r.reloadStatuses[user]
+	reloaded := false
 	if !existing || rulesUpdated || externalLabelsUpdated {
 		level.Debug(r.logger).Log("msg", "updating rules", "user", user)
 		r.configUpdatesTotal.WithLabelValues(user).Inc()
 		if (rulesUpdated || externalLabelsUpdated) && existing {
 			r.updateRuleCache(user, manager.RuleGroups())
 		}
-		err = manager.Update(r.cfg.EvaluationInterval, files, externalLabels, r.cfg.ExternalURL.String(), r.ruleGroupIterationFunc)
+		iterationFunc := r.ruleGroupIterationFunc
+		if r.evaluationHistory != nil {
+			iterationFunc = r.evaluationHistory.wrapIterationFunc(iterationFunc)
+		}
+		err = manager.Update(r.cfg.EvaluationInterval, files, externalLabels, r.cfg.ExternalURL.String(), iterationFunc)
 		r.deleteRuleCache(user)
 		if err != nil {
 			r.lastReloadSuccessful.WithLabelValues(user).Set(0)
 			level.Error(r.logger).Log("msg", "unable to update rule manager", "user", user, "err", err)
+			r.reloadStatuses[user] = reloadStatus{rulesFailed: true}
 			return
 		}
-		if externalLabelsUpdated {
-			if err = r.notifierApplyExternalLabels(user, externalLabels); err != nil {
-				r.lastReloadSuccessful.WithLabelValues(user).Set(0)
-				level.Error(r.logger).Log("msg", "unable to update notifier", "user", user, "err", err)
-				return
-			}
-		}
+		status = reloadStatus{}
+		reloaded = true
+	}
+
+	// The notifier config depends on the tenant overrides, which can change regardless of the rules.
+	if err = r.updateNotifierConfig(user, externalLabels); err != nil {
+		level.Error(r.logger).Log("msg", "unable to update notifier", "user", user, "err", err)
+		status.notifierFailed = true
+	} else if status.notifierFailed {
+		// The notifier config failed in a previous sync, so this sync recovered the reload.
+		status.notifierFailed = false
+		reloaded = true
+	}
+	r.reloadStatuses[user] = status
 
+	if status.failed() {
+		r.lastReloadSuccessful.WithLabelValues(user).Set(0)
+		return
+	}
+	if reloaded {
 		r.lastReloadSuccessful.WithLabelValues(user).Set(1)
 		r.lastReloadSuccessfulTimestamp.WithLabelValues(user).SetToCurrentTime()
 	}
 }
 
+// reloadStatus tracks why the last reload of a user failed. A failed rules update is only
+// retried once the rules change, while the notifier config is applied on every sync.
+type reloadStatus struct {
+	rulesFailed    bool
+	notifierFailed bool
+}
+
+func (s reloadStatus) failed() bool {
+	return s.rulesFailed || s.notifierFailed
+}
+
 func (r *DefaultMultiTenantManager) getRulesManager(user string, ctx context.Context) RulesManager {
 	r.userManagerMtx.RLock()
 	defer r.userManagerMtx.RUnlock()
@@ -366,8 +415,9 @@ func (r *DefaultMultiTenantManager) getOrCreateNotifier(userID string, userManag
 
 	n.run()
 
-	// This should never fail, unless there's a programming mistake.
-	if err := n.applyConfig(r.notifierCfg); err != nil {
+	externalLabels, _ := r.userExternalLabels.get(userID)
+	if err := n.applyUserConfig(&r.cfg, r.limits.RulerAlertmanagerConfig(userID), externalLabels); err != nil {
+		n.stop()
 		return nil, err
 	}
 
@@ -375,7 +425,9 @@ func (r *DefaultMultiTenantManager) getOrCreateNotifier(userID string, userManag
 	return n.notifier, nil
 }
 
-func (r *DefaultMultiTenantManager) notifierApplyExternalLabels(userID string, externalLabels labels.Labels) error {
+// updateNotifierConfig applies the Alertmanager overrides and external labels of the user to
+// its notifier, if they have changed.
+func (r *DefaultMultiTenantManager) updateNotifierConfig(userID string, externalLabels labels.Labels) error {
 	r.notifiersMtx.Lock()
 	defer r.notifiersMtx.Unlock()
 
@@ -383,9 +435,7 @@ func (r *DefaultMultiTenantManager) notifierApplyExternalLabels(userID string, e
 	if !ok {
 		return fmt.Errorf("notifier not found")
 	}
-	cfg := *r.notifierCfg // Copy it
-	cfg.GlobalConfig.ExternalLabels = externalLabels
-	return n.applyConfig(&cfg)
+	return n.applyUserConfig(&r.cfg, r.limits.RulerAlertmanagerConfig(userID), externalLabels)
 }
 
 func (r *DefaultMultiTenantManager) getCachedRules(userID string) ([]*promRules.Group, bool) {
@@ -417,6 +467,13 @@ func (r *DefaultMultiTenantManager) GetBackupRules(userID string) rulespb.RuleGr
 	return nil
 }
 
+func (r *DefaultMultiTenantManager) GetRuleEvaluationHistory(group *promRules.Group, i int, ruleName string) []RuleEvaluationDesc {
+	if r.evaluationHistory != nil {
+		return r.evaluationHistory.get(group, i, ruleName)
+	}
+	return nil
+}
+
 func (r *DefaultMultiTenantManager) Stop() {
 	r.notifiersMtx.Lock()
 	for _, n := range r.notifiers {
@@ -8,6 +8,7 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/rulefmt"
 	"github.com/prometheus/prometheus/notifier"
@@ -19,6 +20,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/ruler/rulespb"
 	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/test"
+	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
 func TestSyncRuleGroups(t *testing.T) {
@@ -113,6 +115,87 @@ func TestSyncRuleGroups(t *testing.T) {
 	})
 }
 
+func TestSyncRuleGroups_ReloadsNotifierOnAlertmanagerConfigChange(t *testing.T) {
+	dir := t.TempDir()
+
+	ruleManagerFactory := RuleManagerFactory(nil, []time.Duration{1 * time.Millisecond, 1 * time.Millisecond})
+	limits := &ruleLimits{}
+
+	cfg := Config{RulePath: dir, AlertmanagerURL: "http://global-alertmanager:9093", NotificationTimeout: 10 * time.Second}
+	m, err := NewDefaultMultiTenantManager(cfg, limits, ruleManagerFactory, nil, prometheus.NewRegistry(), log.NewNopLogger())
+	require.NoError(t, err)
+	defer m.Stop()
+
+	const user = "testUser"
+	userRules := map[string]rulespb.RuleGroupList{
+		user: {
+			&rulespb.RuleGroupDesc{
+				Name:      "group1",
+				Namespace: "ns",
+				Interval:  1 * time.Minute,
+				User:      user,
+			},
+		},
+	}
+
+	alertmanagers := func() any {
+		m.notifiersMtx.Lock()
+		defer m.notifiersMtx.Unlock()
+
+		var urls []string
+		for _, u := range m.notifiers[user].notifier.Alertmanagers() {
+			urls = append(urls, u.String())
+		}
+		return urls
+	}
+
+	// Without overrides, the alerts are sent to the global Alertmanager.
+	m.SyncRuleGroups(context.Background(), userRules)
+	test.Poll(t, 10*time.Second, []string{"http://global-alertmanager:9093/api/v2/alerts"}, alertmanagers)
+
+	// The notifier is reloaded when the tenant overrides change, even if the rules didn't.
+	limits.setRulerAlertmanagerConfig(validation.RulerAlertmanagerConfig{AlertmanagerURL: "http://tenant-alertmanager:9093"})
+	m.SyncRuleGroups(context.Background(), userRules)
+	test.Poll(t, 10*time.Second, []string{"http://tenant-alertmanager:9093/api/v2/alerts"}, alertmanagers)
+}
+
+func TestSyncRuleGroups_ResetsLastReloadSuccessfulOnNotifierConfigRecovery(t *testing.T) {
+	dir := t.TempDir()
+
+	ruleManagerFactory := RuleManagerFactory(nil, []time.Duration{1 * time.Millisecond, 1 * time.Millisecond})
+	limits := &ruleLimits{}
+
+	cfg := Config{RulePath: dir, AlertmanagerURL: "http://global-alertmanager:9093", NotificationTimeout: 10 * time.Second}
+	m, err := NewDefaultMultiTenantManager(cfg, limits, ruleManagerFactory, nil, prometheus.NewRegistry(), log.NewNopLogger())
+	require.NoError(t, err)
+	defer m.Stop()
+
+	const user = "testUser"
+	userRules := map[string]rulespb.RuleGroupList{
+		user: {
+			&rulespb.RuleGroupDesc{
+				Name:      "group1",
+				Namespace: "ns",
+				Interval:  1 * time.Minute,
+				User:      user,
+			},
+		},
+	}
+
+	m.SyncRuleGroups(context.Background(), userRules)
+	require.Equal(t, float64(1), testutil.ToFloat64(m.lastReloadSuccessful.WithLabelValues(user)))
+
+	// An invalid notifier config fails the reload, even if the rules didn't change.
+	limits.setRulerAlertmanagerConfig(validation.RulerAlertmanagerConfig{AlertmanagerURL: "http://tenant-alertmanager:9093", AlertmanagerDiscovery: true})
+	m.SyncRuleGroups(context.Background(), userRules)
+	require.Equal(t, float64(0), testutil.ToFloat64(m.lastReloadSuccessful.WithLabelValues(user)))
+
+	// Fixing the notifier config recovers the reload.
+	limits.setRulerAlertmanagerConfig(validation.RulerAlertmanagerConfig{AlertmanagerURL: "http://tenant-alertmanager:9093"})
+	m.SyncRuleGroups(context.Background(), userRules)
+	require.Equal(t, float64(1), testutil.ToFloat64(m.lastReloadSuccessful.WithLabelValues(user)))
+}
+
 func TestSlowRuleGroupSyncDoesNotSlowdownListRules(t *testing.T) {
 	dir := t.TempDir()
 	const user = "testUser"
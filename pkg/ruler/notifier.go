@@ -1,6 +1,7 @@
 package ruler
 
 import (
+	"bytes"
 	"context"
 	"flag"
 	"fmt"
@@ -17,11 +18,14 @@ import (
 	"github.com/prometheus/prometheus/config"
 	"github.com/prometheus/prometheus/discovery"
 	"github.com/prometheus/prometheus/discovery/dns"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/notifier"
+	"gopkg.in/yaml.v3"
 
 	"github.com/cortexproject/cortex/pkg/util"
 	util_log "github.com/cortexproject/cortex/pkg/util/log"
 	"github.com/cortexproject/cortex/pkg/util/tls"
+	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
 type NotifierConfig struct {
@@ -43,6 +47,10 @@ type rulerNotifier struct {
 	sdManager *discovery.Manager
 	wg        sync.WaitGroup
 	logger    gklog.Logger
+
+	// The tenant overrides and external labels the notifier has been last configured with.
+	userConfig     []byte
+	externalLabels labels.Labels
 }
 
 func newRulerNotifier(o *notifier.Options, l gklog.Logger, registerer prometheus.Registerer, sdMetrics map[string]discovery.DiscovererMetrics) *rulerNotifier {
@@ -83,6 +91,31 @@ func (rn *rulerNotifier) applyConfig(cfg *config.Config) error {
 	return rn.sdManager.ApplyConfig(sdCfgs)
 }
 
+// applyUserConfig builds the notifier config from the ruler config and the tenant overrides, and
+// applies it unless the notifier has already been configured with the same overrides and external labels.
+func (rn *rulerNotifier) applyUserConfig(rulerConfig *Config, userConfig validation.RulerAlertmanagerConfig, externalLabels labels.Labels) error {
+	// The overrides are compared in their YAML form because the relabel configs hold compiled regexps.
+	key, err := yaml.Marshal(userConfig)
+	if err != nil {
+		return err
+	}
+	if rn.userConfig != nil && bytes.Equal(rn.userConfig, key) && labels.Equal(rn.externalLabels, externalLabels) {
+		return nil
+	}
+
+	cfg, err := buildUserNotifierConfig(rulerConfig, userConfig, externalLabels)
+	if err != nil {
+		return err
+	}
+	if err := rn.applyConfig(cfg); err != nil {
+		return err
+	}
+
+	rn.userConfig = key
+	rn.externalLabels = externalLabels
+	return nil
+}
+
 func (rn *rulerNotifier) stop() {
 	rn.sdCancel()
 	rn.notifier.Stop()
@@ -136,6 +169,43 @@ func buildNotifierConfig(rulerConfig *Config) (*config.Config, error) {
 	return promConfig, nil
 }
 
+// Builds the Prometheus config.Config of the notifier of a tenant, overriding the ruler.Config
+// Alertmanager options with the per-tenant ones, if any. When the tenant overrides the Alertmanager
+// URL, the ruler basic auth and TLS client settings are not used, so that the ruler credentials are
+// never sent to the Alertmanagers of the tenant.
+func buildUserNotifierConfig(rulerConfig *Config, userConfig validation.RulerAlertmanagerConfig, externalLabels labels.Labels) (*config.Config, error) {
+	cfg := *rulerConfig // Copy it
+	if userConfig.AlertmanagerURL != "" {
+		cfg.AlertmanagerURL = userConfig.AlertmanagerURL
+		cfg.AlertmanagerDiscovery = userConfig.AlertmanagerDiscovery
+		cfg.Notifier = NotifierConfig{}
+	}
+	if userConfig.BasicAuthUsername != "" || userConfig.BasicAuthPassword.Value != "" {
+		cfg.Notifier.BasicAuth = util.BasicAuth{
+			Username: userConfig.BasicAuthUsername,
+			Password: userConfig.BasicAuthPassword.Value,
+		}
+	}
+	if userConfig.HasTLSConfig() {
+		cfg.Notifier.TLS = tls.ClientConfig{
+			CertPath:           userConfig.TLSCertPath,
+			KeyPath:            userConfig.TLSKeyPath,
+			CAPath:             userConfig.TLSCAPath,
+			ServerName:         userConfig.TLSServerName,
+			InsecureSkipVerify: userConfig.TLSInsecureSkipVerify,
+		}
+	}
+
+	promConfig, err := buildNotifierConfig(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	promConfig.GlobalConfig.ExternalLabels = externalLabels
+	promConfig.AlertingConfig.AlertRelabelConfigs = userConfig.AlertRelabelConfigs
+
+	return promConfig, nil
+}
+
 func amConfigFromURL(rulerConfig *Config, url *url.URL, apiVersion config.AlertmanagerAPIVersion) *config.AlertmanagerConfig {
 	var sdConfig discovery.Configs
 	if rulerConfig.AlertmanagerDiscovery {
@@ -11,9 +11,13 @@ import (
 	"github.com/prometheus/prometheus/discovery"
 	"github.com/prometheus/prometheus/discovery/dns"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
 	"github.com/stretchr/testify/require"
 
 	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/tls"
+	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
 func TestBuildNotifierConfig(t *testing.T) {
@@ -263,3 +267,136 @@ func TestBuildNotifierConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildUserNotifierConfig(t *testing.T) {
+	rulerConfig := &Config{
+		AlertmanagerURL: "http://alertmanager.default.svc.cluster.local/alertmanager",
+		Notifier: NotifierConfig{
+			TLS:       tls.ClientConfig{CAPath: "/path/to/ca"},
+			BasicAuth: util.BasicAuth{Username: "global", Password: "global-password"},
+		},
+	}
+	externalLabels := labels.FromStrings("region", "us-east-1")
+	relabelConfigs := []*relabel.Config{{
+		SourceLabels: model.LabelNames{"severity"},
+		Regex:        relabel.MustNewRegexp("debug"),
+		Action:       relabel.Drop,
+	}}
+
+	staticConfig := func(host string) discovery.Configs {
+		return discovery.Configs{
+			discovery.StaticConfig{
+				{
+					Targets: []model.LabelSet{{"__address__": model.LabelValue(host)}},
+				},
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		userConfig validation.RulerAlertmanagerConfig
+		expected   *config.AlertmanagerConfig
+		expectErr  bool
+	}{
+		"should use the ruler config without overrides": {
+			expected: &config.AlertmanagerConfig{
+				HTTPClientConfig: config_util.HTTPClientConfig{
+					BasicAuth: &config_util.BasicAuth{Username: "global", Password: "global-password"},
+					TLSConfig: config_util.TLSConfig{CAFile: "/path/to/ca"},
+				},
+				APIVersion:              "v2",
+				Scheme:                  "http",
+				PathPrefix:              "/alertmanager",
+				ServiceDiscoveryConfigs: staticConfig("alertmanager.default.svc.cluster.local"),
+			},
+		},
+		"should override the URL, auth and TLS settings": {
+			userConfig: validation.RulerAlertmanagerConfig{
+				AlertmanagerURL:   "https://tenant-alertmanager.example.com/api",
+				BasicAuthUsername: "tenant",
+				BasicAuthPassword: flagext.Secret{Value: "tenant-password"},
+				TLSServerName:     "tenant-alertmanager",
+			},
+			expected: &config.AlertmanagerConfig{
+				HTTPClientConfig: config_util.HTTPClientConfig{
+					BasicAuth: &config_util.BasicAuth{Username: "tenant", Password: "tenant-password"},
+					TLSConfig: config_util.TLSConfig{ServerName: "tenant-alertmanager"},
+				},
+				APIVersion:              "v2",
+				Scheme:                  "https",
+				PathPrefix:              "/api",
+				ServiceDiscoveryConfigs: staticConfig("tenant-alertmanager.example.com"),
+			},
+		},
+		"should not use the ruler auth and TLS settings for the tenant URL": {
+			userConfig: validation.RulerAlertmanagerConfig{
+				AlertmanagerURL: "https://tenant-alertmanager.example.com/api",
+			},
+			expected: &config.AlertmanagerConfig{
+				APIVersion:              "v2",
+				Scheme:                  "https",
+				PathPrefix:              "/api",
+				ServiceDiscoveryConfigs: staticConfig("tenant-alertmanager.example.com"),
+			},
+		},
+		"should override the auth and TLS settings for the ruler URL": {
+			userConfig: validation.RulerAlertmanagerConfig{
+				BasicAuthUsername: "tenant",
+				BasicAuthPassword: flagext.Secret{Value: "tenant-password"},
+			},
+			expected: &config.AlertmanagerConfig{
+				HTTPClientConfig: config_util.HTTPClientConfig{
+					BasicAuth: &config_util.BasicAuth{Username: "tenant", Password: "tenant-password"},
+					TLSConfig: config_util.TLSConfig{CAFile: "/path/to/ca"},
+				},
+				APIVersion:              "v2",
+				Scheme:                  "http",
+				PathPrefix:              "/alertmanager",
+				ServiceDiscoveryConfigs: staticConfig("alertmanager.default.svc.cluster.local"),
+			},
+		},
+		"should use DNS service discovery for the tenant URL": {
+			userConfig: validation.RulerAlertmanagerConfig{
+				AlertmanagerURL:       "http://_http._tcp.tenant-alertmanager.example.com",
+				AlertmanagerDiscovery: true,
+			},
+			expected: &config.AlertmanagerConfig{
+				APIVersion: "v2",
+				Scheme:     "http",
+				ServiceDiscoveryConfigs: discovery.Configs{
+					&dns.SDConfig{
+						Names: []string{"_http._tcp.tenant-alertmanager.example.com"},
+						Type:  "SRV",
+					},
+				},
+			},
+		},
+		"should fail if the tenant URL is not a SRV record with DNS service discovery": {
+			userConfig: validation.RulerAlertmanagerConfig{
+				AlertmanagerURL:       "http://tenant-alertmanager.example.com",
+				AlertmanagerDiscovery: true,
+			},
+			expectErr: true,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			testData.userConfig.AlertRelabelConfigs = relabelConfigs
+
+			ncfg, err := buildUserNotifierConfig(rulerConfig, testData.userConfig, externalLabels)
+			if testData.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, &config.Config{
+				GlobalConfig: config.GlobalConfig{ExternalLabels: externalLabels},
+				AlertingConfig: config.AlertingConfig{
+					AlertRelabelConfigs: relabelConfigs,
+					AlertmanagerConfigs: []*config.AlertmanagerConfig{testData.expected},
+				},
+			}, ncfg)
+		})
+	}
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/ring/kv"
 	"github.com/cortexproject/cortex/pkg/ruler/rulespb"
 	"github.com/cortexproject/cortex/pkg/ruler/rulestore"
+	"github.com/cortexproject/cortex/pkg/usage"
 	"github.com/cortexproject/cortex/pkg/util"
 	util_api "github.com/cortexproject/cortex/pkg/util/api"
 	"github.com/cortexproject/cortex/pkg/util/concurrency"
@@ -53,10 +54,11 @@ var (
 	supportedQueryResponseFormats = []string{queryResponseFormatJson, queryResponseFormatProtobuf}
 
 	// Validation errors.
-	errInvalidShardingStrategy    = errors.New("invalid sharding strategy")
-	errInvalidTenantShardSize     = errors.New("invalid tenant shard size, the value must be greater than 0")
-	errInvalidMaxConcurrentEvals  = errors.New("invalid max concurrent evals, the value must be greater than 0")
-	errInvalidQueryResponseFormat = errors.New("invalid query response format")
+	errInvalidShardingStrategy          = errors.New("invalid sharding strategy")
+	errInvalidTenantShardSize           = errors.New("invalid tenant shard size, the value must be greater than 0")
+	errInvalidMaxConcurrentEvals        = errors.New("invalid max concurrent evals, the value must be greater than 0")
+	errInvalidQueryResponseFormat       = errors.New("invalid query response format")
+	errInvalidRuleEvaluationHistorySize = errors.New("invalid rule evaluation history size, the value must be greater than or equal to 0")
 )
 
 const (
@@ -167,8 +169,9 @@ type Config struct {
 	RingCheckPeriod time.Duration `yaml:"-"`
 
 	// Field will be populated during runtime.
-	LookbackDelta        time.Duration `yaml:"-"`
-	PrometheusHTTPPrefix string        `yaml:"-"`
+	LookbackDelta        time.Duration  `yaml:"-"`
+	PrometheusHTTPPrefix string         `yaml:"-"`
+	UsageTracker         *usage.Tracker `yaml:"-"`
 
 	EnableQueryStats      bool `yaml:"query_stats_enabled"`
 	DisableRuleGroupLabel bool `yaml:"disable_rule_group_label"`
@@ -176,6 +179,8 @@ type Config struct {
 	EnableHAEvaluation   bool          `yaml:"enable_ha_evaluation"`
 	LivenessCheckTimeout time.Duration `yaml:"liveness_check_timeout"`
 
+	RuleEvaluationHistorySize int `yaml:"rule_evaluation_history_size"`
+
 	ThanosEngine engine.ThanosEngineConfig `yaml:"thanos_engine"`
 }
 
@@ -201,6 +206,10 @@ func (cfg *Config) Validate(limits validation.Limits, log log.Logger) error {
 		return errInvalidMaxConcurrentEvals
 	}
 
+	if cfg.RuleEvaluationHistorySize < 0 {
+		return errInvalidRuleEvaluationHistorySize
+	}
+
 	if !slices.Contains(supportedQueryResponseFormats, cfg.QueryResponseFormat) {
 		return errInvalidQueryResponseFormat
 	}
@@ -265,6 +274,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 
 	f.BoolVar(&cfg.EnableHAEvaluation, "ruler.enable-ha-evaluation", false, "Enable high availability")
 	f.DurationVar(&cfg.LivenessCheckTimeout, "ruler.liveness-check-timeout", 1*time.Second, "Timeout duration for non-primary rulers during liveness checks. If the check times out, the non-primary ruler will evaluate the rule group. Applicable when ruler.enable-ha-evaluation is true.")
+	f.IntVar(&cfg.RuleEvaluationHistorySize, "ruler.rule-evaluation-history-size", 0, "[Experimental] Number of evaluations to keep in memory for each rule, including their timestamp, duration, samples, error and missed iterations. The history is returned by the Prometheus rules API when the evaluation_history parameter is true. 0 to disable.")
 	cfg.RingCheckPeriod = 5 * time.Second
 }
 
@@ -289,6 +299,8 @@ type MultiTenantManager interface {
 	Stop()
 	// ValidateRuleGroup validates a rulegroup
 	ValidateRuleGroup(rulefmt.RuleGroup) []error
+	// GetRuleEvaluationHistory returns the last evaluations of the i-th rule of a group, if the history is enabled.
+	GetRuleEvaluationHistory(group *promRules.Group, i int, ruleName string) []RuleEvaluationDesc
 }
 
 // Ruler evaluates rules.
@@ -1146,6 +1158,7 @@ func (r *Ruler) getLocalRules(userID string, rulesRequest RulesRequest, includeB
 
 	returnAlerts := ruleType == "" || ruleType == alertingRuleFilter
 	returnRecording := (ruleType == "" || ruleType == recordingRuleFilter) && alertState == ""
+	manager := r.manager
 
 	for _, group := range groups {
 		// The mapped filename is url path escaped encoded to make handling `/` characters easier
@@ -1180,7 +1193,7 @@ func (r *Ruler) getLocalRules(userID string, rulesRequest RulesRequest, includeB
 			EvaluationTimestamp: group.GetLastEvaluation(),
 			EvaluationDuration:  group.GetEvaluationTime(),
 		}
-		for _, r := range group.Rules() {
+		for i, r := range group.Rules() {
 			if len(ruleNameSet) > 0 {
 				if _, OK := ruleNameSet[r.Name()]; !OK {
 					continue
@@ -1257,6 +1270,9 @@ func (r *Ruler) getLocalRules(userID string, rulesRequest RulesRequest, includeB
 			default:
 				return RulesResponse{}, errors.Errorf("failed to assert type of rule '%v'", rule.Name())
 			}
+			if rulesRequest.IncludeEvaluationHistory {
+				ruleDesc.EvaluationHistory = manager.GetRuleEvaluationHistory(group, i, r.Name())
+			}
 			groupDesc.ActiveRules = append(groupDesc.ActiveRules, ruleDesc)
 		}
 		if len(groupDesc.ActiveRules) > 0 {
@@ -1477,6 +1493,8 @@ func (r *Ruler) getShardedRules(ctx context.Context, userID string, rulesRequest
 			ExcludeAlerts:  rulesRequest.GetExcludeAlerts(),
 			MaxRuleGroups:  rulesRequest.GetMaxRuleGroups(),
 			NextToken:      rulesRequest.GetNextToken(),
+
+			IncludeEvaluationHistory: rulesRequest.GetIncludeEvaluationHistory(),
 		})
 
 		if err != nil {
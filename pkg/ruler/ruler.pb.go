@@ -39,16 +39,17 @@ var _ = time.Kitchen
 const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
 type RulesRequest struct {
-	RuleNames      []string `protobuf:"bytes,1,rep,name=ruleNames,proto3" json:"ruleNames,omitempty"`
-	RuleGroupNames []string `protobuf:"bytes,2,rep,name=ruleGroupNames,proto3" json:"ruleGroupNames,omitempty"`
-	Files          []string `protobuf:"bytes,3,rep,name=files,proto3" json:"files,omitempty"`
-	Type           string   `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
-	State          string   `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`
-	Health         string   `protobuf:"bytes,6,opt,name=health,proto3" json:"health,omitempty"`
-	Matchers       []string `protobuf:"bytes,7,rep,name=matchers,proto3" json:"matchers,omitempty"`
-	ExcludeAlerts  bool     `protobuf:"varint,8,opt,name=excludeAlerts,proto3" json:"excludeAlerts,omitempty"`
-	MaxRuleGroups  int32    `protobuf:"varint,9,opt,name=maxRuleGroups,proto3" json:"maxRuleGroups,omitempty"`
-	NextToken      string   `protobuf:"bytes,10,opt,name=nextToken,proto3" json:"nextToken,omitempty"`
+	RuleNames                []string `protobuf:"bytes,1,rep,name=ruleNames,proto3" json:"ruleNames,omitempty"`
+	RuleGroupNames           []string `protobuf:"bytes,2,rep,name=ruleGroupNames,proto3" json:"ruleGroupNames,omitempty"`
+	Files                    []string `protobuf:"bytes,3,rep,name=files,proto3" json:"files,omitempty"`
+	Type                     string   `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	State                    string   `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`
+	Health                   string   `protobuf:"bytes,6,opt,name=health,proto3" json:"health,omitempty"`
+	Matchers                 []string `protobuf:"bytes,7,rep,name=matchers,proto3" json:"matchers,omitempty"`
+	ExcludeAlerts            bool     `protobuf:"varint,8,opt,name=excludeAlerts,proto3" json:"excludeAlerts,omitempty"`
+	MaxRuleGroups            int32    `protobuf:"varint,9,opt,name=maxRuleGroups,proto3" json:"maxRuleGroups,omitempty"`
+	NextToken                string   `protobuf:"bytes,10,opt,name=nextToken,proto3" json:"nextToken,omitempty"`
+	IncludeEvaluationHistory bool     `protobuf:"varint,11,opt,name=includeEvaluationHistory,proto3" json:"includeEvaluationHistory,omitempty"`
 }
 
 func (m *RulesRequest) Reset()      { *m = RulesRequest{} }
@@ -153,6 +154,13 @@ func (m *RulesRequest) GetNextToken() string {
 	return ""
 }
 
+func (m *RulesRequest) GetIncludeEvaluationHistory() bool {
+	if m != nil {
+		return m.IncludeEvaluationHistory
+	}
+	return false
+}
+
 type LivenessCheckRequest struct {
 }
 
@@ -352,13 +360,14 @@ func (m *GroupStateDesc) GetEvaluationDuration() time.Duration {
 
 // RuleStateDesc is a proto representation of a Prometheus Rule
 type RuleStateDesc struct {
-	Rule                *rulespb.RuleDesc `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
-	State               string            `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
-	Health              string            `protobuf:"bytes,3,opt,name=health,proto3" json:"health,omitempty"`
-	LastError           string            `protobuf:"bytes,4,opt,name=lastError,proto3" json:"lastError,omitempty"`
-	Alerts              []*AlertStateDesc `protobuf:"bytes,5,rep,name=alerts,proto3" json:"alerts,omitempty"`
-	EvaluationTimestamp time.Time         `protobuf:"bytes,6,opt,name=evaluationTimestamp,proto3,stdtime" json:"evaluationTimestamp"`
-	EvaluationDuration  time.Duration     `protobuf:"bytes,7,opt,name=evaluationDuration,proto3,stdduration" json:"evaluationDuration"`
+	Rule                *rulespb.RuleDesc    `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	State               string               `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	Health              string               `protobuf:"bytes,3,opt,name=health,proto3" json:"health,omitempty"`
+	LastError           string               `protobuf:"bytes,4,opt,name=lastError,proto3" json:"lastError,omitempty"`
+	Alerts              []*AlertStateDesc    `protobuf:"bytes,5,rep,name=alerts,proto3" json:"alerts,omitempty"`
+	EvaluationTimestamp time.Time            `protobuf:"bytes,6,opt,name=evaluationTimestamp,proto3,stdtime" json:"evaluationTimestamp"`
+	EvaluationDuration  time.Duration        `protobuf:"bytes,7,opt,name=evaluationDuration,proto3,stdduration" json:"evaluationDuration"`
+	EvaluationHistory   []RuleEvaluationDesc `protobuf:"bytes,8,rep,name=evaluationHistory,proto3" json:"evaluationHistory"`
 }
 
 func (m *RuleStateDesc) Reset()      { *m = RuleStateDesc{} }
@@ -442,6 +451,13 @@ func (m *RuleStateDesc) GetEvaluationDuration() time.Duration {
 	return 0
 }
 
+func (m *RuleStateDesc) GetEvaluationHistory() []RuleEvaluationDesc {
+	if m != nil {
+		return m.EvaluationHistory
+	}
+	return nil
+}
+
 type AlertStateDesc struct {
 	State           string                                                      `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
 	Labels          []github_com_cortexproject_cortex_pkg_cortexpb.LabelAdapter `protobuf:"bytes,2,rep,name=labels,proto3,customtype=github.com/cortexproject/cortex/pkg/cortexpb.LabelAdapter" json:"labels"`
@@ -543,6 +559,90 @@ func (m *AlertStateDesc) GetKeepFiringSince() time.Time {
 	return time.Time{}
 }
 
+// RuleEvaluationDesc is a proto representation of the outcome of a rule evaluation
+type RuleEvaluationDesc struct {
+	Timestamp        time.Time     `protobuf:"bytes,1,opt,name=timestamp,proto3,stdtime" json:"timestamp"`
+	Duration         time.Duration `protobuf:"bytes,2,opt,name=duration,proto3,stdduration" json:"duration"`
+	Samples          int64         `protobuf:"varint,3,opt,name=samples,proto3" json:"samples,omitempty"`
+	Health           string        `protobuf:"bytes,4,opt,name=health,proto3" json:"health,omitempty"`
+	LastError        string        `protobuf:"bytes,5,opt,name=lastError,proto3" json:"lastError,omitempty"`
+	MissedIterations int64         `protobuf:"varint,6,opt,name=missedIterations,proto3" json:"missedIterations,omitempty"`
+}
+
+func (m *RuleEvaluationDesc) Reset()      { *m = RuleEvaluationDesc{} }
+func (*RuleEvaluationDesc) ProtoMessage() {}
+func (*RuleEvaluationDesc) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ecbec0a4cfddea6, []int{7}
+}
+func (m *RuleEvaluationDesc) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RuleEvaluationDesc) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RuleEvaluationDesc.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RuleEvaluationDesc) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RuleEvaluationDesc.Merge(m, src)
+}
+func (m *RuleEvaluationDesc) XXX_Size() int {
+	return m.Size()
+}
+func (m *RuleEvaluationDesc) XXX_DiscardUnknown() {
+	xxx_messageInfo_RuleEvaluationDesc.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RuleEvaluationDesc proto.InternalMessageInfo
+
+func (m *RuleEvaluationDesc) GetTimestamp() time.Time {
+	if m != nil {
+		return m.Timestamp
+	}
+	return time.Time{}
+}
+
+func (m *RuleEvaluationDesc) GetDuration() time.Duration {
+	if m != nil {
+		return m.Duration
+	}
+	return 0
+}
+
+func (m *RuleEvaluationDesc) GetSamples() int64 {
+	if m != nil {
+		return m.Samples
+	}
+	return 0
+}
+
+func (m *RuleEvaluationDesc) GetHealth() string {
+	if m != nil {
+		return m.Health
+	}
+	return ""
+}
+
+func (m *RuleEvaluationDesc) GetLastError() string {
+	if m != nil {
+		return m.LastError
+	}
+	return ""
+}
+
+func (m *RuleEvaluationDesc) GetMissedIterations() int64 {
+	if m != nil {
+		return m.MissedIterations
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*RulesRequest)(nil), "ruler.RulesRequest")
 	proto.RegisterType((*LivenessCheckRequest)(nil), "ruler.LivenessCheckRequest")
@@ -551,67 +651,76 @@ func init() {
 	proto.RegisterType((*GroupStateDesc)(nil), "ruler.GroupStateDesc")
 	proto.RegisterType((*RuleStateDesc)(nil), "ruler.RuleStateDesc")
 	proto.RegisterType((*AlertStateDesc)(nil), "ruler.AlertStateDesc")
+	proto.RegisterType((*RuleEvaluationDesc)(nil), "ruler.RuleEvaluationDesc")
 }
 
 func init() { proto.RegisterFile("ruler.proto", fileDescriptor_9ecbec0a4cfddea6) }
 
 var fileDescriptor_9ecbec0a4cfddea6 = []byte{
-	// 880 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x55, 0x4f, 0x6f, 0xe3, 0x44,
-	0x14, 0x8f, 0xd3, 0x26, 0x4d, 0x5e, 0xda, 0xae, 0x98, 0x66, 0x57, 0x26, 0x54, 0x6e, 0x15, 0x10,
-	0xaa, 0x90, 0xd6, 0x91, 0xca, 0x4a, 0x88, 0x03, 0x42, 0x29, 0xbb, 0xcb, 0xa5, 0x42, 0x2b, 0x77,
-	0xe1, 0x84, 0x14, 0x4d, 0x9c, 0x57, 0xc7, 0xd4, 0xb1, 0xcd, 0xcc, 0x38, 0x0a, 0x37, 0xee, 0x5c,
-	0xf6, 0xc8, 0x99, 0x13, 0x67, 0x3e, 0xc5, 0x1e, 0x2b, 0x4e, 0x2b, 0x84, 0x16, 0x9a, 0x5e, 0x38,
-	0xee, 0x47, 0x40, 0xf3, 0xc6, 0x6e, 0xe2, 0x12, 0xa4, 0x56, 0xab, 0x5e, 0x92, 0x79, 0x7f, 0x7e,
-	0xbf, 0x79, 0xff, 0xe6, 0x19, 0x5a, 0x22, 0x8b, 0x50, 0xb8, 0xa9, 0x48, 0x54, 0xc2, 0x6a, 0x24,
-	0x74, 0xda, 0x41, 0x12, 0x24, 0xa4, 0xe9, 0xe9, 0x93, 0x31, 0x76, 0x9c, 0x20, 0x49, 0x82, 0x08,
-	0x7b, 0x24, 0x0d, 0xb3, 0xd3, 0xde, 0x28, 0x13, 0x5c, 0x85, 0x49, 0x9c, 0xdb, 0xf7, 0xae, 0xdb,
-	0x55, 0x38, 0x41, 0xa9, 0xf8, 0x24, 0xcd, 0x1d, 0x3e, 0x0d, 0x42, 0x35, 0xce, 0x86, 0xae, 0x9f,
-	0x4c, 0x7a, 0x7e, 0x22, 0x14, 0xce, 0x52, 0x91, 0x7c, 0x87, 0xbe, 0xca, 0xa5, 0x5e, 0x7a, 0x16,
-	0x14, 0x86, 0x61, 0x7e, 0xc8, 0xa1, 0x9f, 0xdd, 0x04, 0x4a, 0xc1, 0xd3, 0xaf, 0x4c, 0x87, 0xe6,
-	0xdf, 0xc0, 0xbb, 0xbf, 0x55, 0x61, 0xd3, 0xd3, 0xb2, 0x87, 0xdf, 0x67, 0x28, 0x15, 0xdb, 0x85,
-	0xa6, 0xb6, 0x7f, 0xc5, 0x27, 0x28, 0x6d, 0x6b, 0x7f, 0xed, 0xa0, 0xe9, 0x2d, 0x14, 0xec, 0x43,
-	0xd8, 0xd6, 0xc2, 0x97, 0x22, 0xc9, 0x52, 0xe3, 0x52, 0x25, 0x97, 0x6b, 0x5a, 0xd6, 0x86, 0xda,
-	0x69, 0x18, 0xa1, 0xb4, 0xd7, 0xc8, 0x6c, 0x04, 0xc6, 0x60, 0x5d, 0xfd, 0x90, 0xa2, 0xbd, 0xbe,
-	0x6f, 0x1d, 0x34, 0x3d, 0x3a, 0x6b, 0x4f, 0xa9, 0xb8, 0x42, 0xbb, 0x46, 0x4a, 0x23, 0xb0, 0x07,
-	0x50, 0x1f, 0x23, 0x8f, 0xd4, 0xd8, 0xae, 0x93, 0x3a, 0x97, 0x58, 0x07, 0x1a, 0x13, 0xae, 0xfc,
-	0x31, 0x0a, 0x69, 0x6f, 0x10, 0xf5, 0x95, 0xcc, 0x3e, 0x80, 0x2d, 0x9c, 0xf9, 0x51, 0x36, 0xc2,
-	0x7e, 0x84, 0x42, 0x49, 0xbb, 0xb1, 0x6f, 0x1d, 0x34, 0xbc, 0xb2, 0x52, 0x7b, 0x4d, 0xf8, 0xcc,
-	0x2b, 0xc2, 0x95, 0x76, 0x73, 0xdf, 0x3a, 0xa8, 0x79, 0x65, 0xa5, 0xae, 0x42, 0x8c, 0x33, 0xf5,
-	0x3c, 0x39, 0xc3, 0xd8, 0x06, 0x0a, 0x61, 0xa1, 0xe8, 0x3e, 0x80, 0xf6, 0x71, 0x38, 0xc5, 0x18,
-	0xa5, 0xfc, 0x62, 0x8c, 0xfe, 0x59, 0x5e, 0xbb, 0xee, 0x43, 0xb8, 0x7f, 0x4d, 0x2f, 0xd3, 0x24,
-	0x96, 0x4b, 0x49, 0x5a, 0x74, 0x99, 0x11, 0xba, 0xdf, 0xc2, 0x56, 0x5e, 0xfa, 0xdc, 0xed, 0x21,
-	0xd4, 0x03, 0x13, 0x94, 0x2e, 0x7c, 0xeb, 0xf0, 0xbe, 0x6b, 0x46, 0x90, 0x82, 0x3a, 0xd1, 0x98,
-	0xc7, 0x28, 0x7d, 0x2f, 0x77, 0x2a, 0x07, 0x59, 0xbd, 0x1e, 0xe4, 0x2f, 0x55, 0xd8, 0x2e, 0x03,
-	0xd9, 0x47, 0x50, 0x23, 0x28, 0x85, 0xd1, 0x3a, 0x6c, 0xbb, 0x66, 0x12, 0xae, 0xf2, 0x26, 0x76,
-	0xe3, 0xc2, 0x3e, 0x81, 0x4d, 0xee, 0xab, 0x70, 0x8a, 0x03, 0x72, 0xa2, 0x3e, 0x17, 0x10, 0x41,
-	0x90, 0x45, 0x40, 0x2d, 0xe3, 0x49, 0xc9, 0xb0, 0x6f, 0x60, 0x07, 0xa7, 0x3c, 0xca, 0xe8, 0x01,
-	0x3c, 0x2f, 0x06, 0xdd, 0x5e, 0xa3, 0x2b, 0x3b, 0xae, 0x79, 0x0a, 0x6e, 0xf1, 0x14, 0xdc, 0x2b,
-	0x8f, 0xa3, 0xc6, 0xcb, 0xd7, 0x7b, 0x95, 0x17, 0x7f, 0xed, 0x59, 0xde, 0x2a, 0x02, 0x76, 0x02,
-	0x6c, 0xa1, 0x7e, 0x9c, 0x3f, 0x30, 0x1a, 0xa5, 0xd6, 0xe1, 0xbb, 0xff, 0xa1, 0x2d, 0x1c, 0x0c,
-	0xeb, 0xcf, 0x9a, 0x75, 0x05, 0xbc, 0xfb, 0x67, 0xd5, 0xf4, 0x60, 0x51, 0xa3, 0xf7, 0x61, 0x5d,
-	0xa7, 0x98, 0x97, 0xe8, 0xde, 0x52, 0x89, 0x28, 0x55, 0x32, 0x2e, 0xfa, 0x59, 0x5d, 0x3d, 0xb4,
-	0x6b, 0xa5, 0xa1, 0xdd, 0x85, 0x66, 0xc4, 0xa5, 0x7a, 0x22, 0x44, 0x22, 0xf2, 0xd9, 0x5f, 0x28,
-	0x74, 0xd3, 0xb9, 0x99, 0xd7, 0x5a, 0xa9, 0xe9, 0x34, 0xaf, 0x4b, 0x4d, 0x37, 0x4e, 0xff, 0x57,
-	0xde, 0xfa, 0xdd, 0x94, 0x77, 0xe3, 0xed, 0xca, 0xfb, 0x7b, 0x0d, 0xb6, 0xcb, 0x79, 0x94, 0x9f,
-	0xc2, 0x55, 0xe9, 0x62, 0xa8, 0x47, 0x7c, 0x88, 0x51, 0x31, 0x67, 0x3b, 0x6e, 0xb1, 0xed, 0xdc,
-	0x63, 0xad, 0x7f, 0xc6, 0x43, 0x71, 0xd4, 0xd7, 0x77, 0xfd, 0xf1, 0x7a, 0xef, 0x56, 0xdb, 0xd2,
-	0xe0, 0xfb, 0x23, 0x9e, 0x2a, 0x14, 0x5e, 0x7e, 0x0b, 0x9b, 0x41, 0x8b, 0xc7, 0x71, 0xa2, 0x28,
-	0x4c, 0xb3, 0xa5, 0xee, 0xee, 0xd2, 0xe5, 0xab, 0x74, 0xfe, 0xba, 0x4e, 0x66, 0x09, 0x5a, 0x9e,
-	0x11, 0x58, 0x1f, 0x9a, 0xf9, 0x6b, 0xe3, 0x8a, 0x36, 0xe1, 0x4d, 0x7b, 0xd9, 0x30, 0xb0, 0xbe,
-	0x62, 0x9f, 0x43, 0xe3, 0x34, 0x14, 0x38, 0xd2, 0x0c, 0xb7, 0x99, 0x86, 0x0d, 0x42, 0xf5, 0x15,
-	0x7b, 0x02, 0x2d, 0x81, 0x32, 0x89, 0xa6, 0x86, 0x63, 0xe3, 0x16, 0x1c, 0x50, 0x00, 0xfb, 0x8a,
-	0x3d, 0x85, 0x4d, 0x3d, 0xdc, 0x03, 0x89, 0xb1, 0xd2, 0x3c, 0x8d, 0xdb, 0xf0, 0x68, 0xe4, 0x09,
-	0xc6, 0xca, 0x84, 0x33, 0xe5, 0x51, 0x38, 0x1a, 0x64, 0xb1, 0x0a, 0x23, 0x5a, 0xd3, 0x37, 0xa6,
-	0x21, 0xe0, 0xd7, 0x1a, 0xc7, 0x9e, 0xc1, 0x3b, 0x67, 0x88, 0xe9, 0xe0, 0x34, 0x14, 0x61, 0x1c,
-	0x0c, 0x64, 0x18, 0xfb, 0x48, 0x1b, 0xfd, 0xa6, 0x64, 0xf7, 0x34, 0xfc, 0x29, 0xa1, 0x4f, 0x34,
-	0xf8, 0xf0, 0x27, 0x0b, 0x6a, 0x7a, 0x1f, 0x08, 0xf6, 0xc8, 0x1c, 0x24, 0xdb, 0x59, 0x5a, 0x8b,
-	0xc5, 0x97, 0xb4, 0xd3, 0x2e, 0x2b, 0xcd, 0x8e, 0xef, 0x56, 0xd8, 0x31, 0x6c, 0x95, 0xbe, 0x12,
-	0xec, 0xbd, 0xdc, 0x71, 0xd5, 0x37, 0xa5, 0xb3, 0xbb, 0xda, 0x58, 0xb0, 0x1d, 0x3d, 0x3a, 0xbf,
-	0x70, 0x2a, 0xaf, 0x2e, 0x9c, 0xca, 0x9b, 0x0b, 0xc7, 0xfa, 0x71, 0xee, 0x58, 0xbf, 0xce, 0x1d,
-	0xeb, 0xe5, 0xdc, 0xb1, 0xce, 0xe7, 0x8e, 0xf5, 0xf7, 0xdc, 0xb1, 0xfe, 0x99, 0x3b, 0x95, 0x37,
-	0x73, 0xc7, 0x7a, 0x71, 0xe9, 0x54, 0xce, 0x2f, 0x9d, 0xca, 0xab, 0x4b, 0xa7, 0x32, 0xac, 0x53,
-	0xca, 0x1f, 0xff, 0x1b, 0x00, 0x00, 0xff, 0xff, 0x29, 0x54, 0x5d, 0xa5, 0xe4, 0x08, 0x00, 0x00,
+	// 996 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x56, 0x4d, 0x6b, 0x1b, 0xc7,
+	0x1b, 0xd7, 0x4a, 0x5a, 0xbd, 0x3c, 0xb2, 0x9d, 0x7f, 0xc6, 0x4a, 0xd8, 0xe8, 0x6f, 0xd6, 0x46,
+	0x2d, 0x45, 0x04, 0x22, 0x81, 0x1b, 0x28, 0x2d, 0x94, 0x22, 0x35, 0x4e, 0x5b, 0x70, 0x4b, 0x58,
+	0xa7, 0x3d, 0x15, 0xc4, 0x48, 0x1a, 0x4b, 0x5b, 0xaf, 0x76, 0xb7, 0x33, 0xb3, 0x42, 0xb9, 0xf5,
+	0xde, 0x4b, 0x2e, 0x85, 0x9e, 0x7b, 0x2a, 0xf4, 0x8b, 0xe4, 0x68, 0x7a, 0x0a, 0x3d, 0xa4, 0xb5,
+	0x7c, 0xe9, 0xa9, 0xe4, 0x23, 0x94, 0x79, 0x66, 0x57, 0xd2, 0x4a, 0x32, 0x44, 0x94, 0x5c, 0xa4,
+	0x7d, 0x5e, 0x7e, 0xbf, 0x99, 0xe7, 0x75, 0x17, 0x2a, 0x3c, 0xf2, 0x18, 0x6f, 0x86, 0x3c, 0x90,
+	0x01, 0x31, 0x51, 0xa8, 0x55, 0x87, 0xc1, 0x30, 0x40, 0x4d, 0x4b, 0x3d, 0x69, 0x63, 0xcd, 0x1e,
+	0x06, 0xc1, 0xd0, 0x63, 0x2d, 0x94, 0x7a, 0xd1, 0x79, 0x6b, 0x10, 0x71, 0x2a, 0xdd, 0xc0, 0x8f,
+	0xed, 0x87, 0xab, 0x76, 0xe9, 0x8e, 0x99, 0x90, 0x74, 0x1c, 0xc6, 0x0e, 0x1f, 0x0e, 0x5d, 0x39,
+	0x8a, 0x7a, 0xcd, 0x7e, 0x30, 0x6e, 0xf5, 0x03, 0x2e, 0xd9, 0x34, 0xe4, 0xc1, 0x77, 0xac, 0x2f,
+	0x63, 0xa9, 0x15, 0x5e, 0x0c, 0x13, 0x43, 0x2f, 0x7e, 0x88, 0xa1, 0x1f, 0xbf, 0x09, 0x14, 0x2f,
+	0x8f, 0xbf, 0x22, 0xec, 0xe9, 0x7f, 0x0d, 0xaf, 0xff, 0x93, 0x85, 0x1d, 0x47, 0xc9, 0x0e, 0xfb,
+	0x3e, 0x62, 0x42, 0x92, 0x03, 0x28, 0x2b, 0xfb, 0x57, 0x74, 0xcc, 0x84, 0x65, 0x1c, 0xe5, 0x1a,
+	0x65, 0x67, 0xa1, 0x20, 0xef, 0xc1, 0x9e, 0x12, 0x3e, 0xe3, 0x41, 0x14, 0x6a, 0x97, 0x2c, 0xba,
+	0xac, 0x68, 0x49, 0x15, 0xcc, 0x73, 0xd7, 0x63, 0xc2, 0xca, 0xa1, 0x59, 0x0b, 0x84, 0x40, 0x5e,
+	0x3e, 0x0b, 0x99, 0x95, 0x3f, 0x32, 0x1a, 0x65, 0x07, 0x9f, 0x95, 0xa7, 0x90, 0x54, 0x32, 0xcb,
+	0x44, 0xa5, 0x16, 0xc8, 0x5d, 0x28, 0x8c, 0x18, 0xf5, 0xe4, 0xc8, 0x2a, 0xa0, 0x3a, 0x96, 0x48,
+	0x0d, 0x4a, 0x63, 0x2a, 0xfb, 0x23, 0xc6, 0x85, 0x55, 0x44, 0xea, 0xb9, 0x4c, 0xde, 0x85, 0x5d,
+	0x36, 0xed, 0x7b, 0xd1, 0x80, 0xb5, 0x3d, 0xc6, 0xa5, 0xb0, 0x4a, 0x47, 0x46, 0xa3, 0xe4, 0xa4,
+	0x95, 0xca, 0x6b, 0x4c, 0xa7, 0x4e, 0x72, 0x5d, 0x61, 0x95, 0x8f, 0x8c, 0x86, 0xe9, 0xa4, 0x95,
+	0x2a, 0x0b, 0x3e, 0x9b, 0xca, 0xa7, 0xc1, 0x05, 0xf3, 0x2d, 0xc0, 0x2b, 0x2c, 0x14, 0xe4, 0x23,
+	0xb0, 0x5c, 0x1f, 0x49, 0x4f, 0x26, 0xd4, 0x8b, 0xb0, 0xd4, 0x9f, 0xbb, 0x42, 0x06, 0xfc, 0x99,
+	0x55, 0xc1, 0x43, 0x6f, 0xb4, 0xd7, 0xef, 0x42, 0xf5, 0xd4, 0x9d, 0x30, 0x9f, 0x09, 0xf1, 0xe9,
+	0x88, 0xf5, 0x2f, 0xe2, 0xbc, 0xd7, 0x1f, 0xc0, 0x9d, 0x15, 0xbd, 0x08, 0x03, 0x5f, 0x2c, 0x25,
+	0xc8, 0xc0, 0x8b, 0x6a, 0xa1, 0xfe, 0x2d, 0xec, 0xc6, 0x65, 0x8b, 0xdd, 0x1e, 0x40, 0x61, 0xa8,
+	0x03, 0x52, 0x45, 0xab, 0x1c, 0xdf, 0x69, 0xea, 0xf6, 0xc5, 0x80, 0xce, 0x14, 0xe6, 0x11, 0x13,
+	0x7d, 0xa7, 0x30, 0xdc, 0x10, 0x60, 0x76, 0x25, 0xc0, 0xfa, 0x2f, 0x59, 0xd8, 0x4b, 0x03, 0xc9,
+	0x7d, 0x30, 0x11, 0x8a, 0xd7, 0xa8, 0x1c, 0x57, 0x9b, 0xba, 0x8b, 0xe6, 0x39, 0x43, 0x76, 0xed,
+	0x42, 0x3e, 0x80, 0x1d, 0xda, 0x97, 0xee, 0x84, 0x75, 0xd1, 0x09, 0x7b, 0x24, 0x81, 0x70, 0x84,
+	0x2c, 0x2e, 0x54, 0xd1, 0x9e, 0x18, 0x0c, 0xf9, 0x06, 0xf6, 0xd9, 0x3c, 0x63, 0x4f, 0x93, 0x21,
+	0xb1, 0x72, 0x78, 0x64, 0xad, 0xa9, 0xc7, 0xa8, 0x99, 0x8c, 0x51, 0x73, 0xee, 0xd1, 0x29, 0xbd,
+	0x78, 0x75, 0x98, 0x79, 0xfe, 0xe7, 0xa1, 0xe1, 0x6c, 0x22, 0x20, 0x67, 0x40, 0x16, 0xea, 0x47,
+	0xf1, 0x70, 0x62, 0x1b, 0x56, 0x8e, 0xef, 0xad, 0xd1, 0x26, 0x0e, 0x9a, 0xf5, 0x67, 0xc5, 0xba,
+	0x01, 0x5e, 0xff, 0x2d, 0x07, 0xbb, 0xa9, 0x58, 0xc8, 0x3b, 0x90, 0x57, 0x21, 0xc6, 0x29, 0xba,
+	0xb5, 0x94, 0x22, 0x0c, 0x15, 0x8d, 0x8b, 0x7a, 0x66, 0x37, 0x37, 0x7c, 0x2e, 0xd5, 0xf0, 0x07,
+	0x50, 0xf6, 0xa8, 0x90, 0x27, 0x9c, 0x07, 0x3c, 0x9e, 0x9b, 0x85, 0x42, 0x15, 0x9d, 0xea, 0x5e,
+	0x37, 0x53, 0x45, 0xc7, 0x5e, 0x5f, 0x2a, 0xba, 0x76, 0xba, 0x29, 0xbd, 0x85, 0xb7, 0x93, 0xde,
+	0xe2, 0x7f, 0x4a, 0x2f, 0xf9, 0x12, 0x6e, 0xb3, 0xb5, 0xe9, 0x2a, 0x61, 0x98, 0xf7, 0x96, 0x3a,
+	0x69, 0x31, 0x61, 0x2a, 0xd4, 0x4e, 0x5e, 0x71, 0x3a, 0xeb, 0xc8, 0xfa, 0xef, 0x26, 0xec, 0xa5,
+	0xd3, 0x92, 0x9e, 0xac, 0x79, 0x25, 0x7c, 0x28, 0x78, 0xb4, 0xc7, 0xbc, 0xa4, 0x6d, 0xf7, 0x9b,
+	0xc9, 0xe2, 0x6d, 0x9e, 0x2a, 0xfd, 0x13, 0xea, 0xf2, 0x4e, 0x5b, 0x1d, 0xf3, 0xc7, 0xab, 0xc3,
+	0xad, 0x16, 0xb7, 0xc6, 0xb7, 0x07, 0x34, 0x94, 0x8c, 0x3b, 0xf1, 0x29, 0x64, 0x0a, 0x15, 0xea,
+	0xfb, 0x81, 0xc4, 0xdb, 0xea, 0x85, 0xf9, 0xf6, 0x0e, 0x5d, 0x3e, 0x4a, 0xc5, 0xaf, 0xd2, 0xa4,
+	0xf7, 0xb1, 0xe1, 0x68, 0x81, 0xb4, 0xa1, 0x1c, 0x0f, 0x2f, 0x95, 0x96, 0xb9, 0x45, 0x6b, 0x94,
+	0x34, 0xac, 0x2d, 0xc9, 0x27, 0x50, 0x3a, 0x77, 0x39, 0x1b, 0x28, 0x86, 0x6d, 0x9a, 0xab, 0x88,
+	0xa8, 0xb6, 0x24, 0x27, 0x50, 0xe1, 0x4c, 0x04, 0xde, 0x44, 0x73, 0x14, 0xb7, 0xe0, 0x80, 0x04,
+	0xd8, 0x96, 0xe4, 0x31, 0xec, 0xa8, 0x59, 0xe9, 0x0a, 0xe6, 0x4b, 0xc5, 0x53, 0xda, 0x86, 0x47,
+	0x21, 0xcf, 0x98, 0x2f, 0xf5, 0x75, 0x26, 0xd4, 0x73, 0x07, 0xdd, 0xc8, 0x97, 0xae, 0x67, 0x95,
+	0xb7, 0xa1, 0x41, 0xe0, 0xd7, 0x0a, 0x47, 0x9e, 0xc0, 0xed, 0x0b, 0xc6, 0xc2, 0xee, 0xb9, 0xcb,
+	0x5d, 0x7f, 0xd8, 0x15, 0xae, 0xdf, 0x67, 0x16, 0x6c, 0x41, 0x76, 0x4b, 0xc1, 0x1f, 0x23, 0xfa,
+	0x4c, 0x81, 0xeb, 0x3f, 0x65, 0x81, 0xac, 0x0f, 0x01, 0xe9, 0x40, 0x79, 0xfe, 0x85, 0x61, 0x19,
+	0x5b, 0x1c, 0xb0, 0x80, 0xa9, 0x1a, 0x26, 0x5f, 0x31, 0xb8, 0xa9, 0xde, 0x70, 0x92, 0xe7, 0x20,
+	0x62, 0x41, 0x51, 0xd0, 0x71, 0xa8, 0x3f, 0x02, 0x8c, 0x46, 0xce, 0x49, 0xc4, 0xa5, 0x5d, 0x97,
+	0xbf, 0x79, 0xd7, 0x99, 0xab, 0xbb, 0xee, 0x3e, 0xfc, 0x6f, 0xec, 0x0a, 0xc1, 0x06, 0x5f, 0x48,
+	0xc6, 0xe3, 0x61, 0x29, 0x20, 0xf1, 0x9a, 0xfe, 0xf8, 0x47, 0x03, 0x4c, 0x95, 0x17, 0x4e, 0x1e,
+	0xea, 0x07, 0x41, 0xf6, 0x97, 0x76, 0x46, 0xf2, 0xb1, 0x53, 0xab, 0xa6, 0x95, 0xfa, 0x55, 0x5a,
+	0xcf, 0x90, 0x53, 0xd8, 0x4d, 0xbd, 0x8c, 0xc9, 0xff, 0x63, 0xc7, 0x4d, 0xaf, 0xee, 0xda, 0xc1,
+	0x66, 0x63, 0xc2, 0xd6, 0x79, 0x78, 0x79, 0x65, 0x67, 0x5e, 0x5e, 0xd9, 0x99, 0xd7, 0x57, 0xb6,
+	0xf1, 0xc3, 0xcc, 0x36, 0x7e, 0x9d, 0xd9, 0xc6, 0x8b, 0x99, 0x6d, 0x5c, 0xce, 0x6c, 0xe3, 0xaf,
+	0x99, 0x6d, 0xfc, 0x3d, 0xb3, 0x33, 0xaf, 0x67, 0xb6, 0xf1, 0xfc, 0xda, 0xce, 0x5c, 0x5e, 0xdb,
+	0x99, 0x97, 0xd7, 0x76, 0xa6, 0x57, 0xc0, 0x34, 0xbf, 0xff, 0xef, 0x00, 0xa7, 0x0a, 0x28, 0x53,
+	0x87, 0x0a, 0x00, 0x00,
 }
 
 func (this *RulesRequest) Equal(that interface{}) bool {
@@ -683,6 +792,9 @@ func (this *RulesRequest) Equal(that interface{}) bool {
 	if this.NextToken != that1.NextToken {
 		return false
 	}
+	if this.IncludeEvaluationHistory != that1.IncludeEvaluationHistory {
+		return false
+	}
 	return true
 }
 func (this *LivenessCheckRequest) Equal(that interface{}) bool {
@@ -845,6 +957,14 @@ func (this *RuleStateDesc) Equal(that interface{}) bool {
 	if this.EvaluationDuration != that1.EvaluationDuration {
 		return false
 	}
+	if len(this.EvaluationHistory) != len(that1.EvaluationHistory) {
+		return false
+	}
+	for i := range this.EvaluationHistory {
+		if !this.EvaluationHistory[i].Equal(&that1.EvaluationHistory[i]) {
+			return false
+		}
+	}
 	return true
 }
 func (this *AlertStateDesc) Equal(that interface{}) bool {
@@ -908,11 +1028,50 @@ func (this *AlertStateDesc) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *RuleEvaluationDesc) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*RuleEvaluationDesc)
+	if !ok {
+		that2, ok := that.(RuleEvaluationDesc)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !this.Timestamp.Equal(that1.Timestamp) {
+		return false
+	}
+	if this.Duration != that1.Duration {
+		return false
+	}
+	if this.Samples != that1.Samples {
+		return false
+	}
+	if this.Health != that1.Health {
+		return false
+	}
+	if this.LastError != that1.LastError {
+		return false
+	}
+	if this.MissedIterations != that1.MissedIterations {
+		return false
+	}
+	return true
+}
 func (this *RulesRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 14)
+	s := make([]string, 0, 15)
 	s = append(s, "&ruler.RulesRequest{")
 	s = append(s, "RuleNames: "+fmt.Sprintf("%#v", this.RuleNames)+",\n")
 	s = append(s, "RuleGroupNames: "+fmt.Sprintf("%#v", this.RuleGroupNames)+",\n")
@@ -924,6 +1083,7 @@ func (this *RulesRequest) GoString() string {
 	s = append(s, "ExcludeAlerts: "+fmt.Sprintf("%#v", this.ExcludeAlerts)+",\n")
 	s = append(s, "MaxRuleGroups: "+fmt.Sprintf("%#v", this.MaxRuleGroups)+",\n")
 	s = append(s, "NextToken: "+fmt.Sprintf("%#v", this.NextToken)+",\n")
+	s = append(s, "IncludeEvaluationHistory: "+fmt.Sprintf("%#v", this.IncludeEvaluationHistory)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -980,7 +1140,7 @@ func (this *RuleStateDesc) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 11)
+	s := make([]string, 0, 12)
 	s = append(s, "&ruler.RuleStateDesc{")
 	if this.Rule != nil {
 		s = append(s, "Rule: "+fmt.Sprintf("%#v", this.Rule)+",\n")
@@ -993,6 +1153,13 @@ func (this *RuleStateDesc) GoString() string {
 	}
 	s = append(s, "EvaluationTimestamp: "+fmt.Sprintf("%#v", this.EvaluationTimestamp)+",\n")
 	s = append(s, "EvaluationDuration: "+fmt.Sprintf("%#v", this.EvaluationDuration)+",\n")
+	if this.EvaluationHistory != nil {
+		vs := make([]*RuleEvaluationDesc, len(this.EvaluationHistory))
+		for i := range vs {
+			vs[i] = &this.EvaluationHistory[i]
+		}
+		s = append(s, "EvaluationHistory: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -1015,6 +1182,21 @@ func (this *AlertStateDesc) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *RuleEvaluationDesc) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 10)
+	s = append(s, "&ruler.RuleEvaluationDesc{")
+	s = append(s, "Timestamp: "+fmt.Sprintf("%#v", this.Timestamp)+",\n")
+	s = append(s, "Duration: "+fmt.Sprintf("%#v", this.Duration)+",\n")
+	s = append(s, "Samples: "+fmt.Sprintf("%#v", this.Samples)+",\n")
+	s = append(s, "Health: "+fmt.Sprintf("%#v", this.Health)+",\n")
+	s = append(s, "LastError: "+fmt.Sprintf("%#v", this.LastError)+",\n")
+	s = append(s, "MissedIterations: "+fmt.Sprintf("%#v", this.MissedIterations)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func valueToGoStringRuler(v interface{}, typ string) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -1160,6 +1342,16 @@ func (m *RulesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.IncludeEvaluationHistory {
+		i--
+		if m.IncludeEvaluationHistory {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x58
+	}
 	if len(m.NextToken) > 0 {
 		i -= len(m.NextToken)
 		copy(dAtA[i:], m.NextToken)
@@ -1422,6 +1614,20 @@ func (m *RuleStateDesc) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.EvaluationHistory) > 0 {
+		for iNdEx := len(m.EvaluationHistory) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.EvaluationHistory[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintRuler(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x42
+		}
+	}
 	n4, err4 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.EvaluationDuration, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.EvaluationDuration):])
 	if err4 != nil {
 		return 0, err4
@@ -1600,6 +1806,69 @@ func (m *AlertStateDesc) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *RuleEvaluationDesc) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RuleEvaluationDesc) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RuleEvaluationDesc) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.MissedIterations != 0 {
+		i = encodeVarintRuler(dAtA, i, uint64(m.MissedIterations))
+		i--
+		dAtA[i] = 0x30
+	}
+	if len(m.LastError) > 0 {
+		i -= len(m.LastError)
+		copy(dAtA[i:], m.LastError)
+		i = encodeVarintRuler(dAtA, i, uint64(len(m.LastError)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.Health) > 0 {
+		i -= len(m.Health)
+		copy(dAtA[i:], m.Health)
+		i = encodeVarintRuler(dAtA, i, uint64(len(m.Health)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if m.Samples != 0 {
+		i = encodeVarintRuler(dAtA, i, uint64(m.Samples))
+		i--
+		dAtA[i] = 0x18
+	}
+	n13, err13 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.Duration, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.Duration):])
+	if err13 != nil {
+		return 0, err13
+	}
+	i -= n13
+	i = encodeVarintRuler(dAtA, i, uint64(n13))
+	i--
+	dAtA[i] = 0x12
+	n14, err14 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.Timestamp, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.Timestamp):])
+	if err14 != nil {
+		return 0, err14
+	}
+	i -= n14
+	i = encodeVarintRuler(dAtA, i, uint64(n14))
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintRuler(dAtA []byte, offset int, v uint64) int {
 	offset -= sovRuler(v)
 	base := offset
@@ -1663,6 +1932,9 @@ func (m *RulesRequest) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovRuler(uint64(l))
 	}
+	if m.IncludeEvaluationHistory {
+		n += 2
+	}
 	return n
 }
 
@@ -1761,6 +2033,12 @@ func (m *RuleStateDesc) Size() (n int) {
 	n += 1 + l + sovRuler(uint64(l))
 	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.EvaluationDuration)
 	n += 1 + l + sovRuler(uint64(l))
+	if len(m.EvaluationHistory) > 0 {
+		for _, e := range m.EvaluationHistory {
+			l = e.Size()
+			n += 1 + l + sovRuler(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -1804,6 +2082,33 @@ func (m *AlertStateDesc) Size() (n int) {
 	return n
 }
 
+func (m *RuleEvaluationDesc) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = github_com_gogo_protobuf_types.SizeOfStdTime(m.Timestamp)
+	n += 1 + l + sovRuler(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.Duration)
+	n += 1 + l + sovRuler(uint64(l))
+	if m.Samples != 0 {
+		n += 1 + sovRuler(uint64(m.Samples))
+	}
+	l = len(m.Health)
+	if l > 0 {
+		n += 1 + l + sovRuler(uint64(l))
+	}
+	l = len(m.LastError)
+	if l > 0 {
+		n += 1 + l + sovRuler(uint64(l))
+	}
+	if m.MissedIterations != 0 {
+		n += 1 + sovRuler(uint64(m.MissedIterations))
+	}
+	return n
+}
+
 func sovRuler(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
 }
@@ -1825,6 +2130,7 @@ func (this *RulesRequest) String() string {
 		`ExcludeAlerts:` + fmt.Sprintf("%v", this.ExcludeAlerts) + `,`,
 		`MaxRuleGroups:` + fmt.Sprintf("%v", this.MaxRuleGroups) + `,`,
 		`NextToken:` + fmt.Sprintf("%v", this.NextToken) + `,`,
+		`IncludeEvaluationHistory:` + fmt.Sprintf("%v", this.IncludeEvaluationHistory) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1891,6 +2197,11 @@ func (this *RuleStateDesc) String() string {
 		repeatedStringForAlerts += strings.Replace(f.String(), "AlertStateDesc", "AlertStateDesc", 1) + ","
 	}
 	repeatedStringForAlerts += "}"
+	repeatedStringForEvaluationHistory := "[]RuleEvaluationDesc{"
+	for _, f := range this.EvaluationHistory {
+		repeatedStringForEvaluationHistory += strings.Replace(strings.Replace(f.String(), "RuleEvaluationDesc", "RuleEvaluationDesc", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForEvaluationHistory += "}"
 	s := strings.Join([]string{`&RuleStateDesc{`,
 		`Rule:` + strings.Replace(fmt.Sprintf("%v", this.Rule), "RuleDesc", "rulespb.RuleDesc", 1) + `,`,
 		`State:` + fmt.Sprintf("%v", this.State) + `,`,
@@ -1899,6 +2210,7 @@ func (this *RuleStateDesc) String() string {
 		`Alerts:` + repeatedStringForAlerts + `,`,
 		`EvaluationTimestamp:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.EvaluationTimestamp), "Timestamp", "timestamppb.Timestamp", 1), `&`, ``, 1) + `,`,
 		`EvaluationDuration:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.EvaluationDuration), "Duration", "durationpb.Duration", 1), `&`, ``, 1) + `,`,
+		`EvaluationHistory:` + repeatedStringForEvaluationHistory + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1922,6 +2234,21 @@ func (this *AlertStateDesc) String() string {
 	}, "")
 	return s
 }
+func (this *RuleEvaluationDesc) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&RuleEvaluationDesc{`,
+		`Timestamp:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.Timestamp), "Timestamp", "timestamppb.Timestamp", 1), `&`, ``, 1) + `,`,
+		`Duration:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.Duration), "Duration", "durationpb.Duration", 1), `&`, ``, 1) + `,`,
+		`Samples:` + fmt.Sprintf("%v", this.Samples) + `,`,
+		`Health:` + fmt.Sprintf("%v", this.Health) + `,`,
+		`LastError:` + fmt.Sprintf("%v", this.LastError) + `,`,
+		`MissedIterations:` + fmt.Sprintf("%v", this.MissedIterations) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func valueToStringRuler(v interface{}) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -2254,6 +2581,26 @@ func (m *RulesRequest) Unmarshal(dAtA []byte) error {
 			}
 			m.NextToken = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IncludeEvaluationHistory", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRuler
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IncludeEvaluationHistory = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRuler(dAtA[iNdEx:])
@@ -2972,6 +3319,40 @@ func (m *RuleStateDesc) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EvaluationHistory", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRuler
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRuler
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRuler
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.EvaluationHistory = append(m.EvaluationHistory, RuleEvaluationDesc{})
+			if err := m.EvaluationHistory[len(m.EvaluationHistory)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRuler(dAtA[iNdEx:])
@@ -3358,6 +3739,227 @@ func (m *AlertStateDesc) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *RuleEvaluationDesc) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRuler
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RuleEvaluationDesc: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RuleEvaluationDesc: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRuler
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRuler
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRuler
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(&m.Timestamp, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Duration", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRuler
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRuler
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRuler
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.Duration, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Samples", wireType)
+			}
+			m.Samples = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRuler
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Samples |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Health", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRuler
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRuler
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRuler
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Health = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LastError", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRuler
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRuler
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRuler
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.LastError = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MissedIterations", wireType)
+			}
+			m.MissedIterations = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRuler
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MissedIterations |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRuler(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthRuler
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthRuler
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipRuler(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
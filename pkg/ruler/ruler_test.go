@@ -95,6 +95,7 @@ type ruleLimits struct {
 	maxQueryLength       time.Duration
 	queryOffset          time.Duration
 	externalLabels       labels.Labels
+	alertmanagerConfig   validation.RulerAlertmanagerConfig
 }
 
 func (r *ruleLimits) setRulerExternalLabels(lset labels.Labels) {
@@ -103,6 +104,12 @@ func (r *ruleLimits) setRulerExternalLabels(lset labels.Labels) {
 	r.mtx.Unlock()
 }
 
+func (r *ruleLimits) setRulerAlertmanagerConfig(cfg validation.RulerAlertmanagerConfig) {
+	r.mtx.Lock()
+	r.alertmanagerConfig = cfg
+	r.mtx.Unlock()
+}
+
 func (r *ruleLimits) RulerTenantShardSize(_ string) float64 {
 	r.mtx.RLock()
 	defer r.mtx.RUnlock()
@@ -145,6 +152,12 @@ func (r *ruleLimits) RulerExternalLabels(_ string) labels.Labels {
 	return r.externalLabels
 }
 
+func (r *ruleLimits) RulerAlertmanagerConfig(_ string) validation.RulerAlertmanagerConfig {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.alertmanagerConfig
+}
+
 func newEmptyQueryable() storage.Queryable {
 	return storage.QueryableFunc(func(mint, maxt int64) (storage.Querier, error) {
 		return emptyQuerier{}, nil
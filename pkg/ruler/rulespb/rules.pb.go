@@ -4,6 +4,7 @@
 package rulespb
 
 import (
+	encoding_binary "encoding/binary"
 	fmt "fmt"
 	_ "github.com/cortexproject/cortex/pkg/cortexpb"
 	github_com_cortexproject_cortex_pkg_cortexpb "github.com/cortexproject/cortex/pkg/cortexpb"
@@ -215,50 +216,153 @@ func (m *RuleDesc) GetKeepFiringFor() time.Duration {
 	return 0
 }
 
+// SLODesc is a proto representation of a service level objective, stored in
+// the options of the rule group generated from it.
+type SLODesc struct {
+	Name        string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Service     string `protobuf:"bytes,2,opt,name=service,proto3" json:"service,omitempty"`
+	Description string `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	// The queries returning the rate of good and total events. The $window
+	// placeholder is replaced by the range of each generated recording rule.
+	GoodQuery  string                                                      `protobuf:"bytes,4,opt,name=good_query,json=goodQuery,proto3" json:"good_query,omitempty"`
+	TotalQuery string                                                      `protobuf:"bytes,5,opt,name=total_query,json=totalQuery,proto3" json:"total_query,omitempty"`
+	Objective  float64                                                     `protobuf:"fixed64,6,opt,name=objective,proto3" json:"objective,omitempty"`
+	Window     time.Duration                                               `protobuf:"bytes,7,opt,name=window,proto3,stdduration" json:"window"`
+	Labels     []github_com_cortexproject_cortex_pkg_cortexpb.LabelAdapter `protobuf:"bytes,8,rep,name=labels,proto3,customtype=github.com/cortexproject/cortex/pkg/cortexpb.LabelAdapter" json:"labels"`
+}
+
+func (m *SLODesc) Reset()      { *m = SLODesc{} }
+func (*SLODesc) ProtoMessage() {}
+func (*SLODesc) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8e722d3e922f0937, []int{2}
+}
+func (m *SLODesc) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SLODesc) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SLODesc.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SLODesc) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SLODesc.Merge(m, src)
+}
+func (m *SLODesc) XXX_Size() int {
+	return m.Size()
+}
+func (m *SLODesc) XXX_DiscardUnknown() {
+	xxx_messageInfo_SLODesc.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SLODesc proto.InternalMessageInfo
+
+func (m *SLODesc) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SLODesc) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *SLODesc) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *SLODesc) GetGoodQuery() string {
+	if m != nil {
+		return m.GoodQuery
+	}
+	return ""
+}
+
+func (m *SLODesc) GetTotalQuery() string {
+	if m != nil {
+		return m.TotalQuery
+	}
+	return ""
+}
+
+func (m *SLODesc) GetObjective() float64 {
+	if m != nil {
+		return m.Objective
+	}
+	return 0
+}
+
+func (m *SLODesc) GetWindow() time.Duration {
+	if m != nil {
+		return m.Window
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*RuleGroupDesc)(nil), "rules.RuleGroupDesc")
 	proto.RegisterType((*RuleDesc)(nil), "rules.RuleDesc")
+	proto.RegisterType((*SLODesc)(nil), "rules.SLODesc")
 }
 
 func init() { proto.RegisterFile("rules.proto", fileDescriptor_8e722d3e922f0937) }
 
 var fileDescriptor_8e722d3e922f0937 = []byte{
-	// 554 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x53, 0x31, 0x6f, 0xd3, 0x40,
-	0x18, 0xf5, 0x61, 0xc7, 0x75, 0xce, 0x44, 0xad, 0x8e, 0x08, 0xb9, 0x05, 0x5d, 0xa2, 0x4a, 0x48,
-	0x99, 0x1c, 0xa9, 0x88, 0x81, 0x01, 0xa1, 0x44, 0xa5, 0x48, 0x11, 0x12, 0xc8, 0x23, 0x42, 0xaa,
-	0x6c, 0xe7, 0x6c, 0x4c, 0x1d, 0x9f, 0x39, 0x9f, 0x51, 0xb3, 0xf1, 0x13, 0x18, 0xf9, 0x09, 0xfc,
-	0x94, 0x0e, 0x0c, 0x61, 0xab, 0x18, 0x02, 0x71, 0x16, 0xc4, 0xd4, 0x9f, 0x80, 0xee, 0xce, 0x86,
-	0x00, 0x03, 0x65, 0x80, 0x29, 0xdf, 0x77, 0xef, 0xde, 0x7d, 0xef, 0x7b, 0x2f, 0x86, 0x36, 0x2b,
-	0x53, 0x52, 0xb8, 0x39, 0xa3, 0x9c, 0xa2, 0x96, 0x6c, 0xf6, 0xba, 0x31, 0x8d, 0xa9, 0x3c, 0x19,
-	0x8a, 0x4a, 0x81, 0x7b, 0x38, 0xa6, 0x34, 0x4e, 0xc9, 0x50, 0x76, 0x41, 0x19, 0x0d, 0xa7, 0x25,
-	0xf3, 0x79, 0x42, 0xb3, 0x1a, 0xdf, 0xfd, 0x15, 0xf7, 0xb3, 0x79, 0x0d, 0xdd, 0x8d, 0x13, 0xfe,
-	0xbc, 0x0c, 0xdc, 0x90, 0xce, 0x86, 0x21, 0x65, 0x9c, 0x9c, 0xe6, 0x8c, 0xbe, 0x20, 0x21, 0xaf,
-	0xbb, 0x61, 0x7e, 0x12, 0x37, 0x40, 0x50, 0x17, 0x8a, 0xba, 0xff, 0x5e, 0x87, 0x1d, 0xaf, 0x4c,
-	0xc9, 0x43, 0x46, 0xcb, 0xfc, 0x90, 0x14, 0x21, 0x42, 0xd0, 0xc8, 0xfc, 0x19, 0x71, 0x40, 0x1f,
-	0x0c, 0xda, 0x9e, 0xac, 0xd1, 0x4d, 0xd8, 0x16, 0xbf, 0x45, 0xee, 0x87, 0xc4, 0xb9, 0x22, 0x81,
-	0x1f, 0x07, 0xe8, 0x3e, 0xb4, 0x92, 0x8c, 0x13, 0xf6, 0xca, 0x4f, 0x1d, 0xbd, 0x0f, 0x06, 0xf6,
-	0xc1, 0xae, 0xab, 0xc4, 0xba, 0x8d, 0x58, 0xf7, 0xb0, 0x5e, 0x66, 0x6c, 0x9d, 0x2d, 0x7b, 0xda,
-	0xdb, 0x4f, 0x3d, 0xe0, 0x7d, 0x27, 0xa1, 0x5b, 0x50, 0x39, 0xe3, 0x18, 0x7d, 0x7d, 0x60, 0x1f,
-	0x6c, 0xbb, 0xca, 0x34, 0xa1, 0x4b, 0x48, 0xf2, 0x14, 0x2a, 0x94, 0x95, 0x05, 0x61, 0x8e, 0xa9,
-	0x94, 0x89, 0x1a, 0xb9, 0x70, 0x8b, 0xe6, 0xe2, 0xe1, 0xc2, 0x69, 0x4b, 0x72, 0xf7, 0xb7, 0xd1,
-	0xa3, 0x6c, 0xee, 0x35, 0x97, 0x50, 0x17, 0xb6, 0xd2, 0x64, 0x96, 0x70, 0x07, 0xf6, 0xc1, 0x40,
-	0xf7, 0x54, 0x83, 0x1e, 0x40, 0xfb, 0x65, 0x49, 0xd8, 0xfc, 0x71, 0x14, 0x15, 0x84, 0x3b, 0xf6,
-	0x65, 0x96, 0x00, 0x72, 0x89, 0x4d, 0x1e, 0xca, 0xa0, 0x99, 0xfa, 0x01, 0x49, 0x0b, 0xe7, 0xaa,
-	0xd4, 0x72, 0xcd, 0x6d, 0x4c, 0x77, 0x1f, 0x89, 0xf3, 0x27, 0x7e, 0xc2, 0xc6, 0x23, 0x61, 0xc0,
-	0xc7, 0x65, 0xef, 0xaf, 0x42, 0x53, 0xfc, 0xd1, 0xd4, 0xcf, 0x39, 0x61, 0x5e, 0x3d, 0x65, 0x62,
-	0x58, 0xad, 0x1d, 0x73, 0x62, 0x58, 0x5b, 0x3b, 0xd6, 0xc4, 0xb0, 0xac, 0x9d, 0xf6, 0xfe, 0x07,
-	0x1d, 0x5a, 0x8d, 0x6d, 0xc2, 0x2f, 0xf1, 0x68, 0x93, 0xa4, 0xa8, 0xd1, 0x75, 0x68, 0x32, 0x12,
-	0x52, 0x36, 0xad, 0x63, 0xac, 0x3b, 0xe1, 0x8b, 0x9f, 0x12, 0xc6, 0x65, 0x80, 0x6d, 0x4f, 0x35,
-	0xe8, 0x0e, 0xd4, 0x23, 0xca, 0x1c, 0xe3, 0xf2, 0xa1, 0x8a, 0xfb, 0x1b, 0x3e, 0xb4, 0xfe, 0x87,
-	0x0f, 0xe8, 0x14, 0xda, 0x7e, 0x96, 0x51, 0xee, 0xab, 0x3f, 0x82, 0xf9, 0x4f, 0x87, 0x6e, 0x8e,
-	0x42, 0xcf, 0x60, 0xe7, 0x84, 0x90, 0xfc, 0x28, 0x61, 0x49, 0x16, 0x1f, 0x51, 0xe6, 0x74, 0xfe,
-	0x64, 0xd5, 0x0d, 0xa1, 0xe0, 0xeb, 0xb2, 0xb7, 0x2d, 0x78, 0xc7, 0x91, 0x24, 0x1e, 0x47, 0x94,
-	0x49, 0xf7, 0x7e, 0x7e, 0x4c, 0x26, 0xdb, 0x19, 0xdf, 0x5b, 0xac, 0xb0, 0x76, 0xbe, 0xc2, 0xda,
-	0xc5, 0x0a, 0x83, 0xd7, 0x15, 0x06, 0xef, 0x2a, 0x0c, 0xce, 0x2a, 0x0c, 0x16, 0x15, 0x06, 0x9f,
-	0x2b, 0x0c, 0xbe, 0x54, 0x58, 0xbb, 0xa8, 0x30, 0x78, 0xb3, 0xc6, 0xda, 0x62, 0x8d, 0xb5, 0xf3,
-	0x35, 0xd6, 0x9e, 0x6e, 0xc9, 0x6f, 0x26, 0x0f, 0x02, 0x53, 0x6a, 0xb8, 0xfd, 0x2d, 0x00, 0x00,
-	0xff, 0xff, 0x39, 0x21, 0xc0, 0xef, 0x8a, 0x04, 0x00, 0x00,
+	// 656 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x54, 0x4f, 0x6b, 0xd4, 0x40,
+	0x14, 0xcf, 0x74, 0x77, 0xb3, 0xc9, 0x8b, 0x4b, 0xcb, 0x58, 0x24, 0xad, 0x75, 0x76, 0x29, 0x08,
+	0x7b, 0xca, 0x42, 0xc5, 0x83, 0x88, 0x48, 0x4b, 0xad, 0x50, 0x0a, 0xd5, 0x78, 0x13, 0xa1, 0x24,
+	0xd9, 0xd9, 0x18, 0x9b, 0x66, 0xe2, 0x64, 0xd2, 0x3f, 0x37, 0x3f, 0x82, 0x47, 0x3f, 0x82, 0x1f,
+	0xa5, 0x07, 0x0f, 0xf5, 0x56, 0x3c, 0x54, 0x9b, 0x7a, 0x10, 0x4f, 0xfd, 0x08, 0x32, 0x93, 0xa4,
+	0x5d, 0x15, 0x71, 0x3d, 0xd8, 0x53, 0xde, 0x7b, 0xbf, 0x79, 0x33, 0xbf, 0xf9, 0xfd, 0xde, 0x04,
+	0x2c, 0x9e, 0xc7, 0x34, 0x73, 0x52, 0xce, 0x04, 0xc3, 0x2d, 0x95, 0xcc, 0xcf, 0x86, 0x2c, 0x64,
+	0xaa, 0x32, 0x90, 0x51, 0x09, 0xce, 0x93, 0x90, 0xb1, 0x30, 0xa6, 0x03, 0x95, 0xf9, 0xf9, 0x68,
+	0x30, 0xcc, 0xb9, 0x27, 0x22, 0x96, 0x54, 0xf8, 0xdc, 0xaf, 0xb8, 0x97, 0x1c, 0x54, 0xd0, 0xbd,
+	0x30, 0x12, 0x2f, 0x73, 0xdf, 0x09, 0xd8, 0xce, 0x20, 0x60, 0x5c, 0xd0, 0xfd, 0x94, 0xb3, 0x57,
+	0x34, 0x10, 0x55, 0x36, 0x48, 0xb7, 0xc3, 0x1a, 0xf0, 0xab, 0xa0, 0x6c, 0x5d, 0xfc, 0xd0, 0x80,
+	0x8e, 0x9b, 0xc7, 0xf4, 0x31, 0x67, 0x79, 0xba, 0x4a, 0xb3, 0x00, 0x63, 0x68, 0x26, 0xde, 0x0e,
+	0xb5, 0x51, 0x0f, 0xf5, 0x4d, 0x57, 0xc5, 0x78, 0x01, 0x4c, 0xf9, 0xcd, 0x52, 0x2f, 0xa0, 0xf6,
+	0x94, 0x02, 0x2e, 0x0b, 0xf8, 0x21, 0x18, 0x51, 0x22, 0x28, 0xdf, 0xf5, 0x62, 0xbb, 0xd1, 0x43,
+	0x7d, 0x6b, 0x69, 0xce, 0x29, 0xc9, 0x3a, 0x35, 0x59, 0x67, 0xb5, 0xba, 0xcc, 0x8a, 0x71, 0x78,
+	0xd2, 0xd5, 0xde, 0x7d, 0xee, 0x22, 0xf7, 0xa2, 0x09, 0xdf, 0x86, 0x52, 0x19, 0xbb, 0xd9, 0x6b,
+	0xf4, 0xad, 0xa5, 0x69, 0x47, 0x65, 0x8e, 0xe4, 0x25, 0x29, 0xb9, 0x25, 0x2a, 0x99, 0xe5, 0x19,
+	0xe5, 0xb6, 0x5e, 0x32, 0x93, 0x31, 0x76, 0xa0, 0xcd, 0x52, 0xb9, 0x71, 0x66, 0x9b, 0xaa, 0x79,
+	0xf6, 0xb7, 0xa3, 0x97, 0x93, 0x03, 0xb7, 0x5e, 0x84, 0x67, 0xa1, 0x15, 0x47, 0x3b, 0x91, 0xb0,
+	0xa1, 0x87, 0xfa, 0x0d, 0xb7, 0x4c, 0xf0, 0x23, 0xb0, 0x5e, 0xe7, 0x94, 0x1f, 0x6c, 0x8e, 0x46,
+	0x19, 0x15, 0xb6, 0x35, 0xc9, 0x25, 0x90, 0xba, 0xc4, 0x78, 0x1f, 0x4e, 0x40, 0x8f, 0x3d, 0x9f,
+	0xc6, 0x99, 0x7d, 0x4d, 0x71, 0xb9, 0xee, 0xd4, 0xa2, 0x3b, 0x1b, 0xb2, 0xfe, 0xc4, 0x8b, 0xf8,
+	0xca, 0xb2, 0x14, 0xe0, 0xd3, 0x49, 0xf7, 0x9f, 0x4c, 0x2b, 0xfb, 0x97, 0x87, 0x5e, 0x2a, 0x28,
+	0x77, 0xab, 0x53, 0xd6, 0x9b, 0x46, 0x6b, 0x46, 0x5f, 0x6f, 0x1a, 0xed, 0x19, 0x63, 0xbd, 0x69,
+	0x18, 0x33, 0xe6, 0xe2, 0xc7, 0x06, 0x18, 0xb5, 0x6c, 0x52, 0x2f, 0xb9, 0x69, 0xed, 0xa4, 0x8c,
+	0xf1, 0x0d, 0xd0, 0x39, 0x0d, 0x18, 0x1f, 0x56, 0x36, 0x56, 0x99, 0xd4, 0xc5, 0x8b, 0x29, 0x17,
+	0xca, 0x40, 0xd3, 0x2d, 0x13, 0x7c, 0x17, 0x1a, 0x23, 0xc6, 0xed, 0xe6, 0xe4, 0xa6, 0xca, 0xf5,
+	0x63, 0x3a, 0xb4, 0xae, 0x42, 0x07, 0xbc, 0x0f, 0x96, 0x97, 0x24, 0x4c, 0x78, 0xe5, 0x20, 0xe8,
+	0xff, 0xf5, 0xd0, 0xf1, 0xa3, 0xf0, 0x0b, 0xe8, 0x6c, 0x53, 0x9a, 0xae, 0x45, 0x3c, 0x4a, 0xc2,
+	0x35, 0xc6, 0xed, 0xce, 0xdf, 0xa4, 0xba, 0x29, 0x19, 0x7c, 0x3f, 0xe9, 0x4e, 0xcb, 0xbe, 0xad,
+	0x91, 0x6a, 0xdc, 0x1a, 0x31, 0xae, 0xd4, 0xfb, 0x79, 0x33, 0xe5, 0x6c, 0x67, 0xf1, 0xeb, 0x14,
+	0xb4, 0x9f, 0x6d, 0x6c, 0xfe, 0xf1, 0x71, 0xda, 0xd0, 0xce, 0x28, 0xdf, 0x8d, 0x2e, 0x9e, 0x66,
+	0x9d, 0xe2, 0x1e, 0x58, 0x43, 0x9a, 0x05, 0x3c, 0x52, 0xc3, 0x5f, 0x59, 0x3b, 0x5e, 0xc2, 0xb7,
+	0x00, 0x42, 0xc6, 0x86, 0x5b, 0x6a, 0x8a, 0x95, 0xcf, 0xa6, 0x6b, 0xca, 0xca, 0x53, 0x59, 0xc0,
+	0x5d, 0xb0, 0x04, 0x13, 0x5e, 0x5c, 0xe1, 0x2d, 0x85, 0x83, 0x2a, 0x95, 0x0b, 0x16, 0xc0, 0x64,
+	0xbe, 0xd4, 0x2d, 0xda, 0xa5, 0xea, 0x5d, 0x22, 0xf7, 0xb2, 0x80, 0xef, 0x83, 0xbe, 0x17, 0x25,
+	0x43, 0xb6, 0x67, 0xb7, 0x27, 0x9f, 0xa0, 0xaa, 0x65, 0x6c, 0x88, 0x8c, 0xab, 0x18, 0xa2, 0x95,
+	0x07, 0x47, 0xa7, 0x44, 0x3b, 0x3e, 0x25, 0xda, 0xf9, 0x29, 0x41, 0x6f, 0x0a, 0x82, 0xde, 0x17,
+	0x04, 0x1d, 0x16, 0x04, 0x1d, 0x15, 0x04, 0x7d, 0x29, 0x08, 0xfa, 0x56, 0x10, 0xed, 0xbc, 0x20,
+	0xe8, 0xed, 0x19, 0xd1, 0x8e, 0xce, 0x88, 0x76, 0x7c, 0x46, 0xb4, 0xe7, 0x6d, 0xf5, 0x6b, 0x4a,
+	0x7d, 0x5f, 0x57, 0x77, 0xba, 0xf3, 0x63, 0x00, 0x34, 0x54, 0x49, 0x26, 0xf1, 0x05, 0x00, 0x00,
 }
 
 func (this *RuleGroupDesc) Equal(that interface{}) bool {
@@ -382,6 +486,56 @@ func (this *RuleDesc) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *SLODesc) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*SLODesc)
+	if !ok {
+		that2, ok := that.(SLODesc)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Name != that1.Name {
+		return false
+	}
+	if this.Service != that1.Service {
+		return false
+	}
+	if this.Description != that1.Description {
+		return false
+	}
+	if this.GoodQuery != that1.GoodQuery {
+		return false
+	}
+	if this.TotalQuery != that1.TotalQuery {
+		return false
+	}
+	if this.Objective != that1.Objective {
+		return false
+	}
+	if this.Window != that1.Window {
+		return false
+	}
+	if len(this.Labels) != len(that1.Labels) {
+		return false
+	}
+	for i := range this.Labels {
+		if !this.Labels[i].Equal(that1.Labels[i]) {
+			return false
+		}
+	}
+	return true
+}
 func (this *RuleGroupDesc) GoString() string {
 	if this == nil {
 		return "nil"
@@ -420,6 +574,23 @@ func (this *RuleDesc) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *SLODesc) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 12)
+	s = append(s, "&rulespb.SLODesc{")
+	s = append(s, "Name: "+fmt.Sprintf("%#v", this.Name)+",\n")
+	s = append(s, "Service: "+fmt.Sprintf("%#v", this.Service)+",\n")
+	s = append(s, "Description: "+fmt.Sprintf("%#v", this.Description)+",\n")
+	s = append(s, "GoodQuery: "+fmt.Sprintf("%#v", this.GoodQuery)+",\n")
+	s = append(s, "TotalQuery: "+fmt.Sprintf("%#v", this.TotalQuery)+",\n")
+	s = append(s, "Objective: "+fmt.Sprintf("%#v", this.Objective)+",\n")
+	s = append(s, "Window: "+fmt.Sprintf("%#v", this.Window)+",\n")
+	s = append(s, "Labels: "+fmt.Sprintf("%#v", this.Labels)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func valueToGoStringRules(v interface{}, typ string) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -625,6 +796,92 @@ func (m *RuleDesc) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *SLODesc) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SLODesc) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SLODesc) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Labels) > 0 {
+		for iNdEx := len(m.Labels) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size := m.Labels[iNdEx].Size()
+				i -= size
+				if _, err := m.Labels[iNdEx].MarshalTo(dAtA[i:]); err != nil {
+					return 0, err
+				}
+				i = encodeVarintRules(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x42
+		}
+	}
+	n5, err5 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.Window, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.Window):])
+	if err5 != nil {
+		return 0, err5
+	}
+	i -= n5
+	i = encodeVarintRules(dAtA, i, uint64(n5))
+	i--
+	dAtA[i] = 0x3a
+	if m.Objective != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.Objective))))
+		i--
+		dAtA[i] = 0x31
+	}
+	if len(m.TotalQuery) > 0 {
+		i -= len(m.TotalQuery)
+		copy(dAtA[i:], m.TotalQuery)
+		i = encodeVarintRules(dAtA, i, uint64(len(m.TotalQuery)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.GoodQuery) > 0 {
+		i -= len(m.GoodQuery)
+		copy(dAtA[i:], m.GoodQuery)
+		i = encodeVarintRules(dAtA, i, uint64(len(m.GoodQuery)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Description) > 0 {
+		i -= len(m.Description)
+		copy(dAtA[i:], m.Description)
+		i = encodeVarintRules(dAtA, i, uint64(len(m.Description)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Service) > 0 {
+		i -= len(m.Service)
+		copy(dAtA[i:], m.Service)
+		i = encodeVarintRules(dAtA, i, uint64(len(m.Service)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintRules(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintRules(dAtA []byte, offset int, v uint64) int {
 	offset -= sovRules(v)
 	base := offset
@@ -721,6 +978,46 @@ func (m *RuleDesc) Size() (n int) {
 	return n
 }
 
+func (m *SLODesc) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovRules(uint64(l))
+	}
+	l = len(m.Service)
+	if l > 0 {
+		n += 1 + l + sovRules(uint64(l))
+	}
+	l = len(m.Description)
+	if l > 0 {
+		n += 1 + l + sovRules(uint64(l))
+	}
+	l = len(m.GoodQuery)
+	if l > 0 {
+		n += 1 + l + sovRules(uint64(l))
+	}
+	l = len(m.TotalQuery)
+	if l > 0 {
+		n += 1 + l + sovRules(uint64(l))
+	}
+	if m.Objective != 0 {
+		n += 9
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.Window)
+	n += 1 + l + sovRules(uint64(l))
+	if len(m.Labels) > 0 {
+		for _, e := range m.Labels {
+			l = e.Size()
+			n += 1 + l + sovRules(uint64(l))
+		}
+	}
+	return n
+}
+
 func sovRules(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
 }
@@ -771,6 +1068,23 @@ func (this *RuleDesc) String() string {
 	}, "")
 	return s
 }
+func (this *SLODesc) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&SLODesc{`,
+		`Name:` + fmt.Sprintf("%v", this.Name) + `,`,
+		`Service:` + fmt.Sprintf("%v", this.Service) + `,`,
+		`Description:` + fmt.Sprintf("%v", this.Description) + `,`,
+		`GoodQuery:` + fmt.Sprintf("%v", this.GoodQuery) + `,`,
+		`TotalQuery:` + fmt.Sprintf("%v", this.TotalQuery) + `,`,
+		`Objective:` + fmt.Sprintf("%v", this.Objective) + `,`,
+		`Window:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.Window), "Duration", "protobuf.Duration", 1), `&`, ``, 1) + `,`,
+		`Labels:` + fmt.Sprintf("%v", this.Labels) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func valueToStringRules(v interface{}) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -1401,6 +1715,297 @@ func (m *RuleDesc) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *SLODesc) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRules
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SLODesc: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SLODesc: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRules
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRules
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRules
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Service", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRules
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRules
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRules
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Service = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Description", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRules
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRules
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRules
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Description = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GoodQuery", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRules
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRules
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRules
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.GoodQuery = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TotalQuery", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRules
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRules
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRules
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TotalQuery = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Objective", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.Objective = float64(math.Float64frombits(v))
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Window", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRules
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRules
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRules
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.Window, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Labels", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRules
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRules
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRules
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Labels = append(m.Labels, github_com_cortexproject_cortex_pkg_cortexpb.LabelAdapter{})
+			if err := m.Labels[len(m.Labels)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRules(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthRules
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthRules
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipRules(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -0,0 +1,308 @@
+package ruler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pkg/errors"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/storage"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/ruler/rulespb"
+)
+
+// SLONamespace is the namespace of the rule groups generated from the SLOs. The rule
+// groups of this namespace are managed by the ruler and can't be edited with the rules API.
+const SLONamespace = "cortex_slos"
+
+const (
+	// sloWindowPlaceholder is replaced in the SLI queries by the range of each recording rule.
+	sloWindowPlaceholder = "$window"
+
+	sloLabel      = "slo"
+	serviceLabel  = "service"
+	severityLabel = "severity"
+
+	sloErrorRatioRecordPrefix     = "slo:sli_error:ratio_rate"
+	sloGoodRateRecordPrefix       = "slo:sli_good:rate"
+	sloTotalRateRecordPrefix      = "slo:sli_total:rate"
+	sloPeriodErrorRatioRecord     = "slo:period_error:ratio"
+	sloObjectiveRecord            = "slo:objective:ratio"
+	sloErrorBudgetRemainingRecord = "slo:error_budget_remaining:ratio"
+	sloBurnRateAlert              = "SLOErrorBudgetBurn"
+)
+
+var (
+	// ErrNoSLOName signals a SLO name url parameter was not found
+	ErrNoSLOName = errors.New("a matching SLO name must be provided in the request")
+	// ErrNoSLO signals the SLO requested does not exist
+	ErrNoSLO = errors.New("no SLO found")
+	// ErrBadSLO is returned when the provided SLO can not be unmarshalled
+	ErrBadSLO = errors.New("unable to decode SLO")
+	// ErrManagedNamespace is returned when editing the rule groups generated from the SLOs with the rules API
+	ErrManagedNamespace = fmt.Errorf("the rule groups of the %s namespace are managed by the SLO API", SLONamespace)
+
+	sloNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+	// sloErrorRatioWindows are the ranges of the SLI error ratio recording rules,
+	// used by the burn rate alerts.
+	sloErrorRatioWindows = []model.Duration{
+		model.Duration(5 * time.Minute),
+		model.Duration(30 * time.Minute),
+		model.Duration(time.Hour),
+		model.Duration(2 * time.Hour),
+		model.Duration(6 * time.Hour),
+		model.Duration(24 * time.Hour),
+		model.Duration(3 * 24 * time.Hour),
+	}
+
+	// sloBurnRateAlerts are the multi-window multi-burn-rate alerts recommended by the
+	// Google SRE workbook. Each alert fires when the given fraction of the error budget
+	// is consumed over the long window, and it's still being consumed over the short one.
+	sloBurnRateAlerts = []struct {
+		severity string
+		windows  []sloAlertWindows
+	}{
+		{
+			severity: "page",
+			windows: []sloAlertWindows{
+				{long: model.Duration(time.Hour), short: model.Duration(5 * time.Minute), budgetConsumed: 0.02},
+				{long: model.Duration(6 * time.Hour), short: model.Duration(30 * time.Minute), budgetConsumed: 0.05},
+			},
+		},
+		{
+			severity: "ticket",
+			windows: []sloAlertWindows{
+				{long: model.Duration(24 * time.Hour), short: model.Duration(2 * time.Hour), budgetConsumed: 0.1},
+				{long: model.Duration(3 * 24 * time.Hour), short: model.Duration(6 * time.Hour), budgetConsumed: 0.1},
+			},
+		},
+	}
+)
+
+type sloAlertWindows struct {
+	long, short    model.Duration
+	budgetConsumed float64
+}
+
+// SLO is the format of the service level objectives accepted by the SLO API.
+type SLO struct {
+	Name        string            `yaml:"name"`
+	Service     string            `yaml:"service"`
+	Description string            `yaml:"description,omitempty"`
+	SLI         SLI               `yaml:"sli"`
+	Objective   float64           `yaml:"objective"`
+	Window      model.Duration    `yaml:"window"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+}
+
+// SLI defines the service level indicator of a SLO as the ratio between the rate of
+// good events and the rate of total events. Both queries must use the $window placeholder
+// as range of their rate.
+type SLI struct {
+	GoodQuery  string `yaml:"good_query"`
+	TotalQuery string `yaml:"total_query"`
+}
+
+// Validate returns an error if the SLO is invalid.
+func (s SLO) Validate() error {
+	if !sloNameRegexp.MatchString(s.Name) {
+		return fmt.Errorf("invalid SLO name %q: it must match %s", s.Name, sloNameRegexp.String())
+	}
+	if s.Service == "" {
+		return errors.New("the SLO service must not be empty")
+	}
+	if s.Objective <= 0 || s.Objective >= 1 {
+		return fmt.Errorf("invalid SLO objective %v: it must be between 0 and 1 excluded", s.Objective)
+	}
+	if longest := sloErrorRatioWindows[len(sloErrorRatioWindows)-1]; s.Window < longest {
+		return fmt.Errorf("invalid SLO window %s: it must be at least %s", s.Window, longest)
+	}
+
+	for name, query := range map[string]string{"good": s.SLI.GoodQuery, "total": s.SLI.TotalQuery} {
+		if !strings.Contains(query, sloWindowPlaceholder) {
+			return fmt.Errorf("the SLI %s query must use the %s placeholder", name, sloWindowPlaceholder)
+		}
+		if _, err := parser.ParseExpr(expandSLIQuery(query, sloErrorRatioWindows[0])); err != nil {
+			return errors.Wrapf(err, "invalid SLI %s query", name)
+		}
+	}
+
+	for name := range s.Labels {
+		if !model.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid SLO label name %q", name)
+		}
+		if name == sloLabel || name == serviceLabel || name == severityLabel {
+			return fmt.Errorf("the SLO label %q is reserved", name)
+		}
+	}
+
+	return nil
+}
+
+// sloRuleGroup expands the input SLO into its managed rule group. The group records the SLI
+// error ratio over the ranges used by the multi-window multi-burn-rate alerts, and the
+// error budget remaining over the SLO window. The error ratio over the SLO window is computed
+// from the good and total rates recorded over the shortest range, summed over the SLO window,
+// which weights it by the number of events without querying the SLI over the whole window.
+func sloRuleGroup(s SLO) rulefmt.RuleGroup {
+	lbls := make(map[string]string, len(s.Labels)+2)
+	for name, value := range s.Labels {
+		lbls[name] = value
+	}
+	lbls[sloLabel] = s.Name
+	lbls[serviceLabel] = s.Service
+
+	selector := fmt.Sprintf("{%s=%q}", sloLabel, s.Name)
+	errorBudget := fmt.Sprintf("(1 - %s)", strconv.FormatFloat(s.Objective, 'f', -1, 64))
+
+	rg := rulefmt.RuleGroup{Name: s.Name}
+	for _, w := range sloErrorRatioWindows {
+		rg.Rules = append(rg.Rules, rulefmt.Rule{
+			Record: sloErrorRatioRecordPrefix + w.String(),
+			Expr:   fmt.Sprintf("1 - ((%s) / (%s))", expandSLIQuery(s.SLI.GoodQuery, w), expandSLIQuery(s.SLI.TotalQuery, w)),
+			Labels: lbls,
+		})
+	}
+
+	shortest := sloErrorRatioWindows[0]
+	rg.Rules = append(rg.Rules,
+		rulefmt.Rule{
+			Record: sloGoodRateRecordPrefix + shortest.String(),
+			Expr:   expandSLIQuery(s.SLI.GoodQuery, shortest),
+			Labels: lbls,
+		},
+		rulefmt.Rule{
+			Record: sloTotalRateRecordPrefix + shortest.String(),
+			Expr:   expandSLIQuery(s.SLI.TotalQuery, shortest),
+			Labels: lbls,
+		},
+		rulefmt.Rule{
+			Record: sloPeriodErrorRatioRecord,
+			Expr: fmt.Sprintf("1 - (sum_over_time(%s%s%s[%s]) / sum_over_time(%s%s%s[%s]))",
+				sloGoodRateRecordPrefix, shortest, selector, s.Window,
+				sloTotalRateRecordPrefix, shortest, selector, s.Window),
+			Labels: lbls,
+		},
+		rulefmt.Rule{
+			Record: sloObjectiveRecord,
+			Expr:   fmt.Sprintf("vector(%s)", strconv.FormatFloat(s.Objective, 'f', -1, 64)),
+			Labels: lbls,
+		},
+		rulefmt.Rule{
+			Record: sloErrorBudgetRemainingRecord,
+			Expr:   fmt.Sprintf("1 - (%s%s / %s)", sloPeriodErrorRatioRecord, selector, errorBudget),
+			Labels: lbls,
+		},
+	)
+
+	for _, alert := range sloBurnRateAlerts {
+		conditions := make([]string, 0, len(alert.windows))
+		for _, w := range alert.windows {
+			// The burn rate consuming the given fraction of the error budget of the SLO window over the long window.
+			burnRate := math.Round(w.budgetConsumed*float64(s.Window)/float64(w.long)*1e4) / 1e4
+			threshold := fmt.Sprintf("(%s * %s)", strconv.FormatFloat(burnRate, 'f', -1, 64), errorBudget)
+			conditions = append(conditions, fmt.Sprintf("(%s%s%s > %s and %s%s%s > %s)",
+				sloErrorRatioRecordPrefix, w.long, selector, threshold,
+				sloErrorRatioRecordPrefix, w.short, selector, threshold))
+		}
+
+		alertLabels := make(map[string]string, len(lbls)+1)
+		for name, value := range lbls {
+			alertLabels[name] = value
+		}
+		alertLabels[severityLabel] = alert.severity
+
+		annotations := map[string]string{
+			"summary": fmt.Sprintf("The %s SLO of the %s service is consuming its error budget too fast.", s.Name, s.Service),
+		}
+		if s.Description != "" {
+			annotations["description"] = s.Description
+		}
+
+		rg.Rules = append(rg.Rules, rulefmt.Rule{
+			Alert:       sloBurnRateAlert,
+			Expr:        strings.Join(conditions, " or "),
+			Labels:      alertLabels,
+			Annotations: annotations,
+		})
+	}
+
+	return rg
+}
+
+func expandSLIQuery(query string, window model.Duration) string {
+	return strings.ReplaceAll(query, sloWindowPlaceholder, window.String())
+}
+
+// sloToProto returns the managed rule group of the input SLO, which stores the SLO in its options.
+func sloToProto(userID string, s SLO) (*rulespb.RuleGroupDesc, error) {
+	desc, err := types.MarshalAny(&rulespb.SLODesc{
+		Name:        s.Name,
+		Service:     s.Service,
+		Description: s.Description,
+		GoodQuery:   s.SLI.GoodQuery,
+		TotalQuery:  s.SLI.TotalQuery,
+		Objective:   s.Objective,
+		Window:      time.Duration(s.Window),
+		Labels:      cortexpb.FromLabelsToLabelAdapters(labels.FromMap(s.Labels)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rg := rulespb.ToProto(userID, SLONamespace, sloRuleGroup(s))
+	rg.Options = append(rg.Options, desc)
+	return rg, nil
+}
+
+// sloFromProto returns the SLO stored in the options of the input managed rule group.
+func sloFromProto(rg *rulespb.RuleGroupDesc) (SLO, error) {
+	for _, opt := range rg.Options {
+		if !types.Is(opt, &rulespb.SLODesc{}) {
+			continue
+		}
+
+		desc := rulespb.SLODesc{}
+		if err := types.UnmarshalAny(opt, &desc); err != nil {
+			return SLO{}, err
+		}
+
+		s := SLO{
+			Name:        desc.Name,
+			Service:     desc.Service,
+			Description: desc.Description,
+			SLI:         SLI{GoodQuery: desc.GoodQuery, TotalQuery: desc.TotalQuery},
+			Objective:   desc.Objective,
+			Window:      model.Duration(desc.Window),
+		}
+		if len(desc.Labels) > 0 {
+			s.Labels = cortexpb.FromLabelAdaptersToLabels(desc.Labels).Map()
+		}
+		return s, nil
+	}
+
+	return SLO{}, fmt.Errorf("the rule group %s doesn't contain a SLO", rg.Name)
+}
+
+// SLOQueryFunc runs an instant query on behalf of the input tenant.
+type SLOQueryFunc func(ctx context.Context, userID, qs string, t time.Time) (promql.Vector, error)
+
+// NewSLOQueryFunc returns a SLOQueryFunc running the queries with the input engine and
+// queryable, honoring the same tenant limits as the rules evaluation.
+func NewSLOQueryFunc(cfg Config, engine promql.QueryEngine, q storage.Queryable, overrides RulesLimits) SLOQueryFunc {
+	return func(ctx context.Context, userID, qs string, t time.Time) (promql.Vector, error) {
+		return engineQueryFunc(engine, nil, q, overrides, userID, cfg.LookbackDelta)(ctx, qs, t)
+	}
+}
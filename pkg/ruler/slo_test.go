@@ -0,0 +1,143 @@
+package ruler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func testSLO() SLO {
+	return SLO{
+		Name:        "api-availability",
+		Service:     "api",
+		Description: "99.9% of the API requests are successful.",
+		SLI: SLI{
+			GoodQuery:  `sum(rate(http_requests_total{code!~"5.."}[$window]))`,
+			TotalQuery: `sum(rate(http_requests_total[$window]))`,
+		},
+		Objective: 0.999,
+		Window:    model.Duration(30 * 24 * time.Hour),
+		Labels:    map[string]string{"team": "platform"},
+	}
+}
+
+func TestSLO_Validate(t *testing.T) {
+	tests := map[string]struct {
+		setup       func(s *SLO)
+		expectedErr string
+	}{
+		"valid SLO": {
+			setup: func(*SLO) {},
+		},
+		"invalid name": {
+			setup:       func(s *SLO) { s.Name = "api/availability" },
+			expectedErr: `invalid SLO name "api/availability"`,
+		},
+		"missing service": {
+			setup:       func(s *SLO) { s.Service = "" },
+			expectedErr: "the SLO service must not be empty",
+		},
+		"objective out of range": {
+			setup:       func(s *SLO) { s.Objective = 1 },
+			expectedErr: "invalid SLO objective 1",
+		},
+		"window shorter than the alert windows": {
+			setup:       func(s *SLO) { s.Window = model.Duration(24 * time.Hour) },
+			expectedErr: "invalid SLO window 1d: it must be at least 3d",
+		},
+		"query without the window placeholder": {
+			setup:       func(s *SLO) { s.SLI.TotalQuery = `sum(rate(http_requests_total[5m]))` },
+			expectedErr: "the SLI total query must use the $window placeholder",
+		},
+		"invalid query": {
+			setup:       func(s *SLO) { s.SLI.GoodQuery = `sum(rate(http_requests_total[$window])` },
+			expectedErr: "invalid SLI good query",
+		},
+		"reserved label": {
+			setup:       func(s *SLO) { s.Labels = map[string]string{"severity": "critical"} },
+			expectedErr: `the SLO label "severity" is reserved`,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := testSLO()
+			testData.setup(&s)
+
+			err := s.Validate()
+			if testData.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testData.expectedErr)
+		})
+	}
+}
+
+func TestSLORuleGroup(t *testing.T) {
+	rg := sloRuleGroup(testSLO())
+	require.Equal(t, "api-availability", rg.Name)
+
+	// The generated rule group must be a valid Prometheus rule group.
+	out, err := yaml.Marshal(rg)
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(out, &rg))
+
+	records := map[string]string{}
+	alerts := map[string]string{}
+	for _, r := range rg.Rules {
+		_, err := parser.ParseExpr(r.Expr)
+		require.NoError(t, err, r.Expr)
+		assert.Equal(t, "api-availability", r.Labels["slo"])
+		assert.Equal(t, "api", r.Labels["service"])
+		assert.Equal(t, "platform", r.Labels["team"])
+
+		if r.Record != "" {
+			records[r.Record] = r.Expr
+		} else {
+			alerts[r.Labels["severity"]] = r.Expr
+		}
+	}
+
+	assert.Equal(t, `1 - ((sum(rate(http_requests_total{code!~"5.."}[1h]))) / (sum(rate(http_requests_total[1h]))))`, records["slo:sli_error:ratio_rate1h"])
+	assert.Equal(t, `sum(rate(http_requests_total{code!~"5.."}[5m]))`, records["slo:sli_good:rate5m"])
+	assert.Equal(t, `sum(rate(http_requests_total[5m]))`, records["slo:sli_total:rate5m"])
+	assert.Equal(t, `1 - (sum_over_time(slo:sli_good:rate5m{slo="api-availability"}[30d]) / sum_over_time(slo:sli_total:rate5m{slo="api-availability"}[30d]))`, records["slo:period_error:ratio"])
+	assert.Equal(t, `1 - (slo:period_error:ratio{slo="api-availability"} / (1 - 0.999))`, records["slo:error_budget_remaining:ratio"])
+	assert.Len(t, records, 12)
+
+	// The burn rates are the ones recommended for a 30 days window.
+	assert.Equal(t, `(slo:sli_error:ratio_rate1h{slo="api-availability"} > (14.4 * (1 - 0.999)) and slo:sli_error:ratio_rate5m{slo="api-availability"} > (14.4 * (1 - 0.999)))`+
+		` or (slo:sli_error:ratio_rate6h{slo="api-availability"} > (6 * (1 - 0.999)) and slo:sli_error:ratio_rate30m{slo="api-availability"} > (6 * (1 - 0.999)))`, alerts["page"])
+	assert.Equal(t, `(slo:sli_error:ratio_rate1d{slo="api-availability"} > (3 * (1 - 0.999)) and slo:sli_error:ratio_rate2h{slo="api-availability"} > (3 * (1 - 0.999)))`+
+		` or (slo:sli_error:ratio_rate3d{slo="api-availability"} > (1 * (1 - 0.999)) and slo:sli_error:ratio_rate6h{slo="api-availability"} > (1 * (1 - 0.999)))`, alerts["ticket"])
+}
+
+func TestSLOToProto(t *testing.T) {
+	s := testSLO()
+
+	rg, err := sloToProto("user1", s)
+	require.NoError(t, err)
+	assert.Equal(t, SLONamespace, rg.Namespace)
+	assert.Equal(t, s.Name, rg.Name)
+	assert.Equal(t, "user1", rg.User)
+
+	// The SLO must survive the round trip through the rule store.
+	data, err := rg.Marshal()
+	require.NoError(t, err)
+	require.NoError(t, rg.Unmarshal(data))
+
+	actual, err := sloFromProto(rg)
+	require.NoError(t, err)
+	assert.Equal(t, s, actual)
+
+	rg.Options = nil
+	_, err = sloFromProto(rg)
+	require.Error(t, err)
+}
@@ -49,6 +49,27 @@ type Request interface {
 	util.PriorityOp
 }
 
+// ExpiringRequest is a Request which is not worth handling once its deadline has passed, because
+// the caller has already stopped waiting for the response. Such requests are dropped from the queue.
+type ExpiringRequest interface {
+	Request
+
+	// Deadline returns the deadline of the request, and false if it has no deadline.
+	Deadline() (time.Time, bool)
+
+	// Expire is called with the queue lock held, when the request is dropped because its deadline has passed.
+	Expire()
+}
+
+// EstimatedCostRequest is a Request whose estimated cost is accounted to the user when it's dequeued by
+// the weighted fair queuing, and must be passed to ReportUsage along with the actual cost of the request.
+type EstimatedCostRequest interface {
+	Request
+
+	// SetEstimatedCost is called with the queue lock held, when the request is dequeued.
+	SetEstimatedCost(cost float64)
+}
+
 // RequestQueue holds incoming requests in per-user queues. It also assigns each user specified number of queriers,
 // and when querier asks for next request to handle (using GetNextRequestForQuerier), it returns requests
 // in a fair fashion.
@@ -83,6 +104,53 @@ func NewRequestQueue(forgetDelay time.Duration, queueLength *prometheus.GaugeVec
 	return q
 }
 
+// EnableWeightedFairQueuing makes queriers pick the queue of the tenant with the lowest recent usage
+// relative to its weight, instead of iterating over the tenants. The usage is reported with ReportUsage,
+// and halves every usageHalfLife. It must be called before the queue is used.
+func (q *RequestQueue) EnableWeightedFairQueuing(usageHalfLife time.Duration) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	q.queues.usage = newTenantsUsage(usageHalfLife)
+}
+
+// ReportUsage accounts the cost of a request handled for the user, e.g. querier time or fetched bytes, in
+// place of its estimated cost, accounted when the request was dequeued. The cost of the requests spanning
+// multiple tenants is split evenly across the tenants. It does nothing unless the weighted fair queuing is enabled.
+func (q *RequestQueue) ReportUsage(userID string, cost, estimatedCost float64) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	if q.queues.usage == nil {
+		return
+	}
+
+	now := time.Now()
+	q.queues.usage.observeRequestCost(userID, cost, now)
+	q.addUsage(userID, cost-estimatedCost, now)
+}
+
+// chargeEstimatedCost accounts the estimated cost of a dequeued request to the user right away, so that the
+// queued requests of the user are not all dequeued while its previous requests are still running. The
+// estimate is corrected once the actual cost of the request is reported.
+func (q *RequestQueue) chargeEstimatedCost(userID string, req Request, now time.Time) {
+	estimated, ok := req.(EstimatedCostRequest)
+	if !ok || q.queues.usage == nil {
+		return
+	}
+
+	cost := q.queues.usage.estimateRequestCost(userID)
+	q.addUsage(userID, cost, now)
+	estimated.SetEstimatedCost(cost)
+}
+
+func (q *RequestQueue) addUsage(userID string, cost float64, now time.Time) {
+	ids := tenantIDs(userID)
+	for _, tenantID := range ids {
+		q.queues.usage.add(tenantID, cost/float64(len(ids)), now)
+	}
+}
+
 // EnqueueRequest puts the request into the queue. MaxQueries is user-specific value that specifies how many queriers can
 // this user use (zero or negative = all queriers). It is passed to each EnqueueRequest, because it can change
 // between calls.
@@ -170,6 +238,16 @@ FindQueue:
 			// Tell close() we've processed a request.
 			q.cond.Broadcast()
 
+			now := time.Now()
+			if q.dropIfExpired(userID, request, now) {
+				if queue.length() == 0 {
+					// Look for another queue.
+					break
+				}
+				continue
+			}
+
+			q.chargeEstimatedCost(userID, request, now)
 			return request, last, nil
 		}
 	}
@@ -180,6 +258,23 @@ FindQueue:
 	goto FindQueue
 }
 
+// dropIfExpired drops the request if its deadline has passed, and returns whether it has been dropped.
+func (q *RequestQueue) dropIfExpired(userID string, req Request, now time.Time) bool {
+	expiring, ok := req.(ExpiringRequest)
+	if !ok {
+		return false
+	}
+
+	deadline, ok := expiring.Deadline()
+	if !ok || now.Before(deadline) {
+		return false
+	}
+
+	q.discardedRequests.WithLabelValues(userID, strconv.FormatInt(req.Priority(), 10)).Inc()
+	expiring.Expire()
+	return true
+}
+
 func (q *RequestQueue) getPriorityForQuerier(userID string, querierID string) (int64, bool) {
 	if priority, ok := q.queues.userQueues[userID].reservedQueriers[querierID]; ok {
 		return priority, true
@@ -191,12 +286,17 @@ func (q *RequestQueue) forgetDisconnectedQueriers(_ context.Context) error {
 	q.mtx.Lock()
 	defer q.mtx.Unlock()
 
-	if q.queues.forgetDisconnectedQueriers(time.Now()) > 0 {
+	now := time.Now()
+	if q.queues.forgetDisconnectedQueriers(now) > 0 {
 		// We need to notify goroutines cause having removed some queriers
 		// may have caused a resharding.
 		q.cond.Broadcast()
 	}
 
+	if q.queues.usage != nil {
+		q.queues.usage.cleanup(now)
+	}
+
 	return nil
 }
 
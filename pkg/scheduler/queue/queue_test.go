@@ -9,10 +9,12 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	promtest "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/users"
 	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
@@ -457,3 +459,257 @@ type MockRequest struct {
 func (r MockRequest) Priority() int64 {
 	return r.priority
 }
+
+func TestRequestQueue_WeightedFairQueuing(t *testing.T) {
+	limits := MockLimits{MaxOutstanding: 10, FairQueuingWeights: map[string]float64{"user-c": 4}}
+	queue := NewRequestQueue(0,
+		prometheus.NewGaugeVec(prometheus.GaugeOpts{}, []string{"user", "priority", "type"}),
+		prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"user", "priority"}),
+		limits,
+		nil,
+	)
+	queue.EnableWeightedFairQueuing(time.Hour)
+
+	ctx := context.Background()
+	queue.RegisterQuerierConnection("querier-1")
+
+	for _, userID := range []string{"user-a", "user-b", "user-c"} {
+		for i := range 2 {
+			require.NoError(t, queue.EnqueueRequest(userID, MockRequest{id: fmt.Sprintf("%s-%d", userID, i)}, 0, nil))
+		}
+	}
+
+	// The user-a has consumed the most, and the user-c usage is the lowest relative to its weight.
+	queue.ReportUsage("user-a", 10, 0)
+	queue.ReportUsage("user-b", 2, 0)
+	queue.ReportUsage("user-c", 4, 0)
+
+	var ids []string
+	last := FirstUser()
+	for range 6 {
+		req, idx, err := queue.GetNextRequestForQuerier(ctx, last, "querier-1")
+		require.NoError(t, err)
+		last = idx
+		ids = append(ids, req.(MockRequest).id)
+	}
+
+	assert.Equal(t, []string{"user-c-0", "user-c-1", "user-b-0", "user-b-1", "user-a-0", "user-a-1"}, ids)
+}
+
+func TestRequestQueue_WeightedFairQueuing_ShouldPickUsersWithoutUsageInTurn(t *testing.T) {
+	queue := NewRequestQueue(0,
+		prometheus.NewGaugeVec(prometheus.GaugeOpts{}, []string{"user", "priority", "type"}),
+		prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"user", "priority"}),
+		MockLimits{MaxOutstanding: 10},
+		nil,
+	)
+	queue.EnableWeightedFairQueuing(time.Hour)
+
+	ctx := context.Background()
+	queue.RegisterQuerierConnection("querier-1")
+
+	for _, userID := range []string{"user-a", "user-b"} {
+		for i := range 2 {
+			require.NoError(t, queue.EnqueueRequest(userID, MockRequest{id: fmt.Sprintf("%s-%d", userID, i)}, 0, nil))
+		}
+	}
+
+	var ids []string
+	last := FirstUser()
+	for range 4 {
+		req, idx, err := queue.GetNextRequestForQuerier(ctx, last, "querier-1")
+		require.NoError(t, err)
+		last = idx
+		ids = append(ids, req.(MockRequest).id)
+	}
+
+	assert.Equal(t, []string{"user-a-0", "user-b-0", "user-a-1", "user-b-1"}, ids)
+}
+
+func TestRequestQueue_WeightedFairQueuing_ShouldSkipUsersWithoutRequestsForReservedQuerier(t *testing.T) {
+	queue := NewRequestQueue(0,
+		prometheus.NewGaugeVec(prometheus.GaugeOpts{}, []string{"user", "priority", "type"}),
+		prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"user", "priority"}),
+		MockLimits{
+			MaxOutstanding: 10,
+			QueryPriorityVal: validation.QueryPriority{
+				Enabled:    true,
+				Priorities: []validation.PriorityDef{{Priority: 1, ReservedQueriers: 1}},
+			},
+		},
+		nil,
+	)
+	queue.EnableWeightedFairQueuing(time.Hour)
+
+	ctx := context.Background()
+	queue.RegisterQuerierConnection("querier-1")
+	queue.RegisterQuerierConnection("querier-2")
+	maxQueriers := float64(2)
+
+	// The user-a has the lowest usage, but only a normal request the querier reserved for its priority 1
+	// requests can't dequeue.
+	require.NoError(t, queue.EnqueueRequest("user-a", MockRequest{id: "user-a-0"}, maxQueriers, nil))
+	require.NoError(t, queue.EnqueueRequest("user-b", MockRequest{id: "user-b-0", priority: 1}, maxQueriers, nil))
+	require.NoError(t, queue.EnqueueRequest("user-b", MockRequest{id: "user-b-1"}, maxQueriers, nil))
+	queue.ReportUsage("user-b", 10, 0)
+
+	require.Equal(t, map[string]int64{"querier-1": 1}, queue.queues.userQueues["user-a"].reservedQueriers)
+
+	// The querier gets the request of the user-b instead of waiting for a request of the user-a.
+	ctxTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	time.AfterFunc(2*time.Second, func() {
+		queue.cond.Broadcast()
+	})
+	req, _, err := queue.GetNextRequestForQuerier(ctxTimeout, FirstUser(), "querier-1")
+	require.NoError(t, err)
+	assert.Equal(t, "user-b-0", req.(MockRequest).id)
+
+	// No request left with the priority 1, so the querier waits.
+	ctxTimeout, cancel = context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	time.AfterFunc(200*time.Millisecond, func() {
+		queue.cond.Broadcast()
+	})
+	req, _, err = queue.GetNextRequestForQuerier(ctxTimeout, FirstUser(), "querier-1")
+	assert.Nil(t, req)
+	assert.Error(t, err)
+}
+
+func TestRequestQueue_WeightedFairQueuing_ShouldSplitUsageAcrossTenants(t *testing.T) {
+	users.WithDefaultResolver(users.NewMultiResolver())
+	t.Cleanup(func() {
+		users.WithDefaultResolver(users.NewSingleResolver())
+	})
+
+	queue := NewRequestQueue(0,
+		prometheus.NewGaugeVec(prometheus.GaugeOpts{}, []string{"user", "priority", "type"}),
+		prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"user", "priority"}),
+		MockLimits{MaxOutstanding: 10},
+		nil,
+	)
+	queue.EnableWeightedFairQueuing(time.Hour)
+
+	now := time.Now()
+	queue.ReportUsage("user-a|user-b", 10, 0)
+	queue.ReportUsage("user-b", 2, 0)
+
+	assert.InDelta(t, 5, queue.queues.usage.get("user-a", now), 0.01)
+	assert.InDelta(t, 7, queue.queues.usage.get("user-b", now), 0.01)
+	assert.InDelta(t, 0, queue.queues.usage.get("user-a|user-b", now), 0.01)
+	assert.InDelta(t, 7, queue.queues.weightedUsage("user-a|user-b", now), 0.01)
+}
+
+func TestRequestQueue_WeightedFairQueuing_ShouldChargeEstimatedCostAtDequeue(t *testing.T) {
+	queue := NewRequestQueue(0,
+		prometheus.NewGaugeVec(prometheus.GaugeOpts{}, []string{"user", "priority", "type"}),
+		prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"user", "priority"}),
+		MockLimits{MaxOutstanding: 10},
+		nil,
+	)
+	queue.EnableWeightedFairQueuing(time.Hour)
+
+	ctx := context.Background()
+	queue.RegisterQuerierConnection("querier-1")
+
+	queue.ReportUsage("user-a", 4, 0)
+	queue.ReportUsage("user-b", 6, 0)
+
+	for _, userID := range []string{"user-a", "user-b"} {
+		for i := range 3 {
+			require.NoError(t, queue.EnqueueRequest(userID, &mockEstimatedCostRequest{MockRequest: MockRequest{id: fmt.Sprintf("%s-%d", userID, i)}}, 0, nil))
+		}
+	}
+
+	// The user-a has the lowest usage, but the estimated cost of its running requests is accounted
+	// when they're dequeued, so its requests are not all dequeued first.
+	var reqs []*mockEstimatedCostRequest
+	last := FirstUser()
+	for range 3 {
+		req, idx, err := queue.GetNextRequestForQuerier(ctx, last, "querier-1")
+		require.NoError(t, err)
+		last = idx
+		reqs = append(reqs, req.(*mockEstimatedCostRequest))
+	}
+
+	assert.Equal(t, "user-a-0", reqs[0].id)
+	assert.Equal(t, "user-b-0", reqs[1].id)
+	assert.Equal(t, "user-a-1", reqs[2].id)
+	assert.Equal(t, 4.0, reqs[0].estimatedCost)
+	assert.Equal(t, 6.0, reqs[1].estimatedCost)
+
+	now := time.Now()
+	assert.InDelta(t, 12, queue.queues.usage.get("user-a", now), 0.01)
+	assert.InDelta(t, 12, queue.queues.usage.get("user-b", now), 0.01)
+
+	// The users without any reported cost get the average cost of the requests of all the users.
+	assert.InDelta(t, 4.4, queue.queues.usage.estimateRequestCost("user-c"), 0.01)
+
+	// The estimate is corrected with the actual cost of the request.
+	queue.ReportUsage("user-a", 1, reqs[0].estimatedCost)
+	assert.InDelta(t, 9, queue.queues.usage.get("user-a", now), 0.01)
+}
+
+func TestRequestQueue_ShouldDropExpiredRequests(t *testing.T) {
+	discarded := prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"user", "priority"})
+	queue := NewRequestQueue(0,
+		prometheus.NewGaugeVec(prometheus.GaugeOpts{}, []string{"user", "priority", "type"}),
+		discarded,
+		MockLimits{MaxOutstanding: 10},
+		nil,
+	)
+
+	ctx := context.Background()
+	queue.RegisterQuerierConnection("querier-1")
+
+	var expired []string
+	newRequest := func(id string, deadline time.Time) *mockExpiringRequest {
+		return &mockExpiringRequest{MockRequest: MockRequest{id: id}, deadline: deadline, expire: func() {
+			expired = append(expired, id)
+		}}
+	}
+
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, queue.EnqueueRequest("user-a", newRequest("a-expired", past), 0, nil))
+	require.NoError(t, queue.EnqueueRequest("user-a", newRequest("a-valid", future), 0, nil))
+	require.NoError(t, queue.EnqueueRequest("user-b", newRequest("b-expired", past), 0, nil))
+	require.NoError(t, queue.EnqueueRequest("user-c", newRequest("c-no-deadline", time.Time{}), 0, nil))
+
+	req, last, err := queue.GetNextRequestForQuerier(ctx, FirstUser(), "querier-1")
+	require.NoError(t, err)
+	assert.Equal(t, "a-valid", req.(*mockExpiringRequest).id)
+
+	// The queue of user-b only contains an expired request, so the one of user-c is picked.
+	req, _, err = queue.GetNextRequestForQuerier(ctx, last, "querier-1")
+	require.NoError(t, err)
+	assert.Equal(t, "c-no-deadline", req.(*mockExpiringRequest).id)
+
+	assert.Equal(t, []string{"a-expired", "b-expired"}, expired)
+	assert.Equal(t, 1.0, promtest.ToFloat64(discarded.WithLabelValues("user-a", "0")))
+	assert.Equal(t, 1.0, promtest.ToFloat64(discarded.WithLabelValues("user-b", "0")))
+	assert.Equal(t, 0, queue.queues.len())
+}
+
+type mockExpiringRequest struct {
+	MockRequest
+	deadline time.Time
+	expire   func()
+}
+
+func (r *mockExpiringRequest) Deadline() (time.Time, bool) {
+	return r.deadline, !r.deadline.IsZero()
+}
+
+func (r *mockExpiringRequest) Expire() {
+	r.expire()
+}
+
+type mockEstimatedCostRequest struct {
+	MockRequest
+	estimatedCost float64
+}
+
+func (r *mockEstimatedCostRequest) SetEstimatedCost(cost float64) {
+	r.estimatedCost = cost
+}
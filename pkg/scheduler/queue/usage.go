@@ -0,0 +1,123 @@
+package queue
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// The usage below which a tenant is forgotten, as it makes no difference anymore when comparing tenants.
+	minTrackedUsage = 1e-9
+
+	// The weight of the cost of a request in the average cost of the requests.
+	requestCostSmoothing = 0.2
+
+	// The average cost of the requests of a user is forgotten after this many half-lives without requests.
+	requestCostHalfLives = 30
+)
+
+// tenantsUsage tracks the cost of the requests recently handled for each tenant, e.g. querier time or
+// fetched bytes. The usage decays exponentially over time, so that only the recent requests matter.
+type tenantsUsage struct {
+	halfLife time.Duration
+	usage    map[string]*decayedUsage
+
+	// The average cost of the recent requests of each user, and of all the users, used to estimate
+	// the cost of the requests until it's reported.
+	requestCost    map[string]*decayedUsage
+	avgRequestCost float64
+}
+
+type decayedUsage struct {
+	value     float64
+	updatedAt time.Time
+}
+
+func newTenantsUsage(halfLife time.Duration) *tenantsUsage {
+	return &tenantsUsage{
+		halfLife:    halfLife,
+		usage:       map[string]*decayedUsage{},
+		requestCost: map[string]*decayedUsage{},
+	}
+}
+
+// add accounts the cost of a request handled for the tenant. A negative cost corrects a previously
+// accounted estimate, without the usage of the tenant getting below 0.
+func (u *tenantsUsage) add(userID string, cost float64, now time.Time) {
+	if cost == 0 {
+		return
+	}
+
+	usage := u.usage[userID]
+	if usage == nil {
+		if cost > 0 {
+			u.usage[userID] = &decayedUsage{value: cost, updatedAt: now}
+		}
+		return
+	}
+
+	usage.value = max(0, u.decay(usage, now)+cost)
+	usage.updatedAt = now
+}
+
+// observeRequestCost updates the average cost of the requests of the user with the cost of a request.
+func (u *tenantsUsage) observeRequestCost(userID string, cost float64, now time.Time) {
+	if len(u.requestCost) == 0 {
+		u.avgRequestCost = cost
+	} else {
+		u.avgRequestCost += requestCostSmoothing * (cost - u.avgRequestCost)
+	}
+
+	requestCost := u.requestCost[userID]
+	if requestCost == nil {
+		u.requestCost[userID] = &decayedUsage{value: cost, updatedAt: now}
+		return
+	}
+
+	requestCost.value += requestCostSmoothing * (cost - requestCost.value)
+	requestCost.updatedAt = now
+}
+
+// estimateRequestCost returns the estimated cost of a request of the user, which is the average cost of its
+// recent requests, or of the recent requests of all the users if the user has none.
+func (u *tenantsUsage) estimateRequestCost(userID string) float64 {
+	if requestCost := u.requestCost[userID]; requestCost != nil {
+		return requestCost.value
+	}
+	return u.avgRequestCost
+}
+
+// get returns the usage of the tenant, decayed until now.
+func (u *tenantsUsage) get(userID string, now time.Time) float64 {
+	usage := u.usage[userID]
+	if usage == nil {
+		return 0
+	}
+	return u.decay(usage, now)
+}
+
+// cleanup forgets the tenants whose usage has decayed to nothing.
+func (u *tenantsUsage) cleanup(now time.Time) {
+	for userID, usage := range u.usage {
+		if u.decay(usage, now) < minTrackedUsage {
+			delete(u.usage, userID)
+		}
+	}
+
+	for userID, requestCost := range u.requestCost {
+		if now.Sub(requestCost.updatedAt) > requestCostHalfLives*u.halfLife {
+			delete(u.requestCost, userID)
+		}
+	}
+	if len(u.requestCost) == 0 {
+		u.avgRequestCost = 0
+	}
+}
+
+func (u *tenantsUsage) decay(usage *decayedUsage, now time.Time) float64 {
+	elapsed := now.Sub(usage.updatedAt)
+	if elapsed <= 0 {
+		return usage.value
+	}
+	return usage.value * math.Exp2(-elapsed.Seconds()/u.halfLife.Seconds())
+}
@@ -0,0 +1,64 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantsUsage(t *testing.T) {
+	now := time.Now()
+	usage := newTenantsUsage(time.Minute)
+
+	usage.add("user-1", 8, now)
+	usage.add("user-2", 0, now)
+	assert.Equal(t, 8.0, usage.get("user-1", now))
+	assert.Equal(t, 0.0, usage.get("user-2", now))
+	assert.Len(t, usage.usage, 1)
+
+	// The usage halves every half-life.
+	assert.InDelta(t, 4.0, usage.get("user-1", now.Add(time.Minute)), 1e-9)
+	assert.InDelta(t, 2.0, usage.get("user-1", now.Add(2*time.Minute)), 1e-9)
+
+	// The new cost is added to the decayed usage.
+	usage.add("user-1", 1, now.Add(time.Minute))
+	assert.InDelta(t, 5.0, usage.get("user-1", now.Add(time.Minute)), 1e-9)
+	assert.InDelta(t, 2.5, usage.get("user-1", now.Add(2*time.Minute)), 1e-9)
+
+	// A negative cost corrects the usage, which doesn't get below 0.
+	usage.add("user-1", -1, now.Add(time.Minute))
+	assert.InDelta(t, 4.0, usage.get("user-1", now.Add(time.Minute)), 1e-9)
+	usage.add("user-1", -10, now.Add(time.Minute))
+	assert.Equal(t, 0.0, usage.get("user-1", now.Add(time.Minute)))
+
+	// The users are forgotten once their usage has decayed to nothing.
+	usage.add("user-2", 1, now.Add(time.Hour))
+	usage.cleanup(now.Add(time.Hour))
+	assert.Len(t, usage.usage, 1)
+	assert.Equal(t, 0.0, usage.get("user-1", now.Add(time.Hour)))
+	assert.Equal(t, 1.0, usage.get("user-2", now.Add(time.Hour)))
+}
+
+func TestTenantsUsage_RequestCost(t *testing.T) {
+	now := time.Now()
+	usage := newTenantsUsage(time.Minute)
+
+	// Without any request, the estimated cost is 0.
+	assert.Equal(t, 0.0, usage.estimateRequestCost("user-1"))
+
+	usage.observeRequestCost("user-1", 10, now)
+	usage.observeRequestCost("user-1", 20, now)
+	usage.observeRequestCost("user-2", 5, now)
+
+	// The estimated cost is the average cost of the recent requests of the user, or of all the users.
+	assert.InDelta(t, 12.0, usage.estimateRequestCost("user-1"), 1e-9)
+	assert.InDelta(t, 5.0, usage.estimateRequestCost("user-2"), 1e-9)
+	assert.InDelta(t, 10.6, usage.estimateRequestCost("user-3"), 1e-9)
+
+	// The average costs are forgotten after a while without requests.
+	usage.observeRequestCost("user-2", 5, now.Add(time.Hour))
+	usage.cleanup(now.Add(time.Hour))
+	assert.Len(t, usage.requestCost, 1)
+	assert.InDelta(t, 9.48, usage.estimateRequestCost("user-1"), 1e-9)
+}
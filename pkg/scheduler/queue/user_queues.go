@@ -9,6 +9,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/users"
 	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
@@ -21,6 +22,10 @@ type Limits interface {
 	// QueryPriority returns query priority config for the tenant, including priority level,
 	// their attributes, and how many reserved queriers each priority has.
 	QueryPriority(user string) validation.QueryPriority
+
+	// FairQueuingWeight returns the share of the queriers the tenant gets, relative to the
+	// other tenants, when the weighted fair queuing is enabled.
+	FairQueuingWeight(user string) float64
 }
 
 // querier holds information about a querier registered in the queue.
@@ -57,6 +62,10 @@ type queues struct {
 
 	limits Limits
 
+	// If not nil, the queue of the tenant with the lowest usage relative to its weight is picked,
+	// instead of iterating over the tenants.
+	usage *tenantsUsage
+
 	queueLength *prometheus.GaugeVec // Per user, type and priority.
 }
 
@@ -221,11 +230,15 @@ func (q *queues) createUserRequestQueue(userID string) userRequestQueue {
 // to pass last user index returned by this function as argument. Is there was no previous
 // last user index, use -1.
 func (q *queues) getNextQueueForQuerier(lastUserIndex int, querierID string) (userRequestQueue, string, int) {
-	uid := lastUserIndex
-
 	q.queuesMx.RLock()
 	defer q.queuesMx.RUnlock()
 
+	if q.usage != nil {
+		return q.getLeastUsedQueueForQuerier(lastUserIndex, querierID, time.Now())
+	}
+
+	uid := lastUserIndex
+
 	for iters := 0; iters < len(q.users); iters++ {
 		uid = uid + 1
 
@@ -254,6 +267,78 @@ func (q *queues) getNextQueueForQuerier(lastUserIndex int, querierID string) (us
 	return nil, "", uid
 }
 
+// Finds the queue of the tenant with the lowest usage relative to its weight among the tenants handled by the
+// querier. Tenants with the same weighted usage, e.g. the ones without recent usage, are picked in turn starting
+// after the last user index. Tenants without any request the querier can dequeue, e.g. because the querier is
+// reserved for a higher priority, are skipped so that the querier doesn't wait while other tenants are queued.
+func (q *queues) getLeastUsedQueueForQuerier(lastUserIndex int, querierID string, now time.Time) (userRequestQueue, string, int) {
+	uid := lastUserIndex
+	selected := -1
+	selectedUsage := 0.0
+
+	for iters := 0; iters < len(q.users); iters++ {
+		uid = uid + 1
+		if uid >= len(q.users) {
+			uid = 0
+		}
+
+		u := q.users[uid]
+		if u == "" {
+			continue
+		}
+
+		uq := q.userQueues[u]
+
+		if uq.queriers != nil {
+			if _, ok := uq.queriers[querierID]; !ok {
+				// This querier is not handling the user.
+				continue
+			}
+		}
+
+		minPriority, ok := uq.reservedQueriers[querierID]
+		if !uq.queue.canDequeueRequest(minPriority, ok) {
+			continue
+		}
+
+		usage := q.weightedUsage(u, now)
+		if selected < 0 || usage < selectedUsage {
+			selected = uid
+			selectedUsage = usage
+		}
+	}
+
+	if selected < 0 {
+		return nil, "", uid
+	}
+
+	u := q.users[selected]
+	return q.userQueues[u].queue, u, selected
+}
+
+// weightedUsage returns the usage of the user relative to its weight. The requests spanning multiple tenants
+// get the highest weighted usage of the tenants, so that they're not picked ahead of any of them.
+func (q *queues) weightedUsage(userID string, now time.Time) float64 {
+	weighted := 0.0
+	for _, tenantID := range tenantIDs(userID) {
+		weight := q.limits.FairQueuingWeight(tenantID)
+		if weight <= 0 {
+			weight = 1
+		}
+		weighted = max(weighted, q.usage.get(tenantID, now)/weight)
+	}
+	return weighted
+}
+
+// tenantIDs returns the tenants of the user, which are several for the requests spanning multiple tenants.
+func tenantIDs(userID string) []string {
+	ids, err := users.TenantIDsFromOrgID(userID)
+	if err != nil || len(ids) == 0 {
+		return []string{userID}
+	}
+	return ids
+}
+
 func (q *queues) addQuerierConnection(querierID string) {
 	info := q.queriers[querierID]
 	if info != nil {
@@ -425,6 +510,7 @@ type MockLimits struct {
 	MaxOutstanding        int
 	MaxQueriersPerUserVal float64
 	QueryPriorityVal      validation.QueryPriority
+	FairQueuingWeights    map[string]float64
 }
 
 func (l MockLimits) MaxQueriersPerUser(_ string) float64 {
@@ -438,3 +524,10 @@ func (l MockLimits) MaxOutstandingPerTenant(_ string) int {
 func (l MockLimits) QueryPriority(_ string) validation.QueryPriority {
 	return l.QueryPriorityVal
 }
+
+func (l MockLimits) FairQueuingWeight(user string) float64 {
+	if weight, ok := l.FairQueuingWeights[user]; ok {
+		return weight
+	}
+	return 1
+}
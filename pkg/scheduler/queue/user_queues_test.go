@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/cortexproject/cortex/pkg/util/users"
 	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
@@ -70,6 +71,57 @@ func TestQueues(t *testing.T) {
 	assert.Nil(t, q)
 }
 
+func TestQueues_GetLeastUsedQueueForQuerier(t *testing.T) {
+	users.WithDefaultResolver(users.NewMultiResolver())
+	t.Cleanup(func() {
+		users.WithDefaultResolver(users.NewSingleResolver())
+	})
+
+	now := time.Now()
+	uq := newUserQueues(0, MockLimits{MaxOutstanding: 1, FairQueuingWeights: map[string]float64{"one": 2, "three": 0.5}}, nil)
+	uq.usage = newTenantsUsage(time.Hour)
+	for _, querierID := range []string{"querier-1", "querier-2"} {
+		uq.addQuerierConnection(querierID)
+	}
+
+	qOne := getOrAdd(t, uq, "one", 0)
+	qTwo := getOrAdd(t, uq, "two", 1)
+	qMulti := getOrAdd(t, uq, "one|three", 0)
+	for _, q := range []userRequestQueue{qOne, qTwo, qMulti} {
+		q.enqueueRequest(MockRequest{})
+	}
+	twoQueriers := getKeys(uq.userQueues["two"].queriers)
+	require.Len(t, twoQueriers, 1)
+	otherQuerier := "querier-1"
+	if twoQueriers[0] == otherQuerier {
+		otherQuerier = "querier-2"
+	}
+
+	uq.usage.add("one", 6, now)
+	uq.usage.add("two", 2, now)
+	uq.usage.add("three", 2, now)
+
+	// The usage relative to the weight is 3 for one, 2 for two and 4 for the multi-tenant queue,
+	// which gets the highest weighted usage of its tenants.
+	q, u, _ := uq.getLeastUsedQueueForQuerier(-1, twoQueriers[0], now)
+	assert.Equal(t, qTwo, q)
+	assert.Equal(t, "two", u)
+
+	// The queue of two isn't handled by the other querier.
+	q, u, _ = uq.getLeastUsedQueueForQuerier(-1, otherQuerier, now)
+	assert.Equal(t, qOne, q)
+	assert.Equal(t, "one", u)
+
+	uq.deleteQueue("one")
+	q, u, _ = uq.getLeastUsedQueueForQuerier(-1, otherQuerier, now)
+	assert.Equal(t, qMulti, q)
+	assert.Equal(t, "one|three", u)
+
+	uq.deleteQueue("one|three")
+	q, _, _ = uq.getLeastUsedQueueForQuerier(-1, otherQuerier, now)
+	assert.Nil(t, q)
+}
+
 func TestQueuesWithQueriers(t *testing.T) {
 	uq := newUserQueues(0, MockLimits{}, nil)
 	assert.NotNil(t, uq)
@@ -11,6 +11,7 @@ import (
 type userRequestQueue interface {
 	enqueueRequest(Request)
 	dequeueRequest(int64, bool) Request
+	canDequeueRequest(int64, bool) bool
 	length() int
 }
 
@@ -39,6 +40,10 @@ func (f *FIFORequestQueue) dequeueRequest(_ int64, _ bool) Request {
 	return r
 }
 
+func (f *FIFORequestQueue) canDequeueRequest(_ int64, _ bool) bool {
+	return len(f.queue) > 0
+}
+
 func (f *FIFORequestQueue) length() int {
 	return len(f.queue)
 }
@@ -71,6 +76,13 @@ func (f *PriorityRequestQueue) dequeueRequest(minPriority int64, checkMinPriorit
 	return r
 }
 
+func (f *PriorityRequestQueue) canDequeueRequest(minPriority int64, checkMinPriority bool) bool {
+	if f.queue.Length() == 0 {
+		return false
+	}
+	return !checkMinPriority || f.queue.Peek().Priority() >= minPriority
+}
+
 func (f *PriorityRequestQueue) length() int {
 	return f.queue.Length()
 }
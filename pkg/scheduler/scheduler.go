@@ -7,6 +7,8 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -30,6 +32,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/scheduler/fragment_table"
 	"github.com/cortexproject/cortex/pkg/scheduler/queue"
 	"github.com/cortexproject/cortex/pkg/scheduler/schedulerpb"
+	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
 	"github.com/cortexproject/cortex/pkg/util/grpcclient"
 	"github.com/cortexproject/cortex/pkg/util/httpgrpcutil"
@@ -102,15 +105,57 @@ type connectedFrontend struct {
 	cancel context.CancelFunc
 }
 
+const (
+	// RoundRobinQueueMode makes queriers iterate over the tenants queues.
+	RoundRobinQueueMode = "round-robin"
+	// WeightedFairQueueMode makes queriers pick the queue of the tenant with the lowest recent usage
+	// relative to its weight.
+	WeightedFairQueueMode = "weighted-fair"
+
+	// QuerierTimeCost accounts the querier wall time spent on the tenant requests.
+	QuerierTimeCost = "querier-time"
+	// FetchedBytesCost accounts the chunk and data bytes fetched for the tenant requests.
+	FetchedBytesCost = "fetched-bytes"
+)
+
+var (
+	supportedQueueModes       = []string{RoundRobinQueueMode, WeightedFairQueueMode}
+	supportedFairQueuingCosts = []string{QuerierTimeCost, FetchedBytesCost}
+)
+
 type Config struct {
 	QuerierForgetDelay time.Duration     `yaml:"querier_forget_delay"`
 	GRPCClientConfig   grpcclient.Config `yaml:"grpc_client_config" doc:"description=This configures the gRPC client used to report errors back to the query-frontend."`
+
+	QueueMode                string        `yaml:"queue_mode"`
+	FairQueuingCost          string        `yaml:"fair_queuing_cost"`
+	FairQueuingUsageHalfLife time.Duration `yaml:"fair_queuing_usage_half_life"`
 }
 
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	flagext.DeprecatedFlag(f, "query-scheduler.max-outstanding-requests-per-tenant", "Deprecated: Use frontend.max-outstanding-requests-per-tenant instead.", util_log.Logger)
 	f.DurationVar(&cfg.QuerierForgetDelay, "query-scheduler.querier-forget-delay", 0, "If a querier disconnects without sending notification about graceful shutdown, the query-scheduler will keep the querier in the tenant's shard until the forget delay has passed. This feature is useful to reduce the blast radius when shuffle-sharding is enabled.")
 	cfg.GRPCClientConfig.RegisterFlagsWithPrefix("query-scheduler.grpc-client-config", "", f)
+
+	f.StringVar(&cfg.QueueMode, "query-scheduler.queue-mode", RoundRobinQueueMode, fmt.Sprintf("[Experimental] How queriers pick the tenant whose query they handle next. Supported values are: %s. With %s, the tenants get a share of the queriers proportional to their weight, based on the resources their queries consumed recently.", strings.Join(supportedQueueModes, ", "), WeightedFairQueueMode))
+	f.StringVar(&cfg.FairQueuingCost, "query-scheduler.fair-queuing-cost", QuerierTimeCost, fmt.Sprintf("[Experimental] Resource accounted to the tenants by the %s queue mode. Supported values are: %s.", WeightedFairQueueMode, strings.Join(supportedFairQueuingCosts, ", ")))
+	f.DurationVar(&cfg.FairQueuingUsageHalfLife, "query-scheduler.fair-queuing-usage-half-life", time.Minute, fmt.Sprintf("[Experimental] Time after which the resources consumed by a tenant count for half as much in the %s queue mode.", WeightedFairQueueMode))
+}
+
+func (cfg *Config) Validate() error {
+	if !slices.Contains(supportedQueueModes, cfg.QueueMode) {
+		return fmt.Errorf("unsupported queue mode %q, supported values are: %s", cfg.QueueMode, strings.Join(supportedQueueModes, ", "))
+	}
+	if cfg.QueueMode != WeightedFairQueueMode {
+		return nil
+	}
+	if !slices.Contains(supportedFairQueuingCosts, cfg.FairQueuingCost) {
+		return fmt.Errorf("unsupported fair queuing cost %q, supported values are: %s", cfg.FairQueuingCost, strings.Join(supportedFairQueuingCosts, ", "))
+	}
+	if cfg.FairQueuingUsageHalfLife <= 0 {
+		return errors.New("the fair queuing usage half-life must be greater than 0")
+	}
+	return nil
 }
 
 // NewScheduler creates a new Scheduler.
@@ -140,6 +185,9 @@ func NewScheduler(cfg Config, limits Limits, log log.Logger, registerer promethe
 	}, []string{"user", "priority"})
 
 	s.requestQueue = queue.NewRequestQueue(cfg.QuerierForgetDelay, s.queueLength, s.discardedRequests, s.limits, registerer)
+	if cfg.QueueMode == WeightedFairQueueMode {
+		s.requestQueue.EnableWeightedFairQueuing(cfg.FairQueuingUsageHalfLife)
+	}
 
 	s.queueDuration = promauto.With(registerer).NewHistogram(prometheus.HistogramOpts{
 		Name:    "cortex_query_scheduler_queue_duration_seconds",
@@ -195,6 +243,12 @@ type schedulerRequest struct {
 	// In distributed execution mode, contains a specific plan segment.
 	// In non-distributed mode, only marks the query as root fragment.
 	fragment plan_fragments.Fragment
+
+	// Called when the request is dropped from the queue because its deadline has passed.
+	onExpire func()
+
+	// The cost of the request accounted to the tenant by the weighted fair queuing when it was dequeued.
+	estimatedCost float64
 }
 
 func (s schedulerRequest) Priority() int64 {
@@ -206,6 +260,24 @@ func (s schedulerRequest) Priority() int64 {
 	return priority
 }
 
+// Deadline implements queue.ExpiringRequest.
+func (s schedulerRequest) Deadline() (time.Time, bool) {
+	return s.ctx.Deadline()
+}
+
+// Expire implements queue.ExpiringRequest.
+func (s schedulerRequest) Expire() {
+	s.queueSpan.Finish()
+	if s.onExpire != nil {
+		s.onExpire()
+	}
+}
+
+// SetEstimatedCost implements queue.EstimatedCostRequest.
+func (s *schedulerRequest) SetEstimatedCost(cost float64) {
+	s.estimatedCost = cost
+}
+
 func getPlanFromHTTPRequest(req *httpgrpc.HTTPRequest) ([]byte, error) {
 	if req.Body == nil {
 		return nil, nil
@@ -383,8 +455,17 @@ func (s *Scheduler) fragmentAndEnqueueRequest(frontendContext context.Context, f
 }
 
 func (s *Scheduler) enqueueRequest(frontendContext context.Context, frontendAddr string, msg *schedulerpb.FrontendToScheduler, fragment plan_fragments.Fragment) error {
-	// Create new context for this request, to support cancellation.
-	ctx, cancel := context.WithCancel(frontendContext)
+	// Create new context for this request, to support cancellation. The request is dropped if it's
+	// still queued once the frontend stopped waiting for it.
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if msg.Deadline > 0 {
+		ctx, cancel = context.WithDeadline(frontendContext, util.TimeFromMillis(msg.Deadline))
+	} else {
+		ctx, cancel = context.WithCancel(frontendContext)
+	}
 	shouldCancel := true
 	defer func() {
 		if shouldCancel {
@@ -410,6 +491,9 @@ func (s *Scheduler) enqueueRequest(frontendContext context.Context, frontendAddr
 		statsEnabled:    msg.StatsEnabled,
 		fragment:        fragment,
 	}
+	req.onExpire = func() {
+		s.cancelRequestAndRemoveFromPending(frontendAddr, msg.QueryID, req.fragment.FragmentID, false)
+	}
 
 	now := time.Now()
 
@@ -598,7 +682,10 @@ func (s *Scheduler) forwardRequestToQuerier(querier schedulerpb.SchedulerForQuer
 			return
 		}
 
-		_, err = querier.Recv()
+		resp, err := querier.Recv()
+		if err == nil {
+			s.reportUsage(req, resp)
+		}
 		errCh <- err
 	}()
 
@@ -620,6 +707,16 @@ func (s *Scheduler) forwardRequestToQuerier(querier schedulerpb.SchedulerForQuer
 	}
 }
 
+// reportUsage accounts the resources consumed by the request to the tenant, used by the weighted fair queuing.
+func (s *Scheduler) reportUsage(req *schedulerRequest, resp *schedulerpb.QuerierToScheduler) {
+	switch s.cfg.FairQueuingCost {
+	case QuerierTimeCost:
+		s.requestQueue.ReportUsage(req.userID, resp.QuerierWallTime.Seconds(), req.estimatedCost)
+	case FetchedBytesCost:
+		s.requestQueue.ReportUsage(req.userID, float64(resp.FetchedBytes), req.estimatedCost)
+	}
+}
+
 func (s *Scheduler) forwardErrorToFrontend(ctx context.Context, req *schedulerRequest, requestErr error) {
 	opts, err := s.cfg.GRPCClientConfig.DialOption([]grpc.UnaryClientInterceptor{
 		otgrpc.OpenTracingClientInterceptor(opentracing.GlobalTracer()),
@@ -349,6 +349,93 @@ func TestSchedulerMaxOutstandingRequests(t *testing.T) {
 	require.True(t, msg.Status == schedulerpb.TOO_MANY_REQUESTS_PER_TENANT)
 }
 
+func TestSchedulerDropsExpiredRequests(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	scheduler, frontendClient, querierClient := setupScheduler(t, reg, false)
+
+	frontendLoop := initFrontendLoop(t, frontendClient, "frontend-12345")
+	frontendToScheduler(t, frontendLoop, &schedulerpb.FrontendToScheduler{
+		Type:        schedulerpb.ENQUEUE,
+		QueryID:     1,
+		UserID:      "test",
+		HttpRequest: &httpgrpc.HTTPRequest{Method: "GET", Url: "/expired"},
+		Deadline:    time.Now().Add(-time.Second).UnixMilli(),
+	})
+	frontendToScheduler(t, frontendLoop, &schedulerpb.FrontendToScheduler{
+		Type:        schedulerpb.ENQUEUE,
+		QueryID:     2,
+		UserID:      "test",
+		HttpRequest: &httpgrpc.HTTPRequest{Method: "GET", Url: "/hello"},
+		Deadline:    time.Now().Add(time.Hour).UnixMilli(),
+	})
+
+	querierLoop := initQuerierLoop(t, querierClient, "querier-1")
+
+	msg, err := querierLoop.Recv()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), msg.QueryID)
+	require.NoError(t, querierLoop.Send(&schedulerpb.QuerierToScheduler{}))
+
+	verifyNoPendingRequestsLeft(t, scheduler)
+	require.NoError(t, promtest.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_query_scheduler_discarded_requests_total Total number of query requests discarded.
+		# TYPE cortex_query_scheduler_discarded_requests_total counter
+		cortex_query_scheduler_discarded_requests_total{priority="0",user="test"} 1
+	`), "cortex_query_scheduler_discarded_requests_total"))
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		setup       func(cfg *Config)
+		expectedErr string
+	}{
+		"default config": {
+			setup: func(*Config) {},
+		},
+		"weighted fair queuing": {
+			setup: func(cfg *Config) {
+				cfg.QueueMode = WeightedFairQueueMode
+				cfg.FairQueuingCost = FetchedBytesCost
+			},
+		},
+		"unsupported queue mode": {
+			setup: func(cfg *Config) {
+				cfg.QueueMode = "random"
+			},
+			expectedErr: `unsupported queue mode "random", supported values are: round-robin, weighted-fair`,
+		},
+		"unsupported fair queuing cost": {
+			setup: func(cfg *Config) {
+				cfg.QueueMode = WeightedFairQueueMode
+				cfg.FairQueuingCost = "samples"
+			},
+			expectedErr: `unsupported fair queuing cost "samples", supported values are: querier-time, fetched-bytes`,
+		},
+		"invalid usage half-life": {
+			setup: func(cfg *Config) {
+				cfg.QueueMode = WeightedFairQueueMode
+				cfg.FairQueuingUsageHalfLife = 0
+			},
+			expectedErr: "the fair queuing usage half-life must be greater than 0",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{}
+			flagext.DefaultValues(&cfg)
+			testData.setup(&cfg)
+
+			err := cfg.Validate()
+			if testData.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, testData.expectedErr)
+			}
+		})
+	}
+}
+
 func TestSchedulerForwardsErrorToFrontend(t *testing.T) {
 	_, frontendClient, querierClient := setupScheduler(t, nil, false)
 
@@ -9,22 +9,26 @@ import (
 	_ "github.com/gogo/protobuf/gogoproto"
 	proto "github.com/gogo/protobuf/proto"
 	github_com_gogo_protobuf_sortkeys "github.com/gogo/protobuf/sortkeys"
+	github_com_gogo_protobuf_types "github.com/gogo/protobuf/types"
 	httpgrpc "github.com/weaveworks/common/httpgrpc"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	_ "google.golang.org/protobuf/types/known/durationpb"
 	io "io"
 	math "math"
 	math_bits "math/bits"
 	reflect "reflect"
 	strconv "strconv"
 	strings "strings"
+	time "time"
 )
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
+var _ = time.Kitchen
 
 // This is a compile-time assertion to ensure that this generated file
 // is compatible with the proto package it is being compiled against.
@@ -84,10 +88,17 @@ func (SchedulerToFrontendStatus) EnumDescriptor() ([]byte, []int) {
 }
 
 // Querier reports its own clientID when it connects, so that scheduler knows how many *different* queriers are connected.
-// To signal that querier is ready to accept another request, querier sends empty message.
+// To signal that querier is ready to accept another request, querier sends a message reporting the resources
+// consumed by the processed request.
 type QuerierToScheduler struct {
 	QuerierID      string `protobuf:"bytes,1,opt,name=querierID,proto3" json:"querierID,omitempty"`
 	QuerierAddress string `protobuf:"bytes,2,opt,name=querierAddress,proto3" json:"querierAddress,omitempty"`
+	// Following are reported when querier signals it finished processing a request, and are used
+	// by the scheduler to account the resources consumed by the tenant.
+	// Wall time spent in the querier to process the request.
+	QuerierWallTime time.Duration `protobuf:"bytes,3,opt,name=querierWallTime,proto3,stdduration" json:"querierWallTime"`
+	// The number of chunk and data bytes fetched to process the request.
+	FetchedBytes uint64 `protobuf:"varint,4,opt,name=fetchedBytes,proto3" json:"fetchedBytes,omitempty"`
 }
 
 func (m *QuerierToScheduler) Reset()      { *m = QuerierToScheduler{} }
@@ -136,6 +147,20 @@ func (m *QuerierToScheduler) GetQuerierAddress() string {
 	return ""
 }
 
+func (m *QuerierToScheduler) GetQuerierWallTime() time.Duration {
+	if m != nil {
+		return m.QuerierWallTime
+	}
+	return 0
+}
+
+func (m *QuerierToScheduler) GetFetchedBytes() uint64 {
+	if m != nil {
+		return m.FetchedBytes
+	}
+	return 0
+}
+
 type SchedulerToQuerier struct {
 	// Query ID as reported by frontend. When querier sends the response back to frontend (using frontendAddress),
 	// it identifies the query by using this ID.
@@ -256,6 +281,9 @@ type FrontendToScheduler struct {
 	UserID       string                `protobuf:"bytes,4,opt,name=userID,proto3" json:"userID,omitempty"`
 	HttpRequest  *httpgrpc.HTTPRequest `protobuf:"bytes,5,opt,name=httpRequest,proto3" json:"httpRequest,omitempty"`
 	StatsEnabled bool                  `protobuf:"varint,6,opt,name=statsEnabled,proto3" json:"statsEnabled,omitempty"`
+	// Deadline of the request as unix timestamp in milliseconds, or 0 if the request has no deadline.
+	// The scheduler drops the request if it's still queued once the deadline has passed.
+	Deadline int64 `protobuf:"varint,7,opt,name=deadline,proto3" json:"deadline,omitempty"`
 }
 
 func (m *FrontendToScheduler) Reset()      { *m = FrontendToScheduler{} }
@@ -332,6 +360,13 @@ func (m *FrontendToScheduler) GetStatsEnabled() bool {
 	return false
 }
 
+func (m *FrontendToScheduler) GetDeadline() int64 {
+	if m != nil {
+		return m.Deadline
+	}
+	return 0
+}
+
 type SchedulerToFrontend struct {
 	Status SchedulerToFrontendStatus `protobuf:"varint,1,opt,name=status,proto3,enum=schedulerpb.SchedulerToFrontendStatus" json:"status,omitempty"`
 	Error  string                    `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
@@ -476,54 +511,60 @@ func init() {
 func init() { proto.RegisterFile("scheduler.proto", fileDescriptor_2b3fc28395a6d9c5) }
 
 var fileDescriptor_2b3fc28395a6d9c5 = []byte{
-	// 750 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0x4d, 0x4f, 0xdb, 0x4c,
-	0x10, 0xf6, 0xe6, 0x0b, 0x98, 0xf0, 0x82, 0xdf, 0x05, 0xde, 0x37, 0x8d, 0xa8, 0x89, 0xac, 0x0a,
-	0xa5, 0x1c, 0x92, 0x2a, 0x54, 0x2a, 0xaa, 0x50, 0xa5, 0x94, 0x98, 0x12, 0x95, 0x3a, 0xe0, 0x38,
-	0x6a, 0x4b, 0x0f, 0x51, 0x3e, 0x96, 0x24, 0x22, 0xf1, 0x1a, 0x7b, 0x0d, 0xca, 0xad, 0xc7, 0x1e,
-	0xfb, 0x27, 0x2a, 0xf5, 0xa7, 0xf4, 0xc8, 0x91, 0x43, 0x0f, 0xc5, 0x5c, 0x7a, 0xe4, 0x27, 0x54,
-	0x76, 0xec, 0xd4, 0x09, 0x09, 0x70, 0x9b, 0x19, 0x3f, 0x33, 0x3b, 0xf3, 0x3c, 0xb3, 0x5e, 0x58,
-	0x34, 0x1b, 0x6d, 0xd2, 0xb4, 0xba, 0xc4, 0xc8, 0xe8, 0x06, 0x65, 0x14, 0xc7, 0x87, 0x01, 0xbd,
-	0x9e, 0x5c, 0x6e, 0xd1, 0x16, 0x75, 0xe3, 0x59, 0xc7, 0x1a, 0x40, 0x92, 0xcf, 0x5b, 0x1d, 0xd6,
-	0xb6, 0xea, 0x99, 0x06, 0xed, 0x65, 0xcf, 0x49, 0xed, 0x8c, 0x9c, 0x53, 0xe3, 0xc4, 0xcc, 0x36,
-	0x68, 0xaf, 0x47, 0xb5, 0x6c, 0x9b, 0x31, 0xbd, 0x65, 0xe8, 0x8d, 0xa1, 0x31, 0xc8, 0x12, 0x8f,
-	0x00, 0x1f, 0x5a, 0xc4, 0xe8, 0x10, 0x43, 0xa5, 0x65, 0xff, 0x0c, 0xbc, 0x0a, 0x73, 0xa7, 0x83,
-	0x68, 0xb1, 0x90, 0x40, 0x29, 0x94, 0x9e, 0x53, 0xfe, 0x06, 0xf0, 0x3a, 0x2c, 0x78, 0x4e, 0xbe,
-	0xd9, 0x34, 0x88, 0x69, 0x26, 0x42, 0x2e, 0x64, 0x2c, 0x2a, 0x7e, 0x0b, 0x03, 0x1e, 0xd6, 0x54,
-	0xa9, 0x77, 0x0e, 0x4e, 0xc0, 0x8c, 0x03, 0xec, 0x7b, 0xa5, 0x23, 0x8a, 0xef, 0xe2, 0x17, 0x10,
-	0x77, 0xda, 0x53, 0xc8, 0xa9, 0x45, 0x4c, 0xe6, 0x56, 0x8d, 0xe7, 0x56, 0x32, 0xc3, 0x96, 0xf7,
-	0x54, 0xf5, 0xc0, 0xfb, 0xa8, 0x04, 0x91, 0x38, 0x0d, 0x8b, 0xc7, 0x06, 0xd5, 0x18, 0xd1, 0x9a,
-	0x7e, 0x4b, 0x61, 0xb7, 0xa5, 0xf1, 0x30, 0xfe, 0x0f, 0x62, 0x96, 0xe9, 0x8e, 0x15, 0x71, 0x01,
-	0x9e, 0x87, 0x45, 0x98, 0x37, 0x59, 0x8d, 0x99, 0x92, 0x56, 0xab, 0x77, 0x49, 0x33, 0x11, 0x4d,
-	0xa1, 0xf4, 0xac, 0x32, 0x12, 0xc3, 0x02, 0xc0, 0xb1, 0x51, 0x6b, 0xf5, 0x88, 0xc6, 0x8a, 0x85,
-	0x44, 0xcc, 0xed, 0x3d, 0x10, 0xc1, 0x9f, 0x60, 0xa1, 0xd1, 0xee, 0x74, 0x9b, 0xc5, 0x02, 0xa3,
-	0xce, 0x79, 0x66, 0x62, 0x26, 0x15, 0x4e, 0xc7, 0x73, 0x9b, 0x99, 0x80, 0x7a, 0x99, 0xdb, 0x8c,
-	0x64, 0x76, 0x46, 0xb2, 0x24, 0x8d, 0x19, 0x7d, 0x65, 0xac, 0x94, 0xd3, 0x78, 0xc7, 0x54, 0x28,
-	0x65, 0x89, 0x59, 0xb7, 0x35, 0xcf, 0x4b, 0xe6, 0x61, 0x69, 0x42, 0x3a, 0xe6, 0x21, 0x7c, 0x42,
-	0xfa, 0x1e, 0xc1, 0x8e, 0x89, 0x97, 0x21, 0x7a, 0x56, 0xeb, 0x5a, 0xc4, 0x13, 0x6b, 0xe0, 0xbc,
-	0x0c, 0x6d, 0x21, 0xf1, 0x4b, 0x08, 0x96, 0x76, 0x3d, 0x9e, 0x82, 0x5b, 0xb0, 0x05, 0x11, 0xd6,
-	0xd7, 0x89, 0x5b, 0x64, 0x21, 0xf7, 0x64, 0x64, 0x8a, 0x09, 0x78, 0xb5, 0xaf, 0x13, 0xc5, 0xcd,
-	0x98, 0xa4, 0x47, 0x68, 0xb2, 0x1e, 0x81, 0x65, 0x08, 0x8f, 0x2e, 0xc3, 0x34, 0xa5, 0xc6, 0x96,
-	0x24, 0xfa, 0xe0, 0x25, 0x19, 0x97, 0x38, 0x76, 0x5b, 0x62, 0xf1, 0x04, 0x96, 0x02, 0xfa, 0xf8,
-	0x43, 0xe2, 0x57, 0x10, 0x73, 0x60, 0x96, 0xe9, 0x71, 0xb1, 0x3e, 0x4d, 0x51, 0x3f, 0xa3, 0xec,
-	0xa2, 0x15, 0x2f, 0xcb, 0xe1, 0x9e, 0x18, 0x06, 0x35, 0x7c, 0xee, 0x5d, 0x47, 0xdc, 0x86, 0x55,
-	0x99, 0xb2, 0xce, 0x71, 0xdf, 0xdb, 0x83, 0x72, 0xdb, 0x62, 0x4d, 0x7a, 0xae, 0xf9, 0x0d, 0xdf,
-	0x79, 0x0b, 0xc5, 0x35, 0x78, 0x3c, 0x25, 0xdb, 0xd4, 0xa9, 0x66, 0x92, 0x8d, 0x6d, 0xf8, 0x7f,
-	0x8a, 0x4a, 0x78, 0x16, 0x22, 0x45, 0xb9, 0xa8, 0xf2, 0x1c, 0x8e, 0xc3, 0x8c, 0x24, 0x1f, 0x56,
-	0xa4, 0x8a, 0xc4, 0x23, 0x0c, 0x10, 0xdb, 0xc9, 0xcb, 0x3b, 0xd2, 0x3e, 0x1f, 0xda, 0x68, 0xc0,
-	0xa3, 0xa9, 0x73, 0xe1, 0x18, 0x84, 0x4a, 0x6f, 0x79, 0x0e, 0xa7, 0x60, 0x55, 0x2d, 0x95, 0xaa,
-	0xef, 0xf2, 0xf2, 0xc7, 0xaa, 0x22, 0x1d, 0x56, 0xa4, 0xb2, 0x5a, 0xae, 0x1e, 0x48, 0x4a, 0x55,
-	0x95, 0xe4, 0xbc, 0xac, 0xf2, 0x08, 0xcf, 0x41, 0x54, 0x52, 0x94, 0x92, 0xc2, 0x87, 0xf0, 0xbf,
-	0xf0, 0x4f, 0x79, 0xaf, 0xa2, 0xaa, 0x45, 0xf9, 0x4d, 0xb5, 0x50, 0x7a, 0x2f, 0xf3, 0xe1, 0xdc,
-	0x4f, 0x14, 0xe0, 0x7b, 0x97, 0x1a, 0xfe, 0x2f, 0xa2, 0x02, 0x71, 0xcf, 0xdc, 0xa7, 0x54, 0xc7,
-	0x6b, 0x23, 0x74, 0xdf, 0xfe, 0x5f, 0x25, 0xd7, 0xee, 0xb9, 0x61, 0x22, 0x97, 0x46, 0xcf, 0x10,
-	0xd6, 0x60, 0x65, 0x22, 0x65, 0xf8, 0xe9, 0x48, 0xfe, 0x5d, 0xa2, 0x24, 0x37, 0x1e, 0x02, 0x1d,
-	0x28, 0x90, 0xd3, 0x61, 0x39, 0x38, 0xdd, 0x70, 0x9d, 0x3e, 0xc0, 0xbc, 0x6f, 0xbb, 0xf3, 0xa5,
-	0xee, 0xbb, 0x5a, 0xc9, 0xd4, 0x7d, 0x0b, 0x37, 0x98, 0xf0, 0x75, 0xfe, 0xe2, 0x4a, 0xe0, 0x2e,
-	0xaf, 0x04, 0xee, 0xe6, 0x4a, 0x40, 0x9f, 0x6d, 0x01, 0x7d, 0xb7, 0x05, 0xf4, 0xc3, 0x16, 0xd0,
-	0x85, 0x2d, 0xa0, 0x5f, 0xb6, 0x80, 0x7e, 0xdb, 0x02, 0x77, 0x63, 0x0b, 0xe8, 0xeb, 0xb5, 0xc0,
-	0x5d, 0x5c, 0x0b, 0xdc, 0xe5, 0xb5, 0xc0, 0x1d, 0x05, 0x5f, 0x97, 0x7a, 0xcc, 0x7d, 0x18, 0x36,
-	0xff, 0x04, 0x00, 0x00, 0xff, 0xff, 0xee, 0xbe, 0x64, 0x48, 0x84, 0x06, 0x00, 0x00,
+	// 846 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0x4f, 0x8f, 0xda, 0x46,
+	0x14, 0xf7, 0x00, 0x0b, 0xec, 0x23, 0xdd, 0xa5, 0xb3, 0x9b, 0xd6, 0x41, 0x5b, 0x63, 0xa1, 0x2a,
+	0xa2, 0x7b, 0x30, 0x15, 0xa9, 0xd4, 0xa8, 0x8a, 0x2a, 0xb1, 0x8b, 0xd3, 0xa0, 0x26, 0x26, 0x3b,
+	0x18, 0xa5, 0x7f, 0x0e, 0x08, 0xf0, 0xf0, 0x47, 0x0b, 0x1e, 0xc7, 0x1e, 0x67, 0xc5, 0xad, 0x1f,
+	0xa1, 0xc7, 0x7e, 0x80, 0x56, 0xea, 0x47, 0xc9, 0xa5, 0xd2, 0x1e, 0x73, 0xa8, 0xda, 0x2e, 0x7b,
+	0xe9, 0x31, 0x1f, 0xa1, 0xb2, 0x19, 0x53, 0x43, 0x20, 0xbb, 0xb7, 0xf7, 0x9e, 0xdf, 0x1b, 0xbf,
+	0xdf, 0x1f, 0x8f, 0x61, 0xdf, 0xeb, 0x8f, 0xa8, 0xe5, 0x4f, 0xa8, 0xab, 0x39, 0x2e, 0xe3, 0x0c,
+	0xe7, 0x96, 0x05, 0xa7, 0x57, 0x38, 0x1c, 0xb2, 0x21, 0x0b, 0xeb, 0x95, 0x20, 0x5a, 0xb4, 0x14,
+	0xbe, 0x18, 0x8e, 0xf9, 0xc8, 0xef, 0x69, 0x7d, 0x36, 0xad, 0x5c, 0xd0, 0xee, 0x2b, 0x7a, 0xc1,
+	0xdc, 0x73, 0xaf, 0xd2, 0x67, 0xd3, 0x29, 0xb3, 0x2b, 0x23, 0xce, 0x9d, 0xa1, 0xeb, 0xf4, 0x97,
+	0x81, 0x98, 0x52, 0x86, 0x8c, 0x0d, 0x27, 0xb4, 0x12, 0x66, 0x3d, 0x7f, 0x50, 0xb1, 0x7c, 0xb7,
+	0xcb, 0xc7, 0xcc, 0x5e, 0x3c, 0x2f, 0xfd, 0x81, 0x00, 0x9f, 0xf9, 0xd4, 0x1d, 0x53, 0xd7, 0x64,
+	0xad, 0x68, 0x09, 0x7c, 0x04, 0xbb, 0x2f, 0x17, 0xd5, 0x46, 0x5d, 0x46, 0x2a, 0x2a, 0xef, 0x92,
+	0xff, 0x0b, 0xf8, 0x3e, 0xec, 0x89, 0xa4, 0x66, 0x59, 0x2e, 0xf5, 0x3c, 0x39, 0x11, 0xb6, 0xac,
+	0x55, 0xf1, 0x33, 0xd8, 0x17, 0x95, 0x17, 0xdd, 0xc9, 0xc4, 0x1c, 0x4f, 0xa9, 0x9c, 0x54, 0x51,
+	0x39, 0x57, 0xbd, 0xa7, 0x2d, 0xd6, 0xd2, 0xa2, 0xb5, 0xb4, 0xba, 0x58, 0xeb, 0x24, 0xfb, 0xfa,
+	0xaf, 0xa2, 0xf4, 0xcb, 0xdf, 0x45, 0x44, 0xd6, 0x67, 0x71, 0x09, 0xee, 0x0c, 0x28, 0x0f, 0x76,
+	0x3c, 0x99, 0x71, 0xea, 0xc9, 0x29, 0x15, 0x95, 0x53, 0x64, 0xa5, 0x56, 0xfa, 0x2d, 0x09, 0x78,
+	0x09, 0xc3, 0x64, 0x02, 0x1a, 0x96, 0x21, 0x13, 0x9c, 0x36, 0x13, 0x68, 0x52, 0x24, 0x4a, 0xf1,
+	0x97, 0x90, 0x0b, 0x28, 0x23, 0xf4, 0xa5, 0x4f, 0x3d, 0x1e, 0x02, 0xc9, 0x55, 0xef, 0x6a, 0x4b,
+	0x1a, 0x9f, 0x98, 0xe6, 0x73, 0xf1, 0x90, 0xc4, 0x3b, 0x71, 0x19, 0xf6, 0x07, 0x2e, 0xb3, 0x39,
+	0xb5, 0xad, 0x88, 0x85, 0x64, 0xc8, 0xc2, 0x7a, 0x19, 0x7f, 0x04, 0x69, 0xdf, 0x0b, 0x99, 0x4c,
+	0x85, 0x0d, 0x22, 0x0b, 0xf0, 0x78, 0xbc, 0xcb, 0x3d, 0xdd, 0xee, 0xf6, 0x26, 0xd4, 0x92, 0x77,
+	0x54, 0x54, 0xce, 0x92, 0x95, 0x1a, 0x56, 0x00, 0x06, 0x6e, 0x77, 0x38, 0xa5, 0x36, 0x6f, 0xd4,
+	0xe5, 0x74, 0xb8, 0x7b, 0xac, 0x82, 0x7f, 0x84, 0xbd, 0xfe, 0x68, 0x3c, 0xb1, 0x1a, 0x75, 0xce,
+	0x82, 0xf7, 0x79, 0x72, 0x46, 0x4d, 0x96, 0x73, 0xd5, 0x07, 0x5a, 0xcc, 0x51, 0xda, 0xbb, 0x8c,
+	0x68, 0xa7, 0x2b, 0x53, 0xba, 0xcd, 0xdd, 0x19, 0x59, 0x3b, 0x2a, 0x58, 0x7c, 0xec, 0x11, 0xc6,
+	0xb8, 0x9c, 0x0d, 0x57, 0x13, 0x59, 0xa1, 0x06, 0x07, 0x1b, 0xc6, 0x71, 0x1e, 0x92, 0xe7, 0x74,
+	0x26, 0x08, 0x0e, 0x42, 0x7c, 0x08, 0x3b, 0xaf, 0xba, 0x13, 0x9f, 0x0a, 0x7f, 0x2c, 0x92, 0xaf,
+	0x12, 0x0f, 0x51, 0xe9, 0xd7, 0x04, 0x1c, 0x3c, 0x16, 0x3c, 0xc5, 0x8d, 0xf7, 0x10, 0x52, 0x7c,
+	0xe6, 0xd0, 0xf0, 0x90, 0xbd, 0xea, 0xa7, 0x2b, 0x28, 0x36, 0xf4, 0x9b, 0x33, 0x87, 0x92, 0x70,
+	0x62, 0x93, 0x1e, 0x89, 0xcd, 0x7a, 0xc4, 0xcc, 0x90, 0x5c, 0x35, 0xc3, 0x36, 0xa5, 0xd6, 0x4c,
+	0xb2, 0x73, 0x6b, 0x93, 0xac, 0x4b, 0x9c, 0xde, 0x20, 0x71, 0x01, 0xb2, 0x16, 0xed, 0x5a, 0x93,
+	0xb1, 0x4d, 0xe5, 0x8c, 0x8a, 0xca, 0x49, 0xb2, 0xcc, 0x4b, 0xe7, 0x70, 0x10, 0xd3, 0x2e, 0x22,
+	0x00, 0x7f, 0x0d, 0xe9, 0xe0, 0x08, 0xdf, 0x13, 0x3c, 0xdd, 0xdf, 0xa6, 0x76, 0x34, 0xd1, 0x0a,
+	0xbb, 0x89, 0x98, 0x0a, 0x74, 0xa1, 0xae, 0xcb, 0xdc, 0x48, 0x97, 0x30, 0x29, 0x3d, 0x82, 0x23,
+	0x83, 0xf1, 0xf1, 0x60, 0x26, 0x3c, 0xd2, 0x1a, 0xf9, 0xdc, 0x62, 0x17, 0x76, 0x04, 0xe6, 0xbd,
+	0x97, 0x42, 0xa9, 0x08, 0x9f, 0x6c, 0x99, 0xf6, 0x1c, 0x66, 0x7b, 0xf4, 0xf8, 0x11, 0x7c, 0xbc,
+	0x45, 0x41, 0x9c, 0x85, 0x54, 0xc3, 0x68, 0x98, 0x79, 0x09, 0xe7, 0x20, 0xa3, 0x1b, 0x67, 0x6d,
+	0xbd, 0xad, 0xe7, 0x11, 0x06, 0x48, 0x9f, 0xd6, 0x8c, 0x53, 0xfd, 0x69, 0x3e, 0x71, 0xdc, 0x87,
+	0x7b, 0x5b, 0x71, 0xe1, 0x34, 0x24, 0x9a, 0xdf, 0xe6, 0x25, 0xac, 0xc2, 0x91, 0xd9, 0x6c, 0x76,
+	0x9e, 0xd5, 0x8c, 0xef, 0x3b, 0x44, 0x3f, 0x6b, 0xeb, 0x2d, 0xb3, 0xd5, 0x79, 0xae, 0x93, 0x8e,
+	0xa9, 0x1b, 0x35, 0xc3, 0xcc, 0x23, 0xbc, 0x0b, 0x3b, 0x3a, 0x21, 0x4d, 0x92, 0x4f, 0xe0, 0x0f,
+	0xe1, 0x83, 0xd6, 0x93, 0xb6, 0x69, 0x36, 0x8c, 0x6f, 0x3a, 0xf5, 0xe6, 0x0b, 0x23, 0x9f, 0xac,
+	0xfe, 0x89, 0x62, 0x7c, 0x3f, 0x66, 0x6e, 0x74, 0x7d, 0xb4, 0x21, 0x27, 0xc2, 0xa7, 0x8c, 0x39,
+	0xb8, 0xb8, 0x42, 0xf7, 0xbb, 0xd7, 0x67, 0xa1, 0x78, 0xc3, 0xd7, 0x57, 0x92, 0xca, 0xe8, 0x73,
+	0x84, 0x6d, 0xb8, 0xbb, 0x91, 0x32, 0xfc, 0xd9, 0xca, 0xfc, 0xfb, 0x44, 0x29, 0x1c, 0xdf, 0xa6,
+	0x75, 0xa1, 0x40, 0xd5, 0x81, 0xc3, 0x38, 0xba, 0xa5, 0x9d, 0xbe, 0x83, 0x3b, 0x51, 0x1c, 0xe2,
+	0x53, 0x6f, 0xfa, 0xec, 0x0a, 0xea, 0x4d, 0x86, 0x5b, 0x20, 0x3c, 0xa9, 0x5d, 0x5e, 0x29, 0xd2,
+	0x9b, 0x2b, 0x45, 0x7a, 0x7b, 0xa5, 0xa0, 0x9f, 0xe6, 0x0a, 0xfa, 0x7d, 0xae, 0xa0, 0xd7, 0x73,
+	0x05, 0x5d, 0xce, 0x15, 0xf4, 0xcf, 0x5c, 0x41, 0xff, 0xce, 0x15, 0xe9, 0xed, 0x5c, 0x41, 0x3f,
+	0x5f, 0x2b, 0xd2, 0xe5, 0xb5, 0x22, 0xbd, 0xb9, 0x56, 0xa4, 0x1f, 0xe2, 0x7f, 0xc3, 0x5e, 0x3a,
+	0xfc, 0x47, 0x3c, 0xf8, 0x6f, 0x00, 0xcd, 0x36, 0x1e, 0x52, 0x34, 0x07, 0x00, 0x00,
 }
 
 func (x FrontendToSchedulerType) String() string {
@@ -565,6 +606,12 @@ func (this *QuerierToScheduler) Equal(that interface{}) bool {
 	if this.QuerierAddress != that1.QuerierAddress {
 		return false
 	}
+	if this.QuerierWallTime != that1.QuerierWallTime {
+		return false
+	}
+	if this.FetchedBytes != that1.FetchedBytes {
+		return false
+	}
 	return true
 }
 func (this *SchedulerToQuerier) Equal(that interface{}) bool {
@@ -654,6 +701,9 @@ func (this *FrontendToScheduler) Equal(that interface{}) bool {
 	if this.StatsEnabled != that1.StatsEnabled {
 		return false
 	}
+	if this.Deadline != that1.Deadline {
+		return false
+	}
 	return true
 }
 func (this *SchedulerToFrontend) Equal(that interface{}) bool {
@@ -732,10 +782,12 @@ func (this *QuerierToScheduler) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 6)
+	s := make([]string, 0, 8)
 	s = append(s, "&schedulerpb.QuerierToScheduler{")
 	s = append(s, "QuerierID: "+fmt.Sprintf("%#v", this.QuerierID)+",\n")
 	s = append(s, "QuerierAddress: "+fmt.Sprintf("%#v", this.QuerierAddress)+",\n")
+	s = append(s, "QuerierWallTime: "+fmt.Sprintf("%#v", this.QuerierWallTime)+",\n")
+	s = append(s, "FetchedBytes: "+fmt.Sprintf("%#v", this.FetchedBytes)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -774,7 +826,7 @@ func (this *FrontendToScheduler) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 10)
+	s := make([]string, 0, 11)
 	s = append(s, "&schedulerpb.FrontendToScheduler{")
 	s = append(s, "Type: "+fmt.Sprintf("%#v", this.Type)+",\n")
 	s = append(s, "FrontendAddress: "+fmt.Sprintf("%#v", this.FrontendAddress)+",\n")
@@ -784,6 +836,7 @@ func (this *FrontendToScheduler) GoString() string {
 		s = append(s, "HttpRequest: "+fmt.Sprintf("%#v", this.HttpRequest)+",\n")
 	}
 	s = append(s, "StatsEnabled: "+fmt.Sprintf("%#v", this.StatsEnabled)+",\n")
+	s = append(s, "Deadline: "+fmt.Sprintf("%#v", this.Deadline)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -1125,6 +1178,19 @@ func (m *QuerierToScheduler) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.FetchedBytes != 0 {
+		i = encodeVarintScheduler(dAtA, i, uint64(m.FetchedBytes))
+		i--
+		dAtA[i] = 0x20
+	}
+	n1, err1 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.QuerierWallTime, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.QuerierWallTime):])
+	if err1 != nil {
+		return 0, err1
+	}
+	i -= n1
+	i = encodeVarintScheduler(dAtA, i, uint64(n1))
+	i--
+	dAtA[i] = 0x1a
 	if len(m.QuerierAddress) > 0 {
 		i -= len(m.QuerierAddress)
 		copy(dAtA[i:], m.QuerierAddress)
@@ -1258,6 +1324,11 @@ func (m *FrontendToScheduler) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Deadline != 0 {
+		i = encodeVarintScheduler(dAtA, i, uint64(m.Deadline))
+		i--
+		dAtA[i] = 0x38
+	}
 	if m.StatsEnabled {
 		i--
 		if m.StatsEnabled {
@@ -1420,6 +1491,11 @@ func (m *QuerierToScheduler) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovScheduler(uint64(l))
 	}
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.QuerierWallTime)
+	n += 1 + l + sovScheduler(uint64(l))
+	if m.FetchedBytes != 0 {
+		n += 1 + sovScheduler(uint64(m.FetchedBytes))
+	}
 	return n
 }
 
@@ -1491,6 +1567,9 @@ func (m *FrontendToScheduler) Size() (n int) {
 	if m.StatsEnabled {
 		n += 2
 	}
+	if m.Deadline != 0 {
+		n += 1 + sovScheduler(uint64(m.Deadline))
+	}
 	return n
 }
 
@@ -1545,6 +1624,8 @@ func (this *QuerierToScheduler) String() string {
 	s := strings.Join([]string{`&QuerierToScheduler{`,
 		`QuerierID:` + fmt.Sprintf("%v", this.QuerierID) + `,`,
 		`QuerierAddress:` + fmt.Sprintf("%v", this.QuerierAddress) + `,`,
+		`QuerierWallTime:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.QuerierWallTime), "Duration", "durationpb.Duration", 1), `&`, ``, 1) + `,`,
+		`FetchedBytes:` + fmt.Sprintf("%v", this.FetchedBytes) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1587,6 +1668,7 @@ func (this *FrontendToScheduler) String() string {
 		`UserID:` + fmt.Sprintf("%v", this.UserID) + `,`,
 		`HttpRequest:` + strings.Replace(fmt.Sprintf("%v", this.HttpRequest), "HTTPRequest", "httpgrpc.HTTPRequest", 1) + `,`,
 		`StatsEnabled:` + fmt.Sprintf("%v", this.StatsEnabled) + `,`,
+		`Deadline:` + fmt.Sprintf("%v", this.Deadline) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1722,6 +1804,58 @@ func (m *QuerierToScheduler) Unmarshal(dAtA []byte) error {
 			}
 			m.QuerierAddress = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field QuerierWallTime", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowScheduler
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthScheduler
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthScheduler
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.QuerierWallTime, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FetchedBytes", wireType)
+			}
+			m.FetchedBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowScheduler
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.FetchedBytes |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipScheduler(dAtA[iNdEx:])
@@ -2277,6 +2411,25 @@ func (m *FrontendToScheduler) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.StatsEnabled = bool(v != 0)
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Deadline", wireType)
+			}
+			m.Deadline = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowScheduler
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Deadline |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipScheduler(dAtA[iNdEx:])
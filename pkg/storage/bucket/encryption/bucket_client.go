@@ -0,0 +1,394 @@
+package encryption
+
+import (
+	"bufio"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+	"strings"
+
+	"github.com/go-kit/log"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/pkg/errors"
+	"github.com/thanos-io/objstore"
+
+	"github.com/cortexproject/cortex/pkg/util/users"
+)
+
+// headerCacheSize is the number of object headers cached for the range reads.
+const headerCacheSize = 10000
+
+// objectInfo is the information needed to read a range of an object.
+type objectInfo struct {
+	encrypted bool
+
+	header header
+	aead   cipher.AEAD
+	// size is the size of the encrypted object.
+	size int64
+}
+
+// BucketClient is a wrapper around a objstore.Bucket encrypting the objects of each tenant
+// on the client side. Each object is encrypted with its own data key, wrapped by the key
+// of the tenant of the object, which is the first component of the object name.
+//
+// The objects which don't belong to a tenant, and the objects uploaded before enabling
+// the encryption, are stored and read as they are.
+type BucketClient struct {
+	bucket      objstore.Bucket
+	keyProvider KeyProvider
+
+	// headers caches the information needed by the range reads, which are only issued
+	// on immutable objects like the blocks files.
+	headers *lru.Cache[string, *objectInfo]
+}
+
+// NewBucketClient makes a new BucketClient encrypting the objects with data keys wrapped by the input key provider.
+func NewBucketClient(bucket objstore.Bucket, keyProvider KeyProvider) (*BucketClient, error) {
+	headers, err := lru.New[string, *objectInfo](headerCacheSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BucketClient{
+		bucket:      bucket,
+		keyProvider: keyProvider,
+		headers:     headers,
+	}, nil
+}
+
+// NewMiddleware returns a bucket middleware encrypting the objects with the configured key provider.
+func NewMiddleware(cfg Config, logger log.Logger) (func(objstore.InstrumentedBucket) (objstore.InstrumentedBucket, error), error) {
+	keyProvider, err := NewKeyProvider(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(bucket objstore.InstrumentedBucket) (objstore.InstrumentedBucket, error) {
+		return NewBucketClient(bucket, keyProvider)
+	}, nil
+}
+
+// tenantOf returns the tenant owning the input object, if any.
+func tenantOf(name string) (string, bool) {
+	userID, _, ok := strings.Cut(name, objstore.DirDelim)
+	if !ok || userID == "" || userID == users.GlobalMarkersDir {
+		return "", false
+	}
+	return userID, true
+}
+
+// Close implements objstore.Bucket.
+func (b *BucketClient) Close() error {
+	return b.bucket.Close()
+}
+
+// Provider implements objstore.Bucket.
+func (b *BucketClient) Provider() objstore.ObjProvider {
+	return b.bucket.Provider()
+}
+
+// Name implements objstore.Bucket.
+func (b *BucketClient) Name() string {
+	return b.bucket.Name()
+}
+
+// Upload implements objstore.Bucket.
+func (b *BucketClient) Upload(ctx context.Context, name string, r io.Reader, opts ...objstore.ObjectUploadOption) error {
+	userID, ok := tenantOf(name)
+	if !ok {
+		return b.bucket.Upload(ctx, name, r, opts...)
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return err
+	}
+
+	keyID, wrappedKey, err := b.keyProvider.WrapKey(ctx, userID, dataKey)
+	if err != nil {
+		return errors.Wrapf(err, "wrap the data key of %s", name)
+	}
+
+	encodedHeader := encodeHeader(defaultChunkSize, keyID, wrappedKey)
+	h, err := decodeHeader(encodedHeader)
+	if err != nil {
+		return err
+	}
+
+	size, err := objstore.TryToGetSize(r)
+	if err != nil {
+		size = -1
+	}
+
+	b.headers.Remove(name)
+	return b.bucket.Upload(ctx, name, newEncryptReader(r, size, aead, userID, h, encodedHeader), opts...)
+}
+
+// Delete implements objstore.Bucket.
+func (b *BucketClient) Delete(ctx context.Context, name string) error {
+	b.headers.Remove(name)
+	return b.bucket.Delete(ctx, name)
+}
+
+// Iter implements objstore.Bucket.
+func (b *BucketClient) Iter(ctx context.Context, dir string, f func(string) error, options ...objstore.IterOption) error {
+	return b.bucket.Iter(ctx, dir, f, options...)
+}
+
+// IterWithAttributes implements objstore.Bucket. The iterated attributes don't include the
+// size of the objects, which is only reported by Attributes, as the size of the plaintext.
+func (b *BucketClient) IterWithAttributes(ctx context.Context, dir string, f func(attrs objstore.IterObjectAttributes) error, options ...objstore.IterOption) error {
+	return b.bucket.IterWithAttributes(ctx, dir, f, options...)
+}
+
+// SupportedIterOptions implements objstore.Bucket.
+func (b *BucketClient) SupportedIterOptions() []objstore.IterOptionType {
+	return b.bucket.SupportedIterOptions()
+}
+
+// Get implements objstore.Bucket.
+func (b *BucketClient) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	r, err := b.bucket.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, ok := tenantOf(name)
+	if !ok {
+		return r, nil
+	}
+
+	br := bufio.NewReader(r)
+	if prefix, _ := br.Peek(len(headerMagic)); !isEncrypted(prefix) {
+		return &bufferedReadCloser{Reader: br, rc: r}, nil
+	}
+
+	d, err := b.newDecryptReader(ctx, userID, name, r, br)
+	if err != nil {
+		_ = r.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+func (b *BucketClient) newDecryptReader(ctx context.Context, userID, name string, r io.ReadCloser, br *bufio.Reader) (*decryptReader, error) {
+	prefix, err := br.Peek(headerFixedSize)
+	if err != nil {
+		return nil, errors.Wrapf(errInvalidHeader, "read the header of %s", name)
+	}
+	length, err := headerLength(prefix)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read the header of %s", name)
+	}
+
+	encodedHeader := make([]byte, length)
+	if _, err := io.ReadFull(br, encodedHeader); err != nil {
+		return nil, errors.Wrapf(errInvalidHeader, "read the header of %s", name)
+	}
+	h, err := decodeHeader(encodedHeader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read the header of %s", name)
+	}
+
+	aead, err := b.unwrapKey(ctx, userID, name, h)
+	if err != nil {
+		return nil, err
+	}
+
+	size := int64(-1)
+	if encryptedSize, err := objstore.TryToGetSize(r); err == nil {
+		size = h.plaintextSize(encryptedSize)
+	}
+
+	return &decryptReader{
+		closer:    r,
+		r:         br,
+		aead:      aead,
+		userID:    userID,
+		lastIndex: -1,
+		remaining: -1,
+		size:      size,
+		in:        make([]byte, h.chunkSize+tagSize),
+		out:       make([]byte, 0, h.chunkSize),
+	}, nil
+}
+
+func (b *BucketClient) unwrapKey(ctx context.Context, userID, name string, h header) (cipher.AEAD, error) {
+	dataKey, err := b.keyProvider.UnwrapKey(ctx, userID, h.keyID, h.wrappedKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unwrap the data key of %s", name)
+	}
+	return newAEAD(dataKey)
+}
+
+// GetRange implements objstore.Bucket.
+func (b *BucketClient) GetRange(ctx context.Context, name string, off, length int64) (io.ReadCloser, error) {
+	userID, ok := tenantOf(name)
+	if !ok {
+		return b.bucket.GetRange(ctx, name, off, length)
+	}
+
+	info, err := b.objectInfo(ctx, userID, name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.encrypted {
+		return b.bucket.GetRange(ctx, name, off, length)
+	}
+
+	size := info.header.plaintextSize(info.size)
+	end := size
+	if length >= 0 {
+		end = min(off+length, size)
+	}
+	if off >= end {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+
+	// Read the chunks containing the requested range.
+	chunkSize := int64(info.header.chunkSize)
+	encryptedChunkSize := chunkSize + tagSize
+	first, last := off/chunkSize, (end-1)/chunkSize
+	encryptedOff := int64(info.header.length) + first*encryptedChunkSize
+	encryptedEnd := min(int64(info.header.length)+(last+1)*encryptedChunkSize, info.size)
+
+	r, err := b.bucket.GetRange(ctx, name, encryptedOff, encryptedEnd-encryptedOff)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptReader{
+		closer:    r,
+		r:         bufio.NewReader(r),
+		aead:      info.aead,
+		userID:    userID,
+		index:     uint64(first),
+		lastIndex: (size - 1) / chunkSize,
+		skip:      off - first*chunkSize,
+		remaining: end - off,
+		size:      end - off,
+		// The object may have been overwritten since its header has been cached.
+		onDecryptErr: func() { b.headers.Remove(name) },
+		in:           make([]byte, encryptedChunkSize),
+		out:          make([]byte, 0, chunkSize),
+	}, nil
+}
+
+// objectInfo returns the information needed to read a range of the input object.
+func (b *BucketClient) objectInfo(ctx context.Context, userID, name string) (*objectInfo, error) {
+	if info, ok := b.headers.Get(name); ok {
+		// The data key of a cached header stops being used once the key wrapping it is removed.
+		if !info.encrypted || b.keyProvider.KeyAvailable(ctx, userID, info.header.keyID) {
+			return info, nil
+		}
+		b.headers.Remove(name)
+	}
+
+	attrs, err := b.bucket.Attributes(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := b.readRange(ctx, name, 0, min(headerPrefetchSize, attrs.Size))
+	if err != nil {
+		return nil, err
+	}
+
+	info := &objectInfo{encrypted: isEncrypted(prefix), size: attrs.Size}
+	if info.encrypted {
+		length, err := headerLength(prefix)
+		if err != nil || int64(length) > attrs.Size {
+			return nil, errors.Wrapf(errInvalidHeader, "read the header of %s", name)
+		}
+		if length > len(prefix) {
+			if prefix, err = b.readRange(ctx, name, 0, int64(length)); err != nil {
+				return nil, err
+			}
+		}
+
+		if info.header, err = decodeHeader(prefix); err != nil {
+			return nil, errors.Wrapf(err, "read the header of %s", name)
+		}
+		if info.aead, err = b.unwrapKey(ctx, userID, name, info.header); err != nil {
+			return nil, err
+		}
+	}
+
+	b.headers.Add(name, info)
+	return info, nil
+}
+
+func (b *BucketClient) readRange(ctx context.Context, name string, off, length int64) ([]byte, error) {
+	if length <= 0 {
+		return nil, nil
+	}
+
+	r, err := b.bucket.GetRange(ctx, name, off, length)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+
+	return io.ReadAll(r)
+}
+
+// Exists implements objstore.Bucket.
+func (b *BucketClient) Exists(ctx context.Context, name string) (bool, error) {
+	return b.bucket.Exists(ctx, name)
+}
+
+// IsObjNotFoundErr implements objstore.Bucket.
+func (b *BucketClient) IsObjNotFoundErr(err error) bool {
+	return b.bucket.IsObjNotFoundErr(err)
+}
+
+// IsAccessDeniedErr implements objstore.Bucket.
+func (b *BucketClient) IsAccessDeniedErr(err error) bool {
+	return b.bucket.IsAccessDeniedErr(err)
+}
+
+// Attributes implements objstore.Bucket. The size of an encrypted object is the size of its plaintext.
+func (b *BucketClient) Attributes(ctx context.Context, name string) (objstore.ObjectAttributes, error) {
+	userID, ok := tenantOf(name)
+	if !ok {
+		return b.bucket.Attributes(ctx, name)
+	}
+
+	attrs, err := b.bucket.Attributes(ctx, name)
+	if err != nil {
+		return attrs, err
+	}
+
+	info, err := b.objectInfo(ctx, userID, name)
+	if err != nil {
+		return objstore.ObjectAttributes{}, err
+	}
+	if info.encrypted {
+		attrs.Size = info.header.plaintextSize(attrs.Size)
+	}
+	return attrs, nil
+}
+
+// ReaderWithExpectedErrs implements objstore.InstrumentedBucket.
+func (b *BucketClient) ReaderWithExpectedErrs(fn objstore.IsOpFailureExpectedFunc) objstore.BucketReader {
+	return b.WithExpectedErrs(fn)
+}
+
+// WithExpectedErrs implements objstore.InstrumentedBucket.
+func (b *BucketClient) WithExpectedErrs(fn objstore.IsOpFailureExpectedFunc) objstore.Bucket {
+	if ib, ok := b.bucket.(objstore.InstrumentedBucket); ok {
+		return &BucketClient{
+			bucket:      ib.WithExpectedErrs(fn),
+			keyProvider: b.keyProvider,
+			headers:     b.headers,
+		}
+	}
+
+	return b
+}
@@ -0,0 +1,275 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+)
+
+func newTestKeyfile(t *testing.T, tenantKeys map[string][]string, defaultKeys []string) string {
+	content := "tenant_keys:\n"
+	for userID, keys := range tenantKeys {
+		content += fmt.Sprintf("  %s:\n", userID)
+		for _, key := range keys {
+			content += fmt.Sprintf("    - %s\n", key)
+		}
+	}
+	content += "default_keys:\n"
+	for _, key := range defaultKeys {
+		content += fmt.Sprintf("  - %s\n", key)
+	}
+
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func newTestKey(t *testing.T) string {
+	key := make([]byte, dataKeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func newTestBucketClient(t *testing.T, bkt objstore.Bucket, tenantKeys map[string][]string, defaultKeys []string) *BucketClient {
+	keyProvider, err := NewKeyfileProvider(newTestKeyfile(t, tenantKeys, defaultKeys), 0, log.NewNopLogger())
+	require.NoError(t, err)
+
+	client, err := NewBucketClient(bkt, keyProvider)
+	require.NoError(t, err)
+	return client
+}
+
+// readAll returns a function reading the whole content of the reader returned by a Get or GetRange.
+func readAll(t *testing.T) func(io.ReadCloser, error) []byte {
+	return func(r io.ReadCloser, err error) []byte {
+		require.NoError(t, err)
+		defer func() { require.NoError(t, r.Close()) }()
+
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return content
+	}
+}
+
+func TestBucketClient_UploadAndGet(t *testing.T) {
+	ctx := context.Background()
+
+	for _, size := range []int{0, 1, defaultChunkSize - 1, defaultChunkSize, defaultChunkSize + 1, 3*defaultChunkSize + 100} {
+		t.Run(fmt.Sprintf("size %d", size), func(t *testing.T) {
+			bkt := objstore.NewInMemBucket()
+			client := newTestBucketClient(t, bkt, map[string][]string{"user-1": {newTestKey(t)}}, nil)
+
+			plaintext := make([]byte, size)
+			_, err := rand.Read(plaintext)
+			require.NoError(t, err)
+
+			require.NoError(t, client.Upload(ctx, "user-1/block/chunks/000001", bytes.NewReader(plaintext)))
+
+			// The object is encrypted in the bucket.
+			stored := readAll(t)(bkt.Get(ctx, "user-1/block/chunks/000001"))
+			assert.True(t, isEncrypted(stored))
+			if size > 0 {
+				assert.False(t, bytes.Contains(stored, plaintext))
+			}
+
+			assert.Equal(t, plaintext, readAll(t)(client.Get(ctx, "user-1/block/chunks/000001")))
+
+			attrs, err := client.Attributes(ctx, "user-1/block/chunks/000001")
+			require.NoError(t, err)
+			assert.Equal(t, int64(size), attrs.Size)
+
+			// Every range must be readable, including the ones crossing chunks or the end of the object.
+			for _, r := range [][2]int64{
+				{0, -1},
+				{0, int64(size)},
+				{1, 10},
+				{defaultChunkSize - 5, 10},
+				{defaultChunkSize, defaultChunkSize},
+				{int64(size) - 3, 10},
+				{int64(size), 10},
+			} {
+				off, length := r[0], r[1]
+				if off < 0 || off > int64(size) {
+					continue
+				}
+
+				end := int64(size)
+				if length >= 0 {
+					end = min(off+length, end)
+				}
+				actual := readAll(t)(client.GetRange(ctx, "user-1/block/chunks/000001", off, length))
+				assert.Equal(t, plaintext[off:end], actual, "offset: %d length: %d", off, length)
+			}
+		})
+	}
+}
+
+func TestBucketClient_UnencryptedObjects(t *testing.T) {
+	ctx := context.Background()
+	bkt := objstore.NewInMemBucket()
+	client := newTestBucketClient(t, bkt, map[string][]string{"user-1": {newTestKey(t)}}, nil)
+
+	// The objects which don't belong to a tenant are not encrypted.
+	for _, name := range []string{"user-index.json.gz", "__markers__/user-1/tenant-deletion-mark.json"} {
+		require.NoError(t, client.Upload(ctx, name, bytes.NewReader([]byte("content"))))
+		assert.Equal(t, []byte("content"), readAll(t)(bkt.Get(ctx, name)))
+	}
+
+	// The objects uploaded before enabling the encryption are readable.
+	require.NoError(t, bkt.Upload(ctx, "user-1/bucket-index.json.gz", bytes.NewReader([]byte("plaintext"))))
+	assert.Equal(t, []byte("plaintext"), readAll(t)(client.Get(ctx, "user-1/bucket-index.json.gz")))
+	assert.Equal(t, []byte("text"), readAll(t)(client.GetRange(ctx, "user-1/bucket-index.json.gz", 5, 4)))
+
+	attrs, err := client.Attributes(ctx, "user-1/bucket-index.json.gz")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("plaintext")), attrs.Size)
+}
+
+func TestBucketClient_TenantKeys(t *testing.T) {
+	ctx := context.Background()
+	bkt := objstore.NewInMemBucket()
+
+	user1Key, defaultKey := newTestKey(t), newTestKey(t)
+	client := newTestBucketClient(t, bkt, map[string][]string{"user-1": {user1Key}}, []string{defaultKey})
+
+	require.NoError(t, client.Upload(ctx, "user-1/meta.json", bytes.NewReader([]byte("user-1"))))
+	require.NoError(t, client.Upload(ctx, "user-2/meta.json", bytes.NewReader([]byte("user-2"))))
+
+	// An object copied to another tenant can't be decrypted.
+	stored := readAll(t)(bkt.Get(ctx, "user-2/meta.json"))
+	require.NoError(t, bkt.Upload(ctx, "user-3/meta.json", bytes.NewReader(stored)))
+	_, err := client.Get(ctx, "user-3/meta.json")
+	require.Error(t, err)
+
+	// A rotated tenant key keeps decrypting the objects encrypted with it.
+	client = newTestBucketClient(t, bkt, map[string][]string{"user-1": {newTestKey(t), user1Key}}, []string{defaultKey})
+	assert.Equal(t, []byte("user-1"), readAll(t)(client.Get(ctx, "user-1/meta.json")))
+
+	// Removing the keys of a tenant makes its objects unreadable, without affecting the other tenants.
+	client = newTestBucketClient(t, bkt, map[string][]string{"user-1": nil, "user-2": nil}, []string{defaultKey})
+	_, err = client.Get(ctx, "user-1/meta.json")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = client.GetRange(ctx, "user-1/meta.json", 0, 2)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	// The objects encrypted with the default keys aren't readable either once the tenant keys are removed.
+	_, err = client.Get(ctx, "user-2/meta.json")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	require.NoError(t, client.Upload(ctx, "user-4/meta.json", bytes.NewReader([]byte("user-4"))))
+	assert.Equal(t, []byte("user-4"), readAll(t)(client.Get(ctx, "user-4/meta.json")))
+
+	// The tenants whose keys have been removed don't fall back to the default keys for the new objects.
+	require.ErrorIs(t, client.Upload(ctx, "user-1/meta.json", bytes.NewReader([]byte("user-1"))), ErrTenantKeysRemoved)
+	require.ErrorIs(t, client.Upload(ctx, "user-2/index", bytes.NewReader([]byte("user-2"))), ErrTenantKeysRemoved)
+
+	// Tenants without keys can't upload objects.
+	client = newTestBucketClient(t, bkt, nil, nil)
+	require.Error(t, client.Upload(ctx, "user-5/meta.json", bytes.NewReader([]byte("user-5"))))
+}
+
+func TestBucketClient_TamperedObject(t *testing.T) {
+	ctx := context.Background()
+	bkt := objstore.NewInMemBucket()
+	client := newTestBucketClient(t, bkt, map[string][]string{"user-1": {newTestKey(t)}}, nil)
+
+	plaintext := bytes.Repeat([]byte("x"), 2*defaultChunkSize+10)
+	require.NoError(t, client.Upload(ctx, "user-1/block/index", bytes.NewReader(plaintext)))
+	stored := readAll(t)(bkt.Get(ctx, "user-1/block/index"))
+
+	tests := map[string][]byte{
+		"modified chunk":   append(append(append([]byte{}, stored[:len(stored)-5]...), stored[len(stored)-5]^1), stored[len(stored)-4:]...),
+		"truncated object": stored[:len(stored)-defaultChunkSize/2],
+		"removed chunk":    stored[:len(stored)-(10+tagSize)],
+	}
+
+	for name, tampered := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, bkt.Upload(ctx, "user-1/block/index", bytes.NewReader(tampered)))
+
+			r, err := client.Get(ctx, "user-1/block/index")
+			require.NoError(t, err)
+			_, err = io.ReadAll(r)
+			require.Error(t, err)
+			require.NoError(t, r.Close())
+		})
+	}
+}
+
+func TestBucketClient_ObjectSize(t *testing.T) {
+	ctx := context.Background()
+	bkt := objstore.NewInMemBucket()
+	client := newTestBucketClient(t, bkt, map[string][]string{"user-1": {newTestKey(t)}}, nil)
+
+	plaintext := bytes.Repeat([]byte("x"), defaultChunkSize+10)
+	require.NoError(t, client.Upload(ctx, "user-1/block/index", bytes.NewReader(plaintext)))
+
+	r, err := client.Get(ctx, "user-1/block/index")
+	require.NoError(t, err)
+	size, err := objstore.TryToGetSize(r)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(plaintext)), size)
+	require.NoError(t, r.Close())
+
+	r, err = client.GetRange(ctx, "user-1/block/index", 5, defaultChunkSize)
+	require.NoError(t, err)
+	size, err = objstore.TryToGetSize(r)
+	require.NoError(t, err)
+	assert.Equal(t, int64(defaultChunkSize), size)
+	require.NoError(t, r.Close())
+}
+
+func TestBucketClient_KeyfileReload(t *testing.T) {
+	ctx := context.Background()
+	bkt := objstore.NewInMemBucket()
+
+	user1Key, user2Key := newTestKey(t), newTestKey(t)
+	path := newTestKeyfile(t, map[string][]string{"user-1": {user1Key}, "user-2": {user2Key}}, nil)
+	keyProvider, err := NewKeyfileProvider(path, time.Minute, log.NewNopLogger())
+	require.NoError(t, err)
+	now := time.Now()
+	keyProvider.now = func() time.Time { return now }
+
+	client, err := NewBucketClient(bkt, keyProvider)
+	require.NoError(t, err)
+
+	plaintext := bytes.Repeat([]byte("x"), defaultChunkSize+10)
+	for _, name := range []string{"user-1/block/index", "user-2/block/index"} {
+		require.NoError(t, client.Upload(ctx, name, bytes.NewReader(plaintext)))
+		// The header is cached by the range reads.
+		assert.Equal(t, plaintext[5:15], readAll(t)(client.GetRange(ctx, name, 5, 10)))
+	}
+
+	// The keys removed from the keyfile are still available until the next reload.
+	require.NoError(t, os.WriteFile(path, fmt.Appendf(nil, "tenant_keys:\n  user-1: []\n  user-2:\n    - %s\n", user2Key), 0600))
+	assert.Equal(t, plaintext[5:15], readAll(t)(client.GetRange(ctx, "user-1/block/index", 5, 10)))
+
+	// Once reloaded, the cached headers of the objects encrypted with a removed key aren't used anymore.
+	now = now.Add(time.Minute)
+	_, err = client.GetRange(ctx, "user-1/block/index", 5, 10)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = client.Attributes(ctx, "user-1/block/index")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = client.Get(ctx, "user-1/block/index")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Equal(t, plaintext[5:15], readAll(t)(client.GetRange(ctx, "user-2/block/index", 5, 10)))
+
+	// An invalid keyfile doesn't replace the previous keys.
+	require.NoError(t, os.WriteFile(path, []byte("tenant_keys: invalid"), 0600))
+	now = now.Add(time.Minute)
+	assert.Equal(t, plaintext[5:15], readAll(t)(client.GetRange(ctx, "user-2/block/index", 5, 10)))
+	_, err = client.GetRange(ctx, "user-1/block/index", 5, 10)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+}
@@ -0,0 +1,59 @@
+package encryption
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// KeyProviderKeyfile is the value for the key provider reading the keys from a local file.
+	KeyProviderKeyfile = "keyfile"
+)
+
+var (
+	supportedKeyProviders = []string{KeyProviderKeyfile}
+
+	errUnsupportedKeyProvider = errors.New("unsupported encryption key provider")
+	errMissingKeyfilePath     = errors.New("the encryption keyfile path must be set when using the keyfile key provider")
+)
+
+// Config holds the config options of the client-side encryption of the objects.
+type Config struct {
+	Enabled     bool          `yaml:"enabled"`
+	KeyProvider string        `yaml:"key_provider"`
+	Keyfile     KeyfileConfig `yaml:"keyfile"`
+}
+
+// KeyfileConfig holds the config options of the keyfile key provider.
+type KeyfileConfig struct {
+	Path         string        `yaml:"path"`
+	ReloadPeriod time.Duration `yaml:"reload_period"`
+}
+
+// RegisterFlagsWithPrefix registers the flags for the client-side encryption with the provided prefix.
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"encryption.enabled", false, "[Experimental] If true, the objects of each tenant are encrypted on the client side, before being uploaded to the object storage, with data keys wrapped by the tenant keys of the key provider. Objects uploaded before enabling the encryption remain readable.")
+	f.StringVar(&cfg.KeyProvider, prefix+"encryption.key-provider", KeyProviderKeyfile, fmt.Sprintf("The provider of the keys wrapping the data keys. Supported values are: %s.", strings.Join(supportedKeyProviders, ", ")))
+	f.StringVar(&cfg.Keyfile.Path, prefix+"encryption.keyfile.path", "", "Path to the YAML file containing the base64 encoded AES-256 keys of the tenants, under tenant_keys, and the keys used for the tenants without their own keys, under default_keys. The first key of each list is used to encrypt new objects. Listing a tenant without keys makes its objects unreadable and fails the uploads of new objects, instead of falling back to the default keys.")
+	f.DurationVar(&cfg.Keyfile.ReloadPeriod, prefix+"encryption.keyfile.reload-period", time.Minute, "How frequently the encryption keyfile is reloaded. The objects encrypted with a key removed from the keyfile become unreadable after the next reload, even if their header has been cached. 0 to disable the reloads.")
+}
+
+// Validate the config.
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if !slices.Contains(supportedKeyProviders, cfg.KeyProvider) {
+		return errUnsupportedKeyProvider
+	}
+	if cfg.KeyProvider == KeyProviderKeyfile && cfg.Keyfile.Path == "" {
+		return errMissingKeyfilePath
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package encryption
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		cfg      Config
+		expected error
+	}{
+		"should pass when the encryption is disabled": {
+			cfg: Config{KeyProvider: "unknown"},
+		},
+		"should pass with the keyfile key provider": {
+			cfg: Config{Enabled: true, KeyProvider: KeyProviderKeyfile, Keyfile: KeyfileConfig{Path: "keys.yaml"}},
+		},
+		"should fail on unknown key provider": {
+			cfg:      Config{Enabled: true, KeyProvider: "unknown"},
+			expected: errUnsupportedKeyProvider,
+		},
+		"should fail without the keyfile path": {
+			cfg:      Config{Enabled: true, KeyProvider: KeyProviderKeyfile},
+			expected: errMissingKeyfilePath,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.cfg.Validate())
+		})
+	}
+}
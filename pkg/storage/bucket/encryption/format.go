@@ -0,0 +1,345 @@
+package encryption
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/thanos-io/objstore"
+)
+
+// An encrypted object is made of a header, followed by the plaintext split in chunks of
+// chunkSize bytes, each one encrypted with AES-256-GCM using a data key unique to the object.
+// Each chunk can be decrypted on its own, so that a range of the object can be read without
+// reading the whole object.
+//
+// The header is made of:
+// - the magic bytes
+// - the header length (uint32)
+// - the chunk size (uint32)
+// - the ID of the key wrapping the data key (uint16 length prefixed)
+// - the wrapped data key (uint16 length prefixed)
+//
+// The nonce of a chunk is its index, which is unique because the data key is unique. The
+// additional data of a chunk is its index, whether it's the last one, and the tenant, so
+// that chunks can't be reordered, the object can't be truncated, and it can't be moved to
+// another tenant.
+const (
+	headerMagic = "CTXENCv1"
+
+	// headerFixedSize is the size of the magic bytes and the header length.
+	headerFixedSize = len(headerMagic) + 4
+
+	// headerPrefetchSize is the size of the read for the header of an object, large enough
+	// to contain the header in one read with the usual key IDs and wrapped keys.
+	headerPrefetchSize = 256
+
+	defaultChunkSize = 64 * 1024
+	maxChunkSize     = 16 * 1024 * 1024
+	dataKeySize      = 32
+	tagSize          = 16
+)
+
+var (
+	errDecrypt         = errors.New("failed to decrypt the object")
+	errTruncatedObject = errors.New("the encrypted object is truncated")
+	errInvalidHeader   = errors.New("invalid encrypted object header")
+)
+
+type header struct {
+	chunkSize  int
+	keyID      string
+	wrappedKey []byte
+
+	// length is the size of the encoded header.
+	length int
+}
+
+func encodeHeader(chunkSize int, keyID string, wrappedKey []byte) []byte {
+	length := headerFixedSize + 4 + 2 + len(keyID) + 2 + len(wrappedKey)
+
+	b := make([]byte, 0, length)
+	b = append(b, headerMagic...)
+	b = binary.BigEndian.AppendUint32(b, uint32(length))
+	b = binary.BigEndian.AppendUint32(b, uint32(chunkSize))
+	b = binary.BigEndian.AppendUint16(b, uint16(len(keyID)))
+	b = append(b, keyID...)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(wrappedKey)))
+	return append(b, wrappedKey...)
+}
+
+// isEncrypted returns whether the input object prefix starts with the magic bytes.
+func isEncrypted(prefix []byte) bool {
+	return len(prefix) >= len(headerMagic) && string(prefix[:len(headerMagic)]) == headerMagic
+}
+
+// headerLength returns the size of the header of the encrypted object starting with the input prefix.
+func headerLength(prefix []byte) (int, error) {
+	if len(prefix) < headerFixedSize {
+		return 0, errInvalidHeader
+	}
+	return int(binary.BigEndian.Uint32(prefix[len(headerMagic):headerFixedSize])), nil
+}
+
+func decodeHeader(b []byte) (header, error) {
+	length, err := headerLength(b)
+	if err != nil || len(b) < length {
+		return header{}, errInvalidHeader
+	}
+
+	h := header{length: length}
+	b = b[headerFixedSize:length]
+
+	if len(b) < 6 {
+		return header{}, errInvalidHeader
+	}
+	h.chunkSize = int(binary.BigEndian.Uint32(b))
+	keyIDLength := int(binary.BigEndian.Uint16(b[4:]))
+	b = b[6:]
+
+	if len(b) < keyIDLength+2 {
+		return header{}, errInvalidHeader
+	}
+	h.keyID = string(b[:keyIDLength])
+	wrappedKeyLength := int(binary.BigEndian.Uint16(b[keyIDLength:]))
+	b = b[keyIDLength+2:]
+
+	if len(b) != wrappedKeyLength || h.chunkSize <= 0 || h.chunkSize > maxChunkSize {
+		return header{}, errInvalidHeader
+	}
+	h.wrappedKey = b
+
+	return h, nil
+}
+
+// plaintextSize returns the size of the plaintext of an encrypted object from its size.
+func (h header) plaintextSize(size int64) int64 {
+	encryptedChunkSize := int64(h.chunkSize + tagSize)
+	size -= int64(h.length)
+
+	chunks := (size + encryptedChunkSize - 1) / encryptedChunkSize
+	return size - chunks*tagSize
+}
+
+// encryptedSize returns the size of an encrypted object from the size of its plaintext.
+func (h header) encryptedSize(plaintextSize int64) int64 {
+	// The empty plaintext is encrypted in one empty chunk.
+	chunks := max((plaintextSize+int64(h.chunkSize)-1)/int64(h.chunkSize), 1)
+	return int64(h.length) + plaintextSize + chunks*tagSize
+}
+
+func chunkNonce(aead cipher.AEAD, index uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], index)
+	return nonce
+}
+
+func chunkAdditionalData(userID string, index uint64, last bool) []byte {
+	ad := make([]byte, 0, 9+len(userID))
+	ad = binary.BigEndian.AppendUint64(ad, index)
+	if last {
+		ad = append(ad, 1)
+	} else {
+		ad = append(ad, 0)
+	}
+	return append(ad, userID...)
+}
+
+// encryptReader encrypts the plaintext read from the underlying reader.
+type encryptReader struct {
+	r      *bufio.Reader
+	aead   cipher.AEAD
+	userID string
+	header header
+
+	// size is the size of the plaintext or -1 if unknown.
+	size int64
+
+	index     uint64
+	plaintext []byte
+	out       []byte
+	pending   []byte
+	done      bool
+}
+
+func newEncryptReader(r io.Reader, size int64, aead cipher.AEAD, userID string, h header, encodedHeader []byte) *encryptReader {
+	return &encryptReader{
+		r:         bufio.NewReader(r),
+		aead:      aead,
+		userID:    userID,
+		header:    h,
+		size:      size,
+		plaintext: make([]byte, h.chunkSize),
+		out:       make([]byte, 0, h.chunkSize+tagSize),
+		pending:   encodedHeader,
+	}
+}
+
+func (e *encryptReader) Read(p []byte) (int, error) {
+	for len(e.pending) == 0 {
+		if e.done {
+			return 0, io.EOF
+		}
+		if err := e.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, e.pending)
+	e.pending = e.pending[n:]
+	return n, nil
+}
+
+func (e *encryptReader) next() error {
+	n, err := io.ReadFull(e.r, e.plaintext)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+
+	// The chunk is the last one if the plaintext ends within or right after it.
+	last := err != nil
+	if !last {
+		if _, err := e.r.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	e.pending = e.aead.Seal(e.out[:0], chunkNonce(e.aead, e.index), e.plaintext[:n], chunkAdditionalData(e.userID, e.index, last))
+	e.index++
+	e.done = last
+	return nil
+}
+
+// ObjectSize implements objstore.ObjectSizer, so that the object storage clients know the
+// size of the upload when the size of the plaintext is known.
+func (e *encryptReader) ObjectSize() (int64, error) {
+	if e.size < 0 {
+		return 0, errors.New("unknown object size")
+	}
+	return e.header.encryptedSize(e.size), nil
+}
+
+// decryptReader decrypts the chunks read from the underlying reader, starting at the
+// chunk with the given index.
+type decryptReader struct {
+	closer io.Closer
+	r      *bufio.Reader
+	aead   cipher.AEAD
+	userID string
+
+	index uint64
+	// lastIndex is the index of the last chunk of the object, or -1 if unknown, in which
+	// case the last chunk is the one at the end of the underlying reader.
+	lastIndex int64
+
+	// skip is the number of plaintext bytes to discard at the beginning.
+	skip int64
+	// remaining is the number of plaintext bytes left to return, or -1 to read until the end.
+	remaining int64
+
+	// size is the size of the plaintext returned or -1 if unknown.
+	size int64
+
+	// onDecryptErr, if set, is called when a chunk can't be decrypted.
+	onDecryptErr func()
+
+	in      []byte
+	out     []byte
+	pending []byte
+	done    bool
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.pending) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.pending)
+	d.pending = d.pending[n:]
+	return n, nil
+}
+
+func (d *decryptReader) next() error {
+	n, err := io.ReadFull(d.r, d.in)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	if n < tagSize {
+		d.decryptErr()
+		return errTruncatedObject
+	}
+
+	var last bool
+	if d.lastIndex >= 0 {
+		last = d.index == uint64(d.lastIndex)
+	} else if last = err != nil; !last {
+		if _, err := d.r.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	plaintext, err := d.aead.Open(d.out[:0], chunkNonce(d.aead, d.index), d.in[:n], chunkAdditionalData(d.userID, d.index, last))
+	if err != nil {
+		d.decryptErr()
+		return errDecrypt
+	}
+	d.index++
+	d.done = last
+
+	skip := min(d.skip, int64(len(plaintext)))
+	plaintext = plaintext[skip:]
+	d.skip -= skip
+
+	if d.remaining >= 0 {
+		plaintext = plaintext[:min(d.remaining, int64(len(plaintext)))]
+		d.remaining -= int64(len(plaintext))
+		d.done = d.done || d.remaining == 0
+	}
+
+	d.pending = plaintext
+	return nil
+}
+
+func (d *decryptReader) decryptErr() {
+	if d.onDecryptErr != nil {
+		d.onDecryptErr()
+	}
+}
+
+// ObjectSize implements objstore.ObjectSizer.
+func (d *decryptReader) ObjectSize() (int64, error) {
+	if d.size < 0 {
+		return 0, errors.New("unknown object size")
+	}
+	return d.size, nil
+}
+
+func (d *decryptReader) Close() error {
+	return d.closer.Close()
+}
+
+// bufferedReadCloser is a buffered reader closing the underlying reader.
+type bufferedReadCloser struct {
+	*bufio.Reader
+	rc io.ReadCloser
+}
+
+// ObjectSize implements objstore.ObjectSizer.
+func (b *bufferedReadCloser) ObjectSize() (int64, error) {
+	return objstore.TryToGetSize(b.rc)
+}
+
+func (b *bufferedReadCloser) Close() error {
+	return b.rc.Close()
+}
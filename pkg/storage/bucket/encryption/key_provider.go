@@ -0,0 +1,231 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrKeyNotFound is returned when unwrapping a data key with a key which is not available anymore.
+	ErrKeyNotFound = errors.New("encryption key not found")
+
+	// ErrTenantKeysRemoved is returned when wrapping a data key for a tenant whose keys have been removed.
+	ErrTenantKeysRemoved = errors.New("the encryption keys of the tenant have been removed")
+)
+
+// KeyProvider wraps the data keys encrypting the objects of a tenant with the keys of the tenant.
+type KeyProvider interface {
+	// WrapKey encrypts the data key with the current key of the tenant, and returns the ID of the key used.
+	WrapKey(ctx context.Context, userID string, dataKey []byte) (keyID string, wrapped []byte, err error)
+
+	// UnwrapKey decrypts a data key wrapped with the given key of the tenant.
+	UnwrapKey(ctx context.Context, userID, keyID string, wrapped []byte) ([]byte, error)
+
+	// KeyAvailable returns whether the given key of the tenant is still available, so that
+	// the data keys unwrapped with a key which has been removed since stop being used.
+	KeyAvailable(ctx context.Context, userID, keyID string) bool
+}
+
+// NewKeyProvider makes a new KeyProvider based on the configured key provider.
+func NewKeyProvider(cfg Config, logger log.Logger) (KeyProvider, error) {
+	switch cfg.KeyProvider {
+	case KeyProviderKeyfile:
+		return NewKeyfileProvider(cfg.Keyfile.Path, cfg.Keyfile.ReloadPeriod, logger)
+	default:
+		return nil, errUnsupportedKeyProvider
+	}
+}
+
+// keyfile is the format of the file read by the keyfile key provider.
+type keyfile struct {
+	TenantKeys  map[string][]string `yaml:"tenant_keys"`
+	DefaultKeys []string            `yaml:"default_keys"`
+}
+
+// keyEncryptionKey is a key wrapping the data keys, identified by the fingerprint of its value.
+type keyEncryptionKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// KeyfileProvider is a KeyProvider wrapping the data keys with AES-256-GCM, using keys read from a local file.
+// The keys are reloaded once older than the reload period, so that removing the keys of a tenant makes its
+// objects unreadable without restarting.
+type KeyfileProvider struct {
+	path         string
+	reloadPeriod time.Duration
+	logger       log.Logger
+	now          func() time.Time
+
+	mtx         sync.Mutex
+	tenantKeys  map[string][]keyEncryptionKey
+	defaultKeys []keyEncryptionKey
+	lastReload  time.Time
+}
+
+// NewKeyfileProvider makes a new KeyfileProvider reading the keys from the input file, and reading
+// them again every reload period. A reload period of 0 disables the reloads.
+func NewKeyfileProvider(path string, reloadPeriod time.Duration, logger log.Logger) (*KeyfileProvider, error) {
+	p := &KeyfileProvider{
+		path:         path,
+		reloadPeriod: reloadPeriod,
+		logger:       logger,
+		now:          time.Now,
+	}
+
+	tenantKeys, defaultKeys, err := loadKeyfile(path)
+	if err != nil {
+		return nil, err
+	}
+	p.tenantKeys, p.defaultKeys = tenantKeys, defaultKeys
+	p.lastReload = p.now()
+	return p, nil
+}
+
+func loadKeyfile(path string) (map[string][]keyEncryptionKey, []keyEncryptionKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "read encryption keyfile")
+	}
+
+	kf := keyfile{}
+	if err := yaml.Unmarshal(content, &kf); err != nil {
+		return nil, nil, errors.Wrap(err, "parse encryption keyfile")
+	}
+
+	tenantKeys := make(map[string][]keyEncryptionKey, len(kf.TenantKeys))
+	for userID, keys := range kf.TenantKeys {
+		if tenantKeys[userID], err = parseKeys(keys); err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid encryption key of tenant %s", userID)
+		}
+	}
+	defaultKeys, err := parseKeys(kf.DefaultKeys)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid default encryption key")
+	}
+
+	return tenantKeys, defaultKeys, nil
+}
+
+func parseKeys(keys []string) ([]keyEncryptionKey, error) {
+	out := make([]keyEncryptionKey, 0, len(keys))
+	for _, key := range keys {
+		value, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(value) != dataKeySize {
+			return nil, fmt.Errorf("the key must be %d bytes long, got %d", dataKeySize, len(value))
+		}
+
+		aead, err := newAEAD(value)
+		if err != nil {
+			return nil, err
+		}
+
+		fingerprint := sha256.Sum256(value)
+		out = append(out, keyEncryptionKey{id: hex.EncodeToString(fingerprint[:8]), aead: aead})
+	}
+	return out, nil
+}
+
+// keys returns the lists of keys the tenant objects may be encrypted with, the current key
+// being the first key of the first list. The default keys are used for the tenants which
+// are not listed in the keyfile, while a tenant listed without keys has been removed and
+// gets no keys at all, so that its objects are neither readable nor writable anymore.
+func (p *KeyfileProvider) keys(userID string) [][]keyEncryptionKey {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if now := p.now(); p.reloadPeriod > 0 && now.Sub(p.lastReload) >= p.reloadPeriod {
+		p.lastReload = now
+		if tenantKeys, defaultKeys, err := loadKeyfile(p.path); err != nil {
+			level.Warn(p.logger).Log("msg", "failed to reload the encryption keyfile, keeping the previous keys", "err", err)
+		} else {
+			p.tenantKeys, p.defaultKeys = tenantKeys, defaultKeys
+		}
+	}
+
+	keys, ok := p.tenantKeys[userID]
+	if !ok {
+		return [][]keyEncryptionKey{p.defaultKeys}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// The tenant objects may have been encrypted with the default keys before it got its own keys.
+	return [][]keyEncryptionKey{keys, p.defaultKeys}
+}
+
+// WrapKey implements KeyProvider.
+func (p *KeyfileProvider) WrapKey(_ context.Context, userID string, dataKey []byte) (string, []byte, error) {
+	lists := p.keys(userID)
+	if len(lists) == 0 {
+		return "", nil, errors.Wrapf(ErrTenantKeysRemoved, "tenant %s", userID)
+	}
+	keys := lists[0]
+	if len(keys) == 0 {
+		return "", nil, fmt.Errorf("no encryption key configured for tenant %s", userID)
+	}
+
+	nonce := make([]byte, keys[0].aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+
+	// The tenant is authenticated so that a data key can't be used for another tenant.
+	return keys[0].id, keys[0].aead.Seal(nonce, nonce, dataKey, []byte(userID)), nil
+}
+
+// UnwrapKey implements KeyProvider.
+func (p *KeyfileProvider) UnwrapKey(_ context.Context, userID, keyID string, wrapped []byte) ([]byte, error) {
+	for _, keys := range p.keys(userID) {
+		for _, key := range keys {
+			if key.id != keyID {
+				continue
+			}
+
+			nonceSize := key.aead.NonceSize()
+			if len(wrapped) < nonceSize {
+				return nil, errors.New("invalid wrapped data key")
+			}
+			return key.aead.Open(nil, wrapped[:nonceSize], wrapped[nonceSize:], []byte(userID))
+		}
+	}
+
+	return nil, errors.Wrapf(ErrKeyNotFound, "key %s of tenant %s", keyID, userID)
+}
+
+// KeyAvailable implements KeyProvider.
+func (p *KeyfileProvider) KeyAvailable(_ context.Context, userID, keyID string) bool {
+	for _, keys := range p.keys(userID) {
+		if slices.ContainsFunc(keys, func(key keyEncryptionKey) bool { return key.id == keyID }) {
+			return true
+		}
+	}
+	return false
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,761 @@
+package bucketfsck
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/oklog/ulid/v2"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	"github.com/cortexproject/cortex/pkg/storage/parquet"
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+	"github.com/cortexproject/cortex/pkg/util/concurrency"
+	util_log "github.com/cortexproject/cortex/pkg/util/log"
+	"github.com/cortexproject/cortex/pkg/util/users"
+)
+
+// IssueType is the type of an inconsistency found in the bucket.
+type IssueType string
+
+const (
+	// PartialBlock is a block without meta.json which isn't marked for deletion.
+	PartialBlock IssueType = "partial-block"
+
+	// CorruptedBlockMeta is a block whose meta.json can't be parsed or doesn't match the block.
+	CorruptedBlockMeta IssueType = "corrupted-block-meta"
+
+	// MissingBlockFiles is a block missing some of the files listed in its meta.json.
+	MissingBlockFiles IssueType = "missing-block-files"
+
+	// MissingGlobalMarker is a block marker which isn't copied to the global markers location.
+	MissingGlobalMarker IssueType = "missing-global-marker"
+
+	// MarkerWithoutBlock is a global block marker whose block doesn't exist.
+	MarkerWithoutBlock IssueType = "marker-without-block"
+
+	// OrphanParquetFiles are the Parquet files of a block without Parquet converter mark.
+	OrphanParquetFiles IssueType = "orphan-parquet-files"
+
+	// ConverterMarkWithoutParquetFiles is the Parquet converter mark of a block without Parquet files.
+	ConverterMarkWithoutParquetFiles IssueType = "converter-mark-without-parquet-files"
+
+	// MissingBucketIndex is a tenant with blocks but without bucket index.
+	MissingBucketIndex IssueType = "missing-bucket-index"
+
+	// CorruptedBucketIndex is a bucket index which can't be read.
+	CorruptedBucketIndex IssueType = "corrupted-bucket-index"
+
+	// BlockMissingFromBucketIndex is a block uploaded before the last bucket index update, but not in the index.
+	BlockMissingFromBucketIndex IssueType = "block-missing-from-bucket-index"
+
+	// StaleBucketIndexBlock is a block in the bucket index which doesn't exist anymore.
+	StaleBucketIndexBlock IssueType = "stale-bucket-index-block"
+
+	// StaleBucketIndexDeletionMark is a deletion mark in the bucket index which doesn't exist anymore.
+	StaleBucketIndexDeletionMark IssueType = "stale-bucket-index-deletion-mark"
+
+	// DeletionMarkMissingFromBucketIndex is a deletion mark uploaded before the last bucket index update, but not in the index.
+	DeletionMarkMissingFromBucketIndex IssueType = "deletion-mark-missing-from-bucket-index"
+
+	// StaleBucketIndexParquet is a block listed as converted to Parquet in the bucket index, without Parquet converter mark.
+	StaleBucketIndexParquet IssueType = "stale-bucket-index-parquet"
+
+	// CorruptedUserIndex is a user index which can't be read.
+	CorruptedUserIndex IssueType = "corrupted-user-index"
+
+	// TenantMissingFromUserIndex is a tenant with objects in the bucket, but not in the user index.
+	TenantMissingFromUserIndex IssueType = "tenant-missing-from-user-index"
+
+	// StaleUserIndexTenant is a tenant whose state in the user index doesn't match the bucket.
+	StaleUserIndexTenant IssueType = "stale-user-index-tenant"
+)
+
+// visitMarkerSuffix is the suffix of the compactor visit markers, which are stored in the block directories.
+const visitMarkerSuffix = "visit-mark.json"
+
+// Issue is an inconsistency found in the bucket.
+type Issue struct {
+	Type    IssueType `json:"type"`
+	UserID  string    `json:"user,omitempty"`
+	BlockID string    `json:"block,omitempty"`
+	Details string    `json:"details"`
+
+	// Repair describes how the issue is repaired, or is empty if it can't be repaired automatically.
+	Repair      string `json:"repair,omitempty"`
+	Repaired    bool   `json:"repaired"`
+	RepairError string `json:"repair_error,omitempty"`
+}
+
+// Report is the outcome of a bucket check.
+type Report struct {
+	Tenants int     `json:"tenants"`
+	Blocks  int     `json:"blocks"`
+	Issues  []Issue `json:"issues"`
+}
+
+// Unrepaired returns the number of issues which haven't been repaired.
+func (r *Report) Unrepaired() int {
+	count := 0
+	for _, issue := range r.Issues {
+		if !issue.Repaired {
+			count++
+		}
+	}
+	return count
+}
+
+// Checker cross-checks the blocks of the tenants against their bucket index, markers,
+// Parquet converter marks and the user index, and optionally repairs the inconsistencies.
+type Checker struct {
+	cfg    Config
+	bkt    objstore.InstrumentedBucket
+	logger log.Logger
+
+	blocksMarkedForDeletion prometheus.Counter
+}
+
+// NewChecker makes a new Checker of the input blocks storage bucket.
+func NewChecker(cfg Config, bkt objstore.InstrumentedBucket, logger log.Logger) *Checker {
+	return &Checker{
+		cfg:    cfg,
+		bkt:    bkt,
+		logger: logger,
+		blocksMarkedForDeletion: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "cortex_bucket_fsck_blocks_marked_for_deletion_total",
+			Help: "Total number of blocks marked for deletion.",
+		}),
+	}
+}
+
+// Run checks the configured tenants and, unless in dry-run mode, repairs the issues found.
+func (c *Checker) Run(ctx context.Context) (*Report, error) {
+	report := &Report{}
+	tenants := []string{c.cfg.UserID}
+
+	var (
+		scanner                   users.Scanner
+		active, deleting, deleted []string
+		err                       error
+	)
+	if c.cfg.UserID == "" {
+		scanner, err = users.NewScanner(users.UsersScannerConfig{Strategy: users.UserScanStrategyList}, c.bkt, c.logger, nil)
+		if err != nil {
+			return nil, err
+		}
+		// The tenants being deleted are skipped, since their blocks are expected to be partially deleted.
+		if active, deleting, deleted, err = scanner.ScanUsers(ctx); err != nil {
+			return nil, errors.Wrap(err, "scan tenants")
+		}
+		tenants = active
+	}
+
+	var mtx sync.Mutex
+	err = concurrency.ForEachUser(ctx, tenants, c.cfg.TenantConcurrency, func(ctx context.Context, userID string) error {
+		f, blocks, err := c.checkTenant(ctx, userID)
+		if err != nil {
+			return errors.Wrapf(err, "check tenant %s", userID)
+		}
+		issues := f.apply(ctx, c.cfg.DryRun)
+
+		mtx.Lock()
+		defer mtx.Unlock()
+		report.Tenants++
+		report.Blocks += blocks
+		report.Issues = append(report.Issues, issues...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if scanner != nil {
+		f, err := c.checkUserIndex(ctx, scanner, active, deleting, deleted)
+		if err != nil {
+			return nil, errors.Wrap(err, "check user index")
+		}
+		report.Issues = append(report.Issues, f.apply(ctx, c.cfg.DryRun)...)
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		return a.BlockID < b.BlockID
+	})
+	return report, nil
+}
+
+// checkTenant checks the blocks and bucket index of the tenant, and returns the issues found along with the number of blocks.
+func (c *Checker) checkTenant(ctx context.Context, userID string) (*findings, int, error) {
+	logger := util_log.WithUserID(userID, c.logger)
+	userBkt := bucket.NewUserBucketClient(userID, c.bkt, nil)
+	markersBkt := bucketindex.BucketWithGlobalMarkers(userBkt)
+
+	blocks := map[ulid.ULID]map[string]struct{}{}
+	globalMarkers := map[string]map[ulid.ULID]struct{}{}
+	for marker := range bucketindex.MarkersMap {
+		globalMarkers[marker] = map[ulid.ULID]struct{}{}
+	}
+
+	err := userBkt.Iter(ctx, "", func(name string) error {
+		if id, marker, ok := parseGlobalMarker(name); ok {
+			globalMarkers[marker][id] = struct{}{}
+			return nil
+		}
+
+		parts := strings.SplitN(name, objstore.DirDelim, 2)
+		if len(parts) != 2 {
+			return nil
+		}
+		id, err := ulid.Parse(parts[0])
+		if err != nil {
+			return nil
+		}
+		if blocks[id] == nil {
+			blocks[id] = map[string]struct{}{}
+		}
+		blocks[id][parts[1]] = struct{}{}
+		return nil
+	}, objstore.WithRecursiveIter())
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "list objects")
+	}
+
+	f := &findings{}
+	metas := map[ulid.ULID]*metadata.Meta{}
+
+	for _, id := range sortedIDs(blocks) {
+		files := blocks[id]
+		if _, ok := files[metadata.MetaFilename]; !ok {
+			if err := c.checkPartialBlock(ctx, f, userBkt, markersBkt, userID, id, files, logger); err != nil {
+				return nil, 0, err
+			}
+			continue
+		}
+
+		meta, err := c.checkBlockMeta(ctx, f, userBkt, userID, id, files)
+		if err != nil {
+			return nil, 0, err
+		}
+		if meta != nil {
+			metas[id] = meta
+		}
+
+		c.checkBlockMarkers(f, userBkt, markersBkt, userID, id, files, globalMarkers)
+		if err := c.checkParquetFiles(ctx, f, userBkt, markersBkt, userID, id, files); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	for _, marker := range sortedKeys(globalMarkers) {
+		for _, id := range sortedIDs(globalMarkers[marker]) {
+			if _, ok := blocks[id]; ok {
+				continue
+			}
+
+			name := bucketindex.MarkersMap[marker](id)
+			f.add(Issue{
+				Type:    MarkerWithoutBlock,
+				UserID:  userID,
+				BlockID: id.String(),
+				Details: fmt.Sprintf("the global %s references a block which doesn't exist", marker),
+				Repair:  "delete the marker",
+			}, func(ctx context.Context) error {
+				return deleteObject(ctx, userBkt, name)
+			})
+		}
+	}
+
+	if err := c.checkBucketIndex(ctx, f, userBkt, userID, blocks, metas, globalMarkers, logger); err != nil {
+		return nil, 0, err
+	}
+	return f, len(blocks), nil
+}
+
+// checkPartialBlock reports the blocks without meta.json which are not being deleted,
+// and whose files haven't been uploaded recently.
+func (c *Checker) checkPartialBlock(ctx context.Context, f *findings, userBkt objstore.Bucket, markersBkt objstore.Bucket, userID string, id ulid.ULID, files map[string]struct{}, logger log.Logger) error {
+	// The blocks marked for deletion are deleted by the compactor, starting with the meta.json.
+	if _, ok := files[metadata.DeletionMarkFilename]; ok {
+		return nil
+	}
+
+	var names []string
+	for _, file := range sortedKeys(files) {
+		if !strings.HasSuffix(file, visitMarkerSuffix) {
+			names = append(names, path.Join(id.String(), file))
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	last, err := lastModified(ctx, userBkt, names)
+	if err != nil || last.IsZero() || time.Since(last) < c.cfg.MinAge {
+		return err
+	}
+
+	f.add(Issue{
+		Type:    PartialBlock,
+		UserID:  userID,
+		BlockID: id.String(),
+		Details: fmt.Sprintf("the block has no %s and its last file has been uploaded at %s", metadata.MetaFilename, last.UTC().Format(time.RFC3339)),
+		Repair:  "mark the block for deletion",
+	}, func(ctx context.Context) error {
+		return block.MarkForDeletion(ctx, logger, markersBkt, id, "partial block found by the bucket consistency checker", c.blocksMarkedForDeletion)
+	})
+	return nil
+}
+
+// checkBlockMeta reads the meta.json of the block, and reports whether it's corrupted or if
+// the block is missing some of the files it lists. It returns nil if the meta.json is corrupted.
+func (c *Checker) checkBlockMeta(ctx context.Context, f *findings, userBkt objstore.InstrumentedBucket, userID string, id ulid.ULID, files map[string]struct{}) (*metadata.Meta, error) {
+	r, err := userBkt.WithExpectedErrs(userBkt.IsObjNotFoundErr).Get(ctx, path.Join(id.String(), metadata.MetaFilename))
+	if userBkt.IsObjNotFoundErr(err) {
+		// The block has been deleted since it's been listed.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "read meta.json of block %s", id)
+	}
+
+	meta, err := metadata.Read(r)
+	if err == nil && meta.ULID != id {
+		err = fmt.Errorf("the meta.json belongs to block %s", meta.ULID)
+	}
+	if err != nil {
+		f.add(Issue{
+			Type:    CorruptedBlockMeta,
+			UserID:  userID,
+			BlockID: id.String(),
+			Details: err.Error(),
+		}, nil)
+		return nil, nil
+	}
+
+	var missing []string
+	for _, file := range meta.Thanos.Files {
+		if file.RelPath == metadata.MetaFilename {
+			continue
+		}
+		if _, ok := files[file.RelPath]; !ok {
+			missing = append(missing, file.RelPath)
+		}
+	}
+	if len(missing) > 0 {
+		f.add(Issue{
+			Type:    MissingBlockFiles,
+			UserID:  userID,
+			BlockID: id.String(),
+			Details: fmt.Sprintf("the files listed in the meta.json are missing: %s", strings.Join(missing, ", ")),
+		}, nil)
+	}
+
+	return meta, nil
+}
+
+// checkBlockMarkers reports the block markers which are not copied to the global markers location.
+func (c *Checker) checkBlockMarkers(f *findings, userBkt objstore.Bucket, markersBkt objstore.Bucket, userID string, id ulid.ULID, files map[string]struct{}, globalMarkers map[string]map[ulid.ULID]struct{}) {
+	for _, marker := range sortedKeys(globalMarkers) {
+		if _, ok := files[marker]; !ok {
+			continue
+		}
+		if _, ok := globalMarkers[marker][id]; ok {
+			continue
+		}
+
+		name := path.Join(id.String(), marker)
+		f.add(Issue{
+			Type:    MissingGlobalMarker,
+			UserID:  userID,
+			BlockID: id.String(),
+			Details: fmt.Sprintf("the %s isn't in the global markers location", marker),
+			Repair:  "copy the marker to the global markers location",
+		}, func(ctx context.Context) error {
+			content, err := readObject(ctx, userBkt, name)
+			if err != nil {
+				return err
+			}
+			// The bucket with global markers uploads the marker to both the locations.
+			return markersBkt.Upload(ctx, name, bytes.NewReader(content))
+		})
+	}
+}
+
+// checkParquetFiles reports the Parquet files without converter mark, and the converter marks without Parquet files.
+func (c *Checker) checkParquetFiles(ctx context.Context, f *findings, userBkt objstore.Bucket, markersBkt objstore.Bucket, userID string, id ulid.ULID, files map[string]struct{}) error {
+	var parquetFiles []string
+	for _, file := range sortedKeys(files) {
+		if strings.HasSuffix(file, ".parquet") {
+			parquetFiles = append(parquetFiles, path.Join(id.String(), file))
+		}
+	}
+	_, converted := files[parquet.ConverterMarkerFileName]
+
+	switch {
+	case len(parquetFiles) > 0 && !converted:
+		// The converter mark is uploaded once all the Parquet files have been uploaded.
+		last, err := lastModified(ctx, userBkt, parquetFiles)
+		if err != nil || last.IsZero() || time.Since(last) < c.cfg.MinAge {
+			return err
+		}
+
+		f.add(Issue{
+			Type:    OrphanParquetFiles,
+			UserID:  userID,
+			BlockID: id.String(),
+			Details: fmt.Sprintf("the block has %d Parquet files but no %s", len(parquetFiles), parquet.ConverterMarkerFileName),
+			Repair:  "delete the Parquet files",
+		}, func(ctx context.Context) error {
+			for _, name := range parquetFiles {
+				if err := deleteObject(ctx, userBkt, name); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	case len(parquetFiles) == 0 && converted:
+		f.add(Issue{
+			Type:    ConverterMarkWithoutParquetFiles,
+			UserID:  userID,
+			BlockID: id.String(),
+			Details: fmt.Sprintf("the block has a %s but no Parquet files", parquet.ConverterMarkerFileName),
+			Repair:  "delete the converter mark, so that the block is converted again",
+		}, func(ctx context.Context) error {
+			return deleteObject(ctx, markersBkt, path.Join(id.String(), parquet.ConverterMarkerFileName))
+		})
+	}
+	return nil
+}
+
+// checkBucketIndex cross-checks the bucket index with the blocks and markers of the tenant.
+func (c *Checker) checkBucketIndex(ctx context.Context, f *findings, userBkt objstore.Bucket, userID string, blocks map[ulid.ULID]map[string]struct{}, metas map[ulid.ULID]*metadata.Meta, globalMarkers map[string]map[ulid.ULID]struct{}, logger log.Logger) error {
+	const repair = "rebuild the bucket index"
+	rebuild := onceRepair(func(ctx context.Context) error {
+		updater := bucketindex.NewUpdater(c.bkt, userID, nil, logger)
+		if len(globalMarkers[parquet.ConverterMarkerFileName]) > 0 {
+			updater = updater.EnableParquet()
+		}
+
+		idx, _, _, err := updater.UpdateIndex(ctx, nil)
+		if err != nil {
+			return err
+		}
+		return bucketindex.WriteIndex(ctx, c.bkt, userID, nil, idx)
+	})
+
+	idx, err := bucketindex.ReadIndex(ctx, c.bkt, userID, nil, logger)
+	switch {
+	case errors.Is(err, bucketindex.ErrIndexNotFound):
+		// The bucket index is written by the compactor, some time after the first blocks have been uploaded.
+		for _, id := range sortedIDs(metas) {
+			uploaded, err := lastModified(ctx, userBkt, []string{path.Join(id.String(), metadata.MetaFilename)})
+			if err != nil {
+				return err
+			}
+			if !uploaded.IsZero() && time.Since(uploaded) >= c.cfg.MinAge {
+				f.add(Issue{
+					Type:    MissingBucketIndex,
+					UserID:  userID,
+					Details: fmt.Sprintf("the tenant has %d blocks but no bucket index", len(metas)),
+					Repair:  repair,
+				}, rebuild)
+				break
+			}
+		}
+		return nil
+
+	case errors.Is(err, bucketindex.ErrIndexCorrupted):
+		f.add(Issue{
+			Type:    CorruptedBucketIndex,
+			UserID:  userID,
+			Details: err.Error(),
+			Repair:  repair,
+		}, rebuild)
+		return nil
+
+	case err != nil:
+		return errors.Wrap(err, "read bucket index")
+	}
+
+	updatedAt := idx.GetUpdatedAt()
+	indexed := map[ulid.ULID]struct{}{}
+	for _, b := range idx.Blocks {
+		indexed[b.ID] = struct{}{}
+
+		if _, ok := blocks[b.ID][metadata.MetaFilename]; !ok {
+			f.add(Issue{
+				Type:    StaleBucketIndexBlock,
+				UserID:  userID,
+				BlockID: b.ID.String(),
+				Details: fmt.Sprintf("the bucket index references a block without %s", metadata.MetaFilename),
+				Repair:  repair,
+			}, rebuild)
+			continue
+		}
+
+		if _, ok := globalMarkers[parquet.ConverterMarkerFileName][b.ID]; b.Parquet != nil && !ok {
+			f.add(Issue{
+				Type:    StaleBucketIndexParquet,
+				UserID:  userID,
+				BlockID: b.ID.String(),
+				Details: fmt.Sprintf("the bucket index lists the block as converted to Parquet, but it has no %s", parquet.ConverterMarkerFileName),
+				Repair:  repair,
+			}, rebuild)
+		}
+	}
+
+	for _, id := range sortedIDs(metas) {
+		if _, ok := indexed[id]; ok {
+			continue
+		}
+		uploaded, err := lastModified(ctx, userBkt, []string{path.Join(id.String(), metadata.MetaFilename)})
+		if err != nil {
+			return err
+		}
+		if !uploaded.IsZero() && uploaded.Before(updatedAt) {
+			f.add(Issue{
+				Type:    BlockMissingFromBucketIndex,
+				UserID:  userID,
+				BlockID: id.String(),
+				Details: fmt.Sprintf("the block has been uploaded at %s, before the bucket index update at %s", uploaded.UTC().Format(time.RFC3339), updatedAt.UTC().Format(time.RFC3339)),
+				Repair:  repair,
+			}, rebuild)
+		}
+	}
+
+	marked := map[ulid.ULID]struct{}{}
+	for _, m := range idx.BlockDeletionMarks {
+		marked[m.ID] = struct{}{}
+
+		if _, ok := globalMarkers[metadata.DeletionMarkFilename][m.ID]; !ok {
+			f.add(Issue{
+				Type:    StaleBucketIndexDeletionMark,
+				UserID:  userID,
+				BlockID: m.ID.String(),
+				Details: "the bucket index references a deletion mark which doesn't exist",
+				Repair:  repair,
+			}, rebuild)
+		}
+	}
+
+	for _, id := range sortedIDs(globalMarkers[metadata.DeletionMarkFilename]) {
+		if _, ok := marked[id]; ok {
+			continue
+		}
+		uploaded, err := lastModified(ctx, userBkt, []string{bucketindex.BlockDeletionMarkFilepath(id)})
+		if err != nil {
+			return err
+		}
+		if !uploaded.IsZero() && uploaded.Before(updatedAt) {
+			f.add(Issue{
+				Type:    DeletionMarkMissingFromBucketIndex,
+				UserID:  userID,
+				BlockID: id.String(),
+				Details: fmt.Sprintf("the deletion mark has been uploaded at %s, before the bucket index update at %s", uploaded.UTC().Format(time.RFC3339), updatedAt.UTC().Format(time.RFC3339)),
+				Repair:  repair,
+			}, rebuild)
+		}
+	}
+
+	return nil
+}
+
+// checkUserIndex cross-checks the user index with the tenants found in the bucket.
+func (c *Checker) checkUserIndex(ctx context.Context, scanner users.Scanner, active, deleting, deleted []string) (*findings, error) {
+	f := &findings{}
+	const repair = "rebuild the user index"
+	rebuild := onceRepair(func(ctx context.Context) error {
+		return users.NewUserIndexUpdater(c.bkt, 0, scanner, nil).UpdateUserIndex(ctx)
+	})
+
+	idx, err := users.ReadUserIndex(ctx, c.bkt, c.logger)
+	switch {
+	case errors.Is(err, users.ErrIndexNotFound):
+		// The user index is only written when the user index scanner strategy is used.
+		return f, nil
+
+	case errors.Is(err, users.ErrIndexCorrupted):
+		f.add(Issue{Type: CorruptedUserIndex, Details: err.Error(), Repair: repair}, rebuild)
+		return f, nil
+
+	case err != nil:
+		return nil, err
+	}
+
+	scanned := tenantStates(active, deleting, deleted)
+	indexed := tenantStates(idx.ActiveUsers, idx.DeletingUsers, idx.DeletedUsers)
+	updatedAt := idx.GetUpdatedAt().UTC().Format(time.RFC3339)
+
+	for _, userID := range sortedKeys(scanned) {
+		state, ok := indexed[userID]
+		switch {
+		case !ok:
+			f.add(Issue{
+				Type:    TenantMissingFromUserIndex,
+				UserID:  userID,
+				Details: fmt.Sprintf("the %s tenant isn't in the user index updated at %s", scanned[userID], updatedAt),
+				Repair:  repair,
+			}, rebuild)
+		case state != scanned[userID]:
+			f.add(Issue{
+				Type:    StaleUserIndexTenant,
+				UserID:  userID,
+				Details: fmt.Sprintf("the user index updated at %s lists the tenant as %s, but it's %s", updatedAt, state, scanned[userID]),
+				Repair:  repair,
+			}, rebuild)
+		}
+	}
+
+	for _, userID := range sortedKeys(indexed) {
+		if _, ok := scanned[userID]; !ok {
+			f.add(Issue{
+				Type:    StaleUserIndexTenant,
+				UserID:  userID,
+				Details: fmt.Sprintf("the user index updated at %s lists the tenant as %s, but it has no objects", updatedAt, indexed[userID]),
+				Repair:  repair,
+			}, rebuild)
+		}
+	}
+
+	return f, nil
+}
+
+// findings collects the issues found and the functions repairing them.
+type findings struct {
+	issues  []Issue
+	repairs []func(context.Context) error
+}
+
+// add records the issue and its repair function, which is nil if it can't be repaired automatically.
+func (f *findings) add(issue Issue, repair func(context.Context) error) {
+	f.issues = append(f.issues, issue)
+	f.repairs = append(f.repairs, repair)
+}
+
+// apply runs the repairs in the order the issues have been found, unless in dry-run mode, and returns the issues.
+func (f *findings) apply(ctx context.Context, dryRun bool) []Issue {
+	for i, repair := range f.repairs {
+		if repair == nil || dryRun {
+			continue
+		}
+		if err := repair(ctx); err != nil {
+			f.issues[i].RepairError = err.Error()
+			continue
+		}
+		f.issues[i].Repaired = true
+	}
+	return f.issues
+}
+
+// onceRepair returns a repair function running fn only once, for the issues sharing the same repair.
+func onceRepair(fn func(context.Context) error) func(context.Context) error {
+	var (
+		once sync.Once
+		err  error
+	)
+	return func(ctx context.Context) error {
+		once.Do(func() { err = fn(ctx) })
+		return err
+	}
+}
+
+// parseGlobalMarker returns the block and the marker filename of the input object, if it's a global block marker.
+func parseGlobalMarker(name string) (ulid.ULID, string, bool) {
+	if dir := path.Dir(name); dir != bucketindex.MarkersPathname && dir != parquet.ConverterMarkerPrefix {
+		return ulid.ULID{}, "", false
+	}
+
+	parts := strings.SplitN(path.Base(name), "-", 2)
+	if len(parts) != 2 {
+		return ulid.ULID{}, "", false
+	}
+	id, err := ulid.Parse(parts[0])
+	if err != nil {
+		return ulid.ULID{}, "", false
+	}
+	markerPath, ok := bucketindex.MarkersMap[parts[1]]
+	if !ok || markerPath(id) != name {
+		return ulid.ULID{}, "", false
+	}
+	return id, parts[1], true
+}
+
+// lastModified returns the most recent modification time of the input objects, or zero if none of them exists anymore.
+func lastModified(ctx context.Context, bkt objstore.Bucket, names []string) (time.Time, error) {
+	var last time.Time
+	for _, name := range names {
+		attrs, err := bkt.Attributes(ctx, name)
+		if bkt.IsObjNotFoundErr(err) {
+			continue
+		}
+		if err != nil {
+			return time.Time{}, errors.Wrapf(err, "read attributes of %s", name)
+		}
+		if attrs.LastModified.After(last) {
+			last = attrs.LastModified
+		}
+	}
+	return last, nil
+}
+
+func readObject(ctx context.Context, bkt objstore.Bucket, name string) ([]byte, error) {
+	r, err := bkt.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+
+	return io.ReadAll(r)
+}
+
+func deleteObject(ctx context.Context, bkt objstore.Bucket, name string) error {
+	if err := bkt.Delete(ctx, name); err != nil && !bkt.IsObjNotFoundErr(err) {
+		return err
+	}
+	return nil
+}
+
+func tenantStates(active, deleting, deleted []string) map[string]string {
+	states := map[string]string{}
+	for state, tenants := range map[string][]string{"active": active, "deleting": deleting, "deleted": deleted} {
+		for _, userID := range tenants {
+			states[userID] = state
+		}
+	}
+	return states
+}
+
+func sortedIDs[V any](m map[ulid.ULID]V) []ulid.ULID {
+	ids := make([]ulid.ULID, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b ulid.ULID) int { return a.Compare(b) })
+	return ids
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
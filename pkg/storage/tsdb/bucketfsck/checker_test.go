@@ -0,0 +1,209 @@
+package bucketfsck
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/oklog/ulid/v2"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+
+	"github.com/cortexproject/cortex/pkg/storage/parquet"
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+	"github.com/cortexproject/cortex/pkg/util/testutil"
+	"github.com/cortexproject/cortex/pkg/util/users"
+)
+
+type issueKey struct {
+	Type    IssueType
+	UserID  string
+	BlockID string
+}
+
+func issueKeys(issues []Issue) []issueKey {
+	keys := make([]issueKey, 0, len(issues))
+	for _, issue := range issues {
+		keys = append(keys, issueKey{Type: issue.Type, UserID: issue.UserID, BlockID: issue.BlockID})
+	}
+	return keys
+}
+
+func upload(t *testing.T, bkt objstore.Bucket, name, content string) {
+	require.NoError(t, bkt.Upload(context.Background(), name, strings.NewReader(content)))
+}
+
+func TestChecker_Blocks(t *testing.T) {
+	ctx := context.Background()
+	bkt := objstore.WithNoopInstr(objstore.NewInMemBucket())
+	markersBkt := bucketindex.BucketWithGlobalMarkers(bkt)
+
+	// A block missing some of the files listed in its meta.json.
+	block1 := ulid.MustNew(1, nil)
+	meta1 := metadata.Meta{BlockMeta: tsdb.BlockMeta{ULID: block1, Version: metadata.TSDBVersion1}}
+	meta1.Thanos.Files = []metadata.File{{RelPath: "chunks/000001"}, {RelPath: "index"}, {RelPath: metadata.MetaFilename}}
+	meta1Content, err := json.Marshal(meta1)
+	require.NoError(t, err)
+	upload(t, bkt, path.Join("user-1", block1.String(), "index"), "index")
+	upload(t, bkt, path.Join("user-1", block1.String(), metadata.MetaFilename), string(meta1Content))
+
+	// A block whose deletion mark isn't in the global markers location.
+	block2 := testutil.MockStorageBlock(t, bkt, "user-1", 0, 2)
+	testutil.MockStorageDeletionMark(t, bkt, "user-1", block2)
+
+	// A block with Parquet files but no converter mark.
+	block3 := testutil.MockStorageBlock(t, bkt, "user-1", 0, 3)
+	upload(t, bkt, path.Join("user-1", block3.ULID.String(), "0.labels.parquet"), "labels")
+
+	// A block with a converter mark but no Parquet files.
+	block4 := testutil.MockStorageBlock(t, bkt, "user-1", 0, 4)
+	testutil.MockStorageParquetConverterMark(t, markersBkt, "user-1", block4, 1)
+
+	// A partial block, a partial block being deleted and a block with a corrupted meta.json.
+	partial, deleting, corrupted := ulid.MustNew(5, nil), ulid.MustNew(6, nil), ulid.MustNew(7, nil)
+	upload(t, bkt, path.Join("user-1", partial.String(), "index"), "index")
+	upload(t, bkt, path.Join("user-1", deleting.String(), "index"), "index")
+	upload(t, bkt, path.Join("user-1", deleting.String(), metadata.DeletionMarkFilename), "{}")
+	upload(t, bkt, path.Join("user-1", corrupted.String(), metadata.MetaFilename), "{")
+
+	// A global marker of a block which doesn't exist.
+	missing := ulid.MustNew(8, nil)
+	upload(t, bkt, path.Join("user-1", bucketindex.NoCompactMarkFilenameMarkFilepath(missing)), "{}")
+
+	// A bucket index with a stale block, a stale deletion mark and a stale Parquet conversion, and missing block3.
+	stale := ulid.MustNew(9, nil)
+	require.NoError(t, bucketindex.WriteIndex(ctx, bkt, "user-1", nil, &bucketindex.Index{
+		Version: bucketindex.IndexVersion1,
+		Blocks: bucketindex.Blocks{
+			{ID: block1, Parquet: &parquet.ConverterMarkMeta{Version: parquet.CurrentVersion}},
+			{ID: block2.ULID},
+			{ID: block4.ULID},
+			{ID: stale},
+		},
+		BlockDeletionMarks: bucketindex.BlockDeletionMarks{{ID: stale}},
+		UpdatedAt:          time.Now().Add(time.Hour).Unix(),
+	}))
+
+	cfg := Config{UserID: "user-1", DryRun: true, TenantConcurrency: 1}
+	report, err := NewChecker(cfg, bkt, log.NewNopLogger()).Run(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Tenants)
+	assert.Equal(t, 7, report.Blocks)
+	assert.ElementsMatch(t, []issueKey{
+		{Type: MissingBlockFiles, UserID: "user-1", BlockID: block1.String()},
+		{Type: StaleBucketIndexParquet, UserID: "user-1", BlockID: block1.String()},
+		{Type: MissingGlobalMarker, UserID: "user-1", BlockID: block2.ULID.String()},
+		{Type: OrphanParquetFiles, UserID: "user-1", BlockID: block3.ULID.String()},
+		{Type: BlockMissingFromBucketIndex, UserID: "user-1", BlockID: block3.ULID.String()},
+		{Type: ConverterMarkWithoutParquetFiles, UserID: "user-1", BlockID: block4.ULID.String()},
+		{Type: PartialBlock, UserID: "user-1", BlockID: partial.String()},
+		{Type: CorruptedBlockMeta, UserID: "user-1", BlockID: corrupted.String()},
+		{Type: MarkerWithoutBlock, UserID: "user-1", BlockID: missing.String()},
+		{Type: StaleBucketIndexBlock, UserID: "user-1", BlockID: stale.String()},
+		{Type: StaleBucketIndexDeletionMark, UserID: "user-1", BlockID: stale.String()},
+	}, issueKeys(report.Issues))
+	assert.Equal(t, len(report.Issues), report.Unrepaired())
+
+	// Nothing is modified in dry-run mode.
+	exists, err := bkt.Exists(ctx, path.Join("user-1", partial.String(), metadata.DeletionMarkFilename))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Repair the issues.
+	cfg.DryRun = false
+	report, err = NewChecker(cfg, bkt, log.NewNopLogger()).Run(ctx)
+	require.NoError(t, err)
+	for _, issue := range report.Issues {
+		assert.Empty(t, issue.RepairError)
+		assert.Equal(t, issue.Repair != "", issue.Repaired, issue.Type)
+	}
+	assert.Equal(t, 2, report.Unrepaired())
+
+	for name, expected := range map[string]bool{
+		path.Join("user-1", partial.String(), metadata.DeletionMarkFilename):        true,
+		path.Join("user-1", bucketindex.BlockDeletionMarkFilepath(partial)):         true,
+		path.Join("user-1", bucketindex.BlockDeletionMarkFilepath(block2.ULID)):     true,
+		path.Join("user-1", block3.ULID.String(), "0.labels.parquet"):               false,
+		path.Join("user-1", block4.ULID.String(), parquet.ConverterMarkerFileName):  false,
+		path.Join("user-1", bucketindex.ConverterMarkFilePath(block4.ULID)):         false,
+		path.Join("user-1", bucketindex.NoCompactMarkFilenameMarkFilepath(missing)): false,
+		path.Join("user-1", corrupted.String(), metadata.MetaFilename):              true,
+	} {
+		exists, err := bkt.Exists(ctx, name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, exists, name)
+	}
+
+	// Only the issues which can't be repaired automatically are left.
+	cfg.DryRun = true
+	report, err = NewChecker(cfg, bkt, log.NewNopLogger()).Run(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []issueKey{
+		{Type: MissingBlockFiles, UserID: "user-1", BlockID: block1.String()},
+		{Type: CorruptedBlockMeta, UserID: "user-1", BlockID: corrupted.String()},
+	}, issueKeys(report.Issues))
+
+	idx, err := bucketindex.ReadIndex(ctx, bkt, "user-1", nil, log.NewNopLogger())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []ulid.ULID{block1, block2.ULID, block3.ULID, block4.ULID}, idx.Blocks.GetULIDs())
+	for _, b := range idx.Blocks {
+		assert.Nil(t, b.Parquet)
+	}
+	assert.ElementsMatch(t, []ulid.ULID{partial, block2.ULID}, idx.BlockDeletionMarks.GetULIDs())
+}
+
+func TestChecker_UserIndex(t *testing.T) {
+	ctx := context.Background()
+	bkt := objstore.WithNoopInstr(objstore.NewInMemBucket())
+
+	testutil.MockStorageBlock(t, bkt, "user-1", 0, 1)
+	testutil.MockStorageBlock(t, bkt, "user-2", 0, 1)
+	require.NoError(t, users.WriteTenantDeletionMark(ctx, bkt, "user-3", users.NewTenantDeletionMark(time.Now())))
+	require.NoError(t, users.WriteUserIndex(ctx, bkt, &users.UserIndex{
+		Version:     1,
+		ActiveUsers: []string{"user-1", "user-4"},
+		UpdatedAt:   time.Now().Unix(),
+	}))
+
+	// The blocks uploaded recently are expected not to be in any bucket index yet.
+	cfg := Config{DryRun: true, MinAge: time.Hour, TenantConcurrency: 2}
+	report, err := NewChecker(cfg, bkt, log.NewNopLogger()).Run(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.Tenants)
+	assert.Equal(t, 2, report.Blocks)
+	assert.Equal(t, []issueKey{
+		{Type: TenantMissingFromUserIndex, UserID: "user-2"},
+		{Type: TenantMissingFromUserIndex, UserID: "user-3"},
+		{Type: StaleUserIndexTenant, UserID: "user-4"},
+	}, issueKeys(report.Issues))
+
+	cfg.DryRun = false
+	report, err = NewChecker(cfg, bkt, log.NewNopLogger()).Run(ctx)
+	require.NoError(t, err)
+	assert.Len(t, report.Issues, 3)
+	assert.Zero(t, report.Unrepaired())
+
+	idx, err := users.ReadUserIndex(ctx, bkt, log.NewNopLogger())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user-1", "user-2"}, idx.ActiveUsers)
+	assert.Equal(t, []string{"user-3"}, idx.DeletedUsers)
+
+	// Without the min age, the tenants without bucket index are reported.
+	cfg.MinAge = 0
+	cfg.DryRun = true
+	report, err = NewChecker(cfg, bkt, log.NewNopLogger()).Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []issueKey{
+		{Type: MissingBucketIndex, UserID: "user-1"},
+		{Type: MissingBucketIndex, UserID: "user-2"},
+	}, issueKeys(report.Issues))
+}
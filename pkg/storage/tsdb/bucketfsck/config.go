@@ -0,0 +1,40 @@
+package bucketfsck
+
+import (
+	"flag"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	errInvalidMinAge            = errors.New("the min age must be greater than or equal to 0")
+	errInvalidTenantConcurrency = errors.New("the tenant concurrency must be greater than 0")
+)
+
+// Config holds the bucket consistency checker config.
+type Config struct {
+	UserID            string
+	DryRun            bool
+	MinAge            time.Duration
+	TenantConcurrency int
+}
+
+// RegisterFlags registers the bucket consistency checker flags.
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	f.StringVar(&cfg.UserID, "bucket-fsck.user", "", "ID of the tenant to check. If empty, all the tenants are checked, along with the user index.")
+	f.BoolVar(&cfg.DryRun, "bucket-fsck.dry-run", true, "If true, the inconsistencies are only reported. Set it to false to repair them.")
+	f.DurationVar(&cfg.MinAge, "bucket-fsck.min-age", 24*time.Hour, "Minimum age of the partial blocks and Parquet files to report them as inconsistent, in order to skip the uploads in progress.")
+	f.IntVar(&cfg.TenantConcurrency, "bucket-fsck.tenant-concurrency", 4, "Number of tenants checked concurrently.")
+}
+
+// Validate the config.
+func (cfg *Config) Validate() error {
+	if cfg.MinAge < 0 {
+		return errInvalidMinAge
+	}
+	if cfg.TenantConcurrency <= 0 {
+		return errInvalidTenantConcurrency
+	}
+	return nil
+}
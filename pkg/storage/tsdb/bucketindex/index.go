@@ -113,6 +113,15 @@ type Block struct {
 	SeriesMaxSize int64 `json:"series_max_size,omitempty"`
 	ChunkMaxSize  int64 `json:"chunk_max_size,omitempty"`
 
+	// Size in bytes of the index, chunks segments and parquet files of the block. They're
+	// zero if unknown, as in the case of blocks added to the index by previous versions.
+	IndexSize   int64 `json:"index_size,omitempty"`
+	ChunksSize  int64 `json:"chunks_size,omitempty"`
+	ParquetSize int64 `json:"parquet_size,omitempty"`
+
+	// CompactionLevel is the compaction level of the block, where 1 means not compacted yet.
+	CompactionLevel int `json:"compaction_level,omitempty"`
+
 	// UploadedAt is a unix timestamp (seconds precision) of when the block has been completed to be uploaded
 	// to the storage.
 	UploadedAt int64 `json:"uploaded_at"`
@@ -132,6 +141,11 @@ func (m *Block) GetUploadedAt() time.Time {
 	return time.Unix(m.UploadedAt, 0)
 }
 
+// Size returns the total size in bytes of the block files.
+func (m *Block) Size() int64 {
+	return m.IndexSize + m.ChunksSize + m.ParquetSize
+}
+
 // ThanosMeta returns a block meta based on the known information in the index.
 // The returned meta doesn't include all original meta.json data but only a subset
 // of it.
@@ -178,14 +192,29 @@ func BlockFromThanosMeta(meta metadata.Meta) *Block {
 	segmentsFormat, segmentsNum := detectBlockSegmentsFormat(meta)
 
 	return &Block{
-		ID:             meta.ULID,
-		MinTime:        meta.MinTime,
-		MaxTime:        meta.MaxTime,
-		SegmentsFormat: segmentsFormat,
-		SegmentsNum:    segmentsNum,
-		SeriesMaxSize:  meta.Thanos.IndexStats.SeriesMaxSize,
-		ChunkMaxSize:   meta.Thanos.IndexStats.ChunkMaxSize,
+		ID:              meta.ULID,
+		MinTime:         meta.MinTime,
+		MaxTime:         meta.MaxTime,
+		SegmentsFormat:  segmentsFormat,
+		SegmentsNum:     segmentsNum,
+		SeriesMaxSize:   meta.Thanos.IndexStats.SeriesMaxSize,
+		ChunkMaxSize:    meta.Thanos.IndexStats.ChunkMaxSize,
+		CompactionLevel: meta.Compaction.Level,
+	}
+}
+
+// blockFilesSize returns the size of the index and chunks segments of the block, as
+// listed in the meta.json files. Both are zero if the files are not listed.
+func blockFilesSize(meta metadata.Meta) (indexSize, chunksSize int64) {
+	for _, file := range meta.Thanos.Files {
+		switch {
+		case file.RelPath == block.IndexFilename:
+			indexSize = file.SizeBytes
+		case strings.HasPrefix(file.RelPath, block.ChunksDirname+string(filepath.Separator)):
+			chunksSize += file.SizeBytes
+		}
 	}
+	return indexSize, chunksSize
 }
 
 func detectBlockSegmentsFormat(meta metadata.Meta) (string, int) {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/go-kit/log"
@@ -110,6 +111,18 @@ func (w *Updater) updateBlocks(ctx context.Context, old []*Block, deletedBlocks
 				level.Warn(w.logger).Log("msg", "skipped block with missing global deletion marker", "block", b.ID.String())
 				continue
 			}
+
+			// Blocks added to the index by previous versions have no size nor compaction
+			// level, so we refresh them once.
+			if b.CompactionLevel == 0 {
+				if updated, err := w.updateBlockIndexEntry(ctx, b.ID); err == nil {
+					updated.Parquet = b.Parquet
+					updated.ParquetSize = b.ParquetSize
+					b = updated
+				} else {
+					level.Warn(w.logger).Log("msg", "failed to refresh block size when updating bucket index", "block", b.ID.String(), "err", err)
+				}
+			}
 			blocks = append(blocks, b)
 		}
 	}
@@ -178,6 +191,9 @@ func (w *Updater) updateBlockIndexEntry(ctx context.Context, id ulid.ULID) (*Blo
 
 	block := BlockFromThanosMeta(m)
 
+	// The size is unknown for the blocks whose files are not listed in the meta.json.
+	block.IndexSize, block.ChunksSize = blockFilesSize(m)
+
 	// Get the meta.json attributes.
 	attrs, err := w.bkt.Attributes(ctx, metaFile)
 	if err != nil {
@@ -207,9 +223,37 @@ func (w *Updater) updateParquetBlockIndexEntry(ctx context.Context, id ulid.ULID
 		Version: marker.Version,
 		Shards:  marker.Shards,
 	}
+
+	if block.ParquetSize == 0 {
+		size, err := w.filesSize(ctx, id.String()+"/", func(name string) bool { return strings.HasSuffix(name, ".parquet") })
+		if err != nil {
+			return errors.Wrapf(err, "read parquet files size of block %s", id.String())
+		}
+		block.ParquetSize = size
+	}
 	return nil
 }
 
+// filesSize returns the total size of the files in the directory accepted by the filter.
+func (w *Updater) filesSize(ctx context.Context, dir string, filter func(name string) bool) (int64, error) {
+	total := int64(0)
+
+	err := w.bkt.Iter(ctx, dir, func(name string) error {
+		if strings.HasSuffix(name, "/") || !filter(name) {
+			return nil
+		}
+
+		attrs, err := w.bkt.Attributes(ctx, name)
+		if err != nil {
+			return err
+		}
+		total += attrs.Size
+		return nil
+	})
+
+	return total, err
+}
+
 func (w *Updater) updateBlockMarks(ctx context.Context, old []*BlockDeletionMark) ([]*BlockDeletionMark, map[ulid.ULID]struct{}, int64, error) {
 	out := make([]*BlockDeletionMark, 0, len(old))
 	deletedBlocks := map[ulid.ULID]struct{}{}
@@ -303,6 +347,7 @@ func (w *Updater) updateParquetBlocks(ctx context.Context, blocks []*Block) erro
 		} else if m.Parquet != nil {
 			// Converter marker removed. Reset parquet field.
 			m.Parquet = nil
+			m.ParquetSize = 0
 		}
 	}
 	return nil
@@ -303,6 +303,68 @@ func TestUpdater_UpdateIndex_NoTenantInTheBucket(t *testing.T) {
 	}
 }
 
+func TestUpdater_UpdateIndex_ShouldRecordBlockSizes(t *testing.T) {
+	const userID = "user-1"
+
+	bkt, _ := testutil.PrepareFilesystemBucket(t)
+	bkt = BucketWithGlobalMarkers(bkt)
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+
+	// A block whose files are not listed in the meta.json, so its size is unknown.
+	block1 := testutil.MockStorageBlock(t, bkt, userID, 10, 20)
+
+	// A compacted block whose files are listed in the meta.json.
+	block2 := testutil.MockStorageBlock(t, bkt, userID, 20, 30)
+	meta2 := metadata.Meta{
+		BlockMeta: block2,
+		Thanos: metadata.Thanos{
+			Version: metadata.ThanosVersion1,
+			Files: []metadata.File{
+				{RelPath: "chunks/000001", SizeBytes: 1000},
+				{RelPath: "index", SizeBytes: 100},
+				{RelPath: "meta.json"},
+			},
+		},
+	}
+	meta2.Compaction.Level = 3
+	metaContent, err := json.Marshal(meta2)
+	require.NoError(t, err)
+	require.NoError(t, bkt.Upload(ctx, path.Join(userID, block2.ULID.String(), block.MetaFilename), bytes.NewReader(metaContent)))
+
+	// A block converted to parquet.
+	block3 := testutil.MockStorageBlock(t, bkt, userID, 30, 40)
+	testutil.MockStorageParquetConverterMark(t, bkt, userID, block3, 1)
+	require.NoError(t, bkt.Upload(ctx, path.Join(userID, block3.ULID.String(), "0.labels.parquet"), strings.NewReader("labels")))
+	require.NoError(t, bkt.Upload(ctx, path.Join(userID, block3.ULID.String(), "0.chunks.parquet"), strings.NewReader("chunks")))
+
+	w := NewUpdater(bkt, userID, nil, logger).EnableParquet()
+	idx, _, _, err := w.UpdateIndex(ctx, nil)
+	require.NoError(t, err)
+
+	sizes := map[ulid.ULID][4]int64{}
+	for _, b := range idx.Blocks {
+		sizes[b.ID] = [4]int64{b.IndexSize, b.ChunksSize, b.ParquetSize, int64(b.CompactionLevel)}
+	}
+	assert.Equal(t, map[ulid.ULID][4]int64{
+		block1.ULID: {0, 0, 0, 1},
+		block2.ULID: {100, 1000, 0, 3},
+		block3.ULID: {0, 0, 12, 1},
+	}, sizes)
+
+	// The size of the blocks added to the index by previous versions should be refreshed.
+	for _, b := range idx.Blocks {
+		b.IndexSize, b.ChunksSize, b.CompactionLevel = 0, 0, 0
+	}
+	idx, _, _, err = w.UpdateIndex(ctx, idx)
+	require.NoError(t, err)
+
+	for _, b := range idx.Blocks {
+		assert.Equal(t, sizes[b.ID], [4]int64{b.IndexSize, b.ChunksSize, b.ParquetSize, int64(b.CompactionLevel)})
+	}
+}
+
 func TestUpdater_UpdateIndex_WithParquet(t *testing.T) {
 	const userID = "user-1"
 
@@ -480,10 +542,11 @@ func assertBucketIndexEqual(t testing.TB, idx *Index, bkt objstore.Bucket, userI
 	var expectedBlockEntries []*Block
 	for _, b := range expectedBlocks {
 		expectedBlockEntries = append(expectedBlockEntries, &Block{
-			ID:         b.ULID,
-			MinTime:    b.MinTime,
-			MaxTime:    b.MaxTime,
-			UploadedAt: getBlockUploadedAt(t, bkt, userID, b.ULID),
+			ID:              b.ULID,
+			MinTime:         b.MinTime,
+			MaxTime:         b.MaxTime,
+			UploadedAt:      getBlockUploadedAt(t, bkt, userID, b.ULID),
+			CompactionLevel: b.Compaction.Level,
 		})
 	}
 
@@ -509,10 +572,11 @@ func assertBucketIndexEqualWithParquet(t testing.TB, idx *Index, bkt objstore.Bu
 	var expectedBlockEntries []*Block
 	for _, b := range expectedBlocks {
 		block := &Block{
-			ID:         b.ULID,
-			MinTime:    b.MinTime,
-			MaxTime:    b.MaxTime,
-			UploadedAt: getBlockUploadedAt(t, bkt, userID, b.ULID),
+			ID:              b.ULID,
+			MinTime:         b.MinTime,
+			MaxTime:         b.MaxTime,
+			UploadedAt:      getBlockUploadedAt(t, bkt, userID, b.ULID),
+			CompactionLevel: b.Compaction.Level,
 		}
 		if meta, ok := parquetBlocks[b.ULID.String()]; ok {
 			block.Parquet = meta
@@ -0,0 +1,114 @@
+package bucketindex
+
+import (
+	"sort"
+	"time"
+)
+
+// BlockAgeRange is a range of blocks age, by their max time, the storage usage is reported by.
+type BlockAgeRange struct {
+	Name   string
+	MaxAge time.Duration
+}
+
+// BlockAgeRanges are the ranges of blocks age the storage usage is reported by. The last
+// range has no max age.
+var BlockAgeRanges = []BlockAgeRange{
+	{Name: "0-1d", MaxAge: 24 * time.Hour},
+	{Name: "1d-7d", MaxAge: 7 * 24 * time.Hour},
+	{Name: "7d-30d", MaxAge: 30 * 24 * time.Hour},
+	{Name: "30d-90d", MaxAge: 90 * 24 * time.Hour},
+	{Name: "90d+"},
+}
+
+// StorageUsage is the storage used by the blocks of a tenant, according to its bucket index.
+// The blocks marked for deletion are included until they're deleted.
+type StorageUsage struct {
+	Blocks       int   `json:"blocks"`
+	TotalBytes   int64 `json:"total_bytes"`
+	IndexBytes   int64 `json:"index_bytes"`
+	ChunksBytes  int64 `json:"chunks_bytes"`
+	ParquetBytes int64 `json:"parquet_bytes"`
+
+	// UnknownSizeBlocks is the number of blocks whose size is not known yet.
+	UnknownSizeBlocks int `json:"unknown_size_blocks"`
+
+	ByLevel []LevelStorageUsage `json:"by_level"`
+	ByAge   []AgeStorageUsage   `json:"by_age"`
+}
+
+// LevelStorageUsage is the storage used by the blocks of a compaction level.
+type LevelStorageUsage struct {
+	Level  int   `json:"level"`
+	Blocks int   `json:"blocks"`
+	Bytes  int64 `json:"bytes"`
+}
+
+// AgeStorageUsage is the storage used by the blocks of an age range.
+type AgeStorageUsage struct {
+	Age    string `json:"age"`
+	Blocks int    `json:"blocks"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// StorageUsage returns the storage used by the blocks in the index, with their age computed
+// relative to now. The usage by age includes all the ranges, even if empty, while the usage
+// by level only includes the levels with at least one block.
+func (idx *Index) StorageUsage(now time.Time) StorageUsage {
+	usage := StorageUsage{
+		ByLevel: []LevelStorageUsage{},
+		ByAge:   make([]AgeStorageUsage, len(BlockAgeRanges)),
+	}
+	for i, r := range BlockAgeRanges {
+		usage.ByAge[i].Age = r.Name
+	}
+
+	levels := map[int]*LevelStorageUsage{}
+	for _, b := range idx.Blocks {
+		size := b.Size()
+
+		usage.Blocks++
+		usage.TotalBytes += size
+		usage.IndexBytes += b.IndexSize
+		usage.ChunksBytes += b.ChunksSize
+		usage.ParquetBytes += b.ParquetSize
+		if size == 0 {
+			usage.UnknownSizeBlocks++
+		}
+
+		l, ok := levels[b.CompactionLevel]
+		if !ok {
+			l = &LevelStorageUsage{Level: b.CompactionLevel}
+			levels[b.CompactionLevel] = l
+		}
+		l.Blocks++
+		l.Bytes += size
+
+		age := &usage.ByAge[blockAgeRangeIndex(now.Sub(time.UnixMilli(b.MaxTime)))]
+		age.Blocks++
+		age.Bytes += size
+	}
+
+	for _, l := range levels {
+		usage.ByLevel = append(usage.ByLevel, *l)
+	}
+	sort.Slice(usage.ByLevel, func(i, j int) bool {
+		return usage.ByLevel[i].Level < usage.ByLevel[j].Level
+	})
+
+	return usage
+}
+
+// AgeRange returns the name of the age range of the block, with its age computed relative to now.
+func (m *Block) AgeRange(now time.Time) string {
+	return BlockAgeRanges[blockAgeRangeIndex(now.Sub(time.UnixMilli(m.MaxTime)))].Name
+}
+
+func blockAgeRangeIndex(age time.Duration) int {
+	for i, r := range BlockAgeRanges {
+		if r.MaxAge == 0 || age < r.MaxAge {
+			return i
+		}
+	}
+	return len(BlockAgeRanges) - 1
+}
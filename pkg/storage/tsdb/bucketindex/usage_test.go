@@ -0,0 +1,46 @@
+package bucketindex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndex_StorageUsage(t *testing.T) {
+	now := time.Now()
+	maxTime := func(age time.Duration) int64 { return now.Add(-age).UnixMilli() }
+
+	idx := &Index{Blocks: Blocks{
+		{MaxTime: maxTime(time.Hour), CompactionLevel: 1, IndexSize: 10, ChunksSize: 90},
+		{MaxTime: maxTime(2 * time.Hour), CompactionLevel: 1},
+		{MaxTime: maxTime(3 * 24 * time.Hour), CompactionLevel: 2, IndexSize: 20, ChunksSize: 180, ParquetSize: 100},
+		{MaxTime: maxTime(365 * 24 * time.Hour), CompactionLevel: 4, IndexSize: 100, ChunksSize: 900},
+	}}
+
+	assert.Equal(t, StorageUsage{
+		Blocks:            4,
+		TotalBytes:        1400,
+		IndexBytes:        130,
+		ChunksBytes:       1170,
+		ParquetBytes:      100,
+		UnknownSizeBlocks: 1,
+		ByLevel: []LevelStorageUsage{
+			{Level: 1, Blocks: 2, Bytes: 100},
+			{Level: 2, Blocks: 1, Bytes: 300},
+			{Level: 4, Blocks: 1, Bytes: 1000},
+		},
+		ByAge: []AgeStorageUsage{
+			{Age: "0-1d", Blocks: 2, Bytes: 100},
+			{Age: "1d-7d", Blocks: 1, Bytes: 300},
+			{Age: "7d-30d"},
+			{Age: "30d-90d"},
+			{Age: "90d+", Blocks: 1, Bytes: 1000},
+		},
+	}, idx.StorageUsage(now))
+
+	assert.Equal(t, StorageUsage{
+		ByLevel: []LevelStorageUsage{},
+		ByAge:   []AgeStorageUsage{{Age: "0-1d"}, {Age: "1d-7d"}, {Age: "7d-30d"}, {Age: "30d-90d"}, {Age: "90d+"}},
+	}, (&Index{}).StorageUsage(now))
+}
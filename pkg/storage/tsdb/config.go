@@ -16,6 +16,7 @@ import (
 	"github.com/thanos-io/thanos/pkg/store"
 
 	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	"github.com/cortexproject/cortex/pkg/storage/bucket/encryption"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
 	util_log "github.com/cortexproject/cortex/pkg/util/log"
 	"github.com/cortexproject/cortex/pkg/util/parquetutil"
@@ -64,6 +65,7 @@ var (
 	ErrInvalidTokenBucketBytesLimiterMode               = errors.New("invalid token bucket bytes limiter mode")
 	ErrInvalidLazyExpandedPostingGroupMaxKeySeriesRatio = errors.New("lazy expanded posting group max key series ratio needs to be equal or greater than 0")
 	ErrInvalidBucketStoreType                           = errors.New("invalid bucket store type")
+	ErrEncryptionWithRemoteCache                        = errors.New("the blocks storage client-side encryption can't be enabled with a memcached or redis cache, which would store the decrypted data")
 )
 
 // BlocksStorageConfig holds the config information for the blocks storage.
@@ -74,6 +76,7 @@ type BlocksStorageConfig struct {
 	BucketStore  BucketStoreConfig        `yaml:"bucket_store" doc:"description=This configures how the querier and store-gateway discover and synchronize blocks stored in the bucket."`
 	TSDB         TSDBConfig               `yaml:"tsdb"`
 	UsersScanner users.UsersScannerConfig `yaml:"users_scanner"`
+	Encryption   encryption.Config        `yaml:"encryption"`
 }
 
 // DurationList is the block ranges for a tsdb
@@ -119,6 +122,7 @@ func (cfg *BlocksStorageConfig) RegisterFlags(f *flag.FlagSet) {
 	cfg.BucketStore.RegisterFlags(f)
 	cfg.TSDB.RegisterFlags(f)
 	cfg.UsersScanner.RegisterFlagsWithPrefix("blocks-storage.", f)
+	cfg.Encryption.RegisterFlagsWithPrefix("blocks-storage.", f)
 }
 
 // Validate the config.
@@ -135,6 +139,13 @@ func (cfg *BlocksStorageConfig) Validate() error {
 		return err
 	}
 
+	if err := cfg.Encryption.Validate(); err != nil {
+		return err
+	}
+	if cfg.Encryption.Enabled && cfg.BucketStore.hasRemoteCache() {
+		return ErrEncryptionWithRemoteCache
+	}
+
 	return cfg.BucketStore.Validate()
 }
 
@@ -432,6 +443,19 @@ func (cfg *BucketStoreConfig) Validate() error {
 	return nil
 }
 
+// hasRemoteCache returns whether any of the caches is backed by memcached or redis,
+// which store the cached objects out of the process.
+func (cfg *BucketStoreConfig) hasRemoteCache() bool {
+	for _, backends := range []string{cfg.IndexCache.Backend, cfg.ChunksCache.Backend, cfg.MetadataCache.Backend, cfg.ParquetLabelsCache.Backend} {
+		for _, backend := range strings.Split(backends, ",") {
+			if backend == CacheBackendMemcached || backend == CacheBackendRedis {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 type BucketIndexConfig struct {
 	Enabled               bool          `yaml:"enabled"`
 	UpdateOnErrorInterval time.Duration `yaml:"update_on_error_interval"`
@@ -145,6 +145,30 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			expectedErr: errUnSupportedWALCompressionType,
 		},
+		"should pass on encryption with in-memory caches": {
+			setup: func(cfg *BlocksStorageConfig) {
+				cfg.Encryption.Enabled = true
+				cfg.Encryption.Keyfile.Path = "keys.yaml"
+				cfg.BucketStore.ChunksCache.Backend = CacheBackendInMemory
+			},
+			expectedErr: nil,
+		},
+		"should fail on encryption with a memcached chunks cache": {
+			setup: func(cfg *BlocksStorageConfig) {
+				cfg.Encryption.Enabled = true
+				cfg.Encryption.Keyfile.Path = "keys.yaml"
+				cfg.BucketStore.ChunksCache.Backend = CacheBackendInMemory + "," + CacheBackendMemcached
+			},
+			expectedErr: ErrEncryptionWithRemoteCache,
+		},
+		"should fail on encryption with a redis index cache": {
+			setup: func(cfg *BlocksStorageConfig) {
+				cfg.Encryption.Enabled = true
+				cfg.Encryption.Keyfile.Path = "keys.yaml"
+				cfg.BucketStore.IndexCache.Backend = IndexCacheBackendRedis
+			},
+			expectedErr: ErrEncryptionWithRemoteCache,
+		},
 	}
 
 	for testName, testData := range tests {
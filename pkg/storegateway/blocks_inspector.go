@@ -0,0 +1,269 @@
+package storegateway
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+	storecache "github.com/thanos-io/thanos/pkg/store/cache"
+	"go.uber.org/atomic"
+)
+
+const (
+	// Index-header states reported by the blocks inspector.
+	IndexHeaderStateLoaded = "loaded"
+	IndexHeaderStateLazy   = "lazy"
+)
+
+// BlocksInspector is implemented by the bucket stores able to report the state of the
+// blocks owned by the store-gateway.
+type BlocksInspector interface {
+	// InspectBlocks returns the state of the blocks of the given tenant, or of all
+	// tenants if the user ID is empty.
+	InspectBlocks(userID string) []UserBlocksInfo
+}
+
+// UserBlocksInfo holds the state of the blocks of a tenant owned by the store-gateway.
+type UserBlocksInfo struct {
+	UserID     string          `json:"user_id"`
+	Blocks     []BlockInfo     `json:"blocks"`
+	IndexCache IndexCacheStats `json:"index_cache"`
+}
+
+// BlockInfo holds the state of a block owned by the store-gateway.
+type BlockInfo struct {
+	ID      ulid.ULID `json:"id"`
+	MinTime int64     `json:"min_time"`
+	MaxTime int64     `json:"max_time"`
+
+	// Loaded is true if the block has been handed to the bucket store to be loaded.
+	Loaded bool `json:"loaded"`
+
+	// IndexHeader is the state of the index-header of a loaded block: loaded when the index-header
+	// is memory-mapped, lazy when lazy loading is enabled and the index-header is not memory-mapped.
+	// The state is only reported on Linux, where it's read from the process memory mappings.
+	IndexHeader string `json:"index_header,omitempty"`
+
+	// LastAccess is the last time the block has been queried since the store-gateway started.
+	LastAccess time.Time `json:"last_access,omitempty"`
+
+	// DiskBytes is the size of the local files of the block, while MemoryBytes is the resident
+	// memory of the index-header memory-mapped by the store-gateway.
+	DiskBytes   int64 `json:"disk_bytes"`
+	MemoryBytes int64 `json:"memory_bytes"`
+}
+
+// IndexCacheStats holds the index cache lookups done for a tenant since the store-gateway started.
+type IndexCacheStats struct {
+	PostingsRequests         uint64  `json:"postings_requests"`
+	PostingsHits             uint64  `json:"postings_hits"`
+	PostingsHitRatio         float64 `json:"postings_hit_ratio"`
+	ExpandedPostingsRequests uint64  `json:"expanded_postings_requests"`
+	ExpandedPostingsHits     uint64  `json:"expanded_postings_hits"`
+	ExpandedPostingsHitRatio float64 `json:"expanded_postings_hit_ratio"`
+	SeriesRequests           uint64  `json:"series_requests"`
+	SeriesHits               uint64  `json:"series_hits"`
+	SeriesHitRatio           float64 `json:"series_hit_ratio"`
+}
+
+// userBlocksTracker keeps track of the blocks owned by a tenant and of their usage. It's both
+// the last metadata filter of the tenant bucket store and a wrapper of its index cache.
+type userBlocksTracker struct {
+	mtx        sync.Mutex
+	owned      map[ulid.ULID]*metadata.Meta
+	added      map[ulid.ULID]struct{}
+	lastAccess map[ulid.ULID]time.Time
+
+	postingsRequests         atomic.Uint64
+	postingsHits             atomic.Uint64
+	expandedPostingsRequests atomic.Uint64
+	expandedPostingsHits     atomic.Uint64
+	seriesRequests           atomic.Uint64
+	seriesHits               atomic.Uint64
+}
+
+func newUserBlocksTracker() *userBlocksTracker {
+	return &userBlocksTracker{
+		owned:      map[ulid.ULID]*metadata.Meta{},
+		added:      map[ulid.ULID]struct{}{},
+		lastAccess: map[ulid.ULID]time.Time{},
+	}
+}
+
+// Filter implements block.MetadataFilter. It doesn't filter out any block but keeps track
+// of the ones which have passed all the previous filters.
+func (t *userBlocksTracker) Filter(_ context.Context, metas map[ulid.ULID]*metadata.Meta, _ block.GaugeVec, _ block.GaugeVec) error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.owned = make(map[ulid.ULID]*metadata.Meta, len(metas))
+	for id, meta := range metas {
+		t.owned[id] = meta
+	}
+
+	// Forget the blocks which are not owned anymore, since the bucket store drops them.
+	for id := range t.added {
+		if _, ok := metas[id]; !ok {
+			delete(t.added, id)
+		}
+	}
+	for id := range t.lastAccess {
+		if _, ok := metas[id]; !ok {
+			delete(t.lastAccess, id)
+		}
+	}
+
+	return nil
+}
+
+// add keeps track of a block handed to the bucket store to be loaded. The bucket store hands a block
+// again at the next sync if it failed to load it.
+func (t *userBlocksTracker) add(blockID ulid.ULID) {
+	t.mtx.Lock()
+	t.added[blockID] = struct{}{}
+	t.mtx.Unlock()
+}
+
+func (t *userBlocksTracker) touch(blockID ulid.ULID) {
+	t.mtx.Lock()
+	t.lastAccess[blockID] = time.Now()
+	t.mtx.Unlock()
+}
+
+// indexCache returns an index cache wrapping the input one and tracking the lookups.
+func (t *userBlocksTracker) indexCache(cache storecache.IndexCache) storecache.IndexCache {
+	return &trackingIndexCache{IndexCache: cache, tracker: t}
+}
+
+func (t *userBlocksTracker) cacheStats() IndexCacheStats {
+	stats := IndexCacheStats{
+		PostingsRequests:         t.postingsRequests.Load(),
+		PostingsHits:             t.postingsHits.Load(),
+		ExpandedPostingsRequests: t.expandedPostingsRequests.Load(),
+		ExpandedPostingsHits:     t.expandedPostingsHits.Load(),
+		SeriesRequests:           t.seriesRequests.Load(),
+		SeriesHits:               t.seriesHits.Load(),
+	}
+	stats.PostingsHitRatio = hitRatio(stats.PostingsHits, stats.PostingsRequests)
+	stats.ExpandedPostingsHitRatio = hitRatio(stats.ExpandedPostingsHits, stats.ExpandedPostingsRequests)
+	stats.SeriesHitRatio = hitRatio(stats.SeriesHits, stats.SeriesRequests)
+	return stats
+}
+
+// inspect returns the state of the owned blocks, whose local files are stored in the input directory.
+// The input mapped files are the resident bytes of the files memory-mapped by the process, by absolute
+// path, or nil if unknown.
+func (t *userBlocksTracker) inspect(dir string, lazyLoadingEnabled bool, mapped map[string]int64) []BlockInfo {
+	t.mtx.Lock()
+	blocks := make([]BlockInfo, 0, len(t.owned))
+	for id, meta := range t.owned {
+		_, added := t.added[id]
+		blocks = append(blocks, BlockInfo{
+			ID:         id,
+			MinTime:    meta.MinTime,
+			MaxTime:    meta.MaxTime,
+			Loaded:     added,
+			LastAccess: t.lastAccess[id],
+		})
+	}
+	t.mtx.Unlock()
+
+	// The memory-mapped files are reported by absolute path, with the symlinks resolved.
+	if absDir, err := filepath.Abs(dir); err == nil {
+		dir = absDir
+	}
+	if realDir, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = realDir
+	}
+
+	for i := range blocks {
+		b := &blocks[i]
+		if !b.Loaded {
+			continue
+		}
+
+		blockDir := filepath.Join(dir, b.ID.String())
+		b.DiskBytes = dirSize(blockDir)
+		if mapped == nil {
+			continue
+		}
+
+		// The index-header is memory-mapped once loaded, and released by the lazy reader once idle.
+		if rss, ok := mapped[filepath.Join(blockDir, block.IndexHeaderFilename)]; ok {
+			b.IndexHeader = IndexHeaderStateLoaded
+			b.MemoryBytes = rss
+		} else if lazyLoadingEnabled {
+			b.IndexHeader = IndexHeaderStateLazy
+		}
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].MinTime != blocks[j].MinTime {
+			return blocks[i].MinTime < blocks[j].MinTime
+		}
+		return blocks[i].ID.Compare(blocks[j].ID) < 0
+	})
+
+	return blocks
+}
+
+type trackingIndexCache struct {
+	storecache.IndexCache
+	tracker *userBlocksTracker
+}
+
+func (c *trackingIndexCache) FetchMultiPostings(ctx context.Context, blockID ulid.ULID, keys []labels.Label, tenant string) (map[labels.Label][]byte, []labels.Label) {
+	c.tracker.touch(blockID)
+	hits, misses := c.IndexCache.FetchMultiPostings(ctx, blockID, keys, tenant)
+	c.tracker.postingsRequests.Add(uint64(len(keys)))
+	c.tracker.postingsHits.Add(uint64(len(hits)))
+	return hits, misses
+}
+
+func (c *trackingIndexCache) FetchExpandedPostings(ctx context.Context, blockID ulid.ULID, matchers []*labels.Matcher, tenant string) ([]byte, bool) {
+	c.tracker.touch(blockID)
+	data, hit := c.IndexCache.FetchExpandedPostings(ctx, blockID, matchers, tenant)
+	c.tracker.expandedPostingsRequests.Inc()
+	if hit {
+		c.tracker.expandedPostingsHits.Inc()
+	}
+	return data, hit
+}
+
+func (c *trackingIndexCache) FetchMultiSeries(ctx context.Context, blockID ulid.ULID, ids []storage.SeriesRef, tenant string) (map[storage.SeriesRef][]byte, []storage.SeriesRef) {
+	c.tracker.touch(blockID)
+	hits, misses := c.IndexCache.FetchMultiSeries(ctx, blockID, ids, tenant)
+	c.tracker.seriesRequests.Add(uint64(len(ids)))
+	c.tracker.seriesHits.Add(uint64(len(hits)))
+	return hits, misses
+}
+
+func hitRatio(hits, requests uint64) float64 {
+	if requests == 0 {
+		return 0
+	}
+	return float64(hits) / float64(requests)
+}
+
+// dirSize returns the total size of the regular files in the input directory.
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,128 @@
+package storegateway
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/oklog/ulid/v2"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+	storecache "github.com/thanos-io/thanos/pkg/store/cache"
+)
+
+func TestUserBlocksTracker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	block1 := ulid.MustNew(1, nil)
+	block2 := ulid.MustNew(2, nil)
+	block3 := ulid.MustNew(3, nil)
+
+	// Only block1 and block2 have local files.
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, block1.String()), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, block1.String(), block.IndexHeaderFilename), make([]byte, 100), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, block2.String()), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, block2.String(), block.IndexHeaderFilename), make([]byte, 50), 0o644))
+
+	tracker := newUserBlocksTracker()
+	require.NoError(t, tracker.Filter(ctx, map[ulid.ULID]*metadata.Meta{
+		block1: {BlockMeta: tsdb.BlockMeta{ULID: block1, MinTime: 10, MaxTime: 20}},
+		block2: {BlockMeta: tsdb.BlockMeta{ULID: block2, MinTime: 0, MaxTime: 10}},
+		block3: {BlockMeta: tsdb.BlockMeta{ULID: block3, MinTime: 20, MaxTime: 30}},
+	}, nil, nil))
+
+	cache, err := storecache.NewInMemoryIndexCacheWithConfig(log.NewNopLogger(), nil, nil, storecache.DefaultInMemoryIndexCacheConfig)
+	require.NoError(t, err)
+	trackingCache := tracker.indexCache(cache)
+
+	// Query block1 only.
+	lbl1 := labels.Label{Name: "job", Value: "a"}
+	lbl2 := labels.Label{Name: "job", Value: "b"}
+	trackingCache.StorePostings(block1, lbl1, []byte("postings"), "")
+	trackingCache.FetchMultiPostings(ctx, block1, []labels.Label{lbl1, lbl2}, "")
+	trackingCache.FetchExpandedPostings(ctx, block1, []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "job", "a")}, "")
+	trackingCache.StoreSeries(block1, storage.SeriesRef(1), []byte("series"), "")
+	trackingCache.FetchMultiSeries(ctx, block1, []storage.SeriesRef{1, 2, 3, 4}, "")
+
+	t.Run("should report the index cache stats", func(t *testing.T) {
+		assert.Equal(t, IndexCacheStats{
+			PostingsRequests:         2,
+			PostingsHits:             1,
+			PostingsHitRatio:         0.5,
+			ExpandedPostingsRequests: 1,
+			ExpandedPostingsHits:     0,
+			ExpandedPostingsHitRatio: 0,
+			SeriesRequests:           4,
+			SeriesHits:               1,
+			SeriesHitRatio:           0.25,
+		}, tracker.cacheStats())
+	})
+
+	// Only block1 and block2 have been handed to the bucket store, and only the index-header of block1 is memory-mapped.
+	tracker.add(block1)
+	tracker.add(block2)
+	absDir, err := filepath.Abs(dir)
+	require.NoError(t, err)
+	mapped := map[string]int64{
+		filepath.Join(absDir, block1.String(), block.IndexHeaderFilename): 4096,
+	}
+
+	t.Run("should report the memory-mapped index-headers as loaded", func(t *testing.T) {
+		blocks := tracker.inspect(dir, false, mapped)
+		require.Len(t, blocks, 3)
+
+		assert.Equal(t, block2, blocks[0].ID)
+		assert.True(t, blocks[0].Loaded)
+		assert.Empty(t, blocks[0].IndexHeader)
+		assert.True(t, blocks[0].LastAccess.IsZero())
+		assert.Equal(t, int64(50), blocks[0].DiskBytes)
+		assert.Zero(t, blocks[0].MemoryBytes)
+
+		assert.Equal(t, block1, blocks[1].ID)
+		assert.True(t, blocks[1].Loaded)
+		assert.Equal(t, IndexHeaderStateLoaded, blocks[1].IndexHeader)
+		assert.False(t, blocks[1].LastAccess.IsZero())
+		assert.Equal(t, int64(100), blocks[1].DiskBytes)
+		assert.Equal(t, int64(4096), blocks[1].MemoryBytes)
+
+		assert.Equal(t, BlockInfo{ID: block3, MinTime: 20, MaxTime: 30}, blocks[2])
+	})
+
+	t.Run("should report the index-headers not memory-mapped as lazy if lazy loading is enabled", func(t *testing.T) {
+		blocks := tracker.inspect(dir, true, mapped)
+		require.Len(t, blocks, 3)
+
+		assert.Equal(t, IndexHeaderStateLazy, blocks[0].IndexHeader)
+		assert.Zero(t, blocks[0].MemoryBytes)
+		assert.Equal(t, IndexHeaderStateLoaded, blocks[1].IndexHeader)
+	})
+
+	t.Run("should not report the index-headers state if the memory-mapped files are unknown", func(t *testing.T) {
+		blocks := tracker.inspect(dir, true, nil)
+		require.Len(t, blocks, 3)
+
+		assert.True(t, blocks[1].Loaded)
+		assert.Empty(t, blocks[1].IndexHeader)
+		assert.Zero(t, blocks[1].MemoryBytes)
+	})
+
+	t.Run("should forget the blocks not owned anymore", func(t *testing.T) {
+		require.NoError(t, tracker.Filter(ctx, map[ulid.ULID]*metadata.Meta{
+			block2: {BlockMeta: tsdb.BlockMeta{ULID: block2, MinTime: 0, MaxTime: 10}},
+		}, nil, nil))
+
+		blocks := tracker.inspect(dir, false, mapped)
+		require.Len(t, blocks, 1)
+		assert.Equal(t, block2, blocks[0].ID)
+		assert.NotContains(t, tracker.added, block1)
+	})
+}
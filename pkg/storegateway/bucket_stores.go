@@ -6,6 +6,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
@@ -81,6 +82,9 @@ type ThanosBucketStores struct {
 	storesMu sync.RWMutex
 	stores   map[string]*store.BucketStore
 
+	// Keeps track of the blocks owned by each tenant (protected by storesMu).
+	blocksTrackers map[string]*userBlocksTracker
+
 	// Keeps the last sync error for the bucket store for each tenant.
 	storesErrorsMu sync.RWMutex
 	storesErrors   map[string]error
@@ -139,6 +143,7 @@ func newThanosBucketStores(cfg tsdb.BlocksStorageConfig, shardingStrategy Shardi
 		bucket:             cachingBucket,
 		shardingStrategy:   shardingStrategy,
 		stores:             map[string]*store.BucketStore{},
+		blocksTrackers:     map[string]*userBlocksTracker{},
 		storesErrors:       map[string]error{},
 		logLevel:           logLevel,
 		bucketStoreMetrics: NewBucketStoreMetrics(),
@@ -482,6 +487,36 @@ func (u *ThanosBucketStores) getStore(userID string) *store.BucketStore {
 	return u.stores[userID]
 }
 
+// InspectBlocks implements BlocksInspector.
+func (u *ThanosBucketStores) InspectBlocks(userID string) []UserBlocksInfo {
+	u.storesMu.RLock()
+	trackers := make(map[string]*userBlocksTracker, len(u.blocksTrackers))
+	for id, tracker := range u.blocksTrackers {
+		if userID == "" || userID == id {
+			trackers[id] = tracker
+		}
+	}
+	u.storesMu.RUnlock()
+
+	// The index-headers memory-mapped by the bucket stores are the loaded ones.
+	mapped, err := readMappedFiles()
+	if err != nil {
+		level.Warn(u.logger).Log("msg", "failed to read the memory-mapped index-headers", "err", err)
+	}
+
+	result := make([]UserBlocksInfo, 0, len(trackers))
+	for id, tracker := range trackers {
+		result = append(result, UserBlocksInfo{
+			UserID:     id,
+			Blocks:     tracker.inspect(u.syncDirForUser(id), u.cfg.BucketStore.IndexHeaderLazyLoadingEnabled, mapped),
+			IndexCache: tracker.cacheStats(),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].UserID < result[j].UserID })
+	return result
+}
+
 func (u *ThanosBucketStores) getStoreError(userID string) error {
 	u.storesErrorsMu.RLock()
 	defer u.storesErrorsMu.RUnlock()
@@ -517,6 +552,7 @@ func (u *ThanosBucketStores) closeEmptyBucketStore(userID string) error {
 	}
 
 	delete(u.stores, userID)
+	delete(u.blocksTrackers, userID)
 	unlockInDefer = false
 	u.storesMu.Unlock()
 
@@ -597,6 +633,10 @@ func (u *ThanosBucketStores) getOrCreateStore(userID string) (*store.BucketStore
 		filters = append(filters, NewIgnoreNonQueryableBlocksFilter(userLogger, u.cfg.BucketStore.IgnoreBlocksWithin))
 	}
 
+	// The blocks tracker MUST be the last filter in order to track the blocks loaded by the store.
+	tracker := newUserBlocksTracker()
+	filters = append(filters, tracker)
+
 	// Instantiate a different blocks metadata fetcher based on whether bucket index is enabled or not.
 	var fetcher block.MetadataFetcher
 	if u.cfg.BucketStore.BucketIndex.Enabled {
@@ -653,7 +693,7 @@ func (u *ThanosBucketStores) getOrCreateStore(userID string) (*store.BucketStore
 			return util_log.HeadersFromContext(ctx, logger)
 		}),
 		store.WithRegistry(bucketStoreReg),
-		store.WithIndexCache(u.indexCache),
+		store.WithIndexCache(tracker.indexCache(u.indexCache)),
 		store.WithQueryGate(u.queryGate),
 		store.WithChunkPool(u.chunksPool),
 		store.WithSeriesBatchSize(u.cfg.BucketStore.SeriesBatchSize),
@@ -678,12 +718,17 @@ func (u *ThanosBucketStores) getOrCreateStore(userID string) (*store.BucketStore
 		store.WithBlockLifecycleCallback(&shardingBlockLifecycleCallbackAdapter{
 			userID:   userID,
 			strategy: u.shardingStrategy,
+			tracker:  tracker,
 			logger:   userLogger,
 		}),
 	}
 	if u.logLevel.String() == "debug" {
 		bucketStoreOpts = append(bucketStoreOpts, store.WithDebugLogging())
 	}
+	if s, ok := u.shardingStrategy.(*TimeBasedShardingStrategy); ok {
+		// The index-header of the old blocks is only downloaded once required by a query.
+		bucketStoreOpts = append(bucketStoreOpts, store.WithIndexHeaderLazyDownloadStrategy(s.lazyDownloadIndexHeader))
+	}
 
 	if u.cfg.BucketStore.TokenBucketBytesLimiter.Mode != string(tsdb.TokenBucketBytesLimiterDisabled) {
 		u.userTokenBucketsMu.Lock()
@@ -712,6 +757,7 @@ func (u *ThanosBucketStores) getOrCreateStore(userID string) (*store.BucketStore
 	}
 
 	u.stores[userID] = bs
+	u.blocksTrackers[userID] = tracker
 	u.metaFetcherMetrics.AddUserRegistry(userID, fetcherReg)
 	u.bucketStoreMetrics.AddUserRegistry(userID, bucketStoreReg)
 
@@ -399,6 +399,22 @@ func TestBucketStores_SyncBlocks(t *testing.T) {
 
 	thanosStores := stores.(*ThanosBucketStores)
 	assert.Greater(t, testutil.ToFloat64(thanosStores.syncLastSuccess), float64(0))
+
+	// Both blocks should be reported as loaded, and only the queried one as accessed.
+	inspected := thanosStores.InspectBlocks(userID)
+	require.Len(t, inspected, 1)
+	assert.Equal(t, userID, inspected[0].UserID)
+	require.Len(t, inspected[0].Blocks, 2)
+	for _, b := range inspected[0].Blocks {
+		assert.True(t, b.Loaded)
+		assert.Equal(t, IndexHeaderStateLoaded, b.IndexHeader)
+		assert.Positive(t, b.DiskBytes)
+	}
+	assert.True(t, inspected[0].Blocks[0].LastAccess.IsZero())
+	assert.False(t, inspected[0].Blocks[1].LastAccess.IsZero())
+	assert.Positive(t, inspected[0].IndexCache.PostingsRequests)
+
+	assert.Empty(t, thanosStores.InspectBlocks("user-2"))
 }
 
 func TestBucketStores_syncUsersBlocks(t *testing.T) {
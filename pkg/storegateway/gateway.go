@@ -53,11 +53,13 @@ const (
 )
 
 var (
-	supportedShardingStrategies = []string{util.ShardingStrategyDefault, util.ShardingStrategyShuffle}
+	supportedShardingStrategies = []string{util.ShardingStrategyDefault, util.ShardingStrategyShuffle, util.ShardingStrategyTime}
 
 	// Validation errors.
 	errInvalidShardingStrategy = errors.New("invalid sharding strategy")
 	errInvalidTenantShardSize  = errors.New("invalid tenant shard size, the value must be greater than 0")
+
+	errQueriedMetricsTrackingDisabled = errors.New("queried metrics tracking is disabled, it requires -store-gateway.queried-metrics-tracking-enabled")
 )
 
 // Config holds the store gateway config.
@@ -66,11 +68,16 @@ type Config struct {
 	ShardingRing     RingConfig `yaml:"sharding_ring" doc:"description=The hash ring configuration. This option is required only if blocks sharding is enabled."`
 	ShardingStrategy string     `yaml:"sharding_strategy"`
 
+	TimeBasedSharding TimeBasedShardingConfig `yaml:"time_based_sharding"`
+
 	EnabledTenants  flagext.StringSliceCSV `yaml:"enabled_tenants"`
 	DisabledTenants flagext.StringSliceCSV `yaml:"disabled_tenants"`
 
 	QueryProtection configs.QueryProtection `yaml:"query_protection"`
 
+	QueriedMetricsTrackingEnabled    bool `yaml:"queried_metrics_tracking_enabled"`
+	QueriedMetricsTrackingMaxMetrics int  `yaml:"queried_metrics_tracking_max_metrics"`
+
 	// Hedged Request
 	HedgedRequest bucket.HedgedRequestConfig `yaml:"hedged_request"`
 }
@@ -78,11 +85,14 @@ type Config struct {
 // RegisterFlags registers the Config flags.
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.ShardingRing.RegisterFlags(f)
+	cfg.TimeBasedSharding.RegisterFlags(f)
 
 	f.BoolVar(&cfg.ShardingEnabled, "store-gateway.sharding-enabled", false, "Shard blocks across multiple store gateway instances."+sharedOptionWithQuerier)
 	f.StringVar(&cfg.ShardingStrategy, "store-gateway.sharding-strategy", util.ShardingStrategyDefault, fmt.Sprintf("The sharding strategy to use. Supported values are: %s.", strings.Join(supportedShardingStrategies, ", ")))
 	f.Var(&cfg.EnabledTenants, "store-gateway.enabled-tenants", "Comma separated list of tenants whose store metrics this storegateway can process. If specified, only these tenants will be handled by storegateway, otherwise this storegateway will be enabled for all the tenants in the store-gateway cluster.")
 	f.Var(&cfg.DisabledTenants, "store-gateway.disabled-tenants", "Comma separated list of tenants whose store metrics this storegateway cannot process. If specified, a storegateway that would normally pick the specified tenant(s) for processing will ignore them instead.")
+	f.BoolVar(&cfg.QueriedMetricsTrackingEnabled, "store-gateway.queried-metrics-tracking-enabled", false, "[Experimental] Keep track of the last time each metric name has been queried for each tenant. The queried metrics are used by the unused metrics report.")
+	f.IntVar(&cfg.QueriedMetricsTrackingMaxMetrics, "store-gateway.queried-metrics-tracking-max-metrics", 100000, "The maximum number of queried metric names tracked per tenant. Once reached, the least recently queried metric names are evicted. 0 to disable the limit.")
 	cfg.HedgedRequest.RegisterFlagsWithPrefix(f, "store-gateway.")
 	cfg.QueryProtection.RegisterFlagsWithPrefix(f, "store-gateway.")
 }
@@ -97,6 +107,16 @@ func (cfg *Config) Validate(limits validation.Limits, monitoredResources flagext
 		if cfg.ShardingStrategy == util.ShardingStrategyShuffle && limits.StoreGatewayTenantShardSize <= 0 {
 			return errInvalidTenantShardSize
 		}
+
+		if cfg.ShardingStrategy == util.ShardingStrategyTime {
+			if err := cfg.TimeBasedSharding.Validate(cfg.ShardingRing.ReplicationFactor); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := cfg.ShardingRing.TokensRebalance.Validate(); err != nil {
+		return err
 	}
 
 	if err := cfg.HedgedRequest.Validate(); err != nil {
@@ -131,6 +151,9 @@ type StoreGateway struct {
 
 	resourceBasedLimiter *util_limiter.ResourceBasedLimiter
 
+	// Metric names queried for each tenant. Nil if tracking is disabled.
+	queriedMetrics *queriedMetricsTrackers
+
 	bucketSync *prometheus.CounterVec
 }
 
@@ -171,6 +194,10 @@ func newStoreGateway(gatewayCfg Config, storageCfg cortex_tsdb.BlocksStorageConf
 	}
 	allowedTenants := users.NewAllowedTenants(gatewayCfg.EnabledTenants, gatewayCfg.DisabledTenants)
 
+	if gatewayCfg.QueriedMetricsTrackingEnabled {
+		g.queriedMetrics = newQueriedMetricsTrackers(gatewayCfg.QueriedMetricsTrackingMaxMetrics)
+	}
+
 	// Init metrics.
 	g.bucketSync.WithLabelValues(syncReasonInitial)
 	g.bucketSync.WithLabelValues(syncReasonPeriodic)
@@ -231,6 +258,8 @@ func newStoreGateway(gatewayCfg Config, storageCfg cortex_tsdb.BlocksStorageConf
 			shardingStrategy = NewDefaultShardingStrategy(g.ring, lifecyclerCfg.Addr, logger, allowedTenants)
 		case util.ShardingStrategyShuffle:
 			shardingStrategy = NewShuffleShardingStrategy(g.ring, lifecyclerCfg.ID, lifecyclerCfg.Addr, limits, logger, allowedTenants, g.gatewayCfg.ShardingRing.ZoneStableShuffleSharding)
+		case util.ShardingStrategyTime:
+			shardingStrategy = NewTimeBasedShardingStrategy(g.ring, lifecyclerCfg.Addr, gatewayCfg.TimeBasedSharding, logger, allowedTenants)
 		default:
 			return nil, errInvalidShardingStrategy
 		}
@@ -412,7 +441,20 @@ func (g *StoreGateway) Series(req *storepb.SeriesRequest, srv storegatewaypb.Sto
 	if err := g.checkResourceUtilization(); err != nil {
 		return err
 	}
-	return g.stores.Series(req, srv)
+
+	if g.queriedMetrics == nil {
+		return g.stores.Series(req, srv)
+	}
+
+	trackingSrv := newQueriedMetricsSeriesServer(srv)
+	if err := g.stores.Series(req, trackingSrv); err != nil {
+		return err
+	}
+
+	if userID := getUserIDFromGRPCContext(srv.Context()); userID != "" {
+		g.queriedMetrics.get(userID).Track(trackingSrv.names, time.Now())
+	}
+	return nil
 }
 
 // LabelNames implements the Storegateway proto service.
@@ -431,6 +473,20 @@ func (g *StoreGateway) LabelValues(ctx context.Context, req *storepb.LabelValues
 	return g.stores.LabelValues(ctx, req)
 }
 
+// QueriedMetrics implements the Storegateway proto service.
+func (g *StoreGateway) QueriedMetrics(ctx context.Context, _ *storegatewaypb.QueriedMetricsRequest) (*storegatewaypb.QueriedMetricsResponse, error) {
+	if g.queriedMetrics == nil {
+		return nil, errQueriedMetricsTrackingDisabled
+	}
+
+	userID := getUserIDFromGRPCContext(ctx)
+	if userID == "" {
+		return nil, fmt.Errorf("no userID")
+	}
+
+	return g.queriedMetrics.response(userID), nil
+}
+
 func (g *StoreGateway) checkResourceUtilization() error {
 	if g.resourceBasedLimiter == nil {
 		return nil
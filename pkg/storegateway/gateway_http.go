@@ -1,11 +1,13 @@
 package storegateway
 
 import (
+	"html/template"
 	"net/http"
-	"text/template"
+	"time"
 
 	"github.com/go-kit/log/level"
 
+	"github.com/cortexproject/cortex/pkg/util"
 	util_log "github.com/cortexproject/cortex/pkg/util/log"
 	"github.com/cortexproject/cortex/pkg/util/services"
 )
@@ -23,6 +25,65 @@ var (
 			<p>{{ .Message }}</p>
 		</body>
 	</html>`))
+
+	blocksPageTemplate = template.Must(template.New("blocks").Funcs(template.FuncMap{
+		"formatTime": func(t time.Time) string {
+			if t.IsZero() {
+				return "-"
+			}
+			return t.UTC().Format(time.RFC3339)
+		},
+		"formatMillis": func(ms int64) string {
+			return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+		},
+	}).Parse(`
+	<!DOCTYPE html>
+	<html>
+		<head>
+			<meta charset="UTF-8">
+			<title>Cortex Store Gateway Blocks</title>
+		</head>
+		<body>
+			<h1>Cortex Store Gateway Blocks</h1>
+			<p>Current time: {{ .Now }}</p>
+			{{ range .Tenants }}
+			<h2>Tenant: {{ .UserID }}</h2>
+			<p>
+				Postings cache hit ratio: {{ printf "%.2f" .IndexCache.PostingsHitRatio }} ({{ .IndexCache.PostingsHits }}/{{ .IndexCache.PostingsRequests }}),
+				expanded postings cache hit ratio: {{ printf "%.2f" .IndexCache.ExpandedPostingsHitRatio }} ({{ .IndexCache.ExpandedPostingsHits }}/{{ .IndexCache.ExpandedPostingsRequests }}),
+				series cache hit ratio: {{ printf "%.2f" .IndexCache.SeriesHitRatio }} ({{ .IndexCache.SeriesHits }}/{{ .IndexCache.SeriesRequests }})
+			</p>
+			<table border="1">
+				<thead>
+					<tr>
+						<th>Block ID</th>
+						<th>Min Time</th>
+						<th>Max Time</th>
+						<th>Loaded</th>
+						<th>Index Header</th>
+						<th>Last Access</th>
+						<th>Disk Bytes</th>
+						<th>Memory Bytes</th>
+					</tr>
+				</thead>
+				<tbody>
+					{{ range .Blocks }}
+					<tr>
+						<td>{{ .ID }}</td>
+						<td>{{ formatMillis .MinTime }}</td>
+						<td>{{ formatMillis .MaxTime }}</td>
+						<td>{{ .Loaded }}</td>
+						<td>{{ .IndexHeader }}</td>
+						<td>{{ formatTime .LastAccess }}</td>
+						<td align='right'>{{ .DiskBytes }}</td>
+						<td align='right'>{{ .MemoryBytes }}</td>
+					</tr>
+					{{ end }}
+				</tbody>
+			</table>
+			{{ end }}
+		</body>
+	</html>`))
 )
 
 func writeMessage(w http.ResponseWriter, message string) {
@@ -51,3 +112,26 @@ func (c *StoreGateway) RingHandler(w http.ResponseWriter, req *http.Request) {
 
 	c.ring.ServeHTTP(w, req)
 }
+
+// BlocksHandler shows the blocks owned by the store-gateway for each tenant, or for the
+// tenant in the "tenant" query parameter, along with their state and the index cache stats.
+func (c *StoreGateway) BlocksHandler(w http.ResponseWriter, req *http.Request) {
+	if c.State() != services.Running {
+		writeMessage(w, "Store gateway is not running yet.")
+		return
+	}
+
+	inspector, ok := c.stores.(BlocksInspector)
+	if !ok {
+		writeMessage(w, "Blocks inspection is not supported by the configured bucket store type.")
+		return
+	}
+
+	util.RenderHTTPResponse(w, struct {
+		Now     time.Time        `json:"now"`
+		Tenants []UserBlocksInfo `json:"tenants"`
+	}{
+		Now:     time.Now(),
+		Tenants: inspector.InspectBlocks(req.URL.Query().Get("tenant")),
+	}, blocksPageTemplate, req)
+}
@@ -0,0 +1,56 @@
+//go:build linux
+
+package storegateway
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readMappedFiles returns the resident bytes of the files memory-mapped by the process, by path.
+func readMappedFiles() (map[string]int64, error) {
+	f, err := os.Open("/proc/self/smaps")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseSMaps(bufio.NewScanner(f))
+}
+
+// parseSMaps parses the content of a /proc/[pid]/smaps file, made of a header line for each
+// mapping followed by its "Key: value" lines.
+func parseSMaps(scanner *bufio.Scanner) (map[string]int64, error) {
+	mapped := map[string]int64{}
+	path := ""
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		// The header line starts with the address range of the mapping, e.g.
+		// "7f1c5c000000-7f1c5c021000 r--s 00000000 08:01 1234 /path/to/file".
+		if !strings.HasSuffix(fields[0], ":") {
+			path = ""
+			if len(fields) >= 6 && strings.Contains(fields[0], "-") {
+				path = strings.Join(fields[5:], " ")
+			}
+			continue
+		}
+
+		if path == "" || fields[0] != "Rss:" || len(fields) < 2 {
+			continue
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		mapped[path] += kb * 1024
+	}
+
+	return mapped, scanner.Err()
+}
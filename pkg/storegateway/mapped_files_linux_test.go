@@ -0,0 +1,63 @@
+//go:build linux
+
+package storegateway
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSMaps(t *testing.T) {
+	t.Parallel()
+
+	smaps := `55d4c0e00000-55d4c0e21000 r--p 00000000 08:01 1234 /usr/bin/cortex
+Size:                132 kB
+Rss:                 128 kB
+7f1c5c000000-7f1c5c021000 r--s 00000000 08:01 5678 /data/tsdb-sync/user-1/01ARZ3NDEKTSV4RRFFQ69G5FAV/index-header
+Size:                132 kB
+Rss:                  12 kB
+Pss:                  12 kB
+7f1c5c100000-7f1c5c121000 rw-p 00000000 00:00 0
+Rss:                  64 kB
+7f1c5c200000-7f1c5c221000 r--s 00000000 08:01 5679 /data/tsdb sync/index-header
+Rss:                   4 kB
+`
+
+	mapped, err := parseSMaps(bufio.NewScanner(strings.NewReader(smaps)))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{
+		"/usr/bin/cortex": 128 * 1024,
+		"/data/tsdb-sync/user-1/01ARZ3NDEKTSV4RRFFQ69G5FAV/index-header": 12 * 1024,
+		"/data/tsdb sync/index-header":                                   4 * 1024,
+	}, mapped)
+}
+
+func TestReadMappedFiles(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "index-header")
+	require.NoError(t, os.WriteFile(path, make([]byte, os.Getpagesize()), 0o644))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, os.Getpagesize(), syscall.PROT_READ, syscall.MAP_SHARED)
+	require.NoError(t, err)
+
+	mapped, err := readMappedFiles()
+	require.NoError(t, err)
+	assert.Contains(t, mapped, path)
+
+	require.NoError(t, syscall.Munmap(data))
+	mapped, err = readMappedFiles()
+	require.NoError(t, err)
+	assert.NotContains(t, mapped, path)
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package storegateway
+
+import "errors"
+
+// readMappedFiles returns the resident bytes of the files memory-mapped by the process, by path.
+func readMappedFiles() (map[string]int64, error) {
+	return nil, errors.New("reading the memory-mapped files is only supported on Linux")
+}
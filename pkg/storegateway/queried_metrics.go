@@ -0,0 +1,107 @@
+package storegateway
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/thanos-io/thanos/pkg/store/storepb"
+
+	"github.com/cortexproject/cortex/pkg/storegateway/storegatewaypb"
+	"github.com/cortexproject/cortex/pkg/util/queriedmetrics"
+)
+
+// queriedMetricsTrackers keeps track of the metric names queried for each tenant.
+type queriedMetricsTrackers struct {
+	maxMetrics int
+
+	// since is when the tracking started, which is the tracking start of all the tenants
+	// including the ones not queried yet.
+	since time.Time
+
+	mtx      sync.Mutex
+	trackers map[string]*queriedmetrics.Tracker
+}
+
+func newQueriedMetricsTrackers(maxMetrics int) *queriedMetricsTrackers {
+	return &queriedMetricsTrackers{
+		maxMetrics: maxMetrics,
+		since:      time.Now(),
+		trackers:   map[string]*queriedmetrics.Tracker{},
+	}
+}
+
+// get returns the tracker of the input tenant, creating it if it doesn't exist yet.
+func (q *queriedMetricsTrackers) get(userID string) *queriedmetrics.Tracker {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	t, ok := q.trackers[userID]
+	if !ok {
+		t = queriedmetrics.NewTracker(q.since, q.maxMetrics)
+		q.trackers[userID] = t
+	}
+	return t
+}
+
+// lookup returns the tracker of the input tenant, or nil if the tenant has never been queried.
+func (q *queriedMetricsTrackers) lookup(userID string) *queriedmetrics.Tracker {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	return q.trackers[userID]
+}
+
+// queriedMetricsSeriesServer wraps a Series() server and collects the metric names
+// of the series sent to the client.
+type queriedMetricsSeriesServer struct {
+	storegatewaypb.StoreGateway_SeriesServer
+
+	names map[string]struct{}
+}
+
+func newQueriedMetricsSeriesServer(srv storegatewaypb.StoreGateway_SeriesServer) *queriedMetricsSeriesServer {
+	return &queriedMetricsSeriesServer{
+		StoreGateway_SeriesServer: srv,
+		names:                     map[string]struct{}{},
+	}
+}
+
+func (s *queriedMetricsSeriesServer) Send(resp *storepb.SeriesResponse) error {
+	if series := resp.GetSeries(); series != nil {
+		for _, l := range series.Labels {
+			if l.Name != labels.MetricName {
+				continue
+			}
+
+			// The label value may reference the memory of the response, which can be
+			// reused once sent, so we copy it.
+			if _, ok := s.names[l.Value]; !ok {
+				s.names[strings.Clone(l.Value)] = struct{}{}
+			}
+			break
+		}
+	}
+	return s.StoreGateway_SeriesServer.Send(resp)
+}
+
+// response returns the metrics queried by the input tenant. A tenant not queried yet has no queried
+// metrics since the tracking started, which must not be mistaken for a complete history.
+func (q *queriedMetricsTrackers) response(userID string) *storegatewaypb.QueriedMetricsResponse {
+	resp := &storegatewaypb.QueriedMetricsResponse{TrackedSinceTimestampMs: q.since.UnixMilli()}
+
+	t := q.lookup(userID)
+	if t == nil {
+		return resp
+	}
+
+	resp.TrackedSinceTimestampMs = t.Since().UnixMilli()
+	for name, last := range t.LastQueried() {
+		resp.Metrics = append(resp.Metrics, &storegatewaypb.QueriedMetric{
+			MetricName:             name,
+			LastQueriedTimestampMs: last.UnixMilli(),
+		})
+	}
+	return resp
+}
@@ -0,0 +1,98 @@
+package storegateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/thanos/pkg/store/labelpb"
+	"github.com/thanos-io/thanos/pkg/store/storepb"
+
+	"github.com/cortexproject/cortex/pkg/storegateway/storegatewaypb"
+)
+
+func TestQueriedMetricsSeriesServer(t *testing.T) {
+	t.Parallel()
+
+	inner := newBucketStoreSeriesServer(context.Background())
+	srv := newQueriedMetricsSeriesServer(inner)
+
+	for _, lbls := range [][]labelpb.ZLabel{
+		{{Name: "__name__", Value: "metric_1"}, {Name: "job", Value: "a"}},
+		{{Name: "__name__", Value: "metric_1"}, {Name: "job", Value: "b"}},
+		{{Name: "__name__", Value: "metric_2"}},
+		{{Name: "job", Value: "c"}},
+	} {
+		require.NoError(t, srv.Send(storepb.NewSeriesResponse(&storepb.Series{Labels: lbls})))
+	}
+	require.NoError(t, srv.Send(storepb.NewWarnSeriesResponse(assert.AnError)))
+
+	// All responses should be forwarded to the wrapped server.
+	assert.Len(t, inner.SeriesSet, 4)
+	assert.Len(t, inner.Warnings, 1)
+
+	assert.Equal(t, map[string]struct{}{"metric_1": {}, "metric_2": {}}, srv.names)
+}
+
+func TestQueriedMetricsTrackers(t *testing.T) {
+	t.Parallel()
+
+	trackers := newQueriedMetricsTrackers(0)
+
+	// A tenant never queried has no tracker, but is tracked since the trackers have been created.
+	assert.Nil(t, trackers.lookup("user-1"))
+	assert.Equal(t, &storegatewaypb.QueriedMetricsResponse{
+		TrackedSinceTimestampMs: trackers.since.UnixMilli(),
+	}, trackers.response("user-1"))
+
+	tracker := trackers.get("user-1")
+	assert.Equal(t, trackers.since, tracker.Since())
+	assert.Same(t, tracker, trackers.get("user-1"))
+	assert.Same(t, tracker, trackers.lookup("user-1"))
+	assert.Nil(t, trackers.lookup("user-2"))
+
+	now := tracker.Since().Add(time.Minute)
+	tracker.Track(map[string]struct{}{"metric_1": {}}, now)
+
+	assert.Equal(t, &storegatewaypb.QueriedMetricsResponse{
+		Metrics:                 []*storegatewaypb.QueriedMetric{{MetricName: "metric_1", LastQueriedTimestampMs: now.UnixMilli()}},
+		TrackedSinceTimestampMs: tracker.Since().UnixMilli(),
+	}, trackers.response("user-1"))
+}
+
+func TestStoreGateway_QueriedMetrics(t *testing.T) {
+	t.Parallel()
+
+	ctx := setUserIDToGRPCContext(context.Background(), "user-1")
+
+	t.Run("should fail if tracking is disabled", func(t *testing.T) {
+		g := &StoreGateway{}
+
+		_, err := g.QueriedMetrics(ctx, &storegatewaypb.QueriedMetricsRequest{})
+		assert.Equal(t, errQueriedMetricsTrackingDisabled, err)
+	})
+
+	t.Run("should return the metrics queried by the tenant", func(t *testing.T) {
+		g := &StoreGateway{queriedMetrics: newQueriedMetricsTrackers(0)}
+		tracker := g.queriedMetrics.get("user-1")
+		tracker.Track(map[string]struct{}{"metric_1": {}}, tracker.Since())
+		g.queriedMetrics.get("user-2").Track(map[string]struct{}{"metric_2": {}}, tracker.Since())
+
+		resp, err := g.QueriedMetrics(ctx, &storegatewaypb.QueriedMetricsRequest{})
+		require.NoError(t, err)
+		require.Len(t, resp.Metrics, 1)
+		assert.Equal(t, "metric_1", resp.Metrics[0].MetricName)
+		assert.Equal(t, tracker.Since().UnixMilli(), resp.TrackedSinceTimestampMs)
+	})
+
+	t.Run("should return when the tracking started if the tenant has not been queried", func(t *testing.T) {
+		g := &StoreGateway{queriedMetrics: newQueriedMetricsTrackers(0)}
+
+		resp, err := g.QueriedMetrics(ctx, &storegatewaypb.QueriedMetricsRequest{})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Metrics)
+		assert.Equal(t, g.queriedMetrics.since.UnixMilli(), resp.TrackedSinceTimestampMs)
+	})
+}
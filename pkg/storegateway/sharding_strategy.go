@@ -188,13 +188,35 @@ func (s *ShuffleShardingStrategy) OwnBlock(userID string, meta metadata.Meta) (b
 }
 
 func filterBlocksByRingSharding(r ring.ReadRing, instanceAddr string, metas map[ulid.ULID]*metadata.Meta, loaded map[ulid.ULID]struct{}, synced block.GaugeVec, logger log.Logger) {
+	filterBlocksByRingShardingFunc(instanceAddr, metas, loaded, synced, logger, func(*metadata.Meta) []BlockRing {
+		return []BlockRing{{Ring: r, ReplicationFactor: r.ReplicationFactor()}}
+	})
+}
+
+// filterBlocksByRingShardingFunc is like filterBlocksByRingSharding() but each block is sharded
+// across the rings and replicated by the replication factors returned by the input function,
+// and is kept if it is owned by the store-gateway in any of them.
+func filterBlocksByRingShardingFunc(instanceAddr string, metas map[ulid.ULID]*metadata.Meta, loaded map[ulid.ULID]struct{}, synced block.GaugeVec, logger log.Logger, blockRings func(*metadata.Meta) []BlockRing) {
 	bufDescs, bufHosts, bufZones := ring.MakeBuffersForGet()
 
-	for blockID := range metas {
+	for blockID, meta := range metas {
 		key := cortex_tsdb.HashBlockID(blockID)
+		rings := blockRings(meta)
 
 		// Check if the block is owned by the store-gateway
-		set, err := r.Get(key, BlocksOwnerSync, bufDescs, bufHosts, bufZones)
+		var (
+			owned bool
+			err   error
+		)
+		for _, br := range rings {
+			var set ring.ReplicationSet
+			if set, err = br.Ring.GetWithReplicationFactor(key, BlocksOwnerSync, br.ReplicationFactor, bufDescs, bufHosts, bufZones); err != nil {
+				break
+			}
+			if owned = set.Includes(instanceAddr); owned {
+				break
+			}
+		}
 
 		// If an error occurs while checking the ring, we keep the previously loaded blocks.
 		if err != nil {
@@ -212,19 +234,16 @@ func filterBlocksByRingSharding(r ring.ReadRing, instanceAddr string, metas map[
 		}
 
 		// Keep the block if it is owned by the store-gateway.
-		if set.Includes(instanceAddr) {
+		if owned {
 			continue
 		}
 
 		// The block is not owned by the store-gateway. However, if it's currently loaded
 		// we can safely unload it only once at least 1 authoritative owner is available
-		// for queries.
-		if _, ok := loaded[blockID]; ok {
-			// The ring Get() returns an error if there's no available instance.
-			if _, err := r.Get(key, BlocksOwnerRead, bufDescs, bufHosts, bufZones); err != nil {
-				// Keep the block.
-				continue
-			}
+		// for queries in each ring.
+		if _, ok := loaded[blockID]; ok && !authoritativeOwnersAvailable(key, rings, bufDescs, bufHosts, bufZones) {
+			// Keep the block.
+			continue
 		}
 
 		// The block is not owned by the store-gateway and there's at least 1 available
@@ -235,6 +254,18 @@ func filterBlocksByRingSharding(r ring.ReadRing, instanceAddr string, metas map[
 	}
 }
 
+// authoritativeOwnersAvailable returns whether at least 1 authoritative owner of the block is available for
+// queries in each of the rings.
+func authoritativeOwnersAvailable(key uint32, rings []BlockRing, bufDescs []ring.InstanceDesc, bufHosts []string, bufZones map[string]int) bool {
+	for _, br := range rings {
+		// The ring Get() returns an error if there's no available instance.
+		if _, err := br.Ring.GetWithReplicationFactor(key, BlocksOwnerRead, br.ReplicationFactor, bufDescs, bufHosts, bufZones); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
 // GetShuffleShardingSubring returns the subring to be used for a given user. This function
 // should be used both by store-gateway and querier in order to guarantee the same logic is used.
 func GetShuffleShardingSubring(ring *ring.Ring, userID string, limits ShardingLimits, zoneStableShuffleSharding bool) ring.ReadRing {
@@ -315,6 +346,7 @@ func (a *shardingBucketReaderAdapter) Iter(ctx context.Context, dir string, f fu
 type shardingBlockLifecycleCallbackAdapter struct {
 	userID   string
 	strategy ShardingStrategy
+	tracker  *userBlocksTracker
 	logger   log.Logger
 }
 
@@ -323,6 +355,9 @@ func (a *shardingBlockLifecycleCallbackAdapter) PreAdd(meta metadata.Meta) error
 	// If unable to check if block is owned or not because of ring error, mark it as owned
 	// and ignore the error.
 	if err != nil || own {
+		if a.tracker != nil {
+			a.tracker.add(meta.ULID)
+		}
 		return nil
 	}
 	level.Info(a.logger).Log("msg", "block not owned from pre check", "block", meta.ULID.String())
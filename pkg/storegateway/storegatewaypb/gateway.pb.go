@@ -11,7 +11,11 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	io "io"
 	math "math"
+	math_bits "math/bits"
+	reflect "reflect"
+	strings "strings"
 )
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -25,27 +29,302 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
+type QueriedMetricsRequest struct {
+}
+
+func (m *QueriedMetricsRequest) Reset()      { *m = QueriedMetricsRequest{} }
+func (*QueriedMetricsRequest) ProtoMessage() {}
+func (*QueriedMetricsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f1a937782ebbded5, []int{0}
+}
+func (m *QueriedMetricsRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *QueriedMetricsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_QueriedMetricsRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *QueriedMetricsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueriedMetricsRequest.Merge(m, src)
+}
+func (m *QueriedMetricsRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *QueriedMetricsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueriedMetricsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueriedMetricsRequest proto.InternalMessageInfo
+
+type QueriedMetricsResponse struct {
+	Metrics []*QueriedMetric `protobuf:"bytes,1,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	// The time since when the queried metrics have been tracked.
+	TrackedSinceTimestampMs int64 `protobuf:"varint,2,opt,name=tracked_since_timestamp_ms,json=trackedSinceTimestampMs,proto3" json:"tracked_since_timestamp_ms,omitempty"`
+}
+
+func (m *QueriedMetricsResponse) Reset()      { *m = QueriedMetricsResponse{} }
+func (*QueriedMetricsResponse) ProtoMessage() {}
+func (*QueriedMetricsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f1a937782ebbded5, []int{1}
+}
+func (m *QueriedMetricsResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *QueriedMetricsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_QueriedMetricsResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *QueriedMetricsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueriedMetricsResponse.Merge(m, src)
+}
+func (m *QueriedMetricsResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *QueriedMetricsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueriedMetricsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueriedMetricsResponse proto.InternalMessageInfo
+
+func (m *QueriedMetricsResponse) GetMetrics() []*QueriedMetric {
+	if m != nil {
+		return m.Metrics
+	}
+	return nil
+}
+
+func (m *QueriedMetricsResponse) GetTrackedSinceTimestampMs() int64 {
+	if m != nil {
+		return m.TrackedSinceTimestampMs
+	}
+	return 0
+}
+
+type QueriedMetric struct {
+	MetricName             string `protobuf:"bytes,1,opt,name=metric_name,json=metricName,proto3" json:"metric_name,omitempty"`
+	LastQueriedTimestampMs int64  `protobuf:"varint,2,opt,name=last_queried_timestamp_ms,json=lastQueriedTimestampMs,proto3" json:"last_queried_timestamp_ms,omitempty"`
+}
+
+func (m *QueriedMetric) Reset()      { *m = QueriedMetric{} }
+func (*QueriedMetric) ProtoMessage() {}
+func (*QueriedMetric) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f1a937782ebbded5, []int{2}
+}
+func (m *QueriedMetric) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *QueriedMetric) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_QueriedMetric.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *QueriedMetric) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueriedMetric.Merge(m, src)
+}
+func (m *QueriedMetric) XXX_Size() int {
+	return m.Size()
+}
+func (m *QueriedMetric) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueriedMetric.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueriedMetric proto.InternalMessageInfo
+
+func (m *QueriedMetric) GetMetricName() string {
+	if m != nil {
+		return m.MetricName
+	}
+	return ""
+}
+
+func (m *QueriedMetric) GetLastQueriedTimestampMs() int64 {
+	if m != nil {
+		return m.LastQueriedTimestampMs
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*QueriedMetricsRequest)(nil), "gatewaypb.QueriedMetricsRequest")
+	proto.RegisterType((*QueriedMetricsResponse)(nil), "gatewaypb.QueriedMetricsResponse")
+	proto.RegisterType((*QueriedMetric)(nil), "gatewaypb.QueriedMetric")
+}
+
 func init() { proto.RegisterFile("gateway.proto", fileDescriptor_f1a937782ebbded5) }
 
 var fileDescriptor_f1a937782ebbded5 = []byte{
-	// 257 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x4d, 0x4f, 0x2c, 0x49,
-	0x2d, 0x4f, 0xac, 0xd4, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0x84, 0x72, 0x0b, 0x92, 0xa4,
-	0xcc, 0xd3, 0x33, 0x4b, 0x32, 0x4a, 0x93, 0xf4, 0x92, 0xf3, 0x73, 0xf5, 0x4b, 0x32, 0x12, 0xf3,
-	0xf2, 0x8b, 0x75, 0x33, 0xf3, 0xa1, 0x2c, 0xfd, 0x82, 0xec, 0x74, 0xfd, 0xe2, 0x92, 0xfc, 0xa2,
-	0x54, 0x08, 0x59, 0x90, 0xa4, 0x5f, 0x54, 0x90, 0x0c, 0x31, 0xc3, 0xe8, 0x1a, 0x23, 0x17, 0x4f,
-	0x30, 0x48, 0xd4, 0x1d, 0x62, 0x96, 0x90, 0x25, 0x17, 0x5b, 0x70, 0x6a, 0x51, 0x66, 0x6a, 0xb1,
-	0x90, 0xa8, 0x1e, 0x44, 0xbf, 0x1e, 0x84, 0x1f, 0x94, 0x5a, 0x58, 0x9a, 0x5a, 0x5c, 0x22, 0x25,
-	0x86, 0x2e, 0x5c, 0x5c, 0x90, 0x9f, 0x57, 0x9c, 0x6a, 0xc0, 0x28, 0xe4, 0xcc, 0xc5, 0xe5, 0x93,
-	0x98, 0x94, 0x9a, 0xe3, 0x97, 0x98, 0x9b, 0x5a, 0x2c, 0x24, 0x09, 0x53, 0x87, 0x10, 0x83, 0x19,
-	0x21, 0x85, 0x4d, 0x0a, 0x62, 0x8c, 0x90, 0x1b, 0x17, 0x37, 0x58, 0x34, 0x2c, 0x31, 0xa7, 0x34,
-	0xb5, 0x58, 0x08, 0x55, 0x29, 0x44, 0x10, 0x66, 0x8c, 0x34, 0x56, 0x39, 0x88, 0x39, 0x4e, 0x2e,
-	0x17, 0x1e, 0xca, 0x31, 0xdc, 0x78, 0x28, 0xc7, 0xf0, 0xe1, 0xa1, 0x1c, 0x63, 0xc3, 0x23, 0x39,
-	0xc6, 0x15, 0x8f, 0xe4, 0x18, 0x4f, 0x3c, 0x92, 0x63, 0xbc, 0xf0, 0x48, 0x8e, 0xf1, 0xc1, 0x23,
-	0x39, 0xc6, 0x17, 0x8f, 0xe4, 0x18, 0x3e, 0x3c, 0x92, 0x63, 0x9c, 0xf0, 0x58, 0x8e, 0xe1, 0xc2,
-	0x63, 0x39, 0x86, 0x1b, 0x8f, 0xe5, 0x18, 0xa2, 0xf8, 0xc0, 0x21, 0x04, 0x0f, 0xd7, 0x24, 0x36,
-	0x70, 0x28, 0x19, 0x03, 0x02, 0x00, 0x00, 0xff, 0xff, 0x1b, 0xec, 0xe6, 0x0a, 0x7a, 0x01, 0x00,
-	0x00,
+	// 412 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x92, 0x3f, 0x6f, 0xe2, 0x30,
+	0x00, 0xc5, 0x63, 0x90, 0x38, 0x61, 0x0e, 0x06, 0x4b, 0x40, 0xc8, 0x49, 0xbe, 0x1c, 0x13, 0xcb,
+	0x25, 0x27, 0x6e, 0x38, 0xa1, 0xdb, 0xda, 0xaa, 0x5d, 0x4a, 0xa5, 0x86, 0xb6, 0x43, 0x97, 0xc8,
+	0x09, 0x16, 0x44, 0x90, 0x3f, 0xc4, 0x8e, 0xaa, 0x6e, 0x5d, 0xbb, 0xf5, 0x63, 0x74, 0xe9, 0xf7,
+	0xe8, 0xc8, 0xc8, 0x58, 0xc2, 0xd2, 0x91, 0x8f, 0x50, 0x05, 0x27, 0xb4, 0x41, 0xb4, 0x4b, 0x14,
+	0xbf, 0xf7, 0xfc, 0xb3, 0xfd, 0x6c, 0x58, 0x1d, 0x11, 0x4e, 0x6f, 0xc8, 0xad, 0x16, 0x84, 0x3e,
+	0xf7, 0x51, 0x39, 0x1d, 0x06, 0x96, 0xf2, 0x6f, 0xe4, 0xf0, 0x71, 0x64, 0x69, 0xb6, 0xef, 0xea,
+	0x7c, 0x4c, 0x3c, 0x9f, 0xfd, 0x76, 0xfc, 0xf4, 0x4f, 0x0f, 0x26, 0x23, 0x9d, 0x71, 0x3f, 0xa4,
+	0xe2, 0x1b, 0x58, 0x7a, 0x18, 0xd8, 0x82, 0xd1, 0x6e, 0xc2, 0xfa, 0x79, 0x44, 0x43, 0x87, 0x0e,
+	0xfb, 0x94, 0x87, 0x8e, 0xcd, 0x0c, 0x3a, 0x8b, 0x28, 0xe3, 0xed, 0x7b, 0x00, 0x1b, 0xbb, 0x0e,
+	0x0b, 0x7c, 0x8f, 0x51, 0xd4, 0x85, 0xdf, 0x5c, 0x21, 0xc9, 0x40, 0x2d, 0x76, 0x2a, 0x5d, 0x59,
+	0xdb, 0xee, 0x44, 0xcb, 0xcd, 0x31, 0xb2, 0x20, 0xfa, 0x0f, 0x15, 0x1e, 0x12, 0x7b, 0x42, 0x87,
+	0x26, 0x73, 0x3c, 0x9b, 0x9a, 0xdc, 0x71, 0x29, 0xe3, 0xc4, 0x0d, 0x4c, 0x97, 0xc9, 0x05, 0x15,
+	0x74, 0x8a, 0x46, 0x33, 0x4d, 0x0c, 0x92, 0xc0, 0x45, 0xe6, 0xf7, 0x59, 0x7b, 0x02, 0xab, 0x39,
+	0x2c, 0xfa, 0x09, 0x2b, 0x02, 0x6c, 0x7a, 0xc4, 0xa5, 0x32, 0x50, 0x41, 0xa7, 0x6c, 0x40, 0x21,
+	0x9d, 0x11, 0x97, 0xa2, 0x1e, 0x6c, 0x4d, 0x09, 0xe3, 0xe6, 0x4c, 0x4c, 0xdb, 0xb7, 0x5a, 0x23,
+	0x09, 0xa4, 0xd8, 0x0f, 0x8b, 0x75, 0x9f, 0x0a, 0xf0, 0xfb, 0x20, 0xe9, 0xe9, 0x44, 0x9c, 0x09,
+	0xf5, 0x60, 0x69, 0x90, 0xa4, 0x18, 0xaa, 0x6b, 0xa2, 0x51, 0x4d, 0x8c, 0xd3, 0xaa, 0x94, 0xc6,
+	0xae, 0x2c, 0x7a, 0xfa, 0x03, 0xd0, 0x21, 0x84, 0xa7, 0xc4, 0xa2, 0xd3, 0x64, 0x4f, 0x0c, 0xb5,
+	0xb2, 0xdc, 0xbb, 0x96, 0x21, 0x94, 0x7d, 0x56, 0x5a, 0xf7, 0x31, 0xac, 0x6c, 0xd4, 0x2b, 0x32,
+	0x8d, 0x28, 0x43, 0xf9, 0xa8, 0x10, 0x33, 0xcc, 0x8f, 0xbd, 0x5e, 0xca, 0xb9, 0x84, 0xb5, 0xfc,
+	0x85, 0x22, 0xf5, 0xb3, 0x7b, 0xdb, 0x02, 0x7f, 0x7d, 0x91, 0x10, 0xd8, 0x83, 0xa3, 0xf9, 0x12,
+	0x4b, 0x8b, 0x25, 0x96, 0xd6, 0x4b, 0x0c, 0xee, 0x62, 0x0c, 0x1e, 0x63, 0x0c, 0x9e, 0x63, 0x0c,
+	0xe6, 0x31, 0x06, 0x2f, 0x31, 0x06, 0xaf, 0x31, 0x96, 0xd6, 0x31, 0x06, 0x0f, 0x2b, 0x2c, 0xcd,
+	0x57, 0x58, 0x5a, 0xac, 0xb0, 0x74, 0x5d, 0xdb, 0x3c, 0xc5, 0x2d, 0xdc, 0x2a, 0x6d, 0x9e, 0xe3,
+	0xdf, 0xb7, 0x01, 0x00, 0x5a, 0x3e, 0x99, 0xe0, 0xe3, 0x02, 0x00, 0x00,
+}
+
+func (this *QueriedMetricsRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*QueriedMetricsRequest)
+	if !ok {
+		that2, ok := that.(QueriedMetricsRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	return true
+}
+func (this *QueriedMetricsResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*QueriedMetricsResponse)
+	if !ok {
+		that2, ok := that.(QueriedMetricsResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Metrics) != len(that1.Metrics) {
+		return false
+	}
+	for i := range this.Metrics {
+		if !this.Metrics[i].Equal(that1.Metrics[i]) {
+			return false
+		}
+	}
+	if this.TrackedSinceTimestampMs != that1.TrackedSinceTimestampMs {
+		return false
+	}
+	return true
+}
+func (this *QueriedMetric) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*QueriedMetric)
+	if !ok {
+		that2, ok := that.(QueriedMetric)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.MetricName != that1.MetricName {
+		return false
+	}
+	if this.LastQueriedTimestampMs != that1.LastQueriedTimestampMs {
+		return false
+	}
+	return true
+}
+func (this *QueriedMetricsRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 4)
+	s = append(s, "&storegatewaypb.QueriedMetricsRequest{")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *QueriedMetricsResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&storegatewaypb.QueriedMetricsResponse{")
+	if this.Metrics != nil {
+		s = append(s, "Metrics: "+fmt.Sprintf("%#v", this.Metrics)+",\n")
+	}
+	s = append(s, "TrackedSinceTimestampMs: "+fmt.Sprintf("%#v", this.TrackedSinceTimestampMs)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *QueriedMetric) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&storegatewaypb.QueriedMetric{")
+	s = append(s, "MetricName: "+fmt.Sprintf("%#v", this.MetricName)+",\n")
+	s = append(s, "LastQueriedTimestampMs: "+fmt.Sprintf("%#v", this.LastQueriedTimestampMs)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func valueToGoStringGateway(v interface{}, typ string) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("func(v %v) *%v { return &v } ( %#v )", typ, typ, pv)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -72,6 +351,8 @@ type StoreGatewayClient interface {
 	LabelNames(ctx context.Context, in *storepb.LabelNamesRequest, opts ...grpc.CallOption) (*storepb.LabelNamesResponse, error)
 	// LabelValues returns all label values for given label name.
 	LabelValues(ctx context.Context, in *storepb.LabelValuesRequest, opts ...grpc.CallOption) (*storepb.LabelValuesResponse, error)
+	// QueriedMetrics returns the metric names queried for the tenant, along with the last time they have been queried.
+	QueriedMetrics(ctx context.Context, in *QueriedMetricsRequest, opts ...grpc.CallOption) (*QueriedMetricsResponse, error)
 }
 
 type storeGatewayClient struct {
@@ -132,6 +413,15 @@ func (c *storeGatewayClient) LabelValues(ctx context.Context, in *storepb.LabelV
 	return out, nil
 }
 
+func (c *storeGatewayClient) QueriedMetrics(ctx context.Context, in *QueriedMetricsRequest, opts ...grpc.CallOption) (*QueriedMetricsResponse, error) {
+	out := new(QueriedMetricsResponse)
+	err := c.cc.Invoke(ctx, "/gatewaypb.StoreGateway/QueriedMetrics", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // StoreGatewayServer is the server API for StoreGateway service.
 type StoreGatewayServer interface {
 	// Series streams each Series for given label matchers and time range.
@@ -146,6 +436,8 @@ type StoreGatewayServer interface {
 	LabelNames(context.Context, *storepb.LabelNamesRequest) (*storepb.LabelNamesResponse, error)
 	// LabelValues returns all label values for given label name.
 	LabelValues(context.Context, *storepb.LabelValuesRequest) (*storepb.LabelValuesResponse, error)
+	// QueriedMetrics returns the metric names queried for the tenant, along with the last time they have been queried.
+	QueriedMetrics(context.Context, *QueriedMetricsRequest) (*QueriedMetricsResponse, error)
 }
 
 // UnimplementedStoreGatewayServer can be embedded to have forward compatible implementations.
@@ -161,6 +453,9 @@ func (*UnimplementedStoreGatewayServer) LabelNames(ctx context.Context, req *sto
 func (*UnimplementedStoreGatewayServer) LabelValues(ctx context.Context, req *storepb.LabelValuesRequest) (*storepb.LabelValuesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method LabelValues not implemented")
 }
+func (*UnimplementedStoreGatewayServer) QueriedMetrics(ctx context.Context, req *QueriedMetricsRequest) (*QueriedMetricsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueriedMetrics not implemented")
+}
 
 func RegisterStoreGatewayServer(s *grpc.Server, srv StoreGatewayServer) {
 	s.RegisterService(&_StoreGateway_serviceDesc, srv)
@@ -223,6 +518,24 @@ func _StoreGateway_LabelValues_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StoreGateway_QueriedMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueriedMetricsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StoreGatewayServer).QueriedMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gatewaypb.StoreGateway/QueriedMetrics",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StoreGatewayServer).QueriedMetrics(ctx, req.(*QueriedMetricsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _StoreGateway_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "gatewaypb.StoreGateway",
 	HandlerType: (*StoreGatewayServer)(nil),
@@ -235,6 +548,10 @@ var _StoreGateway_serviceDesc = grpc.ServiceDesc{
 			MethodName: "LabelValues",
 			Handler:    _StoreGateway_LabelValues_Handler,
 		},
+		{
+			MethodName: "QueriedMetrics",
+			Handler:    _StoreGateway_QueriedMetrics_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -245,3 +562,581 @@ var _StoreGateway_serviceDesc = grpc.ServiceDesc{
 	},
 	Metadata: "gateway.proto",
 }
+
+func (m *QueriedMetricsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *QueriedMetricsRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *QueriedMetricsRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	return len(dAtA) - i, nil
+}
+
+func (m *QueriedMetricsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *QueriedMetricsResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *QueriedMetricsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.TrackedSinceTimestampMs != 0 {
+		i = encodeVarintGateway(dAtA, i, uint64(m.TrackedSinceTimestampMs))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Metrics) > 0 {
+		for iNdEx := len(m.Metrics) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Metrics[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintGateway(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *QueriedMetric) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *QueriedMetric) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *QueriedMetric) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.LastQueriedTimestampMs != 0 {
+		i = encodeVarintGateway(dAtA, i, uint64(m.LastQueriedTimestampMs))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.MetricName) > 0 {
+		i -= len(m.MetricName)
+		copy(dAtA[i:], m.MetricName)
+		i = encodeVarintGateway(dAtA, i, uint64(len(m.MetricName)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintGateway(dAtA []byte, offset int, v uint64) int {
+	offset -= sovGateway(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *QueriedMetricsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	return n
+}
+
+func (m *QueriedMetricsResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Metrics) > 0 {
+		for _, e := range m.Metrics {
+			l = e.Size()
+			n += 1 + l + sovGateway(uint64(l))
+		}
+	}
+	if m.TrackedSinceTimestampMs != 0 {
+		n += 1 + sovGateway(uint64(m.TrackedSinceTimestampMs))
+	}
+	return n
+}
+
+func (m *QueriedMetric) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.MetricName)
+	if l > 0 {
+		n += 1 + l + sovGateway(uint64(l))
+	}
+	if m.LastQueriedTimestampMs != 0 {
+		n += 1 + sovGateway(uint64(m.LastQueriedTimestampMs))
+	}
+	return n
+}
+
+func sovGateway(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozGateway(x uint64) (n int) {
+	return sovGateway(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *QueriedMetricsRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&QueriedMetricsRequest{`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *QueriedMetricsResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForMetrics := "[]*QueriedMetric{"
+	for _, f := range this.Metrics {
+		repeatedStringForMetrics += strings.Replace(f.String(), "QueriedMetric", "QueriedMetric", 1) + ","
+	}
+	repeatedStringForMetrics += "}"
+	s := strings.Join([]string{`&QueriedMetricsResponse{`,
+		`Metrics:` + repeatedStringForMetrics + `,`,
+		`TrackedSinceTimestampMs:` + fmt.Sprintf("%v", this.TrackedSinceTimestampMs) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *QueriedMetric) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&QueriedMetric{`,
+		`MetricName:` + fmt.Sprintf("%v", this.MetricName) + `,`,
+		`LastQueriedTimestampMs:` + fmt.Sprintf("%v", this.LastQueriedTimestampMs) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func valueToStringGateway(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("*%v", pv)
+}
+func (m *QueriedMetricsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGateway
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: QueriedMetricsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: QueriedMetricsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGateway(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *QueriedMetricsResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGateway
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: QueriedMetricsResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: QueriedMetricsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metrics", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGateway
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Metrics = append(m.Metrics, &QueriedMetric{})
+			if err := m.Metrics[len(m.Metrics)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TrackedSinceTimestampMs", wireType)
+			}
+			m.TrackedSinceTimestampMs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TrackedSinceTimestampMs |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGateway(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *QueriedMetric) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGateway
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: QueriedMetric: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: QueriedMetric: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MetricName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGateway
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.MetricName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LastQueriedTimestampMs", wireType)
+			}
+			m.LastQueriedTimestampMs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.LastQueriedTimestampMs |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGateway(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthGateway
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipGateway(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowGateway
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+			return iNdEx, nil
+		case 1:
+			iNdEx += 8
+			return iNdEx, nil
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowGateway
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthGateway
+			}
+			iNdEx += length
+			if iNdEx < 0 {
+				return 0, ErrInvalidLengthGateway
+			}
+			return iNdEx, nil
+		case 3:
+			for {
+				var innerWire uint64
+				var start int = iNdEx
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return 0, ErrIntOverflowGateway
+					}
+					if iNdEx >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					innerWire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				innerWireType := int(innerWire & 0x7)
+				if innerWireType == 4 {
+					break
+				}
+				next, err := skipGateway(dAtA[start:])
+				if err != nil {
+					return 0, err
+				}
+				iNdEx = start + next
+				if iNdEx < 0 {
+					return 0, ErrInvalidLengthGateway
+				}
+			}
+			return iNdEx, nil
+		case 4:
+			return iNdEx, nil
+		case 5:
+			iNdEx += 4
+			return iNdEx, nil
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+	}
+	panic("unreachable")
+}
+
+var (
+	ErrInvalidLengthGateway = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowGateway   = fmt.Errorf("proto: integer overflow")
+)
@@ -0,0 +1,162 @@
+package storegateway
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/oklog/ulid/v2"
+	"github.com/pkg/errors"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+
+	"github.com/cortexproject/cortex/pkg/ring"
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/util/users"
+)
+
+var (
+	errInvalidRecentBlocksPeriod            = errors.New("the recent blocks period must be greater than 0 when the time-based sharding strategy is used")
+	errInvalidRecentBlocksReplicationFactor = errors.New("the recent blocks replication factor must be greater than or equal to the store-gateway replication factor")
+	errInvalidOldBlocksShardSize            = errors.New("the old blocks shard size must be 0 or greater than or equal to the store-gateway replication factor")
+	errInvalidTransitionGracePeriod         = errors.New("the transition grace period must be greater than or equal to 0")
+)
+
+// oldBlocksShardIdentifier is the identifier of the shuffle shard of the store-gateways loading the old blocks.
+const oldBlocksShardIdentifier = "time-based-sharding-old-blocks"
+
+// TimeBasedShardingConfig configures the time-based sharding strategy.
+type TimeBasedShardingConfig struct {
+	RecentBlocksPeriod            time.Duration `yaml:"recent_blocks_period"`
+	RecentBlocksReplicationFactor int           `yaml:"recent_blocks_replication_factor"`
+	OldBlocksShardSize            int           `yaml:"old_blocks_shard_size"`
+	TransitionGracePeriod         time.Duration `yaml:"transition_grace_period"`
+}
+
+// RegisterFlags registers the TimeBasedShardingConfig flags.
+func (cfg *TimeBasedShardingConfig) RegisterFlags(f *flag.FlagSet) {
+	f.DurationVar(&cfg.RecentBlocksPeriod, "store-gateway.time-based-sharding.recent-blocks-period", 72*time.Hour, "EXPERIMENTAL: Blocks whose max time is within this period are considered recent by the time-based sharding strategy."+sharedOptionWithQuerier)
+	f.IntVar(&cfg.RecentBlocksReplicationFactor, "store-gateway.time-based-sharding.recent-blocks-replication-factor", 3, "EXPERIMENTAL: The replication factor used for recent blocks by the time-based sharding strategy. Older blocks are replicated by the store-gateway ring replication factor, which can be lowered accordingly."+sharedOptionWithQuerier)
+	f.IntVar(&cfg.OldBlocksShardSize, "store-gateway.time-based-sharding.old-blocks-shard-size", 0, "EXPERIMENTAL: The number of store-gateways dedicated to the old blocks by the time-based sharding strategy. The old blocks are only loaded by this set of store-gateways, selected from the ring with shuffle sharding, and their index-header is lazily downloaded on the first query. 0 to spread the old blocks across all the store-gateways."+sharedOptionWithQuerier)
+	f.DurationVar(&cfg.TransitionGracePeriod, "store-gateway.time-based-sharding.transition-grace-period", 15*time.Minute, "EXPERIMENTAL: The period, before and after a block stops being recent, during which the block is loaded by both the store-gateways of the recent blocks and of the old blocks, and queried from both, so that it remains queryable while the store-gateways sync. It must be greater than or equal to the store-gateway sync interval (-blocks-storage.bucket-store.sync-interval)."+sharedOptionWithQuerier)
+}
+
+// Validate the TimeBasedShardingConfig.
+func (cfg *TimeBasedShardingConfig) Validate(replicationFactor int) error {
+	if cfg.RecentBlocksPeriod <= 0 {
+		return errInvalidRecentBlocksPeriod
+	}
+	if cfg.RecentBlocksReplicationFactor < replicationFactor {
+		return errInvalidRecentBlocksReplicationFactor
+	}
+	if cfg.OldBlocksShardSize != 0 && cfg.OldBlocksShardSize < replicationFactor {
+		return errInvalidOldBlocksShardSize
+	}
+	if cfg.TransitionGracePeriod < 0 {
+		return errInvalidTransitionGracePeriod
+	}
+	return nil
+}
+
+// isRecent returns whether a block with the given max time (in milliseconds) is recent.
+func (cfg TimeBasedShardingConfig) isRecent(blockMaxTime int64, now time.Time) bool {
+	return blockMaxTime >= now.Add(-cfg.RecentBlocksPeriod).UnixMilli()
+}
+
+// Ring returns the ring and the replication factor used to shard a block with the given max time (in milliseconds).
+// Recent blocks are sharded across the whole ring with the recent blocks replication factor, while old blocks are
+// sharded across the store-gateways dedicated to the old blocks with the ring replication factor.
+// This function should be used both by store-gateway and querier in order to guarantee the same logic is used.
+func (cfg TimeBasedShardingConfig) Ring(r ring.ReadRing, blockMaxTime int64, now time.Time) (ring.ReadRing, int) {
+	if cfg.isRecent(blockMaxTime, now) {
+		return r, max(cfg.RecentBlocksReplicationFactor, r.ReplicationFactor())
+	}
+	if cfg.OldBlocksShardSize > 0 {
+		return r.ShuffleShard(oldBlocksShardIdentifier, cfg.OldBlocksShardSize), r.ReplicationFactor()
+	}
+	return r, r.ReplicationFactor()
+}
+
+// BlockRing is a ring, and the replication factor, used to shard a block.
+type BlockRing struct {
+	Ring              ring.ReadRing
+	ReplicationFactor int
+}
+
+// Rings returns the rings used to shard a block with the given max time (in milliseconds). The store-gateways
+// and the queriers don't switch a block from the recent blocks ring to the old blocks ring at the same time, so
+// during the transition grace period around the time the block stops being recent, the block is sharded by
+// both rings. This function should be used both by store-gateway and querier in order to guarantee the same
+// logic is used.
+func (cfg TimeBasedShardingConfig) Rings(r ring.ReadRing, blockMaxTime int64, now time.Time) []BlockRing {
+	recentRing, recentReplicationFactor := cfg.Ring(r, blockMaxTime, now.Add(-cfg.TransitionGracePeriod))
+	rings := []BlockRing{{Ring: recentRing, ReplicationFactor: recentReplicationFactor}}
+
+	if cfg.isRecent(blockMaxTime, now.Add(-cfg.TransitionGracePeriod)) != cfg.isRecent(blockMaxTime, now.Add(cfg.TransitionGracePeriod)) {
+		oldRing, oldReplicationFactor := cfg.Ring(r, blockMaxTime, now.Add(cfg.TransitionGracePeriod))
+		rings = append(rings, BlockRing{Ring: oldRing, ReplicationFactor: oldReplicationFactor})
+	}
+	return rings
+}
+
+// TimeBasedShardingStrategy is a sharding strategy based on the hash ring formed by store-gateways,
+// where recent blocks are replicated across more store-gateway instances than older blocks, and older
+// blocks are lazily loaded by an optional dedicated set of store-gateway instances.
+// Not go-routine safe.
+type TimeBasedShardingStrategy struct {
+	r              *ring.Ring
+	instanceAddr   string
+	cfg            TimeBasedShardingConfig
+	logger         log.Logger
+	allowedTenants *users.AllowedTenants
+}
+
+// NewTimeBasedShardingStrategy creates TimeBasedShardingStrategy.
+func NewTimeBasedShardingStrategy(r *ring.Ring, instanceAddr string, cfg TimeBasedShardingConfig, logger log.Logger, allowedTenants *users.AllowedTenants) *TimeBasedShardingStrategy {
+	return &TimeBasedShardingStrategy{
+		r:            r,
+		instanceAddr: instanceAddr,
+		cfg:          cfg,
+		logger:       logger,
+
+		allowedTenants: allowedTenants,
+	}
+}
+
+// FilterUsers implements ShardingStrategy.
+func (s *TimeBasedShardingStrategy) FilterUsers(_ context.Context, userIDs []string) []string {
+	return filterDisallowedTenants(userIDs, s.logger, s.allowedTenants)
+}
+
+// FilterBlocks implements ShardingStrategy.
+func (s *TimeBasedShardingStrategy) FilterBlocks(_ context.Context, _ string, metas map[ulid.ULID]*metadata.Meta, loaded map[ulid.ULID]struct{}, synced block.GaugeVec) error {
+	now := time.Now()
+	filterBlocksByRingShardingFunc(s.instanceAddr, metas, loaded, synced, s.logger, func(meta *metadata.Meta) []BlockRing {
+		return s.cfg.Rings(s.r, meta.MaxTime, now)
+	})
+	return nil
+}
+
+// OwnBlock implements ShardingStrategy.
+func (s *TimeBasedShardingStrategy) OwnBlock(_ string, meta metadata.Meta) (bool, error) {
+	key := cortex_tsdb.HashBlockID(meta.ULID)
+
+	// Check if the block is owned by the store-gateway in any of its rings.
+	for _, br := range s.cfg.Rings(s.r, meta.MaxTime, time.Now()) {
+		set, err := br.Ring.GetWithReplicationFactor(key, BlocksOwnerSync, br.ReplicationFactor, nil, nil, nil)
+		if err != nil {
+			return false, err
+		}
+		if set.Includes(s.instanceAddr) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// lazyDownloadIndexHeader returns whether the index-header of the block is downloaded on the first query
+// instead of when the block is loaded, which is the case of the old blocks.
+func (s *TimeBasedShardingStrategy) lazyDownloadIndexHeader(meta *metadata.Meta) bool {
+	return !s.cfg.isRecent(meta.MaxTime, time.Now())
+}
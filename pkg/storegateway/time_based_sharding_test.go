@@ -0,0 +1,207 @@
+package storegateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/oklog/ulid/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+	"github.com/thanos-io/thanos/pkg/extprom"
+
+	"github.com/cortexproject/cortex/pkg/ring"
+	"github.com/cortexproject/cortex/pkg/ring/kv/consul"
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/util/services"
+)
+
+func TestTimeBasedShardingConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		cfg      TimeBasedShardingConfig
+		expected error
+	}{
+		"valid": {
+			cfg: TimeBasedShardingConfig{RecentBlocksPeriod: time.Hour, RecentBlocksReplicationFactor: 3},
+		},
+		"invalid recent blocks period": {
+			cfg:      TimeBasedShardingConfig{RecentBlocksPeriod: 0, RecentBlocksReplicationFactor: 3},
+			expected: errInvalidRecentBlocksPeriod,
+		},
+		"recent blocks replication factor lower than the ring replication factor": {
+			cfg:      TimeBasedShardingConfig{RecentBlocksPeriod: time.Hour, RecentBlocksReplicationFactor: 1},
+			expected: errInvalidRecentBlocksReplicationFactor,
+		},
+		"valid old blocks shard size": {
+			cfg: TimeBasedShardingConfig{RecentBlocksPeriod: time.Hour, RecentBlocksReplicationFactor: 3, OldBlocksShardSize: 2},
+		},
+		"old blocks shard size lower than the ring replication factor": {
+			cfg:      TimeBasedShardingConfig{RecentBlocksPeriod: time.Hour, RecentBlocksReplicationFactor: 3, OldBlocksShardSize: 1},
+			expected: errInvalidOldBlocksShardSize,
+		},
+		"negative transition grace period": {
+			cfg:      TimeBasedShardingConfig{RecentBlocksPeriod: time.Hour, RecentBlocksReplicationFactor: 3, TransitionGracePeriod: -time.Minute},
+			expected: errInvalidTransitionGracePeriod,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expected, testData.cfg.Validate(2))
+		})
+	}
+}
+
+func TestTimeBasedShardingStrategy(t *testing.T) {
+	t.Parallel()
+
+	// The following block IDs have been picked to have increasing hash values
+	// in order to simplify the tests.
+	block1 := ulid.MustNew(1, nil) // hash: 283204220
+	block2 := ulid.MustNew(2, nil) // hash: 444110359
+	block1Hash := cortex_tsdb.HashBlockID(block1)
+	block2Hash := cortex_tsdb.HashBlockID(block2)
+	registeredAt := time.Now()
+
+	ctx := context.Background()
+	store, closer := consul.NewInMemoryClient(ring.GetCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+
+	// Initialize the ring state.
+	require.NoError(t, store.CAS(ctx, "test", func(in any) (any, bool, error) {
+		d := ring.NewDesc()
+		d.AddIngester("instance-1", "127.0.0.1", "", []uint32{block1Hash + 1}, ring.ACTIVE, registeredAt)
+		d.AddIngester("instance-2", "127.0.0.2", "", []uint32{block2Hash + 1}, ring.ACTIVE, registeredAt)
+		return d, true, nil
+	}))
+
+	cfg := ring.Config{
+		ReplicationFactor: 1,
+		HeartbeatTimeout:  time.Minute,
+	}
+
+	r, err := ring.NewWithStoreClientAndStrategy(cfg, "test", "test", store, ring.NewIgnoreUnhealthyInstancesReplicationStrategy(), nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, r))
+	defer services.StopAndAwaitTerminated(ctx, r) //nolint:errcheck
+
+	// Wait until the ring client has synced.
+	require.NoError(t, ring.WaitInstanceState(ctx, r, "instance-1", ring.ACTIVE))
+
+	shardingCfg := TimeBasedShardingConfig{RecentBlocksPeriod: time.Hour, RecentBlocksReplicationFactor: 2}
+	recentMaxTime := time.Now().UnixMilli()
+	oldMaxTime := time.Now().Add(-2 * time.Hour).UnixMilli()
+	metaIDs := func(metas map[ulid.ULID]*metadata.Meta) []ulid.ULID {
+		ids := make([]ulid.ULID, 0, len(metas))
+		for id := range metas {
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	t.Run("recent blocks should be owned by the recent blocks replication factor instances", func(t *testing.T) {
+		filter := NewTimeBasedShardingStrategy(r, "127.0.0.2", shardingCfg, log.NewNopLogger(), nil)
+
+		owned, err := filter.OwnBlock("", metadata.Meta{BlockMeta: tsdb.BlockMeta{ULID: block1, MaxTime: recentMaxTime}})
+		require.NoError(t, err)
+		assert.True(t, owned)
+
+		owned, err = filter.OwnBlock("", metadata.Meta{BlockMeta: tsdb.BlockMeta{ULID: block1, MaxTime: oldMaxTime}})
+		require.NoError(t, err)
+		assert.False(t, owned)
+	})
+
+	t.Run("old blocks should be filtered out by the instances not owning them with the ring replication factor", func(t *testing.T) {
+		filter := NewTimeBasedShardingStrategy(r, "127.0.0.2", shardingCfg, log.NewNopLogger(), nil)
+
+		metas := map[ulid.ULID]*metadata.Meta{
+			block1: {BlockMeta: tsdb.BlockMeta{ULID: block1, MaxTime: oldMaxTime}},
+			block2: {BlockMeta: tsdb.BlockMeta{ULID: block2, MaxTime: recentMaxTime}},
+		}
+
+		synced := extprom.NewTxGaugeVec(nil, prometheus.GaugeOpts{}, []string{"state"})
+		require.NoError(t, filter.FilterBlocks(ctx, "user-1", metas, map[ulid.ULID]struct{}{}, synced))
+		assert.Equal(t, []ulid.ULID{block2}, metaIDs(metas))
+
+		metas = map[ulid.ULID]*metadata.Meta{
+			block1: {BlockMeta: tsdb.BlockMeta{ULID: block1, MaxTime: recentMaxTime}},
+			block2: {BlockMeta: tsdb.BlockMeta{ULID: block2, MaxTime: recentMaxTime}},
+		}
+
+		require.NoError(t, filter.FilterBlocks(ctx, "user-1", metas, map[ulid.ULID]struct{}{}, synced))
+		assert.ElementsMatch(t, []ulid.ULID{block1, block2}, metaIDs(metas))
+	})
+
+	t.Run("old blocks should only be owned by the instances dedicated to the old blocks", func(t *testing.T) {
+		cfg := shardingCfg
+		cfg.OldBlocksShardSize = 1
+
+		subring := r.ShuffleShard(oldBlocksShardIdentifier, cfg.OldBlocksShardSize)
+		dedicated, err := subring.GetAllHealthy(BlocksOwnerSync)
+		require.NoError(t, err)
+		require.Len(t, dedicated.Instances, 1)
+
+		for _, addr := range []string{"127.0.0.1", "127.0.0.2"} {
+			filter := NewTimeBasedShardingStrategy(r, addr, cfg, log.NewNopLogger(), nil)
+
+			for _, blockID := range []ulid.ULID{block1, block2} {
+				owned, err := filter.OwnBlock("", metadata.Meta{BlockMeta: tsdb.BlockMeta{ULID: blockID, MaxTime: oldMaxTime}})
+				require.NoError(t, err)
+				assert.Equal(t, addr == dedicated.Instances[0].Addr, owned)
+			}
+
+			assert.True(t, filter.lazyDownloadIndexHeader(&metadata.Meta{BlockMeta: tsdb.BlockMeta{ULID: block1, MaxTime: oldMaxTime}}))
+			assert.False(t, filter.lazyDownloadIndexHeader(&metadata.Meta{BlockMeta: tsdb.BlockMeta{ULID: block1, MaxTime: recentMaxTime}}))
+		}
+	})
+
+	t.Run("blocks transitioning from recent to old should be owned by the instances of both rings", func(t *testing.T) {
+		cfg := shardingCfg
+		cfg.RecentBlocksReplicationFactor = 1
+		cfg.OldBlocksShardSize = 1
+		cfg.TransitionGracePeriod = 15 * time.Minute
+		recentOwners := map[ulid.ULID]string{block1: "127.0.0.1", block2: "127.0.0.2"}
+
+		subring := r.ShuffleShard(oldBlocksShardIdentifier, cfg.OldBlocksShardSize)
+		dedicated, err := subring.GetAllHealthy(BlocksOwnerSync)
+		require.NoError(t, err)
+		require.Len(t, dedicated.Instances, 1)
+
+		// The blocks stopped being recent 5 minutes ago.
+		transitioningMaxTime := time.Now().Add(-cfg.RecentBlocksPeriod - 5*time.Minute).UnixMilli()
+		assert.Len(t, cfg.Rings(r, transitioningMaxTime, time.Now()), 2)
+		assert.Len(t, cfg.Rings(r, oldMaxTime, time.Now()), 1)
+		assert.Len(t, cfg.Rings(r, recentMaxTime, time.Now()), 1)
+
+		for _, addr := range []string{"127.0.0.1", "127.0.0.2"} {
+			filter := NewTimeBasedShardingStrategy(r, addr, cfg, log.NewNopLogger(), nil)
+
+			// The blocks are owned both by their recent blocks owner and by the instance dedicated to the old blocks.
+			var expected []ulid.ULID
+			for _, blockID := range []ulid.ULID{block1, block2} {
+				owned, err := filter.OwnBlock("", metadata.Meta{BlockMeta: tsdb.BlockMeta{ULID: blockID, MaxTime: transitioningMaxTime}})
+				require.NoError(t, err)
+				assert.Equal(t, addr == recentOwners[blockID] || addr == dedicated.Instances[0].Addr, owned)
+
+				if owned {
+					expected = append(expected, blockID)
+				}
+			}
+
+			metas := map[ulid.ULID]*metadata.Meta{
+				block1: {BlockMeta: tsdb.BlockMeta{ULID: block1, MaxTime: transitioningMaxTime}},
+				block2: {BlockMeta: tsdb.BlockMeta{ULID: block2, MaxTime: transitioningMaxTime}},
+			}
+
+			synced := extprom.NewTxGaugeVec(nil, prometheus.GaugeOpts{}, []string{"state"})
+			require.NoError(t, filter.FilterBlocks(ctx, "user-1", metas, map[ulid.ULID]struct{}{}, synced))
+			assert.ElementsMatch(t, expected, metaIDs(metas))
+		}
+	})
+}
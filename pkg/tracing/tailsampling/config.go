@@ -0,0 +1,53 @@
+package tailsampling
+
+import (
+	"flag"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cortexproject/cortex/pkg/util/grpcclient"
+	util_log "github.com/cortexproject/cortex/pkg/util/log"
+)
+
+var (
+	errInvalidDecisionWait     = errors.New("the tail sampling decision wait must be greater than 0")
+	errInvalidMaxDecisionWait  = errors.New("the tail sampling max decision wait must be greater than or equal to the decision wait")
+	errInvalidMaxBufferedSpans = errors.New("the tail sampling max buffered spans must be greater than 0")
+)
+
+type Config struct {
+	Enabled          bool          `yaml:"enabled"`
+	LatencyThreshold time.Duration `yaml:"latency_threshold"`
+	DecisionWait     time.Duration `yaml:"decision_wait"`
+	MaxDecisionWait  time.Duration `yaml:"max_decision_wait"`
+	MaxBufferedSpans int           `yaml:"max_buffered_spans"`
+
+	GRPCClientConfig grpcclient.Config `yaml:"grpc_client_config" doc:"description=The gRPC client used to ask the query-frontends for their sampling decisions."`
+}
+
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+".enabled", false, "[Experimental] If enabled, the spans of every query are buffered and the trace is only kept when the query is slower than the latency threshold, failed or belongs to a tenant with tracing debug enabled. The other traces are sampled with the sample ratio. This option must be set on all the Cortex components.")
+	f.DurationVar(&cfg.LatencyThreshold, prefix+".latency-threshold", 10*time.Second, "The query-frontend keeps the traces of the queries slower than this threshold.")
+	f.DurationVar(&cfg.DecisionWait, prefix+".decision-wait", 10*time.Second, "How long the spans of a trace are buffered after its last span ended, before asking the query-frontend which started the trace whether it must be kept.")
+	f.DurationVar(&cfg.MaxDecisionWait, prefix+".max-decision-wait", 5*time.Minute, "The maximum time the spans of a trace are buffered, waiting for the query to complete or the query-frontend to be reachable. The trace is dropped after this time, unless sampled by the sample ratio.")
+	f.IntVar(&cfg.MaxBufferedSpans, prefix+".max-buffered-spans", 100000, "The maximum number of spans buffered by each Cortex component. When reached, the oldest traces are dropped, unless sampled by the sample ratio, to make room for the new spans.")
+
+	cfg.GRPCClientConfig.RegisterFlagsWithPrefix(prefix+".grpc-client-config", "", f)
+}
+
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.DecisionWait <= 0 {
+		return errInvalidDecisionWait
+	}
+	if cfg.MaxDecisionWait < cfg.DecisionWait {
+		return errInvalidMaxDecisionWait
+	}
+	if cfg.MaxBufferedSpans <= 0 {
+		return errInvalidMaxBufferedSpans
+	}
+	return cfg.GRPCClientConfig.Validate(util_log.Logger)
+}
@@ -0,0 +1,68 @@
+package tailsampling
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/weaveworks/common/middleware"
+
+	"github.com/cortexproject/cortex/pkg/util/users"
+)
+
+// Limits are the per-tenant limits used by the query-frontend to decide whether to keep the traces.
+type Limits interface {
+	// TracingDebugEnabled returns whether the traces of all the queries of the tenant are kept.
+	TracingDebugEnabled(userID string) bool
+}
+
+// Middleware returns the query-frontend middleware keeping the traces of the queries which are slower than
+// the latency threshold, failed, or belong to a tenant with tracing debug enabled.
+func (p *Processor) Middleware(limits Limits) middleware.Interface {
+	return middleware.Func(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+
+			if time.Since(start) > p.cfg.LatencyThreshold || isFailure(sw.status) || debugEnabled(r, limits) {
+				p.Keep(r.Context())
+			}
+		})
+	})
+}
+
+// isFailure returns whether the status code is the one of a failed query, i.e. a server error or a
+// query which failed to execute.
+func isFailure(status int) bool {
+	return status/100 == 5 || status == http.StatusUnprocessableEntity
+}
+
+func debugEnabled(r *http.Request, limits Limits) bool {
+	tenantIDs, err := users.TenantIDs(r.Context())
+	if err != nil {
+		return false
+	}
+	for _, tenantID := range tenantIDs {
+		if limits.TracingDebugEnabled(tenantID) {
+			return true
+		}
+	}
+	return false
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Flush implements http.Flusher, used by the streamed responses.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,84 @@
+package tailsampling
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/weaveworks/common/user"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type mockLimits map[string]bool
+
+func (l mockLimits) TracingDebugEnabled(userID string) bool {
+	return l[userID]
+}
+
+func TestMiddleware(t *testing.T) {
+	for name, testData := range map[string]struct {
+		latencyThreshold time.Duration
+		status           int
+		userID           string
+		expectedKept     bool
+	}{
+		"fast successful query": {
+			latencyThreshold: time.Hour,
+			status:           http.StatusOK,
+			userID:           "user-1",
+		},
+		"slow query": {
+			latencyThreshold: -1,
+			status:           http.StatusOK,
+			userID:           "user-1",
+			expectedKept:     true,
+		},
+		"failed query": {
+			latencyThreshold: time.Hour,
+			status:           http.StatusInternalServerError,
+			userID:           "user-1",
+			expectedKept:     true,
+		},
+		"query failed to execute": {
+			latencyThreshold: time.Hour,
+			status:           http.StatusUnprocessableEntity,
+			userID:           "user-1",
+			expectedKept:     true,
+		},
+		"bad request": {
+			latencyThreshold: time.Hour,
+			status:           http.StatusBadRequest,
+			userID:           "user-1",
+		},
+		"tenant with tracing debug enabled": {
+			latencyThreshold: time.Hour,
+			status:           http.StatusOK,
+			userID:           "debugged",
+			expectedKept:     true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.LatencyThreshold = testData.latencyThreshold
+			tracer, p, _ := newTracer(t, cfg, 0)
+			p.EnableDecisions(frontendAddress)
+
+			handler := p.Middleware(mockLimits{"debugged": true}).Wrap(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(testData.status)
+			}))
+
+			ctx, span := tracer.Start(user.InjectOrgID(context.Background(), testData.userID), "query")
+			defer span.End()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/query", nil).WithContext(ctx)
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+			assert.Equal(t, testData.status, resp.Code)
+
+			_, kept := p.kept[trace.SpanContextFromContext(ctx).TraceID()]
+			assert.Equal(t, testData.expectedKept, kept)
+		})
+	}
+}
@@ -0,0 +1,444 @@
+package tailsampling
+
+import (
+	"container/list"
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/atomic"
+	"google.golang.org/grpc"
+)
+
+const (
+	// The tracestate key carrying the address of the query-frontend which started a deferred trace,
+	// i.e. a trace whose spans are buffered until the query-frontend decides whether it must be kept.
+	originKey = "cortex-tail"
+
+	// How often the buffered traces are checked for a decision.
+	resolveInterval = time.Second
+)
+
+// Processor is a span processor buffering the spans of the deferred traces, until the query-frontend
+// which started the trace decides whether it must be kept. The kept spans are forwarded to the next
+// processor, typically the batcher exporting them.
+//
+// The traces sampled by the sample ratio are always kept, so their spans are forwarded right away.
+type Processor struct {
+	cfg    Config
+	next   sdktrace.SpanProcessor
+	ratio  sdktrace.Sampler
+	logger log.Logger
+	dial   func(address string) (TailSamplingClient, io.Closer, error)
+
+	// The address of the query-frontend running in this process, empty if there's none.
+	address atomic.String
+
+	mtx    sync.Mutex
+	traces map[trace.TraceID]*list.Element
+	order  *list.List // The buffered traces, the oldest first.
+	spans  int
+	// The number of running root spans of the traces started by this process.
+	running map[trace.TraceID]int
+	// The traces explicitly kept, with the time of the decision.
+	kept    map[trace.TraceID]time.Time
+	clients map[string]*decisionsClient
+
+	stop chan struct{}
+	done chan struct{}
+
+	bufferedSpans  prometheus.Gauge
+	decidedTraces  *prometheus.CounterVec
+	evictedTraces  prometheus.Counter
+	decisionErrors prometheus.Counter
+}
+
+type bufferedTrace struct {
+	id        trace.TraceID
+	origin    string
+	spans     []sdktrace.ReadOnlySpan
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+type decisionsClient struct {
+	TailSamplingClient
+	conn     io.Closer
+	lastUsed time.Time
+}
+
+// NewProcessor makes a new Processor forwarding the kept spans to next.
+func NewProcessor(cfg Config, sampleRatio float64, next sdktrace.SpanProcessor, logger log.Logger, reg prometheus.Registerer) *Processor {
+	p := &Processor{
+		cfg:     cfg,
+		next:    next,
+		ratio:   sdktrace.TraceIDRatioBased(sampleRatio),
+		logger:  logger,
+		traces:  map[trace.TraceID]*list.Element{},
+		order:   list.New(),
+		running: map[trace.TraceID]int{},
+		kept:    map[trace.TraceID]time.Time{},
+		clients: map[string]*decisionsClient{},
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+
+		bufferedSpans: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "cortex_tracing_tail_sampling_buffered_spans",
+			Help: "Number of spans buffered waiting for the sampling decision of their trace.",
+		}),
+		decidedTraces: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_tracing_tail_sampling_decided_traces_total",
+			Help: "Total number of buffered traces which have been kept or dropped.",
+		}, []string{"decision"}),
+		evictedTraces: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_tracing_tail_sampling_evicted_traces_total",
+			Help: "Total number of traces decided before the query-frontend's decision, because the buffer was full.",
+		}),
+		decisionErrors: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_tracing_tail_sampling_decision_errors_total",
+			Help: "Total number of failures asking a query-frontend for its sampling decisions.",
+		}),
+	}
+	p.dial = p.dialFrontend
+
+	go p.loop()
+	return p
+}
+
+// Sampler returns the sampler starting deferred traces in the processes running a query-frontend.
+func (p *Processor) Sampler() sdktrace.Sampler {
+	return sampler{p: p}
+}
+
+// EnableDecisions makes this process start deferred traces and answer the decisions about them,
+// advertising the given gRPC address. It's called by the query-frontend.
+func (p *Processor) EnableDecisions(address string) {
+	p.address.Store(address)
+}
+
+// Keep marks the trace of the span in the context to be kept.
+func (p *Processor) Keep(ctx context.Context) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() || sc.TraceState().Get(originKey) == "" {
+		return
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.kept[sc.TraceID()] = time.Now()
+}
+
+func (p *Processor) OnStart(_ context.Context, s sdktrace.ReadWriteSpan) {
+	if s.Parent().IsValid() || !p.isOwnTrace(s.SpanContext()) {
+		return
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.running[s.SpanContext().TraceID()]++
+}
+
+func (p *Processor) OnEnd(s sdktrace.ReadOnlySpan) {
+	sc := s.SpanContext()
+	origin := sc.TraceState().Get(originKey)
+	if origin == "" || p.sampledByRatio(sc.TraceID()) {
+		p.next.OnEnd(s)
+		return
+	}
+
+	id := sc.TraceID()
+	now := time.Now()
+
+	p.mtx.Lock()
+	var spans []sdktrace.ReadOnlySpan
+	if _, ok := p.kept[id]; ok {
+		spans = append(spans, s)
+	} else {
+		spans = p.buffer(id, origin, s, now)
+	}
+
+	// The trace is decided as soon as its root span ends in the process which started it.
+	if !s.Parent().IsValid() && p.isOwnTrace(sc) {
+		if p.running[id]--; p.running[id] <= 0 {
+			delete(p.running, id)
+			spans = append(spans, p.resolve(id, false)...)
+		}
+	}
+	p.mtx.Unlock()
+
+	p.export(spans)
+}
+
+func (p *Processor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+func (p *Processor) Shutdown(ctx context.Context) error {
+	close(p.stop)
+	<-p.done
+
+	p.mtx.Lock()
+	for address, c := range p.clients {
+		_ = c.conn.Close()
+		delete(p.clients, address)
+	}
+	p.mtx.Unlock()
+
+	return p.next.Shutdown(ctx)
+}
+
+// Decisions implements TailSamplingServer.
+func (p *Processor) Decisions(_ context.Context, req *DecisionsRequest) (*DecisionsResponse, error) {
+	resp := &DecisionsResponse{}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	for _, b := range req.TraceIDs {
+		id, ok := toTraceID(b)
+		if !ok {
+			continue
+		}
+
+		switch _, kept := p.kept[id]; {
+		case kept || p.sampledByRatio(id):
+			resp.KeptTraceIDs = append(resp.KeptTraceIDs, b)
+		case p.running[id] > 0:
+			resp.PendingTraceIDs = append(resp.PendingTraceIDs, b)
+		}
+	}
+	return resp, nil
+}
+
+func (p *Processor) loop() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(resolveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.resolveBuffered(context.Background(), time.Now())
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// resolveBuffered asks the query-frontends for the decisions about the traces which got no new spans
+// for the decision wait.
+func (p *Processor) resolveBuffered(ctx context.Context, now time.Time) {
+	byOrigin := map[string][][]byte{}
+
+	p.mtx.Lock()
+	for e := p.order.Front(); e != nil; e = e.Next() {
+		t := e.Value.(*bufferedTrace)
+		if now.Sub(t.updatedAt) >= p.cfg.DecisionWait {
+			byOrigin[t.origin] = append(byOrigin[t.origin], t.id[:])
+		}
+	}
+	p.mtx.Unlock()
+
+	for origin, ids := range byOrigin {
+		resp, err := p.askDecisions(ctx, origin, ids, now)
+		if err != nil {
+			level.Warn(p.logger).Log("msg", "failed to get the tail sampling decisions", "frontend", origin, "err", err)
+			p.decisionErrors.Inc()
+		}
+
+		kept := map[trace.TraceID]bool{}
+		if resp != nil {
+			for _, b := range resp.KeptTraceIDs {
+				if id, ok := toTraceID(b); ok {
+					kept[id] = true
+				}
+			}
+			for _, b := range resp.PendingTraceIDs {
+				if id, ok := toTraceID(b); ok {
+					kept[id] = false
+				}
+			}
+		}
+
+		var spans []sdktrace.ReadOnlySpan
+		p.mtx.Lock()
+		for _, b := range ids {
+			id, _ := toTraceID(b)
+			e, ok := p.traces[id]
+			if !ok {
+				continue
+			}
+			t := e.Value.(*bufferedTrace)
+
+			keep, decided := kept[id]
+			switch {
+			case keep:
+				p.kept[id] = now
+				spans = append(spans, p.resolve(id, true)...)
+			case resp != nil && !decided:
+				spans = append(spans, p.resolve(id, false)...)
+			case now.Sub(t.createdAt) >= p.cfg.MaxDecisionWait:
+				// The query is still running, or the query-frontend can't be reached: give up.
+				spans = append(spans, p.resolve(id, false)...)
+			default:
+				// Ask again after the decision wait.
+				t.updatedAt = now
+			}
+		}
+		p.mtx.Unlock()
+
+		p.export(spans)
+	}
+
+	p.cleanup(now)
+}
+
+func (p *Processor) askDecisions(ctx context.Context, origin string, ids [][]byte, now time.Time) (*DecisionsResponse, error) {
+	req := &DecisionsRequest{TraceIDs: ids}
+	if origin == p.address.Load() {
+		return p.Decisions(ctx, req)
+	}
+
+	client, err := p.getClient(origin, now)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, resolveInterval)
+	defer cancel()
+	return client.Decisions(ctx, req)
+}
+
+func (p *Processor) getClient(address string, now time.Time) (TailSamplingClient, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if c, ok := p.clients[address]; ok {
+		c.lastUsed = now
+		return c, nil
+	}
+
+	client, conn, err := p.dial(address)
+	if err != nil {
+		return nil, err
+	}
+	p.clients[address] = &decisionsClient{TailSamplingClient: client, conn: conn, lastUsed: now}
+	return client, nil
+}
+
+func (p *Processor) dialFrontend(address string) (TailSamplingClient, io.Closer, error) {
+	// The calls aren't traced, not to start new traces while deciding about the others.
+	opts, err := p.cfg.GRPCClientConfig.DialOption(nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := grpc.NewClient(address, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewTailSamplingClient(conn), conn, nil
+}
+
+// cleanup forgets the old decisions and closes the connections to the query-frontends not used anymore.
+func (p *Processor) cleanup(now time.Time) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	for id, decidedAt := range p.kept {
+		if now.Sub(decidedAt) >= p.cfg.MaxDecisionWait {
+			delete(p.kept, id)
+		}
+	}
+
+	for address, c := range p.clients {
+		if now.Sub(c.lastUsed) >= p.cfg.MaxDecisionWait {
+			_ = c.conn.Close()
+			delete(p.clients, address)
+		}
+	}
+}
+
+// buffer adds the span to its trace, and evicts the oldest traces when the buffer is full.
+// It returns the spans of the evicted traces to keep. Must be called with the lock held.
+func (p *Processor) buffer(id trace.TraceID, origin string, s sdktrace.ReadOnlySpan, now time.Time) []sdktrace.ReadOnlySpan {
+	var evicted []sdktrace.ReadOnlySpan
+	for p.spans >= p.cfg.MaxBufferedSpans && p.order.Len() > 0 {
+		oldest := p.order.Front().Value.(*bufferedTrace)
+		evicted = append(evicted, p.resolve(oldest.id, false)...)
+		p.evictedTraces.Inc()
+	}
+
+	e, ok := p.traces[id]
+	if !ok {
+		e = p.order.PushBack(&bufferedTrace{id: id, origin: origin, createdAt: now})
+		p.traces[id] = e
+	}
+	t := e.Value.(*bufferedTrace)
+	t.spans = append(t.spans, s)
+	t.updatedAt = now
+
+	p.spans++
+	p.bufferedSpans.Set(float64(p.spans))
+	return evicted
+}
+
+// resolve removes the trace from the buffer, and returns its spans if the trace must be kept, either
+// because it was decided so or because it was marked to be kept. Must be called with the lock held.
+func (p *Processor) resolve(id trace.TraceID, keep bool) []sdktrace.ReadOnlySpan {
+	if _, ok := p.kept[id]; ok {
+		keep = true
+	}
+
+	e, ok := p.traces[id]
+	if !ok {
+		return nil
+	}
+	t := e.Value.(*bufferedTrace)
+	p.order.Remove(e)
+	delete(p.traces, id)
+
+	p.spans -= len(t.spans)
+	p.bufferedSpans.Set(float64(p.spans))
+
+	if !keep {
+		p.decidedTraces.WithLabelValues("dropped").Inc()
+		return nil
+	}
+	p.decidedTraces.WithLabelValues("kept").Inc()
+	return t.spans
+}
+
+func (p *Processor) export(spans []sdktrace.ReadOnlySpan) {
+	for _, s := range spans {
+		p.next.OnEnd(s)
+	}
+}
+
+func (p *Processor) isOwnTrace(sc trace.SpanContext) bool {
+	origin := sc.TraceState().Get(originKey)
+	return origin != "" && origin == p.address.Load()
+}
+
+// sampledByRatio returns whether the trace is sampled with the sample ratio. The decision only depends on the
+// trace ID, so it's the same in all the components.
+func (p *Processor) sampledByRatio(id trace.TraceID) bool {
+	return p.ratio.ShouldSample(sdktrace.SamplingParameters{TraceID: id}).Decision == sdktrace.RecordAndSample
+}
+
+func toTraceID(b []byte) (trace.TraceID, bool) {
+	var id trace.TraceID
+	if len(b) != len(id) {
+		return id, false
+	}
+	copy(id[:], b)
+	return id, true
+}
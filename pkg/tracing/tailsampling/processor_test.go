@@ -0,0 +1,223 @@
+package tailsampling
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+const frontendAddress = "frontend:9095"
+
+func defaultConfig() Config {
+	return Config{
+		Enabled:          true,
+		LatencyThreshold: time.Hour,
+		DecisionWait:     time.Minute,
+		MaxDecisionWait:  5 * time.Minute,
+		MaxBufferedSpans: 100,
+	}
+}
+
+// newTracer returns a tracer using a new processor, and the recorder of the spans it exports.
+func newTracer(t *testing.T, cfg Config, sampleRatio float64) (trace.Tracer, *Processor, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	p := NewProcessor(cfg, sampleRatio, recorder, log.NewNopLogger(), prometheus.NewPedanticRegistry())
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(p), sdktrace.WithSampler(p.Sampler()))
+	t.Cleanup(func() {
+		require.NoError(t, tp.Shutdown(context.Background()))
+	})
+	return tp.Tracer("test"), p, recorder
+}
+
+func TestProcessor_ShouldDecideTheTracesStartedByTheFrontend(t *testing.T) {
+	for name, testData := range map[string]struct {
+		sampleRatio   float64
+		keep          bool
+		expectedSpans int
+	}{
+		"kept trace": {
+			keep:          true,
+			expectedSpans: 2,
+		},
+		"dropped trace": {
+			expectedSpans: 0,
+		},
+		"trace sampled by the sample ratio": {
+			sampleRatio:   1,
+			expectedSpans: 2,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tracer, p, recorder := newTracer(t, defaultConfig(), testData.sampleRatio)
+			p.EnableDecisions(frontendAddress)
+
+			ctx, root := tracer.Start(context.Background(), "query")
+			assert.Equal(t, frontendAddress, root.SpanContext().TraceState().Get(originKey))
+			assert.True(t, root.SpanContext().IsSampled())
+
+			_, child := tracer.Start(ctx, "child")
+			child.End()
+			if testData.keep {
+				p.Keep(ctx)
+			}
+			if testData.sampleRatio == 0 {
+				assert.Empty(t, recorder.Ended())
+			}
+
+			root.End()
+			assert.Len(t, recorder.Ended(), testData.expectedSpans)
+			assert.Empty(t, p.traces)
+			assert.Zero(t, p.spans)
+		})
+	}
+}
+
+func TestProcessor_ShouldAskTheFrontendForItsDecisions(t *testing.T) {
+	cfg := defaultConfig()
+	frontendTracer, frontend, frontendRecorder := newTracer(t, cfg, 0)
+	frontend.EnableDecisions(frontendAddress)
+	tracer, p, recorder := newTracer(t, cfg, 0)
+	p.dial = func(address string) (TailSamplingClient, io.Closer, error) {
+		require.Equal(t, frontendAddress, address)
+		return &mockClient{server: frontend}, io.NopCloser(nil), nil
+	}
+
+	// Start two queries, and the spans of another component handling them.
+	keptCtx, keptRoot := frontendTracer.Start(context.Background(), "kept query")
+	droppedCtx, droppedRoot := frontendTracer.Start(context.Background(), "dropped query")
+
+	now := time.Now()
+	for _, ctx := range []context.Context{keptCtx, droppedCtx} {
+		remoteCtx := trace.ContextWithRemoteSpanContext(context.Background(), trace.SpanContextFromContext(ctx).WithRemote(true))
+		_, span := tracer.Start(remoteCtx, "querier")
+		assert.Equal(t, frontendAddress, span.SpanContext().TraceState().Get(originKey))
+		span.End()
+	}
+	require.Len(t, p.traces, 2)
+
+	// The spans are buffered until the decision wait elapsed.
+	p.resolveBuffered(context.Background(), now)
+	require.Len(t, p.traces, 2)
+
+	// The queries are still running.
+	now = now.Add(cfg.DecisionWait)
+	p.resolveBuffered(context.Background(), now)
+	require.Len(t, p.traces, 2)
+	assert.Empty(t, recorder.Ended())
+
+	// The queries complete.
+	frontend.Keep(keptCtx)
+	keptRoot.End()
+	droppedRoot.End()
+	assert.Len(t, frontendRecorder.Ended(), 1)
+
+	now = now.Add(cfg.DecisionWait)
+	p.resolveBuffered(context.Background(), now)
+	assert.Empty(t, p.traces)
+	require.Len(t, recorder.Ended(), 1)
+	assert.Equal(t, keptRoot.SpanContext().TraceID(), recorder.Ended()[0].SpanContext().TraceID())
+
+	// The late spans of the kept trace are exported right away.
+	remoteCtx := trace.ContextWithRemoteSpanContext(context.Background(), keptRoot.SpanContext().WithRemote(true))
+	_, span := tracer.Start(remoteCtx, "late")
+	span.End()
+	assert.Len(t, recorder.Ended(), 2)
+}
+
+func TestProcessor_ShouldDropTheTracesWhenTheFrontendIsUnreachable(t *testing.T) {
+	cfg := defaultConfig()
+	frontendTracer, frontend, _ := newTracer(t, cfg, 0)
+	frontend.EnableDecisions(frontendAddress)
+	tracer, p, recorder := newTracer(t, cfg, 0)
+	p.dial = func(string) (TailSamplingClient, io.Closer, error) {
+		return nil, nil, errors.New("unreachable")
+	}
+
+	ctx, root := frontendTracer.Start(context.Background(), "query")
+	defer root.End()
+	remoteCtx := trace.ContextWithRemoteSpanContext(context.Background(), trace.SpanContextFromContext(ctx).WithRemote(true))
+	_, span := tracer.Start(remoteCtx, "querier")
+	span.End()
+
+	now := time.Now().Add(cfg.DecisionWait)
+	p.resolveBuffered(context.Background(), now)
+	require.Len(t, p.traces, 1)
+
+	p.resolveBuffered(context.Background(), now.Add(cfg.MaxDecisionWait))
+	assert.Empty(t, p.traces)
+	assert.Empty(t, recorder.Ended())
+}
+
+func TestProcessor_ShouldEvictTheOldestTracesWhenTheBufferIsFull(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.MaxBufferedSpans = 2
+	tracer, p, recorder := newTracer(t, cfg, 0)
+	p.EnableDecisions(frontendAddress)
+
+	firstCtx, first := tracer.Start(context.Background(), "first")
+	defer first.End()
+	secondCtx, second := tracer.Start(context.Background(), "second")
+	defer second.End()
+
+	for _, ctx := range []context.Context{firstCtx, firstCtx, secondCtx} {
+		_, child := tracer.Start(ctx, "child")
+		child.End()
+	}
+	assert.Empty(t, recorder.Ended())
+	assert.Len(t, p.traces, 1)
+	assert.Equal(t, 1, p.spans)
+
+	// The spans of an evicted trace are exported if it was kept.
+	p.Keep(secondCtx)
+	for _, ctx := range []context.Context{firstCtx, firstCtx} {
+		_, child := tracer.Start(ctx, "child")
+		child.End()
+	}
+	assert.Len(t, recorder.Ended(), 1)
+	assert.Equal(t, second.SpanContext().TraceID(), recorder.Ended()[0].SpanContext().TraceID())
+	assert.Equal(t, 2, p.spans)
+}
+
+func TestSampler(t *testing.T) {
+	tracer, p, _ := newTracer(t, defaultConfig(), 0)
+
+	// Without query-frontend, the root spans are sampled with the sample ratio.
+	_, span := tracer.Start(context.Background(), "root")
+	assert.False(t, span.SpanContext().IsSampled())
+	assert.Empty(t, span.SpanContext().TraceState().Get(originKey))
+	span.End()
+
+	// The child spans are sampled like their parent.
+	p.EnableDecisions(frontendAddress)
+	ctx, root := tracer.Start(context.Background(), "root")
+	assert.True(t, root.SpanContext().IsSampled())
+	_, child := tracer.Start(ctx, "child")
+	assert.True(t, child.SpanContext().IsSampled())
+	assert.Equal(t, root.SpanContext().TraceState(), child.SpanContext().TraceState())
+	child.End()
+	root.End()
+
+	notSampled := trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{1}, Remote: true})
+	_, child = tracer.Start(trace.ContextWithRemoteSpanContext(context.Background(), notSampled), "child")
+	assert.False(t, child.SpanContext().IsSampled())
+	child.End()
+}
+
+type mockClient struct {
+	server TailSamplingServer
+}
+
+func (c *mockClient) Decisions(ctx context.Context, in *DecisionsRequest, _ ...grpc.CallOption) (*DecisionsResponse, error) {
+	return c.server.Decisions(ctx, in)
+}
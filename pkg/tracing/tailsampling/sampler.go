@@ -0,0 +1,36 @@
+package tailsampling
+
+import (
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// sampler samples the child spans like their parent, and the root spans with the sample ratio, except in the
+// processes running a query-frontend, where all the root spans start a deferred trace. The deferred traces are
+// sampled, so that all the components record their spans, and their tracestate carries the address of the
+// query-frontend deciding whether they are kept.
+type sampler struct {
+	p *Processor
+}
+
+func (s sampler) ShouldSample(params sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	parent := trace.SpanContextFromContext(params.ParentContext)
+	if parent.IsValid() {
+		decision := sdktrace.Drop
+		if parent.IsSampled() {
+			decision = sdktrace.RecordAndSample
+		}
+		return sdktrace.SamplingResult{Decision: decision, Tracestate: parent.TraceState()}
+	}
+
+	if address := s.p.address.Load(); address != "" {
+		if state, err := (trace.TraceState{}).Insert(originKey, address); err == nil {
+			return sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample, Tracestate: state}
+		}
+	}
+	return s.p.ratio.ShouldSample(params)
+}
+
+func (s sampler) Description() string {
+	return "TailSampling{" + s.p.ratio.Description() + "}"
+}
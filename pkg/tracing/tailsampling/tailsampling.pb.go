@@ -0,0 +1,799 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: tailsampling.proto
+
+package tailsampling
+
+import (
+	bytes "bytes"
+	context "context"
+	fmt "fmt"
+	_ "github.com/gogo/protobuf/gogoproto"
+	proto "github.com/gogo/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+	reflect "reflect"
+	strings "strings"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+type DecisionsRequest struct {
+	TraceIDs [][]byte `protobuf:"bytes,1,rep,name=traceIDs,proto3" json:"traceIDs,omitempty"`
+}
+
+func (m *DecisionsRequest) Reset()      { *m = DecisionsRequest{} }
+func (*DecisionsRequest) ProtoMessage() {}
+func (*DecisionsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e0f24c6dadf64e68, []int{0}
+}
+func (m *DecisionsRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DecisionsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DecisionsRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DecisionsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DecisionsRequest.Merge(m, src)
+}
+func (m *DecisionsRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *DecisionsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DecisionsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DecisionsRequest proto.InternalMessageInfo
+
+func (m *DecisionsRequest) GetTraceIDs() [][]byte {
+	if m != nil {
+		return m.TraceIDs
+	}
+	return nil
+}
+
+type DecisionsResponse struct {
+	// The traces to keep.
+	KeptTraceIDs [][]byte `protobuf:"bytes,1,rep,name=keptTraceIDs,proto3" json:"keptTraceIDs,omitempty"`
+	// The traces whose query is still running, so the decision isn't taken yet.
+	// The other traces are dropped.
+	PendingTraceIDs [][]byte `protobuf:"bytes,2,rep,name=pendingTraceIDs,proto3" json:"pendingTraceIDs,omitempty"`
+}
+
+func (m *DecisionsResponse) Reset()      { *m = DecisionsResponse{} }
+func (*DecisionsResponse) ProtoMessage() {}
+func (*DecisionsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e0f24c6dadf64e68, []int{1}
+}
+func (m *DecisionsResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DecisionsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DecisionsResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DecisionsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DecisionsResponse.Merge(m, src)
+}
+func (m *DecisionsResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *DecisionsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DecisionsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DecisionsResponse proto.InternalMessageInfo
+
+func (m *DecisionsResponse) GetKeptTraceIDs() [][]byte {
+	if m != nil {
+		return m.KeptTraceIDs
+	}
+	return nil
+}
+
+func (m *DecisionsResponse) GetPendingTraceIDs() [][]byte {
+	if m != nil {
+		return m.PendingTraceIDs
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*DecisionsRequest)(nil), "tailsampling.DecisionsRequest")
+	proto.RegisterType((*DecisionsResponse)(nil), "tailsampling.DecisionsResponse")
+}
+
+func init() { proto.RegisterFile("tailsampling.proto", fileDescriptor_e0f24c6dadf64e68) }
+
+var fileDescriptor_e0f24c6dadf64e68 = []byte{
+	// 241 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x2a, 0x49, 0xcc, 0xcc,
+	0x29, 0x4e, 0xcc, 0x2d, 0xc8, 0xc9, 0xcc, 0x4b, 0xd7, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2,
+	0x41, 0x16, 0x93, 0x12, 0x49, 0xcf, 0x4f, 0xcf, 0x07, 0x4b, 0xe8, 0x83, 0x58, 0x10, 0x35, 0x4a,
+	0x7a, 0x5c, 0x02, 0x2e, 0xa9, 0xc9, 0x99, 0xc5, 0x99, 0xf9, 0x79, 0xc5, 0x41, 0xa9, 0x85, 0xa5,
+	0xa9, 0xc5, 0x25, 0x42, 0x52, 0x5c, 0x1c, 0x25, 0x45, 0x89, 0xc9, 0xa9, 0x9e, 0x2e, 0xc5, 0x12,
+	0x8c, 0x0a, 0xcc, 0x1a, 0x3c, 0x41, 0x70, 0xbe, 0x52, 0x22, 0x97, 0x20, 0x92, 0xfa, 0xe2, 0x82,
+	0xfc, 0xbc, 0xe2, 0x54, 0x21, 0x25, 0x2e, 0x9e, 0xec, 0xd4, 0x82, 0x92, 0x10, 0x54, 0x4d, 0x28,
+	0x62, 0x42, 0x1a, 0x5c, 0xfc, 0x05, 0xa9, 0x79, 0x29, 0x99, 0x79, 0xe9, 0x70, 0x65, 0x4c, 0x60,
+	0x65, 0xe8, 0xc2, 0x46, 0x71, 0x5c, 0x3c, 0x21, 0x89, 0x99, 0x39, 0xc1, 0x50, 0x87, 0x0b, 0xf9,
+	0x71, 0x71, 0xc2, 0xad, 0x14, 0x92, 0xd3, 0x43, 0xf1, 0x28, 0xba, 0xdb, 0xa5, 0xe4, 0x71, 0xca,
+	0x43, 0xdc, 0xaa, 0xc4, 0xe0, 0xe4, 0x74, 0xe1, 0xa1, 0x1c, 0xc3, 0x8d, 0x87, 0x72, 0x0c, 0x1f,
+	0x1e, 0xca, 0x31, 0x36, 0x3c, 0x92, 0x63, 0x5c, 0xf1, 0x48, 0x8e, 0xf1, 0xc4, 0x23, 0x39, 0xc6,
+	0x0b, 0x8f, 0xe4, 0x18, 0x1f, 0x3c, 0x92, 0x63, 0x7c, 0xf1, 0x48, 0x8e, 0xe1, 0xc3, 0x23, 0x39,
+	0xc6, 0x09, 0x8f, 0xe5, 0x18, 0x2e, 0x3c, 0x96, 0x63, 0xb8, 0xf1, 0x58, 0x8e, 0x21, 0x0a, 0x25,
+	0x30, 0x93, 0xd8, 0xc0, 0xa1, 0x67, 0x0c, 0x18, 0x00, 0xc2, 0xa5, 0x68, 0x06, 0x77, 0x01, 0x00,
+	0x00,
+}
+
+func (this *DecisionsRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*DecisionsRequest)
+	if !ok {
+		that2, ok := that.(DecisionsRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.TraceIDs) != len(that1.TraceIDs) {
+		return false
+	}
+	for i := range this.TraceIDs {
+		if !bytes.Equal(this.TraceIDs[i], that1.TraceIDs[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *DecisionsResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*DecisionsResponse)
+	if !ok {
+		that2, ok := that.(DecisionsResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.KeptTraceIDs) != len(that1.KeptTraceIDs) {
+		return false
+	}
+	for i := range this.KeptTraceIDs {
+		if !bytes.Equal(this.KeptTraceIDs[i], that1.KeptTraceIDs[i]) {
+			return false
+		}
+	}
+	if len(this.PendingTraceIDs) != len(that1.PendingTraceIDs) {
+		return false
+	}
+	for i := range this.PendingTraceIDs {
+		if !bytes.Equal(this.PendingTraceIDs[i], that1.PendingTraceIDs[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *DecisionsRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&tailsampling.DecisionsRequest{")
+	s = append(s, "TraceIDs: "+fmt.Sprintf("%#v", this.TraceIDs)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *DecisionsResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&tailsampling.DecisionsResponse{")
+	s = append(s, "KeptTraceIDs: "+fmt.Sprintf("%#v", this.KeptTraceIDs)+",\n")
+	s = append(s, "PendingTraceIDs: "+fmt.Sprintf("%#v", this.PendingTraceIDs)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func valueToGoStringTailsampling(v interface{}, typ string) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("func(v %v) *%v { return &v } ( %#v )", typ, typ, pv)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// TailSamplingClient is the client API for TailSampling service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TailSamplingClient interface {
+	Decisions(ctx context.Context, in *DecisionsRequest, opts ...grpc.CallOption) (*DecisionsResponse, error)
+}
+
+type tailSamplingClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTailSamplingClient(cc *grpc.ClientConn) TailSamplingClient {
+	return &tailSamplingClient{cc}
+}
+
+func (c *tailSamplingClient) Decisions(ctx context.Context, in *DecisionsRequest, opts ...grpc.CallOption) (*DecisionsResponse, error) {
+	out := new(DecisionsResponse)
+	err := c.cc.Invoke(ctx, "/tailsampling.TailSampling/Decisions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TailSamplingServer is the server API for TailSampling service.
+type TailSamplingServer interface {
+	Decisions(context.Context, *DecisionsRequest) (*DecisionsResponse, error)
+}
+
+// UnimplementedTailSamplingServer can be embedded to have forward compatible implementations.
+type UnimplementedTailSamplingServer struct {
+}
+
+func (*UnimplementedTailSamplingServer) Decisions(ctx context.Context, req *DecisionsRequest) (*DecisionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Decisions not implemented")
+}
+
+func RegisterTailSamplingServer(s *grpc.Server, srv TailSamplingServer) {
+	s.RegisterService(&_TailSampling_serviceDesc, srv)
+}
+
+func _TailSampling_Decisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DecisionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TailSamplingServer).Decisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tailsampling.TailSampling/Decisions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TailSamplingServer).Decisions(ctx, req.(*DecisionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _TailSampling_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "tailsampling.TailSampling",
+	HandlerType: (*TailSamplingServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Decisions",
+			Handler:    _TailSampling_Decisions_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "tailsampling.proto",
+}
+
+func (m *DecisionsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DecisionsRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DecisionsRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.TraceIDs) > 0 {
+		for iNdEx := len(m.TraceIDs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.TraceIDs[iNdEx])
+			copy(dAtA[i:], m.TraceIDs[iNdEx])
+			i = encodeVarintTailsampling(dAtA, i, uint64(len(m.TraceIDs[iNdEx])))
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DecisionsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DecisionsResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DecisionsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.PendingTraceIDs) > 0 {
+		for iNdEx := len(m.PendingTraceIDs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.PendingTraceIDs[iNdEx])
+			copy(dAtA[i:], m.PendingTraceIDs[iNdEx])
+			i = encodeVarintTailsampling(dAtA, i, uint64(len(m.PendingTraceIDs[iNdEx])))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.KeptTraceIDs) > 0 {
+		for iNdEx := len(m.KeptTraceIDs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.KeptTraceIDs[iNdEx])
+			copy(dAtA[i:], m.KeptTraceIDs[iNdEx])
+			i = encodeVarintTailsampling(dAtA, i, uint64(len(m.KeptTraceIDs[iNdEx])))
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintTailsampling(dAtA []byte, offset int, v uint64) int {
+	offset -= sovTailsampling(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *DecisionsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.TraceIDs) > 0 {
+		for _, b := range m.TraceIDs {
+			l = len(b)
+			n += 1 + l + sovTailsampling(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *DecisionsResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.KeptTraceIDs) > 0 {
+		for _, b := range m.KeptTraceIDs {
+			l = len(b)
+			n += 1 + l + sovTailsampling(uint64(l))
+		}
+	}
+	if len(m.PendingTraceIDs) > 0 {
+		for _, b := range m.PendingTraceIDs {
+			l = len(b)
+			n += 1 + l + sovTailsampling(uint64(l))
+		}
+	}
+	return n
+}
+
+func sovTailsampling(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozTailsampling(x uint64) (n int) {
+	return sovTailsampling(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *DecisionsRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&DecisionsRequest{`,
+		`TraceIDs:` + fmt.Sprintf("%v", this.TraceIDs) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *DecisionsResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&DecisionsResponse{`,
+		`KeptTraceIDs:` + fmt.Sprintf("%v", this.KeptTraceIDs) + `,`,
+		`PendingTraceIDs:` + fmt.Sprintf("%v", this.PendingTraceIDs) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func valueToStringTailsampling(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("*%v", pv)
+}
+func (m *DecisionsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTailsampling
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DecisionsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DecisionsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceIDs", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTailsampling
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTailsampling
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTailsampling
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TraceIDs = append(m.TraceIDs, make([]byte, postIndex-iNdEx))
+			copy(m.TraceIDs[len(m.TraceIDs)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTailsampling(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTailsampling
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTailsampling
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DecisionsResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTailsampling
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DecisionsResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DecisionsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field KeptTraceIDs", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTailsampling
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTailsampling
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTailsampling
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.KeptTraceIDs = append(m.KeptTraceIDs, make([]byte, postIndex-iNdEx))
+			copy(m.KeptTraceIDs[len(m.KeptTraceIDs)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PendingTraceIDs", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTailsampling
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTailsampling
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTailsampling
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PendingTraceIDs = append(m.PendingTraceIDs, make([]byte, postIndex-iNdEx))
+			copy(m.PendingTraceIDs[len(m.PendingTraceIDs)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTailsampling(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTailsampling
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTailsampling
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipTailsampling(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowTailsampling
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowTailsampling
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+			return iNdEx, nil
+		case 1:
+			iNdEx += 8
+			return iNdEx, nil
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowTailsampling
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthTailsampling
+			}
+			iNdEx += length
+			if iNdEx < 0 {
+				return 0, ErrInvalidLengthTailsampling
+			}
+			return iNdEx, nil
+		case 3:
+			for {
+				var innerWire uint64
+				var start int = iNdEx
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return 0, ErrIntOverflowTailsampling
+					}
+					if iNdEx >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					innerWire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				innerWireType := int(innerWire & 0x7)
+				if innerWireType == 4 {
+					break
+				}
+				next, err := skipTailsampling(dAtA[start:])
+				if err != nil {
+					return 0, err
+				}
+				iNdEx = start + next
+				if iNdEx < 0 {
+					return 0, ErrInvalidLengthTailsampling
+				}
+			}
+			return iNdEx, nil
+		case 4:
+			return iNdEx, nil
+		case 5:
+			iNdEx += 4
+			return iNdEx, nil
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+	}
+	panic("unreachable")
+}
+
+var (
+	ErrInvalidLengthTailsampling = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowTailsampling   = fmt.Errorf("proto: integer overflow")
+)
@@ -8,6 +8,7 @@ import (
 
 	"github.com/go-kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sercand/kuberesolver/v5"
 	"github.com/weaveworks/common/tracing"
 	"go.opentelemetry.io/contrib/propagators/aws/xray"
@@ -23,6 +24,7 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 
 	"github.com/cortexproject/cortex/pkg/tracing/migration"
+	"github.com/cortexproject/cortex/pkg/tracing/tailsampling"
 	util_log "github.com/cortexproject/cortex/pkg/util/log"
 	"github.com/cortexproject/cortex/pkg/util/tls"
 )
@@ -45,6 +47,17 @@ type Otel struct {
 	TLSEnabled     bool                `yaml:"tls_enabled"`
 	TLS            tls.ClientConfig    `yaml:"tls"`
 	ExtraDetectors []resource.Detector `yaml:"-"`
+
+	TailSampling tailsampling.Config `yaml:"tail_sampling"`
+}
+
+// tailSampler is the processor buffering the spans of the deferred traces, when the tail sampling is enabled.
+var tailSampler *tailsampling.Processor
+
+// TailSampler returns the processor buffering the spans of the deferred traces, or nil if the tail sampling
+// isn't enabled.
+func TailSampler() *tailsampling.Processor {
+	return tailSampler
 }
 
 // RegisterFlags registers flag.
@@ -57,6 +70,7 @@ func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&c.Otel.TLSEnabled, p+".otel.tls-enabled", c.Otel.TLSEnabled, "Enable TLS in the GRPC client. This flag needs to be enabled when any other TLS flag is set. If set to false, insecure connection to gRPC server will be used.")
 	f.BoolVar(&c.Otel.RoundRobin, p+".otel.round-robin", false, "If enabled, use round_robin gRPC load balancing policy. By default, use pick_first policy. For more details, please refer to https://github.com/grpc/grpc/blob/master/doc/load-balancing.md#load-balancing-policies.")
 	c.Otel.TLS.RegisterFlagsWithPrefix(p+".otel.tls", f)
+	c.Otel.TailSampling.RegisterFlagsWithPrefix(p+".otel.tail-sampling", f)
 }
 
 func (c *Config) Validate() error {
@@ -65,6 +79,12 @@ func (c *Config) Validate() error {
 		if c.Otel.OtlpEndpoint == "" {
 			return errors.New("otlp-endpoint must be defined when using otel exporter")
 		}
+		if c.Otel.TailSampling.Enabled && strings.ToLower(c.Otel.ExporterType) == "awsxray" {
+			return errors.New("tail sampling is not supported with the awsxray exporter type, as its propagator doesn't propagate the tracestate")
+		}
+		if err := c.Otel.TailSampling.Validate(); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -137,7 +157,6 @@ func SetupTracing(ctx context.Context, name string, c Config) (func(context.Cont
 
 func newTraceProvider(r *resource.Resource, c Config, exporter *otlptrace.Exporter) (propagation.TextMapPropagator, *sdktrace.TracerProvider) {
 	options := []sdktrace.TracerProviderOption{
-		sdktrace.WithBatcher(exporter),
 		sdktrace.WithResource(r),
 	}
 	var propagator propagation.TextMapPropagator = propagation.TraceContext{}
@@ -148,7 +167,12 @@ func newTraceProvider(r *resource.Resource, c Config, exporter *otlptrace.Export
 	default:
 	}
 
-	options = append(options, sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.Otel.SampleRatio))))
+	if c.Otel.TailSampling.Enabled {
+		tailSampler = tailsampling.NewProcessor(c.Otel.TailSampling, c.Otel.SampleRatio, sdktrace.NewBatchSpanProcessor(exporter), util_log.Logger, prometheus.DefaultRegisterer)
+		options = append(options, sdktrace.WithSpanProcessor(tailSampler), sdktrace.WithSampler(tailSampler.Sampler()))
+	} else {
+		options = append(options, sdktrace.WithBatcher(exporter), sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.Otel.SampleRatio))))
+	}
 
 	return propagator, sdktrace.NewTracerProvider(options...)
 }
@@ -3,9 +3,12 @@ package tracing
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+
+	"github.com/cortexproject/cortex/pkg/util/flagext"
 )
 
 func TestNewResource(t *testing.T) {
@@ -19,3 +22,19 @@ func TestNewResource(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, name.AsString(), target)
 }
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := Config{}
+	flagext.DefaultValues(&cfg)
+	cfg.Type = OtelType
+	cfg.Otel.OtlpEndpoint = "collector:4317"
+	cfg.Otel.TailSampling.Enabled = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.Otel.TailSampling.MaxDecisionWait = cfg.Otel.TailSampling.DecisionWait - time.Second
+	require.EqualError(t, cfg.Validate(), "the tail sampling max decision wait must be greater than or equal to the decision wait")
+
+	cfg.Otel.TailSampling.MaxDecisionWait = cfg.Otel.TailSampling.DecisionWait
+	cfg.Otel.ExporterType = "awsxray"
+	require.EqualError(t, cfg.Validate(), "tail sampling is not supported with the awsxray exporter type, as its propagator doesn't propagate the tracestate")
+}
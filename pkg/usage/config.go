@@ -0,0 +1,48 @@
+package usage
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	errInvalidFlushPeriod = errors.New("the usage tracking flush period must be greater than 0")
+	errMissingInstanceID  = errors.New("the usage tracking instance ID must be set")
+)
+
+// Config holds the usage tracking config.
+type Config struct {
+	Enabled     bool          `yaml:"enabled"`
+	FlushPeriod time.Duration `yaml:"flush_period"`
+	InstanceID  string        `yaml:"instance_id" doc:"hidden"`
+}
+
+// RegisterFlags registers the usage tracking flags.
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		panic(fmt.Errorf("failed to get hostname %s", err))
+	}
+
+	f.BoolVar(&cfg.Enabled, "usage-tracking.enabled", false, "If true, the distributors, query-frontends and rulers track the samples ingested, the bytes fetched by the queries and the rule evaluations of each tenant, and periodically write them to the usage reports of the tenant in the blocks storage. The usage API then reports them, in addition to the storage used by the tenant.")
+	f.DurationVar(&cfg.FlushPeriod, "usage-tracking.flush-period", time.Minute, "How frequently the tracked usage is written to the blocks storage. The usage tracked since the last write is lost if the process crashes.")
+	f.StringVar(&cfg.InstanceID, "usage-tracking.instance-id", hostname, "ID of the instance in the name of the usage reports it writes. Each instance must have a unique ID, which should be stable across restarts so that the reports keep being written to the same objects.")
+}
+
+// Validate the config.
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.FlushPeriod <= 0 {
+		return errInvalidFlushPeriod
+	}
+	if cfg.InstanceID == "" {
+		return errMissingInstanceID
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/thanos-io/objstore"
+)
+
+const (
+	// UsagePrefix is the prefix of the usage reports in the bucket of a tenant.
+	UsagePrefix = "usage"
+
+	// DayFormat is the format of the days the usage is reported by, in UTC.
+	DayFormat = "2006-01-02"
+)
+
+// Usage is the usage of a tenant tracked by the Cortex components.
+type Usage struct {
+	SamplesIngested int64 `json:"samples_ingested"`
+	QueriedBytes    int64 `json:"queried_bytes"`
+	RuleEvaluations int64 `json:"rule_evaluations"`
+}
+
+// Add adds the input usage to the usage.
+func (u *Usage) Add(o Usage) {
+	u.SamplesIngested += o.SamplesIngested
+	u.QueriedBytes += o.QueriedBytes
+	u.RuleEvaluations += o.RuleEvaluations
+}
+
+// DayUsage is the usage of a tenant during a day.
+type DayUsage struct {
+	Day string `json:"day"`
+	Usage
+}
+
+// reportPath returns the path of the usage report of an instance for a day, relative to the bucket of the tenant.
+func reportPath(day, instanceID string) string {
+	return path.Join(UsagePrefix, day, instanceID+".json")
+}
+
+func readReport(ctx context.Context, userBkt objstore.BucketReader, name string) (Usage, error) {
+	r, err := userBkt.Get(ctx, name)
+	if err != nil {
+		return Usage{}, err
+	}
+	defer func() { _ = r.Close() }()
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	u := Usage{}
+	if err := json.Unmarshal(content, &u); err != nil {
+		return Usage{}, errors.Wrapf(err, "parse usage report %s", name)
+	}
+	return u, nil
+}
+
+// ReadUsage returns the usage of a tenant by day, from the start day to the end day included, summing the
+// reports of all the instances. The days without any report are not returned.
+func ReadUsage(ctx context.Context, userBkt objstore.BucketReader, start, end time.Time) ([]DayUsage, error) {
+	startDay, endDay := start.UTC().Format(DayFormat), end.UTC().Format(DayFormat)
+
+	// The days are sorted the same way as their names.
+	var days []string
+	err := userBkt.Iter(ctx, UsagePrefix, func(name string) error {
+		day := path.Base(name)
+		if day >= startDay && day <= endDay {
+			days = append(days, day)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list usage reports")
+	}
+	slices.Sort(days)
+
+	out := make([]DayUsage, 0, len(days))
+	for _, day := range days {
+		u := DayUsage{Day: day}
+		err := userBkt.Iter(ctx, path.Join(UsagePrefix, day), func(name string) error {
+			if !strings.HasSuffix(name, ".json") {
+				return nil
+			}
+
+			report, err := readReport(ctx, userBkt, name)
+			if userBkt.IsObjNotFoundErr(err) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			u.Add(report)
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "read usage reports of %s", day)
+		}
+		out = append(out, u)
+	}
+
+	return out, nil
+}
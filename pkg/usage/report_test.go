@@ -0,0 +1,46 @@
+package usage
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+)
+
+func TestReadUsage(t *testing.T) {
+	ctx := context.Background()
+	bkt := objstore.NewInMemBucket()
+
+	for name, content := range map[string]string{
+		"usage/2026-09-30/instance-1.json": `{"samples_ingested":1}`,
+		"usage/2026-10-01/instance-1.json": `{"samples_ingested":10,"queried_bytes":100}`,
+		"usage/2026-10-01/instance-2.json": `{"samples_ingested":20,"rule_evaluations":3}`,
+		"usage/2026-10-01/unknown":         `invalid`,
+		"usage/2026-10-03/instance-1.json": `{"queried_bytes":5}`,
+		"usage/2026-10-04/instance-1.json": `{"queried_bytes":7}`,
+	} {
+		require.NoError(t, bkt.Upload(ctx, name, strings.NewReader(content)))
+	}
+
+	// The usage of the instances is summed by day, within the range of days.
+	usage, err := ReadUsage(ctx, bkt, time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC), time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []DayUsage{
+		{Day: "2026-10-01", Usage: Usage{SamplesIngested: 30, QueriedBytes: 100, RuleEvaluations: 3}},
+		{Day: "2026-10-03", Usage: Usage{QueriedBytes: 5}},
+	}, usage)
+
+	// An invalid report fails the read.
+	require.NoError(t, bkt.Upload(ctx, "usage/2026-10-03/instance-2.json", strings.NewReader("invalid")))
+	_, err = ReadUsage(ctx, bkt, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC))
+	require.Error(t, err)
+
+	// A tenant without reports has no usage.
+	usage, err = ReadUsage(ctx, objstore.NewInMemBucket(), time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Empty(t, usage)
+}
@@ -0,0 +1,183 @@
+package usage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/thanos-io/objstore"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/util/services"
+)
+
+type reportKey struct {
+	userID string
+	day    string
+}
+
+// Tracker tracks the usage of the tenants, and periodically adds it to the usage reports of the
+// instance in the blocks storage, one per tenant and day. A nil Tracker doesn't track anything.
+type Tracker struct {
+	services.Service
+
+	cfg          Config
+	bucketClient objstore.Bucket
+	cfgProvider  bucket.TenantConfigProvider
+	logger       log.Logger
+	now          func() time.Time
+
+	mtx     sync.Mutex
+	pending map[reportKey]*Usage
+
+	// reports are the usage reports written by the instance. They're read from the bucket the first
+	// time they're written after the instance started, and are only accessed while flushing.
+	reports map[reportKey]Usage
+
+	writeFailures prometheus.Counter
+}
+
+// NewTracker makes a new Tracker writing the usage reports to the blocks storage.
+func NewTracker(cfg Config, storageCfg cortex_tsdb.BlocksStorageConfig, cfgProvider bucket.TenantConfigProvider, logger log.Logger, reg prometheus.Registerer) (*Tracker, error) {
+	bucketClient, err := bucket.NewClient(context.Background(), storageCfg.Bucket, nil, "usage-tracker", logger, reg)
+	if err != nil {
+		return nil, errors.Wrap(err, "create bucket client")
+	}
+
+	return newTracker(cfg, bucketClient, cfgProvider, logger, reg), nil
+}
+
+func newTracker(cfg Config, bkt objstore.Bucket, cfgProvider bucket.TenantConfigProvider, logger log.Logger, reg prometheus.Registerer) *Tracker {
+	t := &Tracker{
+		cfg:          cfg,
+		bucketClient: bkt,
+		cfgProvider:  cfgProvider,
+		logger:       logger,
+		now:          time.Now,
+		pending:      map[reportKey]*Usage{},
+		reports:      map[reportKey]Usage{},
+
+		writeFailures: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_usage_tracker_report_write_failures_total",
+			Help: "Total number of failures writing the usage reports. The usage is written again at the next flush.",
+		}),
+	}
+	t.Service = services.NewTimerService(cfg.FlushPeriod, nil, t.iteration, t.stopping)
+	return t
+}
+
+// AddSamplesIngested tracks the samples ingested for the tenant.
+func (t *Tracker) AddSamplesIngested(userID string, samples int) {
+	t.add(userID, Usage{SamplesIngested: int64(samples)})
+}
+
+// AddQueriedBytes tracks the bytes fetched by a query of the tenant.
+func (t *Tracker) AddQueriedBytes(userID string, fetchedBytes uint64) {
+	t.add(userID, Usage{QueriedBytes: int64(fetchedBytes)})
+}
+
+// AddRuleEvaluations tracks the rule evaluations of the tenant.
+func (t *Tracker) AddRuleEvaluations(userID string, evaluations int) {
+	t.add(userID, Usage{RuleEvaluations: int64(evaluations)})
+}
+
+func (t *Tracker) add(userID string, u Usage) {
+	if t == nil || u == (Usage{}) {
+		return
+	}
+
+	key := reportKey{userID: userID, day: t.now().UTC().Format(DayFormat)}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	p, ok := t.pending[key]
+	if !ok {
+		p = &Usage{}
+		t.pending[key] = p
+	}
+	p.Add(u)
+}
+
+// ReadUsage returns the usage of a tenant by day, from the start day to the end day included, as
+// written by all the instances. The usage tracked since the last flush of each instance is missing.
+func (t *Tracker) ReadUsage(ctx context.Context, userID string, start, end time.Time) ([]DayUsage, error) {
+	return ReadUsage(ctx, bucket.NewUserBucketClient(userID, t.bucketClient, t.cfgProvider), start, end)
+}
+
+func (t *Tracker) iteration(ctx context.Context) error {
+	t.flush(ctx)
+	return nil
+}
+
+func (t *Tracker) stopping(_ error) error {
+	t.flush(context.Background())
+	return nil
+}
+
+// flush adds the usage tracked since the last flush to the usage reports.
+func (t *Tracker) flush(ctx context.Context) {
+	t.mtx.Lock()
+	pending := t.pending
+	t.pending = map[reportKey]*Usage{}
+	t.mtx.Unlock()
+
+	for key, u := range pending {
+		if err := t.write(ctx, key, *u); err != nil {
+			level.Warn(t.logger).Log("msg", "failed to write the usage report", "user", key.userID, "day", key.day, "err", err)
+			t.writeFailures.Inc()
+
+			// Keep the usage for the next flush.
+			t.mtx.Lock()
+			p, ok := t.pending[key]
+			if !ok {
+				p = &Usage{}
+				t.pending[key] = p
+			}
+			p.Add(*u)
+			t.mtx.Unlock()
+		}
+	}
+
+	// The reports of the previous days are read again if some usage is still written to them.
+	today := t.now().UTC().Format(DayFormat)
+	for key := range t.reports {
+		if key.day < today {
+			delete(t.reports, key)
+		}
+	}
+}
+
+func (t *Tracker) write(ctx context.Context, key reportKey, u Usage) error {
+	userBkt := bucket.NewUserBucketClient(key.userID, t.bucketClient, t.cfgProvider)
+	name := reportPath(key.day, t.cfg.InstanceID)
+
+	// The report may have been written before the instance restarted.
+	report, ok := t.reports[key]
+	if !ok {
+		var err error
+		if report, err = readReport(ctx, userBkt, name); err != nil && !userBkt.IsObjNotFoundErr(err) {
+			return err
+		}
+	}
+	report.Add(u)
+
+	content, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	if err := userBkt.Upload(ctx, name, bytes.NewReader(content)); err != nil {
+		return err
+	}
+
+	t.reports[key] = report
+	return nil
+}
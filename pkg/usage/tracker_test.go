@@ -0,0 +1,91 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+)
+
+func TestTracker_Flush(t *testing.T) {
+	ctx := context.Background()
+	bkt := objstore.NewInMemBucket()
+	cfg := Config{Enabled: true, FlushPeriod: time.Minute, InstanceID: "instance-1"}
+
+	now := time.Date(2026, 10, 19, 23, 59, 0, 0, time.UTC)
+	tracker := newTracker(cfg, bkt, nil, log.NewNopLogger(), prometheus.NewPedanticRegistry())
+	tracker.now = func() time.Time { return now }
+
+	tracker.AddSamplesIngested("user-1", 100)
+	tracker.AddQueriedBytes("user-1", 1000)
+	tracker.AddRuleEvaluations("user-2", 1)
+	tracker.flush(ctx)
+
+	tracker.AddSamplesIngested("user-1", 50)
+	now = now.Add(time.Minute)
+	tracker.AddSamplesIngested("user-1", 10)
+	tracker.flush(ctx)
+
+	// The usage is added to the report of the instance for each tenant and day.
+	usage, err := tracker.ReadUsage(ctx, "user-1", now.Add(-24*time.Hour), now)
+	require.NoError(t, err)
+	assert.Equal(t, []DayUsage{
+		{Day: "2026-10-19", Usage: Usage{SamplesIngested: 150, QueriedBytes: 1000}},
+		{Day: "2026-10-20", Usage: Usage{SamplesIngested: 10}},
+	}, usage)
+
+	usage, err = tracker.ReadUsage(ctx, "user-2", now.Add(-24*time.Hour), now)
+	require.NoError(t, err)
+	assert.Equal(t, []DayUsage{{Day: "2026-10-19", Usage: Usage{RuleEvaluations: 1}}}, usage)
+
+	// A restarted instance keeps adding the usage to its reports.
+	restarted := newTracker(cfg, bkt, nil, log.NewNopLogger(), prometheus.NewPedanticRegistry())
+	restarted.now = func() time.Time { return now }
+	restarted.AddSamplesIngested("user-1", 5)
+	restarted.flush(ctx)
+
+	usage, err = restarted.ReadUsage(ctx, "user-1", now, now)
+	require.NoError(t, err)
+	assert.Equal(t, []DayUsage{{Day: "2026-10-20", Usage: Usage{SamplesIngested: 15}}}, usage)
+}
+
+func TestTracker_FlushFailure(t *testing.T) {
+	ctx := context.Background()
+	bkt := &bucket.ClientMock{}
+	bkt.MockGet("user-1/usage/2026-10-19/instance-1.json", "", nil)
+	bkt.On("Upload", mock.Anything, "user-1/usage/2026-10-19/instance-1.json", mock.Anything).Return(errors.New("failed to upload")).Once()
+	bkt.On("Upload", mock.Anything, "user-1/usage/2026-10-19/instance-1.json", mock.Anything).Return(nil).Once()
+
+	reg := prometheus.NewPedanticRegistry()
+	tracker := newTracker(Config{Enabled: true, FlushPeriod: time.Minute, InstanceID: "instance-1"}, bkt, nil, log.NewNopLogger(), reg)
+	tracker.now = func() time.Time { return time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC) }
+
+	// The usage which fails to be written is kept for the next flush.
+	tracker.AddSamplesIngested("user-1", 100)
+	tracker.flush(ctx)
+	assert.Equal(t, map[reportKey]*Usage{{userID: "user-1", day: "2026-10-19"}: {SamplesIngested: 100}}, tracker.pending)
+	assert.Equal(t, float64(1), testutil.ToFloat64(tracker.writeFailures))
+
+	tracker.flush(ctx)
+	assert.Empty(t, tracker.pending)
+	assert.Equal(t, Usage{SamplesIngested: 100}, tracker.reports[reportKey{userID: "user-1", day: "2026-10-19"}])
+}
+
+func TestTracker_Nil(t *testing.T) {
+	var tracker *Tracker
+
+	// A nil tracker doesn't track anything.
+	tracker.AddSamplesIngested("user-1", 100)
+	tracker.AddQueriedBytes("user-1", 1000)
+	tracker.AddRuleEvaluations("user-1", 1)
+}
@@ -49,6 +49,12 @@ func RespondFromGRPCError(logger log.Logger, w http.ResponseWriter, err error) {
 		default:
 			errTyp = v1.ErrServer
 		}
+		// Let the client know when to retry, if the error tells it.
+		for _, h := range resp.Headers {
+			if http.CanonicalHeaderKey(h.Key) == "Retry-After" {
+				w.Header()["Retry-After"] = h.Values
+			}
+		}
 		RespondError(logger, w, errTyp, string(resp.Body), code)
 	} else {
 		RespondError(logger, w, v1.ErrServer, err.Error(), http.StatusInternalServerError)
@@ -22,6 +22,8 @@ func TestRespondFromGRPCError(t *testing.T) {
 		err          error
 		expectedResp *Response
 		code         int
+
+		expectedRetryAfter string
 	}{
 		{
 			name: "non grpc error",
@@ -103,6 +105,21 @@ func TestRespondFromGRPCError(t *testing.T) {
 			},
 			code: http.StatusTooManyRequests,
 		},
+		{
+			name: "retry after header",
+			err: httpgrpc.ErrorFromHTTPResponse(&httpgrpc.HTTPResponse{
+				Code:    http.StatusTooManyRequests,
+				Headers: []*httpgrpc.Header{{Key: "Retry-After", Values: []string{"5"}}, {Key: "X-Other", Values: []string{"value"}}},
+				Body:    []byte("bad_data"),
+			}),
+			expectedResp: &Response{
+				Status:    "error",
+				ErrorType: v1.ErrServer,
+				Error:     "bad_data",
+			},
+			code:               http.StatusTooManyRequests,
+			expectedRetryAfter: "5",
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			writer := httptest.NewRecorder()
@@ -118,6 +135,8 @@ func TestRespondFromGRPCError(t *testing.T) {
 			require.Equal(t, tc.expectedResp.ErrorType, res.ErrorType)
 
 			require.Equal(t, tc.code, writer.Code)
+			require.Equal(t, tc.expectedRetryAfter, writer.Header().Get("Retry-After"))
+			require.Empty(t, writer.Header().Get("X-Other"))
 		})
 	}
 }
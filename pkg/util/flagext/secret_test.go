@@ -1,6 +1,7 @@
 package flagext
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -75,3 +76,22 @@ func TestSecretdYAML(t *testing.T) {
 		assert.Equal(t, testStruct, actualStruct)
 	}
 }
+
+func TestSecretJSON(t *testing.T) {
+	type TestStruct struct {
+		Secret Secret `json:"secret"`
+	}
+
+	testStruct := TestStruct{Secret: Secret{Value: "pa55w0rd"}}
+	actual, err := json.Marshal(testStruct)
+	require.NoError(t, err)
+	assert.Equal(t, `{"secret":"********"}`, string(actual))
+
+	var actualStruct TestStruct
+	require.NoError(t, json.Unmarshal([]byte(`{"secret":"pa55w0rd"}`), &actualStruct))
+	assert.Equal(t, testStruct, actualStruct)
+
+	actual, err = json.Marshal(TestStruct{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"secret":""}`, string(actual))
+}
@@ -47,6 +47,29 @@ func (l *RateLimiter) AllowN(now time.Time, tenantID string, n int) bool {
 	return l.getTenantLimiter(now, tenantID).AllowN(now, n)
 }
 
+// AllowAllN reports whether n tokens may be consumed at time now by each of the input tenants,
+// and only consumes them if all the tenants allow it. Otherwise, it returns the indexes of the
+// tenants which don't allow it, and no token is consumed.
+func (l *RateLimiter) AllowAllN(now time.Time, tenantIDs []string, n int) (denied []int) {
+	reservations := make([]*rate.Reservation, 0, len(tenantIDs))
+	for i, tenantID := range tenantIDs {
+		r := l.getTenantLimiter(now, tenantID).ReserveN(now, n)
+		if !r.OK() || r.DelayFrom(now) > 0 {
+			denied = append(denied, i)
+		}
+		reservations = append(reservations, r)
+	}
+
+	if len(denied) > 0 {
+		// Cancel the reservations in reverse order, so that the tokens are fully restored
+		// even if the same tenant is repeated.
+		for i := len(reservations) - 1; i >= 0; i-- {
+			reservations[i].CancelAt(now)
+		}
+	}
+	return denied
+}
+
 // Limit returns the currently configured maximum overall tokens rate.
 func (l *RateLimiter) Limit(now time.Time, tenantID string) float64 {
 	return float64(l.getTenantLimiter(now, tenantID).Limit())
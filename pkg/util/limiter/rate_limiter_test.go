@@ -65,6 +65,31 @@ func TestRateLimiter_AllowN(t *testing.T) {
 	assert.Equal(t, true, limiter.AllowN(now.Add(time.Second), "tenant-2", 2))
 }
 
+func TestRateLimiter_AllowAllN(t *testing.T) {
+	strategy := &staticLimitStrategy{tenants: map[string]struct {
+		limit float64
+		burst int
+	}{
+		"tenant-1": {limit: 10, burst: 20},
+		"tenant-2": {limit: 20, burst: 40},
+	}}
+
+	limiter := NewRateLimiter(strategy, 10*time.Second)
+	now := time.Now()
+
+	assert.Empty(t, limiter.AllowAllN(now, []string{"tenant-1", "tenant-2"}, 15))
+
+	// The tokens are not consumed if a tenant doesn't allow them.
+	assert.Equal(t, []int{0}, limiter.AllowAllN(now, []string{"tenant-1", "tenant-2"}, 10))
+	assert.Equal(t, true, limiter.AllowN(now, "tenant-2", 25))
+	assert.Equal(t, false, limiter.AllowN(now, "tenant-2", 1))
+
+	// The tokens are fully restored when the same tenant is repeated.
+	assert.Equal(t, []int{1}, limiter.AllowAllN(now, []string{"tenant-1", "tenant-1"}, 5))
+	assert.Equal(t, true, limiter.AllowN(now, "tenant-1", 5))
+	assert.Equal(t, false, limiter.AllowN(now, "tenant-1", 1))
+}
+
 func BenchmarkRateLimiter_CustomMultiTenant(b *testing.B) {
 	strategy := &increasingLimitStrategy{}
 	limiter := NewRateLimiter(strategy, 10*time.Second)
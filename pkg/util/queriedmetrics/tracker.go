@@ -0,0 +1,79 @@
+package queriedmetrics
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+)
+
+// Tracker keeps track of the last time each metric name has been queried.
+type Tracker struct {
+	mtx         sync.Mutex
+	since       time.Time
+	lastQueried *simplelru.LRU[string, time.Time]
+}
+
+// NewTracker returns a Tracker tracking the queried metric names since the input time. At most
+// maxMetrics metric names are tracked, 0 to disable the limit: once reached, the least recently
+// queried metric names are evicted and the tracking start time moves forward to their last query,
+// so that the metric names not tracked are still the ones not queried since the tracking start time.
+func NewTracker(since time.Time, maxMetrics int) *Tracker {
+	if maxMetrics <= 0 {
+		maxMetrics = math.MaxInt
+	}
+
+	t := &Tracker{since: since}
+	// The size is always positive, so the LRU creation can't fail.
+	t.lastQueried, _ = simplelru.NewLRU(maxMetrics, func(_ string, last time.Time) {
+		if last.After(t.since) {
+			t.since = last
+		}
+	})
+	return t
+}
+
+// Track records the input metric names as queried at the input time.
+func (t *Tracker) Track(names map[string]struct{}, now time.Time) {
+	if len(names) == 0 {
+		return
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	for name := range names {
+		if last, ok := t.lastQueried.Peek(name); ok {
+			if now.After(last) {
+				t.lastQueried.Add(name, now)
+			}
+			continue
+		}
+
+		// The name may reference the memory of the series labels, so we copy it
+		// to not retain the labels in memory.
+		t.lastQueried.Add(strings.Clone(name), now)
+	}
+}
+
+// LastQueried returns the last time each tracked metric name has been queried.
+func (t *Tracker) LastQueried() map[string]time.Time {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	out := make(map[string]time.Time, t.lastQueried.Len())
+	for _, name := range t.lastQueried.Keys() {
+		out[name], _ = t.lastQueried.Peek(name)
+	}
+	return out
+}
+
+// Since returns the time since when the queried metric names have been tracked.
+func (t *Tracker) Since() time.Time {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	return t.since
+}
@@ -0,0 +1,44 @@
+package queriedmetrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker(t *testing.T) {
+	since := time.Unix(100, 0)
+	tracker := NewTracker(since, 0)
+	assert.Equal(t, since, tracker.Since())
+	assert.Empty(t, tracker.LastQueried())
+
+	tracker.Track(map[string]struct{}{"metric_1": {}, "metric_2": {}}, time.Unix(200, 0))
+	tracker.Track(map[string]struct{}{"metric_2": {}}, time.Unix(300, 0))
+
+	// Tracking an older query should not move the last queried time back.
+	tracker.Track(map[string]struct{}{"metric_1": {}}, time.Unix(150, 0))
+
+	assert.Equal(t, map[string]time.Time{
+		"metric_1": time.Unix(200, 0),
+		"metric_2": time.Unix(300, 0),
+	}, tracker.LastQueried())
+}
+
+func TestTracker_ShouldEvictTheLeastRecentlyQueriedMetrics(t *testing.T) {
+	since := time.Unix(100, 0)
+	tracker := NewTracker(since, 2)
+
+	tracker.Track(map[string]struct{}{"metric_1": {}}, time.Unix(200, 0))
+	tracker.Track(map[string]struct{}{"metric_2": {}}, time.Unix(300, 0))
+	tracker.Track(map[string]struct{}{"metric_1": {}}, time.Unix(400, 0))
+	assert.Equal(t, since, tracker.Since())
+
+	// metric_2 is evicted, so the tracking start time moves to its last query.
+	tracker.Track(map[string]struct{}{"metric_3": {}}, time.Unix(500, 0))
+	assert.Equal(t, time.Unix(300, 0), tracker.Since())
+	assert.Equal(t, map[string]time.Time{
+		"metric_1": time.Unix(400, 0),
+		"metric_3": time.Unix(500, 0),
+	}, tracker.LastQueried())
+}
@@ -0,0 +1,25 @@
+package requestmeta
+
+import "context"
+
+// AccessPolicyKey is the header selecting the query access policy of a request.
+const AccessPolicyKey = "x-cortex-access-policy"
+
+func ContextWithAccessPolicy(ctx context.Context, policy string) context.Context {
+	metadataMap := MapFromContext(ctx)
+	if metadataMap == nil {
+		metadataMap = make(map[string]string)
+	}
+	metadataMap[AccessPolicyKey] = policy
+	return ContextWithRequestMetadataMap(ctx, metadataMap)
+}
+
+// AccessPolicyFromContext returns the query access policy of the request, or an empty
+// string if none has been selected.
+func AccessPolicyFromContext(ctx context.Context) string {
+	metadataMap := MapFromContext(ctx)
+	if metadataMap == nil {
+		return ""
+	}
+	return metadataMap[AccessPolicyKey]
+}
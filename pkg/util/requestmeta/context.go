@@ -41,6 +41,7 @@ func ContextWithRequestMetadataMapFromHeaders(ctx context.Context, headers map[s
 	}
 	headerKeys = append(headerKeys, RequestIdKey)
 	headerKeys = append(headerKeys, RequestSourceKey)
+	headerKeys = append(headerKeys, AccessPolicyKey)
 	for _, header := range headerKeys {
 		if v, ok := headers[textproto.CanonicalMIMEHeaderKey(header)]; ok {
 			headerMap[header] = v
@@ -85,6 +85,17 @@ func TestContextWithRequestMetadataMapFromHeaders_OnlyMatchingKeysUsed(t *testin
 	require.Equal(t, "value1", requestMetadataMap["X-Some-Header"])
 }
 
+func TestContextWithRequestMetadataMapFromHeaders_AccessPolicy(t *testing.T) {
+	headers := map[string]string{
+		textproto.CanonicalMIMEHeaderKey(AccessPolicyKey): "team-a",
+	}
+
+	ctx := context.Background()
+	ctx = ContextWithRequestMetadataMapFromHeaders(ctx, headers, nil)
+
+	require.Equal(t, "team-a", AccessPolicyFromContext(ctx))
+}
+
 func TestInjectMetadataIntoHTTPRequestHeaders(t *testing.T) {
 	contentsMap := make(map[string]string)
 	contentsMap["TestHeader1"] = "RequestID"
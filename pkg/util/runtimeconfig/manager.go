@@ -26,6 +26,10 @@ type BucketClientFactory func(ctx context.Context) (objstore.Bucket, error)
 // Loader loads the configuration from file.
 type Loader func(r io.Reader) (any, error)
 
+// Merger merges the per-tenant overrides stored in the bucket, keyed by tenant ID,
+// into the configuration returned by the Loader.
+type Merger func(config any, tenantOverrides map[string][]byte) (any, error)
+
 // Config holds the config for an Manager instance.
 // It holds config related to loading per-tenant config.
 type Config struct {
@@ -34,8 +38,11 @@ type Config struct {
 	// non-empty value
 	LoadPath string `yaml:"file"`
 	Loader   Loader `yaml:"-"`
+	Merger   Merger `yaml:"-"`
 
 	StorageConfig bucket.Config `yaml:",inline"`
+
+	TenantOverrides TenantOverridesConfig `yaml:"tenant_overrides"`
 }
 
 // RegisterFlags registers flags.
@@ -44,6 +51,7 @@ func (mc *Config) RegisterFlags(f *flag.FlagSet) {
 	f.DurationVar(&mc.ReloadPeriod, "runtime-config.reload-period", 10*time.Second, "How often to check runtime config file.")
 
 	mc.StorageConfig.RegisterFlagsWithPrefixAndBackend("runtime-config.", f, bucket.Filesystem)
+	mc.TenantOverrides.RegisterFlags(f)
 }
 
 // Manager periodically reloads the configuration from a file, and keeps this
@@ -60,11 +68,18 @@ type Manager struct {
 	configMtx sync.RWMutex
 	config    any
 
+	// loadMtx serializes the config reloads, which can be also triggered
+	// by the changes to the tenant overrides.
+	loadMtx sync.Mutex
+
 	configLoadSuccess prometheus.Gauge
 	configHash        *prometheus.GaugeVec
 
 	bucketClient        objstore.Bucket
 	bucketClientFactory BucketClientFactory
+
+	tenantOverridesMtx   sync.Mutex
+	tenantOverridesCache map[string]tenantOverridesObject
 }
 
 // New creates an instance of Manager and starts reload config loop based on config
@@ -77,6 +92,10 @@ func New(cfg Config, registerer prometheus.Registerer, logger log.Logger, factor
 		return nil, errors.New("Backend should not be explicitly empty")
 	}
 
+	if cfg.TenantOverrides.Enabled && cfg.TenantOverrides.Prefix == "" {
+		return nil, errors.New("tenant overrides prefix is empty")
+	}
+
 	mgr := Manager{
 		cfg: cfg,
 		configLoadSuccess: promauto.With(registerer).NewGauge(prometheus.GaugeOpts{
@@ -166,6 +185,9 @@ func (om *Manager) loop(ctx context.Context) error {
 // loadConfig loads configuration using the loader function, and if successful,
 // stores it as current configuration and notifies listeners.
 func (om *Manager) loadConfig(ctx context.Context) error {
+	om.loadMtx.Lock()
+	defer om.loadMtx.Unlock()
+
 	buf, err := om.loadConfigFromBucket(ctx)
 
 	if err != nil {
@@ -179,6 +201,19 @@ func (om *Manager) loadConfig(ctx context.Context) error {
 		om.configLoadSuccess.Set(0)
 		return errors.Wrap(err, "load file")
 	}
+
+	if om.cfg.TenantOverrides.Enabled && om.cfg.Merger != nil {
+		tenantOverrides, err := om.loadTenantOverrides(ctx)
+		if err != nil {
+			om.configLoadSuccess.Set(0)
+			return errors.Wrap(err, "read tenant overrides")
+		}
+
+		if cfg, err = om.cfg.Merger(cfg, tenantOverrides); err != nil {
+			om.configLoadSuccess.Set(0)
+			return errors.Wrap(err, "merge tenant overrides")
+		}
+	}
 	om.configLoadSuccess.Set(1)
 
 	om.setConfig(cfg)
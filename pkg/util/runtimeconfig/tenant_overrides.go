@@ -0,0 +1,175 @@
+package runtimeconfig
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/thanos-io/objstore"
+
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+)
+
+const tenantOverridesExtension = ".yaml"
+
+var errTenantOverridesDisabled = errors.New("tenant overrides are disabled")
+
+// tenantOverridesObject is the content of a tenant overrides object, along with its
+// last modification time, cached to only read the objects changed since the last reload.
+type tenantOverridesObject struct {
+	lastModified time.Time
+	content      []byte
+}
+
+// TenantOverridesConfig configures the per-tenant overrides stored in the runtime config bucket,
+// which are merged on top of the runtime config file.
+type TenantOverridesConfig struct {
+	Enabled             bool                   `yaml:"enabled"`
+	Prefix              string                 `yaml:"prefix"`
+	TenantAllowedLimits flagext.StringSliceCSV `yaml:"tenant_allowed_limits"`
+}
+
+// RegisterFlags registers flags.
+func (cfg *TenantOverridesConfig) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "runtime-config.tenant-overrides.enabled", false, "[Experimental] Enable the per-tenant overrides stored in the runtime config bucket, and the API to read, patch and delete them. The stored overrides take precedence over the ones in the runtime config file. The changes are not atomic across instances, so the PATCH and DELETE requests must all be routed to a single instance.")
+	f.StringVar(&cfg.Prefix, "runtime-config.tenant-overrides.prefix", "tenant-overrides", "[Experimental] Path prefix, in the runtime config bucket, of the per-tenant overrides objects.")
+	f.Var(&cfg.TenantAllowedLimits, "runtime-config.tenant-overrides.tenant-allowed-limits", "[Experimental] Comma separated list of limits, by YAML name, that tenants are allowed to change themselves. If empty, the overrides can only be changed through the admin API.")
+}
+
+// TenantOverridesEnabled returns whether the per-tenant overrides stored in the bucket are enabled.
+func (om *Manager) TenantOverridesEnabled() bool {
+	return om.cfg.TenantOverrides.Enabled
+}
+
+// GetTenantOverrides returns the overrides of a tenant stored in the bucket, or nil if the tenant has none.
+func (om *Manager) GetTenantOverrides(ctx context.Context, userID string) ([]byte, error) {
+	if !om.cfg.TenantOverrides.Enabled {
+		return nil, errTenantOverridesDisabled
+	}
+
+	r, err := om.bucketClient.Get(ctx, om.tenantOverridesPath(userID))
+	if om.bucketClient.IsObjNotFoundErr(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// SetTenantOverrides stores the overrides of a tenant in the bucket and reloads the runtime config,
+// so that they're applied right away by this instance. Other instances apply them on the next reload.
+func (om *Manager) SetTenantOverrides(ctx context.Context, userID string, overrides []byte) error {
+	if !om.cfg.TenantOverrides.Enabled {
+		return errTenantOverridesDisabled
+	}
+
+	if err := om.bucketClient.Upload(ctx, om.tenantOverridesPath(userID), bytes.NewReader(overrides)); err != nil {
+		return errors.Wrap(err, "upload tenant overrides")
+	}
+	om.forgetTenantOverrides(userID)
+	return errors.Wrap(om.loadConfig(ctx), "reload runtime config")
+}
+
+// DeleteTenantOverrides deletes the overrides of a tenant from the bucket and reloads the runtime config.
+func (om *Manager) DeleteTenantOverrides(ctx context.Context, userID string) error {
+	if !om.cfg.TenantOverrides.Enabled {
+		return errTenantOverridesDisabled
+	}
+
+	if err := om.bucketClient.Delete(ctx, om.tenantOverridesPath(userID)); err != nil && !om.bucketClient.IsObjNotFoundErr(err) {
+		return errors.Wrap(err, "delete tenant overrides")
+	}
+	om.forgetTenantOverrides(userID)
+	return errors.Wrap(om.loadConfig(ctx), "reload runtime config")
+}
+
+// loadTenantOverrides reads the overrides of all tenants from the bucket. Only the objects
+// changed since the last reload are read, the others are served from the cache.
+func (om *Manager) loadTenantOverrides(ctx context.Context) (map[string][]byte, error) {
+	listed := map[string]time.Time{}
+
+	var opts []objstore.IterOption
+	if slices.Contains(om.bucketClient.SupportedIterOptions(), objstore.UpdatedAt) {
+		opts = append(opts, objstore.WithUpdatedAt())
+	}
+
+	err := om.bucketClient.IterWithAttributes(ctx, om.cfg.TenantOverrides.Prefix+"/", func(attrs objstore.IterObjectAttributes) error {
+		userID, ok := strings.CutSuffix(path.Base(attrs.Name), tenantOverridesExtension)
+		if !ok || userID == "" {
+			return nil
+		}
+
+		lastModified, ok := attrs.LastModified()
+		if !ok {
+			objAttrs, err := om.bucketClient.Attributes(ctx, attrs.Name)
+			if om.bucketClient.IsObjNotFoundErr(err) {
+				return nil
+			}
+			if err != nil {
+				return errors.Wrapf(err, "read attributes of the overrides of tenant %s", userID)
+			}
+			lastModified = objAttrs.LastModified
+		}
+		listed[userID] = lastModified
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	om.tenantOverridesMtx.Lock()
+	defer om.tenantOverridesMtx.Unlock()
+
+	if om.tenantOverridesCache == nil {
+		om.tenantOverridesCache = map[string]tenantOverridesObject{}
+	}
+
+	overrides := make(map[string][]byte, len(listed))
+	for userID, lastModified := range listed {
+		cached, ok := om.tenantOverridesCache[userID]
+		if !ok || lastModified.IsZero() || !cached.lastModified.Equal(lastModified) {
+			buf, err := om.GetTenantOverrides(ctx, userID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "read overrides of tenant %s", userID)
+			}
+			if buf == nil {
+				delete(om.tenantOverridesCache, userID)
+				continue
+			}
+			cached = tenantOverridesObject{lastModified: lastModified, content: buf}
+			om.tenantOverridesCache[userID] = cached
+		}
+		overrides[userID] = cached.content
+	}
+
+	// Drop the tenants whose overrides have been deleted.
+	for userID := range om.tenantOverridesCache {
+		if _, ok := listed[userID]; !ok {
+			delete(om.tenantOverridesCache, userID)
+		}
+	}
+
+	return overrides, nil
+}
+
+// forgetTenantOverrides drops the cached overrides of a tenant, so that they're read again on the next reload,
+// even if the object last modification time didn't change at the bucket timestamps resolution.
+func (om *Manager) forgetTenantOverrides(userID string) {
+	om.tenantOverridesMtx.Lock()
+	defer om.tenantOverridesMtx.Unlock()
+
+	delete(om.tenantOverridesCache, userID)
+}
+
+func (om *Manager) tenantOverridesPath(userID string) string {
+	return path.Join(om.cfg.TenantOverrides.Prefix, userID+tenantOverridesExtension)
+}
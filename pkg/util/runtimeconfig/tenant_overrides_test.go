@@ -0,0 +1,165 @@
+package runtimeconfig
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"gopkg.in/yaml.v2"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	"github.com/cortexproject/cortex/pkg/util/services"
+)
+
+func testMergeOverrides(config any, tenantOverrides map[string][]byte) (any, error) {
+	overrides := config.(*testOverrides)
+	if overrides.Overrides == nil {
+		overrides.Overrides = map[string]*TestLimits{}
+	}
+
+	for userID, buf := range tenantOverrides {
+		limits := &TestLimits{}
+		if l := overrides.Overrides[userID]; l != nil {
+			*limits = *l
+		}
+		if err := yaml.Unmarshal(buf, limits); err != nil {
+			return nil, err
+		}
+		overrides.Overrides[userID] = limits
+	}
+	return overrides, nil
+}
+
+func TestManager_TenantOverrides(t *testing.T) {
+	ctx := context.Background()
+
+	bkt := objstore.NewInMemBucket()
+	require.NoError(t, bkt.Upload(ctx, "runtime.yaml", strings.NewReader(`overrides:
+  user1:
+    limit1: 100
+    limit2: 200`)))
+	require.NoError(t, bkt.Upload(ctx, "tenant-overrides/user2.yaml", strings.NewReader(`limit1: 10`)))
+
+	cfg := Config{
+		ReloadPeriod:  time.Minute,
+		LoadPath:      "runtime.yaml",
+		Loader:        testLoadOverrides,
+		Merger:        testMergeOverrides,
+		StorageConfig: bucket.Config{Backend: bucket.Filesystem},
+		TenantOverrides: TenantOverridesConfig{
+			Enabled: true,
+			Prefix:  "tenant-overrides",
+		},
+	}
+
+	m, err := New(cfg, nil, log.NewNopLogger(), func(context.Context) (objstore.Bucket, error) { return bkt, nil })
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, m))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(ctx, m))
+	})
+
+	// The overrides stored at startup should be merged.
+	assert.Equal(t, map[string]*TestLimits{
+		"user1": {Limit1: 100, Limit2: 200},
+		"user2": {Limit1: 10},
+	}, m.GetConfig().(*testOverrides).Overrides)
+
+	// Changing the overrides should reload the config.
+	require.NoError(t, m.SetTenantOverrides(ctx, "user1", []byte(`limit2: 300`)))
+	assert.Equal(t, map[string]*TestLimits{
+		"user1": {Limit1: 100, Limit2: 300},
+		"user2": {Limit1: 10},
+	}, m.GetConfig().(*testOverrides).Overrides)
+
+	buf, err := m.GetTenantOverrides(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, "limit2: 300", string(buf))
+
+	// Deleting the overrides should restore the runtime config file ones.
+	require.NoError(t, m.DeleteTenantOverrides(ctx, "user1"))
+	require.NoError(t, m.DeleteTenantOverrides(ctx, "user3"))
+	assert.Equal(t, map[string]*TestLimits{
+		"user1": {Limit1: 100, Limit2: 200},
+		"user2": {Limit1: 10},
+	}, m.GetConfig().(*testOverrides).Overrides)
+
+	buf, err = m.GetTenantOverrides(ctx, "user1")
+	require.NoError(t, err)
+	assert.Nil(t, buf)
+}
+
+func TestManager_TenantOverridesDisabled(t *testing.T) {
+	m := &Manager{}
+
+	_, err := m.GetTenantOverrides(context.Background(), "user1")
+	assert.Equal(t, errTenantOverridesDisabled, err)
+	assert.Equal(t, errTenantOverridesDisabled, m.SetTenantOverrides(context.Background(), "user1", nil))
+	assert.Equal(t, errTenantOverridesDisabled, m.DeleteTenantOverrides(context.Background(), "user1"))
+}
+
+// getsCountingBucket counts the objects read from the wrapped bucket.
+type getsCountingBucket struct {
+	objstore.Bucket
+	gets map[string]int
+}
+
+func (b *getsCountingBucket) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	b.gets[name]++
+	return b.Bucket.Get(ctx, name)
+}
+
+func TestManager_TenantOverridesShouldOnlyReadTheChangedObjects(t *testing.T) {
+	ctx := context.Background()
+
+	bkt := &getsCountingBucket{Bucket: objstore.NewInMemBucket(), gets: map[string]int{}}
+	require.NoError(t, bkt.Upload(ctx, "runtime.yaml", strings.NewReader(`overrides: {}`)))
+	require.NoError(t, bkt.Upload(ctx, "tenant-overrides/user1.yaml", strings.NewReader(`limit1: 10`)))
+	require.NoError(t, bkt.Upload(ctx, "tenant-overrides/user2.yaml", strings.NewReader(`limit1: 20`)))
+
+	cfg := Config{
+		ReloadPeriod:  time.Minute,
+		LoadPath:      "runtime.yaml",
+		Loader:        testLoadOverrides,
+		Merger:        testMergeOverrides,
+		StorageConfig: bucket.Config{Backend: bucket.Filesystem},
+		TenantOverrides: TenantOverridesConfig{
+			Enabled: true,
+			Prefix:  "tenant-overrides",
+		},
+	}
+
+	m, err := New(cfg, nil, log.NewNopLogger(), func(context.Context) (objstore.Bucket, error) { return bkt, nil })
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, m))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(ctx, m))
+	})
+
+	assert.Equal(t, map[string]int{"runtime.yaml": 1, "tenant-overrides/user1.yaml": 1, "tenant-overrides/user2.yaml": 1}, bkt.gets)
+
+	// Reloading without changes should only read the runtime config file.
+	require.NoError(t, m.loadConfig(ctx))
+	assert.Equal(t, map[string]int{"runtime.yaml": 2, "tenant-overrides/user1.yaml": 1, "tenant-overrides/user2.yaml": 1}, bkt.gets)
+
+	// Changing the overrides of a tenant should only read them again.
+	require.NoError(t, m.SetTenantOverrides(ctx, "user1", []byte(`limit1: 30`)))
+	assert.Equal(t, map[string]int{"runtime.yaml": 3, "tenant-overrides/user1.yaml": 2, "tenant-overrides/user2.yaml": 1}, bkt.gets)
+	assert.Equal(t, map[string]*TestLimits{
+		"user1": {Limit1: 30},
+		"user2": {Limit1: 20},
+	}, m.GetConfig().(*testOverrides).Overrides)
+
+	// Deleting the overrides of a tenant should drop them without reading the others.
+	require.NoError(t, m.DeleteTenantOverrides(ctx, "user2"))
+	assert.Equal(t, map[string]int{"runtime.yaml": 4, "tenant-overrides/user1.yaml": 2, "tenant-overrides/user2.yaml": 1}, bkt.gets)
+	assert.Equal(t, map[string]*TestLimits{
+		"user1": {Limit1: 30},
+	}, m.GetConfig().(*testOverrides).Overrides)
+}
@@ -10,6 +10,7 @@ const (
 	// Sharding strategies.
 	ShardingStrategyDefault = "default"
 	ShardingStrategyShuffle = "shuffle-sharding"
+	ShardingStrategyTime    = "time-based"
 
 	// Compaction strategies
 	CompactionStrategyDefault      = "default"
@@ -38,6 +38,7 @@ func TestOverridesExporter_withConfig(t *testing.T) {
 		# TYPE cortex_overrides gauge
 		cortex_overrides{limit_name="accept_ha_samples",user="tenant-a"} 0
 		cortex_overrides{limit_name="accept_mixed_ha_samples",user="tenant-a"} 0
+		cortex_overrides{limit_name="aggregation_max_series",user="tenant-a"} 100000
 		cortex_overrides{limit_name="alertmanager_max_alerts_count",user="tenant-a"} 0
 		cortex_overrides{limit_name="alertmanager_max_alerts_size_bytes",user="tenant-a"} 0
 		cortex_overrides{limit_name="alertmanager_max_config_size_bytes",user="tenant-a"} 0
@@ -57,10 +58,16 @@ func TestOverridesExporter_withConfig(t *testing.T) {
 		cortex_overrides{limit_name="enable_type_and_unit_labels",user="tenant-a"} 0
 		cortex_overrides{limit_name="enforce_metadata_metric_name",user="tenant-a"} 1
 		cortex_overrides{limit_name="enforce_metric_name",user="tenant-a"} 1
+		cortex_overrides{limit_name="export_max_bytes",user="tenant-a"} 0
+		cortex_overrides{limit_name="export_max_concurrent_jobs",user="tenant-a"} 1
+		cortex_overrides{limit_name="export_max_time_range",user="tenant-a"} 0
+		cortex_overrides{limit_name="fair_queuing_weight",user="tenant-a"} 1
 		cortex_overrides{limit_name="ha_max_clusters",user="tenant-a"} 0
 		cortex_overrides{limit_name="ingestion_burst_size",user="tenant-a"} 50000
 		cortex_overrides{limit_name="ingestion_rate",user="tenant-a"} 25000
 		cortex_overrides{limit_name="ingestion_tenant_shard_size",user="tenant-a"} 0
+		cortex_overrides{limit_name="labels_query_burst_size",user="tenant-a"} 0
+		cortex_overrides{limit_name="labels_query_rate",user="tenant-a"} 0
 		cortex_overrides{limit_name="max_cache_freshness",user="tenant-a"} 60
 		cortex_overrides{limit_name="max_downloaded_bytes_per_request",user="tenant-a"} 0
 		cortex_overrides{limit_name="max_exemplars",user="tenant-a"} 0
@@ -102,17 +109,26 @@ func TestOverridesExporter_withConfig(t *testing.T) {
 		cortex_overrides{limit_name="parquet_max_fetched_chunk_bytes",user="tenant-a"} 0
 		cortex_overrides{limit_name="parquet_max_fetched_data_bytes",user="tenant-a"} 0
 		cortex_overrides{limit_name="parquet_max_fetched_row_count",user="tenant-a"} 0
+		cortex_overrides{limit_name="query_burst_size",user="tenant-a"} 0
 		cortex_overrides{limit_name="query_partial_data",user="tenant-a"} 0
+		cortex_overrides{limit_name="query_range_burst_size",user="tenant-a"} 0
+		cortex_overrides{limit_name="query_range_rate",user="tenant-a"} 0
+		cortex_overrides{limit_name="query_rate",user="tenant-a"} 0
 		cortex_overrides{limit_name="query_vertical_shard_size",user="tenant-a"} 0
 		cortex_overrides{limit_name="reject_old_samples",user="tenant-a"} 0
 		cortex_overrides{limit_name="reject_old_samples_max_age",user="tenant-a"} 1.2096e+06
+		cortex_overrides{limit_name="remote_read_burst_size",user="tenant-a"} 0
+		cortex_overrides{limit_name="remote_read_rate",user="tenant-a"} 0
 		cortex_overrides{limit_name="ruler_evaluation_delay_duration",user="tenant-a"} 0
 		cortex_overrides{limit_name="ruler_max_rule_groups_per_tenant",user="tenant-a"} 0
 		cortex_overrides{limit_name="ruler_max_rules_per_rule_group",user="tenant-a"} 0
 		cortex_overrides{limit_name="ruler_query_offset",user="tenant-a"} 0
 		cortex_overrides{limit_name="ruler_tenant_shard_size",user="tenant-a"} 0
 		cortex_overrides{limit_name="rules_partial_data",user="tenant-a"} 0
+		cortex_overrides{limit_name="series_query_burst_size",user="tenant-a"} 0
+		cortex_overrides{limit_name="series_query_rate",user="tenant-a"} 0
 		cortex_overrides{limit_name="store_gateway_tenant_shard_size",user="tenant-a"} 0
+		cortex_overrides{limit_name="tracing_debug_enabled",user="tenant-a"} 0
 	`), "cortex_overrides"))
 }
 
@@ -9,6 +9,7 @@ import (
 	"maps"
 	"math"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/prometheus/prometheus/promql/parser"
 	"github.com/segmentio/fasthash/fnv1a"
 	"golang.org/x/time/rate"
 
@@ -30,13 +32,24 @@ var errMaxLocalNativeHistogramSeriesPerUserValidation = errors.New("the ingester
 var errDuplicateQueryPriorities = errors.New("duplicate entry of priorities found. Make sure they are all unique, including the default priority")
 var errCompilingQueryPriorityRegex = errors.New("error compiling query priority regex")
 var errDuplicatePerLabelSetLimit = errors.New("duplicate per labelSet limits found. Make sure they are all unique")
+var errDuplicateQueryAccessPolicy = errors.New("duplicate query access policy found. Make sure their names are all unique")
+var errInvalidQueryAccessPolicyName = errors.New("invalid query access policy name. It must only contain alphanumeric characters, underscores, dashes and dots")
+var errUnknownMandatoryQueryAccessPolicy = errors.New("the mandatory query access policy must be one of the query access policies")
+var errDuplicateAggregationRuleOutput = errors.New("duplicate aggregation rule output found. Make sure the output metric names are all unique")
+var errAggregationRuleByAndWithout = errors.New("invalid aggregation rule: by and without can't be both set")
 var errInvalidLabelName = errors.New("invalid label name")
 var errInvalidLabelValue = errors.New("invalid label value")
+var errInvalidQueryRateStrategy = errors.New("invalid query rate strategy")
+
+var queryAccessPolicyNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.\-]+$`)
 
 // Supported values for enum limits
 const (
 	LocalIngestionRateStrategy  = "local"
 	GlobalIngestionRateStrategy = "global"
+
+	LocalQueryRateStrategy  = "local"
+	GlobalQueryRateStrategy = "global"
 )
 
 // AccessDeniedError are errors that do not comply with the limits specified.
@@ -66,6 +79,28 @@ type DisabledRuleGroup struct {
 
 type DisabledRuleGroups []DisabledRuleGroup
 
+// RulerAlertmanagerConfig overrides, for a tenant, the Alertmanagers the ruler sends the alerts to.
+type RulerAlertmanagerConfig struct {
+	AlertmanagerURL       string `yaml:"alertmanager_url" json:"alertmanager_url" doc:"nocli|description=Comma-separated list of URL(s) of the Alertmanager(s) to send the alerts of the tenant to, instead of -ruler.alertmanager-url. Basic auth is supported using the URL."`
+	AlertmanagerDiscovery bool   `yaml:"enable_alertmanager_discovery" json:"enable_alertmanager_discovery" doc:"nocli|description=Use DNS SRV records to discover the Alertmanager hosts of alertmanager_url.|default=false"`
+
+	BasicAuthUsername string         `yaml:"basic_auth_username" json:"basic_auth_username" doc:"nocli|description=HTTP Basic authentication username, instead of -ruler.alertmanager-client.basic-auth-username. It overrides the username set in the URL (if any). If alertmanager_url is set, the -ruler.alertmanager-client.basic-auth-* settings are never used."`
+	BasicAuthPassword flagext.Secret `yaml:"basic_auth_password" json:"basic_auth_password" doc:"nocli|description=HTTP Basic authentication password, instead of -ruler.alertmanager-client.basic-auth-password. It overrides the password set in the URL (if any)."`
+
+	TLSCertPath           string `yaml:"tls_cert_path" json:"tls_cert_path" doc:"nocli|description=Path to the client certificate file. If any of the TLS settings is set, they replace the -ruler.alertmanager-client.tls-* settings. If alertmanager_url is set, the -ruler.alertmanager-client.tls-* settings are never used."`
+	TLSKeyPath            string `yaml:"tls_key_path" json:"tls_key_path" doc:"nocli|description=Path to the key file for the client certificate."`
+	TLSCAPath             string `yaml:"tls_ca_path" json:"tls_ca_path" doc:"nocli|description=Path to the CA certificates file to validate the Alertmanager certificate against."`
+	TLSServerName         string `yaml:"tls_server_name" json:"tls_server_name" doc:"nocli|description=Override the expected name on the Alertmanager certificate."`
+	TLSInsecureSkipVerify bool   `yaml:"tls_insecure_skip_verify" json:"tls_insecure_skip_verify" doc:"nocli|description=Skip validating the Alertmanager certificate.|default=false"`
+
+	AlertRelabelConfigs []*relabel.Config `yaml:"alert_relabel_configs,omitempty" json:"alert_relabel_configs,omitempty" doc:"nocli|description=List of relabel configurations applied to the alerts of the tenant before they are sent."`
+}
+
+// HasTLSConfig returns whether any of the TLS settings is set.
+func (c RulerAlertmanagerConfig) HasTLSConfig() bool {
+	return c.TLSCertPath != "" || c.TLSKeyPath != "" || c.TLSCAPath != "" || c.TLSServerName != "" || c.TLSInsecureSkipVerify
+}
+
 type QueryPriority struct {
 	Enabled         bool          `yaml:"enabled" json:"enabled"`
 	DefaultPriority int64         `yaml:"default_priority" json:"default_priority"`
@@ -112,7 +147,9 @@ type QueryStepLimit struct {
 }
 
 type LimitsPerLabelSetEntry struct {
-	MaxSeries int `yaml:"max_series" json:"max_series" doc:"nocli|description=The maximum number of active series per LabelSet, across the cluster before replication. Setting the value 0 will enable the monitoring (metrics) but would not enforce any limits."`
+	MaxSeries          int     `yaml:"max_series" json:"max_series" doc:"nocli|description=The maximum number of active series per LabelSet, across the cluster before replication. Setting the value 0 will enable the monitoring (metrics) but would not enforce any limits."`
+	IngestionRate      float64 `yaml:"ingestion_rate" json:"ingestion_rate" doc:"nocli|description=The per-LabelSet ingestion rate limit in samples per second, applied with the tenant ingestion rate strategy to the samples accepted by the tenant ingestion rate limit. Samples of the series exceeding it are discarded, while the other series of the request are ingested. 0 to disable."`
+	IngestionBurstSize int     `yaml:"ingestion_burst_size" json:"ingestion_burst_size" doc:"nocli|description=The per-LabelSet allowed ingestion burst size (in number of samples). If 0, the tenant ingestion burst size is used."`
 }
 
 type LimitsPerLabelSet struct {
@@ -122,6 +159,36 @@ type LimitsPerLabelSet struct {
 	Hash     uint64                 `yaml:"-" json:"-" doc:"nocli"`
 }
 
+type QueryAccessPolicy struct {
+	Name     string            `yaml:"name" json:"name" doc:"nocli|description=Name of the policy, selected by the X-Cortex-Access-Policy request header. Must be unique."`
+	Selector string            `yaml:"selector" json:"selector" doc:"nocli|description=Series selector (eg. '{namespace=~\"team-a-.*\"}') whose matchers are added to every query issued with this policy."`
+	Matchers []*labels.Matcher `yaml:"-" json:"-" doc:"nocli"`
+}
+
+// Supported operations of the aggregation rules.
+const (
+	AggregationSum      = "sum"
+	AggregationCount    = "count"
+	AggregationMin      = "min"
+	AggregationMax      = "max"
+	AggregationAvg      = "avg"
+	AggregationRate     = "rate"
+	AggregationIncrease = "increase"
+)
+
+var supportedAggregationOperations = []string{AggregationSum, AggregationCount, AggregationMin, AggregationMax, AggregationAvg, AggregationRate, AggregationIncrease}
+
+type AggregationRule struct {
+	Match     string            `yaml:"match" json:"match" doc:"nocli|description=Series selector (eg. '{__name__=\"http_requests_total\"}') of the input series of the rule."`
+	Output    string            `yaml:"output" json:"output" doc:"nocli|description=Metric name of the output series. Must be unique."`
+	Operation string            `yaml:"operation" json:"operation" doc:"nocli|description=Aggregation operation. Supported values are: sum, count, min, max, avg, which aggregate the latest sample of each input series within the interval, and rate, increase, which sum the counter increase of the input series within the interval."`
+	By        []string          `yaml:"by" json:"by" doc:"nocli|description=Labels of the input series kept in the output series. Can't be set with without."`
+	Without   []string          `yaml:"without" json:"without" doc:"nocli|description=Labels of the input series removed from the output series. All the other labels, except the metric name, are kept."`
+	Interval  model.Duration    `yaml:"interval" json:"interval" doc:"nocli|description=Interval at which the output series samples are emitted.|default=1m"`
+	DropInput bool              `yaml:"drop_input" json:"drop_input" doc:"nocli|description=If true, the input series are not ingested.|default=false"`
+	Matchers  []*labels.Matcher `yaml:"-" json:"-" doc:"nocli"`
+}
+
 // Limits describe all the limits for users; can be used to describe global default
 // limits via flags, or per-user limits via yaml config.
 type Limits struct {
@@ -153,6 +220,8 @@ type Limits struct {
 	MaxNativeHistogramBuckets         int                 `yaml:"max_native_histogram_buckets" json:"max_native_histogram_buckets"`
 	PromoteResourceAttributes         []string            `yaml:"promote_resource_attributes" json:"promote_resource_attributes"`
 	EnableTypeAndUnitLabels           bool                `yaml:"enable_type_and_unit_labels" json:"enable_type_and_unit_labels"`
+	AggregationRules                  []AggregationRule   `yaml:"aggregation_rules" json:"aggregation_rules" doc:"nocli|description=[Experimental] Streaming aggregation rules. The input series matching a rule are aggregated in memory by the distributors, which emit the output series every interval. Requires -distributor.aggregation.enabled."`
+	AggregationMaxSeries              int                 `yaml:"aggregation_max_series" json:"aggregation_max_series"`
 
 	// Ingester enforced limits.
 	// Series
@@ -162,7 +231,7 @@ type Limits struct {
 	MaxGlobalSeriesPerUser                int                 `yaml:"max_global_series_per_user" json:"max_global_series_per_user"`
 	MaxGlobalSeriesPerMetric              int                 `yaml:"max_global_series_per_metric" json:"max_global_series_per_metric"`
 	MaxGlobalNativeHistogramSeriesPerUser int                 `yaml:"max_global_native_histogram_series_per_user" json:"max_global_native_histogram_series_per_user"`
-	LimitsPerLabelSet                     []LimitsPerLabelSet `yaml:"limits_per_label_set" json:"limits_per_label_set" doc:"nocli|description=[Experimental] Enable limits per LabelSet. Supported limits per labelSet: [max_series]"`
+	LimitsPerLabelSet                     []LimitsPerLabelSet `yaml:"limits_per_label_set" json:"limits_per_label_set" doc:"nocli|description=[Experimental] Enable limits per LabelSet. Supported limits per labelSet: [max_series, ingestion_rate, ingestion_burst_size]"`
 	EnableNativeHistograms                bool                `yaml:"enable_native_histograms" json:"enable_native_histograms"`
 
 	// Regex matcher query limits.
@@ -194,26 +263,49 @@ type Limits struct {
 	QueryVerticalShardSize       int            `yaml:"query_vertical_shard_size" json:"query_vertical_shard_size"`
 	QueryPartialData             bool           `yaml:"query_partial_data" json:"query_partial_data" doc:"nocli|description=Enable to allow queries to be evaluated with data from a single zone, if other zones are not available.|default=false"`
 
+	// Querier enforced access policies.
+	QueryAccessPolicies        []QueryAccessPolicy `yaml:"query_access_policies" json:"query_access_policies" doc:"nocli|description=[Experimental] Label based access policies. When a request selects a policy through the X-Cortex-Access-Policy header, the querier adds the policy matchers to every series, label names and label values lookup. Requests selecting a policy which doesn't exist are rejected. The header must be set by a trusted authentication gateway, or taken from the token claims with the JWT authentication."`
+	MandatoryQueryAccessPolicy string              `yaml:"mandatory_query_access_policy" json:"mandatory_query_access_policy" doc:"nocli|description=[Experimental] Name of the query access policy applied to every query of the tenant, in addition to the policy selected by the request, if any. The reports covering all the series of the tenant, such as the unused metrics, are denied when a policy applies."`
+
 	// Parquet Queryable enforced limits.
 	ParquetMaxFetchedRowCount   int `yaml:"parquet_max_fetched_row_count" json:"parquet_max_fetched_row_count"`
 	ParquetMaxFetchedChunkBytes int `yaml:"parquet_max_fetched_chunk_bytes" json:"parquet_max_fetched_chunk_bytes"`
 	ParquetMaxFetchedDataBytes  int `yaml:"parquet_max_fetched_data_bytes" json:"parquet_max_fetched_data_bytes"`
 
+	// Export.
+	ExportMaxConcurrentJobs int            `yaml:"export_max_concurrent_jobs" json:"export_max_concurrent_jobs"`
+	ExportMaxTimeRange      model.Duration `yaml:"export_max_time_range" json:"export_max_time_range"`
+	ExportMaxBytes          int64          `yaml:"export_max_bytes" json:"export_max_bytes"`
+
 	// Query Frontend / Scheduler enforced limits.
 	MaxOutstandingPerTenant     int           `yaml:"max_outstanding_requests_per_tenant" json:"max_outstanding_requests_per_tenant"`
+	FairQueuingWeight           float64       `yaml:"fair_queuing_weight" json:"fair_queuing_weight"`
+	TracingDebugEnabled         bool          `yaml:"tracing_debug_enabled" json:"tracing_debug_enabled"`
 	QueryPriority               QueryPriority `yaml:"query_priority" json:"query_priority" doc:"nocli|description=Configuration for query priority."`
 	queryAttributeRegexHash     uint64
 	queryAttributeCompiledRegex map[string]*regexp.Regexp
 	QueryRejection              QueryRejection `yaml:"query_rejection" json:"query_rejection" doc:"nocli|description=Configuration for query rejection."`
+	QueryRateStrategy           string         `yaml:"query_rate_strategy" json:"query_rate_strategy"`
+	QueryRate                   float64        `yaml:"query_rate" json:"query_rate"`
+	QueryBurstSize              int            `yaml:"query_burst_size" json:"query_burst_size"`
+	QueryRangeRate              float64        `yaml:"query_range_rate" json:"query_range_rate"`
+	QueryRangeBurstSize         int            `yaml:"query_range_burst_size" json:"query_range_burst_size"`
+	SeriesQueryRate             float64        `yaml:"series_query_rate" json:"series_query_rate"`
+	SeriesQueryBurstSize        int            `yaml:"series_query_burst_size" json:"series_query_burst_size"`
+	LabelsQueryRate             float64        `yaml:"labels_query_rate" json:"labels_query_rate"`
+	LabelsQueryBurstSize        int            `yaml:"labels_query_burst_size" json:"labels_query_burst_size"`
+	RemoteReadRate              float64        `yaml:"remote_read_rate" json:"remote_read_rate"`
+	RemoteReadBurstSize         int            `yaml:"remote_read_burst_size" json:"remote_read_burst_size"`
 
 	// Ruler defaults and limits.
-	RulerEvaluationDelay        model.Duration `yaml:"ruler_evaluation_delay_duration" json:"ruler_evaluation_delay_duration"`
-	RulerTenantShardSize        float64        `yaml:"ruler_tenant_shard_size" json:"ruler_tenant_shard_size"`
-	RulerMaxRulesPerRuleGroup   int            `yaml:"ruler_max_rules_per_rule_group" json:"ruler_max_rules_per_rule_group"`
-	RulerMaxRuleGroupsPerTenant int            `yaml:"ruler_max_rule_groups_per_tenant" json:"ruler_max_rule_groups_per_tenant"`
-	RulerQueryOffset            model.Duration `yaml:"ruler_query_offset" json:"ruler_query_offset"`
-	RulerExternalLabels         labels.Labels  `yaml:"ruler_external_labels" json:"ruler_external_labels" doc:"nocli|description=external labels for alerting rules"`
-	RulesPartialData            bool           `yaml:"rules_partial_data" json:"rules_partial_data" doc:"nocli|description=Enable to allow rules to be evaluated with data from a single zone, if other zones are not available.|default=false"`
+	RulerEvaluationDelay        model.Duration          `yaml:"ruler_evaluation_delay_duration" json:"ruler_evaluation_delay_duration"`
+	RulerTenantShardSize        float64                 `yaml:"ruler_tenant_shard_size" json:"ruler_tenant_shard_size"`
+	RulerMaxRulesPerRuleGroup   int                     `yaml:"ruler_max_rules_per_rule_group" json:"ruler_max_rules_per_rule_group"`
+	RulerMaxRuleGroupsPerTenant int                     `yaml:"ruler_max_rule_groups_per_tenant" json:"ruler_max_rule_groups_per_tenant"`
+	RulerQueryOffset            model.Duration          `yaml:"ruler_query_offset" json:"ruler_query_offset"`
+	RulerExternalLabels         labels.Labels           `yaml:"ruler_external_labels" json:"ruler_external_labels" doc:"nocli|description=external labels for alerting rules"`
+	RulerAlertmanagerConfig     RulerAlertmanagerConfig `yaml:"ruler_alertmanager_config" json:"ruler_alertmanager_config" doc:"nocli|description=Per-tenant overrides of the Alertmanagers the ruler sends the alerts to."`
+	RulesPartialData            bool                    `yaml:"rules_partial_data" json:"rules_partial_data" doc:"nocli|description=Enable to allow rules to be evaluated with data from a single zone, if other zones are not available.|default=false"`
 
 	// Store-gateway.
 	StoreGatewayTenantShardSize  float64 `yaml:"store_gateway_tenant_shard_size" json:"store_gateway_tenant_shard_size"`
@@ -270,6 +362,7 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&l.HAMaxClusters, "distributor.ha-tracker.max-clusters", 0, "Maximum number of clusters that HA tracker will keep track of for single user. 0 to disable the limit.")
 	f.Var((*flagext.StringSliceCSV)(&l.PromoteResourceAttributes), "distributor.promote-resource-attributes", "Comma separated list of resource attributes that should be converted to labels.")
 	f.Var(&l.DropLabels, "distributor.drop-label", "This flag can be used to specify label names that to drop during sample ingestion within the distributor and can be repeated in order to drop multiple labels.")
+	f.IntVar(&l.AggregationMaxSeries, "distributor.aggregation.max-series", 100000, "[Experimental] Maximum number of input series of the tenant tracked in memory by a distributor to aggregate them, counted once per aggregation rule and interval. The samples of the new input series are discarded once reached. 0 = no limit.")
 	f.BoolVar(&l.EnableTypeAndUnitLabels, "distributor.enable-type-and-unit-labels", false, "EXPERIMENTAL: If true, the __type__ and __unit__ labels are added to metrics. This applies to remote write v2 and OTLP requests.")
 	f.IntVar(&l.MaxLabelNameLength, "validation.max-length-label-name", 1024, "Maximum length accepted for label names")
 	f.IntVar(&l.MaxLabelValueLength, "validation.max-length-label-value", 2048, "Maximum length accepted for label value. This setting also applies to the metric name")
@@ -322,6 +415,19 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&l.QueryRejection.Enabled, "frontend.query-rejection.enabled", false, "Whether query rejection is enabled.")
 
 	f.IntVar(&l.MaxOutstandingPerTenant, "frontend.max-outstanding-requests-per-tenant", 100, "Maximum number of outstanding requests per tenant per request queue (either query frontend or query scheduler); requests beyond this error with HTTP 429.")
+	f.Float64Var(&l.FairQueuingWeight, "query-scheduler.fair-queuing-weight", 1, "[Experimental] Share of the queriers the tenant gets relative to the other tenants, when the query-scheduler weighted fair queuing is enabled. A tenant with weight 2 can consume twice as much as a tenant with weight 1 before its queries are delayed. 0 or a negative value is treated as 1.")
+	f.BoolVar(&l.TracingDebugEnabled, "frontend.tracing-debug-enabled", false, "[Experimental] If enabled, the query-frontend keeps the traces of all the queries of the tenant, when the tail sampling is enabled.")
+	f.StringVar(&l.QueryRateStrategy, "frontend.query-rate-strategy", LocalQueryRateStrategy, "Whether the query rate limits should be applied individually to each query-frontend instance (local), or evenly shared across the query-frontends (global). The global strategy requires the query-frontends ring.")
+	f.Float64Var(&l.QueryRate, "frontend.query-rate", 0, "Per-tenant allowed rate of instant queries (requests per second). Requests beyond this error with HTTP 429. 0 to disable.")
+	f.IntVar(&l.QueryBurstSize, "frontend.query-burst-size", 0, "Per-tenant allowed burst of instant queries. 0 to use the rate, rounded up.")
+	f.Float64Var(&l.QueryRangeRate, "frontend.query-range-rate", 0, "Per-tenant allowed rate of range queries (requests per second). Requests beyond this error with HTTP 429. 0 to disable.")
+	f.IntVar(&l.QueryRangeBurstSize, "frontend.query-range-burst-size", 0, "Per-tenant allowed burst of range queries. 0 to use the rate, rounded up.")
+	f.Float64Var(&l.SeriesQueryRate, "frontend.series-query-rate", 0, "Per-tenant allowed rate of series requests (requests per second). Requests beyond this error with HTTP 429. 0 to disable.")
+	f.IntVar(&l.SeriesQueryBurstSize, "frontend.series-query-burst-size", 0, "Per-tenant allowed burst of series requests. 0 to use the rate, rounded up.")
+	f.Float64Var(&l.LabelsQueryRate, "frontend.labels-query-rate", 0, "Per-tenant allowed rate of label names and values requests (requests per second). Requests beyond this error with HTTP 429. 0 to disable.")
+	f.IntVar(&l.LabelsQueryBurstSize, "frontend.labels-query-burst-size", 0, "Per-tenant allowed burst of label names and values requests. 0 to use the rate, rounded up.")
+	f.Float64Var(&l.RemoteReadRate, "frontend.remote-read-rate", 0, "Per-tenant allowed rate of remote read requests (requests per second). Requests beyond this error with HTTP 429. 0 to disable.")
+	f.IntVar(&l.RemoteReadBurstSize, "frontend.remote-read-burst-size", 0, "Per-tenant allowed burst of remote read requests. 0 to use the rate, rounded up.")
 
 	f.Var(&l.RulerEvaluationDelay, "ruler.evaluation-delay-duration", "Deprecated(use ruler.query-offset instead) and will be removed in v1.19.0: Duration to delay the evaluation of rules to ensure the underlying metrics have been pushed to Cortex.")
 	f.Float64Var(&l.RulerTenantShardSize, "ruler.tenant-shard-size", 0, "The default tenant's shard size when the shuffle-sharding strategy is used by ruler. When this setting is specified in the per-tenant overrides, a value of 0 disables shuffle sharding for the tenant. If the value is < 1 the shard size will be a percentage of the total rulers.")
@@ -344,6 +450,12 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&l.ParquetMaxFetchedChunkBytes, "querier.parquet-queryable.max-fetched-chunk-bytes", 0, "The maximum number of bytes that can be used to fetch chunk column pages when querying parquet storage. 0 to disable.")
 	f.IntVar(&l.ParquetMaxFetchedDataBytes, "querier.parquet-queryable.max-fetched-data-bytes", 0, "The maximum number of bytes that can be used to fetch all column pages when querying parquet storage. 0 to disable.")
 
+	// Export.
+	f.IntVar(&l.ExportMaxConcurrentJobs, "export.max-concurrent-jobs-per-tenant", 1, "The maximum number of pending or running export jobs per tenant. 0 to disable.")
+	_ = l.ExportMaxTimeRange.Set("0s")
+	f.Var(&l.ExportMaxTimeRange, "export.max-time-range", "The maximum time range of an export job. 0 to disable.")
+	f.Int64Var(&l.ExportMaxBytes, "export.max-bytes", 0, "The maximum number of bytes written by an export job. The job fails once the limit is exceeded. 0 to disable.")
+
 	// Store-gateway.
 	f.Float64Var(&l.StoreGatewayTenantShardSize, "store-gateway.tenant-shard-size", 0, "The default tenant's shard size when the shuffle-sharding strategy is used. Must be set when the store-gateway sharding is enabled with the shuffle-sharding strategy. When this setting is specified in the per-tenant overrides, a value of 0 disables shuffle sharding for the tenant. If the value is < 1 the shard size will be a percentage of the total store-gateways.")
 	f.IntVar(&l.MaxDownloadedBytesPerRequest, "store-gateway.max-downloaded-bytes-per-request", 0, "The maximum number of data bytes to download per gRPC request in Store Gateway, including Series/LabelNames/LabelValues requests. 0 to disable.")
@@ -388,6 +500,12 @@ func (l *Limits) Validate(nameValidationScheme model.ValidationScheme, shardByAl
 		return errMaxLocalNativeHistogramSeriesPerUserValidation
 	}
 
+	switch l.QueryRateStrategy {
+	case "", LocalQueryRateStrategy, GlobalQueryRateStrategy:
+	default:
+		return fmt.Errorf("%w: %q, supported values are %s and %s", errInvalidQueryRateStrategy, l.QueryRateStrategy, LocalQueryRateStrategy, GlobalQueryRateStrategy)
+	}
+
 	if err := l.RulerExternalLabels.Validate(func(l labels.Label) error {
 		if !nameValidationScheme.IsValidLabelName(l.Name) {
 			return fmt.Errorf("%w: %q", errInvalidLabelName, l.Name)
@@ -428,6 +546,14 @@ func (l *Limits) UnmarshalYAML(unmarshal func(any) error) error {
 		return err
 	}
 
+	if err := l.compileQueryAccessPolicies(); err != nil {
+		return err
+	}
+
+	if err := l.compileAggregationRules(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -458,6 +584,14 @@ func (l *Limits) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	if err := l.compileQueryAccessPolicies(); err != nil {
+		return err
+	}
+
+	if err := l.compileAggregationRules(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -477,6 +611,64 @@ func (l *Limits) calculateMaxSeriesPerLabelSetId() error {
 	return nil
 }
 
+func (l *Limits) compileQueryAccessPolicies() error {
+	names := map[string]struct{}{}
+
+	for i, policy := range l.QueryAccessPolicies {
+		if !queryAccessPolicyNameRegexp.MatchString(policy.Name) {
+			return errInvalidQueryAccessPolicyName
+		}
+		if _, ok := names[policy.Name]; ok {
+			return errDuplicateQueryAccessPolicy
+		}
+		names[policy.Name] = struct{}{}
+
+		matchers, err := parser.ParseMetricSelector(policy.Selector)
+		if err != nil {
+			return fmt.Errorf("invalid selector of the query access policy %s: %w", policy.Name, err)
+		}
+		l.QueryAccessPolicies[i].Matchers = matchers
+	}
+
+	if _, ok := names[l.MandatoryQueryAccessPolicy]; l.MandatoryQueryAccessPolicy != "" && !ok {
+		return errUnknownMandatoryQueryAccessPolicy
+	}
+
+	return nil
+}
+
+func (l *Limits) compileAggregationRules() error {
+	outputs := map[string]struct{}{}
+
+	for i, rule := range l.AggregationRules {
+		if !model.LegacyValidation.IsValidMetricName(rule.Output) {
+			return fmt.Errorf("invalid output metric name of the aggregation rule: %q", rule.Output)
+		}
+		if _, ok := outputs[rule.Output]; ok {
+			return errDuplicateAggregationRuleOutput
+		}
+		outputs[rule.Output] = struct{}{}
+
+		if !slices.Contains(supportedAggregationOperations, rule.Operation) {
+			return fmt.Errorf("unsupported operation %q of the aggregation rule %s", rule.Operation, rule.Output)
+		}
+		if len(rule.By) > 0 && len(rule.Without) > 0 {
+			return errAggregationRuleByAndWithout
+		}
+		if rule.Interval == 0 {
+			l.AggregationRules[i].Interval = model.Duration(time.Minute)
+		}
+
+		matchers, err := parser.ParseMetricSelector(rule.Match)
+		if err != nil {
+			return fmt.Errorf("invalid match selector of the aggregation rule %s: %w", rule.Output, err)
+		}
+		l.AggregationRules[i].Matchers = matchers
+	}
+
+	return nil
+}
+
 func (l *Limits) copyNotificationIntegrationLimits(defaults NotificationRateLimitMap) {
 	l.NotificationRateLimitPerIntegration = make(map[string]float64, len(defaults))
 	maps.Copy(l.NotificationRateLimitPerIntegration, defaults)
@@ -840,16 +1032,112 @@ func (o *Overrides) MaxOutstandingPerTenant(userID string) int {
 	return o.GetOverridesForUser(userID).MaxOutstandingPerTenant
 }
 
+// FairQueuingWeight returns the share of the queriers the tenant gets relative to the other tenants,
+// when the query-scheduler weighted fair queuing is enabled.
+func (o *Overrides) FairQueuingWeight(userID string) float64 {
+	return o.GetOverridesForUser(userID).FairQueuingWeight
+}
+
+// TracingDebugEnabled returns whether the traces of all the queries of the tenant are kept,
+// when the tail sampling is enabled.
+func (o *Overrides) TracingDebugEnabled(userID string) bool {
+	return o.GetOverridesForUser(userID).TracingDebugEnabled
+}
+
 // QueryPriority returns the query priority config for the tenant, including different priorities and their attributes
 func (o *Overrides) QueryPriority(userID string) QueryPriority {
 	return o.GetOverridesForUser(userID).QueryPriority
 }
 
+// QueryAccessPolicy returns the query access policy of the tenant with the given name,
+// or false if it doesn't exist.
+func (o *Overrides) QueryAccessPolicy(userID, name string) (QueryAccessPolicy, bool) {
+	for _, policy := range o.GetOverridesForUser(userID).QueryAccessPolicies {
+		if policy.Name == name {
+			return policy, true
+		}
+	}
+	return QueryAccessPolicy{}, false
+}
+
+// MandatoryQueryAccessPolicy returns the name of the query access policy applied to every
+// query of the tenant, or an empty string if none.
+func (o *Overrides) MandatoryQueryAccessPolicy(userID string) string {
+	return o.GetOverridesForUser(userID).MandatoryQueryAccessPolicy
+}
+
+// AggregationRules returns the streaming aggregation rules of the tenant.
+func (o *Overrides) AggregationRules(userID string) []AggregationRule {
+	return o.GetOverridesForUser(userID).AggregationRules
+}
+
+// AggregationMaxSeries returns the maximum number of input series of the tenant tracked by a distributor to aggregate them.
+func (o *Overrides) AggregationMaxSeries(userID string) int {
+	return o.GetOverridesForUser(userID).AggregationMaxSeries
+}
+
 // QueryRejection returns the query reject config for the tenant
 func (o *Overrides) QueryRejection(userID string) QueryRejection {
 	return o.GetOverridesForUser(userID).QueryRejection
 }
 
+// QueryRateStrategy returns whether the query rate limits should be individually applied
+// to each query-frontend instance (local) or evenly shared across the query-frontends (global).
+func (o *Overrides) QueryRateStrategy() string {
+	// The query rate strategy can't be overridden on a per-tenant basis
+	return o.defaultLimits.QueryRateStrategy
+}
+
+// QueryRate returns the limit on instant queries (requests per second).
+func (o *Overrides) QueryRate(userID string) float64 {
+	return o.GetOverridesForUser(userID).QueryRate
+}
+
+// QueryBurstSize returns the burst size for instant queries.
+func (o *Overrides) QueryBurstSize(userID string) int {
+	return o.GetOverridesForUser(userID).QueryBurstSize
+}
+
+// QueryRangeRate returns the limit on range queries (requests per second).
+func (o *Overrides) QueryRangeRate(userID string) float64 {
+	return o.GetOverridesForUser(userID).QueryRangeRate
+}
+
+// QueryRangeBurstSize returns the burst size for range queries.
+func (o *Overrides) QueryRangeBurstSize(userID string) int {
+	return o.GetOverridesForUser(userID).QueryRangeBurstSize
+}
+
+// SeriesQueryRate returns the limit on series requests (requests per second).
+func (o *Overrides) SeriesQueryRate(userID string) float64 {
+	return o.GetOverridesForUser(userID).SeriesQueryRate
+}
+
+// SeriesQueryBurstSize returns the burst size for series requests.
+func (o *Overrides) SeriesQueryBurstSize(userID string) int {
+	return o.GetOverridesForUser(userID).SeriesQueryBurstSize
+}
+
+// LabelsQueryRate returns the limit on label names and values requests (requests per second).
+func (o *Overrides) LabelsQueryRate(userID string) float64 {
+	return o.GetOverridesForUser(userID).LabelsQueryRate
+}
+
+// LabelsQueryBurstSize returns the burst size for label names and values requests.
+func (o *Overrides) LabelsQueryBurstSize(userID string) int {
+	return o.GetOverridesForUser(userID).LabelsQueryBurstSize
+}
+
+// RemoteReadRate returns the limit on remote read requests (requests per second).
+func (o *Overrides) RemoteReadRate(userID string) float64 {
+	return o.GetOverridesForUser(userID).RemoteReadRate
+}
+
+// RemoteReadBurstSize returns the burst size for remote read requests.
+func (o *Overrides) RemoteReadBurstSize(userID string) int {
+	return o.GetOverridesForUser(userID).RemoteReadBurstSize
+}
+
 // EnforceMetricName whether to enforce the presence of a metric name.
 func (o *Overrides) EnforceMetricName(userID string) bool {
 	return o.GetOverridesForUser(userID).EnforceMetricName
@@ -936,6 +1224,21 @@ func (o *Overrides) ParquetMaxFetchedDataBytes(userID string) int {
 	return o.GetOverridesForUser(userID).ParquetMaxFetchedDataBytes
 }
 
+// ExportMaxConcurrentJobs returns the maximum number of pending or running export jobs for the tenant.
+func (o *Overrides) ExportMaxConcurrentJobs(userID string) int {
+	return o.GetOverridesForUser(userID).ExportMaxConcurrentJobs
+}
+
+// ExportMaxTimeRange returns the maximum time range of an export job of the tenant.
+func (o *Overrides) ExportMaxTimeRange(userID string) time.Duration {
+	return time.Duration(o.GetOverridesForUser(userID).ExportMaxTimeRange)
+}
+
+// ExportMaxBytes returns the maximum number of bytes written by an export job of the tenant.
+func (o *Overrides) ExportMaxBytes(userID string) int64 {
+	return o.GetOverridesForUser(userID).ExportMaxBytes
+}
+
 // CompactorPartitionIndexSizeBytes returns shard size (number of rulers) used by this tenant when using shuffle-sharding strategy.
 func (o *Overrides) CompactorPartitionIndexSizeBytes(userID string) int64 {
 	return o.GetOverridesForUser(userID).CompactorPartitionIndexSizeBytes
@@ -1132,6 +1435,11 @@ func (o *Overrides) RulerExternalLabels(userID string) labels.Labels {
 	return o.GetOverridesForUser(userID).RulerExternalLabels
 }
 
+// RulerAlertmanagerConfig returns the overrides of the Alertmanagers the ruler sends the alerts of a given user to.
+func (o *Overrides) RulerAlertmanagerConfig(userID string) RulerAlertmanagerConfig {
+	return o.GetOverridesForUser(userID).RulerAlertmanagerConfig
+}
+
 // MaxRegexPatternLength returns the maximum length of an unoptimized regex pattern.
 // This is only used in Ingester.
 func (o *Overrides) MaxRegexPatternLength(userID string) int {
@@ -139,6 +139,14 @@ func TestLimits_Validate(t *testing.T) {
 			expected:             errInvalidLabelValue,
 			nameValidationScheme: model.UTF8Validation,
 		},
+		"global query rate strategy": {
+			limits:   Limits{QueryRateStrategy: GlobalQueryRateStrategy},
+			expected: nil,
+		},
+		"invalid query rate strategy": {
+			limits:   Limits{QueryRateStrategy: "invalid"},
+			expected: errInvalidQueryRateStrategy,
+		},
 	}
 
 	for testName, testData := range tests {
@@ -282,6 +290,91 @@ limits_per_label_set:
 	require.Equal(t, err, errDuplicatePerLabelSetLimit)
 }
 
+func TestOverrides_QueryAccessPolicies(t *testing.T) {
+	inputYAML := `
+query_access_policies:
+  - name: team-a
+    selector: '{namespace=~"team-a-.*", env!="dev"}'
+`
+
+	limitsYAML := Limits{}
+	err := yaml.Unmarshal([]byte(inputYAML), &limitsYAML)
+	require.NoError(t, err)
+
+	limitsJSON := Limits{}
+	err = json.Unmarshal([]byte(`{"query_access_policies": [{"name": "team-a", "selector": "{namespace=~\"team-a-.*\", env!=\"dev\"}"}]}`), &limitsJSON)
+	require.NoError(t, err)
+	require.Len(t, limitsJSON.QueryAccessPolicies, 1)
+	require.Equal(t, limitsYAML.QueryAccessPolicies[0].Selector, limitsJSON.QueryAccessPolicies[0].Selector)
+	require.Equal(t, fmt.Sprint(limitsYAML.QueryAccessPolicies[0].Matchers), fmt.Sprint(limitsJSON.QueryAccessPolicies[0].Matchers))
+
+	overrides := NewOverrides(Limits{}, newMockTenantLimits(map[string]*Limits{"user-1": &limitsYAML}))
+
+	policy, ok := overrides.QueryAccessPolicy("user-1", "team-a")
+	require.True(t, ok)
+	require.Equal(t, `[namespace=~"team-a-.*" env!="dev"]`, fmt.Sprint(policy.Matchers))
+
+	_, ok = overrides.QueryAccessPolicy("user-1", "team-b")
+	require.False(t, ok)
+	_, ok = overrides.QueryAccessPolicy("user-2", "team-a")
+	require.False(t, ok)
+	require.Equal(t, "", overrides.MandatoryQueryAccessPolicy("user-1"))
+
+	limitsYAML.MandatoryQueryAccessPolicy = "team-a"
+	require.Equal(t, "team-a", overrides.MandatoryQueryAccessPolicy("user-1"))
+
+	for input, expectedErr := range map[string]string{
+		"query_access_policies: [{name: a, selector: '{a=\"1\"}'}, {name: a, selector: '{a=\"2\"}'}]":   errDuplicateQueryAccessPolicy.Error(),
+		"query_access_policies: [{name: 'a:b', selector: '{a=\"1\"}'}]":                                 errInvalidQueryAccessPolicyName.Error(),
+		"query_access_policies: [{name: a, selector: '{a='}]":                                           "invalid selector of the query access policy a",
+		"{query_access_policies: [{name: a, selector: '{a=\"1\"}'}], mandatory_query_access_policy: b}": errUnknownMandatoryQueryAccessPolicy.Error(),
+	} {
+		err = yaml.Unmarshal([]byte(input), &Limits{})
+		require.ErrorContains(t, err, expectedErr)
+	}
+}
+
+func TestOverrides_AggregationRules(t *testing.T) {
+	inputYAML := `
+aggregation_rules:
+  - match: '{__name__="http_requests_total"}'
+    output: job:http_requests_total:rate1m
+    operation: rate
+    without: [pod]
+    drop_input: true
+`
+
+	limitsYAML := Limits{}
+	err := yaml.Unmarshal([]byte(inputYAML), &limitsYAML)
+	require.NoError(t, err)
+
+	limitsJSON := Limits{}
+	err = json.Unmarshal([]byte(`{"aggregation_rules": [{"match": "{__name__=\"http_requests_total\"}", "output": "job:http_requests_total:rate1m", "operation": "rate", "without": ["pod"], "drop_input": true}]}`), &limitsJSON)
+	require.NoError(t, err)
+	require.Len(t, limitsJSON.AggregationRules, 1)
+	require.Equal(t, fmt.Sprint(limitsYAML.AggregationRules[0].Matchers), fmt.Sprint(limitsJSON.AggregationRules[0].Matchers))
+
+	overrides := NewOverrides(Limits{}, newMockTenantLimits(map[string]*Limits{"user-1": &limitsYAML}))
+
+	rules := overrides.AggregationRules("user-1")
+	require.Len(t, rules, 1)
+	require.Equal(t, `[__name__="http_requests_total"]`, fmt.Sprint(rules[0].Matchers))
+	require.Equal(t, model.Duration(time.Minute), rules[0].Interval)
+	require.True(t, rules[0].DropInput)
+	require.Empty(t, overrides.AggregationRules("user-2"))
+
+	for input, expectedErr := range map[string]string{
+		"aggregation_rules: [{match: '{a=\"1\"}', output: a, operation: sum}, {match: '{a=\"2\"}', output: a, operation: max}]": errDuplicateAggregationRuleOutput.Error(),
+		"aggregation_rules: [{match: '{a=\"1\"}', output: 'a-b', operation: sum}]":                                              "invalid output metric name of the aggregation rule",
+		"aggregation_rules: [{match: '{a=\"1\"}', output: a, operation: quantile}]":                                             `unsupported operation "quantile" of the aggregation rule a`,
+		"aggregation_rules: [{match: '{a=\"1\"}', output: a, operation: sum, by: [b], without: [c]}]":                           errAggregationRuleByAndWithout.Error(),
+		"aggregation_rules: [{match: '{a=', output: a, operation: sum}]":                                                        "invalid match selector of the aggregation rule a",
+	} {
+		err = yaml.Unmarshal([]byte(input), &Limits{})
+		require.ErrorContains(t, err, expectedErr)
+	}
+}
+
 func TestLimitsStringDurationYamlMatchJson(t *testing.T) {
 	inputYAML := `
 max_query_lookback: 1s
@@ -339,6 +432,43 @@ metric_relabel_configs:
 	assert.Equal(t, []*relabel.Config{&exp}, l.MetricRelabelConfigs)
 }
 
+func TestRulerAlertmanagerConfigLoadingFromYaml(t *testing.T) {
+	SetDefaultLimitsForYAMLUnmarshalling(Limits{})
+
+	inp := `
+ruler_alertmanager_config:
+  alertmanager_url: https://alertmanager.example.com
+  basic_auth_username: user
+  basic_auth_password: password
+  tls_ca_path: /path/to/ca
+  alert_relabel_configs:
+  - action: drop
+    source_labels: [severity]
+    regex: debug
+`
+	exp := relabel.DefaultRelabelConfig
+	exp.Action = relabel.Drop
+	regex, err := relabel.NewRegexp("debug")
+	require.NoError(t, err)
+	exp.Regex = regex
+	exp.SourceLabels = model.LabelNames([]model.LabelName{"severity"})
+
+	l := Limits{}
+	err = yaml.UnmarshalStrict([]byte(inp), &l)
+	require.NoError(t, err)
+
+	overrides := NewOverrides(Limits{}, newMockTenantLimits(map[string]*Limits{"user-1": &l}))
+	assert.Equal(t, RulerAlertmanagerConfig{
+		AlertmanagerURL:     "https://alertmanager.example.com",
+		BasicAuthUsername:   "user",
+		BasicAuthPassword:   flagext.Secret{Value: "password"},
+		TLSCAPath:           "/path/to/ca",
+		AlertRelabelConfigs: []*relabel.Config{&exp},
+	}, overrides.RulerAlertmanagerConfig("user-1"))
+	assert.True(t, overrides.RulerAlertmanagerConfig("user-1").HasTLSConfig())
+	assert.False(t, overrides.RulerAlertmanagerConfig("user-2").HasTLSConfig())
+}
+
 func TestSmallestPositiveIntPerTenant(t *testing.T) {
 	tenantLimits := map[string]*Limits{
 		"tenant-a": {
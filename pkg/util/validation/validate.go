@@ -66,6 +66,7 @@ const (
 	// Declared here to avoid duplication in ingester and distributor.
 	RateLimited                = "rate_limited"
 	NativeHistogramRateLimited = "native_histogram_rate_limited"
+	PerLabelSetRateLimited     = "per_labelset_rate_limited"
 
 	// Too many HA clusters is one of the reasons for discarding samples.
 	TooManyHAClusters = "too_many_ha_clusters"
@@ -398,6 +398,16 @@ func getCustomFieldEntry(parent reflect.Type, field reflect.StructField, fieldVa
 		if err != nil {
 			return nil, err
 		}
+		if fieldFlag == nil {
+			return &configEntry{
+				kind:         "field",
+				name:         getFieldName(field),
+				required:     isFieldRequired(field),
+				fieldDesc:    getFieldDescription(field, ""),
+				fieldType:    "string",
+				fieldDefault: getFieldDefault(field, ""),
+			}, nil
+		}
 
 		return &configEntry{
 			kind:         "field",
@@ -32,6 +32,11 @@ type ProxyConfig struct {
 	CompareResponses               bool
 	ValueComparisonTolerance       float64
 	PassThroughNonRegisteredRoutes bool
+	RecordFile                     string
+	MismatchReportFile             string
+	ReplayFile                     string
+	ReplayRate                     float64
+	ReplayConcurrency              int
 }
 
 func (cfg *ProxyConfig) RegisterFlags(f *flag.FlagSet) {
@@ -42,6 +47,11 @@ func (cfg *ProxyConfig) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&cfg.CompareResponses, "proxy.compare-responses", false, "Compare responses between preferred and secondary endpoints for supported routes.")
 	f.Float64Var(&cfg.ValueComparisonTolerance, "proxy.value-comparison-tolerance", 0.000001, "The tolerance to apply when comparing floating point values in the responses. 0 to disable tolerance and require exact match (not recommended).")
 	f.BoolVar(&cfg.PassThroughNonRegisteredRoutes, "proxy.passthrough-non-registered-routes", false, "Passthrough requests for non-registered routes to preferred backend.")
+	f.StringVar(&cfg.RecordFile, "proxy.record-file", "", "If set, the requests received for the registered routes are appended to this file, so that they can be replayed later via -replay.file.")
+	f.StringVar(&cfg.MismatchReportFile, "proxy.mismatch-report-file", "", "If set, every request whose responses don't match between the preferred and secondary backends is appended to this file, along with the differing series. Requires -proxy.compare-responses.")
+	f.StringVar(&cfg.ReplayFile, "replay.file", "", "If set, the query-tee replays the requests recorded in this file against the backends, compares the responses and exits, instead of running the proxy service. Requires -proxy.compare-responses.")
+	f.Float64Var(&cfg.ReplayRate, "replay.rate", 10, "The maximum number of recorded requests replayed per second. 0 to disable the limit.")
+	f.IntVar(&cfg.ReplayConcurrency, "replay.concurrency", 4, "The maximum number of recorded requests replayed concurrently.")
 }
 
 type Route struct {
@@ -57,6 +67,8 @@ type Proxy struct {
 	logger   log.Logger
 	metrics  *ProxyMetrics
 	routes   []Route
+	recorder *Recorder
+	reporter *MismatchReporter
 
 	// The HTTP server used to run the proxy service.
 	srv         *http.Server
@@ -71,6 +83,18 @@ func NewProxy(cfg ProxyConfig, logger log.Logger, routes []Route, registerer pro
 		return nil, fmt.Errorf("when enabling comparison of results -backend.preferred flag must be set to hostname of preferred backend")
 	}
 
+	if cfg.MismatchReportFile != "" && !cfg.CompareResponses {
+		return nil, fmt.Errorf("when enabling the mismatch report -proxy.compare-responses flag must be set")
+	}
+
+	if cfg.ReplayFile != "" && !cfg.CompareResponses {
+		return nil, fmt.Errorf("when replaying recorded requests -proxy.compare-responses flag must be set")
+	}
+
+	if cfg.ReplayFile != "" && cfg.ReplayConcurrency < 1 {
+		return nil, fmt.Errorf("when replaying recorded requests -replay.concurrency flag must be greater than 0")
+	}
+
 	if cfg.PassThroughNonRegisteredRoutes && cfg.PreferredBackend == "" {
 		return nil, fmt.Errorf("when enabling passthrough for non-registered routes -backend.preferred flag must be set to hostname of backend where those requests needs to be passed")
 	}
@@ -140,9 +164,44 @@ func NewProxy(cfg ProxyConfig, logger log.Logger, routes []Route, registerer pro
 		level.Warn(p.logger).Log("msg", "The proxy is running with only 1 backend. At least 2 backends are required to fulfil the purpose of the proxy and compare results.")
 	}
 
+	// The replayed requests are never recorded, so that a replay doesn't grow the file it reads.
+	if cfg.RecordFile != "" && cfg.ReplayFile != "" {
+		level.Warn(p.logger).Log("msg", "The record file is ignored when replaying recorded requests", "record_file", cfg.RecordFile)
+	} else if cfg.RecordFile != "" {
+		recorder, err := NewRecorder(cfg.RecordFile)
+		if err != nil {
+			return nil, err
+		}
+		p.recorder = recorder
+	}
+
+	if cfg.MismatchReportFile != "" {
+		reporter, err := NewMismatchReporter(cfg.MismatchReportFile)
+		if err != nil {
+			_ = p.closeFiles()
+			return nil, err
+		}
+		p.reporter = reporter
+	}
+
 	return p, nil
 }
 
+func (p *Proxy) newEndpoint(route Route) *ProxyEndpoint {
+	var comparator ResponsesComparator
+	if p.cfg.CompareResponses {
+		comparator = route.ResponseComparator
+	}
+
+	endpoint := NewProxyEndpoint(p.backends, route.RouteName, p.metrics, p.logger, comparator)
+	endpoint.recorder = p.recorder
+	if comparator != nil {
+		endpoint.reporter = p.reporter
+	}
+
+	return endpoint
+}
+
 func (p *Proxy) Start() error {
 	// Setup listener first, so we can fail early if the port is in use.
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", p.cfg.ServerServicePort))
@@ -159,11 +218,7 @@ func (p *Proxy) Start() error {
 
 	// register routes
 	for _, route := range p.routes {
-		var comparator ResponsesComparator
-		if p.cfg.CompareResponses {
-			comparator = route.ResponseComparator
-		}
-		router.Path(route.Path).Methods(route.Methods...).Handler(NewProxyEndpoint(p.backends, route.RouteName, p.metrics, p.logger, comparator))
+		router.Path(route.Path).Methods(route.Methods...).Handler(p.newEndpoint(route))
 	}
 
 	if p.cfg.PassThroughNonRegisteredRoutes {
@@ -195,11 +250,31 @@ func (p *Proxy) Start() error {
 }
 
 func (p *Proxy) Stop() error {
-	if p.srv == nil {
-		return nil
+	if p.srv != nil {
+		if err := p.srv.Shutdown(context.Background()); err != nil {
+			return err
+		}
+	}
+
+	return p.closeFiles()
+}
+
+func (p *Proxy) closeFiles() error {
+	var lastErr error
+
+	if p.recorder != nil {
+		if err := p.recorder.Close(); err != nil {
+			lastErr = err
+		}
+	}
+
+	if p.reporter != nil {
+		if err := p.reporter.Close(); err != nil {
+			lastErr = err
+		}
 	}
 
-	return p.srv.Shutdown(context.Background())
+	return lastErr
 }
 
 func (p *Proxy) Await() {
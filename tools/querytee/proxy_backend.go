@@ -1,6 +1,7 @@
 package querytee
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"net"
@@ -51,8 +52,10 @@ func NewProxyBackend(name string, endpoint *url.URL, timeout time.Duration, pref
 	}
 }
 
-func (b *ProxyBackend) ForwardRequest(orig *http.Request) (int, []byte, error) {
-	req, err := b.createBackendRequest(orig)
+// ForwardRequest sends the input request to the backend. The request body has already been
+// read by the caller, because it's sent to every backend.
+func (b *ProxyBackend) ForwardRequest(orig *http.Request, body []byte) (int, []byte, error) {
+	req, err := b.createBackendRequest(orig, body)
 	if err != nil {
 		return 0, nil, err
 	}
@@ -60,12 +63,22 @@ func (b *ProxyBackend) ForwardRequest(orig *http.Request) (int, []byte, error) {
 	return b.doBackendRequest(req)
 }
 
-func (b *ProxyBackend) createBackendRequest(orig *http.Request) (*http.Request, error) {
-	req, err := http.NewRequest(orig.Method, orig.URL.String(), nil)
+func (b *ProxyBackend) createBackendRequest(orig *http.Request, body []byte) (*http.Request, error) {
+	var reqBody io.Reader
+	if len(body) > 0 {
+		reqBody = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(orig.Method, orig.URL.String(), reqBody)
 	if err != nil {
 		return nil, err
 	}
 
+	// Keep the content type, so that the backend can parse the POST form body.
+	if contentType := orig.Header.Get("Content-Type"); contentType != "" && reqBody != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
 	// Replace the endpoint with the backend one.
 	req.URL.Scheme = b.endpoint.Scheme
 	req.URL.Host = b.endpoint.Host
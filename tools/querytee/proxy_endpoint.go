@@ -2,6 +2,7 @@ package querytee
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"sync"
@@ -28,6 +29,10 @@ type ProxyEndpoint struct {
 
 	// The route name used to track metrics.
 	routeName string
+
+	// Optional recorder of the received requests and reporter of the responses mismatches.
+	recorder *Recorder
+	reporter *MismatchReporter
 }
 
 func NewProxyEndpoint(backends []*ProxyBackend, routeName string, metrics *ProxyMetrics, logger log.Logger, comparator ResponsesComparator) *ProxyEndpoint {
@@ -52,9 +57,22 @@ func NewProxyEndpoint(backends []*ProxyBackend, routeName string, metrics *Proxy
 func (p *ProxyEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	level.Debug(p.logger).Log("msg", "Received request", "path", r.URL.Path, "query", r.URL.RawQuery)
 
+	// Read the body upfront, because the same request is sent to all backends.
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if p.recorder != nil {
+		if err := p.recorder.Record(p.routeName, r, body); err != nil {
+			level.Warn(p.logger).Log("msg", "Unable to record request", "err", err)
+		}
+	}
+
 	// Send the same request to all backends.
 	resCh := make(chan *backendResponse, len(p.backends))
-	go p.executeBackendRequests(r, resCh)
+	go p.executeBackendRequests(r, body, resCh)
 
 	// Wait for the first response that's feasible to be sent back to the client.
 	downstreamRes := p.waitBackendResponseForDownstream(resCh)
@@ -71,7 +89,7 @@ func (p *ProxyEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	p.metrics.responsesTotal.WithLabelValues(downstreamRes.backend.name, r.Method, p.routeName).Inc()
 }
 
-func (p *ProxyEndpoint) executeBackendRequests(r *http.Request, resCh chan *backendResponse) {
+func (p *ProxyEndpoint) executeBackendRequests(r *http.Request, reqBody []byte, resCh chan *backendResponse) {
 	responses := make([]*backendResponse, 0, len(p.backends))
 
 	var (
@@ -86,7 +104,7 @@ func (p *ProxyEndpoint) executeBackendRequests(r *http.Request, resCh chan *back
 			defer wg.Done()
 
 			start := time.Now()
-			status, body, err := b.ForwardRequest(r)
+			status, body, err := b.ForwardRequest(r, reqBody)
 			elapsed := time.Since(start)
 
 			res := &backendResponse{
@@ -134,12 +152,25 @@ func (p *ProxyEndpoint) executeBackendRequests(r *http.Request, resCh chan *back
 			level.Error(util_log.Logger).Log("msg", "response comparison failed", "route-name", p.routeName,
 				"query", r.URL.RawQuery, "err", err)
 			result = comparisonFailed
+
+			if p.reporter != nil {
+				if reportErr := p.reporter.Report(p.routeName, r, reqBody, err); reportErr != nil {
+					level.Warn(p.logger).Log("msg", "Unable to report responses mismatch", "err", reportErr)
+				}
+			}
 		}
 
 		p.metrics.responsesComparedTotal.WithLabelValues(p.routeName, result).Inc()
 	}
 }
 
+// replay sends the input request to all backends and compares the responses, without
+// sending back any response. Replayed requests are never recorded.
+func (p *ProxyEndpoint) replay(r *http.Request, body []byte) {
+	resCh := make(chan *backendResponse, len(p.backends))
+	p.executeBackendRequests(r, body, resCh)
+}
+
 func (p *ProxyEndpoint) waitBackendResponseForDownstream(resCh chan *backendResponse) *backendResponse {
 	var (
 		responses                 = make([]*backendResponse, 0, len(p.backends))
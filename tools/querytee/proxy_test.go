@@ -1,10 +1,15 @@
 package querytee
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -12,8 +17,10 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
 )
 
 var testRoutes = []Route{
@@ -352,3 +359,120 @@ func mockQueryResponse(path string, status int, res string) http.HandlerFunc {
 		}
 	}
 }
+
+func TestProxy_RecordAndReplay(t *testing.T) {
+	var received atomic.Int64
+	backendHandler := func(value string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			received.Inc()
+
+			// The query must be forwarded, whether it's in the query string or in the form body.
+			if r.FormValue("query") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"foo":"bar"},"value":[1,"` + value + `"]}]}}`))
+		}
+	}
+
+	backend1 := httptest.NewServer(backendHandler("1"))
+	t.Cleanup(backend1.Close)
+	backend2 := httptest.NewServer(backendHandler("2"))
+	t.Cleanup(backend2.Close)
+
+	routes := []Route{
+		{Path: "/api/v1/query", RouteName: "api_v1_query", Methods: []string{"GET", "POST"}, ResponseComparator: NewSamplesComparator(0)},
+	}
+
+	dir := t.TempDir()
+	recordFile := filepath.Join(dir, "record.jsonl")
+	reportFile := filepath.Join(dir, "report.jsonl")
+
+	cfg := ProxyConfig{
+		BackendEndpoints:   backend1.URL + "," + backend2.URL,
+		PreferredBackend:   "0",
+		BackendReadTimeout: time.Second,
+		CompareResponses:   true,
+		RecordFile:         recordFile,
+	}
+
+	// Record the requests received by the proxy.
+	p, err := NewProxy(cfg, log.NewNopLogger(), routes, nil)
+	require.NoError(t, err)
+	require.NoError(t, p.Start())
+
+	getReq, err := http.NewRequest("GET", fmt.Sprintf("http://%s/api/v1/query?query=%s&time=1", p.Endpoint(), url.QueryEscape("up")), nil)
+	require.NoError(t, err)
+
+	postReq, err := http.NewRequest("POST", fmt.Sprintf("http://%s/api/v1/query", p.Endpoint()), strings.NewReader(url.Values{"query": {"sum(up)"}, "time": {"1"}}.Encode()))
+	require.NoError(t, err)
+	postReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	for _, req := range []*http.Request{getReq, postReq} {
+		req.Header.Set(orgIDHeader, "user-1")
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	}
+
+	require.NoError(t, p.Stop())
+
+	recorded := readJSONLines[RecordedRequest](t, recordFile)
+	require.Len(t, recorded, 2)
+	assert.Equal(t, "api_v1_query", recorded[0].Route)
+	assert.Equal(t, "GET", recorded[0].Method)
+	assert.Equal(t, "/api/v1/query", recorded[0].Path)
+	assert.Equal(t, "query=up&time=1", recorded[0].RawQuery)
+	assert.Equal(t, "user-1", recorded[0].OrgID)
+	assert.Empty(t, recorded[0].Body)
+	assert.Equal(t, "POST", recorded[1].Method)
+	assert.Empty(t, recorded[1].RawQuery)
+	assert.Equal(t, "application/x-www-form-urlencoded", recorded[1].ContentType)
+	assert.Equal(t, "query=sum%28up%29&time=1", recorded[1].Body)
+
+	// Replay the recorded requests, reporting the mismatches. The record file is kept
+	// configured, to check the replayed requests are not recorded again.
+	received.Store(0)
+	cfg.ReplayFile = recordFile
+	cfg.ReplayRate = 0
+	cfg.ReplayConcurrency = 1
+	cfg.MismatchReportFile = reportFile
+
+	p, err = NewProxy(cfg, log.NewNopLogger(), routes, nil)
+	require.NoError(t, err)
+	require.NoError(t, p.Replay(context.Background()))
+	require.NoError(t, p.Stop())
+
+	assert.Equal(t, int64(4), received.Load())
+	assert.Len(t, readJSONLines[RecordedRequest](t, recordFile), 2)
+
+	mismatches := readJSONLines[Mismatch](t, reportFile)
+	require.Len(t, mismatches, 2)
+	assert.Equal(t, "up", mismatches[0].Query)
+	assert.Equal(t, "sum(up)", mismatches[1].Query)
+
+	for _, mismatch := range mismatches {
+		assert.Equal(t, "api_v1_query", mismatch.Route)
+		assert.Equal(t, "user-1", mismatch.OrgID)
+		assert.Equal(t, "1", mismatch.At)
+		assert.Equal(t, `sample pair not matching for metric {foo="bar"}: expected value 1 for timestamp 1 but got 2`, mismatch.Error)
+		require.Len(t, mismatch.Series, 1)
+		assert.Equal(t, model.SampleValue(1), mismatch.Series[0].Expected.Values[0].Value)
+		assert.Equal(t, model.SampleValue(2), mismatch.Series[0].Actual.Values[0].Value)
+	}
+}
+
+func readJSONLines[T any](t *testing.T, path string) []T {
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var entries []T
+	for line := range strings.Lines(string(content)) {
+		var entry T
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
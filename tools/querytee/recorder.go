@@ -0,0 +1,113 @@
+package querytee
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RecordedRequest is a request received by the proxy, recorded to be replayed later.
+type RecordedRequest struct {
+	Time     time.Time `json:"time"`
+	Route    string    `json:"route"`
+	Method   string    `json:"method"`
+	Path     string    `json:"path"`
+	RawQuery string    `json:"raw_query,omitempty"`
+	OrgID    string    `json:"org_id,omitempty"`
+
+	// The form body of POST requests.
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body,omitempty"`
+}
+
+// request builds the HTTP request to replay the recorded one, along with its body.
+func (r RecordedRequest) request() (*http.Request, []byte, error) {
+	target := r.Path
+	if r.RawQuery != "" {
+		target += "?" + r.RawQuery
+	}
+
+	req, err := http.NewRequest(r.Method, target, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if r.OrgID != "" {
+		req.Header.Set(orgIDHeader, r.OrgID)
+	}
+	if r.ContentType != "" {
+		req.Header.Set("Content-Type", r.ContentType)
+	}
+
+	return req, []byte(r.Body), nil
+}
+
+// Recorder appends the requests received by the proxy to a file, one JSON-encoded request per line.
+type Recorder struct {
+	writer *jsonLinesWriter
+}
+
+func NewRecorder(path string) (*Recorder, error) {
+	writer, err := newJSONLinesWriter(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open the record file")
+	}
+
+	return &Recorder{writer: writer}, nil
+}
+
+// Record writes the input request, along with its body which has already been read by the caller.
+func (r *Recorder) Record(routeName string, req *http.Request, body []byte) error {
+	recorded := RecordedRequest{
+		Time:     time.Now(),
+		Route:    routeName,
+		Method:   req.Method,
+		Path:     req.URL.Path,
+		RawQuery: req.URL.RawQuery,
+		OrgID:    req.Header.Get(orgIDHeader),
+	}
+	if len(body) > 0 {
+		recorded.ContentType = req.Header.Get("Content-Type")
+		recorded.Body = string(body)
+	}
+
+	return r.writer.write(recorded)
+}
+
+func (r *Recorder) Close() error {
+	return r.writer.close()
+}
+
+// jsonLinesWriter appends JSON-encoded entries to a file, one per line. It's safe for concurrent use.
+type jsonLinesWriter struct {
+	mtx  sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+func newJSONLinesWriter(path string) (*jsonLinesWriter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jsonLinesWriter{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+func (w *jsonLinesWriter) write(entry any) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	return w.enc.Encode(entry)
+}
+
+func (w *jsonLinesWriter) close() error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	return w.file.Close()
+}
@@ -0,0 +1,92 @@
+package querytee
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+)
+
+// The max size of a recorded request line. Queries can be long, so we allow for lines
+// way bigger than the bufio.Scanner default.
+const maxRecordedRequestSize = 16 * 1024 * 1024
+
+// Replay sends the requests recorded in the configured replay file to the backends, at the
+// configured rate, and compares the responses. Mismatches are tracked like for the requests
+// received by the proxy. It returns once all recorded requests have been replayed.
+func (p *Proxy) Replay(ctx context.Context) error {
+	file, err := os.Open(p.cfg.ReplayFile)
+	if err != nil {
+		return errors.Wrap(err, "unable to open the replay file")
+	}
+	defer file.Close()
+
+	endpoints := make(map[string]*ProxyEndpoint, len(p.routes))
+	for _, route := range p.routes {
+		endpoints[route.RouteName] = p.newEndpoint(route)
+	}
+
+	limit := rate.Inf
+	if p.cfg.ReplayRate > 0 {
+		limit = rate.Limit(p.cfg.ReplayRate)
+	}
+
+	var (
+		limiter  = rate.NewLimiter(limit, 1)
+		inflight = make(chan struct{}, p.cfg.ReplayConcurrency)
+		wg       sync.WaitGroup
+		replayed int
+		skipped  int
+	)
+
+	// Wait until all in-flight requests completed, whatever the outcome.
+	defer wg.Wait()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxRecordedRequestSize)
+
+	for line := 1; scanner.Scan(); line++ {
+		var recorded RecordedRequest
+		if err := json.Unmarshal(scanner.Bytes(), &recorded); err != nil {
+			return errors.Wrapf(err, "unable to decode the recorded request at line %d", line)
+		}
+
+		endpoint, ok := endpoints[recorded.Route]
+		if !ok {
+			level.Warn(p.logger).Log("msg", "Skipped recorded request for unknown route", "route", recorded.Route, "line", line)
+			skipped++
+			continue
+		}
+
+		req, body, err := recorded.request()
+		if err != nil {
+			return errors.Wrapf(err, "invalid recorded request at line %d", line)
+		}
+
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		inflight <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-inflight }()
+			endpoint.replay(req, body)
+		})
+
+		replayed++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return errors.Wrap(err, "unable to read the replay file")
+	}
+
+	wg.Wait()
+
+	level.Info(p.logger).Log("msg", "Recorded requests replayed", "replayed", replayed, "skipped", skipped)
+	return nil
+}
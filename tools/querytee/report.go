@@ -0,0 +1,96 @@
+package querytee
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Mismatch is a request whose responses don't match between the preferred and secondary backends.
+type Mismatch struct {
+	Time     time.Time `json:"time"`
+	Route    string    `json:"route"`
+	Path     string    `json:"path"`
+	OrgID    string    `json:"org_id,omitempty"`
+	Query    string    `json:"query,omitempty"`
+	Matchers []string  `json:"matchers,omitempty"`
+	Start    string    `json:"start,omitempty"`
+	End      string    `json:"end,omitempty"`
+	Step     string    `json:"step,omitempty"`
+	At       string    `json:"at,omitempty"`
+	Error    string    `json:"error"`
+
+	// Series holds the series which differ, if the comparator was able to detect them.
+	Series []SeriesMismatch `json:"series,omitempty"`
+}
+
+// MismatchReporter appends the mismatches to a file, one JSON-encoded mismatch per line.
+type MismatchReporter struct {
+	writer *jsonLinesWriter
+}
+
+func NewMismatchReporter(path string) (*MismatchReporter, error) {
+	writer, err := newJSONLinesWriter(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open the mismatch report file")
+	}
+
+	return &MismatchReporter{writer: writer}, nil
+}
+
+// Report writes the mismatch detected comparing the responses to the input request, whose
+// body has already been read by the caller.
+func (r *MismatchReporter) Report(routeName string, req *http.Request, body []byte, comparisonErr error) error {
+	params := requestParams(req, body)
+	mismatch := Mismatch{
+		Time:     time.Now(),
+		Route:    routeName,
+		Path:     req.URL.Path,
+		OrgID:    req.Header.Get(orgIDHeader),
+		Query:    params.Get("query"),
+		Matchers: params["match[]"],
+		Start:    params.Get("start"),
+		End:      params.Get("end"),
+		Step:     params.Get("step"),
+		At:       params.Get("time"),
+		Error:    comparisonErr.Error(),
+	}
+
+	var seriesErr *SeriesMismatchError
+	if errors.As(comparisonErr, &seriesErr) {
+		mismatch.Series = seriesErr.Series
+	}
+
+	return r.writer.write(mismatch)
+}
+
+func (r *MismatchReporter) Close() error {
+	return r.writer.close()
+}
+
+// requestParams returns the parameters of the input request, from both the query string and the
+// POST form body, like the Prometheus API does.
+func requestParams(req *http.Request, body []byte) url.Values {
+	params := req.URL.Query()
+
+	contentType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if len(body) == 0 || contentType != "application/x-www-form-urlencoded" {
+		return params
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return params
+	}
+
+	// The form body takes precedence over the query string.
+	for name, values := range params {
+		if _, ok := form[name]; !ok {
+			form[name] = values
+		}
+	}
+	return form
+}
@@ -3,7 +3,9 @@ package querytee
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"math"
+	"slices"
 
 	"github.com/go-kit/log/level"
 	"github.com/pkg/errors"
@@ -21,6 +23,37 @@ type SamplesResponse struct {
 		ResultType string
 		Result     json.RawMessage
 	}
+	Warnings []string
+	Infos    []string
+}
+
+// SeriesMismatch holds a series whose samples don't match between the expected and actual
+// responses. Expected or Actual is nil if the series is missing from the respective response.
+type SeriesMismatch struct {
+	Expected *model.SampleStream `json:"expected"`
+	Actual   *model.SampleStream `json:"actual"`
+}
+
+// SeriesMismatchError is returned by the samples comparator when the expected and actual
+// responses don't match, and holds the series which differ.
+type SeriesMismatchError struct {
+	err    error
+	Series []SeriesMismatch
+}
+
+func newSeriesMismatchError(err error, expected, actual *model.SampleStream) *SeriesMismatchError {
+	return &SeriesMismatchError{
+		err:    err,
+		Series: []SeriesMismatch{{Expected: expected, Actual: actual}},
+	}
+}
+
+func (e *SeriesMismatchError) Error() string {
+	return e.err.Error()
+}
+
+func (e *SeriesMismatchError) Unwrap() error {
+	return e.err
 }
 
 func NewSamplesComparator(tolerance float64) *SamplesComparator {
@@ -70,7 +103,171 @@ func (s *SamplesComparator) Compare(expectedResponse, actualResponse []byte) err
 		return fmt.Errorf("resultType %s not registered for comparison", expected.Data.ResultType)
 	}
 
-	return comparator(expected.Data.Result, actual.Data.Result, s.tolerance)
+	if err := comparator(expected.Data.Result, actual.Data.Result, s.tolerance); err != nil {
+		return err
+	}
+
+	return compareAnnotations(expected.Warnings, expected.Infos, actual.Warnings, actual.Infos)
+}
+
+// LabelsComparator compares the responses of the /api/v1/labels and /api/v1/label/{name}/values routes.
+type LabelsComparator struct{}
+
+func NewLabelsComparator() *LabelsComparator {
+	return &LabelsComparator{}
+}
+
+func (c *LabelsComparator) Compare(expectedResponse, actualResponse []byte) error {
+	var expected, actual dataResponse[[]string]
+	if err := unmarshalResponses(expectedResponse, actualResponse, &expected, &actual); err != nil {
+		return err
+	}
+
+	actualValues := make(map[string]struct{}, len(actual.Data))
+	for _, value := range actual.Data {
+		actualValues[value] = struct{}{}
+	}
+
+	for _, value := range expected.Data {
+		if _, ok := actualValues[value]; !ok {
+			return fmt.Errorf("expected value %s missing from actual response", value)
+		}
+		delete(actualValues, value)
+	}
+
+	if len(actualValues) > 0 {
+		return fmt.Errorf("unexpected values %v in actual response", sortedKeys(actualValues))
+	}
+
+	return compareAnnotations(expected.Warnings, expected.Infos, actual.Warnings, actual.Infos)
+}
+
+// SeriesComparator compares the responses of the /api/v1/series route.
+type SeriesComparator struct{}
+
+func NewSeriesComparator() *SeriesComparator {
+	return &SeriesComparator{}
+}
+
+func (c *SeriesComparator) Compare(expectedResponse, actualResponse []byte) error {
+	var expected, actual dataResponse[[]model.Metric]
+	if err := unmarshalResponses(expectedResponse, actualResponse, &expected, &actual); err != nil {
+		return err
+	}
+
+	actualSeries := make(map[model.Fingerprint]model.Metric, len(actual.Data))
+	for _, series := range actual.Data {
+		actualSeries[series.Fingerprint()] = series
+	}
+
+	for _, series := range expected.Data {
+		if _, ok := actualSeries[series.Fingerprint()]; !ok {
+			return fmt.Errorf("expected series %s missing from actual response", series)
+		}
+		delete(actualSeries, series.Fingerprint())
+	}
+
+	if len(actualSeries) > 0 {
+		unexpected := make(map[string]struct{}, len(actualSeries))
+		for _, series := range actualSeries {
+			unexpected[series.String()] = struct{}{}
+		}
+		return fmt.Errorf("unexpected series %v in actual response", sortedKeys(unexpected))
+	}
+
+	return compareAnnotations(expected.Warnings, expected.Infos, actual.Warnings, actual.Infos)
+}
+
+// dataResponse is the response of the Prometheus API routes not returning samples.
+type dataResponse[T any] struct {
+	Status   string
+	Data     T
+	Warnings []string
+	Infos    []string
+}
+
+func unmarshalResponses[T any](expectedResponse, actualResponse []byte, expected, actual *dataResponse[T]) error {
+	if err := json.Unmarshal(expectedResponse, expected); err != nil {
+		return errors.Wrap(err, "unable to unmarshal expected response")
+	}
+
+	if err := json.Unmarshal(actualResponse, actual); err != nil {
+		return errors.Wrap(err, "unable to unmarshal actual response")
+	}
+
+	if expected.Status != actual.Status {
+		return fmt.Errorf("expected status %s but got %s", expected.Status, actual.Status)
+	}
+
+	return nil
+}
+
+// compareAnnotations compares the warnings and infos of two responses, regardless of their order.
+func compareAnnotations(expectedWarnings, expectedInfos, actualWarnings, actualInfos []string) error {
+	if !equalUnordered(expectedWarnings, actualWarnings) {
+		return fmt.Errorf("expected warnings %v but got %v", expectedWarnings, actualWarnings)
+	}
+
+	if !equalUnordered(expectedInfos, actualInfos) {
+		return fmt.Errorf("expected infos %v but got %v", expectedInfos, actualInfos)
+	}
+
+	return nil
+}
+
+func equalUnordered(first, second []string) bool {
+	return slices.Equal(slices.Sorted(slices.Values(first)), slices.Sorted(slices.Values(second)))
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// diffSeries returns the series which are only in one of the two matrices.
+func diffSeries(expected, actual model.Matrix) []SeriesMismatch {
+	var (
+		mismatches        []SeriesMismatch
+		expectedSeriesSet = make(map[model.Fingerprint]struct{}, len(expected))
+		actualSeriesSet   = make(map[model.Fingerprint]struct{}, len(actual))
+	)
+
+	for _, series := range expected {
+		expectedSeriesSet[series.Metric.Fingerprint()] = struct{}{}
+	}
+	for _, series := range actual {
+		actualSeriesSet[series.Metric.Fingerprint()] = struct{}{}
+	}
+
+	for _, series := range expected {
+		if _, ok := actualSeriesSet[series.Metric.Fingerprint()]; !ok {
+			mismatches = append(mismatches, SeriesMismatch{Expected: series})
+		}
+	}
+	for _, series := range actual {
+		if _, ok := expectedSeriesSet[series.Metric.Fingerprint()]; !ok {
+			mismatches = append(mismatches, SeriesMismatch{Actual: series})
+		}
+	}
+
+	return mismatches
+}
+
+func vectorToMatrix(vector model.Vector) model.Matrix {
+	matrix := make(model.Matrix, 0, len(vector))
+	for _, sample := range vector {
+		matrix = append(matrix, sampleToStream(sample))
+	}
+	return matrix
+}
+
+func sampleToStream(sample *model.Sample) *model.SampleStream {
+	stream := &model.SampleStream{Metric: sample.Metric}
+	if sample.Histogram != nil {
+		stream.Histograms = []model.SampleHistogramPair{{Timestamp: sample.Timestamp, Histogram: sample.Histogram}}
+	} else {
+		stream.Values = []model.SamplePair{{Timestamp: sample.Timestamp, Value: sample.Value}}
+	}
+	return stream
 }
 
 func compareMatrix(expectedRaw, actualRaw json.RawMessage, tolerance float64) error {
@@ -86,8 +283,11 @@ func compareMatrix(expectedRaw, actualRaw json.RawMessage, tolerance float64) er
 	}
 
 	if len(expected) != len(actual) {
-		return fmt.Errorf("expected %d metrics but got %d", len(expected),
-			len(actual))
+		return &SeriesMismatchError{
+			err: fmt.Errorf("expected %d metrics but got %d", len(expected),
+				len(actual)),
+			Series: diffSeries(expected, actual),
+		}
 	}
 
 	metricFingerprintToIndexMap := make(map[model.Fingerprint]int, len(expected))
@@ -98,30 +298,50 @@ func compareMatrix(expectedRaw, actualRaw json.RawMessage, tolerance float64) er
 	for _, expectedMetric := range expected {
 		actualMetricIndex, ok := metricFingerprintToIndexMap[expectedMetric.Metric.Fingerprint()]
 		if !ok {
-			return fmt.Errorf("expected metric %s missing from actual response", expectedMetric.Metric)
+			return newSeriesMismatchError(fmt.Errorf("expected metric %s missing from actual response", expectedMetric.Metric), expectedMetric, nil)
 		}
 
 		actualMetric := actual[actualMetricIndex]
-		expectedMetricLen := len(expectedMetric.Values)
-		actualMetricLen := len(actualMetric.Values)
-
-		if expectedMetricLen != actualMetricLen {
-			err := fmt.Errorf("expected %d samples for metric %s but got %d", expectedMetricLen,
-				expectedMetric.Metric, actualMetricLen)
-			if expectedMetricLen > 0 && actualMetricLen > 0 {
-				level.Error(util_log.Logger).Log("msg", err.Error(), "oldest-expected-ts", expectedMetric.Values[0].Timestamp,
-					"newest-expected-ts", expectedMetric.Values[expectedMetricLen-1].Timestamp,
-					"oldest-actual-ts", actualMetric.Values[0].Timestamp, "newest-actual-ts", actualMetric.Values[actualMetricLen-1].Timestamp)
-			}
-			return err
+		if err := compareSampleStream(expectedMetric, actualMetric, tolerance); err != nil {
+			return newSeriesMismatchError(err, expectedMetric, actualMetric)
 		}
+	}
 
-		for i, expectedSamplePair := range expectedMetric.Values {
-			actualSamplePair := actualMetric.Values[i]
-			err := compareSamplePair(expectedSamplePair, actualSamplePair, tolerance)
-			if err != nil {
-				return errors.Wrapf(err, "sample pair not matching for metric %s", expectedMetric.Metric)
-			}
+	return nil
+}
+
+func compareSampleStream(expected, actual *model.SampleStream, tolerance float64) error {
+	expectedMetricLen := len(expected.Values)
+	actualMetricLen := len(actual.Values)
+
+	if expectedMetricLen != actualMetricLen {
+		err := fmt.Errorf("expected %d samples for metric %s but got %d", expectedMetricLen,
+			expected.Metric, actualMetricLen)
+		if expectedMetricLen > 0 && actualMetricLen > 0 {
+			level.Error(util_log.Logger).Log("msg", err.Error(), "oldest-expected-ts", expected.Values[0].Timestamp,
+				"newest-expected-ts", expected.Values[expectedMetricLen-1].Timestamp,
+				"oldest-actual-ts", actual.Values[0].Timestamp, "newest-actual-ts", actual.Values[actualMetricLen-1].Timestamp)
+		}
+		return err
+	}
+
+	for i, expectedSamplePair := range expected.Values {
+		actualSamplePair := actual.Values[i]
+		err := compareSamplePair(expectedSamplePair, actualSamplePair, tolerance)
+		if err != nil {
+			return errors.Wrapf(err, "sample pair not matching for metric %s", expected.Metric)
+		}
+	}
+
+	if len(expected.Histograms) != len(actual.Histograms) {
+		return fmt.Errorf("expected %d histograms for metric %s but got %d", len(expected.Histograms),
+			expected.Metric, len(actual.Histograms))
+	}
+
+	for i, expectedHistogramPair := range expected.Histograms {
+		err := compareSampleHistogramPair(expectedHistogramPair, actual.Histograms[i], tolerance)
+		if err != nil {
+			return errors.Wrapf(err, "histogram pair not matching for metric %s", expected.Metric)
 		}
 	}
 
@@ -142,8 +362,11 @@ func compareVector(expectedRaw, actualRaw json.RawMessage, tolerance float64) er
 	}
 
 	if len(expected) != len(actual) {
-		return fmt.Errorf("expected %d metrics but got %d", len(expected),
-			len(actual))
+		return &SeriesMismatchError{
+			err: fmt.Errorf("expected %d metrics but got %d", len(expected),
+				len(actual)),
+			Series: diffSeries(vectorToMatrix(expected), vectorToMatrix(actual)),
+		}
 	}
 
 	metricFingerprintToIndexMap := make(map[model.Fingerprint]int, len(expected))
@@ -154,25 +377,45 @@ func compareVector(expectedRaw, actualRaw json.RawMessage, tolerance float64) er
 	for _, expectedMetric := range expected {
 		actualMetricIndex, ok := metricFingerprintToIndexMap[expectedMetric.Metric.Fingerprint()]
 		if !ok {
-			return fmt.Errorf("expected metric %s missing from actual response", expectedMetric.Metric)
+			return newSeriesMismatchError(fmt.Errorf("expected metric %s missing from actual response", expectedMetric.Metric), sampleToStream(expectedMetric), nil)
 		}
 
 		actualMetric := actual[actualMetricIndex]
-		err := compareSamplePair(model.SamplePair{
-			Timestamp: expectedMetric.Timestamp,
-			Value:     expectedMetric.Value,
-		}, model.SamplePair{
-			Timestamp: actualMetric.Timestamp,
-			Value:     actualMetric.Value,
-		}, tolerance)
+		err := compareSample(expectedMetric, actualMetric, tolerance)
 		if err != nil {
-			return errors.Wrapf(err, "sample pair not matching for metric %s", expectedMetric.Metric)
+			return newSeriesMismatchError(errors.Wrapf(err, "sample pair not matching for metric %s", expectedMetric.Metric),
+				sampleToStream(expectedMetric), sampleToStream(actualMetric))
 		}
 	}
 
 	return nil
 }
 
+func compareSample(expected, actual *model.Sample, tolerance float64) error {
+	switch {
+	case expected.Histogram != nil && actual.Histogram == nil:
+		return fmt.Errorf("expected histogram %v for timestamp %v but got value %s", expected.Histogram, expected.Timestamp, actual.Value)
+	case expected.Histogram == nil && actual.Histogram != nil:
+		return fmt.Errorf("expected value %s for timestamp %v but got histogram %v", expected.Value, expected.Timestamp, actual.Histogram)
+	case expected.Histogram != nil:
+		return compareSampleHistogramPair(model.SampleHistogramPair{
+			Timestamp: expected.Timestamp,
+			Histogram: expected.Histogram,
+		}, model.SampleHistogramPair{
+			Timestamp: actual.Timestamp,
+			Histogram: actual.Histogram,
+		}, tolerance)
+	}
+
+	return compareSamplePair(model.SamplePair{
+		Timestamp: expected.Timestamp,
+		Value:     expected.Value,
+	}, model.SamplePair{
+		Timestamp: actual.Timestamp,
+		Value:     actual.Value,
+	}, tolerance)
+}
+
 func compareScalar(expectedRaw, actualRaw json.RawMessage, tolerance float64) error {
 	var expected, actual model.Scalar
 	err := json.Unmarshal(expectedRaw, &expected)
@@ -205,6 +448,41 @@ func compareSamplePair(expected, actual model.SamplePair, tolerance float64) err
 	return nil
 }
 
+func compareSampleHistogramPair(expected, actual model.SampleHistogramPair, tolerance float64) error {
+	if expected.Timestamp != actual.Timestamp {
+		return fmt.Errorf("expected timestamp %v but got %v", expected.Timestamp, actual.Timestamp)
+	}
+	if !compareSampleHistogram(expected.Histogram, actual.Histogram, tolerance) {
+		return fmt.Errorf("expected histogram %v for timestamp %v but got %v", expected.Histogram, expected.Timestamp, actual.Histogram)
+	}
+
+	return nil
+}
+
+func compareSampleHistogram(expected, actual *model.SampleHistogram, tolerance float64) bool {
+	if expected == nil || actual == nil {
+		return expected == actual
+	}
+
+	if !compareSampleValue(model.SampleValue(expected.Count), model.SampleValue(actual.Count), tolerance) ||
+		!compareSampleValue(model.SampleValue(expected.Sum), model.SampleValue(actual.Sum), tolerance) ||
+		len(expected.Buckets) != len(actual.Buckets) {
+		return false
+	}
+
+	for i, expectedBucket := range expected.Buckets {
+		actualBucket := actual.Buckets[i]
+		if expectedBucket.Boundaries != actualBucket.Boundaries ||
+			!compareSampleValue(model.SampleValue(expectedBucket.Lower), model.SampleValue(actualBucket.Lower), tolerance) ||
+			!compareSampleValue(model.SampleValue(expectedBucket.Upper), model.SampleValue(actualBucket.Upper), tolerance) ||
+			!compareSampleValue(model.SampleValue(expectedBucket.Count), model.SampleValue(actualBucket.Count), tolerance) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func compareSampleValue(first, second model.SampleValue, tolerance float64) bool {
 	f := float64(first)
 	s := float64(second)
@@ -215,5 +493,5 @@ func compareSampleValue(first, second model.SampleValue, tolerance float64) bool
 		return math.Float64bits(f) == math.Float64bits(s)
 	}
 
-	return math.Abs(f-s) <= tolerance
+	return f == s || math.Abs(f-s) <= tolerance
 }
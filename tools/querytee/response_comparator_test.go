@@ -5,6 +5,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 )
 
@@ -89,6 +90,35 @@ func TestCompareMatrix(t *testing.T) {
 							{"metric":{"foo":"bar"},"values":[[1,"1"],[2,"2"]]}
 						]`),
 		},
+		{
+			name: "difference in number of histograms",
+			expected: json.RawMessage(`[
+							{"metric":{"foo":"bar"},"histograms":[[1,{"count":"2","sum":"3","buckets":[[0,"0","1","2"]]}]]}
+						]`),
+			actual: json.RawMessage(`[
+							{"metric":{"foo":"bar"},"histograms":[]}
+						]`),
+			err: errors.New("expected 1 histograms for metric {foo=\"bar\"} but got 0"),
+		},
+		{
+			name: "difference in histogram",
+			expected: json.RawMessage(`[
+							{"metric":{"foo":"bar"},"histograms":[[1,{"count":"2","sum":"3","buckets":[[0,"0","1","2"]]}]]}
+						]`),
+			actual: json.RawMessage(`[
+							{"metric":{"foo":"bar"},"histograms":[[1,{"count":"2","sum":"3","buckets":[[0,"0","2","2"]]}]]}
+						]`),
+			err: errors.New("histogram pair not matching for metric {foo=\"bar\"}: expected histogram Count: 2.000000, Sum: 3.000000, Buckets: [(0,1]:2] for timestamp 1 but got Count: 2.000000, Sum: 3.000000, Buckets: [(0,2]:2]"),
+		},
+		{
+			name: "correct histograms",
+			expected: json.RawMessage(`[
+							{"metric":{"foo":"bar"},"histograms":[[1,{"count":"2","sum":"3","buckets":[[0,"0","1","2"]]}]]}
+						]`),
+			actual: json.RawMessage(`[
+							{"metric":{"foo":"bar"},"histograms":[[1,{"count":"2","sum":"3","buckets":[[0,"0","1","2"]]}]]}
+						]`),
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			err := compareMatrix(tc.expected, tc.actual, 0)
@@ -172,6 +202,25 @@ func TestCompareVector(t *testing.T) {
 							{"metric":{"foo":"bar"},"value":[1,"1"]}
 						]`),
 		},
+		{
+			name: "float sample in place of an histogram",
+			expected: json.RawMessage(`[
+							{"metric":{"foo":"bar"},"histogram":[1,{"count":"2","sum":"3","buckets":[[0,"0","1","2"]]}]}
+						]`),
+			actual: json.RawMessage(`[
+							{"metric":{"foo":"bar"},"value":[1,"2"]}
+						]`),
+			err: errors.New("sample pair not matching for metric {foo=\"bar\"}: expected histogram Count: 2.000000, Sum: 3.000000, Buckets: [(0,1]:2] for timestamp 1 but got value 2"),
+		},
+		{
+			name: "correct histograms",
+			expected: json.RawMessage(`[
+							{"metric":{"foo":"bar"},"histogram":[1,{"count":"2","sum":"3","buckets":[[0,"0","1","2"]]}]}
+						]`),
+			actual: json.RawMessage(`[
+							{"metric":{"foo":"bar"},"histogram":[1,{"count":"2","sum":"3","buckets":[[0,"0","1","2"]]}]}
+						]`),
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			err := compareVector(tc.expected, tc.actual, 0)
@@ -325,6 +374,45 @@ func TestCompareSamplesResponse(t *testing.T) {
 						}`),
 			err: errors.New(`sample pair not matching for metric {foo="bar"}: expected value 773054.5916666666 for timestamp 1 but got 773054.789`),
 		},
+		{
+			name: "should pass if the warnings are the same in a different order",
+			expected: json.RawMessage(`{
+							"status": "success",
+							"data": {"resultType":"scalar","result":[1,"1"]},
+							"warnings": ["first", "second"]
+						}`),
+			actual: json.RawMessage(`{
+							"status": "success",
+							"data": {"resultType":"scalar","result":[1,"1"]},
+							"warnings": ["second", "first"]
+						}`),
+		},
+		{
+			name: "should fail if the warnings are different",
+			expected: json.RawMessage(`{
+							"status": "success",
+							"data": {"resultType":"scalar","result":[1,"1"]},
+							"warnings": ["first"]
+						}`),
+			actual: json.RawMessage(`{
+							"status": "success",
+							"data": {"resultType":"scalar","result":[1,"1"]}
+						}`),
+			err: errors.New(`expected warnings [first] but got []`),
+		},
+		{
+			name: "should fail if the infos are different",
+			expected: json.RawMessage(`{
+							"status": "success",
+							"data": {"resultType":"scalar","result":[1,"1"]}
+						}`),
+			actual: json.RawMessage(`{
+							"status": "success",
+							"data": {"resultType":"scalar","result":[1,"1"]},
+							"infos": ["first"]
+						}`),
+			err: errors.New(`expected infos [] but got [first]`),
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			samplesComparator := NewSamplesComparator(tc.tolerance)
@@ -338,3 +426,139 @@ func TestCompareSamplesResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestCompareSamplesResponse_SeriesMismatch(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		expected json.RawMessage
+		actual   json.RawMessage
+		series   []SeriesMismatch
+	}{
+		{
+			name: "different sample value",
+			expected: json.RawMessage(`{
+							"status": "success",
+							"data": {"resultType":"matrix","result":[{"metric":{"foo":"bar"},"values":[[1,"1"],[2,"2"]]}]}
+						}`),
+			actual: json.RawMessage(`{
+							"status": "success",
+							"data": {"resultType":"matrix","result":[{"metric":{"foo":"bar"},"values":[[1,"1"],[2,"3"]]}]}
+						}`),
+			series: []SeriesMismatch{{
+				Expected: &model.SampleStream{Metric: model.Metric{"foo": "bar"}, Values: []model.SamplePair{{Timestamp: 1000, Value: 1}, {Timestamp: 2000, Value: 2}}},
+				Actual:   &model.SampleStream{Metric: model.Metric{"foo": "bar"}, Values: []model.SamplePair{{Timestamp: 1000, Value: 1}, {Timestamp: 2000, Value: 3}}},
+			}},
+		},
+		{
+			name: "missing and extra series",
+			expected: json.RawMessage(`{
+							"status": "success",
+							"data": {"resultType":"vector","result":[{"metric":{"foo":"bar"},"value":[1,"1"]}]}
+						}`),
+			actual: json.RawMessage(`{
+							"status": "success",
+							"data": {"resultType":"vector","result":[{"metric":{"foo":"baz"},"value":[1,"1"]},{"metric":{"foo":"qux"},"value":[1,"2"]}]}
+						}`),
+			series: []SeriesMismatch{
+				{Expected: &model.SampleStream{Metric: model.Metric{"foo": "bar"}, Values: []model.SamplePair{{Timestamp: 1000, Value: 1}}}},
+				{Actual: &model.SampleStream{Metric: model.Metric{"foo": "baz"}, Values: []model.SamplePair{{Timestamp: 1000, Value: 1}}}},
+				{Actual: &model.SampleStream{Metric: model.Metric{"foo": "qux"}, Values: []model.SamplePair{{Timestamp: 1000, Value: 2}}}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := NewSamplesComparator(0).Compare(tc.expected, tc.actual)
+			require.Error(t, err)
+
+			var mismatchErr *SeriesMismatchError
+			require.True(t, errors.As(err, &mismatchErr))
+			require.Equal(t, tc.series, mismatchErr.Series)
+		})
+	}
+}
+
+func TestLabelsComparator(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		expected json.RawMessage
+		actual   json.RawMessage
+		err      error
+	}{
+		{
+			name:     "same values in a different order",
+			expected: json.RawMessage(`{"status":"success","data":["a","b"]}`),
+			actual:   json.RawMessage(`{"status":"success","data":["b","a"]}`),
+		},
+		{
+			name:     "difference in response status",
+			expected: json.RawMessage(`{"status":"success","data":["a"]}`),
+			actual:   json.RawMessage(`{"status":"error"}`),
+			err:      errors.New("expected status success but got error"),
+		},
+		{
+			name:     "value missing from actual response",
+			expected: json.RawMessage(`{"status":"success","data":["a","b"]}`),
+			actual:   json.RawMessage(`{"status":"success","data":["a"]}`),
+			err:      errors.New("expected value b missing from actual response"),
+		},
+		{
+			name:     "unexpected values in actual response",
+			expected: json.RawMessage(`{"status":"success","data":["a"]}`),
+			actual:   json.RawMessage(`{"status":"success","data":["c","a","b"]}`),
+			err:      errors.New("unexpected values [b c] in actual response"),
+		},
+		{
+			name:     "difference in warnings",
+			expected: json.RawMessage(`{"status":"success","data":["a"],"warnings":["warning"]}`),
+			actual:   json.RawMessage(`{"status":"success","data":["a"]}`),
+			err:      errors.New("expected warnings [warning] but got []"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := NewLabelsComparator().Compare(tc.expected, tc.actual)
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, tc.err.Error(), err.Error())
+		})
+	}
+}
+
+func TestSeriesComparator(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		expected json.RawMessage
+		actual   json.RawMessage
+		err      error
+	}{
+		{
+			name:     "same series in a different order",
+			expected: json.RawMessage(`{"status":"success","data":[{"__name__":"up","job":"a"},{"__name__":"up","job":"b"}]}`),
+			actual:   json.RawMessage(`{"status":"success","data":[{"__name__":"up","job":"b"},{"__name__":"up","job":"a"}]}`),
+		},
+		{
+			name:     "series missing from actual response",
+			expected: json.RawMessage(`{"status":"success","data":[{"__name__":"up","job":"a"},{"__name__":"up","job":"b"}]}`),
+			actual:   json.RawMessage(`{"status":"success","data":[{"__name__":"up","job":"a"}]}`),
+			err:      errors.New(`expected series up{job="b"} missing from actual response`),
+		},
+		{
+			name:     "unexpected series in actual response",
+			expected: json.RawMessage(`{"status":"success","data":[{"__name__":"up","job":"a"}]}`),
+			actual:   json.RawMessage(`{"status":"success","data":[{"__name__":"up","job":"a"},{"__name__":"up","job":"b"}]}`),
+			err:      errors.New(`unexpected series [up{job="b"}] in actual response`),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := NewSeriesComparator().Compare(tc.expected, tc.actual)
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, tc.err.Error(), err.Error())
+		})
+	}
+}
@@ -0,0 +1,447 @@
+package tenantmigrate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/oklog/ulid/v2"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+	"github.com/cortexproject/cortex/pkg/util/concurrency"
+	util_log "github.com/cortexproject/cortex/pkg/util/log"
+)
+
+type blockStatus int
+
+const (
+	blockSkipped blockStatus = iota
+	blockCopied
+	blockRewritten
+	blockEmpty
+	blockMigrated
+	blockFailed
+)
+
+// emptyBlocksPathname is the destination tenant directory holding a marker for each source
+// block with no series matching the matchers, so that a resumed migration skips them.
+const emptyBlocksPathname = "tenantmigrate-empty-blocks"
+
+// emptyBlockMarker is the content of the marker of a source block with no matching series.
+type emptyBlockMarker struct {
+	ID       ulid.ULID `json:"id"`
+	Matchers string    `json:"matchers"`
+}
+
+// migrateBlocks copies the blocks of the source tenant which haven't been migrated yet,
+// and then updates the bucket index of the destination tenant.
+func (m *Migrator) migrateBlocks(ctx context.Context, results *Results) error {
+	srcBkt := bucket.NewUserBucketClient(m.cfg.SourceTenant, m.srcBlocks, nil)
+	dstBkt := bucketindex.BucketWithGlobalMarkers(bucket.NewUserBucketClient(m.cfg.DestinationTenant, m.dstBlocks, nil))
+
+	migrated, err := m.migratedBlocks(ctx, dstBkt)
+	if err != nil {
+		return err
+	}
+
+	var jobs []any
+	err = srcBkt.Iter(ctx, "", func(name string) error {
+		if id, ok := block.IsBlockDir(name); ok {
+			jobs = append(jobs, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "list blocks")
+	}
+
+	mtx := sync.Mutex{}
+	err = concurrency.ForEach(ctx, jobs, m.cfg.Concurrency, func(ctx context.Context, job any) error {
+		id := job.(ulid.ULID)
+		logger := log.With(m.logger, "block", id.String())
+
+		status, size, err := m.migrateBlock(ctx, logger, srcBkt, dstBkt, id, migrated)
+		if err != nil {
+			level.Error(logger).Log("msg", "failed to migrate block", "err", err)
+			status = blockFailed
+		}
+
+		mtx.Lock()
+		defer mtx.Unlock()
+
+		results.EstimatedBytes += size
+		switch status {
+		case blockCopied:
+			results.CopiedBlocks = append(results.CopiedBlocks, id.String())
+		case blockRewritten:
+			results.RewrittenBlocks = append(results.RewrittenBlocks, id.String())
+		case blockEmpty:
+			results.EmptyBlocks = append(results.EmptyBlocks, id.String())
+		case blockMigrated:
+			results.MigratedBlocks = append(results.MigratedBlocks, id.String())
+		case blockFailed:
+			results.FailedBlocks = append(results.FailedBlocks, id.String())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if m.cfg.DryRun || len(results.CopiedBlocks)+len(results.RewrittenBlocks) == 0 {
+		return nil
+	}
+	return m.updateBucketIndex(ctx)
+}
+
+// migrateBlock copies a single block and returns its size.
+func (m *Migrator) migrateBlock(ctx context.Context, logger log.Logger, srcBkt, dstBkt objstore.Bucket, id ulid.ULID, migrated map[ulid.ULID]struct{}) (blockStatus, int64, error) {
+	if _, ok := migrated[id]; ok {
+		level.Debug(logger).Log("msg", "skipped block already migrated")
+		return blockMigrated, 0, nil
+	}
+
+	// Blocks marked for deletion are not copied, because they have been replaced by a compacted block.
+	if marked, err := srcBkt.Exists(ctx, path.Join(id.String(), metadata.DeletionMarkFilename)); err != nil {
+		return blockFailed, 0, errors.Wrap(err, "check deletion mark")
+	} else if marked {
+		level.Info(logger).Log("msg", "skipped block marked for deletion")
+		return blockSkipped, 0, nil
+	}
+
+	meta, err := block.DownloadMeta(ctx, logger, srcBkt, id)
+	if err != nil {
+		return blockFailed, 0, errors.Wrap(err, "download meta")
+	}
+
+	files, size, err := m.blockFiles(ctx, srcBkt, id)
+	if err != nil {
+		return blockFailed, 0, err
+	}
+
+	if len(m.matchers) == 0 {
+		if m.cfg.DryRun {
+			level.Info(logger).Log("msg", "block would be copied (dry-run)", "bytes", size)
+			return blockCopied, size, nil
+		}
+		if err := m.copyBlock(ctx, srcBkt, dstBkt, meta, files); err != nil {
+			return blockFailed, 0, err
+		}
+		level.Info(logger).Log("msg", "copied block", "bytes", size)
+		return blockCopied, size, nil
+	}
+
+	if m.cfg.DryRun {
+		level.Info(logger).Log("msg", "block would be rewritten (dry-run)", "max_bytes", size)
+		return blockRewritten, size, nil
+	}
+
+	newID, err := m.rewriteBlock(ctx, logger, srcBkt, dstBkt, meta)
+	if err != nil {
+		return blockFailed, 0, err
+	}
+	if newID == (ulid.ULID{}) {
+		if err := m.uploadEmptyBlockMarker(ctx, dstBkt, id); err != nil {
+			return blockFailed, 0, err
+		}
+		level.Info(logger).Log("msg", "skipped block with no series matching the matchers")
+		return blockEmpty, 0, nil
+	}
+	level.Info(logger).Log("msg", "rewritten block", "new_block", newID.String())
+	return blockRewritten, size, nil
+}
+
+// migratedBlocks returns the IDs of the source blocks already copied to the destination
+// tenant by a previous run, or found to have no series matching the matchers. The
+// meta.json is uploaded last, so a block without it is copied again.
+func (m *Migrator) migratedBlocks(ctx context.Context, dstBkt objstore.InstrumentedBucket) (map[ulid.ULID]struct{}, error) {
+	migrated := map[ulid.ULID]struct{}{}
+
+	err := dstBkt.Iter(ctx, emptyBlocksPathname+objstore.DirDelim, func(name string) error {
+		if id, err := ulid.Parse(path.Base(name)); err == nil {
+			migrated[id] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list empty blocks")
+	}
+
+	err = dstBkt.Iter(ctx, "", func(name string) error {
+		id, ok := block.IsBlockDir(name)
+		if !ok {
+			return nil
+		}
+
+		meta, err := block.DownloadMeta(ctx, m.logger, dstBkt, id)
+		if dstBkt.IsObjNotFoundErr(errors.Cause(err)) {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "download meta of block %s", id.String())
+		}
+
+		migrated[id] = struct{}{}
+		for _, rewrite := range meta.Thanos.Rewrites {
+			for _, source := range rewrite.Sources {
+				migrated[source] = struct{}{}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list migrated blocks")
+	}
+	return migrated, nil
+}
+
+// blockFiles returns the files of a block, except the meta.json and markers, along
+// with their size. When series matchers are set, only the TSDB files are returned,
+// because the other files (eg. Parquet) are not copied to the rewritten block.
+func (m *Migrator) blockFiles(ctx context.Context, bkt objstore.Bucket, id ulid.ULID) ([]string, int64, error) {
+	var (
+		files []string
+		size  int64
+	)
+
+	err := bkt.Iter(ctx, id.String(), func(name string) error {
+		relPath := strings.TrimPrefix(name, id.String()+objstore.DirDelim)
+		switch {
+		case relPath == block.MetaFilename || relPath == metadata.DeletionMarkFilename:
+			return nil
+		case len(m.matchers) > 0 && relPath != block.IndexFilename && !strings.HasPrefix(relPath, block.ChunksDirname+objstore.DirDelim):
+			return nil
+		}
+
+		attrs, err := bkt.Attributes(ctx, name)
+		if err != nil {
+			return errors.Wrapf(err, "get attributes of %s", name)
+		}
+		files = append(files, name)
+		size += attrs.Size
+		return nil
+	}, objstore.WithRecursiveIter())
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "list block files")
+	}
+	return files, size, nil
+}
+
+// copyBlock copies the files of a block as is, uploading the meta.json last. The block
+// keeps its ID, because its content doesn't change.
+func (m *Migrator) copyBlock(ctx context.Context, srcBkt, dstBkt objstore.Bucket, meta metadata.Meta, files []string) error {
+	for _, name := range files {
+		if err := copyObject(ctx, srcBkt, dstBkt, name); err != nil {
+			return err
+		}
+	}
+
+	meta.Thanos.Labels = map[string]string{cortex_tsdb.TenantIDExternalLabel: m.cfg.DestinationTenant}
+	return uploadMeta(ctx, dstBkt, meta)
+}
+
+// rewriteBlock writes a new block with only the series matching the matchers. The new
+// block gets a new ID, because different blocks with the same ID would share the same
+// cache entries, and it references the source block in its rewrites. Returns an empty
+// ID if no series matches.
+func (m *Migrator) rewriteBlock(ctx context.Context, logger log.Logger, srcBkt, dstBkt objstore.Bucket, meta metadata.Meta) (_ ulid.ULID, returnErr error) {
+	workDir := filepath.Join(m.cfg.WorkingDir, meta.ULID.String())
+	if err := os.RemoveAll(workDir); err != nil {
+		return ulid.ULID{}, errors.Wrap(err, "clean working dir")
+	}
+	defer func() {
+		if err := os.RemoveAll(workDir); err != nil {
+			level.Warn(logger).Log("msg", "failed to remove working dir", "dir", workDir, "err", err)
+		}
+	}()
+
+	srcDir := filepath.Join(workDir, "source", meta.ULID.String())
+	if err := block.Download(ctx, logger, srcBkt, meta.ULID, srcDir); err != nil {
+		return ulid.ULID{}, errors.Wrap(err, "download block")
+	}
+
+	srcBlock, err := tsdb.OpenBlock(util_log.GoKitLogToSlog(logger), srcDir, chunkenc.NewPool(), tsdb.DefaultPostingsDecoderFactory)
+	if err != nil {
+		return ulid.ULID{}, errors.Wrap(err, "open block")
+	}
+	defer srcBlock.Close()
+
+	// The head only accepts the samples within half of its chunk range from the most recent
+	// one, while series are appended one after the other from the block min time.
+	dstDir := filepath.Join(workDir, "destination")
+	writer, err := tsdb.NewBlockWriter(util_log.GoKitLogToSlog(logger), dstDir, 2*(meta.MaxTime-meta.MinTime))
+	if err != nil {
+		return ulid.ULID{}, errors.Wrap(err, "create block writer")
+	}
+	defer func() {
+		if err := writer.Close(); err != nil && returnErr == nil {
+			returnErr = errors.Wrap(err, "close block writer")
+		}
+	}()
+
+	numSeries, err := copySeries(ctx, srcBlock, writer, meta, m.matchers)
+	if err != nil {
+		return ulid.ULID{}, err
+	}
+	if numSeries == 0 {
+		return ulid.ULID{}, nil
+	}
+
+	newID, err := writer.Flush(ctx)
+	if err != nil {
+		return ulid.ULID{}, errors.Wrap(err, "flush block")
+	}
+
+	newDir := filepath.Join(dstDir, newID.String())
+	newMeta, err := metadata.ReadFromDir(newDir)
+	if err != nil {
+		return ulid.ULID{}, errors.Wrap(err, "read new block meta")
+	}
+
+	// Keep the time range and compaction state of the source block, so that the compactor
+	// handles the new block the same way.
+	newMeta.MinTime = meta.MinTime
+	newMeta.MaxTime = meta.MaxTime
+	newMeta.Compaction.Level = meta.Compaction.Level
+	newMeta.Compaction.Sources = meta.Compaction.Sources
+	newMeta.Compaction.Parents = meta.Compaction.Parents
+	newMeta.Thanos = meta.Thanos
+	newMeta.Thanos.Labels = map[string]string{cortex_tsdb.TenantIDExternalLabel: m.cfg.DestinationTenant}
+	newMeta.Thanos.Files = nil
+	newMeta.Thanos.Rewrites = append(newMeta.Thanos.Rewrites, metadata.Rewrite{Sources: []ulid.ULID{meta.ULID}})
+	if err := newMeta.WriteToDir(logger, newDir); err != nil {
+		return ulid.ULID{}, errors.Wrap(err, "write new block meta")
+	}
+
+	if err := block.Upload(ctx, logger, dstBkt, newDir, metadata.NoneFunc); err != nil {
+		return ulid.ULID{}, errors.Wrap(err, "upload block")
+	}
+	return newID, nil
+}
+
+// copySeries appends the samples of the series matching the matchers to the writer, and
+// returns the number of series copied.
+func copySeries(ctx context.Context, srcBlock *tsdb.Block, writer *tsdb.BlockWriter, meta metadata.Meta, matchers []*labels.Matcher) (int, error) {
+	q, err := tsdb.NewBlockQuerier(srcBlock, meta.MinTime, meta.MaxTime)
+	if err != nil {
+		return 0, errors.Wrap(err, "create block querier")
+	}
+	defer q.Close()
+
+	numSeries := 0
+	set := q.Select(ctx, false, nil, matchers...)
+	var it chunkenc.Iterator
+	for set.Next() {
+		series := set.At()
+		app := writer.Appender(ctx)
+
+		var (
+			ref storage.SeriesRef
+			err error
+		)
+		it = series.Iterator(it)
+		for vt := it.Next(); vt != chunkenc.ValNone; vt = it.Next() {
+			switch vt {
+			case chunkenc.ValFloat:
+				t, v := it.At()
+				ref, err = app.Append(ref, series.Labels(), t, v)
+			case chunkenc.ValHistogram:
+				t, h := it.AtHistogram(nil)
+				ref, err = app.AppendHistogram(ref, series.Labels(), t, h, nil)
+			case chunkenc.ValFloatHistogram:
+				t, fh := it.AtFloatHistogram(nil)
+				ref, err = app.AppendHistogram(ref, series.Labels(), t, nil, fh)
+			}
+			if err != nil {
+				_ = app.Rollback()
+				return 0, errors.Wrapf(err, "append sample of series %s", series.Labels().String())
+			}
+		}
+		if err := it.Err(); err != nil {
+			_ = app.Rollback()
+			return 0, errors.Wrapf(err, "iterate series %s", series.Labels().String())
+		}
+
+		if err := app.Commit(); err != nil {
+			return 0, errors.Wrap(err, "commit series")
+		}
+		numSeries++
+	}
+	if err := set.Err(); err != nil {
+		return 0, errors.Wrap(err, "select series")
+	}
+	return numSeries, nil
+}
+
+// updateBucketIndex updates the destination tenant bucket index, so that the copied
+// blocks are queryable without waiting for the compactor.
+func (m *Migrator) updateBucketIndex(ctx context.Context) error {
+	old, err := bucketindex.ReadIndex(ctx, m.dstBlocks, m.cfg.DestinationTenant, nil, m.logger)
+	if err != nil && !errors.Is(err, bucketindex.ErrIndexNotFound) {
+		return errors.Wrap(err, "read bucket index")
+	}
+
+	idx, _, _, err := bucketindex.NewUpdater(m.dstBlocks, m.cfg.DestinationTenant, nil, m.logger).UpdateIndex(ctx, old)
+	if err != nil {
+		return errors.Wrap(err, "update bucket index")
+	}
+	if err := bucketindex.WriteIndex(ctx, m.dstBlocks, m.cfg.DestinationTenant, nil, idx); err != nil {
+		return errors.Wrap(err, "write bucket index")
+	}
+	level.Info(m.logger).Log("msg", "updated bucket index", "blocks", len(idx.Blocks))
+	return nil
+}
+
+func copyObject(ctx context.Context, srcBkt, dstBkt objstore.Bucket, name string) error {
+	r, err := srcBkt.Get(ctx, name)
+	if err != nil {
+		return errors.Wrapf(err, "get %s", name)
+	}
+	defer r.Close()
+
+	if err := dstBkt.Upload(ctx, name, r); err != nil {
+		return errors.Wrapf(err, "upload %s", name)
+	}
+	return nil
+}
+
+// uploadEmptyBlockMarker persists that the source block has no series matching the matchers,
+// like the meta.json of the copied blocks does.
+func (m *Migrator) uploadEmptyBlockMarker(ctx context.Context, dstBkt objstore.Bucket, id ulid.ULID) error {
+	body, err := json.Marshal(emptyBlockMarker{ID: id, Matchers: m.cfg.Matchers})
+	if err != nil {
+		return errors.Wrap(err, "encode empty block marker")
+	}
+	if err := dstBkt.Upload(ctx, path.Join(emptyBlocksPathname, id.String()), bytes.NewReader(body)); err != nil {
+		return errors.Wrap(err, "upload empty block marker")
+	}
+	return nil
+}
+
+func uploadMeta(ctx context.Context, bkt objstore.Bucket, meta metadata.Meta) error {
+	var body bytes.Buffer
+	if err := meta.Write(&body); err != nil {
+		return errors.Wrap(err, "encode meta")
+	}
+	if err := bkt.Upload(ctx, path.Join(meta.ULID.String(), block.MetaFilename), &body); err != nil {
+		return errors.Wrap(err, "upload meta")
+	}
+	return nil
+}
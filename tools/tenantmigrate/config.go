@@ -0,0 +1,141 @@
+package tenantmigrate
+
+import (
+	"flag"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	"github.com/cortexproject/cortex/pkg/storage/bucket/encryption"
+	"github.com/cortexproject/cortex/pkg/util/users"
+)
+
+var (
+	errMissingSourceTenant      = errors.New("the source tenant is required")
+	errMissingDestinationTenant = errors.New("the destination tenant is required")
+	errSameTenantAndStorage     = errors.New("the source and destination tenants are the same and no destination storage has been configured")
+	errNothingToMigrate         = errors.New("no source storage has been configured")
+	errEncryptionWithoutStorage = errors.New("the destination blocks storage encryption requires the destination blocks storage to be configured")
+)
+
+// StorageConfig holds the buckets a tenant data is stored in. A storage whose backend
+// is empty is not configured.
+type StorageConfig struct {
+	BlocksStorage       bucket.Config     `yaml:"blocks_storage"`
+	BlocksEncryption    encryption.Config `yaml:"blocks_storage_encryption"`
+	RulerStorage        bucket.Config     `yaml:"ruler_storage"`
+	AlertmanagerStorage bucket.Config     `yaml:"alertmanager_storage"`
+}
+
+// RegisterFlagsWithPrefix registers the storage flags, with no default backend.
+func (cfg *StorageConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	cfg.BlocksStorage.RegisterFlagsWithPrefixAndBackend(prefix+"blocks-storage.", f, "")
+	cfg.BlocksEncryption.RegisterFlagsWithPrefix(prefix+"blocks-storage.", f)
+	cfg.RulerStorage.RegisterFlagsWithPrefixAndBackend(prefix+"ruler-storage.", f, "")
+	cfg.AlertmanagerStorage.RegisterFlagsWithPrefixAndBackend(prefix+"alertmanager-storage.", f, "")
+}
+
+// Config of the tenant migration.
+type Config struct {
+	SourceTenant      string `yaml:"source_tenant"`
+	DestinationTenant string `yaml:"destination_tenant"`
+
+	// Source storage. Each destination storage which is not configured defaults to the
+	// matching source storage.
+	Source      StorageConfig `yaml:"source"`
+	Destination StorageConfig `yaml:"destination"`
+
+	Matchers    string `yaml:"matchers"`
+	WorkingDir  string `yaml:"working_dir"`
+	Concurrency int    `yaml:"concurrency"`
+	DryRun      bool   `yaml:"dry_run"`
+}
+
+// RegisterFlags registers the tenant migration flags.
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	cfg.Source.RegisterFlagsWithPrefix("source.", f)
+	cfg.Destination.RegisterFlagsWithPrefix("destination.", f)
+
+	f.StringVar(&cfg.SourceTenant, "source-tenant", "", "Tenant to copy the data from.")
+	f.StringVar(&cfg.DestinationTenant, "destination-tenant", "", "Tenant to copy the data to.")
+	f.StringVar(&cfg.Matchers, "matchers", "", "Optional series selector (eg. '{namespace=\"prod\"}'). If set, the blocks are rewritten to only keep the matching series, while rule groups and Alertmanager config are copied unchanged.")
+	f.StringVar(&cfg.WorkingDir, "working-dir", "./tenantmigrate", "Directory used to download and rewrite the blocks when series matchers are set.")
+	f.IntVar(&cfg.Concurrency, "concurrency", 4, "Number of blocks copied concurrently.")
+	f.BoolVar(&cfg.DryRun, "dry-run", false, "Don't make changes; only report what would be copied along with an estimate of its size.")
+}
+
+// Validate the config.
+func (cfg *Config) Validate() error {
+	if cfg.SourceTenant == "" {
+		return errMissingSourceTenant
+	}
+	if cfg.DestinationTenant == "" {
+		return errMissingDestinationTenant
+	}
+	for _, tenant := range []string{cfg.SourceTenant, cfg.DestinationTenant} {
+		if err := users.ValidTenantID(tenant); err != nil {
+			return errors.Wrapf(err, "invalid tenant %s", tenant)
+		}
+	}
+
+	if !isConfigured(cfg.Source.BlocksStorage) && !isConfigured(cfg.Source.RulerStorage) && !isConfigured(cfg.Source.AlertmanagerStorage) {
+		return errNothingToMigrate
+	}
+	if cfg.SourceTenant == cfg.DestinationTenant && !isConfigured(cfg.Destination.BlocksStorage) && !isConfigured(cfg.Destination.RulerStorage) && !isConfigured(cfg.Destination.AlertmanagerStorage) {
+		return errSameTenantAndStorage
+	}
+
+	for name, storage := range map[string]bucket.Config{
+		"source blocks storage":            cfg.Source.BlocksStorage,
+		"source ruler storage":             cfg.Source.RulerStorage,
+		"source alertmanager storage":      cfg.Source.AlertmanagerStorage,
+		"destination blocks storage":       cfg.Destination.BlocksStorage,
+		"destination ruler storage":        cfg.Destination.RulerStorage,
+		"destination alertmanager storage": cfg.Destination.AlertmanagerStorage,
+	} {
+		if !isConfigured(storage) {
+			continue
+		}
+		if err := storage.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid %s config", name)
+		}
+	}
+
+	// The blocks are decrypted with the source keys and encrypted again for the destination
+	// tenant, whose objects can't be decrypted with the keys of the source tenant.
+	if err := cfg.Source.BlocksEncryption.Validate(); err != nil {
+		return errors.Wrap(err, "invalid source blocks storage encryption config")
+	}
+	if err := cfg.Destination.BlocksEncryption.Validate(); err != nil {
+		return errors.Wrap(err, "invalid destination blocks storage encryption config")
+	}
+	if cfg.Destination.BlocksEncryption.Enabled && !isConfigured(cfg.Destination.BlocksStorage) {
+		return errEncryptionWithoutStorage
+	}
+
+	if _, err := cfg.parseMatchers(); err != nil {
+		return err
+	}
+	if cfg.Concurrency <= 0 {
+		return errors.New("the concurrency must be greater than 0")
+	}
+	return nil
+}
+
+func (cfg *Config) parseMatchers() ([]*labels.Matcher, error) {
+	if cfg.Matchers == "" {
+		return nil, nil
+	}
+
+	matchers, err := parser.ParseMetricSelector(cfg.Matchers)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid series matchers")
+	}
+	return matchers, nil
+}
+
+func isConfigured(cfg bucket.Config) bool {
+	return cfg.Backend != ""
+}
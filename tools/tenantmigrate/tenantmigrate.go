@@ -0,0 +1,233 @@
+package tenantmigrate
+
+import (
+	"context"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/thanos-io/objstore"
+
+	"github.com/cortexproject/cortex/pkg/alertmanager/alertspb"
+	"github.com/cortexproject/cortex/pkg/alertmanager/alertstore"
+	alertstore_bucket "github.com/cortexproject/cortex/pkg/alertmanager/alertstore/bucketclient"
+	"github.com/cortexproject/cortex/pkg/ruler/rulespb"
+	"github.com/cortexproject/cortex/pkg/ruler/rulestore"
+	rulestore_bucket "github.com/cortexproject/cortex/pkg/ruler/rulestore/bucketclient"
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	"github.com/cortexproject/cortex/pkg/storage/bucket/encryption"
+	"github.com/cortexproject/cortex/pkg/util/users"
+)
+
+// Results of a tenant migration. In dry-run mode, the blocks and objects which
+// would have been copied are reported as copied.
+type Results struct {
+	CopiedBlocks    []string
+	RewrittenBlocks []string
+	EmptyBlocks     []string
+	MigratedBlocks  []string
+	FailedBlocks    []string
+
+	// EstimatedBytes is the size of the blocks to copy. When series matchers are set,
+	// it is an upper bound of the size of the rewritten blocks.
+	EstimatedBytes int64
+
+	RuleGroups         int
+	AlertmanagerConfig bool
+	AlertmanagerState  bool
+}
+
+// Migrator copies the blocks, rule groups and Alertmanager config and state of a tenant
+// to another tenant, optionally stored in different buckets.
+type Migrator struct {
+	cfg      Config
+	logger   log.Logger
+	matchers []*labels.Matcher
+
+	// Buckets and stores are nil if not configured.
+	srcBlocks objstore.InstrumentedBucket
+	dstBlocks objstore.InstrumentedBucket
+	srcRules  rulestore.RuleStore
+	dstRules  rulestore.RuleStore
+	srcAlerts alertstore.AlertStore
+	dstAlerts alertstore.AlertStore
+}
+
+// NewMigrator creates a Migrator from the config, which is expected to be valid.
+func NewMigrator(ctx context.Context, cfg Config, logger log.Logger) (*Migrator, error) {
+	var (
+		buckets [6]objstore.InstrumentedBucket
+		err     error
+	)
+
+	// The blocks of an encrypted storage are decrypted when read and encrypted again for the
+	// destination tenant when written, so that they can be rewritten and read by the destination.
+	blocksEncryption := [2]encryption.Config{cfg.Source.BlocksEncryption, cfg.Destination.BlocksEncryption}
+
+	for i, storage := range []bucket.Config{
+		cfg.Source.BlocksStorage, cfg.Destination.BlocksStorage,
+		cfg.Source.RulerStorage, cfg.Destination.RulerStorage,
+		cfg.Source.AlertmanagerStorage, cfg.Destination.AlertmanagerStorage,
+	} {
+		if !isConfigured(storage) {
+			continue
+		}
+		if i < len(blocksEncryption) && blocksEncryption[i].Enabled {
+			middleware, err := encryption.NewMiddleware(blocksEncryption[i], logger)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to initialize the blocks storage encryption")
+			}
+			storage.Middlewares = append(storage.Middlewares, middleware)
+		}
+		if buckets[i], err = bucket.NewClient(ctx, storage, nil, "tenantmigrate", logger, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return newMigrator(cfg, logger, buckets[0], buckets[1], buckets[2], buckets[3], buckets[4], buckets[5])
+}
+
+// newMigrator creates a Migrator from the given buckets. Each destination bucket which
+// is nil defaults to the matching source bucket.
+func newMigrator(cfg Config, logger log.Logger, srcBlocks, dstBlocks, srcRules, dstRules, srcAlerts, dstAlerts objstore.InstrumentedBucket) (*Migrator, error) {
+	matchers, err := cfg.parseMatchers()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Migrator{
+		cfg:      cfg,
+		logger:   log.With(logger, "source_tenant", cfg.SourceTenant, "destination_tenant", cfg.DestinationTenant),
+		matchers: matchers,
+	}
+
+	// The user scan strategy is hardcoded to list, because the tool doesn't scan users.
+	usersScannerCfg := users.UsersScannerConfig{Strategy: users.UserScanStrategyList}
+
+	if srcBlocks != nil {
+		m.srcBlocks = srcBlocks
+		m.dstBlocks = srcBlocks
+		if dstBlocks != nil {
+			m.dstBlocks = dstBlocks
+		}
+	}
+
+	if srcRules != nil {
+		if dstRules == nil {
+			dstRules = srcRules
+		}
+		if m.srcRules, err = rulestore_bucket.NewBucketRuleStore(srcRules, usersScannerCfg, nil, logger, nil); err != nil {
+			return nil, err
+		}
+		if m.dstRules, err = rulestore_bucket.NewBucketRuleStore(dstRules, usersScannerCfg, nil, logger, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if srcAlerts != nil {
+		if dstAlerts == nil {
+			dstAlerts = srcAlerts
+		}
+		if m.srcAlerts, err = alertstore_bucket.NewBucketAlertStore(srcAlerts, usersScannerCfg, nil, logger, nil); err != nil {
+			return nil, err
+		}
+		if m.dstAlerts, err = alertstore_bucket.NewBucketAlertStore(dstAlerts, usersScannerCfg, nil, logger, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// Run copies the tenant data. Objects already copied by a previous interrupted run
+// are skipped, so it is safe to run it again until it succeeds.
+func (m *Migrator) Run(ctx context.Context) (*Results, error) {
+	results := &Results{}
+
+	if m.srcBlocks != nil {
+		if err := m.migrateBlocks(ctx, results); err != nil {
+			return results, errors.Wrap(err, "error migrating blocks")
+		}
+	}
+
+	if m.srcRules != nil {
+		if err := m.migrateRuleGroups(ctx, results); err != nil {
+			return results, errors.Wrap(err, "error migrating rule groups")
+		}
+	}
+
+	if m.srcAlerts != nil {
+		if err := m.migrateAlertmanager(ctx, results); err != nil {
+			return results, errors.Wrap(err, "error migrating Alertmanager config")
+		}
+	}
+
+	return results, nil
+}
+
+// migrateRuleGroups copies all the rule groups, keeping their namespace. Rule groups
+// already existing in the destination tenant are overwritten.
+func (m *Migrator) migrateRuleGroups(ctx context.Context, results *Results) error {
+	list, err := m.srcRules.ListRuleGroupsForUserAndNamespace(ctx, m.cfg.SourceTenant, "")
+	if err != nil {
+		return errors.Wrap(err, "list rule groups")
+	}
+	if len(list) == 0 {
+		return nil
+	}
+
+	loaded, err := m.srcRules.LoadRuleGroups(ctx, map[string]rulespb.RuleGroupList{m.cfg.SourceTenant: list})
+	if err != nil {
+		return errors.Wrap(err, "load rule groups")
+	}
+
+	for _, group := range loaded[m.cfg.SourceTenant] {
+		results.RuleGroups++
+		if m.cfg.DryRun {
+			level.Info(m.logger).Log("msg", "rule group would be copied (dry-run)", "namespace", group.Namespace, "group", group.Name)
+			continue
+		}
+
+		group.User = m.cfg.DestinationTenant
+		if err := m.dstRules.SetRuleGroup(ctx, m.cfg.DestinationTenant, group.Namespace, group); err != nil {
+			return errors.Wrapf(err, "copy rule group %s in namespace %s", group.Name, group.Namespace)
+		}
+		level.Info(m.logger).Log("msg", "copied rule group", "namespace", group.Namespace, "group", group.Name)
+	}
+	return nil
+}
+
+// migrateAlertmanager copies the Alertmanager config and state (silences and
+// notification log), if any.
+func (m *Migrator) migrateAlertmanager(ctx context.Context, results *Results) error {
+	cfg, err := m.srcAlerts.GetAlertConfig(ctx, m.cfg.SourceTenant)
+	if err != nil && !errors.Is(err, alertspb.ErrNotFound) {
+		return errors.Wrap(err, "get config")
+	}
+	if err == nil {
+		results.AlertmanagerConfig = true
+		if !m.cfg.DryRun {
+			cfg.User = m.cfg.DestinationTenant
+			if err := m.dstAlerts.SetAlertConfig(ctx, cfg); err != nil {
+				return errors.Wrap(err, "copy config")
+			}
+			level.Info(m.logger).Log("msg", "copied Alertmanager config")
+		}
+	}
+
+	state, err := m.srcAlerts.GetFullState(ctx, m.cfg.SourceTenant)
+	if err != nil && !errors.Is(err, alertspb.ErrNotFound) {
+		return errors.Wrap(err, "get state")
+	}
+	if err == nil {
+		results.AlertmanagerState = true
+		if !m.cfg.DryRun {
+			if err := m.dstAlerts.SetFullState(ctx, m.cfg.DestinationTenant, state); err != nil {
+				return errors.Wrap(err, "copy state")
+			}
+			level.Info(m.logger).Log("msg", "copied Alertmanager state")
+		}
+	}
+	return nil
+}
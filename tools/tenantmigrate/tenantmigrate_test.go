@@ -0,0 +1,347 @@
+package tenantmigrate
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/oklog/ulid/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+	"github.com/thanos-io/thanos/pkg/testutil/e2eutil"
+
+	"github.com/cortexproject/cortex/pkg/alertmanager/alertspb"
+	"github.com/cortexproject/cortex/pkg/ruler/rulespb"
+	"github.com/cortexproject/cortex/pkg/storage/bucket"
+	"github.com/cortexproject/cortex/pkg/storage/bucket/encryption"
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	fs := bucket.Config{Backend: bucket.Filesystem}
+	fs.Filesystem.Directory = "/data"
+
+	tests := map[string]struct {
+		setup    func(cfg *Config)
+		expected string
+	}{
+		"should pass with a source storage": {},
+		"should fail without source tenant": {
+			setup:    func(cfg *Config) { cfg.SourceTenant = "" },
+			expected: errMissingSourceTenant.Error(),
+		},
+		"should fail without destination tenant": {
+			setup:    func(cfg *Config) { cfg.DestinationTenant = "" },
+			expected: errMissingDestinationTenant.Error(),
+		},
+		"should fail on invalid tenant": {
+			setup:    func(cfg *Config) { cfg.DestinationTenant = ".." },
+			expected: "invalid tenant ..",
+		},
+		"should fail without source storage": {
+			setup:    func(cfg *Config) { cfg.Source.BlocksStorage = bucket.Config{} },
+			expected: errNothingToMigrate.Error(),
+		},
+		"should fail copying a tenant to itself": {
+			setup:    func(cfg *Config) { cfg.DestinationTenant = cfg.SourceTenant },
+			expected: errSameTenantAndStorage.Error(),
+		},
+		"should pass copying a tenant to itself in another storage": {
+			setup: func(cfg *Config) {
+				cfg.DestinationTenant = cfg.SourceTenant
+				cfg.Destination.BlocksStorage = fs
+			},
+		},
+		"should fail on invalid storage": {
+			setup:    func(cfg *Config) { cfg.Destination.RulerStorage = bucket.Config{Backend: "unknown"} },
+			expected: "invalid destination ruler storage config",
+		},
+		"should pass with the blocks storage encryption": {
+			setup: func(cfg *Config) {
+				cfg.Source.BlocksEncryption = encryption.Config{Enabled: true, KeyProvider: encryption.KeyProviderKeyfile, Keyfile: encryption.KeyfileConfig{Path: "/keys.yaml"}}
+				cfg.Destination.BlocksStorage = fs
+				cfg.Destination.BlocksEncryption = cfg.Source.BlocksEncryption
+			},
+		},
+		"should fail on invalid blocks storage encryption": {
+			setup: func(cfg *Config) {
+				cfg.Source.BlocksEncryption = encryption.Config{Enabled: true, KeyProvider: "unknown"}
+			},
+			expected: "invalid source blocks storage encryption config",
+		},
+		"should fail with the destination encryption without destination blocks storage": {
+			setup: func(cfg *Config) {
+				cfg.Destination.BlocksEncryption = encryption.Config{Enabled: true, KeyProvider: encryption.KeyProviderKeyfile, Keyfile: encryption.KeyfileConfig{Path: "/keys.yaml"}}
+			},
+			expected: errEncryptionWithoutStorage.Error(),
+		},
+		"should fail on invalid matchers": {
+			setup:    func(cfg *Config) { cfg.Matchers = "{job=" },
+			expected: "invalid series matchers",
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := Config{
+				SourceTenant:      "user-1",
+				DestinationTenant: "user-2",
+				Concurrency:       1,
+			}
+			cfg.Source.BlocksStorage = fs
+			if testData.setup != nil {
+				testData.setup(&cfg)
+			}
+
+			err := cfg.Validate()
+			if testData.expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), testData.expected)
+			}
+		})
+	}
+}
+
+func TestMigrator_Blocks(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	srcBkt := objstore.WithNoopInstr(objstore.NewInMemBucket())
+	dstBkt := objstore.WithNoopInstr(objstore.NewInMemBucket())
+
+	block1 := uploadTestBlock(t, srcBkt, "user-1", []labels.Labels{
+		labels.FromStrings(labels.MetricName, "series_1", "env", "prod"),
+		labels.FromStrings(labels.MetricName, "series_2", "env", "dev"),
+	})
+	block2 := uploadTestBlock(t, srcBkt, "user-1", []labels.Labels{
+		labels.FromStrings(labels.MetricName, "series_1", "env", "dev"),
+	})
+	deletedBlock := uploadTestBlock(t, srcBkt, "user-1", []labels.Labels{
+		labels.FromStrings(labels.MetricName, "series_1", "env", "prod"),
+	})
+	require.NoError(t, block.MarkForDeletion(ctx, log.NewNopLogger(), bucket.NewUserBucketClient("user-1", srcBkt, nil), deletedBlock, "", prometheus.NewCounter(prometheus.CounterOpts{})))
+
+	t.Run("dry-run should only estimate the size", func(t *testing.T) {
+		results := runMigrator(t, Config{DryRun: true}, srcBkt, dstBkt)
+
+		assert.ElementsMatch(t, []string{block1.String(), block2.String()}, results.CopiedBlocks)
+		assert.Positive(t, results.EstimatedBytes)
+		assert.Empty(t, listObjects(t, dstBkt))
+	})
+
+	t.Run("should copy the blocks and update the bucket index", func(t *testing.T) {
+		results := runMigrator(t, Config{}, srcBkt, dstBkt)
+
+		assert.ElementsMatch(t, []string{block1.String(), block2.String()}, results.CopiedBlocks)
+		assert.Empty(t, results.FailedBlocks)
+
+		userBkt := bucket.NewUserBucketClient("user-2", dstBkt, nil)
+		for _, id := range []ulid.ULID{block1, block2} {
+			meta, err := block.DownloadMeta(ctx, log.NewNopLogger(), userBkt, id)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{cortex_tsdb.TenantIDExternalLabel: "user-2"}, meta.Thanos.Labels)
+
+			exists, err := userBkt.Exists(ctx, path.Join(id.String(), block.IndexFilename))
+			require.NoError(t, err)
+			assert.True(t, exists)
+		}
+
+		idx, err := bucketindex.ReadIndex(ctx, dstBkt, "user-2", nil, log.NewNopLogger())
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []ulid.ULID{block1, block2}, idx.Blocks.GetULIDs())
+	})
+
+	t.Run("should skip the blocks already migrated", func(t *testing.T) {
+		results := runMigrator(t, Config{}, srcBkt, dstBkt)
+
+		assert.Empty(t, results.CopiedBlocks)
+		assert.ElementsMatch(t, []string{block1.String(), block2.String()}, results.MigratedBlocks)
+	})
+
+	t.Run("should rewrite the blocks with the series matching the matchers", func(t *testing.T) {
+		rewriteBkt := objstore.WithNoopInstr(objstore.NewInMemBucket())
+		cfg := Config{Matchers: `{env="prod"}`, WorkingDir: t.TempDir()}
+
+		results := runMigrator(t, cfg, srcBkt, rewriteBkt)
+		assert.Equal(t, []string{block1.String()}, results.RewrittenBlocks)
+		assert.Equal(t, []string{block2.String()}, results.EmptyBlocks)
+		assert.Empty(t, results.FailedBlocks)
+
+		userBkt := bucket.NewUserBucketClient("user-2", rewriteBkt, nil)
+		idx, err := bucketindex.ReadIndex(ctx, rewriteBkt, "user-2", nil, log.NewNopLogger())
+		require.NoError(t, err)
+		require.Len(t, idx.Blocks, 1)
+		newID := idx.Blocks[0].ID
+		assert.NotEqual(t, block1, newID)
+
+		srcMeta, err := block.DownloadMeta(ctx, log.NewNopLogger(), bucket.NewUserBucketClient("user-1", srcBkt, nil), block1)
+		require.NoError(t, err)
+		meta, err := block.DownloadMeta(ctx, log.NewNopLogger(), userBkt, newID)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{cortex_tsdb.TenantIDExternalLabel: "user-2"}, meta.Thanos.Labels)
+		assert.Equal(t, []metadata.Rewrite{{Sources: []ulid.ULID{block1}}}, meta.Thanos.Rewrites)
+		assert.Equal(t, srcMeta.MinTime, meta.MinTime)
+		assert.Equal(t, srcMeta.MaxTime, meta.MaxTime)
+		assert.Equal(t, uint64(1), meta.Stats.NumSeries)
+		assert.Equal(t, srcMeta.Stats.NumSamples/2, meta.Stats.NumSamples)
+
+		// Running the migration again should skip the rewritten block and the empty one.
+		results = runMigrator(t, cfg, srcBkt, rewriteBkt)
+		assert.Empty(t, results.RewrittenBlocks)
+		assert.Empty(t, results.EmptyBlocks)
+		assert.ElementsMatch(t, []string{block1.String(), block2.String()}, results.MigratedBlocks)
+	})
+}
+
+func TestMigrator_RulesAndAlertmanager(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	rulesBkt := objstore.WithNoopInstr(objstore.NewInMemBucket())
+	alertsBkt := objstore.WithNoopInstr(objstore.NewInMemBucket())
+	logger := log.NewNopLogger()
+
+	m, err := newMigrator(Config{SourceTenant: "user-1", DestinationTenant: "user-2", Concurrency: 1}, logger, nil, nil, rulesBkt, nil, alertsBkt, nil)
+	require.NoError(t, err)
+
+	for _, group := range []*rulespb.RuleGroupDesc{
+		{Name: "group-1", Namespace: "namespace-1", User: "user-1", Interval: time.Minute},
+		{Name: "group-2", Namespace: "namespace-2", User: "user-1", Interval: time.Minute},
+	} {
+		require.NoError(t, m.srcRules.SetRuleGroup(ctx, "user-1", group.Namespace, group))
+	}
+	require.NoError(t, m.srcAlerts.SetAlertConfig(ctx, alertspb.AlertConfigDesc{User: "user-1", RawConfig: "route: {}"}))
+
+	results, err := m.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, results.RuleGroups)
+	assert.True(t, results.AlertmanagerConfig)
+	assert.False(t, results.AlertmanagerState)
+
+	groups, err := m.dstRules.ListRuleGroupsForUserAndNamespace(ctx, "user-2", "")
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+
+	group, err := m.dstRules.GetRuleGroup(ctx, "user-2", "namespace-2", "group-2")
+	require.NoError(t, err)
+	assert.Equal(t, "user-2", group.User)
+	assert.Equal(t, time.Minute, group.Interval)
+
+	cfg, err := m.dstAlerts.GetAlertConfig(ctx, "user-2")
+	require.NoError(t, err)
+	assert.Equal(t, alertspb.AlertConfigDesc{User: "user-2", RawConfig: "route: {}"}, cfg)
+
+	// The source tenant should be left untouched.
+	groups, err = m.srcRules.ListRuleGroupsForUserAndNamespace(ctx, "user-1", "")
+	require.NoError(t, err)
+	assert.Len(t, groups, 2)
+}
+
+func TestMigrator_EncryptedBlocks(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	keyfile := filepath.Join(t.TempDir(), "keys.yaml")
+	require.NoError(t, os.WriteFile(keyfile, []byte("default_keys:\n  - "+base64.StdEncoding.EncodeToString(key)+"\n"), 0600))
+
+	storage := func() (bucket.Config, encryption.Config) {
+		cfg := bucket.Config{Backend: bucket.Filesystem}
+		cfg.Filesystem.Directory = t.TempDir()
+		return cfg, encryption.Config{Enabled: true, KeyProvider: encryption.KeyProviderKeyfile, Keyfile: encryption.KeyfileConfig{Path: keyfile}}
+	}
+	encryptedClient := func(cfg bucket.Config, encryptionCfg encryption.Config) objstore.InstrumentedBucket {
+		middleware, err := encryption.NewMiddleware(encryptionCfg, log.NewNopLogger())
+		require.NoError(t, err)
+		cfg.Middlewares = append(cfg.Middlewares, middleware)
+		bkt, err := bucket.NewClient(ctx, cfg, nil, "test", log.NewNopLogger(), nil)
+		require.NoError(t, err)
+		return bkt
+	}
+
+	cfg := Config{SourceTenant: "user-1", DestinationTenant: "user-2", Concurrency: 1, Matchers: `{env="prod"}`, WorkingDir: t.TempDir()}
+	cfg.Source.BlocksStorage, cfg.Source.BlocksEncryption = storage()
+	cfg.Destination.BlocksStorage, cfg.Destination.BlocksEncryption = storage()
+	require.NoError(t, cfg.Validate())
+
+	srcID := uploadTestBlock(t, encryptedClient(cfg.Source.BlocksStorage, cfg.Source.BlocksEncryption), "user-1", []labels.Labels{
+		labels.FromStrings(labels.MetricName, "series_1", "env", "prod"),
+		labels.FromStrings(labels.MetricName, "series_2", "env", "dev"),
+	})
+
+	m, err := NewMigrator(ctx, cfg, log.NewNopLogger())
+	require.NoError(t, err)
+	results, err := m.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{srcID.String()}, results.RewrittenBlocks)
+	assert.Empty(t, results.FailedBlocks)
+
+	// The rewritten block is encrypted for the destination tenant.
+	dstBkt := encryptedClient(cfg.Destination.BlocksStorage, cfg.Destination.BlocksEncryption)
+	idx, err := bucketindex.ReadIndex(ctx, dstBkt, "user-2", nil, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Len(t, idx.Blocks, 1)
+
+	meta, err := block.DownloadMeta(ctx, log.NewNopLogger(), bucket.NewUserBucketClient("user-2", dstBkt, nil), idx.Blocks[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), meta.Stats.NumSeries)
+
+	raw, err := os.ReadFile(filepath.Join(cfg.Destination.BlocksStorage.Filesystem.Directory, "user-2", idx.Blocks[0].ID.String(), block.MetaFilename))
+	require.NoError(t, err)
+	assert.False(t, json.Valid(raw))
+}
+
+func runMigrator(t *testing.T, cfg Config, srcBkt, dstBkt objstore.InstrumentedBucket) *Results {
+	cfg.SourceTenant = "user-1"
+	cfg.DestinationTenant = "user-2"
+	cfg.Concurrency = 2
+
+	m, err := newMigrator(cfg, log.NewNopLogger(), srcBkt, dstBkt, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	results, err := m.Run(context.Background())
+	require.NoError(t, err)
+	return results
+}
+
+func uploadTestBlock(t *testing.T, bkt objstore.Bucket, userID string, series []labels.Labels) ulid.ULID {
+	dir := t.TempDir()
+	maxt := time.Now().Truncate(2 * time.Hour).UnixMilli()
+	mint := maxt - 2*time.Hour.Milliseconds()
+
+	id, err := e2eutil.CreateBlock(context.Background(), dir, series, 10, mint, maxt, labels.FromStrings(cortex_tsdb.TenantIDExternalLabel, userID), 0, metadata.NoneFunc, []chunkenc.ValueType{chunkenc.ValFloat})
+	require.NoError(t, err)
+	require.NoError(t, block.Upload(context.Background(), log.NewNopLogger(), bucket.NewUserBucketClient(userID, bkt, nil), filepath.Join(dir, id.String()), metadata.NoneFunc))
+	return id
+}
+
+func listObjects(t *testing.T, bkt objstore.Bucket) []string {
+	var names []string
+	require.NoError(t, bkt.Iter(context.Background(), "", func(name string) error {
+		names = append(names, name)
+		return nil
+	}, objstore.WithRecursiveIter()))
+	return names
+}